@@ -33,6 +33,9 @@ type CertificateStoreSpec struct {
 
 	// Parameters of the certificate store
 	Parameters runtime.RawExtension `json:"parameters,omitempty"`
+
+	// Interval to refresh the certificates, e.g. 12h. Certificates are only fetched when the resource changes if empty.
+	RefreshInterval string `json:"refreshInterval,omitempty"`
 }
 
 type CertificateStoreStatus struct {
@@ -21,6 +21,11 @@ import (
 	conversion "k8s.io/apimachinery/pkg/conversion"
 )
 
+// Convert_unversioned_CertificateStoreSpec_To_v1alpha1_CertificateStoreSpec converts the spec, the refresh interval is not supported by v1alpha1.
+func Convert_unversioned_CertificateStoreSpec_To_v1alpha1_CertificateStoreSpec(in *unversioned.CertificateStoreSpec, out *CertificateStoreSpec, s conversion.Scope) error { //nolint:revive // ignore linter for autogenerated code
+	return autoConvert_unversioned_CertificateStoreSpec_To_v1alpha1_CertificateStoreSpec(in, out, s)
+}
+
 // Convert_unversioned_CertificateStoreStatus_To_v1alpha1_CertificateStoreStatus is an autogenerated conversion function.
 func Convert_unversioned_CertificateStoreStatus_To_v1alpha1_CertificateStoreStatus(in *unversioned.CertificateStoreStatus, out *CertificateStoreStatus, s conversion.Scope) error { //nolint:revive // ignore linter for autogenerated code
 	return nil
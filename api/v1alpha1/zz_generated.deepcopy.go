@@ -119,6 +119,7 @@ func (in *CertificateStoreStatus) DeepCopy() *CertificateStoreStatus {
 func (in *PluginSource) DeepCopyInto(out *PluginSource) {
 	*out = *in
 	in.AuthProvider.DeepCopyInto(&out.AuthProvider)
+	in.Verification.DeepCopyInto(&out.Verification)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSource.
@@ -33,6 +33,10 @@ type CertificateStoreSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// Parameters of the certificate store
 	Parameters runtime.RawExtension `json:"parameters,omitempty"`
+
+	// Interval to refresh the certificates, e.g. 12h. Certificates are only fetched when the resource changes if empty.
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
 }
 
 // CertificateStoreStatus defines the observed state of CertificateStore
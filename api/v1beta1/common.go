@@ -27,4 +27,8 @@ type PluginSource struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// AuthProvider to use to authenticate to the OCI Artifact source, optional
 	AuthProvider runtime.RawExtension `json:"authProvider,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// Verification of the plugin artifact signature, optional
+	Verification runtime.RawExtension `json:"verification,omitempty"`
 }
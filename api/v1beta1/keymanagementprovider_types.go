@@ -0,0 +1,96 @@
+/*
+Copyright The Ratify Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// KeyManagementProviderSpec defines the desired state of KeyManagementProvider
+type KeyManagementProviderSpec struct {
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Type of the key management provider, e.g. inline, azurekeyvault, hashicorpvault, awskms, gcpkms or hashivault
+	Type string `json:"type,omitempty"`
+
+	// Interval to refresh the certificates and keys, e.g. 12h. Certificates and keys are only fetched when the resource changes if empty.
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+
+	// Number of previously fetched versions of each certificate and key kept after a rotation, so that artifacts signed with
+	// recently rotated certificates and keys still verify. Only the current versions are kept if 0, at most 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	VersionHistory int `json:"versionHistory,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// Parameters of the key management provider
+	Parameters runtime.RawExtension `json:"parameters,omitempty"`
+}
+
+// KeyManagementProviderStatus defines the observed state of KeyManagementProvider
+type KeyManagementProviderStatus struct {
+	// Important: Run "make manifests" to regenerate code after modifying this file
+
+	// Is successful in fetching the certificates and keys
+	IsSuccess bool `json:"issuccess"`
+	// Error message if operation was unsuccessful
+	// +optional
+	Error string `json:"error,omitempty"`
+	// Truncated error message if the message is too long
+	// +optional
+	BriefError string `json:"brieferror,omitempty"`
+	// The time stamp of last successful fetch operation. If operation failed, last fetched time shows the time of error
+	// +optional
+	LastFetchedTime *metav1.Time `json:"lastfetchedtime,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// provider specific properties of the each individual certificate and key
+	// +optional
+	Properties runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// KeyManagementProvider is the Schema for the keymanagementproviders API
+// +kubebuilder:printcolumn:name="IsSuccess",type=boolean,JSONPath=`.status.issuccess`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.brieferror`
+// +kubebuilder:printcolumn:name="LastFetchedTime",type=date,JSONPath=`.status.lastfetchedtime`
+type KeyManagementProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KeyManagementProviderSpec   `json:"spec,omitempty"`
+	Status KeyManagementProviderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// KeyManagementProviderList contains a list of KeyManagementProvider
+type KeyManagementProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KeyManagementProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KeyManagementProvider{}, &KeyManagementProviderList{})
+}
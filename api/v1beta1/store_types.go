@@ -39,14 +39,41 @@ type StoreSpec struct {
 	Parameters runtime.RawExtension `json:"parameters,omitempty"`
 }
 
+const (
+	// StoreConditionReady reports whether the store was created from the spec.
+	StoreConditionReady = "Ready"
+	// StoreConditionRegistryReachable reports whether the store could connect
+	// to registries on its last request.
+	StoreConditionRegistryReachable = "RegistryReachable"
+)
+
 // StoreStatus defines the observed state of Store
 type StoreStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Is successful in creating the store
+	IsSuccess bool `json:"issuccess"`
+	// Error message if the store is not successfully created
+	// +optional
+	Error string `json:"error,omitempty"`
+	// Truncated error message if the message is too long
+	// +optional
+	BriefError string `json:"brieferror,omitempty"`
+	// Conditions of the store, the RegistryReachable condition reports
+	// problems connecting to registries
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="IsSuccess",type=boolean,JSONPath=`.status.issuccess`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.brieferror`
+// +kubebuilder:printcolumn:name="RegistryReachable",type=string,JSONPath=`.status.conditions[?(@.type=="RegistryReachable")].status`
 // Store is the Schema for the stores API
 type Store struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -22,6 +22,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -120,10 +121,106 @@ func (in *CertificateStoreStatus) DeepCopy() *CertificateStoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagementProvider) DeepCopyInto(out *KeyManagementProvider) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagementProvider.
+func (in *KeyManagementProvider) DeepCopy() *KeyManagementProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagementProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeyManagementProvider) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagementProviderList) DeepCopyInto(out *KeyManagementProviderList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KeyManagementProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagementProviderList.
+func (in *KeyManagementProviderList) DeepCopy() *KeyManagementProviderList {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagementProviderList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KeyManagementProviderList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagementProviderSpec) DeepCopyInto(out *KeyManagementProviderSpec) {
+	*out = *in
+	in.Parameters.DeepCopyInto(&out.Parameters)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagementProviderSpec.
+func (in *KeyManagementProviderSpec) DeepCopy() *KeyManagementProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagementProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagementProviderStatus) DeepCopyInto(out *KeyManagementProviderStatus) {
+	*out = *in
+	if in.LastFetchedTime != nil {
+		in, out := &in.LastFetchedTime, &out.LastFetchedTime
+		*out = (*in).DeepCopy()
+	}
+	in.Properties.DeepCopyInto(&out.Properties)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagementProviderStatus.
+func (in *KeyManagementProviderStatus) DeepCopy() *KeyManagementProviderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagementProviderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PluginSource) DeepCopyInto(out *PluginSource) {
 	*out = *in
 	in.AuthProvider.DeepCopyInto(&out.AuthProvider)
+	in.Verification.DeepCopyInto(&out.Verification)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSource.
@@ -232,7 +329,7 @@ func (in *Store) DeepCopyInto(out *Store) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Store.
@@ -309,6 +406,13 @@ func (in *StoreSpec) DeepCopy() *StoreSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StoreStatus) DeepCopyInto(out *StoreStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreStatus.
@@ -146,4 +146,87 @@ Set the namespace exclusions for Assign
 {{- if and (ne .Release.Namespace $gkNamespace) (ne .Release.Namespace "kube-system") }}
 - {{ .Release.Namespace | quote}}
 {{- end }}
-{{- end }}
\ No newline at end of file
+{{- end }}
+
+{{/*
+Volume mounts of the Ratify and preflight containers
+*/}}
+{{- define "ratify.volumeMounts" -}}
+{{- $dockerAuthMode := or .Values.dockerConfig .Values.registryCredsSecret -}}
+{{- if .Values.cosign.enabled }}
+- mountPath: "/usr/local/ratify-certs/cosign"
+  name: cosign-certs
+  readOnly: true
+{{- end }}
+{{- if .Values.notationTSARootCerts }}
+- mountPath: "/usr/local/ratify-certs/notation/tsa"
+  name: notation-tsa-certs
+  readOnly: true
+{{- end }}
+- mountPath: "/usr/local/ratify"
+  name: config
+  readOnly: true
+{{- if .Values.policy.useRego }}
+- mountPath: "/usr/local/ratify/policy"
+  name: policy
+  readOnly: true
+{{- end }}
+{{- if $dockerAuthMode }}
+- mountPath: "/usr/local/docker"
+  name: dockerconfig
+  readOnly: true
+{{- end }}
+- mountPath: /usr/local/tls
+  name: tls
+  readOnly: true
+{{- if (lookup "v1" "Secret" .Release.Namespace "gatekeeper-webhook-server-cert") }}
+- mountPath: /usr/local/tls/client-ca
+  name: client-ca-cert
+  readOnly: true
+{{- end }}
+{{- end }}
+
+{{/*
+Environment variables of the Ratify and preflight containers
+*/}}
+{{- define "ratify.env" -}}
+{{- $dockerAuthMode := or .Values.dockerConfig .Values.registryCredsSecret -}}
+{{- if .Values.logger.level }}
+- name: RATIFY_LOG_LEVEL
+  value: {{ .Values.logger.level }}
+{{- end }}
+{{- if $dockerAuthMode }}
+- name: DOCKER_CONFIG
+  value: "/usr/local/docker"
+{{- end }}
+{{- if .Values.oras.authProviders.azureManagedIdentityEnabled }}
+- name: AZURE_TENANT_ID
+  value: {{ .Values.azureManagedIdentity.tenantId }}
+{{- end }}
+{{- if and .Values.oras.authProviders.awsEcrBasicEnabled .Values.oras.authProviders.awsApiOverride.enabled }}
+{{- if and .Values.oras.authProviders.awsApiOverride.endpoint .Values.oras.authProviders.awsApiOverride.partition .Values.oras.authProviders.awsApiOverride.region }}
+- name: AWS_API_OVERRIDE_ENDPOINT
+  value: {{ .Values.oras.authProviders.awsApiOverride.endpoint }}
+- name: AWS_API_OVERRIDE_PARTITION
+  value: {{ .Values.oras.authProviders.awsApiOverride.partition }}
+- name: AWS_API_OVERRIDE_REGION
+  value: {{ .Values.oras.authProviders.awsApiOverride.region }}
+{{- end }}
+{{- end }}
+- name: RATIFY_NAMESPACE
+  valueFrom:
+    fieldRef:
+      fieldPath: metadata.namespace
+- name: RATIFY_POD_NAME
+  valueFrom:
+    fieldRef:
+      fieldPath: metadata.name
+- name: RATIFY_NAME
+  value: {{ include "ratify.fullname" . }}
+- name: RATIFY_IMAGE
+  value: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+{{- range $k, $v := .Values.featureFlags }}
+- name: {{ $k }}
+  value: {{ $v | ternary 1 0 | quote }}
+{{- end }}
+{{- end }}
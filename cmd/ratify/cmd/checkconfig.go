@@ -0,0 +1,104 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/deislabs/ratify/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkConfigUse = "check-config"
+)
+
+type checkConfigCmdOptions struct {
+	configFilePath string
+	output         string
+}
+
+func NewCmdCheckConfig(argv ...string) *cobra.Command {
+	if len(argv) == 0 {
+		argv = []string{os.Args[0]}
+	}
+
+	eg := fmt.Sprintf(`  # Check that the stores, verifiers and policy of a config can be created
+  %s check-config -c ./config.json`, strings.Join(argv, " "))
+
+	var opts checkConfigCmdOptions
+
+	cmd := &cobra.Command{
+		Use:     checkConfigUse,
+		Short:   "Initialize the stores, verifiers and policy of a config without serving, fails if any of them fails to initialize",
+		Example: eg,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return checkConfig(opts)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
+	flags.StringVarP(&opts.output, "output", "o", outputTable, "Output format of the check results, table or json")
+	return cmd
+}
+
+func checkConfig(opts checkConfigCmdOptions) error {
+	if opts.output != outputTable && opts.output != outputJSON {
+		return fmt.Errorf("unsupported output format %s, supported formats are %s, %s", opts.output, outputTable, outputJSON)
+	}
+
+	checks := config.Check(opts.configFilePath)
+
+	var err error
+	if opts.output == outputJSON {
+		err = PrintJSON(checks)
+	} else {
+		err = printChecks(os.Stdout, checks)
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.CheckFailed(checks) {
+		return errors.New("config check failed")
+	}
+	return nil
+}
+
+// printChecks prints a row per check followed by a row per warning.
+func printChecks(w io.Writer, checks []config.ComponentCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tNAME\tRESULT\tMESSAGE")
+	for _, check := range checks {
+		result := "ok"
+		if check.Error != "" {
+			result = "error"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Component, check.Name, result, check.Error)
+		for _, warning := range check.Warnings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Component, check.Name, "warning", warning)
+		}
+	}
+	return tw.Flush()
+}
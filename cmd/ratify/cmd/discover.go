@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/deislabs/ratify/config"
@@ -38,11 +39,19 @@ const (
 	discoverUse = "discover"
 )
 
+// output formats of the discover command
+const (
+	discoverOutputTree = "tree"
+	discoverOutputJSON = "json"
+)
+
 type discoverCmdOptions struct {
 	configFilePath string
 	subject        string
 	artifactTypes  []string
+	annotations    []string
 	flatOutput     bool
+	output         string
 }
 
 func NewCmdDiscover(argv ...string) *cobra.Command {
@@ -51,16 +60,24 @@ func NewCmdDiscover(argv ...string) *cobra.Command {
 	}
 
 	eg := fmt.Sprintf(`  # List referrers for a subject
-  %s discover -c ./config.yaml -s myregistry/myrepo@sha256:34343`, strings.Join(argv, " "))
+  %[1]s discover -c ./config.yaml -s myregistry/myrepo@sha256:34343
+
+  # Print the referrer graph of the signatures of a subject as JSON
+  %[1]s discover -c ./config.yaml myregistry/myrepo@sha256:34343 --artifact-type application/vnd.cncf.notary.signature -o json`, strings.Join(argv, " "))
 
 	var opts discoverCmdOptions
 
 	cmd := &cobra.Command{
-		Use:     discoverUse,
+		Use:     discoverUse + " [subject]",
 		Short:   "Discover referrers for a subject",
 		Example: eg,
-		Args:    cobra.NoArgs,
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			subject, err := subjectFromArgs(opts.subject, args)
+			if err != nil {
+				return err
+			}
+			opts.subject = subject
 			return discover(opts)
 		},
 	}
@@ -70,7 +87,10 @@ func NewCmdDiscover(argv ...string) *cobra.Command {
 	flags.StringVarP(&opts.subject, "subject", "s", "", "Subject Reference")
 	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
 	flags.StringArrayVarP(&opts.artifactTypes, "artifactType", "t", nil, "artifact type to filter")
+	flags.StringArrayVar(&opts.artifactTypes, "artifact-type", nil, "artifact type to filter, same as artifactType")
+	flags.StringArrayVar(&opts.annotations, "annotation", nil, "annotation key=value the referrers must have, a key without value matches any value")
 	flags.BoolVar(&opts.flatOutput, "flat", false, "Output referrers in a flat list format (default is tree format)")
+	flags.StringVarP(&opts.output, "output", "o", discoverOutputTree, "Output format of the referrer graph, tree or json")
 	return cmd
 }
 
@@ -79,10 +99,30 @@ type listResult struct {
 	References []ocispecs.ReferenceDescriptor `json:"References,omitempty"`
 }
 
+// storeGraph is the referrer graph of the subject in a store.
+type storeGraph struct {
+	Name      string         `json:"storeName"`
+	Subject   string         `json:"subject"`
+	Referrers []referrerNode `json:"referrers"`
+}
+
+// referrerNode is a referrer of the subject or of another referrer.
+type referrerNode struct {
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	MediaType    string            `json:"mediaType,omitempty"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Referrers    []referrerNode    `json:"referrers,omitempty"`
+
+	descriptor ocispecs.ReferenceDescriptor
+}
+
 func Test(subject string) {
 	_ = discover((discoverCmdOptions{
 		subject:       subject,
 		artifactTypes: []string{""},
+		output:        discoverOutputTree,
 	}))
 }
 
@@ -91,14 +131,25 @@ func discover(opts discoverCmdOptions) error {
 		return errors.New("subject parameter is required")
 	}
 
+	if opts.output != discoverOutputTree && opts.output != discoverOutputJSON {
+		return fmt.Errorf("unsupported output format %s, supported formats are %s, %s", opts.output, discoverOutputTree, discoverOutputJSON)
+	}
+
 	subRef, err := utils.ParseSubjectReference(opts.subject)
 	if err != nil {
 		return err
 	}
 
 	if subRef.Digest == "" {
-		fmt.Println(taggedReferenceWarning)
+		// the warning is not part of the output, which may be parsed
+		fmt.Fprintln(os.Stderr, taggedReferenceWarning)
+	}
+
+	annotations, err := parseAnnotationFilters(opts.annotations)
+	if err != nil {
+		return err
 	}
+	filter := referrerstore.ReferrerFilter{ArtifactTypes: opts.artifactTypes, Annotations: annotations}
 
 	cf, err := config.Load(opts.configFilePath)
 	if err != nil {
@@ -109,8 +160,6 @@ func discover(opts discoverCmdOptions) error {
 		return err
 	}
 
-	rootImage := treeprint.NewWithRoot(subRef.String())
-
 	stores, err := sf.CreateStoresFromConfig(cf.StoresConfig, config.GetDefaultPluginPath())
 	if err != nil {
 		return err
@@ -124,57 +173,107 @@ func discover(opts discoverCmdOptions) error {
 		subRef.Digest = desc.Digest
 	}
 
-	results := []listResult{}
+	graphs := []storeGraph{}
 	for _, referrerStore := range stores {
-		storeNode := rootImage.AddBranch(referrerStore.Name())
-		result, err := listReferrersForStore(subRef, opts.artifactTypes, referrerStore, storeNode)
+		referrers, err := discoverReferrers(subRef, filter, referrerStore)
 		if err != nil {
 			return err
 		}
-		results = append(results, *result)
+		graphs = append(graphs, storeGraph{Name: referrerStore.Name(), Subject: fmt.Sprintf("%s@%s", subRef.Path, subRef.Digest), Referrers: referrers})
 	}
 
-	if !opts.flatOutput {
-		fmt.Println(rootImage.String())
+	switch {
+	case opts.flatOutput:
+		results := make([]listResult, 0, len(graphs))
+		for _, graph := range graphs {
+			results = append(results, listResult{Name: graph.Name, References: flattenReferrers(graph.Referrers)})
+		}
+		return PrintJSON(results)
+	case opts.output == discoverOutputJSON:
+		return PrintJSON(graphs)
+	default:
+		fmt.Println(referrerTree(subRef.String(), graphs).String())
 		return nil
 	}
-
-	return PrintJSON(results)
 }
 
-func listReferrersForStore(subRef common.Reference, artifactTypes []string, store referrerstore.ReferrerStore, treeNode treeprint.Tree) (*listResult, error) {
+// discoverReferrers lists the referrers of the subject in the store and the
+// referrers of each referrer.
+func discoverReferrers(subRef common.Reference, filter referrerstore.ReferrerFilter, store referrerstore.ReferrerStore) ([]referrerNode, error) {
 	var continuationToken string
-	result := listResult{
-		Name: store.Name(),
-	}
+	nodes := []referrerNode{}
 
 	for {
 		// subject descriptor has not been resolved thus nil passed in to ListReferrers
-		lr, err := store.ListReferrers(context.Background(), subRef, artifactTypes, continuationToken, nil)
+		lr, err := referrerstore.ListFilteredReferrers(context.Background(), store, subRef, filter, continuationToken, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get referrers list from subject %s: %w", subRef.Original, err)
 		}
 
 		continuationToken = lr.NextToken
 		for _, ref := range lr.Referrers {
-			refNode := treeNode.AddBranch(fmt.Sprintf("[%s]%s", ref.ArtifactType, ref.Digest.String()))
 			sr := common.Reference{
 				Path:     subRef.Path,
 				Digest:   ref.Digest,
 				Original: fmt.Sprintf("%s@%s", subRef.Path, ref.Digest),
 			}
 
-			subResult, err := listReferrersForStore(sr, artifactTypes, store, refNode)
+			referrers, err := discoverReferrers(sr, filter, store)
 			if err != nil {
 				return nil, err
 			}
-			result.References = append(result.References, subResult.References...)
+			nodes = append(nodes, referrerNode{
+				ArtifactType: ref.ArtifactType,
+				Digest:       ref.Digest.String(),
+				MediaType:    ref.MediaType,
+				Size:         ref.Size,
+				Annotations:  ref.Annotations,
+				Referrers:    referrers,
+				descriptor:   ref,
+			})
 		}
-		result.References = append(result.References, lr.Referrers...)
 		if continuationToken == "" {
 			break
 		}
 	}
 
-	return &result, nil
+	return nodes, nil
+}
+
+// flattenReferrers lists the referrers of each referrer before the referrer
+// itself.
+func flattenReferrers(nodes []referrerNode) []ocispecs.ReferenceDescriptor {
+	var references []ocispecs.ReferenceDescriptor
+	for _, node := range nodes {
+		references = append(references, flattenReferrers(node.Referrers)...)
+	}
+	for _, node := range nodes {
+		references = append(references, node.descriptor)
+	}
+	return references
+}
+
+// referrerTree returns a tree of the referrers in each store, each referrer
+// is printed with its size followed by its annotations.
+func referrerTree(subject string, graphs []storeGraph) treeprint.Tree {
+	root := treeprint.NewWithRoot(subject)
+	var add func(tree treeprint.Tree, nodes []referrerNode)
+	add = func(tree treeprint.Tree, nodes []referrerNode) {
+		for _, node := range nodes {
+			branch := tree.AddBranch(fmt.Sprintf("[%s]%s (%d bytes)", node.ArtifactType, node.Digest, node.Size))
+			keys := make([]string, 0, len(node.Annotations))
+			for key := range node.Annotations {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				branch.AddNode(fmt.Sprintf("%s=%s", key, node.Annotations[key]))
+			}
+			add(branch, node.Referrers)
+		}
+	}
+	for _, graph := range graphs {
+		add(root.AddBranch(graph.Name), graph.Referrers)
+	}
+	return root
 }
@@ -0,0 +1,78 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func testReferrerNode(artifactType string, digestValue digest.Digest, annotations map[string]string, referrers ...referrerNode) referrerNode {
+	return referrerNode{
+		ArtifactType: artifactType,
+		Digest:       digestValue.String(),
+		Size:         42,
+		Annotations:  annotations,
+		Referrers:    referrers,
+		descriptor:   ocispecs.ReferenceDescriptor{Descriptor: v1.Descriptor{Digest: digestValue, Size: 42, Annotations: annotations}, ArtifactType: artifactType},
+	}
+}
+
+func testReferrerGraphs() []storeGraph {
+	sbomSignature := testReferrerNode("application/vnd.cncf.notary.signature", digest.Digest(testSignatureDigest), nil)
+	sbom := testReferrerNode("application/spdx+json", digest.Digest(testSBOMDigest), map[string]string{"org.opencontainers.image.created": "2023-01-01T00:00:00Z"}, sbomSignature)
+	return []storeGraph{{Name: "oras", Subject: testSubject, Referrers: []referrerNode{sbom}}}
+}
+
+func TestReferrerTree(t *testing.T) {
+	tree := referrerTree(testSubject, testReferrerGraphs()).String()
+	for _, expected := range []string{
+		"oras",
+		"[application/spdx+json]" + testSBOMDigest + " (42 bytes)",
+		"org.opencontainers.image.created=2023-01-01T00:00:00Z",
+		"[application/vnd.cncf.notary.signature]" + testSignatureDigest + " (42 bytes)",
+	} {
+		if !strings.Contains(tree, expected) {
+			t.Fatalf("expected tree to contain %q, got\n%s", expected, tree)
+		}
+	}
+}
+
+func TestFlattenReferrers(t *testing.T) {
+	references := flattenReferrers(testReferrerGraphs()[0].Referrers)
+	if len(references) != 2 {
+		t.Fatalf("expected 2 references, got %d", len(references))
+	}
+	if references[0].Digest.String() != testSignatureDigest || references[1].Digest.String() != testSBOMDigest {
+		t.Fatalf("expected nested referrers to be listed first, got %v", references)
+	}
+}
+
+func TestSubjectFromArgs(t *testing.T) {
+	if subject, err := subjectFromArgs("", []string{testSubject}); err != nil || subject != testSubject {
+		t.Fatalf("expected subject from argument, got %s, %v", subject, err)
+	}
+	if subject, err := subjectFromArgs(testSubject, nil); err != nil || subject != testSubject {
+		t.Fatalf("expected subject from parameter, got %s, %v", subject, err)
+	}
+	if _, err := subjectFromArgs("registry.io/other:v1", []string{testSubject}); err == nil {
+		t.Fatalf("expected conflicting subjects to fail")
+	}
+}
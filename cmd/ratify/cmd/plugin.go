@@ -0,0 +1,182 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/deislabs/ratify/internal/version"
+	"github.com/spf13/cobra"
+)
+
+const (
+	pluginUse     = "plugin"
+	pluginInitUse = "init"
+
+	pluginTypeVerifier = "verifier"
+	pluginTypeStore    = "store"
+
+	pluginTemplatesDir = "plugintemplates"
+	pluginTemplateExt  = ".tmpl"
+)
+
+// pluginTemplates are the files of the generated plugin projects, the files
+// in common are generated for all plugin types
+//
+//go:embed plugintemplates
+var pluginTemplates embed.FS
+
+// pluginNamePattern restricts plugin names to names that are valid binary
+// names on all platforms
+var pluginNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+type pluginInitCmdOptions struct {
+	pluginType string
+	name       string
+	module     string
+	dir        string
+}
+
+// pluginTemplateData is the data the plugin templates are executed with
+type pluginTemplateData struct {
+	Name   string
+	Type   string
+	Module string
+	// RatifyVersion is the version of Ratify the plugin requires, the latest
+	// version is required by go mod tidy if it is empty
+	RatifyVersion string
+}
+
+func NewCmdPlugin(argv ...string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   pluginUse,
+		Short: "Manage external plugins",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+	cmd.AddCommand(NewCmdPluginInit(append(argv, pluginInitUse)...))
+	return cmd
+}
+
+func NewCmdPluginInit(argv ...string) *cobra.Command {
+	if len(argv) == 0 {
+		argv = []string{os.Args[0]}
+	}
+
+	eg := fmt.Sprintf(`  # Generate the project of a verifier plugin in ./mychecker
+  %s --type verifier --name mychecker --module github.com/myorg/mychecker`, strings.Join(argv, " "))
+
+	var opts pluginInitCmdOptions
+
+	cmd := &cobra.Command{
+		Use:     pluginInitUse,
+		Short:   "Generate the skeleton project of an external verifier or store plugin",
+		Example: eg,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pluginInit(opts)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVar(&opts.pluginType, "type", pluginTypeVerifier, "Type of the plugin, verifier or store")
+	flags.StringVar(&opts.name, "name", "", "Name of the plugin, the name of its binary")
+	flags.StringVar(&opts.module, "module", "", "Go module path of the plugin project, defaults to example.com/<name>")
+	flags.StringVar(&opts.dir, "dir", "", "Directory the project is generated in, defaults to ./<name>")
+	return cmd
+}
+
+func pluginInit(opts pluginInitCmdOptions) error {
+	if opts.pluginType != pluginTypeVerifier && opts.pluginType != pluginTypeStore {
+		return fmt.Errorf("unsupported plugin type %s, supported types are %s, %s", opts.pluginType, pluginTypeVerifier, pluginTypeStore)
+	}
+	if opts.name == "" {
+		return errors.New("name parameter is required")
+	}
+	if !pluginNamePattern.MatchString(opts.name) {
+		return fmt.Errorf("invalid plugin name %s, the name may contain letters, digits, '-' and '_'", opts.name)
+	}
+	if opts.module == "" {
+		opts.module = "example.com/" + opts.name
+	}
+	if opts.dir == "" {
+		opts.dir = opts.name
+	}
+
+	if entries, err := os.ReadDir(opts.dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", opts.dir)
+	}
+
+	data := pluginTemplateData{
+		Name:          opts.name,
+		Type:          opts.pluginType,
+		Module:        opts.module,
+		RatifyVersion: version.GitTag,
+	}
+	files, err := renderPluginTemplates(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(opts.dir, 0o755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(opts.dir, name), content, 0o644); err != nil { //nolint:gosec // the generated project is not sensitive
+			return err
+		}
+	}
+
+	fmt.Printf("generated %s plugin %s in %s, run make build in the directory to build it\n", opts.pluginType, opts.name, opts.dir)
+	return nil
+}
+
+// renderPluginTemplates returns the files of the project of the plugin keyed
+// by their names.
+func renderPluginTemplates(data pluginTemplateData) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, dir := range []string{"common", data.Type} {
+		templateDir := path.Join(pluginTemplatesDir, dir)
+		entries, err := fs.ReadDir(pluginTemplates, templateDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			tmpl, err := template.ParseFS(pluginTemplates, path.Join(templateDir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var content strings.Builder
+			if err := tmpl.Execute(&content, data); err != nil {
+				return nil, fmt.Errorf("failed to render %s: %w", entry.Name(), err)
+			}
+			files[strings.TrimSuffix(entry.Name(), pluginTemplateExt)] = []byte(content.String())
+		}
+	}
+	return files, nil
+}
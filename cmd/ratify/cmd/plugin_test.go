@@ -0,0 +1,92 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPluginInit_GeneratesProject(t *testing.T) {
+	for _, pluginType := range []string{pluginTypeVerifier, pluginTypeStore} {
+		t.Run(pluginType, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "mychecker")
+			opts := pluginInitCmdOptions{
+				pluginType: pluginType,
+				name:       "mychecker",
+				module:     "github.com/myorg/mychecker",
+				dir:        dir,
+			}
+			if err := pluginInit(opts); err != nil {
+				t.Fatalf("pluginInit() error = %v", err)
+			}
+
+			for _, name := range []string{"go.mod", "Makefile", "main.go", "main_test.go", "README.md"} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Fatalf("expected %s to be generated: %v", name, err)
+				}
+			}
+			goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(goMod), "module github.com/myorg/mychecker\n") {
+				t.Fatalf("unexpected go.mod %s", goMod)
+			}
+			for _, name := range []string{"main.go", "main_test.go"} {
+				if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.AllErrors); err != nil {
+					t.Fatalf("generated %s does not parse: %v", name, err)
+				}
+			}
+
+			if err := pluginInit(opts); err == nil {
+				t.Fatal("expected generating into a non-empty directory to fail")
+			}
+		})
+	}
+}
+
+func TestPluginInit_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts pluginInitCmdOptions
+	}{
+		{
+			name: "missing name",
+			opts: pluginInitCmdOptions{pluginType: pluginTypeVerifier},
+		},
+		{
+			name: "name with path separator",
+			opts: pluginInitCmdOptions{pluginType: pluginTypeVerifier, name: "../mychecker"},
+		},
+		{
+			name: "unsupported type",
+			opts: pluginInitCmdOptions{pluginType: "policy", name: "mychecker"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.dir = t.TempDir()
+			if err := pluginInit(tt.opts); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/deislabs/ratify/internal/version"
+	"github.com/deislabs/ratify/pkg/executor/types"
+	"github.com/deislabs/ratify/pkg/utils"
+)
+
+// output formats of the verify command
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputJUnit = "junit"
+	outputSARIF = "sarif"
+)
+
+var outputFormats = []string{outputJSON, outputTable, outputJUnit, outputSARIF}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	ratifyURI    = "https://ratify.dev"
+)
+
+// verifierRow is the result of a verifier for an artifact of the referrer
+// graph of the subject.
+type verifierRow struct {
+	artifact types.ArtifactReport
+	// depth is the level of the artifact below the subject, starting at 0
+	depth    int
+	verifier types.VerifierReport
+}
+
+// validateOutputFormat returns an error if the output format is not supported,
+// so that it is rejected before verifying the subject.
+func validateOutputFormat(format string) error {
+	for _, supported := range outputFormats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %s, supported formats are %s", format, strings.Join(outputFormats, ", "))
+}
+
+// printVerifyResult prints the result of verifying the subject in the given
+// output format.
+func printVerifyResult(w io.Writer, format string, subject string, result types.VerifyResult) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case outputTable:
+		return printTable(w, subject, result)
+	case outputJUnit:
+		return printJUnit(w, subject, result)
+	case outputSARIF:
+		return printSARIF(w, subject, result)
+	default:
+		return validateOutputFormat(format)
+	}
+}
+
+// verifierRows flattens the artifact reports of the result in the order of
+// the referrer graph, nested artifacts follow the artifact they are attached
+// to.
+func verifierRows(result types.VerifyResult) []verifierRow {
+	var rows []verifierRow
+	var add func(reports []types.ArtifactReport, depth int)
+	add = func(reports []types.ArtifactReport, depth int) {
+		for _, report := range reports {
+			for _, verifierReport := range report.VerifierReports {
+				rows = append(rows, verifierRow{artifact: report, depth: depth, verifier: verifierReport})
+			}
+			add(report.NestedReports, depth+1)
+		}
+	}
+	add(types.NewArtifactReports(result.VerifierReports), 0)
+	return rows
+}
+
+func resultString(isSuccess bool) string {
+	if isSuccess {
+		return "passed"
+	}
+	return "failed"
+}
+
+// artifactName returns the digest of the artifact, or the subject if the
+// verification did not reach an artifact.
+func artifactName(report types.ArtifactReport) string {
+	if report.ArtifactDigest != "" {
+		return report.ArtifactDigest
+	}
+	return report.Subject
+}
+
+// artifactReference returns the reference of the artifact in the repository
+// of the subject.
+func artifactReference(subject string, report types.ArtifactReport) string {
+	ref, err := utils.ParseSubjectReference(subject)
+	if err != nil || report.ArtifactDigest == "" {
+		return subject
+	}
+	return fmt.Sprintf("%s@%s", ref.Path, report.ArtifactDigest)
+}
+
+func printTable(w io.Writer, subject string, result types.VerifyResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ARTIFACT\tARTIFACT TYPE\tVERIFIER\tRESULT\tMESSAGE")
+	for _, row := range verifierRows(result) {
+		message := row.verifier.Message
+		if row.verifier.ErrorCode != "" {
+			message = fmt.Sprintf("%s: %s", row.verifier.ErrorCode, message)
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\n", strings.Repeat("  ", row.depth), artifactName(row.artifact), row.artifact.ArtifactType, row.verifier.Name, resultString(row.verifier.IsSuccess), message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nverification of %s %s\n", subject, resultString(result.IsSuccess))
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+// printJUnit prints a test suite for the subject with a test case per
+// verifier and artifact. A subject failing the policy without any failed
+// verifier, e.g. because no artifacts are attached, is reported as a failed
+// policy test case.
+func printJUnit(w io.Writer, subject string, result types.VerifyResult) error {
+	suite := junitTestSuite{Name: subject}
+	for _, row := range verifierRows(result) {
+		testCase := junitTestCase{
+			Name:      row.verifier.Name,
+			ClassName: fmt.Sprintf("%s (%s)", artifactName(row.artifact), row.artifact.ArtifactType),
+		}
+		if !row.verifier.IsSuccess {
+			testCase.Failure = &junitFailure{Message: row.verifier.Message, Type: row.verifier.ErrorCode, Content: row.verifier.Message}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	if !result.IsSuccess && suite.Failures == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      "policy",
+			ClassName: subject,
+			Failure:   &junitFailure{Message: "subject failed the verification policy"},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Kind      string          `json:"kind"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// printSARIF prints a SARIF log with a rule per verifier and a result per
+// verifier and artifact, located at the reference of the artifact.
+func printSARIF(w io.Writer, subject string, result types.VerifyResult) error {
+	driver := sarifDriver{Name: "ratify", Version: version.GitTag, InformationURI: ratifyURI, Rules: []sarifRule{}}
+	rules := map[string]struct{}{}
+	results := []sarifResult{}
+	for _, row := range verifierRows(result) {
+		if _, ok := rules[row.verifier.Name]; !ok {
+			rules[row.verifier.Name] = struct{}{}
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:               row.verifier.Name,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("verification by the %s verifier", row.verifier.Name)},
+			})
+		}
+		sarifResult := sarifResult{
+			RuleID:  row.verifier.Name,
+			Kind:    "pass",
+			Level:   "none",
+			Message: sarifMessage{Text: row.verifier.Message},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				Name:               artifactName(row.artifact),
+				FullyQualifiedName: artifactReference(subject, row.artifact),
+				Kind:               "resource",
+			}}}},
+		}
+		if !row.verifier.IsSuccess {
+			sarifResult.Kind = "fail"
+			sarifResult.Level = "error"
+		}
+		if sarifResult.Message.Text == "" {
+			sarifResult.Message.Text = fmt.Sprintf("%s verification %s", row.verifier.Name, resultString(row.verifier.IsSuccess))
+		}
+		results = append(results, sarifResult)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
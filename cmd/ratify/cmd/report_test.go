@@ -0,0 +1,123 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/types"
+	"github.com/deislabs/ratify/pkg/verifier"
+)
+
+const (
+	testSubject         = "registry.io/app@sha256:b556844e6e59451caf4429eb1934a2d7c7d3b8c2b2c0de7e0c0a27bbc4ec1e1b"
+	testSignatureDigest = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	testSBOMDigest      = "sha256:60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+)
+
+var testVerifyResult = types.VerifyResult{
+	VerifierReports: []interface{}{
+		verifier.VerifierResult{Subject: testSubject, Name: "notation", IsSuccess: true, ArtifactType: "application/vnd.cncf.notary.signature", ReferenceDigest: testSignatureDigest},
+		verifier.VerifierResult{Subject: testSubject, Name: "sbom", Message: "license GPL is not allowed", ErrorCode: "VERIFY_REFERENCE_FAILURE", ArtifactType: "application/spdx+json", ReferenceDigest: testSBOMDigest},
+	},
+}
+
+func TestPrintVerifyResult_Table(t *testing.T) {
+	var out bytes.Buffer
+	if err := printVerifyResult(&out, outputTable, testSubject, testVerifyResult); err != nil {
+		t.Fatalf("printVerifyResult() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header, 2 rows and a summary, got %q", out.String())
+	}
+	if !strings.Contains(lines[2], "sbom") || !strings.Contains(lines[2], "failed") || !strings.Contains(lines[2], "VERIFY_REFERENCE_FAILURE: license GPL is not allowed") {
+		t.Fatalf("unexpected row %q", lines[2])
+	}
+	if lines[4] != "verification of "+testSubject+" failed" {
+		t.Fatalf("unexpected summary %q", lines[4])
+	}
+}
+
+func TestPrintVerifyResult_JUnit(t *testing.T) {
+	var out bytes.Buffer
+	if err := printVerifyResult(&out, outputJUnit, testSubject, testVerifyResult); err != nil {
+		t.Fatalf("printVerifyResult() error = %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("failed to parse JUnit report: %v", err)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 1 {
+		t.Fatalf("expected 2 tests with 1 failure, got %+v", suites)
+	}
+	failed := suites.Suites[0].Cases[1]
+	if failed.Name != "sbom" || failed.Failure == nil || failed.Failure.Type != "VERIFY_REFERENCE_FAILURE" {
+		t.Fatalf("unexpected failed test case %+v", failed)
+	}
+}
+
+func TestPrintVerifyResult_JUnitPolicyFailure(t *testing.T) {
+	var out bytes.Buffer
+	if err := printVerifyResult(&out, outputJUnit, testSubject, types.VerifyResult{}); err != nil {
+		t.Fatalf("printVerifyResult() error = %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("failed to parse JUnit report: %v", err)
+	}
+	if suites.Failures != 1 || suites.Suites[0].Cases[0].Name != "policy" {
+		t.Fatalf("expected a failed policy test case, got %+v", suites)
+	}
+}
+
+func TestPrintVerifyResult_SARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := printVerifyResult(&out, outputSARIF, testSubject, testVerifyResult); err != nil {
+		t.Fatalf("printVerifyResult() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("failed to parse SARIF log: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("expected 2 rules and 2 results, got %+v", run)
+	}
+	result := run.Results[1]
+	if result.RuleID != "sbom" || result.Kind != "fail" || result.Level != "error" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if location := result.Locations[0].LogicalLocations[0]; location.FullyQualifiedName != "registry.io/app@"+testSBOMDigest {
+		t.Fatalf("unexpected location %+v", location)
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	if err := validateOutputFormat(outputSARIF); err != nil {
+		t.Fatalf("expected sarif to be supported, got %v", err)
+	}
+	if err := validateOutputFormat("yaml"); err == nil {
+		t.Fatalf("expected yaml to be unsupported")
+	}
+}
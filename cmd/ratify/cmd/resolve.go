@@ -37,6 +37,10 @@ const (
 type resolveCmdOptions struct {
 	configFilePath string
 	subject        string
+	mutationStores []string
+	digestOnly     bool
+	platform       string
+	digested       string
 }
 
 func NewCmdResolve(argv ...string) *cobra.Command {
@@ -45,16 +49,33 @@ func NewCmdResolve(argv ...string) *cobra.Command {
 	}
 
 	eg := fmt.Sprintf(`  # Resolve digest of a subject that is referenced by a tag
-  %s resolve -c ./config.yaml -s myregistry/myrepo:v1`, strings.Join(argv, " "))
+  %[1]s resolve -c ./config.yaml -s myregistry/myrepo:v1
+
+  # Preview the reference the mutation endpoint replaces a tagged reference with
+  %[1]s resolve -c ./config.yaml myregistry/myrepo:v1 --mutation-store oras
+
+  # Check the tag of a pinned subject still points at its digest
+  %[1]s resolve -c ./config.yaml myregistry/myrepo:v1@sha256:... --digested-references verify-tag
+
+  # Fall back to the store of the upstream registry if the store of the mirror fails
+  %[1]s resolve -c ./config.yaml myregistry/myrepo:v1 --mutation-store mirror,upstream
+
+  # Resolve the manifest of a platform in a multi-arch image
+  %[1]s resolve -c ./config.yaml myregistry/myrepo:v1 --platform linux/arm64`, strings.Join(argv, " "))
 
 	var opts resolveCmdOptions
 
 	cmd := &cobra.Command{
-		Use:     resolveUse,
-		Short:   "Resolve digest of a subject that is referenced by a tag",
+		Use:     resolveUse + " [subject]",
+		Short:   "Resolve digest of a subject that is referenced by a tag, as the mutation endpoint does",
 		Example: eg,
-		Args:    cobra.NoArgs,
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			subject, err := subjectFromArgs(opts.subject, args)
+			if err != nil {
+				return err
+			}
+			opts.subject = subject
 			return resolve(opts)
 		},
 	}
@@ -63,6 +84,10 @@ func NewCmdResolve(argv ...string) *cobra.Command {
 
 	flags.StringVarP(&opts.subject, "subject", "s", "", "Subject Reference")
 	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
+	flags.StringSliceVar(&opts.mutationStores, "mutation-store", []string{su.DefaultMutationStoreName}, "Names of the stores resolving the tag in order, the next store is tried if a store fails, as used by the mutation endpoint configured with --mutation-stores")
+	flags.StringVar(&opts.platform, "platform", "", fmt.Sprintf("Platform os/arch[/variant], or %s for the current platform, whose manifest is resolved if the tag references an image index, as the mutation endpoint configured with --mutation-platform", nodePlatform))
+	flags.StringVar(&opts.digested, "digested-references", string(su.DigestedReferencesPassthrough), fmt.Sprintf("Handling of subjects already referenced by digest, as the mutation endpoint configured with --mutation-digested-references: %s, %s or %s", su.DigestedReferencesPassthrough, su.DigestedReferencesValidate, su.DigestedReferencesVerifyTag))
+	flags.BoolVar(&opts.digestOnly, "digest-only", false, "Print the digest only instead of the reference by digest")
 	return cmd
 }
 
@@ -71,7 +96,17 @@ func resolve(opts resolveCmdOptions) error {
 		return errors.New("subject parameter is required")
 	}
 
-	subRef, err := utils.ParseSubjectReference(opts.subject)
+	if _, err := utils.ParseSubjectReference(opts.subject); err != nil {
+		return err
+	}
+	if len(opts.mutationStores) == 0 {
+		return errors.New("at least one mutation store is required")
+	}
+	platform, err := parseMutationPlatform(opts.platform)
+	if err != nil {
+		return err
+	}
+	digested, err := su.ParseDigestedReferenceMode(opts.digested)
 	if err != nil {
 		return err
 	}
@@ -91,12 +126,25 @@ func resolve(opts resolveCmdOptions) error {
 		return err
 	}
 
-	result, err := su.ResolveSubjectDescriptor(context.Background(), &stores, subRef)
-
+	mutated, err := su.ResolveMutatedReference(context.Background(), stores, opts.subject, su.MutationOptions{
+		StoreName:          opts.mutationStores[0],
+		FallbackStoreNames: opts.mutationStores[1:],
+		Platform:           platform,
+		DigestedReferences: digested,
+	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(result.Digest)
+	if opts.digestOnly {
+		mutatedRef, err := utils.ParseSubjectReference(mutated)
+		if err != nil {
+			return err
+		}
+		fmt.Println(mutatedRef.Digest)
+		return nil
+	}
+
+	fmt.Println(mutated)
 	return nil
 }
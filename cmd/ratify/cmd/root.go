@@ -51,10 +51,14 @@ func New(use, short string) *cobra.Command {
 
 	root.AddCommand(NewCmdReferrer(use, referrerUse))
 	root.AddCommand(NewCmdVerify(use, verifyUse))
+	root.AddCommand(NewCmdVerifyWorkload(use, verifyWorkloadUse))
 	root.AddCommand(NewCmdServe(use, serveUse))
 	root.AddCommand(NewCmdDiscover(use, discoverUse))
 	root.AddCommand(NewCmdVersion(use, versionUse))
 	root.AddCommand(NewCmdResolve(use, resolveUse))
+	root.AddCommand(NewCmdScenario(use, scenarioUse))
+	root.AddCommand(NewCmdCheckConfig(use, checkConfigUse))
+	root.AddCommand(NewCmdPlugin(use, pluginUse))
 
 	root.PersistentFlags().BoolVarP(&enableDebug, "debug", "d", false, "Enable debug mode. If enabled, set logger level to debug")
 	return root
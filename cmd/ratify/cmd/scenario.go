@@ -0,0 +1,114 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/deislabs/ratify/config"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/scenario"
+	"github.com/deislabs/ratify/pkg/testregistry"
+	"github.com/spf13/cobra"
+)
+
+const (
+	scenarioUse = "scenario"
+)
+
+type scenarioCmdOptions struct {
+	configFilePath string
+	serverURL      string
+}
+
+func NewCmdScenario(_ ...string) *cobra.Command {
+	var opts scenarioCmdOptions
+
+	cmd := &cobra.Command{
+		Use:   scenarioUse + " FILE...",
+		Short: "Run verification scenarios described in YAML files against an embedded registry",
+		Long: `Run verification scenarios described in YAML files. The subject and the artifacts of each scenario are pushed to an embedded registry and the subject is verified in process with the given config, or by a running Ratify server.
+The referrer store must set useHttp to true to reach the embedded registry.`,
+		Example: "ratify scenario -c config.json scenarios.yaml",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScenarios(opts, args)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
+	flags.StringVar(&opts.serverURL, "server", "", "URL of a running Ratify server to verify with instead of the config, e.g. http://localhost:6001")
+	return cmd
+}
+
+func runScenarios(opts scenarioCmdOptions, files []string) error {
+	var scenarios []scenario.Scenario
+	for _, file := range files {
+		loaded, err := scenario.Load(file)
+		if err != nil {
+			return err
+		}
+		scenarios = append(scenarios, loaded...)
+	}
+
+	var target scenario.Target
+	if opts.serverURL != "" {
+		target = scenario.ServerTarget{URL: opts.serverURL}
+	} else {
+		cf, err := config.Load(opts.configFilePath)
+		if err != nil {
+			return err
+		}
+		if err := logger.InitLogConfig(cf.LoggerConfig); err != nil {
+			return err
+		}
+		executor, err := newExecutor(cf)
+		if err != nil {
+			return err
+		}
+		target = scenario.ExecutorTarget{Executor: executor}
+	}
+
+	registry := testregistry.New()
+	defer registry.Close()
+
+	failed := 0
+	for _, s := range scenarios {
+		outcome, err := scenario.Run(context.Background(), registry, target, s)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("ERROR %s: %v\n", s.Name, err)
+		case outcome.Passed:
+			fmt.Printf("PASS  %s\n", s.Name)
+		default:
+			failed++
+			fmt.Printf("FAIL  %s\n", s.Name)
+			for _, failure := range outcome.Failures {
+				fmt.Printf("      %s\n", failure)
+			}
+		}
+	}
+
+	fmt.Printf("%d/%d scenarios passed\n", len(scenarios)-failed, len(scenarios))
+	if failed > 0 {
+		return fmt.Errorf("%d scenarios failed", failed)
+	}
+	return nil
+}
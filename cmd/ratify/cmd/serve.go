@@ -18,24 +18,36 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
+	"os"
 	"time"
 
 	"github.com/deislabs/ratify/config"
 	"github.com/deislabs/ratify/httpserver"
 	"github.com/deislabs/ratify/internal/logger"
 	"github.com/deislabs/ratify/pkg/cache"
+	"github.com/deislabs/ratify/pkg/distributedlock"
+	"github.com/deislabs/ratify/pkg/events"
 	"github.com/deislabs/ratify/pkg/manager"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/deislabs/ratify/pkg/preflight"
+	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
+	"github.com/deislabs/ratify/pkg/selfverify"
+	"github.com/deislabs/ratify/pkg/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 const (
 	serveUse = "serve"
+	// devServerAddress is the HTTP address in development mode if not set
+	devServerAddress = "127.0.0.1:6001"
 )
 
 type serveCmdOptions struct {
 	configFilePath    string
 	httpServerAddress string
+	grpcAddress       string
 	certDirectory     string
 	caCertFile        string
 	enableCrdManager  bool
@@ -47,7 +59,42 @@ type serveCmdOptions struct {
 	metricsEnabled    bool
 	metricsType       string
 	metricsPort       int
+	metricsEndpoint   string
+	metricsInsecure   bool
+	metricsInterval   time.Duration
 	healthPort        string
+	rateLimit         float64
+	rateLimitBurst    int
+	allowedClients    []string
+	adminClients      []string
+	enablePins        bool
+	allowOverrides    bool
+	checkRegistries   bool
+	checkKeyProviders bool
+	maxRequestBytes   int64
+	maxRequestKeys    int
+	maxContentBytes   int64
+	maxConcurrentKeys int
+	maxVerifications  int
+	maxQueuedKeys     int
+	reportSigningKey  string
+	preflight         bool
+	canaryImage       string
+	preflightTimeout  time.Duration
+	dev               bool
+	tracingEndpoint   string
+	tracingInsecure   bool
+	tracingRatio      float64
+	denialEvents      bool
+	eventsNamespace   string
+	auditLog          string
+	mutationPlatform  string
+	mutationDigested  string
+	mutationStores    []string
+	lockType          string
+	lockName          string
+	lockTTL           time.Duration
+	drainTimeout      time.Duration
 }
 
 func NewCmdServe(_ ...string) *cobra.Command {
@@ -66,6 +113,7 @@ func NewCmdServe(_ ...string) *cobra.Command {
 	flags := cmd.Flags()
 
 	flags.StringVar(&opts.httpServerAddress, "http", "", "HTTP Address")
+	flags.StringVar(&opts.grpcAddress, "grpc-address", "", "Address of the gRPC verification service served alongside the HTTP server, the service is disabled if empty")
 	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
 	flags.StringVar(&opts.certDirectory, "cert-dir", "", "Path to ratify certs")
 	flags.StringVar(&opts.caCertFile, "ca-cert-file", "", "Path to CA cert file")
@@ -78,11 +126,124 @@ func NewCmdServe(_ ...string) *cobra.Command {
 	flags.BoolVar(&opts.metricsEnabled, "metrics-enabled", false, "Enable metrics exporter if enabled (default: false)")
 	flags.StringVar(&opts.metricsType, "metrics-type", httpserver.DefaultMetricsType, fmt.Sprintf("Metrics exporter type to use (default: %s)", httpserver.DefaultMetricsType))
 	flags.IntVar(&opts.metricsPort, "metrics-port", httpserver.DefaultMetricsPort, fmt.Sprintf("Metrics exporter port to use (default: %d)", httpserver.DefaultMetricsPort))
+	flags.StringVar(&opts.metricsEndpoint, "metrics-endpoint", "", "Address of the receiver the otlp and statsd metrics exporters push the metrics to: the OTLP gRPC receiver or the StatsD agent")
+	flags.BoolVar(&opts.metricsInsecure, "metrics-insecure", false, "Push metrics to the OTLP receiver without TLS (default: false)")
+	flags.DurationVar(&opts.metricsInterval, "metrics-push-interval", metrics.DefaultPushInterval, fmt.Sprintf("Interval at which the otlp and statsd metrics exporters push the metrics (default: %fs)", metrics.DefaultPushInterval.Seconds()))
 	flags.StringVar(&opts.healthPort, "health-port", httpserver.DefaultHealthPort, fmt.Sprintf("Health port to use (default: %s)", httpserver.DefaultHealthPort))
+	flags.Float64Var(&opts.rateLimit, "rate-limit", 0, "Requests per second each client may send to the REST endpoints not called by Gatekeeper, 0 disables rate limiting (default: 0)")
+	flags.IntVar(&opts.rateLimitBurst, "rate-limit-burst", 0, "Requests each client may send at once to the rate limited endpoints (default: rate-limit rounded up)")
+	flags.StringSliceVar(&opts.allowedClients, "allowed-client-names", nil, "Patterns of the common name or a subject alternative name of the client certificates allowed to call the verify and mutate endpoints, requires --ca-cert-file (default: any client certificate issued by the CA)")
+	flags.StringSliceVar(&opts.adminClients, "admin-client-names", nil, "Patterns of the common name or a subject alternative name of the client certificates allowed to call the admin endpoints such as preheat and pins, requires --ca-cert-file (default: admin endpoints reject all requests)")
+	flags.BoolVar(&opts.enablePins, "enable-pins", false, "Serve the admin API pinning digests approved without verification, pins are kept in memory and require a single replica (default: false)")
+	flags.BoolVar(&opts.allowOverrides, "allow-policy-overrides", false, "Allow admin clients to override the configured policy in requests to the verify endpoint of the REST API (default: false)")
+	flags.BoolVar(&opts.checkRegistries, "readiness-check-registries", false, "Report the server as not ready while a referrer store fails to connect to a registry (default: false)")
+	flags.BoolVar(&opts.checkKeyProviders, "readiness-check-key-providers", false, "Report the server as not ready while the last fetch of a key management provider failed (default: false)")
+	flags.Int64Var(&opts.maxRequestBytes, "max-request-bytes", 0, "Maximum size in bytes of the request body sent by Gatekeeper, to verify-workload or to the REST API, 0 disables the limit (default: 0)")
+	flags.IntVar(&opts.maxRequestKeys, "max-request-keys", 0, "Maximum number of keys of a request sent by Gatekeeper, 0 disables the limit (default: 0)")
+	flags.Int64Var(&opts.maxContentBytes, "max-content-bytes", httpserver.DefaultMaxContentBytes, fmt.Sprintf("Maximum size in bytes of the request body of the verify-content endpoint (default: %d)", httpserver.DefaultMaxContentBytes))
+	flags.IntVar(&opts.maxConcurrentKeys, "max-concurrent-request-keys", 0, "Maximum number of keys of a request sent by Gatekeeper verified at the same time, 0 disables the limit (default: 0)")
+	flags.IntVar(&opts.maxVerifications, "max-concurrent-verifications", 0, "Maximum number of subjects of all admission or all audit verify requests verified at the same time, 0 disables the limit (default: 0)")
+	flags.IntVar(&opts.maxQueuedKeys, "max-queued-verifications", 0, "Number of admission or audit subjects waiting to be verified above which verify requests of the same class are rejected with 503, 0 disables the limit (default: 0)")
+	flags.StringVar(&opts.reportSigningKey, "report-signing-key", "", "Path to a PEM encoded RSA or ECDSA private key signing the digests of verification reports in the response headers")
+	flags.BoolVar(&opts.preflight, "preflight", false, "Validate the configuration, probe stores and key providers, verify the canary image and exit with a status code instead of serving (default: false)")
+	flags.StringVar(&opts.canaryImage, "preflight-canary-image", "", "Image that must pass the configured policy in preflight mode, stores are not probed if empty")
+	flags.BoolVar(&opts.dev, "dev", false, fmt.Sprintf("Development mode: serve without TLS on localhost (default address: %s), reload on changes of the config file, trust material and plugins, and print each verification report to stdout (default: false)", devServerAddress))
+	flags.BoolVar(&opts.denialEvents, "denial-events", false, "Record a Kubernetes Event for each subject failing verification in an admission request (default: false)")
+	flags.StringVar(&opts.eventsNamespace, "denial-events-namespace", "", "Namespace the denial events are recorded in, the events are recorded on the Ratify pod if empty")
+	flags.StringVar(&opts.auditLog, "audit-log", "", fmt.Sprintf("Append-only log of the verification decisions: %s, an http(s) URL the decisions are posted to, or the path of a file, decisions are not logged if empty", httpserver.AuditSinkStdout))
+	flags.StringSliceVar(&opts.mutationStores, "mutation-stores", []string{su.DefaultMutationStoreName}, "Names of the stores the mutation endpoint resolves tags with, in order: the next store is tried if a store fails, e.g. the store of the upstream registry after the store of a mirror")
+	flags.StringVar(&opts.mutationPlatform, "mutation-platform", "", fmt.Sprintf("Platform os/arch[/variant], or %s for the platform Ratify runs on, whose manifest the mutation endpoint resolves tags of image indexes to, the index digest is kept if empty", nodePlatform))
+	flags.StringVar(&opts.mutationDigested, "mutation-digested-references", string(su.DigestedReferencesPassthrough), fmt.Sprintf("Handling of images already referenced by digest by the mutation endpoint: %s returns them as is, %s fails images whose digest is not found, %s also fails images pinned to a tag and a digest whose tag points at another digest", su.DigestedReferencesPassthrough, su.DigestedReferencesValidate, su.DigestedReferencesVerifyTag))
+	flags.StringVar(&opts.tracingEndpoint, "tracing-endpoint", "", "Address of the OTLP gRPC collector receiving the traces of verification requests, tracing is disabled if empty")
+	flags.BoolVar(&opts.tracingInsecure, "tracing-insecure", false, "Export traces to the collector without TLS (default: false)")
+	flags.Float64Var(&opts.tracingRatio, "tracing-sample-ratio", tracing.DefaultSampleRatio, fmt.Sprintf("Ratio of the requests that are traced, between 0 and 1 (default: %v)", tracing.DefaultSampleRatio))
+	flags.StringVar(&opts.lockType, "distributed-lock-type", "", fmt.Sprintf("Lock shared by the replicas so that a subject is verified by one replica at a time while the others wait for its result in the shared cache: %s (a Dapr lock store) or %s (Kubernetes Leases), subjects are locked within each replica only if empty", distributedlock.TypeDapr, distributedlock.TypeLease))
+	flags.StringVar(&opts.lockName, "distributed-lock-name", distributedlock.DefaultName, fmt.Sprintf("Name of the Dapr lock store of the %s distributed lock (default: %s)", distributedlock.TypeDapr, distributedlock.DefaultName))
+	flags.DurationVar(&opts.lockTTL, "distributed-lock-ttl", distributedlock.DefaultTTL, fmt.Sprintf("Time after which a distributed lock that was not released, e.g. by a crashed replica, expires (default: %fs)", distributedlock.DefaultTTL.Seconds()))
+	flags.DurationVar(&opts.drainTimeout, "drain-timeout", httpserver.DefaultDrainTimeout, fmt.Sprintf("Time in-flight verification requests are given to complete on shutdown, new requests are rejected as soon as the server receives SIGTERM (default: %fs)", httpserver.DefaultDrainTimeout.Seconds()))
+	flags.DurationVar(&opts.preflightTimeout, "preflight-timeout", preflight.DefaultTimeout, fmt.Sprintf("Timeout of each probe in preflight mode (default: %fs)", preflight.DefaultTimeout.Seconds()))
 	return cmd
 }
 
 func serve(opts serveCmdOptions) error {
+	if opts.dev {
+		if err := applyDevOptions(&opts); err != nil {
+			return err
+		}
+	}
+	rateLimit := httpserver.RateLimitConfig{
+		RequestsPerSecond: opts.rateLimit,
+		Burst:             opts.rateLimitBurst,
+	}
+	clientAuth := httpserver.ClientAuthConfig{
+		AllowedNames: opts.allowedClients,
+	}
+	if err := clientAuth.Validate(opts.caCertFile); err != nil {
+		return err
+	}
+	admin := httpserver.AdminConfig{
+		Names:                opts.adminClients,
+		EnablePins:           opts.enablePins,
+		AllowPolicyOverrides: opts.allowOverrides,
+	}
+	if err := admin.Validate(opts.caCertFile); err != nil {
+		return err
+	}
+	healthChecks := httpserver.HealthCheckConfig{
+		Registries:             opts.checkRegistries,
+		KeyManagementProviders: opts.checkKeyProviders,
+	}
+	requestLimit := httpserver.RequestLimitConfig{
+		MaxBodyBytes:               opts.maxRequestBytes,
+		MaxKeys:                    opts.maxRequestKeys,
+		MaxContentBytes:            opts.maxContentBytes,
+		MaxConcurrentKeys:          opts.maxConcurrentKeys,
+		MaxConcurrentVerifications: opts.maxVerifications,
+		MaxQueuedVerifications:     opts.maxQueuedKeys,
+	}
+	var denialRecorder httpserver.DenialRecorder
+	if opts.denialEvents {
+		recorder, err := events.NewRecorder(opts.eventsNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to initialize denial events: %w", err)
+		}
+		denialRecorder = recorder
+	}
+	mutationPlatform, err := parseMutationPlatform(opts.mutationPlatform)
+	if err != nil {
+		return err
+	}
+	mutationDigested, err := su.ParseDigestedReferenceMode(opts.mutationDigested)
+	if err != nil {
+		return err
+	}
+	if len(opts.mutationStores) == 0 {
+		return fmt.Errorf("at least one mutation store is required")
+	}
+	var distributedLock httpserver.DistributedLock
+	if opts.lockType != "" {
+		locker, err := distributedlock.New(opts.lockType, opts.lockName, opts.lockTTL)
+		if err != nil {
+			return fmt.Errorf("failed to initialize distributed lock: %w", err)
+		}
+		distributedLock = locker
+	}
+	auditSink, err := httpserver.NewAuditSink(opts.auditLog)
+	if err != nil {
+		return err
+	}
+	if auditSink != nil {
+		defer auditSink.Close()
+	}
+	metricsPush := metrics.PushConfig{
+		Endpoint: opts.metricsEndpoint,
+		Insecure: opts.metricsInsecure,
+		Interval: opts.metricsInterval,
+	}
+	reportSigner, err := httpserver.LoadReportSigningKey(opts.reportSigningKey)
+	if err != nil {
+		return err
+	}
 	if opts.cacheEnabled {
 		// initialize global cache of specified type
 		if _, err := cache.NewCacheProvider(context.TODO(), opts.cacheType, opts.cacheName, opts.cacheSize); err != nil {
@@ -98,12 +259,30 @@ func serve(opts serveCmdOptions) error {
 		return fmt.Errorf("failed to initialize logger configuration: %w", err)
 	}
 
+	if opts.preflight {
+		return runPreflight(opts)
+	}
+
+	shutdownTracing, err := tracing.InitTracing(context.Background(), tracing.Config{
+		Endpoint:    opts.tracingEndpoint,
+		Insecure:    opts.tracingInsecure,
+		SampleRatio: opts.tracingRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.Warnf("failed to flush traces: %v", err)
+		}
+	}()
+
 	// in crd mode, the manager gets latest store/verifier from crd and pass on to the http server
 	if opts.enableCrdManager {
 		certRotatorReady := make(chan struct{})
 		logrus.Infof("starting crd manager")
 		go manager.StartManager(certRotatorReady, opts.healthPort)
-		manager.StartServer(opts.httpServerAddress, opts.configFilePath, opts.certDirectory, opts.caCertFile, opts.cacheTTL, opts.metricsEnabled, opts.metricsType, opts.metricsPort, certRotatorReady)
+		manager.StartServer(opts.httpServerAddress, opts.configFilePath, opts.certDirectory, opts.caCertFile, opts.cacheTTL, opts.metricsEnabled, opts.metricsType, opts.metricsPort, metricsPush, rateLimit, clientAuth, admin, healthChecks, requestLimit, reportSigner, denialRecorder, auditSink, distributedLock, opts.mutationStores, mutationPlatform, mutationDigested, opts.grpcAddress, opts.drainTimeout, certRotatorReady)
 
 		return nil
 	}
@@ -112,12 +291,43 @@ func serve(opts serveCmdOptions) error {
 	if err != nil {
 		return err
 	}
+	cf, err := config.Load(opts.configFilePath)
+	if err != nil {
+		return err
+	}
+	if err := selfverify.Run(context.Background(), cf.SelfVerifyConfig, getExecutor(), config.GetPluginDirs(cf)); err != nil {
+		return err
+	}
+	if opts.dev {
+		if err := config.WatchForDevelopment(opts.configFilePath); err != nil {
+			return err
+		}
+	}
 
 	if opts.httpServerAddress != "" {
 		server, err := httpserver.NewServer(context.Background(), opts.httpServerAddress, getExecutor, opts.certDirectory, opts.caCertFile, opts.cacheTTL, opts.metricsEnabled, opts.metricsType, opts.metricsPort)
 		if err != nil {
 			return err
 		}
+		server.RateLimit = rateLimit
+		server.ClientAuth = clientAuth
+		server.Admin = admin
+		server.HealthChecks = healthChecks
+		server.RequestLimit = requestLimit
+		server.ReportSigner = reportSigner
+		server.GRPCAddress = opts.grpcAddress
+		server.MetricsPush = metricsPush
+		server.DenialRecorder = denialRecorder
+		server.AuditSink = auditSink
+		server.DistributedLock = distributedLock
+		server.DrainTimeout = opts.drainTimeout
+		server.MutationStoreName = opts.mutationStores[0]
+		server.MutationFallbackStoreNames = opts.mutationStores[1:]
+		server.MutationPlatform = mutationPlatform
+		server.MutationDigestedReferences = mutationDigested
+		if opts.dev {
+			server.ReportWriter = os.Stdout
+		}
 		logrus.Infof("starting server at" + opts.httpServerAddress)
 		if err := server.Run(nil); err != nil {
 			return err
@@ -126,3 +336,43 @@ func serve(opts serveCmdOptions) error {
 
 	return nil
 }
+
+// applyDevOptions serves without TLS on a loopback address in development mode,
+// the server is meant to be called by a local edit-verify loop only.
+func applyDevOptions(opts *serveCmdOptions) error {
+	if opts.enableCrdManager {
+		return fmt.Errorf("--dev is not supported with --enable-crd-manager, resources are reloaded by the manager")
+	}
+	if opts.httpServerAddress == "" {
+		opts.httpServerAddress = devServerAddress
+	}
+	host, _, err := net.SplitHostPort(opts.httpServerAddress)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP address %s: %w", opts.httpServerAddress, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("--dev serves without TLS and requires a loopback HTTP address, got %s", opts.httpServerAddress)
+	}
+	if opts.certDirectory != "" || opts.caCertFile != "" {
+		logrus.Warnf("--dev serves without TLS, ignoring the cert directory and CA cert file")
+		opts.certDirectory = ""
+		opts.caCertFile = ""
+	}
+	return nil
+}
+
+// runPreflight prints the preflight report and exits with its status code so
+// that an init container gates the rollout on a healthy configuration.
+func runPreflight(opts serveCmdOptions) error {
+	report := manager.Preflight(context.Background(), opts.configFilePath, opts.enableCrdManager, preflight.Options{
+		CanaryImage: opts.canaryImage,
+		Timeout:     opts.preflightTimeout,
+	})
+	if err := PrintJSON(report); err != nil {
+		return err
+	}
+	if !report.Success {
+		os.Exit(report.ExitCode)
+	}
+	return nil
+}
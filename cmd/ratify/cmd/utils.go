@@ -17,9 +17,19 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"runtime"
+	"strings"
+
+	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// nodePlatform selects the platform Ratify runs on as mutation platform
+const nodePlatform = "node"
+
 const taggedReferenceWarning = "Warning: Digest should be used instead of tagged reference. The resolved digest may not point to the same signed artifact, since tags are mutable."
 
 func PrintJSON(object interface{}) error {
@@ -28,3 +38,45 @@ func PrintJSON(object interface{}) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(object)
 }
+
+// subjectFromArgs returns the subject given as argument or by the subject
+// parameter.
+func subjectFromArgs(subject string, args []string) (string, error) {
+	if len(args) == 0 {
+		return subject, nil
+	}
+	if subject != "" && subject != args[0] {
+		return "", errors.New("subject must be given either as argument or by the subject parameter")
+	}
+	return args[0], nil
+}
+
+// parseAnnotationFilters parses the key=value annotation filters of referrers,
+// a key without value matches any value of the annotation.
+func parseAnnotationFilters(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	annotations := make(map[string]string, len(values))
+	for _, value := range values {
+		key, annotation, _ := strings.Cut(value, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid annotation filter %q, expected key=value", value)
+		}
+		annotations[key] = annotation
+	}
+	return annotations, nil
+}
+
+// parseMutationPlatform parses the platform whose manifest tags of image
+// indexes are resolved to, indexes are kept if the platform is empty.
+func parseMutationPlatform(platform string) (*oci.Platform, error) {
+	switch platform {
+	case "":
+		return nil, nil
+	case nodePlatform:
+		return &oci.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}, nil
+	default:
+		return su.ParsePlatform(platform)
+	}
+}
@@ -19,10 +19,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/deislabs/ratify/config"
 	"github.com/deislabs/ratify/internal/constants"
 	"github.com/deislabs/ratify/internal/logger"
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
 	e "github.com/deislabs/ratify/pkg/executor"
 	ef "github.com/deislabs/ratify/pkg/executor/core"
 	pf "github.com/deislabs/ratify/pkg/policyprovider/factory"
@@ -40,18 +44,30 @@ type verifyCmdOptions struct {
 	configFilePath string
 	subject        string
 	artifactTypes  []string
+	annotations    []string
 	silentMode     bool
+	time           string
+	output         string
 }
 
 func NewCmdVerify(_ ...string) *cobra.Command {
 	var opts verifyCmdOptions
 
 	cmd := &cobra.Command{
-		Use:     verifyUse,
-		Short:   "Verify a subject",
-		Example: "sample example",
-		Args:    cobra.NoArgs,
+		Use:   verifyUse + " [subject]",
+		Short: "Verify a subject, fails if the subject fails the verification policy",
+		Example: `  # Verify a subject and print a table of the verifier results
+  ratify verify -c ./config.json myregistry.azurecr.io/app@sha256:... -o table
+
+  # Write a JUnit report of the verification in a CI pipeline
+  ratify verify -c ./config.json -s myregistry.azurecr.io/app@sha256:... -o junit > ratify.xml`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			subject, err := subjectFromArgs(opts.subject, args)
+			if err != nil {
+				return err
+			}
+			opts.subject = subject
 			return verify(opts)
 		},
 	}
@@ -61,7 +77,10 @@ func NewCmdVerify(_ ...string) *cobra.Command {
 	flags.StringVarP(&opts.subject, "subject", "s", "", "Subject Reference")
 	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
 	flags.StringArrayVarP(&opts.artifactTypes, "artifactType", "t", nil, "artifact type to filter")
+	flags.StringArrayVar(&opts.annotations, "annotation", nil, "annotation key=value the referrers must have, a key without value matches any value")
 	flags.BoolVar(&opts.silentMode, "silent", false, "Silent output")
+	flags.StringVar(&opts.time, "time", "", "Verify as of the RFC3339 timestamp instead of the current time")
+	flags.StringVarP(&opts.output, "output", "o", outputJSON, fmt.Sprintf("Output format of the verification result, one of %s", strings.Join(outputFormats, ", ")))
 	return cmd
 }
 
@@ -76,13 +95,32 @@ func verify(opts verifyCmdOptions) error {
 		return errors.New("subject parameter is required")
 	}
 
+	if err := validateOutputFormat(opts.output); err != nil {
+		return err
+	}
+
 	subRef, err := utils.ParseSubjectReference(opts.subject)
 	if err != nil {
 		return err
 	}
 
 	if subRef.Digest == "" {
-		fmt.Println(taggedReferenceWarning)
+		// the warning is not part of the output, which may be parsed
+		fmt.Fprintln(os.Stderr, taggedReferenceWarning)
+	}
+
+	var verificationTime *time.Time
+	if opts.time != "" {
+		t, err := time.Parse(time.RFC3339, opts.time)
+		if err != nil {
+			return fmt.Errorf("invalid time parameter %s: %w", opts.time, err)
+		}
+		verificationTime = &t
+	}
+
+	annotations, err := parseAnnotationFilters(opts.annotations)
+	if err != nil {
+		return err
 	}
 
 	cf, err := config.Load(opts.configFilePath)
@@ -94,45 +132,59 @@ func verify(opts verifyCmdOptions) error {
 		return err
 	}
 
-	stores, err := sf.CreateStoresFromConfig(cf.StoresConfig, config.GetDefaultPluginPath())
-
+	executor, err := newExecutor(cf)
 	if err != nil {
 		return err
 	}
 
-	verifiers, err := vf.CreateVerifiersFromConfig(cf.VerifiersConfig, config.GetDefaultPluginPath(), constants.EmptyNamespace)
-
-	if err != nil {
-		return err
+	verifyParameters := e.VerifyParameters{
+		Subject:              opts.subject,
+		ReferenceTypes:       opts.artifactTypes,
+		ReferenceAnnotations: annotations,
+		VerificationTime:     verificationTime,
 	}
 
-	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(cf.PoliciesConfig)
+	result, err := executor.VerifySubject(context.Background(), verifyParameters)
 
 	if err != nil {
 		return err
 	}
 
-	executor := ef.Executor{
-		Verifiers:      verifiers,
-		ReferrerStores: stores,
-		PolicyEnforcer: policyEnforcer,
-		Config:         &cf.ExecutorConfig,
+	if !opts.silentMode {
+		if err := printVerifyResult(os.Stdout, opts.output, opts.subject, result); err != nil {
+			return err
+		}
 	}
 
-	verifyParameters := e.VerifyParameters{
-		Subject:        opts.subject,
-		ReferenceTypes: opts.artifactTypes,
+	if !result.IsSuccess {
+		return fmt.Errorf("subject %s failed verification", opts.subject)
 	}
+	return nil
+}
 
-	result, err := executor.VerifySubject(context.Background(), verifyParameters)
+// newExecutor creates an executor from the stores, verifiers and policy of the configuration
+func newExecutor(cf config.Config) (*ef.Executor, error) {
+	pluginCommon.ConfigurePool(cf.ExecutorConfig.PluginPool)
 
+	stores, err := sf.CreateStoresFromConfig(cf.StoresConfig, config.GetDefaultPluginPath())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !opts.silentMode {
-		return PrintJSON(result)
+	verifiers, err := vf.CreateVerifiersFromConfig(cf.VerifiersConfig, config.GetDefaultPluginPath(), constants.EmptyNamespace)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(cf.PoliciesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ef.Executor{
+		Verifiers:      verifiers,
+		ReferrerStores: stores,
+		PolicyEnforcer: policyEnforcer,
+		Config:         &cf.ExecutorConfig,
+	}, nil
 }
@@ -0,0 +1,115 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deislabs/ratify/config"
+	"github.com/deislabs/ratify/internal/logger"
+	e "github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/workload"
+	"github.com/spf13/cobra"
+)
+
+const (
+	verifyWorkloadUse = "verify-workload"
+)
+
+type verifyWorkloadCmdOptions struct {
+	configFilePath string
+	manifestPath   string
+	silentMode     bool
+}
+
+func NewCmdVerifyWorkload(argv ...string) *cobra.Command {
+	if len(argv) == 0 {
+		argv = []string{os.Args[0]}
+	}
+
+	eg := fmt.Sprintf(`  # Verify the images of all containers of a Deployment
+  %s verify-workload -c ./config.yaml -f ./deployment.yaml`, strings.Join(argv, " "))
+
+	var opts verifyWorkloadCmdOptions
+
+	cmd := &cobra.Command{
+		Use:     verifyWorkloadUse,
+		Short:   "Verify the images of the workloads in a manifest, fails if any image fails verification",
+		Example: eg,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyWorkload(opts)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&opts.manifestPath, "file", "f", "", "Workload manifest file path, e.g. of a Deployment, StatefulSet or Job")
+	flags.StringVarP(&opts.configFilePath, "config", "c", "", "Config File Path")
+	flags.BoolVar(&opts.silentMode, "silent", false, "Silent output")
+	return cmd
+}
+
+func verifyWorkload(opts verifyWorkloadCmdOptions) error {
+	if opts.manifestPath == "" {
+		return errors.New("file parameter is required")
+	}
+
+	content, err := os.ReadFile(opts.manifestPath)
+	if err != nil {
+		return err
+	}
+	workloads, err := workload.Parse(content)
+	if err != nil {
+		return err
+	}
+
+	cf, err := config.Load(opts.configFilePath)
+	if err != nil {
+		return err
+	}
+
+	if err := logger.InitLogConfig(cf.LoggerConfig); err != nil {
+		return err
+	}
+
+	executor, err := newExecutor(cf)
+	if err != nil {
+		return err
+	}
+
+	summary := workload.Verify(context.Background(), workloads, func(ctx context.Context, _ workload.Workload, image string) (bool, interface{}, error) {
+		result, err := executor.VerifySubject(ctx, e.VerifyParameters{Subject: image})
+		if err != nil {
+			return false, nil, err
+		}
+		return result.IsSuccess, result, nil
+	})
+
+	if !opts.silentMode {
+		if err := PrintJSON(summary); err != nil {
+			return err
+		}
+	}
+	if !summary.IsSuccess {
+		return errors.New("workload verification failed")
+	}
+	return nil
+}
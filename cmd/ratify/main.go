@@ -25,6 +25,7 @@ import (
 	_ "github.com/deislabs/ratify/pkg/policyprovider/regopolicy"   // register regopolicy policy provider
 	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"          // register oras referrer store
 	_ "github.com/deislabs/ratify/pkg/verifier/notation"           // register notation verifier
+	_ "github.com/deislabs/ratify/pkg/verifier/static"             // register static verifier
 )
 
 func main() {
@@ -0,0 +1,170 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/deislabs/ratify/internal/constants"
+	pf "github.com/deislabs/ratify/pkg/policyprovider/factory"
+	rsConfig "github.com/deislabs/ratify/pkg/referrerstore/config"
+	sf "github.com/deislabs/ratify/pkg/referrerstore/factory"
+	vfConfig "github.com/deislabs/ratify/pkg/verifier/config"
+	vf "github.com/deislabs/ratify/pkg/verifier/factory"
+	"github.com/deislabs/ratify/pkg/verifier/types"
+)
+
+// components of the configuration reported by Check
+const (
+	CheckComponentConfig   = "config"
+	CheckComponentExecutor = "executor"
+	CheckComponentStore    = "store"
+	CheckComponentVerifier = "verifier"
+	CheckComponentPolicy   = "policy"
+)
+
+// ComponentCheck is the result of initializing a component of the
+// configuration.
+type ComponentCheck struct {
+	Component string `json:"component"`
+	// Name is the name of the plugin of a store or verifier, it is empty for
+	// checks of a whole section of the configuration.
+	Name     string   `json:"name,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Check loads the configuration file and initializes each store, verifier and
+// the policy provider without serving requests, so that a configuration can
+// be validated before it is rolled out. A failing component does not stop the
+// remaining components from being checked.
+func Check(configFilePath string) []ComponentCheck {
+	configFilePath = getConfigurationFile(configFilePath)
+	configCheck := ComponentCheck{Component: CheckComponentConfig, Name: configFilePath}
+	body, err := os.ReadFile(configFilePath)
+	if err != nil {
+		configCheck.Error = fmt.Sprintf("unable to read config file: %v", err)
+		return []ComponentCheck{configCheck}
+	}
+
+	problems, unknownFields := checkSchema(body)
+	for _, field := range unknownFields {
+		configCheck.Warnings = append(configCheck.Warnings, fmt.Sprintf("unknown field %s is ignored", field))
+	}
+	if len(problems) > 0 {
+		configCheck.Error = fmt.Sprintf("invalid config file: %s", strings.Join(problems, "; "))
+		return []ComponentCheck{configCheck}
+	}
+
+	expanded, err := expandReferences(body)
+	if err != nil {
+		configCheck.Error = fmt.Sprintf("unable to resolve references: %v", err)
+		return []ComponentCheck{configCheck}
+	}
+
+	var cf Config
+	if err := json.Unmarshal(expanded, &cf); err != nil {
+		configCheck.Error = fmt.Sprintf("unable to unmarshal config body: %v", err)
+		return []ComponentCheck{configCheck}
+	}
+
+	checks := []ComponentCheck{configCheck}
+	checks = append(checks, newComponentCheck(CheckComponentExecutor, "", cf.ExecutorConfig.Validate()))
+	checks = append(checks, checkStores(cf.StoresConfig)...)
+	checks = append(checks, checkVerifiers(cf.VerifiersConfig)...)
+	_, err = pf.CreatePolicyProviderFromConfig(cf.PoliciesConfig)
+	checks = append(checks, newComponentCheck(CheckComponentPolicy, pluginName(cf.PoliciesConfig.PolicyPlugin), err))
+	return checks
+}
+
+// CheckFailed returns true if any of the checks failed.
+func CheckFailed(checks []ComponentCheck) bool {
+	for _, check := range checks {
+		if check.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func newComponentCheck(component string, name string, err error) ComponentCheck {
+	check := ComponentCheck{Component: component, Name: name}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// pluginName returns the name of the plugin of a component, empty if the
+// name is missing.
+func pluginName[T ~map[string]interface{}](pluginConfig T) string {
+	name, _ := pluginConfig[types.Name].(string)
+	return name
+}
+
+// checkStores creates each store on its own, so that the error of each store
+// is reported.
+func checkStores(storesConfig rsConfig.StoresConfig) []ComponentCheck {
+	if len(storesConfig.Stores) == 0 {
+		_, err := sf.CreateStoresFromConfig(storesConfig, GetDefaultPluginPath())
+		return []ComponentCheck{newComponentCheck(CheckComponentStore, "", err)}
+	}
+
+	checks := make([]ComponentCheck, 0, len(storesConfig.Stores))
+	for _, storeConfig := range storesConfig.Stores {
+		single := storesConfig
+		single.Stores = []rsConfig.StorePluginConfig{storeConfig}
+		_, err := sf.CreateStoresFromConfig(single, GetDefaultPluginPath())
+		checks = append(checks, newComponentCheck(CheckComponentStore, pluginName(storeConfig), err))
+	}
+	return checks
+}
+
+// checkVerifiers creates each verifier on its own, so that the error of each
+// verifier is reported, and then all verifiers together to check the
+// dependencies between them.
+func checkVerifiers(verifiersConfig vfConfig.VerifiersConfig) []ComponentCheck {
+	if len(verifiersConfig.Verifiers) == 0 {
+		_, err := vf.CreateVerifiersFromConfig(verifiersConfig, GetDefaultPluginPath(), constants.EmptyNamespace)
+		return []ComponentCheck{newComponentCheck(CheckComponentVerifier, "", err)}
+	}
+
+	checks := make([]ComponentCheck, 0, len(verifiersConfig.Verifiers)+1)
+	failed := false
+	for _, verifierConfig := range verifiersConfig.Verifiers {
+		// dependencies are checked once all verifiers are created
+		withoutDependencies := vfConfig.VerifierConfig{}
+		for key, value := range verifierConfig {
+			if key != types.DependsOn {
+				withoutDependencies[key] = value
+			}
+		}
+		single := verifiersConfig
+		single.Verifiers = []vfConfig.VerifierConfig{withoutDependencies}
+		_, err := vf.CreateVerifiersFromConfig(single, GetDefaultPluginPath(), constants.EmptyNamespace)
+		failed = failed || err != nil
+		checks = append(checks, newComponentCheck(CheckComponentVerifier, pluginName(verifierConfig), err))
+	}
+	if !failed {
+		if _, err := vf.CreateVerifiersFromConfig(verifiersConfig, GetDefaultPluginPath(), constants.EmptyNamespace); err != nil {
+			checks = append(checks, newComponentCheck(CheckComponentVerifier, "", err))
+		}
+	}
+	return checks
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// resetDefaultPaths resets the default paths initialized by creating plugins,
+// so that other tests can initialize them from the environment.
+func resetDefaultPaths() {
+	configDir, defaultPluginsPath, defaultConfigFilePath = "", "", ""
+	initConfigDir = new(sync.Once)
+}
+
+func TestCheck(t *testing.T) {
+	defer resetDefaultPaths()
+	fileName := filepath.Join(t.TempDir(), ConfigFileName)
+	content := `{
+		"executor": {"reportVersion": "v2"},
+		"store": {"useHttp": true, "plugins": [{"name": "oras"}, {"name": "invalid/name"}]},
+		"policy": {"plugin": {"name": "unknownPolicy"}},
+		"verifier": {"plugins": []}
+	}`
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		t.Fatalf("config file creation failed %v", err)
+	}
+
+	checks := Check(fileName)
+	if !CheckFailed(checks) {
+		t.Fatalf("expected check to fail")
+	}
+	expected := []struct {
+		component string
+		name      string
+		failed    bool
+	}{
+		{component: CheckComponentConfig, name: fileName},
+		{component: CheckComponentExecutor},
+		{component: CheckComponentStore, name: "oras"},
+		{component: CheckComponentStore, name: "invalid/name", failed: true},
+		{component: CheckComponentVerifier, failed: true},
+		{component: CheckComponentPolicy, name: "unknownPolicy", failed: true},
+	}
+	if len(checks) != len(expected) {
+		t.Fatalf("expected %d checks, got %+v", len(expected), checks)
+	}
+	for i, check := range checks {
+		if check.Component != expected[i].component || check.Name != expected[i].name || (check.Error != "") != expected[i].failed {
+			t.Fatalf("expected check %d to be %+v, got %+v", i, expected[i], check)
+		}
+	}
+	if len(checks[0].Warnings) != 1 || !strings.Contains(checks[0].Warnings[0], "store.useHttp") {
+		t.Fatalf("expected a warning for the unknown field, got %v", checks[0].Warnings)
+	}
+}
+
+func TestCheck_InvalidConfig(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(fileName, []byte(`{"store": {"plugins": [{}]}}`), 0600); err != nil {
+		t.Fatalf("config file creation failed %v", err)
+	}
+
+	checks := Check(fileName)
+	if len(checks) != 1 || !strings.Contains(checks[0].Error, "store.plugins[0].name: field is required") {
+		t.Fatalf("expected only the config check to fail, got %+v", checks)
+	}
+}
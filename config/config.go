@@ -26,7 +26,9 @@ import (
 
 	"github.com/deislabs/ratify/internal/constants"
 	"github.com/deislabs/ratify/internal/logger"
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
 	exConfig "github.com/deislabs/ratify/pkg/executor/config"
+	"github.com/deislabs/ratify/pkg/executor/core"
 	"github.com/deislabs/ratify/pkg/homedir"
 	"github.com/deislabs/ratify/pkg/policyprovider"
 	pcConfig "github.com/deislabs/ratify/pkg/policyprovider/config"
@@ -34,6 +36,7 @@ import (
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	rsConfig "github.com/deislabs/ratify/pkg/referrerstore/config"
 	sf "github.com/deislabs/ratify/pkg/referrerstore/factory"
+	"github.com/deislabs/ratify/pkg/selfverify"
 	"github.com/deislabs/ratify/pkg/verifier"
 	vfConfig "github.com/deislabs/ratify/pkg/verifier/config"
 	vf "github.com/deislabs/ratify/pkg/verifier/factory"
@@ -48,12 +51,13 @@ const (
 )
 
 type Config struct {
-	StoresConfig    rsConfig.StoresConfig    `json:"store,omitempty"`
-	PoliciesConfig  pcConfig.PoliciesConfig  `json:"policy,omitempty"`
-	VerifiersConfig vfConfig.VerifiersConfig `json:"verifier,omitempty"`
-	ExecutorConfig  exConfig.ExecutorConfig  `json:"executor,omitempty"`
-	LoggerConfig    logger.Config            `json:"logger,omitempty"`
-	fileHash        string                   `json:"-"`
+	StoresConfig     rsConfig.StoresConfig    `json:"store,omitempty"`
+	PoliciesConfig   pcConfig.PoliciesConfig  `json:"policy,omitempty"`
+	VerifiersConfig  vfConfig.VerifiersConfig `json:"verifier,omitempty"`
+	ExecutorConfig   exConfig.ExecutorConfig  `json:"executor,omitempty"`
+	LoggerConfig     logger.Config            `json:"logger,omitempty"`
+	SelfVerifyConfig selfverify.Config        `json:"selfVerification,omitempty"`
+	fileHash         string                   `json:"-"`
 }
 
 var (
@@ -85,6 +89,12 @@ func getHomeDir() string {
 
 // Returns created referer store, verifier, policyprovider objects from config
 func CreateFromConfig(cf Config) ([]referrerstore.ReferrerStore, []verifier.ReferenceVerifier, policyprovider.PolicyProvider, error) {
+	if err := cf.ExecutorConfig.Validate(); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "invalid executor config")
+	}
+	pluginCommon.ConfigurePool(cf.ExecutorConfig.PluginPool)
+	core.ConfigureScheduler(cf.ExecutorConfig.Priority)
+
 	stores, err := sf.CreateStoresFromConfig(cf.StoresConfig, GetDefaultPluginPath())
 
 	if err != nil {
@@ -122,11 +132,20 @@ func Load(configFilePath string) (Config, error) {
 		return config, fmt.Errorf("unable to read config file at path %s: %w", configFilePath, err)
 	}
 
-	if err = json.Unmarshal(body, &config); err != nil {
+	if err = validateSchema(body); err != nil {
+		return config, fmt.Errorf("invalid config file at path %s: %w", configFilePath, err)
+	}
+
+	expanded, err := expandReferences(body)
+	if err != nil {
+		return config, fmt.Errorf("unable to resolve references in config file at path %s: %w", configFilePath, err)
+	}
+
+	if err = json.Unmarshal(expanded, &config); err != nil {
 		return config, fmt.Errorf("unable to unmarshal config body: %w", err)
 	}
 
-	if config.fileHash, err = getFileHash(body); err != nil {
+	if config.fileHash, err = getFileHash(expanded); err != nil {
 		return config, fmt.Errorf("error getting configuration file hash error: %w", err)
 	}
 
@@ -140,6 +159,12 @@ func GetDefaultPluginPath() string {
 	return defaultPluginsPath
 }
 
+// GetPluginDirs returns the directories the plugins of the config are loaded from.
+func GetPluginDirs(cf Config) []string {
+	dirs := append([]string{GetDefaultPluginPath()}, cf.VerifiersConfig.PluginBinDirs...)
+	return append(dirs, cf.StoresConfig.PluginBinDirs...)
+}
+
 // returns default plugin version of 1.0.0
 func GetDefaultPluginVersion() string {
 	return "1.0.0"
@@ -16,10 +16,14 @@ limitations under the License.
 package config
 
 import (
-	"os"
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	ef "github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/metrics"
 	"github.com/fsnotify/fsnotify"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -27,9 +31,28 @@ import (
 
 type GetExecutor func() *ef.Executor
 
+// warmTimeout bounds the time spent preparing a reloaded executor before it
+// replaces the active one
+const warmTimeout = 2 * time.Minute
+
+// configReloadDelay batches the events of a change to the configuration file,
+// e.g. truncating and writing it, into a single reload
+const configReloadDelay = 500 * time.Millisecond
+
+// configMapDataDir is the symlink to the data of a mounted ConfigMap, which
+// Kubernetes replaces atomically when the ConfigMap is updated
+const configMapDataDir = "..data"
+
 var (
 	configHash string
-	executor   ef.Executor
+	// configGeneration is incremented whenever a changed config file is loaded
+	configGeneration int64
+	// executor is replaced as a whole on reload, requests keep using the
+	// executor they started with
+	executor atomic.Pointer[ef.Executor]
+	// reloadMu serializes reloads triggered by the configuration file and by
+	// the files watched in development mode
+	reloadMu sync.Mutex
 )
 
 // Create a executor from configurationFile and setup config file watcher
@@ -41,20 +64,15 @@ func GetExecutorAndWatchForUpdate(configFilePath string) (GetExecutor, error) {
 		return func() *ef.Executor { return &ef.Executor{} }, err
 	}
 
-	configHash = cf.fileHash
-
-	stores, verifiers, policyEnforcer, err := CreateFromConfig(cf)
+	initialExecutor, err := newExecutor(cf, configGeneration+1)
 
 	if err != nil {
 		return func() *ef.Executor { return &ef.Executor{} }, err
 	}
 
-	executor = ef.Executor{
-		Verifiers:      verifiers,
-		ReferrerStores: stores,
-		PolicyEnforcer: policyEnforcer,
-		Config:         &cf.ExecutorConfig,
-	}
+	executor.Store(initialExecutor)
+	configHash = cf.fileHash
+	configGeneration = initialExecutor.ConfigGeneration
 
 	err = watchForConfigurationChange(configFilePath)
 
@@ -64,48 +82,82 @@ func GetExecutorAndWatchForUpdate(configFilePath string) (GetExecutor, error) {
 
 	logrus.Info("configuration successfully loaded.")
 
-	return func() *ef.Executor { return &executor }, nil
+	return executor.Load, nil
+}
+
+// newExecutor creates the stores, verifiers and policy provider of the config.
+// Creating them validates the config, so that an invalid config never
+// replaces the active executor.
+func newExecutor(cf Config, generation int64) (*ef.Executor, error) {
+	stores, verifiers, policyEnforcer, err := CreateFromConfig(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ef.Executor{
+		Verifiers:        verifiers,
+		ReferrerStores:   stores,
+		PolicyEnforcer:   policyEnforcer,
+		Config:           &cf.ExecutorConfig,
+		ConfigGeneration: generation,
+	}, nil
 }
 
-func reloadExecutor(configFilePath string) {
+// reloadExecutor replaces the executor if the configuration file changed, or
+// regardless of changes if force is set, e.g. when trust material changed.
+// The active executor keeps serving requests if the config is invalid.
+func reloadExecutor(configFilePath string, force bool) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	ctx := context.Background()
 	cf, err := Load(configFilePath)
 
 	if err != nil {
-		logrus.Warnf("failed to load from config file , err: %v", err)
+		logrus.Errorf("failed to load from config file, keeping executor of config generation %d, err: %v", configGeneration, err)
+		metrics.ReportConfigReload(ctx, false)
 		return
 	}
 
-	if configHash != cf.fileHash {
-		stores, verifiers, policyEnforcer, err := CreateFromConfig(cf)
-
-		newExecutor := ef.Executor{
-			Verifiers:      verifiers,
-			ReferrerStores: stores,
-			PolicyEnforcer: policyEnforcer,
-			Config:         &cf.ExecutorConfig,
-		}
+	if !force && configHash == cf.fileHash {
+		logrus.Infof("no change found in config file, no executor update needed")
+		return
+	}
 
-		if err != nil {
-			logrus.Warnf("failed to store/verifier/policy objects from config, no updates loaded. err: %v", err)
-			return
-		}
+	reloadedExecutor, err := newExecutor(cf, configGeneration+1)
 
-		executor = newExecutor
-		configHash = cf.fileHash
-		logrus.Infof("configuration file has been updated, reloading executor succeeded")
-	} else {
-		logrus.Infof("no change found in config file, no executor update needed")
+	if err != nil {
+		logrus.Errorf("failed to store/verifier/policy objects from config, keeping executor of config generation %d, err: %v", configGeneration, err)
+		metrics.ReportConfigReload(ctx, false)
+		return
 	}
+
+	// prepare the new executor in the background while the active one keeps
+	// serving requests, so the first requests after the swap are not slowed
+	// down by starting plugins and loading trust stores
+	warmCtx, cancel := context.WithTimeout(ctx, warmTimeout)
+	reloadedExecutor.Warm(warmCtx)
+	cancel()
+
+	executor.Store(reloadedExecutor)
+	configHash = cf.fileHash
+	configGeneration = reloadedExecutor.ConfigGeneration
+	logrus.Infof("configuration file has been updated, reloading executor succeeded, config generation %d", configGeneration)
+	metrics.ReportConfigReload(ctx, true)
 }
 
-// Setup a watcher on file at configFilePath, reload executor on file change
+// Setup a watcher on the directory of the file at configFilePath, reload
+// executor on file change
 func watchForConfigurationChange(configFilePath string) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return errors.Wrap(err, "new file watcher on configuration file failed ")
 	}
 
-	if err = watcher.Add(configFilePath); err != nil {
+	// the directory is watched rather than the file, since editors replace
+	// the file and Kubernetes updates a mounted ConfigMap by swapping the
+	// symlink of its data directory, both of which end a watch on the file
+	if err = watcher.Add(filepath.Dir(configFilePath)); err != nil {
 		logrus.Errorf("adding configuration file watcher failed, err: %v", err)
 		return err
 	}
@@ -114,6 +166,7 @@ func watchForConfigurationChange(configFilePath string) error {
 
 	// setup for loop to listen for events
 	go func() {
+		var timer *time.Timer
 		for {
 			select {
 			case event, ok := <-watcher.Events:
@@ -122,53 +175,44 @@ func watchForConfigurationChange(configFilePath string) error {
 					return
 				}
 
-				logrus.Infof("file watcher event detected %v", event)
-
-				// In a cluster scenario, a configMap will recreate the config file
-				// after the remove event, the watcher will also be removed
-				// since a watcher on a non existent file is not supported, we sleep until the file exist add the watcher back
-				if event.Name == configFilePath && event.Op&fsnotify.Remove == fsnotify.Remove {
-					logrus.Infof("config file remove event detected")
-					sleepTime := 1 * time.Second
-					waitTime := 60 //1min
-
-					time.Sleep(sleepTime)
-					_, err := os.Stat(configFilePath)
-
-					for err != nil {
-						if waitTime < 0 {
-							logrus.Warnf("config file not found after waiting for %v sec, os.Stat error %v", waitTime, err)
-							return
-						}
-						logrus.Infof("config file does not exist yet, sleeping again")
-						_, err = os.Stat(configFilePath)
-						time.Sleep(sleepTime)
-						waitTime--
-					}
-					reloadExecutor(configFilePath)
-					err = watcher.Add(configFilePath)
-
-					if err != nil {
-						logrus.Errorf("adding configuration file watcher failed, err: %v", err)
-						continue
-					}
-
-					logrus.Infof("watcher added on configuration directory %v", configFilePath)
+				if !configFileChanged(event, configFilePath) {
+					continue
 				}
 
-				// In a local scenario, the configuration will be updated through a write event
-				if event.Name == configFilePath && event.Op&fsnotify.Write == fsnotify.Write {
-					reloadExecutor(configFilePath)
+				logrus.Infof("file watcher event detected %v", event)
+
+				if timer != nil {
+					timer.Stop()
 				}
+				timer = time.AfterFunc(configReloadDelay, func() {
+					reloadExecutor(configFilePath, false)
+				})
 
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					logrus.Errorf("configuration file watcher returned error : %v, watcher will be closed.", err)
 					return
 				}
+				logrus.Warnf("configuration file watcher returned error: %v", err)
 			}
 		}
 	}()
 
 	return nil
 }
+
+// configFileChanged returns true if the event writes or creates the
+// configuration file or swaps the data directory of the ConfigMap it is
+// mounted from. Removing the file is ignored, the file is reloaded once it
+// is created again.
+func configFileChanged(event fsnotify.Event, configFilePath string) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	configFilePath = filepath.Clean(configFilePath)
+	if name == configFilePath {
+		return true
+	}
+	return filepath.Base(name) == configMapDataDir && filepath.Dir(name) == filepath.Dir(configFilePath)
+}
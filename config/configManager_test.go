@@ -0,0 +1,78 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ef "github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestConfigFileChanged(t *testing.T) {
+	configFilePath := "/usr/local/ratify/config.json"
+	testCases := []struct {
+		name     string
+		event    fsnotify.Event
+		expected bool
+	}{
+		{name: "config file written", event: fsnotify.Event{Name: configFilePath, Op: fsnotify.Write}, expected: true},
+		{name: "config file replaced", event: fsnotify.Event{Name: configFilePath, Op: fsnotify.Create}, expected: true},
+		{name: "config map data swapped", event: fsnotify.Event{Name: "/usr/local/ratify/..data", Op: fsnotify.Create}, expected: true},
+		{name: "config file removed", event: fsnotify.Event{Name: configFilePath, Op: fsnotify.Remove}, expected: false},
+		{name: "config file chmod", event: fsnotify.Event{Name: configFilePath, Op: fsnotify.Chmod}, expected: false},
+		{name: "other file written", event: fsnotify.Event{Name: "/usr/local/ratify/other.json", Op: fsnotify.Write}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if changed := configFileChanged(tc.event, configFilePath); changed != tc.expected {
+				t.Fatalf("expected configFileChanged() to return %v, got %v", tc.expected, changed)
+			}
+		})
+	}
+}
+
+func TestReloadExecutor_InvalidConfigKeepsExecutor(t *testing.T) {
+	configFilePath := filepath.Join(t.TempDir(), ConfigFileName)
+	active := &ef.Executor{ConfigGeneration: 1}
+	executor.Store(active)
+	configHash, configGeneration = "active", 1
+	defer func() { configHash, configGeneration = "", 0 }()
+
+	testCases := []struct {
+		name   string
+		config string
+	}{
+		{name: "malformed config", config: `{"store":`},
+		{name: "invalid executor config", config: `{"executor": {"reportVersion": "3.0.0"}}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.WriteFile(configFilePath, []byte(tc.config), 0o600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			reloadExecutor(configFilePath, false)
+			if executor.Load() != active {
+				t.Fatalf("expected the active executor to be kept")
+			}
+			if configHash != "active" || configGeneration != 1 {
+				t.Fatalf("expected config hash and generation to be kept, got %s and %d", configHash, configGeneration)
+			}
+		})
+	}
+}
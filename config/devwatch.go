@@ -0,0 +1,165 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// devReloadDelay batches the events of a change to the watched files, e.g.
+// copying a plugin or a certificate chain, into a single reload
+const devReloadDelay = 500 * time.Millisecond
+
+// trustMaterialKeys are the keys of verifier configurations holding local
+// paths of trust material, e.g. the certificates of the notation verifier
+// and the public key of the cosign verifier
+var trustMaterialKeys = []string{"verificationCerts", "key"}
+
+// WatchForDevelopment reloads the executor whenever the trust material
+// referenced by the verifiers of the configuration file or the plugins in the
+// plugin directories change, even though the configuration file itself did
+// not change. Changes of the configuration file are watched by
+// GetExecutorAndWatchForUpdate.
+func WatchForDevelopment(configFilePath string) error {
+	configFilePath = getConfigurationFile(configFilePath)
+	cf, err := Load(configFilePath)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "new file watcher on trust material and plugins failed")
+	}
+	paths := newWatchedPaths()
+	for _, path := range append(trustMaterialPaths(cf), GetPluginDirs(cf)...) {
+		dir, err := paths.add(path)
+		if err != nil {
+			logrus.Warnf("not watching %s for changes: %v", path, err)
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			logrus.Warnf("not watching %s for changes: %v", path, err)
+			continue
+		}
+		logrus.Infof("watching %s for changes", path)
+	}
+
+	go func() {
+		var mu sync.Mutex
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					logrus.Warnf("no longer watching trust material and plugin changes, file watcher event channel closed")
+					return
+				}
+				if !paths.matches(event.Name) || event.Op == fsnotify.Chmod {
+					continue
+				}
+				logrus.Infof("change of %s detected, reloading executor", event.Name)
+				mu.Lock()
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(devReloadDelay, func() {
+					reloadExecutor(configFilePath, true)
+				})
+				mu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logrus.Warnf("trust material and plugin watcher returned error: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// trustMaterialPaths returns the local paths of the trust material referenced
+// by the verifier configurations, references to key management services are
+// skipped
+func trustMaterialPaths(cf Config) []string {
+	var paths []string
+	for _, verifierConfig := range cf.VerifiersConfig.Verifiers {
+		for _, key := range trustMaterialKeys {
+			var values []string
+			switch value := verifierConfig[key].(type) {
+			case string:
+				values = []string{value}
+			case []interface{}:
+				for _, item := range value {
+					if path, ok := item.(string); ok {
+						values = append(values, path)
+					}
+				}
+			}
+			for _, value := range values {
+				if value != "" && !strings.Contains(value, "://") {
+					paths = append(paths, value)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// watchedPaths are the directories watched for changes. Files are watched
+// through their directory, since editors and tools replace files rather than
+// writing them, and only events of the watched files are considered.
+type watchedPaths struct {
+	dirs  map[string]struct{}
+	files map[string]struct{}
+}
+
+func newWatchedPaths() *watchedPaths {
+	return &watchedPaths{dirs: map[string]struct{}{}, files: map[string]struct{}{}}
+}
+
+// add adds the path and returns the directory to watch for it
+func (p *watchedPaths) add(path string) (string, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		p.dirs[path] = struct{}{}
+		return path, nil
+	}
+	p.files[path] = struct{}{}
+	return filepath.Dir(path), nil
+}
+
+// matches returns true if the event of the file concerns a watched path
+func (p *watchedPaths) matches(name string) bool {
+	name = filepath.Clean(name)
+	if _, ok := p.files[name]; ok {
+		return true
+	}
+	_, ok := p.dirs[filepath.Dir(name)]
+	return ok
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	vc "github.com/deislabs/ratify/pkg/verifier/config"
+)
+
+func TestTrustMaterialPaths(t *testing.T) {
+	cf := Config{
+		VerifiersConfig: vc.VerifiersConfig{
+			Verifiers: []vc.VerifierConfig{
+				{"name": "notation", "verificationCerts": []interface{}{"/certs/ca.crt", "/certs/dir"}},
+				{"name": "cosign", "key": "/keys/cosign.pub"},
+				{"name": "cosign-kms", "key": "azurekms://vault/key"},
+				{"name": "sbom"},
+			},
+		},
+	}
+	expected := []string{"/certs/ca.crt", "/certs/dir", "/keys/cosign.pub"}
+	if paths := trustMaterialPaths(cf); !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected trust material paths %v, got %v", expected, paths)
+	}
+}
+
+func TestWatchedPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	certDir := filepath.Join(tmpDir, "certs")
+	if err := os.Mkdir(certDir, 0o700); err != nil {
+		t.Fatalf("failed to create cert dir: %v", err)
+	}
+	keyFile := filepath.Join(tmpDir, "cosign.pub")
+	if err := os.WriteFile(keyFile, []byte("key"), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	paths := newWatchedPaths()
+	if dir, err := paths.add(certDir); err != nil || dir != certDir {
+		t.Fatalf("expected cert dir to be watched, got %s, err: %v", dir, err)
+	}
+	if dir, err := paths.add(keyFile); err != nil || dir != tmpDir {
+		t.Fatalf("expected directory of key file to be watched, got %s, err: %v", dir, err)
+	}
+	if _, err := paths.add(filepath.Join(tmpDir, "missing")); err == nil {
+		t.Fatalf("expected error adding missing path")
+	}
+
+	testCases := []struct {
+		name    string
+		matches bool
+	}{
+		{name: filepath.Join(certDir, "ca.crt"), matches: true},
+		{name: keyFile, matches: true},
+		{name: filepath.Join(tmpDir, "config.json"), matches: false},
+		{name: filepath.Join(certDir, "nested", "ca.crt"), matches: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if matches := paths.matches(tc.name); matches != tc.matches {
+				t.Fatalf("expected matches %v, got %v", tc.matches, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// secretRefPrefix marks a string value of the config file as a reference to
+// a file holding the value, e.g. a key of a Kubernetes Secret mounted into
+// the pod, so that secrets are not stored in the ConfigMap.
+const secretRefPrefix = "secretRef:"
+
+// envVarPattern matches ${NAME} references to environment variables, $${NAME}
+// is kept as the literal ${NAME}.
+var envVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandReferences resolves the references to environment variables and
+// secret files in the string values of the config file. Values are resolved
+// after parsing the file, so resolved values never need to be escaped.
+func expandReferences(body []byte) ([]byte, error) {
+	if !strings.Contains(string(body), "${") && !strings.Contains(string(body), secretRefPrefix) {
+		return body, nil
+	}
+
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		// malformed JSON is reported when unmarshaling the configuration
+		return body, nil
+	}
+	expanded, err := expandValue(content, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(expanded)
+}
+
+func expandValue(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			expanded, err := expandValue(item, fieldPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = expanded
+		}
+	case []interface{}:
+		for i, item := range v {
+			expanded, err := expandValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	case string:
+		return expandString(v, path)
+	}
+	return value, nil
+}
+
+func expandString(value string, path string) (string, error) {
+	if secretPath, ok := strings.CutPrefix(value, secretRefPrefix); ok {
+		secret, err := os.ReadFile(secretPath)
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to read secret reference: %w", path, err)
+		}
+		return strings.TrimRight(string(secret), "\r\n"), nil
+	}
+
+	var err error
+	expanded := envVarPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if strings.HasPrefix(reference, "$$") {
+			return reference[1:]
+		}
+		name := envVarPattern.FindStringSubmatch(reference)[1]
+		envValue, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%s: environment variable %s is not set", path, name)
+		}
+		return envValue
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandReferences(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("s3cr\"et\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret: %v", err)
+	}
+	t.Setenv("RATIFY_TEST_REGISTRY", "myregistry.azurecr.io")
+
+	testCases := []struct {
+		name          string
+		config        string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "no references",
+			config:   `{"store": {"version": "1.0.0"}}`,
+			expected: `{"store": {"version": "1.0.0"}}`,
+		},
+		{
+			name:     "environment variable",
+			config:   `{"store": {"plugins": [{"name": "oras", "registry": "https://${RATIFY_TEST_REGISTRY}/v2"}]}}`,
+			expected: `{"store":{"plugins":[{"name":"oras","registry":"https://myregistry.azurecr.io/v2"}]}}`,
+		},
+		{
+			name:     "escaped environment variable",
+			config:   `{"policy": {"plugin": {"name": "regoPolicy", "policy": "$${RATIFY_TEST_REGISTRY}"}}}`,
+			expected: `{"policy":{"plugin":{"name":"regoPolicy","policy":"${RATIFY_TEST_REGISTRY}"}}}`,
+		},
+		{
+			name:     "secret reference",
+			config:   `{"store": {"plugins": [{"name": "oras", "password": "secretRef:` + secretFile + `"}]}}`,
+			expected: `{"store":{"plugins":[{"name":"oras","password":"s3cr\"et"}]}}`,
+		},
+		{
+			name:          "unset environment variable",
+			config:        `{"store": {"plugins": [{"name": "oras", "registry": "${RATIFY_TEST_UNSET}"}]}}`,
+			expectedError: "store.plugins[0].registry: environment variable RATIFY_TEST_UNSET is not set",
+		},
+		{
+			name:          "missing secret",
+			config:        `{"verifier": {"plugins": [{"name": "cosign", "key": "secretRef:/nonexistent/key"}]}}`,
+			expectedError: "verifier.plugins[0].key: failed to read secret reference: open /nonexistent/key: no such file or directory",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, err := expandReferences([]byte(tc.config))
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandReferences() error = %v", err)
+			}
+			if string(expanded) != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, expanded)
+			}
+		})
+	}
+}
+
+func TestLoad_ExpandsReferences(t *testing.T) {
+	t.Setenv("RATIFY_TEST_STORE_VERSION", testVersion)
+	fileName := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(fileName, []byte(`{"store": {"version": "${RATIFY_TEST_STORE_VERSION}"}}`), 0600); err != nil {
+		t.Fatalf("config file creation failed %v", err)
+	}
+
+	config, err := Load(fileName)
+	if err != nil {
+		t.Fatalf("loading config failed %v", err)
+	}
+	if config.StoresConfig.Version != testVersion {
+		t.Fatalf("expected store version %s, got %s", testVersion, config.StoresConfig.Version)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// configSchema is the JSON schema of the configuration file. Fields of the
+// plugins other than their name are validated by the plugins.
+//
+//go:embed schema.json
+var configSchema []byte
+
+var configSchemaLoader = gojsonschema.NewBytesLoader(configSchema)
+
+// validateSchema validates the configuration file against the schema of the
+// configuration. Type mismatches and missing required fields are returned as
+// a single error listing the path of each field, e.g.
+// store.plugins[0].name. Unknown fields are ignored when the configuration is
+// loaded, so they are logged as warnings only.
+func validateSchema(body []byte) error {
+	problems, unknownFields := checkSchema(body)
+	for _, field := range unknownFields {
+		logrus.Warnf("unknown field %s in config file is ignored", field)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// checkSchema returns the type mismatches and missing required fields of the
+// configuration file and the paths of its unknown fields.
+func checkSchema(body []byte) (problems []string, unknownFields []string) {
+	result, err := gojsonschema.Validate(configSchemaLoader, gojsonschema.NewBytesLoader(body))
+	if err != nil {
+		// malformed JSON is reported when unmarshaling the configuration
+		return nil, nil
+	}
+
+	for _, resultErr := range result.Errors() {
+		switch resultErr.Type() {
+		case "additional_property_not_allowed":
+			unknownFields = append(unknownFields, fieldPath(resultErr.Field(), resultErr.Details()["property"]))
+		case "required":
+			problems = append(problems, fmt.Sprintf("%s: field is required", fieldPath(resultErr.Field(), resultErr.Details()["property"])))
+		default:
+			problems = append(problems, fmt.Sprintf("%s: %s", fieldPath(resultErr.Field(), nil), resultErr.Description()))
+		}
+	}
+	// the properties of an object are validated in random order
+	sort.Strings(problems)
+	sort.Strings(unknownFields)
+	return problems, unknownFields
+}
+
+// fieldPath converts the field of a schema error, e.g. store.plugins.0, to the
+// path of the field in the configuration, e.g. store.plugins[0], optionally
+// followed by the property the error is about.
+func fieldPath(field string, property interface{}) string {
+	var path strings.Builder
+	if field != gojsonschema.STRING_CONTEXT_ROOT {
+		for _, segment := range strings.Split(field, ".") {
+			if _, err := strconv.Atoi(segment); err == nil {
+				path.WriteString("[" + segment + "]")
+				continue
+			}
+			if path.Len() > 0 {
+				path.WriteString(".")
+			}
+			path.WriteString(segment)
+		}
+	}
+	if name, ok := property.(string); ok {
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(name)
+	}
+	return path.String()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name:   "empty config",
+			config: `{}`,
+		},
+		{
+			name:   "unknown fields are ignored",
+			config: `{"store": {"version": "1.0.0", "useHttp": true, "plugins": [{"name": "oras", "useHttp": true}]}}`,
+		},
+		{
+			name:          "missing plugin name",
+			config:        `{"store": {"plugins": [{"name": "oras"}, {"useHttp": true}]}}`,
+			expectedError: "store.plugins[1].name: field is required",
+		},
+		{
+			name:          "type mismatch",
+			config:        `{"executor": {"maxNestedDepth": "3"}}`,
+			expectedError: "executor.maxNestedDepth: Invalid type. Expected: [integer,null], given: string",
+		},
+		{
+			name:          "multiple problems",
+			config:        `{"executor": {"pluginPool": {"maxProcesses": -1}}, "policy": {"plugin": {}}}`,
+			expectedError: "executor.pluginPool.maxProcesses: Must be greater than or equal to 0; policy.plugin.name: field is required",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSchema([]byte(tc.config))
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Fatalf("expected config to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedError {
+				t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchema_SampleConfigs(t *testing.T) {
+	samples, err := filepath.Glob(filepath.Join("..", "test", "bats", "tests", "config", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list sample configs: %v", err)
+	}
+	for _, sample := range append(samples, ConfigFileName) {
+		body, err := os.ReadFile(sample)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", sample, err)
+		}
+		if err := validateSchema(body); err != nil {
+			t.Fatalf("expected %s to be valid, got %v", sample, err)
+		}
+	}
+}
+
+func TestLoad_InvalidSchema(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(fileName, []byte(`{"verifier": {"plugins": {"name": "notation"}}}`), 0600); err != nil {
+		t.Fatalf("config file creation failed %v", err)
+	}
+
+	_, err := Load(fileName)
+	if err == nil || !strings.Contains(err.Error(), "verifier.plugins: Invalid type. Expected: array, given: object") {
+		t.Fatalf("expected error to name the invalid field, got %v", err)
+	}
+}
@@ -47,6 +47,14 @@ var (
 		Description: `The request is invalid or malformed. Check the request body and headers for more details.`,
 	})
 
+	// ErrorCodeRequestLimitExceeded is returned if the request exceeds the
+	// configured size or number of keys.
+	ErrorCodeRequestLimitExceeded = Register("errcode", ErrorDescriptor{
+		Value:       "REQUEST_LIMIT_EXCEEDED",
+		Message:     "request limit exceeded",
+		Description: `The request body is larger or has more keys than the server accepts. Please reduce the number of images sent per request or increase the request limits of the server.`,
+	})
+
 	// ErrorCodeReferenceInvalid is returned if provided image reference is invalid.
 	ErrorCodeReferenceInvalid = Register("errcode", ErrorDescriptor{
 		Value:       "REFERENCE_INVALID",
@@ -112,6 +112,30 @@ var (
 		Description: "No referrers are found. Please verify the subject has attached expected artifacts and refer to https://ratify.dev/docs/reference/store/ to investigate Referrer Store configuration.",
 	})
 
+	// ErrorCodeRequiredReferrerNotFound is returned if no referrers of an
+	// artifact type required by the executor are attached to the subject.
+	ErrorCodeRequiredReferrerNotFound = Register("errcode", ErrorDescriptor{
+		Value:       "REQUIRED_REFERRER_NOT_FOUND",
+		Message:     "required referrer not found",
+		Description: "No referrers of an artifact type required by the executor configuration are attached to the subject. Please verify the expected artifacts, e.g. an SBOM, are attached to the subject and can be listed by the configured referrer stores.",
+	})
+
+	// ErrorCodeVerificationLimitExceeded is returned if the referrer graph of a
+	// subject has more artifacts than the executor verifies per subject.
+	ErrorCodeVerificationLimitExceeded = Register("errcode", ErrorDescriptor{
+		Value:       "VERIFICATION_LIMIT_EXCEEDED",
+		Message:     "verification limit exceeded",
+		Description: "The subject and its nested artifacts have more referrers than the maximum number of artifacts verified per subject. Please check the referrers attached to the subject or increase maxVerificationCount of the executor configuration.",
+	})
+
+	// ErrorCodeReferrerCycleDetected is returned if a referrer of a subject is
+	// the subject itself or one of the artifacts it is attached to.
+	ErrorCodeReferrerCycleDetected = Register("errcode", ErrorDescriptor{
+		Value:       "REFERRER_CYCLE_DETECTED",
+		Message:     "referrer cycle detected",
+		Description: "A referrer listed for the subject is the subject itself or an artifact the subject is attached to. The referrer graph returned by the registry is invalid, please check the referrers of the subject in the registry.",
+	})
+
 	// Generic errors happen in plugins
 
 	// ErrorCodePluginInitFailure is returned when executor or controller fails
@@ -145,6 +169,13 @@ var (
 		Description: "The certificate is invalid. Please verify the provided inline certificates or certificates fetched from key vault are in valid format. Refer to https://ratify.dev/docs/reference/crds/certificate-stores for more information.",
 	})
 
+	// ErrorCodeKeyInvalid is returned when provided keys are invalid.
+	ErrorCodeKeyInvalid = Register("errcode", ErrorDescriptor{
+		Value:       "KEY_INVALID",
+		Message:     "key invalid",
+		Description: "The key is invalid. Please verify the provided inline keys or keys fetched from the key management provider are in valid format. Refer to https://ratify.dev/docs/reference/crds/key-management-providers for more information.",
+	})
+
 	// ErrorCodePolicyProviderNotFound is returned when a policy provider cannot
 	// be found.
 	ErrorCodePolicyProviderNotFound = Register("errcode", ErrorDescriptor{
@@ -160,4 +191,44 @@ var (
 		Message:     "Key vault operation failed",
 		Description: "Key vault operation failed. Please validate correct key vault configuration is provided or check the error details for further investigation.",
 	})
+
+	// ErrorCodeSubjectMismatch is returned when an attestation does not list
+	// the digest of the subject it is attached to.
+	ErrorCodeSubjectMismatch = Register("errcode", ErrorDescriptor{
+		Value:       "SUBJECT_MISMATCH",
+		Message:     "attestation subject mismatch",
+		Description: "The attestation subjects do not contain the digest of the artifact being verified. The attestation may have been generated for a different artifact and attached to this one. Please check the error details for the expected digest and the subjects found in the attestation.",
+	})
+
+	// ErrorCodeArtifactLinkageMismatch is returned when a nested result was
+	// not produced for the artifact whose content was verified.
+	ErrorCodeArtifactLinkageMismatch = Register("errcode", ErrorDescriptor{
+		Value:       "ARTIFACT_LINKAGE_MISMATCH",
+		Message:     "artifact linkage mismatch",
+		Description: "The nested verification result, e.g. a signature, was produced for a different artifact than the one whose content was verified. Mixing signatures and content of different artifacts is not allowed. Please check the error details for the expected and actual digests.",
+	})
+
+	// ErrorCodeVerificationInconclusive is returned when a verifier cannot
+	// reach a conclusion because it is not ready to run.
+	ErrorCodeVerificationInconclusive = Register("errcode", ErrorDescriptor{
+		Value:       "VERIFICATION_INCONCLUSIVE",
+		Message:     "verification inconclusive",
+		Description: "The verifier could not run, e.g. the verifier plugin is missing or the certificates and keys have not been fetched from the key management provider yet. The result does not indicate whether the artifact is trusted and is counted according to the inconclusive policy of the policy provider.",
+	})
+
+	// ErrorCodeDigestNotFound is returned when the digest an image reference
+	// is pinned to is not found in the registry.
+	ErrorCodeDigestNotFound = Register("errcode", ErrorDescriptor{
+		Value:       "DIGEST_NOT_FOUND",
+		Message:     "digest not found",
+		Description: "The digest the image reference is pinned to could not be found by the referrer store. The image may have been deleted or the digest may be mistyped. Please check the error details and the repository of the image.",
+	})
+
+	// ErrorCodeTagDigestMismatch is returned when the tag of a reference
+	// pinned to a tag and a digest no longer points at the digest.
+	ErrorCodeTagDigestMismatch = Register("errcode", ErrorDescriptor{
+		Value:       "TAG_DIGEST_MISMATCH",
+		Message:     "tag digest mismatch",
+		Description: "The tag of the image reference pinned to both a tag and a digest points at a different digest. The tag has been pushed again since the reference was pinned. Please check the error details for the digest the tag points at and update the reference.",
+	})
 )
@@ -43,14 +43,15 @@ var (
 type ComponentType string
 
 const (
-	Verifier       ComponentType = "verifier"
-	ReferrerStore  ComponentType = "referrerStore"
-	Policy         ComponentType = "policy"
-	Executor       ComponentType = "executor"
-	Cache          ComponentType = "cache"
-	AuthProvider   ComponentType = "authProvider"
-	PolicyProvider ComponentType = "policyProvider"
-	CertProvider   ComponentType = "certProvider"
+	Verifier              ComponentType = "verifier"
+	ReferrerStore         ComponentType = "referrerStore"
+	Policy                ComponentType = "policy"
+	Executor              ComponentType = "executor"
+	Cache                 ComponentType = "cache"
+	AuthProvider          ComponentType = "authProvider"
+	PolicyProvider        ComponentType = "policyProvider"
+	CertProvider          ComponentType = "certProvider"
+	KeyManagementProvider ComponentType = "keyManagementProvider"
 )
 
 // ErrorCode represents the error type. The errors are serialized via strings
@@ -171,6 +172,16 @@ func (ec ErrorCode) NewError(componentType ComponentType, pluginName, link strin
 	}
 }
 
+// CodeOf returns the code of the first Error in the chain of err, or the
+// fallback code if err does not wrap an Error.
+func CodeOf(err error, fallback ErrorCode) ErrorCode {
+	var e Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return fallback
+}
+
 func newError(code ErrorCode, message string) Error {
 	return Error{
 		Code:    code,
@@ -0,0 +1,86 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminConfig enables the admin endpoints, which are restricted to the client
+// certificates matching the admin names.
+type AdminConfig struct {
+	// Names are patterns of the common name or of a subject alternative name of
+	// the client certificates allowed to call the admin endpoints. The admin
+	// endpoints reject all requests if empty.
+	Names []string
+	// EnablePins serves the pin API approving digests without verification.
+	// Pins are kept in memory of the replica, they are not shared with other
+	// replicas and are lost on restart, so pins require a single replica.
+	EnablePins bool
+	// AllowPolicyOverrides allows admin clients to override the configured
+	// policy in requests to the verify endpoint of the REST API.
+	AllowPolicyOverrides bool
+}
+
+// Validate returns an error if the admin names are invalid patterns, client
+// certificates are not verified or admin endpoints are enabled without admins.
+func (c AdminConfig) Validate(caCertFile string) error {
+	if len(c.Names) == 0 {
+		if c.EnablePins {
+			return fmt.Errorf("pins require admin client names")
+		}
+		if c.AllowPolicyOverrides {
+			return fmt.Errorf("policy overrides require admin client names")
+		}
+		return nil
+	}
+	if caCertFile == "" {
+		return fmt.Errorf("admin client names require a CA cert file to verify client certificates")
+	}
+	for _, pattern := range c.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid admin client name %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// isAdmin returns true if the request carries a client certificate matching an
+// admin name. The TLS handshake verified the certificate chain against the CA.
+func (server *Server) isAdmin(r *http.Request) bool {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false
+	}
+	return matchesName(r.TLS.PeerCertificates[0], server.Admin.Names)
+}
+
+// authorizeAdmin rejects requests without a client certificate matching an
+// admin name with 403 Forbidden.
+func (server *Server) authorizeAdmin(h ContextHandler) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if !server.isAdmin(r) {
+			logrus.Warnf("request to %s from %s rejected, client is not an admin", r.URL.Path, clientIdentity(r))
+			return errcode.ServeJSON(w, errcode.ErrorCodeDenied.WithDetail("an admin client certificate is required"))
+		}
+		return h(ctx, w, r)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"testing"
+)
+
+func TestAdminConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		config     AdminConfig
+		caCertFile string
+		expectErr  bool
+	}{
+		{
+			name: "no admin names",
+		},
+		{
+			name:       "pins with admin names",
+			config:     AdminConfig{Names: []string{"ratify-admin"}, EnablePins: true},
+			caCertFile: "ca.crt",
+		},
+		{
+			name:      "pins without admin names",
+			config:    AdminConfig{EnablePins: true},
+			expectErr: true,
+		},
+		{
+			name:      "policy overrides without admin names",
+			config:    AdminConfig{AllowPolicyOverrides: true},
+			expectErr: true,
+		},
+		{
+			name:      "admin names without CA cert file",
+			config:    AdminConfig{Names: []string{"ratify-admin"}},
+			expectErr: true,
+		},
+		{
+			name:       "invalid pattern",
+			config:     AdminConfig{Names: []string{"["}},
+			caCertFile: "ca.crt",
+			expectErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.config.Validate(tc.caCertFile); (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/workload"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// admitPath is the path of the validating admission webhook, clusters without
+// Gatekeeper may register it to enforce verification of workload images.
+const admitPath = "/admit"
+
+// admit serves an AdmissionReview of the Kubernetes API server. The images of
+// the pod spec of the admitted resource are verified and the resource is denied
+// if any image fails verification.
+func (server *Server) admit(_ context.Context, w http.ResponseWriter, r *http.Request) error {
+	ctx, cancel := context.WithTimeout(r.Context(), server.GetExecutor().GetVerifyRequestTimeout())
+	defer cancel()
+	ctx = logger.InitContext(ctx, r)
+
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return err
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to decode admission review")
+	}
+	if review.Request == nil {
+		return errors.ErrorCodeBadRequest.WithDetail("admission review has no request")
+	}
+
+	review.Response = server.admissionResponse(ctx, review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	response, err := json.Marshal(review)
+	if err != nil {
+		return errors.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to marshal admission review")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(response)
+	return err
+}
+
+// admissionResponse verifies the images of the resource of the admission
+// request. Resources without a pod spec and deletions are always allowed.
+func (server *Server) admissionResponse(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation == admissionv1.Delete || request.Operation == admissionv1.Connect || !workload.IsSupportedKind(request.Kind.Kind) {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	w, err := workload.ParseObject(request.Object.Raw, request.Namespace)
+	if err != nil {
+		return deniedResponse(http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+	}
+
+	operation := string(request.Operation)
+	summary := workload.Verify(ctx, []workload.Workload{w}, func(ctx context.Context, w workload.Workload, image string) (bool, interface{}, error) {
+		item := server.verifyImageKey(ctx, w.Namespace, operation, image)
+		server.recordDenial(ctx, item)
+		return imageResult(image, item)
+	})
+	logger.GetLogger(ctx, server.LogOption).Infof("admission of %s %s/%s, allowed: %v", w.Kind, w.Namespace, w.Name, summary.IsSuccess)
+
+	var failures, warnings []string
+	for _, result := range summary.Workloads[0].Containers {
+		if response, ok := result.Result.(VerificationResponse); ok && response.Warning != "" {
+			warnings = append(warnings, fmt.Sprintf("image %s of %s %s: %s", result.Image, result.Type, result.Name, response.Warning))
+		}
+		if result.IsSuccess {
+			continue
+		}
+		failure := fmt.Sprintf("image %s of %s %s failed verification", result.Image, result.Type, result.Name)
+		if result.Error != "" {
+			failure = fmt.Sprintf("%s: %s", failure, result.Error)
+		}
+		failures = append(failures, failure)
+	}
+	if len(failures) > 0 {
+		response := deniedResponse(http.StatusForbidden, metav1.StatusReasonForbidden, strings.Join(failures, "; "))
+		response.Warnings = warnings
+		return response
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}
+}
+
+// deniedResponse returns an admission response denying the request.
+func deniedResponse(code int32, reason metav1.StatusReason, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
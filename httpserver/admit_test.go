@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func admissionReview(kind, operation, object string) string {
+	return `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"test-uid","kind":{"group":"","version":"v1","kind":"` + kind +
+		`"},"namespace":"team","operation":"` + operation + `","object":` + object + `}}`
+}
+
+func TestServer_Admit(t *testing.T) {
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			// the sidecar tag cannot be resolved
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("test")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+	}
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     context.Background(),
+	}
+	handler := contextHandler{context: server.Context, handler: server.admit}
+
+	testCases := []struct {
+		name           string
+		review         string
+		expectedStatus int
+		allowed        bool
+	}{
+		{
+			name:           "verified pod",
+			review:         admissionReview("Pod", "CREATE", `{"kind":"Pod","metadata":{"name":"app"},"spec":{"containers":[{"name":"app","image":"localhost:5000/net-monitor:v1"}]}}`),
+			expectedStatus: http.StatusOK,
+			allowed:        true,
+		},
+		{
+			name:           "unverified deployment",
+			review:         admissionReview("Deployment", "UPDATE", `{"kind":"Deployment","metadata":{"name":"app"},"spec":{"template":{"spec":{"containers":[{"name":"app","image":"localhost:5000/net-monitor:v1"},{"name":"sidecar","image":"localhost:5000/sidecar:v2"}]}}}}`),
+			expectedStatus: http.StatusOK,
+			allowed:        false,
+		},
+		{
+			name:           "unsupported kind",
+			review:         admissionReview("ConfigMap", "CREATE", `{"kind":"ConfigMap","metadata":{"name":"config"}}`),
+			expectedStatus: http.StatusOK,
+			allowed:        true,
+		},
+		{
+			name:           "deletion",
+			review:         admissionReview("Pod", "DELETE", `null`),
+			expectedStatus: http.StatusOK,
+			allowed:        true,
+		},
+		{
+			name:           "invalid pod",
+			review:         admissionReview("Pod", "CREATE", `{"kind":"Pod","metadata":{"name":"app"},"spec":{}}`),
+			expectedStatus: http.StatusOK,
+			allowed:        false,
+		},
+		{
+			name:           "missing request",
+			review:         `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview"}`,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			responseRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, admitPath, strings.NewReader(tc.review)))
+			if responseRecorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatus, responseRecorder.Code)
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var review admissionv1.AdmissionReview
+			if err := json.NewDecoder(responseRecorder.Body).Decode(&review); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if review.Response == nil || review.Response.UID != "test-uid" {
+				t.Fatalf("expected response to request test-uid, got %+v", review.Response)
+			}
+			if review.Response.Allowed != tc.allowed {
+				t.Fatalf("expected allowed %v, got %+v", tc.allowed, review.Response)
+			}
+			if !tc.allowed && (review.Response.Result == nil || review.Response.Result.Message == "") {
+				t.Fatalf("expected denied response to explain the failure, got %+v", review.Response)
+			}
+		})
+	}
+}
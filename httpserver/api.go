@@ -0,0 +1,146 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/policyprovider"
+	pc "github.com/deislabs/ratify/pkg/policyprovider/config"
+	pf "github.com/deislabs/ratify/pkg/policyprovider/factory"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	"github.com/docker/distribution/registry/api/errcode"
+)
+
+// APIRootURL is the root of the REST API for clients other than Gatekeeper,
+// e.g. CI systems or other admission controllers.
+const APIRootURL = "/ratify/api/v1"
+
+// verifySubjects verifies the subjects of the request and returns the reports
+// of the verifiers of each subject. The subjects are verified like Gatekeeper
+// requests unless the request overrides the policy, results verified with an
+// overridden policy are not cached.
+func (server *Server) verifySubjects(_ context.Context, w http.ResponseWriter, r *http.Request) error {
+	ctx, cancel := context.WithTimeout(r.Context(), server.GetExecutor().GetVerifyRequestTimeout())
+	defer cancel()
+	ctx = logger.InitContext(ctx, r)
+
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	var request VerifyRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+	}
+	if len(request.Subjects) == 0 {
+		return errors.ErrorCodeBadRequest.WithDetail("no subjects to verify")
+	}
+	if maxKeys := server.RequestLimit.MaxKeys; maxKeys > 0 && len(request.Subjects) > maxKeys {
+		return errors.ErrorCodeRequestLimitExceeded.WithDetail(fmt.Sprintf("request has %d subjects, the maximum is %d", len(request.Subjects), maxKeys))
+	}
+
+	var policyOverride policyprovider.PolicyProvider
+	if request.Policy != nil {
+		if !server.Admin.AllowPolicyOverrides || !server.isAdmin(r) {
+			logger.GetLogger(ctx, server.LogOption).Warnf("policy override from %s rejected", clientIdentity(r))
+			return errcode.ServeJSON(w, errcode.ErrorCodeDenied.WithDetail("policy overrides are only allowed for admin clients if enabled"))
+		}
+		if policyOverride, err = pf.CreatePolicyProviderFromConfig(pc.PoliciesConfig{PolicyPlugin: request.Policy}); err != nil {
+			return errors.ErrorCodeBadRequest.WithError(err).WithDetail("invalid policy override")
+		}
+	}
+
+	response := VerifyResponse{IsSuccess: true, Subjects: make([]SubjectVerification, len(request.Subjects))}
+	err = server.verifyEach(ctx, len(request.Subjects), func(i int, scheduleErr error) {
+		if scheduleErr != nil {
+			response.Subjects[i] = SubjectVerification{Subject: request.Subjects[i], Error: fmt.Sprintf("unable to schedule the verification: %v", scheduleErr)}
+			return
+		}
+		response.Subjects[i] = server.verifySubject(ctx, request.Subjects[i], policyOverride)
+	})
+	if err != nil {
+		logger.GetLogger(ctx, server.LogOption).Warnf("rejecting request with %d subjects: %v", len(request.Subjects), err)
+		w.Header().Set("Retry-After", "1")
+		return errcode.ServeJSON(w, errcode.ErrorCodeUnavailable.WithDetail(err.Error()))
+	}
+	for _, verification := range response.Subjects {
+		response.IsSuccess = response.IsSuccess && verification.IsSuccess
+	}
+	logger.GetLogger(ctx, server.LogOption).Infof("verified %d subjects, success: %v", len(request.Subjects), response.IsSuccess)
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return errors.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to marshal response")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	server.writeVerificationProof(ctx, w, responseBytes, server.GetExecutor().ConfigGeneration)
+	_, err = w.Write(responseBytes)
+	return err
+}
+
+// verifySubject verifies a subject of the REST API with the configured policy
+// or with the policy override of the request. The subject is a plain reference,
+// the qualifiers of Gatekeeper request keys, e.g. the namespace, are rejected.
+func (server *Server) verifySubject(ctx context.Context, subject string, policyOverride policyprovider.PolicyProvider) SubjectVerification {
+	verification := SubjectVerification{Subject: subject}
+	if strings.HasPrefix(subject, "[") {
+		verification.Error = errors.ErrorCodeReferenceInvalid.WithDetail(fmt.Sprintf("subject %s is not a plain reference, request key qualifiers are not supported", subject)).Error()
+		return verification
+	}
+	subjectReference, err := pkgUtils.ParseSubjectReference(subject)
+	if err != nil {
+		verification.Error = errors.ErrorCodeReferenceInvalid.WithError(err).Error()
+		return verification
+	}
+	if policyOverride == nil {
+		item := server.verifyKey(ctx, subject)
+		server.recordUsage(ctx, "", item)
+		server.recordAudit(ctx, "", item)
+		if item.Error != "" {
+			verification.Error = item.Error
+			return verification
+		}
+		result, ok := item.Value.(VerificationResponse)
+		if !ok {
+			verification.Error = fmt.Sprintf("unexpected verification result of subject %s", subject)
+			return verification
+		}
+		verification.IsSuccess = result.IsSuccess
+		verification.Result = &result
+		return verification
+	}
+
+	ex := *server.GetExecutor()
+	ex.PolicyEnforcer = policyOverride
+	result, err := ex.VerifySubject(ctx, executor.VerifyParameters{Subject: subjectReference.Original})
+	if err != nil {
+		verification.Error = errors.ErrorCodeExecutorFailure.WithError(err).WithComponentType(errors.Executor).Error()
+		return verification
+	}
+	response := fromVerifyResult(result, policyOverride.GetPolicyType(ctx), ex.GetReportVersion())
+	verification.IsSuccess = response.IsSuccess
+	verification.Result = &response
+	return verification
+}
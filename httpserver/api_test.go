@@ -0,0 +1,168 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	pc "github.com/deislabs/ratify/pkg/policyprovider/config"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestServer_VerifySubjects(t *testing.T) {
+	testDigest := digest.FromString("test")
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool { return at == testArtifactType },
+			VerifyResult:  func(_ string) bool { return true },
+		}},
+	}
+
+	testCases := []struct {
+		name              string
+		request           VerifyRequest
+		admin             bool
+		expectedStatus    int
+		expectedSubjectOK []bool
+	}{
+		{
+			name:           "no subjects",
+			request:        VerifyRequest{},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:              "configured policy",
+			request:           VerifyRequest{Subjects: []string{"localhost:5000/net-monitor:v1", "&&"}},
+			expectedStatus:    http.StatusOK,
+			expectedSubjectOK: []bool{true, false},
+		},
+		{
+			name:              "request key qualifiers",
+			request:           VerifyRequest{Subjects: []string{"[kube-system]localhost:5000/net-monitor:v1", "[time:2023-06-01T00:00:00Z]localhost:5000/net-monitor:v1"}},
+			expectedStatus:    http.StatusOK,
+			expectedSubjectOK: []bool{false, false},
+		},
+		{
+			name: "policy override",
+			request: VerifyRequest{
+				Subjects: []string{"localhost:5000/net-monitor:v1"},
+				Policy: pc.PolicyPluginConfig{
+					"name": "configpolicy",
+					"artifactVerificationPolicies": map[string]interface{}{
+						testArtifactType:        "any",
+						"application/spdx+json": "all",
+					},
+				},
+			},
+			admin:             true,
+			expectedStatus:    http.StatusOK,
+			expectedSubjectOK: []bool{false},
+		},
+		{
+			name: "policy override from a client that is not an admin",
+			request: VerifyRequest{
+				Subjects: []string{"localhost:5000/net-monitor:v1"},
+				Policy:   pc.PolicyPluginConfig{"name": "configpolicy"},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "invalid policy override",
+			request: VerifyRequest{
+				Subjects: []string{"localhost:5000/net-monitor:v1"},
+				Policy:   pc.PolicyPluginConfig{"name": "unknown"},
+			},
+			admin:          true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(tc.request)
+			if err != nil {
+				t.Fatalf("failed to encode request body: %v", err)
+			}
+			request := httptest.NewRequest(http.MethodPost, APIRootURL+"/verify", bytes.NewReader(body))
+			clientName := "ci"
+			if tc.admin {
+				clientName = "ratify-admin"
+			}
+			request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: clientName}}}}
+			responseRecorder := httptest.NewRecorder()
+			server := &Server{
+				GetExecutor: func() *core.Executor { return ex },
+				Context:     request.Context(),
+				Admin:       AdminConfig{Names: []string{"ratify-admin"}, AllowPolicyOverrides: true},
+			}
+			handler := contextHandler{context: server.Context, handler: server.verifySubjects}
+
+			handler.ServeHTTP(responseRecorder, request)
+			if responseRecorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatus, responseRecorder.Code)
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var response VerifyResponse
+			if err := json.NewDecoder(responseRecorder.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if len(response.Subjects) != len(tc.expectedSubjectOK) {
+				t.Fatalf("expected %d subjects, got %d", len(tc.expectedSubjectOK), len(response.Subjects))
+			}
+			expectedSuccess := true
+			for i, expected := range tc.expectedSubjectOK {
+				verification := response.Subjects[i]
+				if verification.Subject != tc.request.Subjects[i] {
+					t.Fatalf("expected subject %d to be %s, got %s", i, tc.request.Subjects[i], verification.Subject)
+				}
+				if verification.IsSuccess != expected {
+					t.Fatalf("expected subject %s success to be %v, got %+v", verification.Subject, expected, verification)
+				}
+				if verification.Error == "" && (verification.Result == nil || len(verification.Result.VerifierReports) == 0) {
+					t.Fatalf("expected verifier reports of subject %s", verification.Subject)
+				}
+				expectedSuccess = expectedSuccess && expected
+			}
+			if response.IsSuccess != expectedSuccess {
+				t.Fatalf("expected success %v, got %v", expectedSuccess, response.IsSuccess)
+			}
+		})
+	}
+}
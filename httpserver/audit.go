@@ -0,0 +1,267 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor/types"
+	"github.com/deislabs/ratify/pkg/metrics"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AuditSinkStdout is the audit sink target writing the records to stdout.
+	AuditSinkStdout = "stdout"
+
+	auditWebhookQueueLength = 1024
+	auditWebhookTimeout     = 10 * time.Second
+	auditWebhookRetries     = 3
+)
+
+// AuditRecord is the evidence of a verification decision written to the audit
+// log.
+type AuditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	TraceID   string    `json:"traceID,omitempty"`
+	// Key is the request key of the subject, including its qualifiers.
+	Key     string `json:"key"`
+	Subject string `json:"subject"`
+	// SubjectDigest is the digest of the verified subject, it is empty if the
+	// subject was referenced by tag and could not be resolved.
+	SubjectDigest string `json:"subjectDigest,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Allowed       bool   `json:"allowed"`
+	// Warning explains why a subject failing verification was allowed.
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// PolicyType and ConfigGeneration identify the version of the policy the
+	// subject was verified with.
+	PolicyType       string `json:"policyType"`
+	ConfigGeneration int64  `json:"configGeneration"`
+	// ReportsDigest is the sha256 digest of the JSON encoded verifier reports
+	// the decision is based on.
+	ReportsDigest string `json:"reportsDigest,omitempty"`
+}
+
+// AuditSink is an append-only log of verification decisions.
+type AuditSink interface {
+	// Write appends the record to the log.
+	Write(record AuditRecord) error
+	// Close flushes the pending records and releases the log.
+	Close() error
+}
+
+// NewAuditSink returns the audit sink of the target: stdout, an http(s) URL
+// the records are posted to, or the path of a file the records are appended
+// to as JSON lines. Records are not written if the target is empty.
+func NewAuditSink(target string) (AuditSink, error) {
+	switch {
+	case target == "":
+		return nil, nil
+	case target == AuditSinkStdout:
+		return &streamAuditSink{w: os.Stdout}, nil
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return newWebhookAuditSink(target, &http.Client{Timeout: auditWebhookTimeout}), nil
+	default:
+		path := strings.TrimPrefix(target, "file://")
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+		}
+		return &streamAuditSink{w: file, closer: file}, nil
+	}
+}
+
+// streamAuditSink writes the records as JSON lines to a stream.
+type streamAuditSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func (s *streamAuditSink) Write(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+func (s *streamAuditSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// webhookAuditSink posts each record to a URL in the background, so that
+// verification requests do not wait for the webhook.
+type webhookAuditSink struct {
+	url    string
+	client *http.Client
+	queue  chan AuditRecord
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newWebhookAuditSink(url string, client *http.Client) *webhookAuditSink {
+	s := &webhookAuditSink{
+		url:    url,
+		client: client,
+		queue:  make(chan AuditRecord, auditWebhookQueueLength),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues the record, records are dropped if the webhook falls behind.
+func (s *webhookAuditSink) Write(record AuditRecord) error {
+	select {
+	case s.queue <- record:
+		return nil
+	default:
+		return fmt.Errorf("audit webhook queue is full, dropping record of subject %s", record.Subject)
+	}
+}
+
+func (s *webhookAuditSink) Close() error {
+	s.once.Do(func() { close(s.queue) })
+	<-s.done
+	return nil
+}
+
+func (s *webhookAuditSink) run() {
+	defer close(s.done)
+	for record := range s.queue {
+		if err := s.post(record); err != nil {
+			logrus.Errorf("failed to post audit record of subject %s: %v", record.Subject, err)
+			metrics.ReportSystemError(context.Background(), "audit webhook failure")
+		}
+	}
+}
+
+// post sends the record to the webhook, retrying failed deliveries.
+func (s *webhookAuditSink) post(record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		err = s.send(body)
+		if err == nil || attempt == auditWebhookRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt+1) * time.Second)
+	}
+}
+
+func (s *webhookAuditSink) send(body []byte) error {
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("audit webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// recordAudit appends the decision of the request key to the audit log.
+func (server *Server) recordAudit(ctx context.Context, namespace string, item externaldata.Item) {
+	if server.AuditSink == nil {
+		return
+	}
+	executor := server.GetExecutor()
+	record := AuditRecord{
+		Timestamp:        time.Now().UTC(),
+		TraceID:          logger.GetTraceID(ctx),
+		Key:              item.Key,
+		Subject:          item.Key,
+		Namespace:        namespace,
+		Error:            item.Error,
+		ConfigGeneration: executor.ConfigGeneration,
+	}
+	if executor.PolicyEnforcer != nil {
+		record.PolicyType = executor.PolicyEnforcer.GetPolicyType(ctx)
+	}
+	if requestKey, err := pkgUtils.ParseRequestKey(item.Key); err == nil {
+		record.Subject = requestKey.Subject
+		if reference, err := pkgUtils.ParseSubjectReference(requestKey.Subject); err == nil {
+			record.SubjectDigest = reference.Digest.String()
+		}
+	}
+	if response, ok := item.Value.(VerificationResponse); ok {
+		record.Allowed = response.IsSuccess
+		record.Warning = response.Warning
+		record.ReportsDigest = reportsDigest(response)
+		if record.SubjectDigest == "" {
+			record.SubjectDigest = reportedSubjectDigest(response)
+		}
+	}
+	if err := server.AuditSink.Write(record); err != nil {
+		logger.GetLogger(ctx, server.LogOption).Errorf("failed to write audit record of subject %s: %v", record.Subject, err)
+		metrics.ReportSystemError(ctx, "audit log failure")
+	}
+}
+
+// reportsDigest returns the sha256 digest of the verifier reports of the
+// response, which do not change with the request they are returned to.
+func reportsDigest(response VerificationResponse) string {
+	content, err := json.Marshal(struct {
+		VerifierReports []interface{} `json:"verifierReports,omitempty"`
+		ArtifactReports interface{}   `json:"artifactReports,omitempty"`
+	}{response.VerifierReports, response.ArtifactReports})
+	if err != nil {
+		return ""
+	}
+	digest := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+// reportedSubjectDigest returns the digest of the subject resolved during
+// verification of a subject referenced by tag.
+func reportedSubjectDigest(response VerificationResponse) string {
+	reports := response.ArtifactReports
+	if len(reports) == 0 {
+		reports = types.NewArtifactReports(response.VerifierReports)
+	}
+	for _, report := range reports {
+		if reference, err := pkgUtils.ParseSubjectReference(report.Subject); err == nil && reference.Digest != "" {
+			return reference.Digest.String()
+		}
+	}
+	return ""
+}
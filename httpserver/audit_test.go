@@ -0,0 +1,199 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+const auditDigest = "sha256:b556844e6e59451caf4429eb1934a2d7c7d3b8c2b2c0de7e0c0a27bbc4ec1e1b"
+
+type mockAuditSink struct {
+	records []AuditRecord
+}
+
+func (s *mockAuditSink) Write(record AuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *mockAuditSink) Close() error {
+	return nil
+}
+
+func TestServer_RecordAudit(t *testing.T) {
+	ex := &core.Executor{PolicyEnforcer: &configpolicy.PolicyEnforcer{}, ConfigGeneration: 3}
+	testCases := []struct {
+		name     string
+		item     externaldata.Item
+		expected AuditRecord
+	}{
+		{
+			name: "allowed subject",
+			item: externaldata.Item{Key: "[team][operation:CREATE]registry.io/test@" + auditDigest, Value: VerificationResponse{
+				IsSuccess:       true,
+				VerifierReports: []interface{}{verifier.VerifierResult{Name: "notation", IsSuccess: true}},
+			}},
+			expected: AuditRecord{
+				Key:           "[team][operation:CREATE]registry.io/test@" + auditDigest,
+				Subject:       "registry.io/test@" + auditDigest,
+				SubjectDigest: auditDigest,
+				Namespace:     "team",
+				Allowed:       true,
+			},
+		},
+		{
+			name: "tagged subject denied",
+			item: externaldata.Item{Key: "registry.io/test:v1", Value: VerificationResponse{
+				VerifierReports: []interface{}{verifier.VerifierResult{Subject: "registry.io/test@" + auditDigest, Name: "notation"}},
+			}},
+			expected: AuditRecord{
+				Key:           "registry.io/test:v1",
+				Subject:       "registry.io/test:v1",
+				SubjectDigest: auditDigest,
+				Namespace:     "team",
+			},
+		},
+		{
+			name: "verification error",
+			item: externaldata.Item{Key: "registry.io/test:v1", Error: "failed to resolve subject"},
+			expected: AuditRecord{
+				Key:       "registry.io/test:v1",
+				Subject:   "registry.io/test:v1",
+				Namespace: "team",
+				Error:     "failed to resolve subject",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &mockAuditSink{}
+			server := &Server{GetExecutor: func() *core.Executor { return ex }, AuditSink: sink}
+			server.recordAudit(context.Background(), "team", tc.item)
+			if len(sink.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(sink.records))
+			}
+			record := sink.records[0]
+			if record.Timestamp.IsZero() {
+				t.Fatalf("expected timestamp to be set")
+			}
+			if record.PolicyType != "configpolicy" || record.ConfigGeneration != 3 {
+				t.Fatalf("expected policy configpolicy of generation 3, got %s of generation %d", record.PolicyType, record.ConfigGeneration)
+			}
+			if (record.ReportsDigest != "") != (tc.item.Error == "") {
+				t.Fatalf("unexpected reports digest %s", record.ReportsDigest)
+			}
+			record.Timestamp, record.PolicyType, record.ConfigGeneration, record.ReportsDigest = tc.expected.Timestamp, "", 0, ""
+			if record != tc.expected {
+				t.Fatalf("expected record %+v, got %+v", tc.expected, record)
+			}
+		})
+	}
+}
+
+func TestReportsDigest(t *testing.T) {
+	response := VerificationResponse{VerifierReports: []interface{}{verifier.VerifierResult{Name: "notation", IsSuccess: true}}}
+	digest := reportsDigest(response)
+	response.TraceID = "another-request"
+	if reportsDigest(response) != digest {
+		t.Fatalf("expected digest independent of the request")
+	}
+	response.VerifierReports = []interface{}{verifier.VerifierResult{Name: "notation"}}
+	if reportsDigest(response) == digest {
+		t.Fatalf("expected digest to change with the reports")
+	}
+}
+
+func TestNewAuditSink_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		sink, err := NewAuditSink(path)
+		if err != nil {
+			t.Fatalf("NewAuditSink() error = %v", err)
+		}
+		if err := sink.Write(AuditRecord{Subject: "registry.io/test:v1", Allowed: i == 0}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("failed to decode record: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 || !records[0].Allowed || records[1].Allowed {
+		t.Fatalf("expected the records to be appended, got %+v", records)
+	}
+}
+
+func TestNewAuditSink_Webhook(t *testing.T) {
+	received := make(chan AuditRecord, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record AuditRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- record
+	}))
+	defer webhook.Close()
+
+	sink, err := NewAuditSink(webhook.URL)
+	if err != nil {
+		t.Fatalf("NewAuditSink() error = %v", err)
+	}
+	if err := sink.Write(AuditRecord{Subject: "registry.io/test:v1", Allowed: true}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	record := <-received
+	if record.Subject != "registry.io/test:v1" || !record.Allowed {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestNewAuditSink_Disabled(t *testing.T) {
+	sink, err := NewAuditSink("")
+	if err != nil || sink != nil {
+		t.Fatalf("expected no sink, got %v, %v", sink, err)
+	}
+}
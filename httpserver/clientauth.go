@@ -0,0 +1,112 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/sirupsen/logrus"
+)
+
+// ClientAuthConfig restricts the clients allowed to call the endpoints called
+// by Gatekeeper, e.g. to prevent other workloads from calling them in shared
+// clusters. The client certificates are verified against the CA cert file.
+type ClientAuthConfig struct {
+	// AllowedNames are patterns of the common name or of a subject alternative
+	// name of the client certificates allowed to call the verify and mutate
+	// endpoints, e.g. gatekeeper-webhook-service.*.svc. Any client certificate
+	// issued by the CA is allowed if empty.
+	AllowedNames []string
+}
+
+// Validate returns an error if the allowed names are invalid patterns or
+// client certificates are not verified.
+func (c ClientAuthConfig) Validate(caCertFile string) error {
+	if len(c.AllowedNames) == 0 {
+		return nil
+	}
+	if caCertFile == "" {
+		return fmt.Errorf("allowed client names require a CA cert file to verify client certificates")
+	}
+	for _, pattern := range c.AllowedNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowed client name %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// allowed returns true if the common name or a subject alternative name of the
+// certificate matches an allowed name.
+func (c ClientAuthConfig) allowed(cert *x509.Certificate) bool {
+	return matchesName(cert, c.AllowedNames)
+}
+
+// matchesName returns true if the common name or a subject alternative name of
+// the certificate matches one of the patterns.
+func matchesName(cert *x509.Certificate, patterns []string) bool {
+	for _, name := range certificateNames(cert) {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// certificateNames returns the common name and the subject alternative names
+// of the certificate.
+func certificateNames(cert *x509.Certificate) []string {
+	var names []string
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
+
+// authorizeClient rejects requests without a client certificate allowed by
+// the client auth configuration with 401 Unauthorized or 403 Forbidden.
+func (server *Server) authorizeClient(h ContextHandler) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if len(server.ClientAuth.AllowedNames) == 0 {
+			return h(ctx, w, r)
+		}
+		// the TLS handshake verified the certificate chain against the CA
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			logrus.Warnf("request to %s from %s rejected, no client certificate provided", r.URL.Path, clientIdentity(r))
+			return errcode.ServeJSON(w, errcode.ErrorCodeUnauthorized.WithDetail("a client certificate is required"))
+		}
+		if !server.ClientAuth.allowed(r.TLS.PeerCertificates[0]) {
+			logrus.Warnf("request to %s from %s rejected, client certificate is not allowed", r.URL.Path, clientIdentity(r))
+			return errcode.ServeJSON(w, errcode.ErrorCodeDenied.WithDetail("the client certificate is not allowed"))
+		}
+		return h(ctx, w, r)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestClientAuthConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		config     ClientAuthConfig
+		caCertFile string
+		expectErr  bool
+	}{
+		{
+			name: "no allowed names",
+		},
+		{
+			name:       "allowed names with CA cert file",
+			config:     ClientAuthConfig{AllowedNames: []string{"gatekeeper-webhook-service.*.svc"}},
+			caCertFile: "ca.crt",
+		},
+		{
+			name:      "allowed names without CA cert file",
+			config:    ClientAuthConfig{AllowedNames: []string{"gatekeeper"}},
+			expectErr: true,
+		},
+		{
+			name:       "invalid pattern",
+			config:     ClientAuthConfig{AllowedNames: []string{"["}},
+			caCertFile: "ca.crt",
+			expectErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.config.Validate(tc.caCertFile); (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeClient(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://cluster.local/ns/gatekeeper-system/sa/gatekeeper-admin")
+	testCases := []struct {
+		name         string
+		allowedNames []string
+		cert         *x509.Certificate
+		expected     int
+	}{
+		{
+			name:     "any client allowed",
+			expected: http.StatusOK,
+		},
+		{
+			name:         "no client certificate",
+			allowedNames: []string{"gatekeeper"},
+			expected:     http.StatusUnauthorized,
+		},
+		{
+			name:         "common name allowed",
+			allowedNames: []string{"gatekeeper"},
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "gatekeeper"}},
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "DNS name matches pattern",
+			allowedNames: []string{"gatekeeper-webhook-service.*.svc"},
+			cert:         &x509.Certificate{DNSNames: []string{"gatekeeper-webhook-service.gatekeeper-system.svc"}},
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "URI allowed",
+			allowedNames: []string{spiffeID.String()},
+			cert:         &x509.Certificate{URIs: []*url.URL{spiffeID}},
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "client not allowed",
+			allowedNames: []string{"gatekeeper-webhook-service.*.svc"},
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "other"}, DNSNames: []string{"other.default.svc"}},
+			expected:     http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{ClientAuth: ClientAuthConfig{AllowedNames: tc.allowedNames}}
+			handler := server.authorizeClient(func(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			})
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.cert != nil {
+				request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.cert}}
+			}
+			recorder := httptest.NewRecorder()
+			if err := handler(context.Background(), recorder, request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if recorder.Code != tc.expected {
+				t.Fatalf("expected status %d, got %d", tc.expected, recorder.Code)
+			}
+		})
+	}
+}
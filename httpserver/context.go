@@ -17,10 +17,14 @@ package httpserver
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/deislabs/ratify/pkg/tracing"
 	"github.com/deislabs/ratify/utils"
 	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,10 +47,28 @@ func (ch *contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	sanitizedMethod := utils.SanitizeString(r.Method)
 	sanitizedURL := utils.SanitizeURL(*r.URL)
 	logrus.Debugf("received request %s %s ", sanitizedMethod, sanitizedURL)
-	if err := ch.handler(ch.context, w, r); err != nil {
+	path := routePath(r)
+	metrics.ReportInflightRequest(r.Context(), 1, path)
+	defer metrics.ReportInflightRequest(r.Context(), -1, path)
+	ctx, span := tracing.StartServerSpan(r.Context(), fmt.Sprintf("%s %s", sanitizedMethod, path), r.Header)
+	r = r.WithContext(ctx)
+	err := ch.handler(ch.context, w, r)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		logrus.Errorf("request %s %s failed with error %v", sanitizedMethod, sanitizedURL, err)
 		if serveErr := errcode.ServeJSON(w, err); serveErr != nil {
 			logrus.Errorf("request %s %s failed to send with error  %v", sanitizedMethod, sanitizedURL, serveErr)
 		}
 	}
 }
+
+// routePath returns the path template of the route of the request, so that
+// spans and metrics of requests to the same route share their name.
+func routePath(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
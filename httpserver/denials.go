@@ -0,0 +1,94 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/types"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+// DenialRecorder records the subjects failing verification in admission
+// requests, e.g. as Kubernetes Events.
+type DenialRecorder interface {
+	// RecordDenial records the subject failing verification by the verifiers
+	// with the summary of the failures.
+	RecordDenial(subject string, verifiers []string, message string)
+}
+
+// recordDenial records the result of a request key to the denial recorder if
+// the subject failed verification in an admission request. Subjects allowed
+// with a warning, e.g. by the audit enforcement mode, and audit requests are
+// not recorded.
+func (server *Server) recordDenial(ctx context.Context, item externaldata.Item) {
+	if server.DenialRecorder == nil || executor.RequestClassFromContext(ctx) == executor.RequestClassAudit {
+		return
+	}
+	subject := item.Key
+	if requestKey, err := pkgUtils.ParseRequestKey(item.Key); err == nil {
+		subject = requestKey.Subject
+	}
+	if item.Error != "" {
+		server.DenialRecorder.RecordDenial(subject, nil, item.Error)
+		return
+	}
+	response, ok := item.Value.(VerificationResponse)
+	if !ok || response.IsSuccess {
+		return
+	}
+	reports := response.ArtifactReports
+	if len(reports) == 0 {
+		reports = types.NewArtifactReports(response.VerifierReports)
+	}
+	verifiers, message := failedVerifiers(reports)
+	if message == "" {
+		message = "the verification results do not satisfy the policy"
+	}
+	logger.GetLogger(ctx, server.LogOption).Debugf("recording denial of subject %s", subject)
+	server.DenialRecorder.RecordDenial(subject, verifiers, message)
+}
+
+// failedVerifiers returns the distinct names of the failed verifiers of the
+// artifact reports and their first failure message.
+func failedVerifiers(reports []types.ArtifactReport) ([]string, string) {
+	var verifiers []string
+	var message string
+	seen := map[string]bool{}
+	var walk func(reports []types.ArtifactReport)
+	walk = func(reports []types.ArtifactReport) {
+		for _, report := range reports {
+			for _, verifier := range report.VerifierReports {
+				if verifier.IsSuccess {
+					continue
+				}
+				if message == "" {
+					message = verifier.Message
+				}
+				if !seen[verifier.Name] {
+					seen[verifier.Name] = true
+					verifiers = append(verifiers, verifier.Name)
+				}
+			}
+			walk(report.NestedReports)
+		}
+	}
+	walk(reports)
+	return verifiers, message
+}
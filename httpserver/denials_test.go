@@ -0,0 +1,104 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+type denial struct {
+	subject   string
+	verifiers []string
+	message   string
+}
+
+type mockDenialRecorder struct {
+	denials []denial
+}
+
+func (r *mockDenialRecorder) RecordDenial(subject string, verifiers []string, message string) {
+	r.denials = append(r.denials, denial{subject, verifiers, message})
+}
+
+func TestServer_RecordDenial(t *testing.T) {
+	testCases := []struct {
+		name     string
+		item     externaldata.Item
+		expected []denial
+	}{
+		{
+			name: "verification error",
+			item: externaldata.Item{Key: "[default][operation:CREATE]registry.io/test:v1", Error: "failed to resolve subject"},
+			expected: []denial{
+				{subject: "registry.io/test:v1", message: "failed to resolve subject"},
+			},
+		},
+		{
+			name: "failed verifiers",
+			item: externaldata.Item{Key: "registry.io/test:v1", Value: VerificationResponse{
+				VerifierReports: []interface{}{
+					verifier.VerifierResult{Subject: "registry.io/test:v1", Name: "notation", IsSuccess: false, Message: "no trusted signature", ReferenceDigest: "sha256:1"},
+					verifier.VerifierResult{Subject: "registry.io/test:v1", Name: "notation", IsSuccess: false, Message: "expired signature", ReferenceDigest: "sha256:2"},
+					verifier.VerifierResult{Subject: "registry.io/test:v1", Name: "sbom", IsSuccess: true, ReferenceDigest: "sha256:3"},
+				},
+			}},
+			expected: []denial{
+				{subject: "registry.io/test:v1", verifiers: []string{"notation"}, message: "no trusted signature"},
+			},
+		},
+		{
+			name: "no verifier reports",
+			item: externaldata.Item{Key: "registry.io/test:v1", Value: VerificationResponse{}},
+			expected: []denial{
+				{subject: "registry.io/test:v1", message: "the verification results do not satisfy the policy"},
+			},
+		},
+		{
+			name: "success",
+			item: externaldata.Item{Key: "registry.io/test:v1", Value: VerificationResponse{IsSuccess: true}},
+		},
+		{
+			name: "allowed with warning",
+			item: externaldata.Item{Key: "registry.io/test:v1", Value: VerificationResponse{IsSuccess: true, Warning: "audit mode"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &mockDenialRecorder{}
+			server := &Server{DenialRecorder: recorder}
+			server.recordDenial(context.Background(), tc.item)
+			if !reflect.DeepEqual(recorder.denials, tc.expected) {
+				t.Fatalf("expected denials %+v, got %+v", tc.expected, recorder.denials)
+			}
+		})
+	}
+}
+
+func TestServer_RecordDenial_AuditRequest(t *testing.T) {
+	recorder := &mockDenialRecorder{}
+	server := &Server{DenialRecorder: recorder}
+	ctx := executor.WithRequestClass(context.Background(), executor.RequestClassAudit)
+	server.recordDenial(ctx, externaldata.Item{Key: "registry.io/test:v1", Error: "failed to resolve subject"})
+	if len(recorder.denials) != 0 {
+		t.Fatalf("expected no denials of audit requests, got %+v", recorder.denials)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultDrainTimeout is the time in-flight requests are given to complete
+// on shutdown before they are aborted.
+const DefaultDrainTimeout = 6 * time.Second
+
+// drainTracker tracks the in-flight requests of the endpoints called by
+// Gatekeeper and rejects new requests once the server is shutting down.
+type drainTracker struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   uint64
+	inflight map[uint64]inflightRequest
+}
+
+// inflightRequest is a request being served by a drainable endpoint.
+type inflightRequest struct {
+	path  string
+	start time.Time
+}
+
+// begin tracks a request to the path, returns false if the server is
+// draining and the request must be rejected. The returned function ends the
+// tracking of the request.
+func (d *drainTracker) begin(path string) (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return nil, false
+	}
+	if d.inflight == nil {
+		d.inflight = make(map[uint64]inflightRequest)
+	}
+	id := d.nextID
+	d.nextID++
+	d.inflight[id] = inflightRequest{path: path, start: time.Now()}
+	return func() {
+		d.mu.Lock()
+		delete(d.inflight, id)
+		d.mu.Unlock()
+	}, true
+}
+
+// startDraining rejects the requests begun from now on and returns the number
+// of requests in flight.
+func (d *drainTracker) startDraining() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining = true
+	return len(d.inflight)
+}
+
+// abortSummary logs the requests still in flight, which are aborted as the
+// drain timeout expired.
+func (d *drainTracker) abortSummary() {
+	d.mu.Lock()
+	requests := make([]inflightRequest, 0, len(d.inflight))
+	for _, request := range d.inflight {
+		requests = append(requests, request)
+	}
+	d.mu.Unlock()
+	if len(requests) == 0 {
+		return
+	}
+
+	perPath := make(map[string]int)
+	oldest := time.Now()
+	for _, request := range requests {
+		perPath[request.path]++
+		if request.start.Before(oldest) {
+			oldest = request.start
+		}
+	}
+	paths := make([]string, 0, len(perPath))
+	for path := range perPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fields := logrus.Fields{"oldestRequestAge": time.Since(oldest).Round(time.Millisecond).String()}
+	for _, path := range paths {
+		fields[path] = perPath[path]
+	}
+	logrus.WithFields(fields).Warnf("aborting %d in-flight requests at the drain deadline", len(requests))
+}
+
+// drainable tracks the requests to an endpoint called by Gatekeeper, so that
+// they are drained on shutdown, and rejects them with 503 Service Unavailable
+// once the server is shutting down so that Gatekeeper retries another replica.
+func (server *Server) drainable(h ContextHandler) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		end, ok := server.drain.begin(routePath(r))
+		if !ok {
+			w.Header().Set("Connection", "close")
+			return errcode.ServeJSON(w, errcode.ErrorCodeUnavailable.WithDetail("the server is shutting down"))
+		}
+		defer end()
+		return h(ctx, w, r)
+	}
+}
+
+// drainTimeout returns the time in-flight requests are given to complete on
+// shutdown.
+func (server *Server) drainTimeout() time.Duration {
+	if server.DrainTimeout > 0 {
+		return server.DrainTimeout
+	}
+	return DefaultDrainTimeout
+}
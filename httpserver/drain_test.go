@@ -0,0 +1,86 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestDrainTracker(t *testing.T) {
+	var drain drainTracker
+	end, ok := drain.begin("/ratify/gatekeeper/v1/verify")
+	if !ok {
+		t.Fatal("expected request to be accepted before draining")
+	}
+	if _, ok := drain.begin("/ratify/gatekeeper/v1/mutate"); !ok {
+		t.Fatal("expected request to be accepted before draining")
+	}
+	end()
+
+	if inflight := drain.startDraining(); inflight != 1 {
+		t.Fatalf("expected 1 in-flight request, got %d", inflight)
+	}
+	if _, ok := drain.begin("/ratify/gatekeeper/v1/verify"); ok {
+		t.Fatal("expected request to be rejected while draining")
+	}
+
+	hook := test.NewGlobal()
+	drain.abortSummary()
+	entry := hook.LastEntry()
+	if entry == nil || entry.Message != "aborting 1 in-flight requests at the drain deadline" {
+		t.Fatalf("expected summary of the aborted requests, got %v", entry)
+	}
+	if entry.Data["/ratify/gatekeeper/v1/mutate"] != 1 {
+		t.Fatalf("expected aborted requests per path, got %v", entry.Data)
+	}
+}
+
+func TestServer_Drainable(t *testing.T) {
+	server := &Server{}
+	handler := server.drainable(func(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	if err := handler(context.Background(), w, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", nil)); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected request to be served, got status %d, err %v", w.Code, err)
+	}
+
+	server.drain.startDraining()
+	w = httptest.NewRecorder()
+	if err := handler(context.Background(), w, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Connection") != "close" {
+		t.Fatalf("expected request to be rejected with 503 and the connection closed, got status %d", w.Code)
+	}
+}
+
+func TestServer_DrainTimeout(t *testing.T) {
+	if timeout := (&Server{}).drainTimeout(); timeout != DefaultDrainTimeout {
+		t.Fatalf("expected default drain timeout %v, got %v", DefaultDrainTimeout, timeout)
+	}
+	if timeout := (&Server{DrainTimeout: 20 * time.Second}).drainTimeout(); timeout != 20*time.Second {
+		t.Fatalf("expected drain timeout 20s, got %v", timeout)
+	}
+}
@@ -0,0 +1,392 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/experimental/proto/v1/verification"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	"github.com/deislabs/ratify/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// grpcStreamConcurrency limits the subjects of a VerifySubjects stream
+	// verified at once
+	grpcStreamConcurrency = 32
+)
+
+// grpcService serves the verification service over gRPC with the handlers of
+// the HTTP server, so that results are shared through the same cache.
+type grpcService struct {
+	verification.UnimplementedVerificationServiceServer
+	server *Server
+}
+
+// startGRPC starts the gRPC verification service alongside the HTTP server if
+// an address is configured. The service uses the TLS config of the HTTP server.
+func (server *Server) startGRPC(tlsConfig *tls.Config) error {
+	if server.GRPCAddress == "" {
+		return nil
+	}
+	lsnr, err := net.Listen("tcp", server.GRPCAddress)
+	if err != nil {
+		return err
+	}
+	grpcServer := server.newGRPCServer(tlsConfig)
+
+	go func() {
+		sigint := make(chan os.Signal, 1)
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+		<-sigint
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		// open streams are given the drain timeout to complete before they
+		// are canceled
+		select {
+		case <-stopped:
+		case <-time.After(server.drainTimeout()):
+			grpcServer.Stop()
+		}
+	}()
+	go func() {
+		logrus.Infof("starting gRPC verification service on %s", server.GRPCAddress)
+		if err := grpcServer.Serve(lsnr); err != nil {
+			logrus.Errorf("failed to serve gRPC verification service: %v", err)
+		}
+	}()
+	return nil
+}
+
+// newGRPCServer returns a gRPC server serving the verification service to the
+// clients allowed to call the verify endpoints, rate limited like the REST
+// endpoints of the HTTP server.
+func (server *Server) newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(server.grpcUnaryAuthorize, server.grpcUnaryRateLimit),
+		grpc.ChainStreamInterceptor(server.grpcStreamAuthorize, server.grpcStreamRateLimit),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(grpcTLSConfig(tlsConfig))))
+	}
+	if server.RequestLimit.MaxBodyBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(server.RequestLimit.MaxBodyBytes)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	verification.RegisterVerificationServiceServer(grpcServer, &grpcService{server: server})
+	return grpcServer
+}
+
+// grpcTLSConfig returns the TLS config of the HTTP server negotiating HTTP/2
+// for the configs returned per client, gRPC requires HTTP/2.
+func grpcTLSConfig(tlsConfig *tls.Config) *tls.Config {
+	config := tlsConfig.Clone()
+	if getConfigForClient := tlsConfig.GetConfigForClient; getConfigForClient != nil {
+		config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			clientConfig, err := getConfigForClient(hello)
+			if err != nil || clientConfig == nil {
+				return clientConfig, err
+			}
+			clientConfig = clientConfig.Clone()
+			clientConfig.NextProtos = []string{"h2"}
+			return clientConfig, nil
+		}
+	}
+	return config
+}
+
+func (server *Server) grpcUnaryAuthorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := server.authorizeGRPC(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (server *Server) grpcStreamAuthorize(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := server.authorizeGRPC(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authorizeGRPC rejects calls without a client certificate allowed by the
+// client auth configuration with Unauthenticated or PermissionDenied, like
+// authorizeClient rejects requests to the verify endpoints
+func (server *Server) authorizeGRPC(ctx context.Context, method string) error {
+	if len(server.ClientAuth.AllowedNames) == 0 {
+		return nil
+	}
+	// the TLS handshake verified the certificate chain against the CA
+	state, _ := grpcPeer(ctx)
+	peerCertificates := state.PeerCertificates
+	if len(peerCertificates) == 0 {
+		logrus.Warnf("call of %s from %s rejected, no client certificate provided", method, grpcClientIdentity(ctx))
+		return status.Error(codes.Unauthenticated, "a client certificate is required")
+	}
+	if !server.ClientAuth.allowed(peerCertificates[0]) {
+		logrus.Warnf("call of %s from %s rejected, client certificate is not allowed", method, grpcClientIdentity(ctx))
+		return status.Error(codes.PermissionDenied, "the client certificate is not allowed")
+	}
+	return nil
+}
+
+func (server *Server) grpcUnaryRateLimit(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := server.allowGRPC(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (server *Server) grpcStreamRateLimit(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := server.allowGRPC(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// allowGRPC rejects calls of clients exceeding the configured rate with
+// ResourceExhausted, a stream counts as a single call
+func (server *Server) allowGRPC(ctx context.Context, method string) error {
+	if server.RateLimit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	client := grpcClientIdentity(ctx)
+	allowed, retryAfter := server.rateLimiter.allow(server.RateLimit, client, time.Now())
+	if allowed {
+		return nil
+	}
+	logrus.Debugf("rate limit exceeded for client %s", client)
+	metrics.ReportRateLimitedRequest(ctx, method)
+	return status.Errorf(codes.ResourceExhausted, "rate limit of %v requests per second exceeded, retry after %v", server.RateLimit.RequestsPerSecond, retryAfter)
+}
+
+// grpcPeer returns the TLS connection state and the address of the client of
+// a gRPC call
+func grpcPeer(ctx context.Context) (state tls.ConnectionState, remoteAddr string) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			remoteAddr = p.Addr.String()
+		}
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = tlsInfo.State
+		}
+	}
+	return state, remoteAddr
+}
+
+// grpcClientIdentity identifies the client of a gRPC call like clients of the
+// HTTP server
+func grpcClientIdentity(ctx context.Context) string {
+	state, remoteAddr := grpcPeer(ctx)
+	return identifyClient(verifiedCertificates(state), remoteAddr)
+}
+
+// classifyGRPC sets the class of the call on the context, it is the class of
+// the request class metadata if set, audit otherwise, so that calls do not
+// take the admission workers of Gatekeeper requests by default
+func classifyGRPC(ctx context.Context) (context.Context, error) {
+	class := executor.RequestClassAudit
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestClassHeader); len(values) > 0 {
+			var err error
+			if class, err = executor.ParseRequestClass(values[0]); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+	}
+	return executor.WithRequestClass(ctx, class), nil
+}
+
+// VerifySubject verifies a subject against the configured policy.
+func (s *grpcService) VerifySubject(ctx context.Context, request *verification.VerifySubjectRequest) (*verification.VerifySubjectResponse, error) {
+	ctx, err := classifyGRPC(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.server.GetExecutor().GetVerifyRequestTimeout())
+	defer cancel()
+	return s.verifySubject(ctx, request)
+}
+
+// VerifySubjects verifies the subjects of the stream concurrently and sends
+// the response of each subject once its verification completes.
+func (s *grpcService) VerifySubjects(stream verification.VerificationService_VerifySubjectsServer) error {
+	ctx, err := classifyGRPC(stream.Context())
+	if err != nil {
+		return err
+	}
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+	var sendErr error
+	limiter := make(chan struct{}, grpcStreamConcurrency)
+	defer wg.Wait()
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case limiter <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func(request *verification.VerifySubjectRequest) {
+			defer wg.Done()
+			defer func() { <-limiter }()
+			subjectCtx, cancel := context.WithTimeout(ctx, s.server.GetExecutor().GetVerifyRequestTimeout())
+			defer cancel()
+			response, err := s.verifySubject(subjectCtx, request)
+			if err != nil {
+				response = &verification.VerifySubjectResponse{Subject: request.Subject, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if sendErr == nil {
+				sendErr = stream.Send(response)
+			}
+		}(request)
+	}
+}
+
+func (s *grpcService) verifySubject(ctx context.Context, request *verification.VerifySubjectRequest) (*verification.VerifySubjectResponse, error) {
+	if request.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "no subject to verify")
+	}
+	subject := utils.SanitizeString(request.Subject)
+	var result SubjectVerification
+	err := s.server.verifyEach(ctx, 1, func(_ int, scheduleErr error) {
+		if scheduleErr != nil {
+			result = SubjectVerification{Subject: subject, Error: fmt.Sprintf("unable to schedule the verification: %v", scheduleErr)}
+			return
+		}
+		result = s.server.verifySubject(ctx, subject, nil)
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	response := &verification.VerifySubjectResponse{
+		Subject:   request.Subject,
+		IsSuccess: result.IsSuccess,
+		Error:     result.Error,
+	}
+	if result.Result != nil {
+		report, err := toStruct(result.Result)
+		if err != nil {
+			return nil, status.Error(codes.Internal, re.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to encode verification report").Error())
+		}
+		response.Report = report
+	}
+	logger.GetLogger(ctx, s.server.LogOption).Debugf("verified subject %s over gRPC, success: %v", request.Subject, response.IsSuccess)
+	return response, nil
+}
+
+// MutateSubject resolves the tag of a subject to the digest it references.
+func (s *grpcService) MutateSubject(ctx context.Context, request *verification.MutateSubjectRequest) (*verification.MutateSubjectResponse, error) {
+	if request.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "no subject to mutate")
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.server.GetExecutor().GetMutationRequestTimeout())
+	defer cancel()
+	item := s.server.mutateKey(ctx, utils.SanitizeString(request.Subject))
+	if item.Error != "" {
+		return nil, status.Error(codes.FailedPrecondition, item.Error)
+	}
+	mutatedSubject, _ := item.Value.(string)
+	return &verification.MutateSubjectResponse{Subject: request.Subject, MutatedSubject: mutatedSubject}, nil
+}
+
+// ListReferrers lists the referrers of a subject in all referrer stores and
+// sends a response for each page of referrers.
+func (s *grpcService) ListReferrers(request *verification.ListReferrersRequest, stream verification.VerificationService_ListReferrersServer) error {
+	ctx, cancel := context.WithTimeout(stream.Context(), s.server.GetExecutor().GetVerifyRequestTimeout())
+	defer cancel()
+	subjectReference, err := pkgUtils.ParseSubjectReference(utils.SanitizeString(request.Subject))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, re.ErrorCodeReferenceInvalid.WithError(err).Error())
+	}
+	filter := referrerstore.ReferrerFilter{ArtifactTypes: request.ArtifactTypes}
+	for _, store := range s.server.GetExecutor().ReferrerStores {
+		var continuationToken string
+		for {
+			result, err := referrerstore.ListFilteredReferrers(ctx, store, subjectReference, filter, continuationToken, nil)
+			if err != nil {
+				return status.Error(codes.Unavailable, re.ErrorCodeListReferrersFailure.NewError(re.ReferrerStore, store.Name(), re.EmptyLink, err, nil, re.HideStackTrace).Error())
+			}
+			response := &verification.ListReferrersResponse{StoreName: store.Name()}
+			for _, referrer := range result.Referrers {
+				response.Referrers = append(response.Referrers, &verification.Referrer{
+					ArtifactType: referrer.ArtifactType,
+					MediaType:    referrer.MediaType,
+					Digest:       referrer.Digest.String(),
+					Size:         referrer.Size,
+					Annotations:  referrer.Annotations,
+				})
+			}
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			continuationToken = result.NextToken
+			if continuationToken == "" {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// toStruct converts a JSON report to a protobuf struct
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
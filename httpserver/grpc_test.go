@@ -0,0 +1,310 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/deislabs/ratify/experimental/proto/v1/verification"
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTestClient serves the gRPC verification service of the server over
+// an in-memory connection
+func newGRPCTestClient(t *testing.T, server *Server) verification.VerificationServiceClient {
+	t.Helper()
+	lsnr := bufconn.Listen(1024 * 1024)
+	grpcServer := server.newGRPCServer(nil)
+	go func() {
+		_ = grpcServer.Serve(lsnr)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lsnr.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial gRPC server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return verification.NewVerificationServiceClient(conn)
+}
+
+func newGRPCTestServer() *Server {
+	testDigest := digest.FromString("test")
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool { return at == testArtifactType },
+			VerifyResult:  func(_ string) bool { return true },
+		}},
+	}
+	return &Server{
+		GetExecutor:       func() *core.Executor { return ex },
+		Context:           context.Background(),
+		MutationStoreName: "testStore",
+	}
+}
+
+func TestGRPCService_VerifySubject(t *testing.T) {
+	client := newGRPCTestClient(t, newGRPCTestServer())
+
+	response, err := client.VerifySubject(context.Background(), &verification.VerifySubjectRequest{Subject: "localhost:5000/net-monitor:v1"})
+	if err != nil {
+		t.Fatalf("failed to verify subject: %v", err)
+	}
+	if !response.IsSuccess || response.Report == nil {
+		t.Fatalf("expected successful verification with report, got %+v", response)
+	}
+	if isSuccess := response.Report.Fields["isSuccess"].GetBoolValue(); !isSuccess {
+		t.Fatalf("expected report to be successful, got %v", response.Report)
+	}
+
+	response, err = client.VerifySubject(context.Background(), &verification.VerifySubjectRequest{Subject: "&&"})
+	if err != nil {
+		t.Fatalf("failed to verify subject: %v", err)
+	}
+	if response.IsSuccess || response.Error == "" {
+		t.Fatalf("expected verification of invalid subject to fail with error, got %+v", response)
+	}
+
+	_, err = client.VerifySubject(context.Background(), &verification.VerifySubjectRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument for empty subject, got %v", err)
+	}
+}
+
+func TestGRPCService_VerifySubjects(t *testing.T) {
+	client := newGRPCTestClient(t, newGRPCTestServer())
+
+	stream, err := client.VerifySubjects(context.Background())
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	subjects := []string{"localhost:5000/net-monitor:v1", "&&", ""}
+	for _, subject := range subjects {
+		if err := stream.Send(&verification.VerifySubjectRequest{Subject: subject}); err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("failed to close stream: %v", err)
+	}
+
+	results := map[string]bool{}
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to receive response: %v", err)
+		}
+		results[response.Subject] = response.IsSuccess
+	}
+	expected := map[string]bool{"localhost:5000/net-monitor:v1": true, "&&": false, "": false}
+	if len(results) != len(expected) {
+		t.Fatalf("expected responses for %d subjects, got %v", len(expected), results)
+	}
+	for subject, isSuccess := range expected {
+		if results[subject] != isSuccess {
+			t.Fatalf("expected success %v for subject %q, got %v", isSuccess, subject, results[subject])
+		}
+	}
+}
+
+func TestGRPCService_MutateSubject(t *testing.T) {
+	client := newGRPCTestClient(t, newGRPCTestServer())
+
+	response, err := client.MutateSubject(context.Background(), &verification.MutateSubjectRequest{Subject: "localhost:5000/net-monitor:v1"})
+	if err != nil {
+		t.Fatalf("failed to mutate subject: %v", err)
+	}
+	expected := "localhost:5000/net-monitor@" + digest.FromString("test").String()
+	if response.MutatedSubject != expected {
+		t.Fatalf("expected mutated subject %s, got %s", expected, response.MutatedSubject)
+	}
+
+	_, err = client.MutateSubject(context.Background(), &verification.MutateSubjectRequest{Subject: "&&"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected failed precondition for invalid subject, got %v", err)
+	}
+}
+
+func TestGRPCService_ListReferrers(t *testing.T) {
+	client := newGRPCTestClient(t, newGRPCTestServer())
+
+	stream, err := client.ListReferrers(context.Background(), &verification.ListReferrersRequest{Subject: "localhost:5000/net-monitor:v1"})
+	if err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	var referrers []*verification.Referrer
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to receive referrers: %v", err)
+		}
+		if response.StoreName != "testStore" {
+			t.Fatalf("expected referrers of store testStore, got %s", response.StoreName)
+		}
+		referrers = append(referrers, response.Referrers...)
+	}
+	if len(referrers) != 1 || referrers[0].ArtifactType != testArtifactType {
+		t.Fatalf("expected a referrer of type %s, got %v", testArtifactType, referrers)
+	}
+}
+
+func TestGRPCService_RateLimit(t *testing.T) {
+	server := newGRPCTestServer()
+	server.RateLimit = RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}
+	client := newGRPCTestClient(t, server)
+
+	request := &verification.MutateSubjectRequest{Subject: "localhost:5000/net-monitor:v1"}
+	if _, err := client.MutateSubject(context.Background(), request); err != nil {
+		t.Fatalf("expected first call to be allowed, got %v", err)
+	}
+	if _, err := client.MutateSubject(context.Background(), request); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected second call to be rate limited, got %v", err)
+	}
+}
+
+func TestAuthorizeGRPC(t *testing.T) {
+	testCases := []struct {
+		name         string
+		allowedNames []string
+		cert         *x509.Certificate
+		expected     codes.Code
+	}{
+		{
+			name:     "any client allowed",
+			expected: codes.OK,
+		},
+		{
+			name:         "no client certificate",
+			allowedNames: []string{"gatekeeper"},
+			expected:     codes.Unauthenticated,
+		},
+		{
+			name:         "common name allowed",
+			allowedNames: []string{"gatekeeper"},
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "gatekeeper"}},
+			expected:     codes.OK,
+		},
+		{
+			name:         "client not allowed",
+			allowedNames: []string{"gatekeeper-webhook-service.*.svc"},
+			cert:         &x509.Certificate{Subject: pkix.Name{CommonName: "other"}, DNSNames: []string{"other.default.svc"}},
+			expected:     codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{ClientAuth: ClientAuthConfig{AllowedNames: tc.allowedNames}}
+			ctx := context.Background()
+			if tc.cert != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.cert}}}})
+			}
+			if err := server.authorizeGRPC(ctx, "/verification.VerificationService/VerifySubject"); status.Code(err) != tc.expected {
+				t.Fatalf("expected code %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestGRPCService_ClientAuth(t *testing.T) {
+	server := newGRPCTestServer()
+	server.ClientAuth = ClientAuthConfig{AllowedNames: []string{"gatekeeper"}}
+	client := newGRPCTestClient(t, server)
+
+	if _, err := client.VerifySubject(context.Background(), &verification.VerifySubjectRequest{Subject: "localhost:5000/net-monitor:v1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected call without client certificate to be rejected, got %v", err)
+	}
+	stream, err := client.VerifySubjects(context.Background())
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected stream without client certificate to be rejected, got %v", err)
+	}
+}
+
+func TestClassifyGRPC(t *testing.T) {
+	ctx, err := classifyGRPC(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if class := executor.RequestClassFromContext(ctx); class != executor.RequestClassAudit {
+		t.Fatalf("expected class %q by default, got %q", executor.RequestClassAudit, class)
+	}
+
+	ctx, err = classifyGRPC(metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestClassHeader, "admission")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if class := executor.RequestClassFromContext(ctx); class != executor.RequestClassAdmission {
+		t.Fatalf("expected class %q of the metadata, got %q", executor.RequestClassAdmission, class)
+	}
+
+	if _, err := classifyGRPC(metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestClassHeader, "background"))); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument for invalid class, got %v", err)
+	}
+}
+
+func TestGRPCService_VerifySubject_QueueFull(t *testing.T) {
+	server := newGRPCTestServer()
+	server.RequestLimit = RequestLimitConfig{MaxConcurrentVerifications: 1, MaxQueuedVerifications: 1}
+	if _, err := server.verificationPool.enqueue(executor.RequestClassAudit, 1, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := newGRPCTestClient(t, server)
+
+	if _, err := client.VerifySubject(context.Background(), &verification.VerifySubjectRequest{Subject: "localhost:5000/net-monitor:v1"}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected unavailable while the audit queue is full, got %v", err)
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
@@ -28,9 +27,12 @@ import (
 	"github.com/deislabs/ratify/internal/logger"
 	"github.com/deislabs/ratify/pkg/cache"
 	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/core"
 	"github.com/deislabs/ratify/pkg/executor/types"
 	"github.com/deislabs/ratify/pkg/metrics"
-	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/policyprovider"
+	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
+	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
 	pkgUtils "github.com/deislabs/ratify/pkg/utils"
 	"github.com/deislabs/ratify/utils"
 
@@ -44,108 +46,346 @@ const apiVersion = "externaldata.gatekeeper.sh/v1alpha1"
 // e.g.
 // 1. docker.io/library/nginx:latest an image without a namespace would be evaluated by cluster-wide policy.
 // 2. [ratify]docker.io/library/nginx:latest an image with a namespace would be evaluated by namespaced policy.
+// 3. [ratify][operation:UPDATE]docker.io/library/nginx:latest an image with the admission operation would be evaluated
+// by the policy configured for that operation.
 func (server *Server) verify(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	startTime := time.Now()
 	sanitizedMethod := utils.SanitizeString(r.Method)
 	sanitizedURL := utils.SanitizeURL(*r.URL)
 	logger.GetLogger(ctx, server.LogOption).Debugf("start request %s %s", sanitizedMethod, sanitizedURL)
 
-	body, err := io.ReadAll(r.Body)
+	providerRequest, err := server.readProviderRequest(w, r)
 	if err != nil {
-		return fmt.Errorf("unable to read request body: %w", err)
+		return err
 	}
-	defer r.Body.Close()
 
-	// parse request body
-	var providerRequest externaldata.ProviderRequest
-	if err = json.Unmarshal(body, &providerRequest); err != nil {
-		return fmt.Errorf("unable to unmarshal request body: %w", err)
+	results, cached := server.cachedResponse(ctx, providerRequest.Request.Keys)
+	if !cached {
+		if results, err = server.verifyKeys(ctx, providerRequest.Request.Keys); err != nil {
+			logger.GetLogger(ctx, server.LogOption).Warnf("rejecting request with %d keys: %v", len(providerRequest.Request.Keys), err)
+			w.Header().Set("Retry-After", "1")
+			return sendResponse(nil, err.Error(), w, http.StatusServiceUnavailable, false)
+		}
+		server.cacheResponse(ctx, providerRequest.Request.Keys, results)
 	}
+	elapsedTime := time.Since(startTime).Milliseconds()
+	logger.GetLogger(ctx, server.LogOption).Debugf("verification: execution time for request: %dms", elapsedTime)
+	metrics.ReportVerificationRequest(ctx, elapsedTime)
 
-	results := make([]externaldata.Item, 0)
-	wg := sync.WaitGroup{}
+	body, err := json.Marshal(newProviderResponse(&results, "", false))
+	if err != nil {
+		return errors.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to marshal response")
+	}
+	server.writeVerificationProof(ctx, w, body, server.GetExecutor().ConfigGeneration)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+	return err
+}
+
+// verifyKeys verifies the subjects of the request keys with the workers of
+// the verification pool, keys of the same subject are verified once. The
+// results are in the order the verifications complete.
+func (server *Server) verifyKeys(ctx context.Context, keys []string) ([]externaldata.Item, error) {
+	sanitizedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		sanitizedKeys[i] = utils.SanitizeString(key)
+	}
+	results := make([]externaldata.Item, 0, len(keys))
 	mu := sync.Mutex{}
+	verifications := &subjectVerifications{}
+	deduplicate := len(keys) > 1
+
+	err := server.verifyEach(ctx, len(keys), func(i int, scheduleErr error) {
+		key := sanitizedKeys[i]
+		keyCtx, namespace := requestNamespace(ctx, key)
+		returnItem := externaldata.Item{Key: key}
+		switch {
+		case scheduleErr != nil:
+			returnItem.Error = fmt.Sprintf("unable to schedule the verification: %v", scheduleErr)
+		case deduplicate:
+			returnItem = server.verifyKeyOnce(keyCtx, key, verifications)
+		default:
+			returnItem = server.verifyKey(keyCtx, key)
+		}
+		server.recordUsage(keyCtx, namespace, returnItem)
+		server.recordAudit(keyCtx, namespace, returnItem)
+		server.recordDenial(keyCtx, returnItem)
+		mu.Lock()
+		results = append(results, returnItem)
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-	// iterate over all keys
-	for _, key := range providerRequest.Request.Keys {
-		wg.Add(1)
-		go func(key string) {
-			defer wg.Done()
-			routineStartTime := time.Now()
-			returnItem := externaldata.Item{
-				Key: key,
-			}
-			defer func() {
-				mu.Lock()
-				results = append(results, returnItem)
-				mu.Unlock()
-			}()
-			requestKey, err := pkgUtils.ParseRequestKey(key)
-			if err != nil {
-				returnItem.Error = err.Error()
-				return
-			}
-			subjectReference, err := pkgUtils.ParseSubjectReference(requestKey.Subject)
-			if err != nil {
-				returnItem.Error = err.Error()
-				return
-			}
-			if subjectReference.Digest.String() == "" {
-				logger.GetLogger(ctx, server.LogOption).Warn("Digest should be used instead of tagged reference. The resolved digest may not point to the same signed artifact, since tags are mutable.")
-			}
-			resolvedSubjectReference := subjectReference.Original
-			unlock := server.keyMutex.Lock(resolvedSubjectReference)
-			defer unlock()
-
-			logger.GetLogger(ctx, server.LogOption).Infof("verifying subject %v", resolvedSubjectReference)
-			var result types.VerifyResult
-			found := false
-			cacheHit := false
-			var cacheResponse string
-			cacheProvider := cache.GetCacheProvider()
-			if cacheProvider != nil {
-				cacheResponse, found = cacheProvider.Get(ctx, fmt.Sprintf(cache.CacheKeyVerifyHandler, resolvedSubjectReference))
-			}
-			if found && cacheResponse != "" {
-				if err := json.Unmarshal([]byte(cacheResponse), &result); err != nil {
-					err = errors.ErrorCodeDataDecodingFailure.WithError(err).WithDetail(fmt.Sprintf("unable to unmarshal cache entry for subject %v", resolvedSubjectReference))
-					logger.GetLogger(ctx, server.LogOption).Warn(err)
-				} else {
-					cacheHit = true
-					logger.GetLogger(ctx, server.LogOption).Debugf("cache hit for subject %v", resolvedSubjectReference)
-				}
-			}
-			if !cacheHit {
-				verifyParameters := executor.VerifyParameters{
-					Subject: resolvedSubjectReference,
-				}
-
-				if result, err = server.GetExecutor().VerifySubject(ctx, verifyParameters); err != nil {
-					returnItem.Error = errors.ErrorCodeExecutorFailure.WithError(err).WithComponentType(errors.Executor).Error()
-					return
-				}
-
-				if cacheProvider != nil {
-					logger.GetLogger(ctx, server.LogOption).Debugf("cache miss for subject %v", resolvedSubjectReference)
-					if !cacheProvider.SetWithTTL(ctx, fmt.Sprintf(cache.CacheKeyVerifyHandler, resolvedSubjectReference), result, server.CacheTTL) {
-						logger.GetLogger(ctx, server.LogOption).Warnf("unable to insert cache entry for subject %v", resolvedSubjectReference)
-					}
-				}
-
-				if res, err := json.MarshalIndent(result, "", "  "); err == nil {
-					logger.GetLogger(ctx, server.LogOption).Infof("verify result for subject %s: %s", resolvedSubjectReference, string(res))
-				}
+// subjectVerification is the result of verifying a subject shared by the keys
+// of a request resolving to the same subject.
+type subjectVerification struct {
+	once sync.Once
+	item externaldata.Item
+}
+
+// subjectVerifications are the verifications of the subjects of a request,
+// indexed by the request key of the subject referenced by digest.
+type subjectVerifications struct {
+	mu            sync.Mutex
+	verifications map[string]*subjectVerification
+}
+
+func (v *subjectVerifications) get(id string) *subjectVerification {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.verifications == nil {
+		v.verifications = map[string]*subjectVerification{}
+	}
+	if _, ok := v.verifications[id]; !ok {
+		v.verifications[id] = &subjectVerification{}
+	}
+	return v.verifications[id]
+}
+
+// verifyKeyOnce verifies the subject of the request key once for all keys of
+// the request resolving to the same subject, e.g. different tags of a sidecar
+// image, and fans the result out to the keys.
+func (server *Server) verifyKeyOnce(ctx context.Context, key string, verifications *subjectVerifications) externaldata.Item {
+	id, err := server.subjectID(ctx, key)
+	if err != nil {
+		// the error is reported by the verification of the key
+		return server.verifyKey(ctx, key)
+	}
+
+	verification := verifications.get(id)
+	verified := false
+	verification.once.Do(func() {
+		verification.item = server.verifyKey(ctx, key)
+		verified = true
+	})
+	if !verified {
+		logger.GetLogger(ctx, server.LogOption).Debugf("subject of key %s already verified as %s in the request", key, id)
+		metrics.ReportDeduplicatedVerification(ctx)
+	}
+	item := verification.item
+	item.Key = key
+	return item
+}
+
+// subjectID returns the request key with the subject referenced by digest,
+// keys of the same subject and qualifiers are verified once per request.
+func (server *Server) subjectID(ctx context.Context, key string) (string, error) {
+	requestKey, err := pkgUtils.ParseRequestKey(key)
+	if err != nil {
+		return "", err
+	}
+	subject, err := server.GetExecutor().ResolveSubject(ctx, requestKey.Subject)
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("[%s][operation:%s]%s", requestKey.Namespace, requestKey.Operation, subject)
+	if !requestKey.VerificationTime.IsZero() {
+		id = fmt.Sprintf("[time:%s]%s", requestKey.VerificationTime.UTC().Format(time.RFC3339), id)
+	}
+	return id, nil
+}
+
+// verifyKey verifies the subject of the request key, the result is cached for
+// subsequent requests of the same key.
+func (server *Server) verifyKey(ctx context.Context, key string) externaldata.Item {
+	routineStartTime := time.Now()
+	returnItem := externaldata.Item{
+		Key: key,
+	}
+	requestKey, err := pkgUtils.ParseRequestKey(key)
+	if err != nil {
+		returnItem.Error = err.Error()
+		return returnItem
+	}
+	subjectReference, err := pkgUtils.ParseSubjectReference(requestKey.Subject)
+	if err != nil {
+		returnItem.Error = err.Error()
+		return returnItem
+	}
+	if subjectReference.Digest.String() == "" {
+		logger.GetLogger(ctx, server.LogOption).Warn("Digest should be used instead of tagged reference. The resolved digest may not point to the same signed artifact, since tags are mutable.")
+	}
+	resolvedSubjectReference := subjectReference.Original
+	cacheKey := resolvedSubjectReference
+	if requestKey.Operation != "" {
+		cacheKey = fmt.Sprintf("%s_%s", requestKey.Operation, resolvedSubjectReference)
+	}
+	var verificationTime *time.Time
+	if !requestKey.VerificationTime.IsZero() {
+		verificationTime = &requestKey.VerificationTime
+		cacheKey = fmt.Sprintf("%s_%s", requestKey.VerificationTime.UTC().Format(time.RFC3339), cacheKey)
+	}
+	// pins approve the current admission of a digest, they do not apply to
+	// verifications at a past time
+	if verificationTime == nil {
+		if result, ok := server.verifyPin(ctx, resolvedSubjectReference, subjectReference.Digest.String()); ok {
+			response := fromVerifyResult(result, server.GetExecutor().PolicyEnforcer.GetPolicyType(ctx), server.GetExecutor().GetReportVersion())
+			response.TraceID = logger.GetTraceID(ctx)
+			server.printReport(resolvedSubjectReference, response)
+			returnItem.Value = response
+			return returnItem
+		}
+	}
+	// the slot of the request class is taken before the locks of the subject,
+	// an audit verification waiting for a slot must not block admission
+	// requests of the same subject
+	ctx, release, err := core.Schedule(ctx)
+	if err != nil {
+		returnItem.Error = errors.ErrorCodeExecutorFailure.WithError(err).WithComponentType(errors.Executor).Error()
+		return returnItem
+	}
+	defer release()
+	unlock := server.keyMutex.Lock(resolvedSubjectReference)
+	defer unlock()
+	// the replica holding the lock caches the result for the others waiting
+	if server.DistributedLock != nil {
+		release, err := server.DistributedLock.Lock(ctx, resolvedSubjectReference)
+		if err != nil {
+			logger.GetLogger(ctx, server.LogOption).Warnf("failed to acquire the distributed lock of subject %v, verifying without it: %v", resolvedSubjectReference, err)
+		} else {
+			defer release()
+		}
+	}
+
+	logger.GetLogger(ctx, server.LogOption).Infof("verifying subject %v", resolvedSubjectReference)
+	var result types.VerifyResult
+	found := false
+	cacheHit := false
+	var cacheResponse string
+	cacheProvider := cache.GetCacheProvider()
+	if cacheProvider != nil {
+		cacheResponse, found = cacheProvider.Get(ctx, fmt.Sprintf(cache.CacheKeyVerifyHandler, cacheKey))
+	}
+	if found && cacheResponse != "" {
+		if err := json.Unmarshal([]byte(cacheResponse), &result); err != nil {
+			err = errors.ErrorCodeDataDecodingFailure.WithError(err).WithDetail(fmt.Sprintf("unable to unmarshal cache entry for subject %v", resolvedSubjectReference))
+			logger.GetLogger(ctx, server.LogOption).Warn(err)
+		} else {
+			cacheHit = true
+			logger.GetLogger(ctx, server.LogOption).Debugf("cache hit for subject %v", resolvedSubjectReference)
+		}
+	}
+	if !cacheHit {
+		verifyParameters := executor.VerifyParameters{
+			Subject:          resolvedSubjectReference,
+			Operation:        requestKey.Operation,
+			VerificationTime: verificationTime,
+		}
+
+		if result, err = server.GetExecutor().VerifySubject(ctx, verifyParameters); err != nil {
+			returnItem.Error = errors.ErrorCodeExecutorFailure.WithError(err).WithComponentType(errors.Executor).Error()
+			return returnItem
+		}
+
+		if cacheProvider != nil {
+			logger.GetLogger(ctx, server.LogOption).Debugf("cache miss for subject %v", resolvedSubjectReference)
+			if ttl, ok := cacheTTL(result, server.CacheTTL, time.Now()); !ok {
+				logger.GetLogger(ctx, server.LogOption).Debugf("trust material of subject %v is about to expire, skipping cache entry", resolvedSubjectReference)
+			} else if !cacheProvider.SetWithTTL(ctx, fmt.Sprintf(cache.CacheKeyVerifyHandler, cacheKey), result, ttl) {
+				logger.GetLogger(ctx, server.LogOption).Warnf("unable to insert cache entry for subject %v", resolvedSubjectReference)
 			}
+		}
 
-			returnItem.Value = fromVerifyResult(result, server.GetExecutor().PolicyEnforcer.GetPolicyType(ctx))
-			logger.GetLogger(ctx, server.LogOption).Debugf("verification: execution time for image %s: %dms", resolvedSubjectReference, time.Since(routineStartTime).Milliseconds())
-		}(utils.SanitizeString(key))
+		if res, err := json.MarshalIndent(result, "", "  "); err == nil {
+			logger.GetLogger(ctx, server.LogOption).Infof("verify result for subject %s: %s", resolvedSubjectReference, string(res))
+		}
 	}
-	wg.Wait()
-	elapsedTime := time.Since(startTime).Milliseconds()
-	logger.GetLogger(ctx, server.LogOption).Debugf("verification: execution time for request: %dms", elapsedTime)
-	metrics.ReportVerificationRequest(ctx, elapsedTime)
-	return sendResponse(&results, "", w, http.StatusOK, false)
+
+	response := fromVerifyResult(result, server.GetExecutor().PolicyEnforcer.GetPolicyType(ctx), server.GetExecutor().GetReportVersion())
+	response.TraceID = logger.GetTraceID(ctx)
+	server.applyEnforcementMode(ctx, resolvedSubjectReference, &response)
+	server.printReport(resolvedSubjectReference, response)
+	returnItem.Value = response
+	logger.GetLogger(ctx, server.LogOption).Debugf("verification: execution time for image %s: %dms", resolvedSubjectReference, time.Since(routineStartTime).Milliseconds())
+	return returnItem
+}
+
+// cacheTTL returns the TTL of the cache entry of a verify result, bounded by
+// the earliest expiry of the trust material the result relies on so that
+// cached decisions never outlive it. Returns false if the result must not be
+// cached since its trust material expires within a second, the granularity
+// of the TTL of the external cache.
+func cacheTTL(result types.VerifyResult, ttl time.Duration, now time.Time) (time.Duration, bool) {
+	return ttlUntil(result.ValidUntil(), ttl, now)
+}
+
+// ttlUntil returns the TTL bounded by the expiry of the trust material, false
+// if the trust material expires within a second.
+func ttlUntil(validUntil *time.Time, ttl time.Duration, now time.Time) (time.Duration, bool) {
+	if validUntil == nil {
+		return ttl, true
+	}
+	remaining := validUntil.Sub(now)
+	if remaining < time.Second {
+		return 0, false
+	}
+	// a TTL of zero does not expire the entry
+	if ttl <= 0 || remaining < ttl {
+		return remaining, true
+	}
+	return ttl, true
+}
+
+// applyEnforcementMode allows a subject failing verification with a warning if
+// the policy is in audit mode. The verify result is cached as is, so that the
+// mode applies to cached results once it is changed.
+func (server *Server) applyEnforcementMode(ctx context.Context, subject string, response *VerificationResponse) {
+	policyEnforcer := server.GetExecutor().PolicyEnforcer
+	if response.IsSuccess || policyprovider.GetEnforcementMode(ctx, policyEnforcer) != pt.EnforcementModeAudit {
+		return
+	}
+	response.IsSuccess = true
+	response.Warning = fmt.Sprintf("verification of subject %s failed, the subject is allowed since the policy is in %s mode", subject, pt.EnforcementModeAudit)
+	logger.GetLogger(ctx, server.LogOption).Warn(response.Warning)
+	metrics.ReportAuditedVerificationFailure(ctx, policyEnforcer.GetPolicyType(ctx))
+}
+
+// verifyContent validates a subject and its referrers supplied in the request
+// body against the configured policy without fetching them from a registry.
+func (server *Server) verifyContent(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	body, err := server.readContentBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	var request VerifyContentRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+	}
+
+	verifyParameters := executor.VerifyParameters{
+		Subject:          request.SubjectReference(request.Repository),
+		VerificationTime: request.VerificationTime,
+	}
+	logger.GetLogger(ctx, server.LogOption).Infof("verifying supplied content of subject %v", verifyParameters.Subject)
+	var result types.VerifyResult
+	var verifyErr error
+	err = server.verifyEach(ctx, 1, func(_ int, scheduleErr error) {
+		if verifyErr = scheduleErr; verifyErr == nil {
+			result, verifyErr = server.GetExecutor().VerifyContent(ctx, verifyParameters, request.Content)
+		}
+	})
+	if err != nil {
+		logger.GetLogger(ctx, server.LogOption).Warnf("rejecting content of subject %v: %v", verifyParameters.Subject, err)
+		w.Header().Set("Retry-After", "1")
+		return sendResponse(nil, err.Error(), w, http.StatusServiceUnavailable, false)
+	}
+	if verifyErr != nil {
+		return errors.ErrorCodeExecutorFailure.WithError(verifyErr).WithComponentType(errors.Executor)
+	}
+
+	response, err := json.Marshal(fromVerifyResult(result, server.GetExecutor().PolicyEnforcer.GetPolicyType(ctx), server.GetExecutor().GetReportVersion()))
+	if err != nil {
+		return errors.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to marshal response")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	server.writeVerificationProof(ctx, w, response, server.GetExecutor().ConfigGeneration)
+	_, err = w.Write(response)
+	return err
 }
 
 func (server *Server) mutate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -154,15 +394,9 @@ func (server *Server) mutate(ctx context.Context, w http.ResponseWriter, r *http
 	sanitizedURL := utils.SanitizeURL(*r.URL)
 	logger.GetLogger(ctx, server.LogOption).Debugf("start request %s %s", sanitizedMethod, sanitizedURL)
 
-	body, err := io.ReadAll(r.Body)
+	providerRequest, err := server.readProviderRequest(w, r)
 	if err != nil {
-		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to read request body")
-	}
-
-	// parse request body
-	var providerRequest externaldata.ProviderRequest
-	if err = json.Unmarshal(body, &providerRequest); err != nil {
-		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+		return err
 	}
 
 	results := make([]externaldata.Item, 0)
@@ -173,48 +407,10 @@ func (server *Server) mutate(ctx context.Context, w http.ResponseWriter, r *http
 		wg.Add(1)
 		go func(image string) {
 			defer wg.Done()
-			routineStartTime := time.Now()
-			logger.GetLogger(ctx, server.LogOption).Infof("mutating image %v", image)
-			returnItem := externaldata.Item{
-				Key:   image,
-				Value: image,
-			}
-			defer func() {
-				mu.Lock()
-				results = append(results, returnItem)
-				mu.Unlock()
-			}()
-			parsedReference, err := pkgUtils.ParseSubjectReference(image)
-			if err != nil {
-				err = errors.ErrorCodeReferenceInvalid.WithError(err).WithDetail(fmt.Sprintf("failed to parse image reference %s", image))
-				logger.GetLogger(ctx, server.LogOption).Error(err)
-				returnItem.Error = err.Error()
-				return
-			}
-
-			if parsedReference.Digest == "" {
-				var selectedStore referrerstore.ReferrerStore
-				for _, store := range server.GetExecutor().ReferrerStores {
-					if store.Name() == server.MutationStoreName {
-						selectedStore = store
-						break
-					}
-				}
-				if selectedStore == nil {
-					err := errors.ErrorCodeReferrerStoreFailure.WithDetail(fmt.Sprintf("failed to mutate image reference %s: could not find matching store %s", image, server.MutationStoreName)).WithComponentType(errors.ReferrerStore)
-					logger.GetLogger(ctx, server.LogOption).Error(err)
-					returnItem.Error = err.Error()
-					return
-				}
-				descriptor, err := selectedStore.GetSubjectDescriptor(ctx, parsedReference)
-				if err != nil {
-					err = errors.ErrorCodeGetSubjectDescriptorFailure.NewError(errors.ReferrerStore, selectedStore.Name(), errors.EmptyLink, err, fmt.Sprintf("failed to get subject descriptor for image %s", image), errors.HideStackTrace)
-					returnItem.Error = err.Error()
-					return
-				}
-				returnItem.Value = fmt.Sprintf("%s@%s", parsedReference.Path, descriptor.Digest.String())
-			}
-			logger.GetLogger(ctx, server.LogOption).Debugf("mutation: execution time for image %s: %dms", image, time.Since(routineStartTime).Milliseconds())
+			returnItem := server.mutateKey(ctx, image)
+			mu.Lock()
+			results = append(results, returnItem)
+			mu.Unlock()
 		}(utils.SanitizeString(image))
 	}
 	wg.Wait()
@@ -224,7 +420,40 @@ func (server *Server) mutate(ctx context.Context, w http.ResponseWriter, r *http
 	return sendResponse(&results, "", w, http.StatusOK, true)
 }
 
+// mutateKey resolves the tag of the image to the digest it references, or to
+// the digest of the manifest of the mutation platform if the tag references an
+// index, images referenced by digest are returned as is once checked as
+// selected by the mode for digested references.
+func (server *Server) mutateKey(ctx context.Context, image string) externaldata.Item {
+	routineStartTime := time.Now()
+	logger.GetLogger(ctx, server.LogOption).Infof("mutating image %v", image)
+	returnItem := externaldata.Item{
+		Key:   image,
+		Value: image,
+	}
+	mutated, err := su.ResolveMutatedReference(ctx, server.GetExecutor().ReferrerStores, image, su.MutationOptions{
+		StoreName:          server.MutationStoreName,
+		FallbackStoreNames: server.MutationFallbackStoreNames,
+		Platform:           server.MutationPlatform,
+		DigestedReferences: server.MutationDigestedReferences,
+		FailurePolicies:    server.GetExecutor().GetMutationFailurePolicies(),
+	})
+	if err != nil {
+		logger.GetLogger(ctx, server.LogOption).Error(err)
+		returnItem.Error = err.Error()
+		return returnItem
+	}
+	returnItem.Value = mutated
+	logger.GetLogger(ctx, server.LogOption).Debugf("mutation: execution time for image %s: %dms", image, time.Since(routineStartTime).Milliseconds())
+	return returnItem
+}
+
 func sendResponse(results *[]externaldata.Item, systemErr string, w http.ResponseWriter, respCode int, isMutation bool) error {
+	w.WriteHeader(respCode)
+	return json.NewEncoder(w).Encode(newProviderResponse(results, systemErr, isMutation))
+}
+
+func newProviderResponse(results *[]externaldata.Item, systemErr string, isMutation bool) externaldata.ProviderResponse {
 	response := externaldata.ProviderResponse{
 		APIVersion: apiVersion,
 		Kind:       "ProviderResponse",
@@ -239,9 +468,7 @@ func sendResponse(results *[]externaldata.Item, systemErr string, w http.Respons
 		metrics.ReportSystemError(context.Background(), systemErr) // this context should not be tied to the lifetime of the request
 		response.Response.SystemError = systemErr
 	}
-
-	w.WriteHeader(respCode)
-	return json.NewEncoder(w).Encode(response)
+	return response
 }
 
 func processTimeout(h ContextHandler, duration time.Duration, isMutation bool) ContextHandler {
@@ -250,6 +477,7 @@ func processTimeout(h ContextHandler, duration time.Duration, isMutation bool) C
 		defer cancel()
 
 		ctx = logger.InitContext(ctx, r)
+		w.Header().Set(logger.RequestIDHeader, logger.GetTraceID(ctx))
 
 		r = r.WithContext(ctx)
 
@@ -267,7 +495,11 @@ func processTimeout(h ContextHandler, duration time.Duration, isMutation bool) C
 		}
 
 		if err != nil {
-			return sendResponse(nil, fmt.Sprintf("operation failed with error %v", err), w, http.StatusInternalServerError, isMutation)
+			respCode := http.StatusInternalServerError
+			if errors.CodeOf(err, errors.ErrorCodeUnknown) == errors.ErrorCodeRequestLimitExceeded {
+				respCode = http.StatusRequestEntityTooLarge
+			}
+			return sendResponse(nil, fmt.Sprintf("operation failed with error %v", err), w, respCode, isMutation)
 		}
 
 		return nil
@@ -0,0 +1,60 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/executor/types"
+	"github.com/deislabs/ratify/pkg/verifier"
+)
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Now()
+	validUntil := func(d time.Duration) types.VerifyResult {
+		expiry := now.Add(d)
+		return types.VerifyResult{
+			IsSuccess: true,
+			VerifierReports: []interface{}{
+				verifier.VerifierResult{IsSuccess: true},
+				verifier.VerifierResult{IsSuccess: true, ValidUntil: &expiry},
+			},
+		}
+	}
+	testCases := []struct {
+		name     string
+		result   types.VerifyResult
+		ttl      time.Duration
+		expected time.Duration
+		cached   bool
+	}{
+		{name: "no expiry", result: types.VerifyResult{IsSuccess: true}, ttl: time.Minute, expected: time.Minute, cached: true},
+		{name: "expiry after ttl", result: validUntil(time.Hour), ttl: time.Minute, expected: time.Minute, cached: true},
+		{name: "expiry before ttl", result: validUntil(30 * time.Second), ttl: time.Minute, expected: 30 * time.Second, cached: true},
+		{name: "expiry without ttl", result: validUntil(time.Hour), ttl: 0, expected: time.Hour, cached: true},
+		{name: "expiring", result: validUntil(time.Millisecond), ttl: time.Minute},
+		{name: "expired", result: validUntil(-time.Hour), ttl: time.Minute},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttl, cached := cacheTTL(tc.result, tc.ttl, now)
+			if cached != tc.cached || ttl != tc.expected {
+				t.Fatalf("expected ttl %v cached %v, got %v %v", tc.expected, tc.cached, ttl, cached)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/verifier"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// HealthCheckConfig selects the optional dependency checks of the readiness
+// endpoint. The executor and the verifier plugins are always checked.
+type HealthCheckConfig struct {
+	// Registries fails the readiness check if a referrer store failed to
+	// connect to a registry on its last request. Registries are not contacted
+	// by the check.
+	Registries bool
+	// KeyManagementProviders fails the readiness check if the last fetch of
+	// the certificates and keys of a key management provider failed.
+	KeyManagementProviders bool
+}
+
+// HealthCheck is the result of a single check of the health endpoints.
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthResponse is the response of the health endpoints.
+type HealthResponse struct {
+	Success bool          `json:"success"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// healthz reports that the server is able to serve requests.
+func (server *Server) healthz(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+	return writeHealthResponse(w, []HealthCheck{{Name: "ping", Success: true}})
+}
+
+// readyz reports whether the executor is ready to verify subjects, it
+// responds with 503 Service Unavailable if a check fails.
+func (server *Server) readyz(ctx context.Context, w http.ResponseWriter, _ *http.Request) error {
+	return writeHealthResponse(w, server.readinessChecks(ctx))
+}
+
+// readinessChecks returns the results of the readiness checks of the executor
+// and the dependencies selected by the health check configuration.
+func (server *Server) readinessChecks(ctx context.Context) []HealthCheck {
+	executor := server.GetExecutor()
+	switch {
+	case executor == nil:
+		return []HealthCheck{{Name: "executor", Message: "executor is not initialized"}}
+	case executor.PolicyEnforcer == nil:
+		return []HealthCheck{{Name: "executor", Message: "no policy provider is configured"}}
+	case len(executor.ReferrerStores) == 0:
+		return []HealthCheck{{Name: "executor", Message: "no referrer store is configured"}}
+	case len(executor.Verifiers) == 0:
+		return []HealthCheck{{Name: "executor", Message: "no verifier is configured"}}
+	}
+	checks := []HealthCheck{{Name: "executor", Success: true}}
+
+	for _, v := range executor.Verifiers {
+		check := HealthCheck{Name: "verifier/" + v.Name(), Success: true}
+		if err := verifier.CheckHealth(ctx, v); err != nil {
+			check.Success = false
+			check.Message = err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	if server.HealthChecks.Registries {
+		for _, store := range executor.ReferrerStores {
+			reporter, ok := store.(referrerstore.ConnectivityReporter)
+			if !ok {
+				continue
+			}
+			check := HealthCheck{Name: "store/" + store.Name(), Success: true}
+			if attempted, err := reporter.RegistryConnectivity(); attempted && err != nil {
+				check.Success = false
+				check.Message = fmt.Sprintf("failed to connect to registry: %v", err)
+			}
+			checks = append(checks, check)
+		}
+	}
+
+	if server.HealthChecks.KeyManagementProviders {
+		fetchErrors := keymanagementprovider.GetFetchErrors()
+		resources := make([]string, 0, len(fetchErrors))
+		for resource := range fetchErrors {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			checks = append(checks, HealthCheck{Name: "keyManagementProvider/" + resource, Message: fetchErrors[resource]})
+		}
+	}
+	return checks
+}
+
+func writeHealthResponse(w http.ResponseWriter, checks []HealthCheck) error {
+	response := HealthResponse{Success: true, Checks: checks}
+	for _, check := range checks {
+		response.Success = response.Success && check.Success
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !response.Success {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return json.NewEncoder(w).Encode(response)
+}
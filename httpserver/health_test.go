@@ -0,0 +1,177 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+)
+
+type healthCheckVerifier struct {
+	core.TestVerifier
+	err error
+}
+
+func (v *healthCheckVerifier) CheckHealth(_ context.Context) error {
+	return v.err
+}
+
+type connectivityStore struct {
+	mocks.TestStore
+	err error
+}
+
+func (s *connectivityStore) RegistryConnectivity() (bool, error) {
+	return true, s.err
+}
+
+func TestReadyz(t *testing.T) {
+	testCases := []struct {
+		name           string
+		executor       *core.Executor
+		healthChecks   HealthCheckConfig
+		fetchErrors    map[string]string
+		expectedStatus int
+		expectedFailed []string
+	}{
+		{
+			name:           "executor not initialized",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedFailed: []string{"executor"},
+		},
+		{
+			name: "no verifier configured",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{}},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedFailed: []string{"executor"},
+		},
+		{
+			name: "healthy verifiers",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{}},
+				Verifiers:      []verifier.ReferenceVerifier{&core.TestVerifier{}, &healthCheckVerifier{}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unhealthy verifier",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{}},
+				Verifiers:      []verifier.ReferenceVerifier{&healthCheckVerifier{err: errors.New("plugin not found")}},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedFailed: []string{"verifier/verifier-testVerifier"},
+		},
+		{
+			name: "registry check disabled",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&connectivityStore{err: errors.New("connection refused")}},
+				Verifiers:      []verifier.ReferenceVerifier{&core.TestVerifier{}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "registry unreachable",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&connectivityStore{err: errors.New("connection refused")}},
+				Verifiers:      []verifier.ReferenceVerifier{&core.TestVerifier{}},
+			},
+			healthChecks:   HealthCheckConfig{Registries: true},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedFailed: []string{"store/testStore"},
+		},
+		{
+			name: "key management provider fetch failed",
+			executor: &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{}},
+				Verifiers:      []verifier.ReferenceVerifier{&core.TestVerifier{}},
+			},
+			healthChecks:   HealthCheckConfig{KeyManagementProviders: true},
+			fetchErrors:    map[string]string{"ns/kmp": "vault unreachable"},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedFailed: []string{"keyManagementProvider/ns/kmp"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for resource, fetchError := range tc.fetchErrors {
+				keymanagementprovider.SetFetchError(resource, fetchError)
+				defer keymanagementprovider.SetFetchError(resource, "")
+			}
+			server := &Server{
+				GetExecutor:  func() *core.Executor { return tc.executor },
+				HealthChecks: tc.healthChecks,
+			}
+			request := httptest.NewRequest(http.MethodGet, readyzPath, nil)
+			recorder := httptest.NewRecorder()
+			if err := server.readyz(context.Background(), recorder, request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatus, recorder.Code)
+			}
+			var response HealthResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var failed []string
+			for _, check := range response.Checks {
+				if !check.Success {
+					failed = append(failed, check.Name)
+				}
+			}
+			if len(failed) != len(tc.expectedFailed) {
+				t.Fatalf("expected failed checks %v, got %v", tc.expectedFailed, failed)
+			}
+			for i := range failed {
+				if failed[i] != tc.expectedFailed[i] {
+					t.Fatalf("expected failed checks %v, got %v", tc.expectedFailed, failed)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	server := &Server{}
+	recorder := httptest.NewRecorder()
+	if err := server.healthz(context.Background(), recorder, httptest.NewRequest(http.MethodGet, healthzPath, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
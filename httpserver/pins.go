@@ -0,0 +1,221 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor/types"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	vr "github.com/deislabs/ratify/pkg/verifier"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxPinDuration bounds the expiry of a pin so that pinned digests are
+	// verified again once the signing infrastructure is restored.
+	maxPinDuration  = 7 * 24 * time.Hour
+	pinVerifierName = "pin"
+)
+
+// Pin approves a digest without verification until it expires.
+type Pin struct {
+	// Digest is the digest of the pinned image, e.g. sha256:abc...
+	Digest string `json:"digest"`
+	// Justification explains why the digest is approved without verification.
+	Justification string `json:"justification"`
+	// RequestedBy is the operator requesting the pin.
+	RequestedBy string `json:"requestedBy"`
+	// Expiry is the time the pin expires, at most 7 days after its creation.
+	Expiry time.Time `json:"expiry"`
+	// CreatedAt is the time the pin was created.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// pinStore is an in-memory store of the pinned digests of a replica. Pins are
+// not shared with other replicas and are lost on restart, the pin API is only
+// served if enabled for a single replica.
+type pinStore struct {
+	mu   sync.Mutex
+	pins map[string]Pin
+}
+
+// add stores the pin, replacing the pin of the same digest.
+func (s *pinStore) add(pin Pin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pins == nil {
+		s.pins = map[string]Pin{}
+	}
+	s.pins[pin.Digest] = pin
+}
+
+// remove deletes the pin of the digest, returns false if it is not pinned.
+func (s *pinStore) remove(digest string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	if _, ok := s.pins[digest]; !ok {
+		return false
+	}
+	delete(s.pins, digest)
+	return true
+}
+
+// get returns the unexpired pin of the digest.
+func (s *pinStore) get(digest string, now time.Time) (Pin, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	pin, ok := s.pins[digest]
+	return pin, ok
+}
+
+// list returns the unexpired pins sorted by digest.
+func (s *pinStore) list(now time.Time) []Pin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	pins := make([]Pin, 0, len(s.pins))
+	for _, pin := range s.pins {
+		pins = append(pins, pin)
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].Digest < pins[j].Digest
+	})
+	return pins
+}
+
+// prune deletes the expired pins, the caller must hold the lock.
+func (s *pinStore) prune(now time.Time) {
+	for digest, pin := range s.pins {
+		if !now.Before(pin.Expiry) {
+			delete(s.pins, digest)
+			logrus.Infof("audit: pin of digest %s requested by %s expired at %s", digest, pin.RequestedBy, pin.Expiry.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
+// pinsEnabled responds to requests to the pin API with 404 Not Found unless
+// pins are enabled.
+func (server *Server) pinsEnabled(h ContextHandler) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if !server.Admin.EnablePins {
+			http.NotFound(w, r)
+			return nil
+		}
+		return h(ctx, w, r)
+	}
+}
+
+// pin approves a digest until its expiry so that emergency deployments are
+// admitted while the signing infrastructure is unavailable.
+func (server *Server) pin(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	var pin Pin
+	if err = json.Unmarshal(body, &pin); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+	}
+	now := time.Now()
+	if err = validatePin(&pin, now); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err)
+	}
+	pin.CreatedAt = now
+	server.pins.add(pin)
+	server.responses.clear()
+	logger.GetLogger(ctx, server.LogOption).Warnf("audit: digest %s pinned by %s (client %s) until %s, justification: %s", pin.Digest, pin.RequestedBy, clientIdentity(r), pin.Expiry.UTC().Format(time.RFC3339), pin.Justification)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(pin)
+}
+
+// unpin removes the pin of the digest in the request path.
+func (server *Server) unpin(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	digest := mux.Vars(r)["digest"]
+	if !server.pins.remove(digest, time.Now()) {
+		http.Error(w, fmt.Sprintf("digest %s is not pinned", digest), http.StatusNotFound)
+		return nil
+	}
+	server.responses.clear()
+	logger.GetLogger(ctx, server.LogOption).Warnf("audit: digest %s unpinned by client %s", digest, clientIdentity(r))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// listPins returns the unexpired pins.
+func (server *Server) listPins(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(server.pins.list(time.Now()))
+}
+
+// verifyPin returns an approved result if the digest of the subject is pinned.
+func (server *Server) verifyPin(ctx context.Context, subject string, digest string) (types.VerifyResult, bool) {
+	if digest == "" || !server.Admin.EnablePins {
+		return types.VerifyResult{}, false
+	}
+	pin, ok := server.pins.get(digest, time.Now())
+	if !ok {
+		return types.VerifyResult{}, false
+	}
+	logger.GetLogger(ctx, server.LogOption).Warnf("audit: subject %s admitted without verification by pin of %s requested by %s, justification: %s", subject, pin.Digest, pin.RequestedBy, pin.Justification)
+	return types.VerifyResult{
+		IsSuccess: true,
+		VerifierReports: []interface{}{vr.VerifierResult{
+			Subject:    subject,
+			IsSuccess:  true,
+			Name:       pinVerifierName,
+			Type:       pinVerifierName,
+			ValidUntil: &pin.Expiry,
+			Message:    fmt.Sprintf("digest pinned by %s until %s: %s", pin.RequestedBy, pin.Expiry.UTC().Format(time.RFC3339), pin.Justification),
+		}},
+	}, true
+}
+
+func validatePin(pin *Pin, now time.Time) error {
+	digest, err := pkgUtils.ParseDigest(pin.Digest)
+	if err != nil {
+		return err
+	}
+	pin.Digest = digest.String()
+	if strings.TrimSpace(pin.Justification) == "" {
+		return fmt.Errorf("justification of the pin is required")
+	}
+	if strings.TrimSpace(pin.RequestedBy) == "" {
+		return fmt.Errorf("requester of the pin is required")
+	}
+	if !pin.Expiry.After(now) {
+		return fmt.Errorf("expiry of the pin must be in the future")
+	}
+	if pin.Expiry.Sub(now) > maxPinDuration {
+		return fmt.Errorf("expiry of the pin must be within %s", maxPinDuration)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestValidatePin(t *testing.T) {
+	now := time.Now()
+	testDigest := digest.FromString("test").String()
+	testCases := []struct {
+		name      string
+		pin       Pin
+		expectErr bool
+	}{
+		{
+			name: "valid pin",
+			pin:  Pin{Digest: testDigest, Justification: "signing service outage", RequestedBy: "oncall", Expiry: now.Add(time.Hour)},
+		},
+		{
+			name:      "invalid digest",
+			pin:       Pin{Digest: "sha256:invalid", Justification: "signing service outage", RequestedBy: "oncall", Expiry: now.Add(time.Hour)},
+			expectErr: true,
+		},
+		{
+			name:      "missing justification",
+			pin:       Pin{Digest: testDigest, RequestedBy: "oncall", Expiry: now.Add(time.Hour)},
+			expectErr: true,
+		},
+		{
+			name:      "missing requester",
+			pin:       Pin{Digest: testDigest, Justification: "signing service outage", Expiry: now.Add(time.Hour)},
+			expectErr: true,
+		},
+		{
+			name:      "expired",
+			pin:       Pin{Digest: testDigest, Justification: "signing service outage", RequestedBy: "oncall", Expiry: now},
+			expectErr: true,
+		},
+		{
+			name:      "expiry too far",
+			pin:       Pin{Digest: testDigest, Justification: "signing service outage", RequestedBy: "oncall", Expiry: now.Add(maxPinDuration + time.Hour)},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validatePin(&tc.pin, now); tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestServer_Pins(t *testing.T) {
+	testDigest := digest.FromString("test")
+	subject := "localhost:5000/net-monitor@" + testDigest.String()
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return false
+			},
+		}},
+	}
+	ctx := context.Background()
+	server, err := NewServer(ctx, "localhost:0", func() *core.Executor { return ex }, "", "", 0, false, "", 0)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	isSuccess := func() bool {
+		item := server.verifyKey(ctx, subject)
+		if item.Error != "" {
+			t.Fatalf("unexpected error: %s", item.Error)
+		}
+		return item.Value.(VerificationResponse).IsSuccess
+	}
+	clientCert := &x509.Certificate{Subject: pkix.Name{CommonName: "ratify-admin"}}
+	serveAs := func(cert *x509.Certificate, method, path string, body []byte) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, ServerRootURL+path, bytes.NewReader(body))
+		request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		responseRecorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(responseRecorder, request)
+		return responseRecorder
+	}
+	serve := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		return serveAs(clientCert, method, path, body)
+	}
+
+	if isSuccess() {
+		t.Fatalf("expected unpinned subject to fail verification")
+	}
+
+	body, _ := json.Marshal(Pin{Digest: testDigest.String(), Justification: "signing service outage", RequestedBy: "oncall", Expiry: time.Now().Add(time.Hour)})
+	if code := serve(http.MethodPost, "/pins", body).Code; code != http.StatusNotFound {
+		t.Fatalf("expected status %d while pins are disabled, got %d", http.StatusNotFound, code)
+	}
+	server.Admin = AdminConfig{Names: []string{"ratify-admin"}, EnablePins: true}
+	if code := serveAs(&x509.Certificate{Subject: pkix.Name{CommonName: "gatekeeper"}}, http.MethodPost, "/pins", body).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status %d for a client that is not an admin, got %d", http.StatusForbidden, code)
+	}
+	if code := serve(http.MethodPost, "/pins", body).Code; code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	var pins []Pin
+	if err := json.NewDecoder(serve(http.MethodGet, "/pins", nil).Body).Decode(&pins); err != nil || len(pins) != 1 || pins[0].CreatedAt.IsZero() {
+		t.Fatalf("unexpected pins %v, err: %v", pins, err)
+	}
+	if !isSuccess() {
+		t.Fatalf("expected pinned subject to be approved")
+	}
+
+	if code := serve(http.MethodDelete, "/pins/"+testDigest.String(), nil).Code; code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, code)
+	}
+	if code := serve(http.MethodDelete, "/pins/"+testDigest.String(), nil).Code; code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+	if isSuccess() {
+		t.Fatalf("expected unpinned subject to fail verification")
+	}
+
+	server.pins.add(Pin{Digest: testDigest.String(), Expiry: time.Now().Add(-time.Second)})
+	if isSuccess() || len(server.pins.list(time.Now())) != 0 {
+		t.Fatalf("expected expired pin to be ignored and removed")
+	}
+}
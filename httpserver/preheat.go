@@ -0,0 +1,161 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+)
+
+const (
+	// preheatWorkers is the number of subjects verified concurrently in the background
+	preheatWorkers = 4
+	// preheatQueueLength is the number of subjects waiting to be verified, requests exceeding it are rejected
+	preheatQueueLength = 1000
+)
+
+// PreheatSubject is a newly pushed image to verify before its first admission.
+type PreheatSubject struct {
+	// Subject is the reference of the image, e.g. myregistry.io/app:v1.
+	Subject string `json:"subject"`
+	// Digest is the digest of the image. It is required unless the subject is
+	// referenced by digest, since admission requests reference images by digest.
+	Digest string `json:"digest,omitempty"`
+}
+
+// PreheatRequest is the request body of the preheat endpoint.
+type PreheatRequest struct {
+	Subjects []PreheatSubject `json:"subjects"`
+	// Operations are the admission operations, e.g. CREATE, to verify the subjects
+	// for if Gatekeeper passes the operation in the request keys.
+	Operations []string `json:"operations,omitempty"`
+}
+
+// PreheatResponse is the response of the preheat endpoint.
+type PreheatResponse struct {
+	// Keys are the request keys queued for verification.
+	Keys []string `json:"keys"`
+}
+
+// preheatQueue verifies subjects in the background to populate the cache.
+type preheatQueue struct {
+	once sync.Once
+	keys chan string
+}
+
+// preheat queues the subjects of the request to be verified in the background
+// so that their results are cached before their first admission. Preheating
+// pulls from registries on behalf of the client, so it is served to admin
+// clients only.
+func (server *Server) preheat(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	var request PreheatRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+	}
+	keys, err := preheatKeys(request)
+	if err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err)
+	}
+
+	server.preheatQueue.once.Do(func() {
+		server.preheatQueue.keys = make(chan string, preheatQueueLength)
+		for i := 0; i < preheatWorkers; i++ {
+			go server.runPreheatWorker()
+		}
+	})
+	if len(keys) > cap(server.preheatQueue.keys)-len(server.preheatQueue.keys) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, fmt.Sprintf("unable to queue %d subjects, preheat queue is full", len(keys)), http.StatusServiceUnavailable)
+		return nil
+	}
+	for _, key := range keys {
+		server.preheatQueue.keys <- key
+	}
+	logger.GetLogger(ctx, server.LogOption).Infof("queued %d subjects for preheating", len(keys))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(PreheatResponse{Keys: keys})
+}
+
+// runPreheatWorker verifies the queued keys until the server shuts down.
+func (server *Server) runPreheatWorker() {
+	for {
+		select {
+		case <-server.Context.Done():
+			return
+		case key := <-server.preheatQueue.keys:
+			ctx, cancel := context.WithTimeout(server.Context, server.GetExecutor().GetVerifyRequestTimeout())
+			// preheating is background work, it must not delay admission requests
+			item := server.verifyKey(executor.WithRequestClass(ctx, executor.RequestClassAudit), key)
+			cancel()
+			if item.Error != "" {
+				logger.GetLogger(server.Context, server.LogOption).Warnf("failed to preheat subject %s: %s", key, item.Error)
+			}
+		}
+	}
+}
+
+// preheatKeys returns the request keys of the subjects in the format sent by
+// Gatekeeper, so that the cached results are found by admission requests.
+func preheatKeys(request PreheatRequest) ([]string, error) {
+	if len(request.Subjects) == 0 {
+		return nil, fmt.Errorf("no subjects to preheat")
+	}
+	operations := []string{""}
+	if len(request.Operations) > 0 {
+		operations = request.Operations
+	}
+
+	keys := make([]string, 0, len(request.Subjects)*len(operations))
+	for _, subject := range request.Subjects {
+		subjectRef, err := pkgUtils.ParseSubjectReference(subject.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subject %s: %w", subject.Subject, err)
+		}
+		if subject.Digest != "" {
+			if subjectRef.Digest, err = pkgUtils.ParseDigest(subject.Digest); err != nil {
+				return nil, err
+			}
+		}
+		if subjectRef.Digest == "" {
+			return nil, fmt.Errorf("digest of subject %s is required", subject.Subject)
+		}
+		reference := fmt.Sprintf("%s@%s", subjectRef.Path, subjectRef.Digest)
+		for _, operation := range operations {
+			if operation == "" {
+				keys = append(keys, reference)
+				continue
+			}
+			keys = append(keys, fmt.Sprintf("[operation:%s]%s", strings.ToUpper(operation), reference))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestPreheatKeys(t *testing.T) {
+	testDigest := digest.FromString("test")
+	testCases := []struct {
+		name      string
+		request   PreheatRequest
+		keys      []string
+		expectErr bool
+	}{
+		{
+			name:    "tagged subject with digest",
+			request: PreheatRequest{Subjects: []PreheatSubject{{Subject: "localhost:5000/net-monitor:v1", Digest: testDigest.String()}}},
+			keys:    []string{"localhost:5000/net-monitor@" + testDigest.String()},
+		},
+		{
+			name: "digested subject with operations",
+			request: PreheatRequest{
+				Subjects:   []PreheatSubject{{Subject: "localhost:5000/net-monitor@" + testDigest.String()}},
+				Operations: []string{"create", "UPDATE"},
+			},
+			keys: []string{
+				"[operation:CREATE]localhost:5000/net-monitor@" + testDigest.String(),
+				"[operation:UPDATE]localhost:5000/net-monitor@" + testDigest.String(),
+			},
+		},
+		{
+			name:      "subject without digest",
+			request:   PreheatRequest{Subjects: []PreheatSubject{{Subject: "localhost:5000/net-monitor:v1"}}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid digest",
+			request:   PreheatRequest{Subjects: []PreheatSubject{{Subject: "localhost:5000/net-monitor:v1", Digest: "sha256:invalid"}}},
+			expectErr: true,
+		},
+		{
+			name:      "no subjects",
+			request:   PreheatRequest{},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := preheatKeys(tc.request)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatalf("expected keys %v, got %v", tc.keys, keys)
+			}
+		})
+	}
+}
+
+func TestServer_Preheat(t *testing.T) {
+	testDigest := digest.FromString("test")
+	verified := make(chan struct{}, 1)
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			// preheated subjects are referenced by digest
+			ResolveMap: map[string]digest.Digest{"": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				verified <- struct{}{}
+				return true
+			},
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     ctx,
+		Admin:       AdminConfig{Names: []string{"ratify-admin"}},
+	}
+	handler := contextHandler{context: ctx, handler: server.authorizeAdmin(server.preheat)}
+	serveAs := func(cert *x509.Certificate, body []byte) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/preheat", bytes.NewReader(body))
+		request.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		responseRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(responseRecorder, request)
+		return responseRecorder
+	}
+	adminCert := &x509.Certificate{Subject: pkix.Name{CommonName: "ratify-admin"}}
+
+	body, _ := json.Marshal(PreheatRequest{Subjects: []PreheatSubject{{Subject: "localhost:5000/net-monitor:v1", Digest: testDigest.String()}}})
+	if code := serveAs(&x509.Certificate{Subject: pkix.Name{CommonName: "gatekeeper"}}, body).Code; code != http.StatusForbidden {
+		t.Fatalf("expected status %d for a client that is not an admin, got %d", http.StatusForbidden, code)
+	}
+	responseRecorder := serveAs(adminCert, body)
+	if responseRecorder.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, responseRecorder.Code)
+	}
+	var response PreheatResponse
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&response); err != nil || len(response.Keys) != 1 {
+		t.Fatalf("unexpected response %v, err: %v", response, err)
+	}
+	select {
+	case <-verified:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected subject to be verified in the background")
+	}
+
+	if code := serveAs(adminCert, []byte(`{"subjects":[{"subject":"localhost:5000/net-monitor:v1"}]}`)).Code; code == http.StatusAccepted {
+		t.Fatalf("expected subject without digest to be rejected")
+	}
+
+	server.RequestLimit = RequestLimitConfig{MaxBodyBytes: int64(len(body) - 1)}
+	if code := serveAs(adminCert, body).Code; code == http.StatusAccepted {
+		t.Fatalf("expected request exceeding the maximum body size to be rejected")
+	}
+}
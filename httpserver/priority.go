@@ -0,0 +1,45 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/executor"
+)
+
+// RequestClassHeader is the header selecting the class of a verify request,
+// admission or audit. Verifications of audit requests wait while admission
+// verifications are queued.
+const RequestClassHeader = "X-Ratify-Request-Class"
+
+// classify sets the class of the request on the context passed to h and on
+// the context of the request, it is the class of the request class header if
+// set, the default class otherwise.
+func classify(h ContextHandler, defaultClass executor.RequestClass) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		class := defaultClass
+		if value := r.Header.Get(RequestClassHeader); value != "" {
+			var err error
+			if class, err = executor.ParseRequestClass(value); err != nil {
+				return errors.ErrorCodeBadRequest.WithError(err).WithDetail("invalid request class header")
+			}
+		}
+		return h(executor.WithRequestClass(ctx, class), w, r.WithContext(executor.WithRequestClass(r.Context(), class)))
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/executor"
+	exconfig "github.com/deislabs/ratify/pkg/executor/config"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name          string
+		defaultClass  executor.RequestClass
+		header        string
+		expectedClass executor.RequestClass
+		expectErr     bool
+	}{
+		{
+			name:          "verify endpoint",
+			defaultClass:  executor.RequestClassAdmission,
+			expectedClass: executor.RequestClassAdmission,
+		},
+		{
+			name:          "audit endpoint",
+			defaultClass:  executor.RequestClassAudit,
+			expectedClass: executor.RequestClassAudit,
+		},
+		{
+			name:          "audit header",
+			defaultClass:  executor.RequestClassAdmission,
+			header:        "Audit",
+			expectedClass: executor.RequestClassAudit,
+		},
+		{
+			name:         "invalid header",
+			defaultClass: executor.RequestClassAdmission,
+			header:       "background",
+			expectErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var class, requestClass executor.RequestClass
+			handler := classify(func(ctx context.Context, _ http.ResponseWriter, r *http.Request) error {
+				class = executor.RequestClassFromContext(ctx)
+				requestClass = executor.RequestClassFromContext(r.Context())
+				return nil
+			}, tc.defaultClass)
+
+			r := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", nil)
+			if tc.header != "" {
+				r.Header.Set(RequestClassHeader, tc.header)
+			}
+			err := handler(context.Background(), httptest.NewRecorder(), r)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if class != tc.expectedClass || (!tc.expectErr && requestClass != tc.expectedClass) {
+				t.Fatalf("expected class %q, got %q and %q of the request", tc.expectedClass, class, requestClass)
+			}
+		})
+	}
+}
+
+func TestServer_VerifyKey_QueuedAuditDoesNotBlockAdmission(t *testing.T) {
+	core.ConfigureScheduler(exconfig.PriorityConfig{MaxConcurrentAudit: 1})
+	defer core.ConfigureScheduler(exconfig.PriorityConfig{})
+
+	testDigest := digest.FromString("test")
+	subject := "localhost:5000/net-monitor@" + testDigest.String()
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool { return at == testArtifactType },
+			VerifyResult:  func(_ string) bool { return true },
+		}},
+		Config: &exconfig.ExecutorConfig{},
+	}
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     context.Background(),
+	}
+
+	// occupy the only audit slot so that the audit verification of the subject waits
+	auditCtx := executor.WithRequestClass(context.Background(), executor.RequestClassAudit)
+	_, release, err := core.Schedule(auditCtx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auditDone := make(chan externaldata.Item, 1)
+	go func() {
+		auditDone <- server.verifyKey(auditCtx, subject)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	admissionDone := make(chan externaldata.Item, 1)
+	go func() {
+		admissionDone <- server.verifyKey(context.Background(), subject)
+	}()
+	select {
+	case item := <-admissionDone:
+		if item.Error != "" || !item.Value.(VerificationResponse).IsSuccess {
+			t.Fatalf("expected admission verification to succeed, got %+v", item)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected admission verification not to wait for the queued audit verification of the same subject")
+	}
+
+	release()
+	select {
+	case item := <-auditDone:
+		if item.Error != "" {
+			t.Fatalf("unexpected error: %s", item.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected audit verification to complete once the audit slot is released")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/deislabs/ratify/internal/logger"
+)
+
+const (
+	// ReportDigestHeader is the sha256 digest of the response body.
+	ReportDigestHeader = "Ratify-Report-Digest"
+	// ConfigGenerationHeader is the generation of the configuration the
+	// subjects were verified with.
+	ConfigGenerationHeader = "Ratify-Config-Generation"
+	// ReportSignatureHeader is the base64 encoded detached signature of the
+	// report digest, it is only set if a report signing key is configured.
+	ReportSignatureHeader = "Ratify-Report-Signature"
+)
+
+// LoadReportSigningKey reads the PEM encoded RSA or ECDSA private key used to
+// sign the digests of verification reports.
+func LoadReportSigningKey(path string) (crypto.Signer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report signing key: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("report signing key %s is not PEM encoded", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report signing key: %w", err)
+	}
+	switch signer := key.(type) {
+	case *rsa.PrivateKey:
+		return signer, nil
+	case *ecdsa.PrivateKey:
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported report signing key type %T, RSA and ECDSA keys are supported", key)
+	}
+}
+
+// writeVerificationProof sets the headers with the digest of the report in the
+// response body, the configuration generation and the signature of the digest,
+// so that callers can archive verifiable evidence without parsing the body.
+func (server *Server) writeVerificationProof(ctx context.Context, w http.ResponseWriter, body []byte, configGeneration int64) {
+	digest := sha256.Sum256(body)
+	w.Header().Set(ReportDigestHeader, "sha256:"+hex.EncodeToString(digest[:]))
+	w.Header().Set(ConfigGenerationHeader, strconv.FormatInt(configGeneration, 10))
+	if server.ReportSigner == nil {
+		return
+	}
+	signature, err := server.ReportSigner.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		// the report is still valid without the optional signature
+		logger.GetLogger(ctx, server.LogOption).Warnf("failed to sign verification report: %v", err)
+		return
+	}
+	w.Header().Set(ReportSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	"github.com/opencontainers/go-digest"
+)
+
+func writeTestKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return path
+}
+
+func TestLoadReportSigningKey(t *testing.T) {
+	if signer, err := LoadReportSigningKey(""); err != nil || signer != nil {
+		t.Fatalf("expected no signer without key, got %v, %v", signer, err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if _, err := LoadReportSigningKey(writeTestKey(t, key)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalidPath := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidPath, []byte("not a key"), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	if _, err := LoadReportSigningKey(invalidPath); err == nil {
+		t.Fatalf("expected error loading invalid key")
+	}
+}
+
+func TestServer_Verify_ProofHeaders(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(externaldata.NewProviderRequest([]string{"localhost:5000/net-monitor:v1"})); err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body.Bytes()))
+	responseRecorder := httptest.NewRecorder()
+
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("v1")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+		ConfigGeneration: 3,
+	}
+	server := &Server{
+		GetExecutor:  func() *core.Executor { return ex },
+		Context:      request.Context(),
+		ReportSigner: key,
+	}
+	handler := contextHandler{
+		context: server.Context,
+		handler: processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false),
+	}
+
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+	respBody, err := io.ReadAll(responseRecorder.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	bodyDigest := sha256.Sum256(respBody)
+	if got := responseRecorder.Header().Get(ReportDigestHeader); got != "sha256:"+hex.EncodeToString(bodyDigest[:]) {
+		t.Fatalf("expected digest of the response body, got %s", got)
+	}
+	if got := responseRecorder.Header().Get(ConfigGenerationHeader); got != "3" {
+		t.Fatalf("expected config generation 3, got %s", got)
+	}
+	signature, err := base64.StdEncoding.DecodeString(responseRecorder.Header().Get(ReportSignatureHeader))
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+	if !ecdsa.VerifyASN1(&key.PublicKey, bodyDigest[:], signature) {
+		t.Fatalf("expected signature of the report digest to verify")
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// idle clients are forgotten after this duration, their bucket is full again by then
+	rateLimitIdleTimeout = 10 * time.Minute
+	// rateLimitMaxClients bounds the number of buckets kept in memory, the
+	// bucket of the least recently seen client is dropped for a new client
+	rateLimitMaxClients = 10000
+)
+
+// RateLimitConfig configures the per client rate limit of the REST endpoints
+// that are not called by Gatekeeper.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the rate at which each client may send requests, 0 disables rate limiting
+	RequestsPerSecond float64
+	// Burst is the number of requests each client may send at once
+	Burst int
+}
+
+// clientRateLimiter keeps a token bucket per client
+type clientRateLimiter struct {
+	mu        sync.Mutex
+	limiters  map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// allow returns true if the client has a token left, or the time to wait for one otherwise
+func (l *clientRateLimiter) allow(config RateLimitConfig, client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiters == nil {
+		l.limiters = map[string]*clientLimiter{}
+	}
+	if now.Sub(l.lastSweep) > rateLimitIdleTimeout {
+		for key, limiter := range l.limiters {
+			if now.Sub(limiter.lastSeen) > rateLimitIdleTimeout {
+				delete(l.limiters, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	burst := config.Burst
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(config.RequestsPerSecond)))
+	}
+	limiter, ok := l.limiters[client]
+	if !ok && len(l.limiters) >= rateLimitMaxClients {
+		l.evictLeastRecentlySeen()
+	}
+	if !ok || limiter.limiter.Limit() != rate.Limit(config.RequestsPerSecond) || limiter.limiter.Burst() != burst {
+		limiter = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(config.RequestsPerSecond), burst)}
+		l.limiters[client] = limiter
+	}
+	limiter.lastSeen = now
+
+	reservation := limiter.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// evictLeastRecentlySeen drops the bucket of the client seen least recently
+func (l *clientRateLimiter) evictLeastRecentlySeen() {
+	var oldestKey string
+	var oldest time.Time
+	for key, limiter := range l.limiters {
+		if oldestKey == "" || limiter.lastSeen.Before(oldest) {
+			oldestKey, oldest = key, limiter.lastSeen
+		}
+	}
+	delete(l.limiters, oldestKey)
+}
+
+// rateLimit rejects requests of clients exceeding the configured rate with 429 Too Many Requests
+func (server *Server) rateLimit(h ContextHandler) ContextHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if server.RateLimit.RequestsPerSecond <= 0 {
+			return h(ctx, w, r)
+		}
+		client := clientIdentity(r)
+		allowed, retryAfter := server.rateLimiter.allow(server.RateLimit, client, time.Now())
+		if allowed {
+			return h(ctx, w, r)
+		}
+
+		logrus.Debugf("rate limit exceeded for client %s", client)
+		metrics.ReportRateLimitedRequest(ctx, r.URL.Path)
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+		return errcode.ServeJSON(w, errcode.ErrorCodeTooManyRequests.WithDetail(fmt.Sprintf("rate limit of %v requests per second exceeded", server.RateLimit.RequestsPerSecond)))
+	}
+}
+
+// clientIdentity identifies the client of a request by the identity of its
+// verified TLS client certificate, otherwise by its address
+func clientIdentity(r *http.Request) string {
+	var peerCertificates []*x509.Certificate
+	if r.TLS != nil {
+		peerCertificates = verifiedCertificates(*r.TLS)
+	}
+	return identifyClient(peerCertificates, r.RemoteAddr)
+}
+
+// verifiedCertificates returns the client certificates of the connection if
+// they were verified during the handshake. Unverified certificates and other
+// credentials chosen by the client, e.g. bearer tokens, do not identify it.
+func verifiedCertificates(state tls.ConnectionState) []*x509.Certificate {
+	if len(state.VerifiedChains) == 0 {
+		return nil
+	}
+	return state.PeerCertificates
+}
+
+// identifyClient identifies a client of the HTTP or gRPC server by its
+// verified certificates and address
+func identifyClient(peerCertificates []*x509.Certificate, remoteAddr string) string {
+	if len(peerCertificates) > 0 {
+		cert := peerCertificates[0]
+		switch {
+		case cert.Subject.CommonName != "":
+			return "cert:" + cert.Subject.CommonName
+		case len(cert.DNSNames) > 0:
+			return "cert:" + cert.DNSNames[0]
+		case len(cert.URIs) > 0:
+			return "cert:" + cert.URIs[0].String()
+		}
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "address:" + host
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimiter_Allow(t *testing.T) {
+	limiter := &clientRateLimiter{}
+	config := RateLimitConfig{RequestsPerSecond: 1, Burst: 2}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.allow(config, "client", now); !allowed {
+			t.Fatalf("expected request %d within burst to be allowed", i)
+		}
+	}
+	allowed, retryAfter := limiter.allow(config, "client", now)
+	if allowed || retryAfter <= 0 || retryAfter > time.Second {
+		t.Fatalf("expected request exceeding burst to be rejected with retry after at most 1s, got %v, %v", allowed, retryAfter)
+	}
+	if allowed, _ := limiter.allow(config, "other", now); !allowed {
+		t.Fatalf("expected other client to have its own bucket")
+	}
+	if allowed, _ := limiter.allow(config, "client", now.Add(time.Second)); !allowed {
+		t.Fatalf("expected bucket to refill after 1s")
+	}
+
+	limiter.allow(config, "client", now.Add(2*rateLimitIdleTimeout))
+	if len(limiter.limiters) != 1 {
+		t.Fatalf("expected idle clients to be removed, got %d clients", len(limiter.limiters))
+	}
+}
+
+func TestClientRateLimiter_MaxClients(t *testing.T) {
+	limiter := &clientRateLimiter{}
+	config := RateLimitConfig{RequestsPerSecond: 1, Burst: 1}
+	now := time.Now()
+
+	for i := 0; i < rateLimitMaxClients+10; i++ {
+		limiter.allow(config, fmt.Sprintf("client-%d", i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if len(limiter.limiters) != rateLimitMaxClients {
+		t.Fatalf("expected at most %d clients, got %d", rateLimitMaxClients, len(limiter.limiters))
+	}
+	if _, ok := limiter.limiters["client-0"]; ok {
+		t.Fatalf("expected the least recently seen client to be dropped")
+	}
+	if _, ok := limiter.limiters[fmt.Sprintf("client-%d", rateLimitMaxClients+9)]; !ok {
+		t.Fatalf("expected the most recently seen client to be kept")
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	certRequest := httptest.NewRequest(http.MethodPost, "/", nil)
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ci"}}
+	certRequest.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	unverifiedCertRequest := httptest.NewRequest(http.MethodPost, "/", nil)
+	unverifiedCertRequest.RemoteAddr = "10.0.0.2:4567"
+	unverifiedCertRequest.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	tokenRequest := httptest.NewRequest(http.MethodPost, "/", nil)
+	tokenRequest.RemoteAddr = "10.0.0.3:4567"
+	tokenRequest.Header.Set("Authorization", "Bearer secret")
+	addressRequest := httptest.NewRequest(http.MethodPost, "/", nil)
+	addressRequest.RemoteAddr = "10.0.0.1:4567"
+
+	if identity := clientIdentity(certRequest); identity != "cert:ci" {
+		t.Fatalf("unexpected identity %s", identity)
+	}
+	if identity := clientIdentity(unverifiedCertRequest); identity != "address:10.0.0.2" {
+		t.Fatalf("unexpected identity %s", identity)
+	}
+	if identity := clientIdentity(tokenRequest); identity != "address:10.0.0.3" {
+		t.Fatalf("unexpected identity %s", identity)
+	}
+	if identity := clientIdentity(addressRequest); identity != "address:10.0.0.1" {
+		t.Fatalf("unexpected identity %s", identity)
+	}
+}
+
+func TestRateLimit_TooManyRequests(t *testing.T) {
+	server := &Server{RateLimit: RateLimitConfig{RequestsPerSecond: 0.001, Burst: 1}}
+	handler := server.rateLimit(func(_ context.Context, w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	expected := []int{http.StatusOK, http.StatusTooManyRequests}
+	for _, status := range expected {
+		recorder := httptest.NewRecorder()
+		if err := handler(context.Background(), recorder, httptest.NewRequest(http.MethodPost, "/", nil)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if recorder.Code != status {
+			t.Fatalf("expected status %d, got %d", status, recorder.Code)
+		}
+	}
+
+	server.RateLimit = RateLimitConfig{}
+	recorder := httptest.NewRecorder()
+	_ = handler(context.Background(), recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected rate limiting to be disabled, got status %d", recorder.Code)
+	}
+}
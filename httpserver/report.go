@@ -0,0 +1,47 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// printReport writes the verification report of the subject pretty-printed to
+// the report writer of the server, e.g. stdout in development mode.
+func (server *Server) printReport(subject string, response VerificationResponse) {
+	if server.ReportWriter == nil {
+		return
+	}
+	report, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		logrus.Warnf("failed to print verification report of subject %s: %v", subject, err)
+		return
+	}
+	decision := "PASSED"
+	if !response.IsSuccess {
+		decision = "FAILED"
+	}
+
+	server.reportMu.Lock()
+	defer server.reportMu.Unlock()
+	if _, err := fmt.Fprintf(server.ReportWriter, "=== %s %s %s\n%s\n", time.Now().Format(time.RFC3339), decision, subject, report); err != nil {
+		logrus.Warnf("failed to print verification report of subject %s: %v", subject, err)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintReport(t *testing.T) {
+	server := &Server{}
+	// no writer configured
+	server.printReport("localhost:5000/net-monitor:v1", VerificationResponse{IsSuccess: true})
+
+	var buf bytes.Buffer
+	server.ReportWriter = &buf
+	server.printReport("localhost:5000/net-monitor:v1", VerificationResponse{Version: "0.1.0", IsSuccess: false})
+	output := buf.String()
+	if !strings.Contains(output, "FAILED localhost:5000/net-monitor:v1\n") {
+		t.Fatalf("expected report header with decision and subject, got %s", output)
+	}
+	if !strings.Contains(output, "\n  \"isSuccess\": false") {
+		t.Fatalf("expected indented report, got %s", output)
+	}
+}
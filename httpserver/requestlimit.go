@@ -0,0 +1,109 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+// DefaultMaxContentBytes is the default maximum size of the requests to the
+// verify-content endpoint.
+const DefaultMaxContentBytes = 32 << 20
+
+// RequestLimitConfig limits the external data requests sent by Gatekeeper to
+// the verify and mutate endpoints, the requests to the verify-workload
+// endpoint and to the verify endpoint of the REST API.
+type RequestLimitConfig struct {
+	// MaxBodyBytes is the maximum size of a request body, 0 disables the limit
+	MaxBodyBytes int64
+	// MaxKeys is the maximum number of keys or subjects of a request, 0
+	// disables the limit
+	MaxKeys int
+	// MaxConcurrentKeys is the maximum number of keys of a request sent by
+	// Gatekeeper verified at the same time, 0 disables the limit
+	MaxConcurrentKeys int
+	// MaxConcurrentVerifications is the maximum number of subjects of all
+	// verify requests of a request class verified at the same time, 0 disables
+	// the limit
+	MaxConcurrentVerifications int
+	// MaxContentBytes is the maximum size of a request body of the
+	// verify-content endpoint, which carries the content of the subject and
+	// its referrers, DefaultMaxContentBytes if not positive
+	MaxContentBytes int64
+	// MaxQueuedVerifications is the number of subjects of a request class
+	// waiting to be verified above which verify requests of the class are
+	// rejected with 503 and Retry-After, 0 disables the limit
+	MaxQueuedVerifications int
+}
+
+// readProviderRequest reads and parses the external data request of the body.
+func (server *Server) readProviderRequest(w http.ResponseWriter, r *http.Request) (externaldata.ProviderRequest, error) {
+	var providerRequest externaldata.ProviderRequest
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return providerRequest, err
+	}
+
+	if err = json.Unmarshal(body, &providerRequest); err != nil {
+		return providerRequest, re.ErrorCodeBadRequest.WithError(err).WithDetail("unable to unmarshal request body")
+	}
+	if maxKeys := server.RequestLimit.MaxKeys; maxKeys > 0 && len(providerRequest.Request.Keys) > maxKeys {
+		return providerRequest, re.ErrorCodeRequestLimitExceeded.WithDetail(fmt.Sprintf("request has %d keys, the maximum is %d", len(providerRequest.Request.Keys), maxKeys))
+	}
+	return providerRequest, nil
+}
+
+// readLimitedBody reads the request body, the body is not read beyond the
+// configured maximum size.
+func (server *Server) readLimitedBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	return readBody(w, r, server.RequestLimit.MaxBodyBytes)
+}
+
+// readContentBody reads the request body of the verify-content endpoint, the
+// body is not read beyond the configured maximum content size.
+func (server *Server) readContentBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	maxBytes := server.RequestLimit.MaxContentBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxContentBytes
+	}
+	return readBody(w, r, maxBytes)
+}
+
+// readBody reads the request body up to maxBytes, 0 does not limit the size.
+func readBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, error) {
+	defer r.Body.Close()
+
+	reader := r.Body
+	if maxBytes > 0 {
+		reader = http.MaxBytesReader(w, r.Body, maxBytes)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, re.ErrorCodeRequestLimitExceeded.WithDetail(fmt.Sprintf("request body exceeds the maximum size of %d bytes", maxBytesErr.Limit))
+		}
+		return nil, re.ErrorCodeBadRequest.WithError(err).WithDetail("unable to read request body")
+	}
+	return body, nil
+}
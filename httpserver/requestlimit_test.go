@@ -0,0 +1,144 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/errors"
+	exconfig "github.com/deislabs/ratify/pkg/executor/config"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+func TestServer_Verify_RequestLimit(t *testing.T) {
+	keys := []string{"&&", "&&&", "&&&&"}
+	body, err := json.Marshal(externaldata.NewProviderRequest(keys))
+	if err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		requestLimit   RequestLimitConfig
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "no limits",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "within limits",
+			requestLimit:   RequestLimitConfig{MaxBodyBytes: int64(len(body)), MaxKeys: len(keys)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "concurrency limits",
+			requestLimit:   RequestLimitConfig{MaxConcurrentKeys: 1, MaxConcurrentVerifications: 2, MaxQueuedVerifications: len(keys)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "body too large",
+			requestLimit:   RequestLimitConfig{MaxBodyBytes: int64(len(body) - 1)},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "request body exceeds the maximum size",
+		},
+		{
+			name:           "too many keys",
+			requestLimit:   RequestLimitConfig{MaxKeys: len(keys) - 1},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "request has 3 keys, the maximum is 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body))
+			responseRecorder := httptest.NewRecorder()
+			ex := &core.Executor{
+				PolicyEnforcer: config.PolicyEnforcer{},
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{}},
+				Verifiers:      []verifier.ReferenceVerifier{&core.TestVerifier{}},
+				Config:         &exconfig.ExecutorConfig{},
+			}
+			server := &Server{
+				GetExecutor:  func() *core.Executor { return ex },
+				Context:      request.Context(),
+				RequestLimit: tc.requestLimit,
+			}
+			handler := contextHandler{
+				context: server.Context,
+				handler: processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false),
+			}
+
+			handler.ServeHTTP(responseRecorder, request)
+			if responseRecorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatus, responseRecorder.Code)
+			}
+			var respBody externaldata.ProviderResponse
+			if err := json.NewDecoder(responseRecorder.Body).Decode(&respBody); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if tc.expectedError == "" {
+				if respBody.Response.SystemError != "" || len(respBody.Response.Items) != len(keys) {
+					t.Fatalf("expected %d items without system error, got %+v", len(keys), respBody.Response)
+				}
+				return
+			}
+			if !strings.Contains(respBody.Response.SystemError, errors.ErrorCodeRequestLimitExceeded.Descriptor().Value) ||
+				!strings.Contains(respBody.Response.SystemError, tc.expectedError) {
+				t.Fatalf("expected system error %q, got %q", tc.expectedError, respBody.Response.SystemError)
+			}
+		})
+	}
+}
+
+func TestServer_VerifyContent_RequestLimit(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify-content", strings.NewReader(`{"repository":"localhost:5000/net-monitor"}`))
+	responseRecorder := httptest.NewRecorder()
+	ex := &core.Executor{Config: &exconfig.ExecutorConfig{}}
+	server := &Server{
+		GetExecutor:  func() *core.Executor { return ex },
+		Context:      request.Context(),
+		RequestLimit: RequestLimitConfig{MaxContentBytes: 8},
+	}
+	handler := contextHandler{
+		context: server.Context,
+		handler: processTimeout(server.verifyContent, server.GetExecutor().GetVerifyRequestTimeout(), false),
+	}
+
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, responseRecorder.Code)
+	}
+	var respBody externaldata.ProviderResponse
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&respBody); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if !strings.Contains(respBody.Response.SystemError, "request body exceeds the maximum size of 8 bytes") {
+		t.Fatalf("unexpected system error %q", respBody.Response.SystemError)
+	}
+}
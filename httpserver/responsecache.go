@@ -0,0 +1,167 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/cache"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+// maxCachedResponses is the maximum number of responses kept by the response
+// cache, responses are not cached once it is full of unexpired entries.
+const maxCachedResponses = 1024
+
+// cachedResponseEntry is the response to a request and its expiry.
+type cachedResponseEntry struct {
+	items  []externaldata.Item
+	expiry time.Time
+}
+
+// responseCache is an in-memory cache of the responses to Gatekeeper requests
+// of a replica, indexed by the digest of the request keys. Identical requests,
+// e.g. re-admissions of the pods of a rollout, are served without verifying
+// each key again.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponseEntry
+}
+
+func (c *responseCache) get(key string, now time.Time) ([]externaldata.Item, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiry) {
+		return nil, false
+	}
+	return append([]externaldata.Item(nil), entry.items...), true
+}
+
+func (c *responseCache) set(key string, items []externaldata.Item, expiry time.Time, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedResponseEntry)
+	}
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiry) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) >= maxCachedResponses {
+		return false
+	}
+	c.entries[key] = cachedResponseEntry{items: items, expiry: expiry}
+	return true
+}
+
+// clear removes all responses, e.g. once a pin changes the result of a
+// subject.
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// responseCacheEnabled returns true if responses are cached, which requires
+// the cache of the verification results of subjects.
+func (server *Server) responseCacheEnabled() bool {
+	return server.CacheTTL > 0 && cache.GetCacheProvider() != nil
+}
+
+// responseCacheKey returns the digest of the request keys of the configuration
+// generation. The keys carry the namespace and the operation of each subject,
+// so responses are only shared by requests verifying the same subjects under
+// the same policies.
+func responseCacheKey(keys []string, configGeneration int64) string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	// marshaling a slice of strings cannot fail
+	content, _ := json.Marshal(struct {
+		Generation int64    `json:"generation"`
+		Keys       []string `json:"keys"`
+	}{configGeneration, sorted})
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
+
+// cachedResponse returns the cached response to the request keys. The usage of
+// the namespaces of the keys is recorded as if they were verified, and the
+// reports carry the trace ID of the request.
+func (server *Server) cachedResponse(ctx context.Context, keys []string) ([]externaldata.Item, bool) {
+	if !server.responseCacheEnabled() {
+		return nil, false
+	}
+	items, ok := server.responses.get(responseCacheKey(keys, server.GetExecutor().ConfigGeneration), time.Now())
+	metrics.ReportResponseCacheCount(ctx, ok)
+	if !ok {
+		return nil, false
+	}
+	logger.GetLogger(ctx, server.LogOption).Debugf("response cache hit for request of %d keys", len(keys))
+	for i, item := range items {
+		// the reports refer to the request they are returned to
+		if response, ok := item.Value.(VerificationResponse); ok {
+			response.TraceID = logger.GetTraceID(ctx)
+			items[i].Value = response
+		}
+		keyCtx, namespace := requestNamespace(ctx, item.Key)
+		server.recordUsage(keyCtx, namespace, item)
+		server.recordAudit(keyCtx, namespace, item)
+		server.recordDenial(keyCtx, item)
+	}
+	return items, true
+}
+
+// cacheResponse caches the response to the request keys.
+func (server *Server) cacheResponse(ctx context.Context, keys []string, items []externaldata.Item) {
+	if !server.responseCacheEnabled() {
+		return
+	}
+	now := time.Now()
+	expiry, ok := responseExpiry(items, server.CacheTTL, now)
+	if !ok {
+		return
+	}
+	if !server.responses.set(responseCacheKey(keys, server.GetExecutor().ConfigGeneration), items, expiry, now) {
+		logger.GetLogger(ctx, server.LogOption).Debugf("response cache is full, skipping response of %d keys", len(keys))
+	}
+}
+
+// responseExpiry returns the expiry of the cached response after the TTL,
+// bounded by the earliest expiry of the trust material of its subjects.
+// Returns false if the response must not be cached since it has errors, e.g.
+// of unreachable registries.
+func responseExpiry(items []externaldata.Item, ttl time.Duration, now time.Time) (time.Time, bool) {
+	for _, item := range items {
+		response, ok := item.Value.(VerificationResponse)
+		if item.Error != "" || !ok {
+			return time.Time{}, false
+		}
+		if ttl, ok = ttlUntil(response.validUntil, ttl, now); !ok {
+			return time.Time{}, false
+		}
+	}
+	return now.Add(ttl), true
+}
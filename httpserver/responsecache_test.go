@@ -0,0 +1,105 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	key := responseCacheKey([]string{"[team]registry.io/a:v1", "[team]registry.io/b:v1"}, 1)
+	if reordered := responseCacheKey([]string{"[team]registry.io/b:v1", "[team]registry.io/a:v1"}, 1); reordered != key {
+		t.Fatalf("expected key to be independent of the order of the request keys")
+	}
+	if namespaced := responseCacheKey([]string{"[other]registry.io/a:v1", "[team]registry.io/b:v1"}, 1); namespaced == key {
+		t.Fatalf("expected keys of different namespaces to differ")
+	}
+	if generation := responseCacheKey([]string{"[team]registry.io/a:v1", "[team]registry.io/b:v1"}, 2); generation == key {
+		t.Fatalf("expected keys of different configuration generations to differ")
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	now := time.Now()
+	c := responseCache{}
+	items := []externaldata.Item{{Key: "registry.io/a:v1", Value: VerificationResponse{IsSuccess: true}}}
+	if !c.set("key", items, now.Add(time.Second), now) {
+		t.Fatalf("expected response to be cached")
+	}
+	if cached, ok := c.get("key", now); !ok || len(cached) != 1 || cached[0].Key != "registry.io/a:v1" {
+		t.Fatalf("expected cached response, got %v", cached)
+	}
+	if _, ok := c.get("key", now.Add(time.Second)); ok {
+		t.Fatalf("expected expired response to be missed")
+	}
+	if _, ok := c.get("other", now); ok {
+		t.Fatalf("expected unknown request to be missed")
+	}
+	c.clear()
+	if _, ok := c.get("key", now); ok {
+		t.Fatalf("expected cleared response to be missed")
+	}
+}
+
+func TestResponseExpiry(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(3 * time.Second)
+	expiring := now.Add(500 * time.Millisecond)
+	testCases := []struct {
+		name     string
+		items    []externaldata.Item
+		expected time.Time
+		cached   bool
+	}{
+		{
+			name:     "cache ttl",
+			items:    []externaldata.Item{{Value: VerificationResponse{IsSuccess: true}}},
+			expected: now.Add(10 * time.Second),
+			cached:   true,
+		},
+		{
+			name: "bounded by trust material",
+			items: []externaldata.Item{
+				{Value: VerificationResponse{IsSuccess: true}},
+				{Value: VerificationResponse{IsSuccess: true, validUntil: &soon}},
+			},
+			expected: soon,
+			cached:   true,
+		},
+		{
+			name:  "expiring trust material",
+			items: []externaldata.Item{{Value: VerificationResponse{IsSuccess: true, validUntil: &expiring}}},
+		},
+		{
+			name:  "error",
+			items: []externaldata.Item{{Value: VerificationResponse{IsSuccess: true}}, {Error: "registry unavailable"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expiry, ok := responseExpiry(tc.items, 10*time.Second, now)
+			if ok != tc.cached {
+				t.Fatalf("expected cached %v, got %v", tc.cached, ok)
+			}
+			if ok && !expiry.Equal(tc.expected) {
+				t.Fatalf("expected expiry %v, got %v", tc.expected, expiry)
+			}
+		})
+	}
+}
@@ -17,8 +17,11 @@ package httpserver
 
 import (
 	"context"
+	"crypto"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -31,9 +34,12 @@ import (
 
 	"github.com/deislabs/ratify/config"
 	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor"
 	"github.com/deislabs/ratify/pkg/metrics"
+	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
 
 	"github.com/gorilla/mux"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
@@ -42,7 +48,7 @@ const (
 	certName                         = "tls.crt"
 	keyName                          = "tls.key"
 	readHeaderTimeout                = 5 * time.Second
-	defaultMutationReferrerStoreName = "oras"
+	defaultMutationReferrerStoreName = su.DefaultMutationStoreName
 
 	DefaultMetricsType = "prometheus"
 	DefaultMetricsPort = 8888
@@ -62,8 +68,66 @@ type Server struct {
 	MetricsPort       int
 	CacheTTL          time.Duration
 	LogOption         logger.Option
+	// MetricsPush configures the receiver of the otlp and statsd metrics backends
+	MetricsPush metrics.PushConfig
+	// RateLimit limits the requests of each client to the REST endpoints that are not called by Gatekeeper
+	RateLimit RateLimitConfig
+	// ClientAuth restricts the clients allowed to call the endpoints called by Gatekeeper
+	ClientAuth ClientAuthConfig
+	// RequestLimit limits the size and number of keys of the requests sent by Gatekeeper
+	RequestLimit RequestLimitConfig
+	// HealthChecks selects the optional dependency checks of the readiness endpoint
+	HealthChecks HealthCheckConfig
+	// GRPCAddress is the address of the gRPC verification service, the service
+	// is not started if empty
+	GRPCAddress string
+	// ReportWriter receives each verification report pretty-printed, e.g.
+	// stdout in development mode, reports are not written if nil
+	ReportWriter io.Writer
+	// ReportSigner signs the digests of the verification reports, reports are
+	// not signed if nil
+	ReportSigner crypto.Signer
+	// DenialRecorder records the subjects failing verification in admission
+	// requests, denials are not recorded if nil
+	DenialRecorder DenialRecorder
+	// AuditSink is the append-only log of the verification decisions,
+	// decisions are not logged if nil
+	AuditSink AuditSink
+	// MutationFallbackStoreNames are the stores resolving tags in order if the
+	// mutation store fails to resolve them, e.g. of the upstream registry of a mirror
+	MutationFallbackStoreNames []string
+	// MutationPlatform selects the manifest of an image index the mutation
+	// endpoint resolves tags to, the index digest is kept if nil
+	MutationPlatform *oci.Platform
+	// MutationDigestedReferences selects how the mutation endpoint handles
+	// references already pinned to a digest, they are passed through if empty
+	MutationDigestedReferences su.DigestedReferenceMode
+	// DistributedLock de-duplicates the verifications of a subject across the
+	// replicas sharing the cache, verifications are de-duplicated within this
+	// replica only if nil
+	DistributedLock DistributedLock
+	// DrainTimeout is the time in-flight requests are given to complete on
+	// shutdown, DefaultDrainTimeout if not positive
+	DrainTimeout time.Duration
+	// Admin enables the admin endpoints and restricts them to admin clients
+	Admin AdminConfig
 
-	keyMutex keyMutex
+	keyMutex         keyMutex
+	rateLimiter      clientRateLimiter
+	preheatQueue     preheatQueue
+	verificationPool verificationPool
+	pins             pinStore
+	usage            usageStore
+	responses        responseCache
+	reportMu         sync.Mutex
+	drain            drainTracker
+}
+
+// DistributedLock is a lock shared by the replicas of Ratify.
+type DistributedLock interface {
+	// Lock blocks until the lock of the key is acquired or the context is
+	// done, and returns a function to release it.
+	Lock(ctx context.Context, key string) (func(), error)
 }
 
 // keyMutex is a thread-safe map of mutexes, indexed by key.
@@ -113,6 +177,12 @@ func NewServer(context context.Context,
 }
 
 func (server *Server) Run(certRotatorReady chan struct{}) error {
+	if err := server.ClientAuth.Validate(server.CaCertFile); err != nil {
+		return err
+	}
+	if len(server.ClientAuth.AllowedNames) > 0 && server.CertDirectory == "" {
+		return fmt.Errorf("allowed client names require TLS to be enabled with a cert directory")
+	}
 	tcpAddr, err := net.ResolveTCPAddr("tcp", server.Address)
 	if err != nil {
 		return err
@@ -120,7 +190,7 @@ func (server *Server) Run(certRotatorReady chan struct{}) error {
 
 	// initialize metrics exporters
 	if server.MetricsEnabled {
-		if err := metrics.InitMetricsExporter(server.MetricsType, server.MetricsPort); err != nil {
+		if err := metrics.InitMetricsExporter(server.MetricsType, server.MetricsPort, server.MetricsPush); err != nil {
 			logrus.Errorf("failed to initialize metrics exporter %s: %v", server.MetricsType, err)
 			os.Exit(1)
 		}
@@ -161,9 +231,15 @@ func (server *Server) Run(certRotatorReady chan struct{}) error {
 			MinVersion:         tls.VersionTLS13,
 		}
 
-		return startServerWithGracefulShutdown(true, svr, lsnr, certFile, keyFile)
+		if err = server.startGRPC(svr.TLSConfig); err != nil {
+			return err
+		}
+		return startServerWithGracefulShutdown(true, svr, lsnr, certFile, keyFile, server.drainTimeout(), &server.drain)
 	}
-	return startServerWithGracefulShutdown(false, svr, lsnr, "", "")
+	if err = server.startGRPC(nil); err != nil {
+		return err
+	}
+	return startServerWithGracefulShutdown(false, svr, lsnr, "", "", server.drainTimeout(), &server.drain)
 }
 
 func (server *Server) register(method, path string, handler ContextHandler) {
@@ -178,13 +254,62 @@ func (server *Server) registerHandlers() error {
 	if err != nil {
 		return err
 	}
-	server.register(http.MethodPost, verifyPath, processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false))
+	server.register(http.MethodPost, verifyPath, server.authorizeClient(server.drainable(processTimeout(classify(server.verify, executor.RequestClassAdmission), server.GetExecutor().GetVerifyRequestTimeout(), false))))
+
+	auditVerifyPath, err := url.JoinPath(ServerRootURL, "audit", "verify")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodPost, auditVerifyPath, server.authorizeClient(server.drainable(processTimeout(classify(server.verify, executor.RequestClassAudit), server.GetExecutor().GetVerifyRequestTimeout(), false))))
+
+	verifyContentPath, err := url.JoinPath(ServerRootURL, "verify-content")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodPost, verifyContentPath, server.authorizeClient(server.drainable(processTimeout(classify(server.verifyContent, executor.RequestClassAudit), server.GetExecutor().GetVerifyRequestTimeout(), false))))
+
+	verifyWorkloadPath, err := url.JoinPath(ServerRootURL, "verify-workload")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodPost, verifyWorkloadPath, server.authorizeClient(server.drainable(server.rateLimit(classify(server.verifyWorkload, executor.RequestClassAudit)))))
+
+	preheatPath, err := url.JoinPath(ServerRootURL, "preheat")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodPost, preheatPath, server.authorizeAdmin(server.rateLimit(server.preheat)))
+
+	pinsPath, err := url.JoinPath(ServerRootURL, "pins")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodGet, pinsPath, server.pinsEnabled(server.authorizeAdmin(server.rateLimit(server.listPins))))
+	server.register(http.MethodPost, pinsPath, server.pinsEnabled(server.authorizeAdmin(server.rateLimit(server.pin))))
+	server.register(http.MethodDelete, pinsPath+"/{digest}", server.pinsEnabled(server.authorizeAdmin(server.rateLimit(server.unpin))))
+
+	usagePath, err := url.JoinPath(ServerRootURL, "usage")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodGet, usagePath, server.rateLimit(server.listUsage))
+
+	apiVerifyPath, err := url.JoinPath(APIRootURL, "verify")
+	if err != nil {
+		return err
+	}
+	server.register(http.MethodPost, apiVerifyPath, server.authorizeClient(server.rateLimit(classify(server.verifySubjects, executor.RequestClassAudit))))
+
+	server.register(http.MethodPost, admitPath, server.admit)
+
+	server.register(http.MethodGet, healthzPath, server.healthz)
+	server.register(http.MethodGet, readyzPath, server.readyz)
 
 	mutatePath, err := url.JoinPath(ServerRootURL, "mutate")
 	if err != nil {
 		return err
 	}
-	server.register(http.MethodPost, mutatePath, processTimeout(server.mutate, server.GetExecutor().GetMutationRequestTimeout(), true))
+	server.register(http.MethodPost, mutatePath, server.authorizeClient(server.drainable(processTimeout(server.mutate, server.GetExecutor().GetMutationRequestTimeout(), true))))
 
 	return nil
 }
@@ -195,19 +320,27 @@ func (err ServerAddrNotFoundError) Error() string {
 	return "The http server address configuration is not set. Skipping server creation"
 }
 
-// startServerWithGracefulShutdown starts the server and waits for SIGINT or SIGTERM to shutdown the server gracefully
-func startServerWithGracefulShutdown(isTLSEnabled bool, svr *http.Server, lsnr net.Listener, certFile string, keyFile string) error {
+// startServerWithGracefulShutdown starts the server and waits for SIGINT or SIGTERM to shutdown the server gracefully:
+// new requests to the drainable endpoints are rejected and the in-flight requests are given the drain timeout to complete
+func startServerWithGracefulShutdown(isTLSEnabled bool, svr *http.Server, lsnr net.Listener, certFile string, keyFile string, drainTimeout time.Duration, drain *drainTracker) error {
 	connectionsClosed := make(chan struct{})
 	// wait for SIGINT or SIGTERM to shutdown the server gracefully
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
-		logrus.Info("shutting down ratify server...")
-		ctx, cancel := context.WithTimeout(context.Background(), 6*time.Second) // wait 6 seconds for existing connections to finish
+		inflight := drain.startDraining()
+		logrus.Infof("shutting down ratify server, draining %d in-flight requests for up to %v...", inflight, drainTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 		defer cancel()
 		if err := svr.Shutdown(ctx); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				drain.abortSummary()
+			}
 			logrus.Errorf("failed to shutdown ratify server: %v", err)
+			if err := svr.Close(); err != nil {
+				logrus.Errorf("failed to close ratify server: %v", err)
+			}
 		}
 		close(connectionsClosed)
 	}()
@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -237,6 +238,149 @@ func TestServer_MultipleSubjects_Success(t *testing.T) {
 	})
 }
 
+func TestServer_MultipleSubjects_Deduplicated(t *testing.T) {
+	testImageNames := []string{"localhost:5000/net-monitor:v1", "localhost:5000/net-monitor:latest", "localhost:5000/net-monitor:v2", "localhost:5000/net-monitor:v1"}
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(externaldata.NewProviderRequest(testImageNames)); err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body.Bytes()))
+	responseRecorder := httptest.NewRecorder()
+
+	var mu sync.Mutex
+	verifications := 0
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{
+				"v1":     digest.FromString("v1"),
+				"latest": digest.FromString("v1"),
+				"v2":     digest.FromString("v2"),
+			},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				verifications++
+				return true
+			},
+		}},
+	}
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     request.Context(),
+	}
+	handler := contextHandler{
+		context: server.Context,
+		handler: processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false),
+	}
+
+	handler.ServeHTTP(responseRecorder, request)
+	var respBody externaldata.ProviderResponse
+	if err := json.NewDecoder(responseRecorder.Result().Body).Decode(&respBody); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if len(respBody.Response.Items) != len(testImageNames) {
+		t.Fatalf("expected %d items, got %d", len(testImageNames), len(respBody.Response.Items))
+	}
+	keys := map[string]int{}
+	for _, item := range respBody.Response.Items {
+		keys[item.Key]++
+		if item.Error != "" || !item.Value.(map[string]interface{})["isSuccess"].(bool) {
+			t.Fatalf("expected key %s to be verified, got %+v", item.Key, item)
+		}
+	}
+	if keys[testImageNames[0]] != 2 || keys[testImageNames[1]] != 1 || keys[testImageNames[2]] != 1 {
+		t.Fatalf("expected an item per key, got %v", keys)
+	}
+	if verifications != 2 {
+		t.Fatalf("expected subjects resolving to the same digest to be verified once, got %d verifications", verifications)
+	}
+}
+
+func TestServer_AuditMode_AllowsFailedVerification(t *testing.T) {
+	testImageName := "localhost:5000/net-monitor:v1"
+	testCases := []struct {
+		name            string
+		mode            types.EnforcementMode
+		expectedSuccess bool
+	}{
+		{name: "enforce mode denies", mode: types.EnforcementModeEnforce, expectedSuccess: false},
+		{name: "audit mode allows with warning", mode: types.EnforcementModeAudit, expectedSuccess: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := new(bytes.Buffer)
+			if err := json.NewEncoder(body).Encode(externaldata.NewProviderRequest([]string{testImageName})); err != nil {
+				t.Fatalf("failed to encode request body: %v", err)
+			}
+			request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body.Bytes()))
+			responseRecorder := httptest.NewRecorder()
+
+			configPolicy := config.PolicyEnforcer{
+				ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+					testArtifactType: types.AllVerifySuccess,
+				},
+				Mode: tc.mode,
+			}
+			store := &mocks.TestStore{
+				References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+				ResolveMap: map[string]digest.Digest{"v1": digest.FromString("test")},
+			}
+			ver := &core.TestVerifier{
+				CanVerifyFunc: func(at string) bool {
+					return at == testArtifactType
+				},
+				VerifyResult: func(_ string) bool {
+					return false
+				},
+			}
+			ex := &core.Executor{
+				PolicyEnforcer: configPolicy,
+				ReferrerStores: []referrerstore.ReferrerStore{store},
+				Verifiers:      []verifier.ReferenceVerifier{ver},
+				Config:         &exconfig.ExecutorConfig{},
+			}
+			server := &Server{
+				GetExecutor: func() *core.Executor {
+					return ex
+				},
+				Context:  request.Context(),
+				keyMutex: keyMutex{},
+			}
+			handler := contextHandler{
+				context: server.Context,
+				handler: processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false),
+			}
+
+			handler.ServeHTTP(responseRecorder, request)
+			var respBody externaldata.ProviderResponse
+			if err := json.NewDecoder(responseRecorder.Result().Body).Decode(&respBody); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			value, ok := respBody.Response.Items[0].Value.(map[string]interface{})
+			if !ok {
+				t.Fatalf("unexpected response item value %v", respBody.Response.Items[0].Value)
+			}
+			if value["isSuccess"] != tc.expectedSuccess {
+				t.Fatalf("expected isSuccess %v, got %v", tc.expectedSuccess, value["isSuccess"])
+			}
+			_, hasWarning := value["warning"]
+			if hasWarning != (tc.mode == types.EnforcementModeAudit) {
+				t.Fatalf("expected warning only in audit mode, got %v", value["warning"])
+			}
+		})
+	}
+}
+
 func TestServer_Mutation_Success(t *testing.T) {
 	timeoutDuration := 6
 	testImageNameTagged := "localhost:5000/net-monitor:v1"
@@ -457,7 +601,7 @@ func TestServer_serverGracefulShutdown(t *testing.T) {
 
 	// start the server
 	go func() {
-		_ = startServerWithGracefulShutdown(false, ts.Config, ts.Listener, "", "")
+		_ = startServerWithGracefulShutdown(false, ts.Config, ts.Listener, "", "", DefaultDrainTimeout, &drainTracker{})
 	}()
 
 	// wait a second for server to come online
@@ -495,3 +639,112 @@ func TestServer_serverGracefulShutdown(t *testing.T) {
 	// wait some time to see shutdown logs
 	time.Sleep(5 * time.Second)
 }
+
+func TestServer_Verify_RequestID(t *testing.T) {
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(externaldata.NewProviderRequest([]string{"localhost:5000/net-monitor:v1"})); err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body.Bytes()))
+	request.Header.Set("X-Request-ID", "correlation-id")
+	responseRecorder := httptest.NewRecorder()
+
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("test")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+	}
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     request.Context(),
+	}
+	handler := contextHandler{
+		context: server.Context,
+		handler: processTimeout(server.verify, server.GetExecutor().GetVerifyRequestTimeout(), false),
+	}
+
+	handler.ServeHTTP(responseRecorder, request)
+	if requestID := responseRecorder.Header().Get("X-Request-ID"); requestID != "correlation-id" {
+		t.Fatalf("expected request ID correlation-id in the response, got %q", requestID)
+	}
+	var respBody externaldata.ProviderResponse
+	if err := json.NewDecoder(responseRecorder.Result().Body).Decode(&respBody); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	report, ok := respBody.Response.Items[0].Value.(map[string]interface{})
+	if !ok || report["traceID"] != "correlation-id" {
+		t.Fatalf("expected report with trace ID correlation-id, got %v", respBody.Response.Items[0].Value)
+	}
+}
+
+type mockDistributedLock struct {
+	locked   []string
+	released int
+	err      error
+}
+
+func (l *mockDistributedLock) Lock(_ context.Context, key string) (func(), error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.locked = append(l.locked, key)
+	return func() {
+		l.released++
+	}, nil
+}
+
+func TestServer_VerifyKeyDistributedLock(t *testing.T) {
+	testDigest := digest.FromString("test")
+	subject := "localhost:5000/net-monitor@" + testDigest.String()
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+	}
+	ctx := context.Background()
+	server, err := NewServer(ctx, "localhost:0", func() *core.Executor { return ex }, "", "", 0, false, "", 0)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	lock := &mockDistributedLock{}
+	server.DistributedLock = lock
+	if item := server.verifyKey(ctx, subject); item.Error != "" || !item.Value.(VerificationResponse).IsSuccess {
+		t.Fatalf("expected subject to pass verification, got %+v", item)
+	}
+	if len(lock.locked) != 1 || lock.locked[0] != subject || lock.released != 1 {
+		t.Fatalf("expected the subject to be locked and released once, got locked %v released %d", lock.locked, lock.released)
+	}
+
+	// verification proceeds without the lock if it cannot be acquired
+	server.DistributedLock = &mockDistributedLock{err: fmt.Errorf("lock store unavailable")}
+	if item := server.verifyKey(ctx, subject); item.Error != "" || !item.Value.(VerificationResponse).IsSuccess {
+		t.Fatalf("expected subject to pass verification without the lock, got %+v", item)
+	}
+}
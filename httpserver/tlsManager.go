@@ -21,6 +21,8 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +32,9 @@ import (
 )
 
 // This implementation is based on K8s certwatcher: https://github.com/kubernetes-sigs/controller-runtime/blob/main/pkg/certwatcher/certwatcher.go
+// The directories of the files are watched instead of the files, so that
+// certificates rotated by replacing the files, e.g. the atomic symlink swap of
+// Kubernetes secret volumes used by cert-manager, are picked up as well.
 type TLSCertWatcher struct {
 	sync.RWMutex
 	ratifyServerCert *tls.Certificate
@@ -62,11 +67,11 @@ func NewTLSCertWatcher(ratifyServerCertPath, ratifyServerKeyPath, clientCACertPa
 	return certWatcher, nil
 }
 
-// Start adds the files to watcher and starts the certificate watcher routine
+// Start adds the directories of the files to watcher and starts the certificate watcher routine
 func (t *TLSCertWatcher) Start() error {
-	files := map[string]struct{}{t.ratifyServerCertPath: {}, t.ratifyServerKeyPath: {}}
-	if t.clientCACertPath != "" {
-		files[t.clientCACertPath] = struct{}{}
+	dirs := map[string]struct{}{}
+	for _, file := range t.files() {
+		dirs[filepath.Dir(file)] = struct{}{}
 	}
 
 	{
@@ -74,13 +79,13 @@ func (t *TLSCertWatcher) Start() error {
 		pollInterval := 1 * time.Second
 		pollTimeout := 10 * time.Second
 		if err := wait.PollUntilContextTimeout(context.TODO(), pollInterval, pollTimeout, false, func(ctx context.Context) (done bool, err error) {
-			for f := range files {
-				if err := t.watcher.Add(f); err != nil {
+			for dir := range dirs {
+				if err := t.watcher.Add(dir); err != nil {
 					watchErr = err
 					return false, nil //nolint:nilerr // we want to keep trying.
 				}
 				// remove it from the set
-				delete(files, f)
+				delete(dirs, dir)
 			}
 			return true, nil
 		}); err != nil {
@@ -101,7 +106,9 @@ func (t *TLSCertWatcher) Stop() {
 	}
 }
 
-// ReadCertificates reads the certificates from the cert/key paths
+// ReadCertificates reads the certificates from the cert/key paths. The
+// certificates in use are kept if the files cannot be read or are invalid,
+// e.g. while they are being rotated.
 func (t *TLSCertWatcher) ReadCertificates() error {
 	if t.ratifyServerCertPath == "" || t.ratifyServerKeyPath == "" {
 		return fmt.Errorf("ratify server cert or key path is empty")
@@ -114,7 +121,9 @@ func (t *TLSCertWatcher) ReadCertificates() error {
 		}
 
 		clientCAs := x509.NewCertPool()
-		clientCAs.AppendCertsFromPEM(caCert)
+		if !clientCAs.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("no certificates found in client CA file %s", t.clientCACertPath)
+		}
 		t.Lock()
 		t.clientCACert = clientCAs
 		t.Unlock()
@@ -130,6 +139,15 @@ func (t *TLSCertWatcher) ReadCertificates() error {
 	return nil
 }
 
+// files returns the paths of the watched files
+func (t *TLSCertWatcher) files() []string {
+	files := []string{t.ratifyServerCertPath, t.ratifyServerKeyPath}
+	if t.clientCACertPath != "" {
+		files = append(files, t.clientCACertPath)
+	}
+	return files
+}
+
 // GetConfigForClient returns the tls config for the client use in the TLS Config
 func (t *TLSCertWatcher) GetConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
 	t.RLock()
@@ -149,25 +167,37 @@ func (t *TLSCertWatcher) GetConfigForClient(*tls.ClientHelloInfo) (*tls.Config,
 }
 
 func (t *TLSCertWatcher) handleEvent(event fsnotify.Event) {
-	// Only care about events which may modify the contents of the file.
-	if !(isWrite(event) || isRemove(event) || isCreate(event)) {
+	// Only care about events which may modify the contents of the files.
+	if !(isWrite(event) || isRemove(event) || isCreate(event) || isRename(event)) {
+		return
+	}
+	if !t.affectsFiles(event) {
 		return
 	}
 
 	logrus.Infof("tls certificate rotation event: %v", event)
 
-	// If the file was removed, re-add the watch.
-	if isRemove(event) {
-		if err := t.watcher.Add(event.Name); err != nil {
-			logrus.Errorf("error re-watching file: %v", err)
-		}
-	}
-
 	if err := t.ReadCertificates(); err != nil {
 		logrus.Errorf("error re-reading certificates: %v", err)
 	}
 }
 
+// affectsFiles returns true if the event is on one of the watched files or
+// on the data symlink of a Kubernetes volume in their directories, which the
+// files are linked through.
+func (t *TLSCertWatcher) affectsFiles(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	for _, file := range t.files() {
+		if name == filepath.Clean(file) {
+			return true
+		}
+		if filepath.Dir(name) == filepath.Dir(filepath.Clean(file)) && strings.HasPrefix(filepath.Base(name), "..") {
+			return true
+		}
+	}
+	return false
+}
+
 // Watch watches the certificate files for changes and terminates on error/stop
 func (t *TLSCertWatcher) Watch() {
 	for {
@@ -192,13 +222,17 @@ func (t *TLSCertWatcher) Watch() {
 }
 
 func isWrite(event fsnotify.Event) bool {
-	return event.Op == fsnotify.Write
+	return event.Has(fsnotify.Write)
 }
 
 func isCreate(event fsnotify.Event) bool {
-	return event.Op == fsnotify.Create
+	return event.Has(fsnotify.Create)
 }
 
 func isRemove(event fsnotify.Event) bool {
-	return event.Op == fsnotify.Remove
+	return event.Has(fsnotify.Remove)
+}
+
+func isRename(event fsnotify.Event) bool {
+	return event.Has(fsnotify.Rename)
 }
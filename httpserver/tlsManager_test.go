@@ -356,6 +356,116 @@ func TestCertRotation(t *testing.T) {
 	}
 }
 
+func TestCertRotation_SymlinkSwap(t *testing.T) {
+	// setup temp dir laid out like a Kubernetes secret volume, the files are
+	// linked through the ..data symlink which is swapped on updates
+	tmpDir := t.TempDir()
+	writeVersion := func(version, cert, key, caCert string) {
+		versionDir := filepath.Join(tmpDir, version)
+		if err := os.Mkdir(versionDir, 0700); err != nil {
+			t.Fatalf("version dir creation failed %v", err)
+		}
+		for name, content := range map[string]string{certName: cert, keyName: key, firstCACertFileName: caCert} {
+			if err := os.WriteFile(filepath.Join(versionDir, name), []byte(content), 0600); err != nil {
+				t.Fatalf("file creation failed %v", err)
+			}
+		}
+		if err := os.Symlink(version, filepath.Join(tmpDir, "..data_tmp")); err != nil {
+			t.Fatalf("symlink creation failed %v", err)
+		}
+		if err := os.Rename(filepath.Join(tmpDir, "..data_tmp"), filepath.Join(tmpDir, "..data")); err != nil {
+			t.Fatalf("symlink swap failed %v", err)
+		}
+	}
+	writeVersion("..first", firstCertificate, firstKey, firstCACert)
+	for _, name := range []string{certName, keyName, firstCACertFileName} {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(tmpDir, name)); err != nil {
+			t.Fatalf("symlink creation failed %v", err)
+		}
+	}
+	certFileName := filepath.Join(tmpDir, certName)
+	keyFileName := filepath.Join(tmpDir, keyName)
+
+	cw, err := NewTLSCertWatcher(certFileName, keyFileName, filepath.Join(tmpDir, firstCACertFileName))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err = cw.Start(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer cw.Stop()
+
+	writeVersion("..second", secondCertificate, secondKey, secondCACert)
+	if err := os.RemoveAll(filepath.Join(tmpDir, "..first")); err != nil {
+		t.Fatalf("old version removal failed %v", err)
+	}
+
+	expectedCertBundle, err := tls.LoadX509KeyPair(certFileName, keyFileName)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expectedCaKey := x509.NewCertPool()
+	expectedCaKey.AppendCertsFromPEM([]byte(secondCACert))
+	// wait for cert rotation (watcher is not instant)
+	for i := 0; i < 50; i++ {
+		config, err := cw.GetConfigForClient(nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if bytes.Equal(expectedCertBundle.Certificate[0], config.Certificates[0].Certificate[0]) && expectedCaKey.Equal(config.ClientCAs) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("Expected rotated certificates to be loaded")
+}
+
+func TestHandleEvent_InvalidFilesKeepCertificates(t *testing.T) {
+	tmpDir := t.TempDir()
+	certFileName := filepath.Join(tmpDir, firstCertFileName)
+	if err := os.WriteFile(certFileName, []byte(firstCertificate), 0600); err != nil {
+		t.Fatalf("cert file creation failed %v", err)
+	}
+	keyFileName := filepath.Join(tmpDir, firstKeyFileName)
+	if err := os.WriteFile(keyFileName, []byte(firstKey), 0600); err != nil {
+		t.Fatalf("cert key creation failed %v", err)
+	}
+	caFileName := filepath.Join(tmpDir, firstCACertFileName)
+	if err := os.WriteFile(caFileName, []byte(firstCACert), 0600); err != nil {
+		t.Fatalf("ca cert file creation failed %v", err)
+	}
+	cw, err := NewTLSCertWatcher(certFileName, keyFileName, caFileName)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	serverCert, clientCACert := cw.ratifyServerCert, cw.clientCACert
+
+	// a partially written CA file and a key not matching the certificate
+	if err := os.WriteFile(caFileName, []byte("-----BEGIN CERTIFICATE-----"), 0600); err != nil {
+		t.Fatalf("ca cert file update failed %v", err)
+	}
+	if err := os.WriteFile(certFileName, []byte(secondCertificate), 0600); err != nil {
+		t.Fatalf("cert file update failed %v", err)
+	}
+	cw.handleEvent(fsnotify.Event{Name: caFileName, Op: fsnotify.Write})
+	if cw.ratifyServerCert != serverCert || cw.clientCACert != clientCACert {
+		t.Fatalf("Expected certificates in use to be kept")
+	}
+
+	// events on other files in the directory are ignored
+	if err := os.WriteFile(caFileName, []byte(secondCACert), 0600); err != nil {
+		t.Fatalf("ca cert file update failed %v", err)
+	}
+	cw.handleEvent(fsnotify.Event{Name: filepath.Join(tmpDir, "other"), Op: fsnotify.Create})
+	if cw.clientCACert != clientCACert {
+		t.Fatalf("Expected event on unrelated file to be ignored")
+	}
+	cw.handleEvent(fsnotify.Event{Name: caFileName, Op: fsnotify.Write | fsnotify.Chmod})
+	if cw.clientCACert == clientCACert {
+		t.Fatalf("Expected client CA certificates to be reloaded")
+	}
+}
+
 func TestIsWrite_Expected(t *testing.T) {
 	actual := fsnotify.Event{Op: fsnotify.Write}
 	if !isWrite(actual) {
@@ -370,6 +480,13 @@ func TestIsCreate_Expected(t *testing.T) {
 	}
 }
 
+func TestIsRename_Expected(t *testing.T) {
+	actual := fsnotify.Event{Op: fsnotify.Rename | fsnotify.Chmod}
+	if !isRename(actual) {
+		t.Errorf("Expected true, got false")
+	}
+}
+
 func TestIsRemove_Expected(t *testing.T) {
 	actual := fsnotify.Event{Op: fsnotify.Remove}
 	if !isRemove(actual) {
@@ -16,8 +16,13 @@ limitations under the License.
 package httpserver
 
 import (
+	"time"
+
+	ec "github.com/deislabs/ratify/pkg/executor/config"
 	"github.com/deislabs/ratify/pkg/executor/types"
+	pc "github.com/deislabs/ratify/pkg/policyprovider/config"
 	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore/inline"
 )
 
 const (
@@ -25,15 +30,48 @@ const (
 	// Starting from this version, the verification result can be
 	// evaluated by Ratify embedded OPA engine.
 	ResultVersionSupportingRego = "1.0.0"
+	// Starting from this version, the verification result reports the
+	// structured result of each artifact instead of the verifier reports.
+	ResultVersionArtifactReports = "2.0.0"
 )
 
 type VerificationResponse struct {
-	Version         string        `json:"version"`
-	IsSuccess       bool          `json:"isSuccess"`
-	VerifierReports []interface{} `json:"verifierReports,omitempty"`
+	Version         string                 `json:"version"`
+	IsSuccess       bool                   `json:"isSuccess"`
+	VerifierReports []interface{}          `json:"verifierReports,omitempty"`
+	ArtifactReports []types.ArtifactReport `json:"artifactReports,omitempty"`
+	// Warning explains why a subject failing verification is allowed, e.g. by
+	// the audit enforcement mode.
+	Warning string `json:"warning,omitempty"`
+	// TraceID is the correlation ID of the request, the logs of the executor
+	// and of the plugins verifying the subject are labeled with it.
+	TraceID string `json:"traceID,omitempty"`
+
+	// validUntil is the earliest expiry of the trust material the result
+	// relies on, responses are not cached beyond it.
+	validUntil *time.Time
+}
+
+// VerifyContentRequest is the request body of the verify-content endpoint. The
+// manifests and blobs are base64 encoded.
+type VerifyContentRequest struct {
+	// Repository is the repository the subject is going to be pushed to, it is
+	// used to reference the subject during verification.
+	Repository string `json:"repository,omitempty"`
+	// VerificationTime is the time to evaluate trust at instead of the current time.
+	VerificationTime *time.Time `json:"verificationTime,omitempty"`
+	inline.Content
 }
 
-func fromVerifyResult(res types.VerifyResult, policyType string) VerificationResponse {
+func fromVerifyResult(res types.VerifyResult, policyType string, reportVersion string) VerificationResponse {
+	if reportVersion == ec.ReportVersionV2 {
+		return VerificationResponse{
+			Version:         ResultVersionArtifactReports,
+			IsSuccess:       res.IsSuccess,
+			ArtifactReports: types.NewArtifactReports(res.VerifierReports),
+			validUntil:      res.ValidUntil(),
+		}
+	}
 	version := VerificationResultVersion
 	if policyType == pt.RegoPolicy {
 		version = ResultVersionSupportingRego
@@ -42,5 +80,33 @@ func fromVerifyResult(res types.VerifyResult, policyType string) VerificationRes
 		Version:         version,
 		IsSuccess:       res.IsSuccess,
 		VerifierReports: res.VerifierReports,
+		validUntil:      res.ValidUntil(),
 	}
 }
+
+// VerifyRequest is the request body of the verify endpoint of the REST API.
+type VerifyRequest struct {
+	// Subjects are the references of the subjects to verify.
+	Subjects []string `json:"subjects"`
+	// Policy overrides the configured policy provider for the request, e.g.
+	// {"name": "configpolicy", "artifactVerificationPolicies": {...}}.
+	Policy pc.PolicyPluginConfig `json:"policy,omitempty"`
+}
+
+// SubjectVerification is the result of verifying a subject of a request to
+// the verify endpoint of the REST API.
+type SubjectVerification struct {
+	Subject   string `json:"subject"`
+	IsSuccess bool   `json:"isSuccess"`
+	// Result has the reports of the verifiers, it is empty if the subject
+	// could not be verified.
+	Result *VerificationResponse `json:"result,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// VerifyResponse is the response of the verify endpoint of the REST API, it
+// is successful if all subjects are verified successfully.
+type VerifyResponse struct {
+	IsSuccess bool                  `json:"isSuccess"`
+	Subjects  []SubjectVerification `json:"subjects"`
+}
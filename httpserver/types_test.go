@@ -27,6 +27,7 @@ func TestFromVerifyResult(t *testing.T) {
 	testCases := []struct {
 		name            string
 		policyType      string
+		reportVersion   string
 		expectedVersion string
 	}{
 		{
@@ -39,11 +40,17 @@ func TestFromVerifyResult(t *testing.T) {
 			policyType:      pt.ConfigPolicy,
 			expectedVersion: "0.1.0",
 		},
+		{
+			name:            "Artifact reports",
+			policyType:      pt.RegoPolicy,
+			reportVersion:   "v2",
+			expectedVersion: "2.0.0",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if res := fromVerifyResult(result, tc.policyType); res.Version != tc.expectedVersion {
+			if res := fromVerifyResult(result, tc.policyType, tc.reportVersion); res.Version != tc.expectedVersion {
 				t.Fatalf("Expected version to be %s, got %s", tc.expectedVersion, res.Version)
 			}
 		})
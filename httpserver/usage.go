@@ -0,0 +1,130 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/metrics"
+	pkgUtils "github.com/deislabs/ratify/pkg/utils"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+const (
+	// usageRetentionDays is the number of days the usage is kept for.
+	usageRetentionDays = 31
+	usageDateFormat    = "2006-01-02"
+)
+
+// Usage is the number of verifications of a namespace on a day.
+type Usage struct {
+	// Date is the UTC day of the verifications, e.g. 2023-08-01.
+	Date string `json:"date"`
+	// Namespace is the namespace of the requests, empty for cluster-wide
+	// requests.
+	Namespace string `json:"namespace"`
+	// Verifications is the number of verified request keys.
+	Verifications int64 `json:"verifications"`
+}
+
+type usageKey struct {
+	date      string
+	namespace string
+}
+
+// usageStore is an in-memory store of the verifications per namespace per day
+// of a replica.
+type usageStore struct {
+	mu    sync.Mutex
+	usage map[usageKey]int64
+}
+
+// record counts a verification of the namespace.
+func (s *usageStore) record(namespace string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage == nil {
+		s.usage = map[usageKey]int64{}
+	}
+	s.prune(now)
+	s.usage[usageKey{date: now.UTC().Format(usageDateFormat), namespace: namespace}]++
+}
+
+// list returns the usage of the retained days sorted by date and namespace,
+// only the usage of the namespace is returned if the filter is set.
+func (s *usageStore) list(namespace string, filter bool, now time.Time) []Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	usage := make([]Usage, 0, len(s.usage))
+	for key, verifications := range s.usage {
+		if filter && key.namespace != namespace {
+			continue
+		}
+		usage = append(usage, Usage{Date: key.date, Namespace: key.namespace, Verifications: verifications})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Date != usage[j].Date {
+			return usage[i].Date < usage[j].Date
+		}
+		return usage[i].Namespace < usage[j].Namespace
+	})
+	return usage
+}
+
+// prune deletes the usage of the days past the retention, the caller must
+// hold the lock.
+func (s *usageStore) prune(now time.Time) {
+	oldest := now.UTC().AddDate(0, 0, -usageRetentionDays+1).Format(usageDateFormat)
+	for key := range s.usage {
+		if key.date < oldest {
+			delete(s.usage, key)
+		}
+	}
+}
+
+// requestNamespace returns the context of a request key labeled with the
+// namespace of the key, and the namespace.
+func requestNamespace(ctx context.Context, key string) (context.Context, string) {
+	requestKey, err := pkgUtils.ParseRequestKey(key)
+	if err != nil {
+		return ctx, ""
+	}
+	return logger.WithNamespace(ctx, requestKey.Namespace), requestKey.Namespace
+}
+
+// recordUsage accounts the verification of a request key to its namespace.
+func (server *Server) recordUsage(ctx context.Context, namespace string, item externaldata.Item) {
+	server.usage.record(namespace, time.Now())
+	metrics.ReportNamespaceVerification(ctx, item.Error != "")
+}
+
+// listUsage returns the verifications per namespace per day of the replica,
+// the namespace query parameter restricts the usage to a namespace.
+func (server *Server) listUsage(_ context.Context, w http.ResponseWriter, r *http.Request) error {
+	namespace, filter := "", false
+	if values, ok := r.URL.Query()["namespace"]; ok && len(values) > 0 {
+		namespace, filter = values[0], true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(server.usage.list(namespace, filter, time.Now()))
+}
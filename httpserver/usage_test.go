@@ -0,0 +1,134 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestUsageStore(t *testing.T) {
+	now := time.Date(2023, 8, 31, 23, 0, 0, 0, time.UTC)
+	store := usageStore{}
+	store.record("team-b", now)
+	store.record("team-a", now)
+	store.record("team-a", now)
+	store.record("", now.Add(-24*time.Hour))
+	store.record("team-a", now.AddDate(0, 0, -usageRetentionDays))
+
+	expected := []Usage{
+		{Date: "2023-08-30", Namespace: "", Verifications: 1},
+		{Date: "2023-08-31", Namespace: "team-a", Verifications: 2},
+		{Date: "2023-08-31", Namespace: "team-b", Verifications: 1},
+	}
+	usage := store.list("", false, now)
+	if len(usage) != len(expected) {
+		t.Fatalf("expected usage %+v, got %+v", expected, usage)
+	}
+	for i := range expected {
+		if usage[i] != expected[i] {
+			t.Fatalf("expected usage %+v, got %+v", expected, usage)
+		}
+	}
+
+	if usage := store.list("", true, now); len(usage) != 1 || usage[0] != expected[0] {
+		t.Fatalf("expected usage of cluster-wide requests, got %+v", usage)
+	}
+
+	// usage past the retention is pruned
+	if usage := store.list("team-a", true, now.AddDate(0, 0, usageRetentionDays)); len(usage) != 0 {
+		t.Fatalf("expected expired usage to be pruned, got %+v", usage)
+	}
+}
+
+func TestRequestNamespace(t *testing.T) {
+	ctx, namespace := requestNamespace(context.Background(), "[team-a]localhost:5000/net-monitor:v1")
+	if namespace != "team-a" || logger.GetNamespace(ctx) != "team-a" {
+		t.Fatalf("expected namespace team-a, got %s and %s", namespace, logger.GetNamespace(ctx))
+	}
+	ctx, namespace = requestNamespace(context.Background(), "localhost:5000/net-monitor:v1")
+	if namespace != "" || logger.GetNamespace(ctx) != "" {
+		t.Fatalf("expected no namespace, got %s and %s", namespace, logger.GetNamespace(ctx))
+	}
+}
+
+func TestServer_Usage(t *testing.T) {
+	testDigest := digest.FromString("test")
+	subject := "localhost:5000/net-monitor@" + testDigest.String()
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"": testDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+	}
+	ctx := context.Background()
+	server, err := NewServer(ctx, "localhost:0", func() *core.Executor { return ex }, "", "", 0, false, "", 0)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	serve := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		responseRecorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(responseRecorder, httptest.NewRequest(method, ServerRootURL+path, bytes.NewReader(body)))
+		return responseRecorder
+	}
+
+	body, _ := json.Marshal(externaldata.ProviderRequest{Request: externaldata.Request{Keys: []string{"[team-a]" + subject, "[team-a]" + subject, subject}}})
+	if code := serve(http.MethodPost, "/verify", body).Code; code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+
+	var usage []Usage
+	if err := json.NewDecoder(serve(http.MethodGet, "/usage?namespace=team-a", nil).Body).Decode(&usage); err != nil {
+		t.Fatalf("failed to decode usage: %v", err)
+	}
+	if len(usage) != 1 || usage[0].Namespace != "team-a" || usage[0].Verifications != 2 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if err := json.NewDecoder(serve(http.MethodGet, "/usage", nil).Body).Decode(&usage); err != nil {
+		t.Fatalf("failed to decode usage: %v", err)
+	}
+	if len(usage) != 2 || usage[0].Namespace != "" || usage[0].Verifications != 1 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
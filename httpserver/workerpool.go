@@ -0,0 +1,133 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/deislabs/ratify/pkg/executor"
+)
+
+// errVerificationQueueFull is returned when the subjects of a request are not
+// queued because too many subjects are waiting for a worker.
+var errVerificationQueueFull = errors.New("too many subjects are waiting to be verified")
+
+// verificationPool bounds the number of subjects verified at the same time
+// across all requests. Each request class has its own workers and queue, so
+// that audit requests do not hold up or reject admission requests.
+type verificationPool struct {
+	mu      sync.Mutex
+	classes map[executor.RequestClass]*classWorkers
+}
+
+// classWorkers are the workers and the queue of subjects waiting for a worker
+// of a request class.
+type classWorkers struct {
+	// slots is nil if the number of concurrent verifications is not limited
+	slots  chan struct{}
+	queued int
+}
+
+// verifyEach calls verify for each of the n subjects of a request once a
+// worker of the request class of the context is available, scheduleErr is set
+// if the request context ended while waiting. At most
+// RequestLimit.MaxConcurrentKeys subjects of the request and
+// RequestLimit.MaxConcurrentVerifications subjects of all requests of the
+// class are verified at the same time. The subjects are rejected if more than
+// RequestLimit.MaxQueuedVerifications subjects of the class would be waiting
+// for a worker.
+func (server *Server) verifyEach(ctx context.Context, n int, verify func(i int, scheduleErr error)) error {
+	workers, err := server.verificationPool.enqueue(executor.RequestClassFromContext(ctx), n, server.RequestLimit.MaxConcurrentVerifications, server.RequestLimit.MaxQueuedVerifications)
+	if err != nil {
+		return err
+	}
+
+	pending := make(chan int, n)
+	for i := 0; i < n; i++ {
+		pending <- i
+	}
+	close(pending)
+
+	requestWorkers := n
+	if maxWorkers := server.RequestLimit.MaxConcurrentKeys; maxWorkers > 0 && maxWorkers < requestWorkers {
+		requestWorkers = maxWorkers
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < requestWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				release, err := server.verificationPool.acquire(ctx, workers)
+				verify(i, err)
+				if err == nil {
+					release()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// enqueue adds n subjects to the queue of the class, it fails if more than
+// maxQueued subjects would be waiting. maxQueued of 0 does not limit the queue.
+func (p *verificationPool) enqueue(class executor.RequestClass, n, maxConcurrent, maxQueued int) (*classWorkers, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.classes == nil {
+		p.classes = map[executor.RequestClass]*classWorkers{}
+	}
+	workers, ok := p.classes[class]
+	if !ok {
+		workers = &classWorkers{}
+		if maxConcurrent > 0 {
+			workers.slots = make(chan struct{}, maxConcurrent)
+		}
+		p.classes[class] = workers
+	}
+	if maxQueued > 0 && workers.queued+n > maxQueued {
+		return nil, errVerificationQueueFull
+	}
+	workers.queued += n
+	return workers, nil
+}
+
+// acquire removes a queued subject from the queue once a worker is available
+// and returns the function releasing the worker.
+func (p *verificationPool) acquire(ctx context.Context, workers *classWorkers) (func(), error) {
+	defer p.dequeue(workers)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if workers.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case workers.slots <- struct{}{}:
+		return func() { <-workers.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *verificationPool) dequeue(workers *classWorkers) {
+	p.mu.Lock()
+	workers.queued--
+	p.mu.Unlock()
+}
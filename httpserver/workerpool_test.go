@@ -0,0 +1,138 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+func TestVerificationPool(t *testing.T) {
+	var pool verificationPool
+	if _, err := pool.enqueue(executor.RequestClassAdmission, 3, 1, 2); !errors.Is(err, errVerificationQueueFull) {
+		t.Fatalf("expected subjects exceeding the queue to be rejected, got %v", err)
+	}
+	workers, err := pool.enqueue(executor.RequestClassAdmission, 2, 1, 2)
+	if err != nil {
+		t.Fatalf("expected subjects to be queued, got %v", err)
+	}
+	if _, err := pool.enqueue(executor.RequestClassAdmission, 1, 1, 2); !errors.Is(err, errVerificationQueueFull) {
+		t.Fatalf("expected full queue, got %v", err)
+	}
+
+	release, err := pool.acquire(context.Background(), workers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.acquire(ctx, workers); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected subject to wait for a worker, got %v", err)
+	}
+	release()
+
+	if workers.queued != 0 {
+		t.Fatalf("expected empty queue, got %d queued subjects", workers.queued)
+	}
+	if _, err := pool.enqueue(executor.RequestClassAdmission, 1, 1, 2); err != nil {
+		t.Fatalf("expected subjects to be queued once the queue drained, got %v", err)
+	}
+}
+
+func TestVerificationPool_AuditDoesNotBlockAdmission(t *testing.T) {
+	var pool verificationPool
+	audit, err := pool.enqueue(executor.RequestClassAudit, 2, 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release, err := pool.acquire(context.Background(), audit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+	if _, err := pool.enqueue(executor.RequestClassAudit, 2, 1, 2); !errors.Is(err, errVerificationQueueFull) {
+		t.Fatalf("expected full audit queue, got %v", err)
+	}
+
+	admission, err := pool.enqueue(executor.RequestClassAdmission, 2, 1, 2)
+	if err != nil {
+		t.Fatalf("expected admission subjects to be queued, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	admissionRelease, err := pool.acquire(ctx, admission)
+	if err != nil {
+		t.Fatalf("expected admission subject to get a worker, got %v", err)
+	}
+	admissionRelease()
+}
+
+func TestServer_Verify_QueueFull(t *testing.T) {
+	body, err := json.Marshal(externaldata.NewProviderRequest([]string{"&&"}))
+	if err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+	server := &Server{
+		Context:      context.Background(),
+		RequestLimit: RequestLimitConfig{MaxConcurrentVerifications: 1, MaxQueuedVerifications: 1},
+	}
+	if _, err := server.verificationPool.enqueue(executor.RequestClassAdmission, 1, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := server.verify(context.Background(), w, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify", bytes.NewReader(body))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 503 with Retry-After, got status %d", w.Code)
+	}
+	var response externaldata.ProviderResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if response.Response.SystemError != errVerificationQueueFull.Error() {
+		t.Fatalf("expected system error %q, got %q", errVerificationQueueFull, response.Response.SystemError)
+	}
+}
+
+func TestServer_VerifyContent_QueueFull(t *testing.T) {
+	server := &Server{
+		Context:      context.Background(),
+		RequestLimit: RequestLimitConfig{MaxConcurrentVerifications: 1, MaxQueuedVerifications: 1},
+	}
+	if _, err := server.verificationPool.enqueue(executor.RequestClassAdmission, 1, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify-content", bytes.NewReader([]byte(`{"repository":"localhost:5000/net-monitor"}`)))
+	if err := server.verifyContent(context.Background(), w, request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 503 with Retry-After, got status %d", w.Code)
+	}
+}
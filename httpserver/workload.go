@@ -0,0 +1,102 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/workload"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+// verifyWorkload verifies the images of all containers of the workloads in the
+// YAML or JSON manifest of the request body, e.g. a Deployment, and returns an
+// aggregated decision with the results of each container.
+func (server *Server) verifyWorkload(_ context.Context, w http.ResponseWriter, r *http.Request) error {
+	ctx, cancel := context.WithTimeout(r.Context(), server.GetExecutor().GetVerifyRequestTimeout())
+	defer cancel()
+	ctx = logger.InitContext(ctx, r)
+
+	body, err := server.readLimitedBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	workloads, err := workload.Parse(body)
+	if err != nil {
+		return errors.ErrorCodeBadRequest.WithError(err)
+	}
+
+	summary := workload.Verify(ctx, workloads, server.verifyWorkloadImage)
+	logger.GetLogger(ctx, server.LogOption).Infof("verified %d workloads, success: %v", len(workloads), summary.IsSuccess)
+
+	response, err := json.Marshal(summary)
+	if err != nil {
+		return errors.ErrorCodeDataEncodingFailure.WithError(err).WithDetail("unable to marshal response")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	server.writeVerificationProof(ctx, w, response, server.GetExecutor().ConfigGeneration)
+	_, err = w.Write(response)
+	return err
+}
+
+// verifyWorkloadImage verifies the image like Gatekeeper requests, so that
+// results are shared with admission requests through the cache and the
+// namespaced policies of the workload apply.
+func (server *Server) verifyWorkloadImage(ctx context.Context, w workload.Workload, image string) (bool, interface{}, error) {
+	return server.verifyImage(ctx, w.Namespace, "", image)
+}
+
+// verifyImage verifies the image of a workload in the namespace, the admission
+// operation is optional.
+func (server *Server) verifyImage(ctx context.Context, namespace, operation, image string) (bool, interface{}, error) {
+	return imageResult(image, server.verifyImageKey(ctx, namespace, operation, image))
+}
+
+// verifyImageKey verifies the image with the request key of the namespace and
+// operation.
+func (server *Server) verifyImageKey(ctx context.Context, namespace, operation, image string) externaldata.Item {
+	key := image
+	if operation != "" {
+		key = fmt.Sprintf("[operation:%s]%s", operation, key)
+	}
+	if namespace != "" {
+		key = fmt.Sprintf("[%s]%s", namespace, key)
+	}
+	ctx = logger.WithNamespace(ctx, namespace)
+	item := server.verifyKey(ctx, key)
+	server.recordUsage(ctx, namespace, item)
+	server.recordAudit(ctx, namespace, item)
+	return item
+}
+
+// imageResult converts the verification result of the image to the result of
+// a workload container.
+func imageResult(image string, item externaldata.Item) (bool, interface{}, error) {
+	if item.Error != "" {
+		return false, nil, fmt.Errorf("%s", item.Error)
+	}
+	response, ok := item.Value.(VerificationResponse)
+	if !ok {
+		return false, nil, fmt.Errorf("unexpected verification result of image %s", image)
+	}
+	return response.IsSuccess, response, nil
+}
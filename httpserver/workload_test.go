@@ -0,0 +1,109 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/workload"
+	"github.com/opencontainers/go-digest"
+)
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: net-monitor
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: localhost:5000/net-monitor:v1
+        - name: sidecar
+          image: localhost:5000/sidecar:v2
+`
+
+func TestServer_VerifyWorkload(t *testing.T) {
+	ex := &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				testArtifactType: types.AnyVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			// the sidecar tag cannot be resolved
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("test")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return true
+			},
+		}},
+	}
+	server := &Server{
+		GetExecutor: func() *core.Executor { return ex },
+		Context:     context.Background(),
+	}
+	handler := contextHandler{context: server.Context, handler: server.verifyWorkload}
+
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify-workload", strings.NewReader(testDeployment)))
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+	var summary workload.Summary
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if summary.IsSuccess || len(summary.Workloads) != 1 || len(summary.Workloads[0].Containers) != 2 {
+		t.Fatalf("expected failed summary of 1 workload with 2 containers, got %+v", summary)
+	}
+	app, sidecar := summary.Workloads[0].Containers[0], summary.Workloads[0].Containers[1]
+	if !app.IsSuccess || app.Name != "app" {
+		t.Fatalf("expected app container to pass verification, got %+v", app)
+	}
+	if sidecar.IsSuccess || sidecar.Result == nil {
+		t.Fatalf("expected sidecar container to fail verification with a report, got %+v", sidecar)
+	}
+
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify-workload", strings.NewReader("kind: ConfigMap\n")))
+	if responseRecorder.Code == http.StatusOK {
+		t.Fatalf("expected unsupported workload to be rejected")
+	}
+
+	server.RequestLimit = RequestLimitConfig{MaxBodyBytes: int64(len(testDeployment) - 1)}
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, httptest.NewRequest(http.MethodPost, "/ratify/gatekeeper/v1/verify-workload", strings.NewReader(testDeployment)))
+	if responseRecorder.Code == http.StatusOK || !strings.Contains(responseRecorder.Body.String(), "request body exceeds the maximum size") {
+		t.Fatalf("expected manifest exceeding the maximum body size to be rejected, got status %d: %s", responseRecorder.Code, responseRecorder.Body.String())
+	}
+}
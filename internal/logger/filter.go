@@ -0,0 +1,212 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatterEnvVar overrides the formatter of the config file.
+	FormatterEnvVar = "RATIFY_LOG_FORMATTER"
+	// ComponentLevelsEnvVar overrides the levels of components of the config
+	// file, e.g. "executor=debug,referrerStore=warn".
+	ComponentLevelsEnvVar = "RATIFY_LOG_COMPONENT_LEVELS"
+	// SamplingInitialEnvVar overrides the number of debug logs with the same
+	// message written per sampling interval before sampling starts.
+	SamplingInitialEnvVar = "RATIFY_LOG_SAMPLING_INITIAL"
+	// SamplingThereafterEnvVar overrides the sampling rate of debug logs with
+	// the same message once the initial logs are written.
+	SamplingThereafterEnvVar = "RATIFY_LOG_SAMPLING_THEREAFTER"
+
+	defaultSamplingInterval = time.Second
+)
+
+// SamplingConfig limits the debug and trace logs with the same message, e.g.
+// logs of each artifact of large referrer graphs. Within each interval the
+// first Initial logs are written, then every Thereafter-th log, none if
+// Thereafter is 0.
+type SamplingConfig struct {
+	Initial    int `json:"initial"`
+	Thereafter int `json:"thereafter"`
+	// Interval is the duration the logs are counted for, e.g. 1s. Default to
+	// 1s if not specified.
+	Interval string `json:"interval,omitempty"`
+}
+
+// withEnv returns the config overridden by the logging environment variables.
+func withEnv(config Config) (Config, error) {
+	if formatter := os.Getenv(FormatterEnvVar); formatter != "" {
+		config.Formatter = formatter
+	}
+	if levels := os.Getenv(ComponentLevelsEnvVar); levels != "" {
+		config.ComponentLevels = map[string]string{}
+		for _, pair := range strings.Split(levels, ",") {
+			component, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return config, re.ErrorCodeConfigInvalid.WithDetail(fmt.Sprintf("invalid component log level %q of %s, expected component=level", pair, ComponentLevelsEnvVar))
+			}
+			config.ComponentLevels[component] = level
+		}
+	}
+	initial, hasInitial, err := envCount(SamplingInitialEnvVar)
+	if err != nil {
+		return config, err
+	}
+	thereafter, hasThereafter, err := envCount(SamplingThereafterEnvVar)
+	if err != nil {
+		return config, err
+	}
+	if hasInitial || hasThereafter {
+		sampling := SamplingConfig{}
+		if config.Sampling != nil {
+			sampling = *config.Sampling
+		}
+		if hasInitial {
+			sampling.Initial = initial
+		}
+		if hasThereafter {
+			sampling.Thereafter = thereafter
+		}
+		config.Sampling = &sampling
+	}
+	return config, nil
+}
+
+// envCount returns the non-negative integer of the environment variable and
+// whether it is set.
+func envCount(name string) (int, bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false, re.ErrorCodeConfigInvalid.WithDetail(fmt.Sprintf("invalid value %q of %s, expected a non-negative integer", value, name))
+	}
+	return n, true, nil
+}
+
+// filterFormatter drops the logs of components below their configured level
+// and samples debug logs before they are formatted. The level of the logger
+// is lowered to the most verbose component level so that the logs reach the
+// formatter.
+type filterFormatter struct {
+	logrus.Formatter
+	level           logrus.Level
+	componentLevels map[string]logrus.Level
+	sampler         *sampler
+}
+
+// newFilterFormatter returns a formatter filtering the logs written with the
+// base formatter, or nil if no component levels or sampling are configured.
+func newFilterFormatter(base logrus.Formatter, level logrus.Level, config Config) (*filterFormatter, error) {
+	if len(config.ComponentLevels) == 0 && config.Sampling == nil {
+		return nil, nil
+	}
+	f := &filterFormatter{
+		Formatter:       base,
+		level:           level,
+		componentLevels: map[string]logrus.Level{},
+	}
+	for component, value := range config.ComponentLevels {
+		componentLevel, err := logrus.ParseLevel(value)
+		if err != nil {
+			return nil, re.ErrorCodeConfigInvalid.WithDetail(fmt.Sprintf("invalid log level %s of component %s", value, component))
+		}
+		f.componentLevels[component] = componentLevel
+	}
+	if config.Sampling != nil {
+		interval := defaultSamplingInterval
+		if config.Sampling.Interval != "" {
+			var err error
+			if interval, err = time.ParseDuration(config.Sampling.Interval); err != nil || interval <= 0 {
+				return nil, re.ErrorCodeConfigInvalid.WithDetail(fmt.Sprintf("invalid log sampling interval %s", config.Sampling.Interval))
+			}
+		}
+		f.sampler = &sampler{
+			initial:    config.Sampling.Initial,
+			thereafter: config.Sampling.Thereafter,
+			interval:   interval,
+			counts:     map[string]int{},
+		}
+	}
+	return f, nil
+}
+
+// maxLevel returns the most verbose level of the logger and the components.
+func (f *filterFormatter) maxLevel() logrus.Level {
+	level := f.level
+	for _, componentLevel := range f.componentLevels {
+		if componentLevel > level {
+			level = componentLevel
+		}
+	}
+	return level
+}
+
+// Format formats the entry with the base formatter, dropped entries are
+// formatted to nothing.
+func (f *filterFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	level := f.level
+	if component, ok := entry.Data[ContextKeyComponentType.String()]; ok {
+		if componentLevel, ok := f.componentLevels[fmt.Sprint(component)]; ok {
+			level = componentLevel
+		}
+	}
+	if entry.Level > level {
+		return nil, nil
+	}
+	if f.sampler != nil && entry.Level >= logrus.DebugLevel && !f.sampler.sample(entry.Message, entry.Time) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// sampler counts the logs of each message per interval.
+type sampler struct {
+	initial    int
+	thereafter int
+	interval   time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+// sample reports whether the log of the message is written.
+func (s *sampler) sample(message string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= s.interval {
+		s.start = now
+		s.counts = map[string]int{}
+	}
+	s.counts[message]++
+	n := s.counts[message]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
@@ -0,0 +1,152 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWithEnv(t *testing.T) {
+	testCases := []struct {
+		name      string
+		env       map[string]string
+		config    Config
+		expected  Config
+		expectErr bool
+	}{
+		{
+			name:     "no env",
+			config:   Config{Formatter: "text"},
+			expected: Config{Formatter: "text"},
+		},
+		{
+			name: "overrides",
+			env: map[string]string{
+				FormatterEnvVar:          "json",
+				ComponentLevelsEnvVar:    "executor=debug, referrerStore=warn",
+				SamplingThereafterEnvVar: "10",
+			},
+			config: Config{Formatter: "text", ComponentLevels: map[string]string{"server": "debug"}, Sampling: &SamplingConfig{Initial: 5, Interval: "2s"}},
+			expected: Config{
+				Formatter:       "json",
+				ComponentLevels: map[string]string{"executor": "debug", "referrerStore": "warn"},
+				Sampling:        &SamplingConfig{Initial: 5, Thereafter: 10, Interval: "2s"},
+			},
+		},
+		{
+			name:      "invalid component level",
+			env:       map[string]string{ComponentLevelsEnvVar: "executor"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid sampling",
+			env:       map[string]string{SamplingInitialEnvVar: "-1"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{FormatterEnvVar, ComponentLevelsEnvVar, SamplingInitialEnvVar, SamplingThereafterEnvVar} {
+				t.Setenv(name, tc.env[name])
+			}
+			config, err := withEnv(tc.config)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectErr, err)
+			}
+			if err == nil && !reflect.DeepEqual(config, tc.expected) {
+				t.Fatalf("expected config %+v, got %+v", tc.expected, config)
+			}
+		})
+	}
+}
+
+func TestFilterFormatter(t *testing.T) {
+	if f, err := newFilterFormatter(&logrus.TextFormatter{}, logrus.InfoLevel, Config{}); err != nil || f != nil {
+		t.Fatalf("expected no filter, got %v, %v", f, err)
+	}
+	if _, err := newFilterFormatter(&logrus.TextFormatter{}, logrus.InfoLevel, Config{ComponentLevels: map[string]string{"executor": "verbose"}}); err == nil {
+		t.Fatalf("expected error of invalid level")
+	}
+	if _, err := newFilterFormatter(&logrus.TextFormatter{}, logrus.InfoLevel, Config{Sampling: &SamplingConfig{Interval: "soon"}}); err == nil {
+		t.Fatalf("expected error of invalid interval")
+	}
+
+	f, err := newFilterFormatter(&logrus.TextFormatter{}, logrus.InfoLevel, Config{
+		ComponentLevels: map[string]string{string(Executor): "debug", string(Server): "error"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level := f.maxLevel(); level != logrus.DebugLevel {
+		t.Fatalf("expected max level debug, got %s", level)
+	}
+	testCases := []struct {
+		name      string
+		component componentType
+		level     logrus.Level
+		written   bool
+	}{
+		{name: "debug log of verbose component", component: Executor, level: logrus.DebugLevel, written: true},
+		{name: "warning of quiet component", component: Server, level: logrus.WarnLevel},
+		{name: "error of quiet component", component: Server, level: logrus.ErrorLevel, written: true},
+		{name: "debug log of other component", component: ReferrerStore, level: logrus.DebugLevel},
+		{name: "info log of other component", component: ReferrerStore, level: logrus.InfoLevel, written: true},
+		{name: "debug log without component", level: logrus.DebugLevel},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := &logrus.Entry{Level: tc.level, Message: "test", Data: logrus.Fields{}}
+			if tc.component != "" {
+				entry.Data[ContextKeyComponentType.String()] = tc.component
+			}
+			out, err := f.Format(entry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if written := len(out) > 0; written != tc.written {
+				t.Fatalf("expected written: %v, got: %v", tc.written, written)
+			}
+		})
+	}
+}
+
+func TestSampler(t *testing.T) {
+	s := &sampler{initial: 2, thereafter: 3, interval: time.Second, counts: map[string]int{}}
+	now := time.Now()
+	var sampled []bool
+	for i := 0; i < 8; i++ {
+		sampled = append(sampled, s.sample("verifying artifact", now))
+	}
+	expected := []bool{true, true, false, false, true, false, false, true}
+	if !reflect.DeepEqual(sampled, expected) {
+		t.Fatalf("expected samples %v, got %v", expected, sampled)
+	}
+	if !s.sample("other message", now) {
+		t.Fatalf("expected other messages to be counted separately")
+	}
+	if !s.sample("verifying artifact", now.Add(time.Second)) {
+		t.Fatalf("expected counts to reset after the interval")
+	}
+
+	s = &sampler{initial: 1, interval: time.Second, counts: map[string]int{}}
+	if !s.sample("verifying artifact", now) || s.sample("verifying artifact", now) {
+		t.Fatalf("expected logs after the initial ones to be dropped")
+	}
+}
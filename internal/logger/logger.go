@@ -47,6 +47,12 @@ type Option struct {
 type Config struct {
 	Formatter      string                 `json:"formatter,omitempty"`
 	RequestHeaders map[string]interface{} `json:"requestHeaders"`
+	// ComponentLevels overrides the log level of components, e.g.
+	// {"executor": "debug"}. The keys are the component types of the logs.
+	ComponentLevels map[string]string `json:"componentLevels,omitempty"`
+	// Sampling limits the debug logs with the same message, debug logs are
+	// not sampled if nil.
+	Sampling *SamplingConfig `json:"sampling,omitempty"`
 }
 
 var traceIDHeaderNames = make([]string, 0)
@@ -54,6 +60,8 @@ var traceIDHeaderNames = make([]string, 0)
 const (
 	// ContextKeyTraceID is the context key for the trace ID.
 	ContextKeyTraceID = ContextKey("trace-id")
+	// ContextKeyNamespace is the context key for the namespace of the request.
+	ContextKeyNamespace = ContextKey("namespace")
 	// ContextKeyComponentType is the context key for the component type.
 	ContextKeyComponentType = ContextKey("component-type")
 	// Executor is the component type for the executor.
@@ -66,20 +74,53 @@ const (
 	Cache componentType = "cache"
 	// CertProvider is the component type for certificate provider.
 	CertProvider componentType = "certificateProvider"
+	// KeyManagementProvider is the component type for key management provider.
+	KeyManagementProvider componentType = "keyManagementProvider"
 	// AuthProvider is the component type for auth provider.
 	AuthProvider componentType = "authProvider"
 	// PolicyProvider is the component type for policy provider.
 	PolicyProvider componentType = "policyProvider"
 	// Verifier is the component type for verifier.
 	Verifier componentType = "verifier"
+	// Plugin is the component type for the invocations of external plugins.
+	Plugin componentType = "plugin"
 
 	traceIDHeaderName = "traceIDHeaderName"
+
+	// RequestIDHeader is the header of the correlation ID of a request. An
+	// incoming ID is used as the trace ID if none of the configured trace ID
+	// headers is set, the trace ID is returned in it.
+	RequestIDHeader = "X-Request-ID"
 )
 
-// InitLogConfig initializes log configuration for the server.
+// InitLogConfig initializes log configuration for the server. The logging
+// environment variables override the config.
 func InitLogConfig(config Config) error {
+	config, err := withEnv(config)
+	if err != nil {
+		return err
+	}
+	// the level of the logger is lowered by the component levels of a
+	// previous config
+	level := logrus.GetLevel()
+	if previous, ok := logrus.StandardLogger().Formatter.(*filterFormatter); ok {
+		level = previous.level
+	}
 	initTraceIDHeaders(config.RequestHeaders)
-	return setFormatter(config.Formatter)
+	if err := setFormatter(config.Formatter); err != nil {
+		return err
+	}
+	filter, err := newFilterFormatter(logrus.StandardLogger().Formatter, level, config)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		logrus.SetLevel(level)
+		return nil
+	}
+	logrus.SetFormatter(filter)
+	logrus.SetLevel(filter.maxLevel())
+	return nil
 }
 
 // InitContext initializes the context with required loggers for a request.
@@ -87,6 +128,23 @@ func InitContext(ctx context.Context, r *http.Request) context.Context {
 	return setTraceID(ctx, r)
 }
 
+// WithNamespace sets the namespace of the request in the context, the logs of
+// the request are labeled with the namespace.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, ContextKeyNamespace, namespace)
+	return dcontext.WithLogger(ctx, dcontext.GetLogger(ctx, ContextKeyNamespace))
+}
+
+// GetNamespace returns the namespace of the request in the context, empty for
+// cluster-wide requests.
+func GetNamespace(ctx context.Context) string {
+	namespace, _ := ctx.Value(ContextKeyNamespace).(string)
+	return namespace
+}
+
 // GetLogger returns a logger with provided values.
 func GetLogger(ctx context.Context, opt Option) dcontext.Logger {
 	ctx = context.WithValue(ctx, ContextKeyComponentType, opt.ComponentType)
@@ -101,6 +159,9 @@ func setTraceID(ctx context.Context, r *http.Request) context.Context {
 			break
 		}
 	}
+	if traceID == "" {
+		traceID = r.Header.Get(RequestIDHeader)
+	}
 	if traceID == "" {
 		traceID = uuid.New().String()
 	}
@@ -108,6 +169,13 @@ func setTraceID(ctx context.Context, r *http.Request) context.Context {
 	return dcontext.WithLogger(ctx, dcontext.GetLogger(ctx, ContextKeyTraceID))
 }
 
+// GetTraceID returns the trace ID of the request in the context, empty if the
+// context is not of a request.
+func GetTraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(ContextKeyTraceID).(string)
+	return traceID
+}
+
 // SetTraceIDHeader sets the trace ID in the http header.
 func SetTraceIDHeader(ctx context.Context, header http.Header) http.Header {
 	traceID := ctx.Value(ContextKeyTraceID)
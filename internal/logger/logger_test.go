@@ -69,6 +69,14 @@ func TestInitContext(t *testing.T) {
 			},
 			expectedTraceID: testTraceID,
 		},
+		{
+			name:        "request has a correlation ID",
+			headerNames: []string{},
+			r: &http.Request{
+				Header: http.Header{"X-Request-Id": []string{"correlation-id"}},
+			},
+			expectedTraceID: "correlation-id",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -76,6 +84,9 @@ func TestInitContext(t *testing.T) {
 			traceIDHeaderNames = tc.headerNames
 			ctx := InitContext(context.Background(), tc.r)
 			traceID := dcontext.GetStringValue(ctx, ContextKeyTraceID)
+			if GetTraceID(ctx) != traceID {
+				t.Fatalf("expected GetTraceID to return %s, but got %s", traceID, GetTraceID(ctx))
+			}
 			if traceID == "" {
 				t.Fatalf("expected non-empty traceID, but got empty one")
 			}
@@ -116,6 +127,20 @@ func TestGetLogger(t *testing.T) {
 	}
 }
 
+func TestWithNamespace(t *testing.T) {
+	ctx := WithNamespace(context.Background(), "team-a")
+	if namespace := GetNamespace(ctx); namespace != "team-a" {
+		t.Fatalf("expected namespace team-a, but got %s", namespace)
+	}
+	entry := GetLogger(ctx, Option{ComponentType: testComponentType}).WithError(errors.New("test"))
+	if namespace := entry.Data["namespace"]; namespace != "team-a" {
+		t.Fatalf("expected namespace field team-a, but got %v", namespace)
+	}
+	if namespace := GetNamespace(WithNamespace(context.Background(), "")); namespace != "" {
+		t.Fatalf("expected no namespace, but got %s", namespace)
+	}
+}
+
 func TestInitTraceIDHeaders(t *testing.T) {
 	defer cleanup()
 
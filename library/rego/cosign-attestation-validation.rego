@@ -0,0 +1,42 @@
+# Copyright The Ratify Authors.
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+
+# http://www.apache.org/licenses/LICENSE-2.0
+
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+package ratify.policy
+
+import future.keywords.if
+import future.keywords.in
+
+# This template defines policy for cosign attestation validation.
+# It checks the following:
+# - There is at least one cosign attestation that was verified
+# - A verified attestation carries a SLSA provenance predicate
+# - The provenance was produced by a trusted builder
+
+default valid := false
+
+trusted_builders := {"https://github.com/actions/runner"} # change to the builder IDs allowed to produce the image
+
+valid if {
+    some attestation in verified_attestations(input)
+    attestation.predicateType == "https://slsa.dev/provenance/v1"
+    attestation.predicate.runDetails.builder.id in trusted_builders
+}
+
+verified_attestations(subject_result) := [att |
+    some i, j, k
+    report := subject_result.verifierReports[i].verifierReports[j]
+    report.name == "cosign"
+    report.isSuccess
+    att := report.extensions.attestations[k]
+    att.isSuccess
+]
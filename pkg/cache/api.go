@@ -25,6 +25,7 @@ const (
 	CacheKeyListReferrers     string = "cache_ratify_list_referrers_%s"
 	CacheKeyVerifyHandler     string = "cache_ratify_verify_handler_%s"
 	CacheKeyOrasAuth          string = "cache_ratify_oras_auth_%s"
+	CacheKeyBlob              string = "cache_ratify_blob_%s"
 
 	DefaultCacheType string = "ristretto"
 	// DefaultCacheTTL is the default time-to-live for the cache entry.
@@ -128,6 +128,26 @@ func (s *akvCertProvider) GetCertificates(ctx context.Context, attrib map[string
 	return certs, getCertStatusMap(certsStatus), nil
 }
 
+// NewKeyVaultClient returns a key vault client of the cloud authenticated with
+// the workload identity of the client ID.
+func NewKeyVaultClient(ctx context.Context, cloudName, tenantID, clientID string) (*kv.BaseClient, error) {
+	azureCloudEnv, err := parseAzureEnvironment(cloudName)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.CertProvider, providerName, re.EmptyLink, nil, fmt.Sprintf("cloudName %s is not valid", cloudName), re.HideStackTrace)
+	}
+	return initializeKvClient(ctx, azureCloudEnv.KeyVaultEndpoint, tenantID, clientID)
+}
+
+// GetCertificatesFromSecretBundle returns the certificate chain of the secret
+// bundle of a certificate and the version of the secret.
+func GetCertificatesFromSecretBundle(ctx context.Context, secretBundle kv.SecretBundle, certName string) ([]*x509.Certificate, string, error) {
+	certs, _, err := getCertsFromSecretBundle(ctx, secretBundle, certName)
+	if err != nil {
+		return nil, "", err
+	}
+	return certs, getObjectVersion(*secretBundle.ID), nil
+}
+
 // azure keyvault provider certificate status is a map from "certificates" key to an array of of certificate status
 func getCertStatusMap(certsStatus []map[string]string) certificateprovider.CertificatesStatus {
 	status := certificateprovider.CertificatesStatus{}
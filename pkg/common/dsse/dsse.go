@@ -0,0 +1,89 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dsse implements decoding and signature verification of Dead Simple
+// Signing Envelopes as specified in https://github.com/secure-systems-lab/dsse.
+package dsse
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PayloadTypeInToto is the payload type of envelopes wrapping in-toto statements.
+const PayloadTypeInToto = "application/vnd.in-toto+json"
+
+// ErrNoSignatures is returned when verifying an envelope without signatures.
+var ErrNoSignatures = errors.New("envelope has no signatures")
+
+// Envelope is a DSSE envelope. Payload and signatures are base64 encoded.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a single signature of a DSSE envelope.
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Decode parses a DSSE envelope from its JSON encoding. An error is returned if
+// the blob is not an envelope, i.e. is missing the payload type or payload.
+func Decode(blob []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(blob, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse DSSE envelope: %w", err)
+	}
+	if envelope.PayloadType == "" || envelope.Payload == "" {
+		return nil, fmt.Errorf("failed to parse DSSE envelope: missing payloadType or payload")
+	}
+	return &envelope, nil
+}
+
+// IsEnvelope returns true if the blob is a JSON encoded DSSE envelope.
+func IsEnvelope(blob []byte) bool {
+	_, err := Decode(blob)
+	return err == nil
+}
+
+// DecodePayload returns the decoded payload of the envelope.
+func (e *Envelope) DecodePayload() ([]byte, error) {
+	payload, err := decodeBase64(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope payload: %w", err)
+	}
+	return payload, nil
+}
+
+// PAE returns the pre-authentication encoding of the payload, which is the
+// message that is actually signed:
+// "DSSEv1" SP LEN(type) SP type SP LEN(body) SP body
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// decodeBase64 accepts both standard and URL safe encodings, with or without padding
+func decodeBase64(value string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64 encoding")
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsse
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+const testPayload = `{"_type":"https://in-toto.io/Statement/v1"}`
+
+func TestPAE(t *testing.T) {
+	// example from the DSSE specification
+	expected := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if result := string(PAE("http://example.com/HelloWorld", []byte("hello world"))); result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	blob, _ := json.Marshal(Envelope{
+		PayloadType: PayloadTypeInToto,
+		Payload:     base64.StdEncoding.EncodeToString([]byte(testPayload)),
+		Signatures:  []Signature{{KeyID: "key", Sig: "c2ln"}},
+	})
+	envelope, err := Decode(blob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, err := envelope.DecodePayload()
+	if err != nil || string(payload) != testPayload {
+		t.Fatalf("expected payload %s, got %s, err: %v", testPayload, payload, err)
+	}
+	if len(envelope.Signatures) != 1 || envelope.Signatures[0].KeyID != "key" {
+		t.Fatalf("unexpected signatures %v", envelope.Signatures)
+	}
+
+	if IsEnvelope([]byte(testPayload)) {
+		t.Fatalf("statement should not be detected as an envelope")
+	}
+	if _, err := Decode([]byte("not json")); err == nil {
+		t.Fatalf("expected error for invalid json")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ed25519Public, ed25519Private, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	message := PAE(PayloadTypeInToto, []byte(testPayload))
+	digest := sha256.Sum256(message)
+	ecdsaSig, _ := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	rsaSig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	ed25519Sig := ed25519.Sign(ed25519Private, message)
+
+	envelope := &Envelope{
+		PayloadType: PayloadTypeInToto,
+		Payload:     base64.StdEncoding.EncodeToString([]byte(testPayload)),
+		Signatures: []Signature{
+			{KeyID: "ecdsa", Sig: base64.StdEncoding.EncodeToString(ecdsaSig)},
+			{Sig: base64.StdEncoding.EncodeToString(rsaSig)},
+			{KeyID: "ed25519", Sig: base64.RawURLEncoding.EncodeToString(ed25519Sig)},
+		},
+	}
+
+	ecdsaVerifier, _ := NewVerifier("ecdsa", &ecdsaKey.PublicKey)
+	rsaVerifier, _ := NewVerifier("rsa", &rsaKey.PublicKey)
+	ed25519Verifier, _ := NewVerifier("", ed25519Public)
+	otherVerifier, _ := NewVerifier("other", &otherKey.PublicKey)
+
+	testCases := []struct {
+		name      string
+		verifiers []Verifier
+		threshold int
+		accepted  int
+		isSuccess bool
+	}{
+		{name: "all keys", verifiers: []Verifier{ecdsaVerifier, rsaVerifier, ed25519Verifier}, threshold: 3, accepted: 3, isSuccess: true},
+		{name: "default threshold", verifiers: []Verifier{rsaVerifier}, accepted: 1, isSuccess: true},
+		{name: "threshold not met", verifiers: []Verifier{ecdsaVerifier, otherVerifier}, threshold: 2, accepted: 1},
+		{name: "unknown key", verifiers: []Verifier{otherVerifier}, threshold: 1},
+		{name: "more keys required than available", verifiers: []Verifier{ecdsaVerifier}, threshold: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accepted, err := envelope.Verify(tc.verifiers, tc.threshold)
+			if (err == nil) != tc.isSuccess {
+				t.Fatalf("expected success %v, got error %v", tc.isSuccess, err)
+			}
+			if len(accepted) != tc.accepted {
+				t.Fatalf("expected %d accepted keys, got %d", tc.accepted, len(accepted))
+			}
+		})
+	}
+
+	tampered := *envelope
+	tampered.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type":"tampered"}`))
+	if _, err := tampered.Verify([]Verifier{ecdsaVerifier}, 1); err == nil {
+		t.Fatalf("expected tampered payload to fail verification")
+	}
+
+	unsigned := Envelope{PayloadType: PayloadTypeInToto, Payload: envelope.Payload}
+	if _, err := unsigned.Verify([]Verifier{ecdsaVerifier}, 1); !errors.Is(err, ErrNoSignatures) {
+		t.Fatalf("expected ErrNoSignatures, got %v", err)
+	}
+}
+
+func TestNewVerifierFromPEM(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	verifier, err := NewVerifierFromPEM("p384", pemBytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	message := []byte("message")
+	digest := sha512.Sum384(message)
+	sig, _ := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err := verifier.Verify(message, sig); err != nil {
+		t.Fatalf("expected P-384 signature to verify: %v", err)
+	}
+
+	if _, err := NewVerifierFromPEM("invalid", []byte("invalid")); err == nil {
+		t.Fatalf("expected error for invalid PEM")
+	}
+	if _, err := NewVerifier("invalid", "key"); err == nil {
+		t.Fatalf("expected error for unsupported key type")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsse
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Verifier verifies a signature over a message with a single key.
+type Verifier interface {
+	// KeyID returns the identifier of the key, may be empty.
+	KeyID() string
+	// Verify returns an error if sig is not a valid signature of message.
+	Verify(message, sig []byte) error
+}
+
+// AcceptedKey is a key that produced a valid signature of an envelope.
+type AcceptedKey struct {
+	KeyID     string
+	Signature Signature
+}
+
+type publicKeyVerifier struct {
+	keyID     string
+	publicKey crypto.PublicKey
+}
+
+// NewVerifier returns a Verifier for an ECDSA, RSA or ed25519 public key.
+// ECDSA signatures are expected in ASN.1 DER form hashed with the digest
+// matching the curve size, RSA signatures may use either PKCS #1 v1.5 or PSS
+// with SHA-256.
+func NewVerifier(keyID string, publicKey crypto.PublicKey) (Verifier, error) {
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return &publicKeyVerifier{keyID: keyID, publicKey: publicKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// NewVerifierFromPEM returns a Verifier for a PEM encoded PKIX public key.
+func NewVerifierFromPEM(keyID string, pemBytes []byte) (Verifier, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return NewVerifier(keyID, publicKey)
+}
+
+func (v *publicKeyVerifier) KeyID() string {
+	return v.keyID
+}
+
+func (v *publicKeyVerifier) Verify(message, sig []byte) error {
+	switch publicKey := v.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch publicKey.Curve.Params().BitSize {
+		case 384:
+			sum := sha512.Sum384(message)
+			digest = sum[:]
+		case 521:
+			sum := sha512.Sum512(message)
+			digest = sum[:]
+		default:
+			sum := sha256.Sum256(message)
+			digest = sum[:]
+		}
+		if !ecdsa.VerifyASN1(publicKey, digest, sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(message)
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, sum[:], sig); err == nil {
+			return nil
+		}
+		if err := rsa.VerifyPSS(publicKey, crypto.SHA256, sum[:], sig, nil); err != nil {
+			return errors.New("invalid RSA signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(publicKey, message, sig) {
+			return errors.New("invalid ed25519 signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported public key type %T", v.publicKey)
+}
+
+// Verify checks the envelope signatures against the verifiers and returns the
+// keys that produced a valid signature. Each verifier is counted at most once,
+// and verification fails unless at least threshold distinct verifiers accepted
+// a signature. A threshold below 1 is treated as 1.
+// When a signature carries a key ID, only verifiers with the same key ID or
+// without a key ID are tried.
+func (e *Envelope) Verify(verifiers []Verifier, threshold int) ([]AcceptedKey, error) {
+	if len(e.Signatures) == 0 {
+		return nil, ErrNoSignatures
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	if len(verifiers) < threshold {
+		return nil, fmt.Errorf("threshold of %d signatures cannot be met with %d keys", threshold, len(verifiers))
+	}
+
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+	message := PAE(e.PayloadType, payload)
+
+	var accepted []AcceptedKey
+	used := make([]bool, len(verifiers))
+	var verifyErrs []error
+	for _, signature := range e.Signatures {
+		sig, err := decodeBase64(signature.Sig)
+		if err != nil {
+			verifyErrs = append(verifyErrs, fmt.Errorf("failed to decode signature: %w", err))
+			continue
+		}
+		for i, verifier := range verifiers {
+			if used[i] || (signature.KeyID != "" && verifier.KeyID() != "" && signature.KeyID != verifier.KeyID()) {
+				continue
+			}
+			if err := verifier.Verify(message, sig); err != nil {
+				verifyErrs = append(verifyErrs, err)
+				continue
+			}
+			used[i] = true
+			accepted = append(accepted, AcceptedKey{KeyID: verifier.KeyID(), Signature: signature})
+			break
+		}
+	}
+
+	if len(accepted) < threshold {
+		err := fmt.Errorf("accepted signatures %d do not meet threshold %d", len(accepted), threshold)
+		if len(verifyErrs) > 0 {
+			err = fmt.Errorf("%w: %w", err, errors.Join(verifyErrs...))
+		}
+		return accepted, err
+	}
+	return accepted, nil
+}
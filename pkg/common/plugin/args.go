@@ -16,10 +16,17 @@ limitations under the License.
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	"github.com/deislabs/ratify/internal/logger"
 )
 
+// TraceIDEnvKey is set to the trace ID of the request a plugin is invoked for,
+// so that the logs of the plugin can be correlated with the logs of Ratify.
+const TraceIDEnvKey = "RATIFY_TRACE_ID"
+
 type PluginArgs interface { //nolint:revive // ignore linter to have unique type name
 	AsEnviron() []string
 }
@@ -75,3 +82,14 @@ func ParseInputArgs(args string) ([][2]string, error) {
 	}
 	return pluginArgs, nil
 }
+
+// withTraceID returns a copy of the environment variables with the trace ID of
+// the request in the context, if any.
+func withTraceID(ctx context.Context, environ []string) []string {
+	traceID := logger.GetTraceID(ctx)
+	if traceID == "" {
+		return environ
+	}
+	env := append(append([]string(nil), environ...), fmt.Sprintf("%s=%s", TraceIDEnvKey, traceID))
+	return MergeDuplicateEnviron(env)
+}
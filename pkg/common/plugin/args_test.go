@@ -15,7 +15,12 @@ limitations under the License.
 
 package plugin
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/deislabs/ratify/internal/logger"
+)
 
 func TestConcat_ReturnsExpected(t *testing.T) {
 	testcases := []struct {
@@ -157,3 +162,25 @@ func TestMergeDuplicateEnviron_ReturnsExpected(t *testing.T) {
 		}
 	}
 }
+
+func TestWithTraceID(t *testing.T) {
+	environ := []string{"RATIFY_VERIFIER_COMMAND=VERIFY"}
+	if env := withTraceID(context.Background(), environ); len(env) != 1 {
+		t.Fatalf("expected environment without trace ID, got %v", env)
+	}
+
+	ctx := context.WithValue(context.Background(), logger.ContextKeyTraceID, "trace")
+	env := withTraceID(ctx, environ)
+	found := false
+	for _, e := range env {
+		if e == TraceIDEnvKey+"=trace" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s in environment %v", TraceIDEnvKey, env)
+	}
+	if len(environ) != 1 {
+		t.Fatalf("expected environment of the caller to be unchanged, got %v", environ)
+	}
+}
@@ -16,27 +16,24 @@ limitations under the License.
 package plugin
 
 import (
-	"context"
 	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"time"
 
-	"github.com/deislabs/ratify/internal/version"
 	"github.com/deislabs/ratify/pkg/common/oras/authprovider"
-	commonutils "github.com/deislabs/ratify/pkg/common/utils"
-	"github.com/deislabs/ratify/pkg/ocispecs"
-	oci "github.com/opencontainers/image-spec/specs-go/v1"
-	"github.com/sirupsen/logrus"
-	"oras.land/oras-go/v2/registry/remote"
-	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
 type PluginSource struct { //nolint:revive // ignore linter to have unique type name
 	Artifact     string                          `json:"artifact"`
 	AuthProvider authprovider.AuthProviderConfig `json:"authProvider,omitempty"`
+	// Verification configures the signature verification of the plugin artifact, optional
+	Verification *PluginVerification `json:"verification,omitempty"`
+}
+
+// PluginVerification configures the notation signature verification of a plugin artifact
+type PluginVerification struct { //nolint:revive // ignore linter to have unique type name
+	// Certificates are paths to PEM encoded certificates of the CAs or the self-signed certificates trusted to sign plugins
+	Certificates []string `json:"certificates"`
+	// TrustedIdentities are the trusted signing identities in the notation trust policy format, e.g. "x509.subject: CN=ratify", defaults to any identity
+	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
 }
 
 func ParsePluginSource(source interface{}) (PluginSource, error) {
@@ -55,103 +52,3 @@ func ParsePluginSource(source interface{}) (PluginSource, error) {
 
 	return pluginSource, nil
 }
-
-func DownloadPlugin(source PluginSource, targetPath string) error {
-	ctx := context.TODO()
-
-	// initialize a repository
-	repository, err := remote.NewRepository(source.Artifact)
-	if err != nil {
-		return err
-	}
-
-	repository.Client = &auth.Client{
-		Client: &http.Client{Timeout: 10 * time.Second, Transport: http.DefaultTransport.(*http.Transport).Clone()},
-		Header: http.Header{
-			"User-Agent": {version.UserAgent},
-		},
-		Cache: auth.NewCache(),
-		Credential: func(ctx context.Context, registry string) (auth.Credential, error) {
-			authProvider, err := authprovider.CreateAuthProviderFromConfig(source.AuthProvider)
-			if err != nil {
-				return auth.EmptyCredential, err
-			}
-
-			authConfig, err := authProvider.Provide(ctx, registry)
-			if err != nil {
-				return auth.EmptyCredential, err
-			}
-
-			if authConfig.Username != "" || authConfig.Password != "" || authConfig.IdentityToken != "" {
-				return auth.Credential{
-					Username:     authConfig.Username,
-					Password:     authConfig.Password,
-					RefreshToken: authConfig.IdentityToken,
-				}, nil
-			}
-			return auth.EmptyCredential, nil
-		},
-	}
-
-	// read the reference manifest
-	referenceManifestDescriptor, err := repository.Resolve(ctx, source.Artifact)
-	if err != nil {
-		return err
-	}
-	logrus.Debugf("Resolved plugin manifest: %v", referenceManifestDescriptor)
-
-	manifestReader, err := repository.Fetch(ctx, referenceManifestDescriptor)
-	if err != nil {
-		return err
-	}
-
-	manifestBytes, err := io.ReadAll(manifestReader)
-	if err != nil {
-		return err
-	}
-
-	referenceManifest := ocispecs.ReferenceManifest{}
-	// marshal manifest bytes into reference manifest descriptor
-	if referenceManifestDescriptor.MediaType == oci.MediaTypeImageManifest {
-		var imageManifest oci.Manifest
-		if err := json.Unmarshal(manifestBytes, &imageManifest); err != nil {
-			return err
-		}
-		referenceManifest = commonutils.OciManifestToReferenceManifest(imageManifest)
-	} else if referenceManifestDescriptor.MediaType == ocispecs.MediaTypeArtifactManifest {
-		if err := json.Unmarshal(manifestBytes, &referenceManifest); err != nil {
-			return err
-		}
-	} else {
-		return fmt.Errorf("unsupported manifest media type: %s", referenceManifestDescriptor.MediaType)
-	}
-
-	// download the first blob to the target path
-	blobReference := fmt.Sprintf("%s@%s", source, referenceManifest.Blobs[0].Digest)
-	logrus.Debugf("Downloading blob %s", blobReference)
-	_, blobReader, err := repository.Blobs().FetchReference(ctx, blobReference)
-	if err != nil {
-		return err
-	}
-
-	logrus.Debugf("writing plugin bytes to %s", targetPath)
-	pluginFile, err := os.Create(targetPath)
-	if err != nil {
-		return err
-	}
-
-	defer pluginFile.Close()
-	_, err = io.Copy(pluginFile, blobReader)
-	if err != nil {
-		return err
-	}
-
-	// mark the plugin as executable
-	logrus.Debugf("marking %s as executable", targetPath)
-	err = os.Chmod(targetPath, 0700)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
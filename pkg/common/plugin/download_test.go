@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/deislabs/ratify/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestParsePluginSource_HandlesJSON(t *testing.T) {
@@ -73,3 +74,24 @@ func TestParsePluginSource_HandlesCRD(t *testing.T) {
 		t.Fatalf("unexpected artifact: %s", source.Artifact)
 	}
 }
+
+func TestParsePluginSource_HandlesCRDVerification(t *testing.T) {
+	source, err := ParsePluginSource(&v1beta1.PluginSource{
+		Artifact:     "wabbitnetworks.azurecr.io/test/sample-plugin:v1",
+		Verification: runtime.RawExtension{Raw: []byte(`{"certificates":["/certs/signing.crt"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("failed to parse plugin source: %v", err)
+	}
+	if source.Verification == nil || len(source.Verification.Certificates) != 1 || source.Verification.Certificates[0] != "/certs/signing.crt" {
+		t.Fatalf("unexpected verification: %+v", source.Verification)
+	}
+
+	source, err = ParsePluginSource(&v1beta1.PluginSource{Artifact: "wabbitnetworks.azurecr.io/test/sample-plugin:v1"})
+	if err != nil {
+		t.Fatalf("failed to parse plugin source: %v", err)
+	}
+	if source.Verification != nil {
+		t.Fatalf("expected no verification, got %+v", source.Verification)
+	}
+}
@@ -21,9 +21,12 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -32,6 +35,8 @@ const (
 	waitDuration  = time.Second
 )
 
+var logOpt = logger.Option{ComponentType: logger.Plugin}
+
 // Executor is an interface that defines methods to lookup a plugin and execute it.
 type Executor interface {
 	// ExecutePlugin executes the plugin with the given parameters
@@ -40,6 +45,13 @@ type Executor interface {
 	FindInPaths(plugin string, paths []string) (string, error)
 }
 
+// Warmer is implemented by executors that keep plugins running across
+// invocations.
+type Warmer interface {
+	// Warm starts the plugin so that its first invocation does not wait for it
+	Warm(pluginPath string)
+}
+
 // DefaultExecutor finds the plugin executable and invokes it as a os command
 type DefaultExecutor struct {
 	Stderr io.Writer
@@ -47,29 +59,45 @@ type DefaultExecutor struct {
 
 // return the command output and the error
 func (e *DefaultExecutor) ExecutePlugin(ctx context.Context, pluginPath string, cmdArgs []string, stdinData []byte, environ []string) ([]byte, error) {
+	ctx, span := tracing.StartSpan(ctx, "plugin.Execute", tracing.PluginKey.String(filepath.Base(pluginPath)))
+	output, err := e.execute(ctx, pluginPath, cmdArgs, stdinData, environ)
+	tracing.EndSpan(span, err)
+	return output, err
+}
+
+func (e *DefaultExecutor) execute(ctx context.Context, pluginPath string, cmdArgs []string, stdinData []byte, environ []string) ([]byte, error) {
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	c := exec.CommandContext(ctx, pluginPath, cmdArgs...)
-	c.Env = environ
+	c.Env = withTraceID(ctx, environ)
 	c.Stdin = bytes.NewBuffer(stdinData)
 	c.Stdout = stdout
 	c.Stderr = stderr
 
 	// DEBUG: log the process details used to launch the binary plugin
 	if logrus.IsLevelEnabled(logrus.DebugLevel) {
-		logrus.Debugf("launching plugin %s", pluginPath)
+		log := logger.GetLogger(ctx, logOpt)
+		log.Debugf("launching plugin %s", pluginPath)
 
 		pluginEnv := make([]string, 3)
-		for _, env := range environ {
+		for _, env := range c.Env {
 			// plugins inherit all env vars, but we're interested in the RATIFY_* ones. This also helps to keep secret values out of the logs.
 			if strings.HasPrefix(env, "RATIFY_") {
 				pluginEnv = append(pluginEnv, env)
 			}
 		}
-		logrus.Debugf("env vars: %v", pluginEnv)
-		logrus.Debugf("args: %v", cmdArgs)
-		logrus.Debugf("stdin: %s", stdinData)
+		log.Debugf("env vars: %v", pluginEnv)
+		log.Debugf("args: %v", cmdArgs)
+		log.Debugf("stdin: %s", stdinData)
+	}
+
+	// wait for a process slot so that bursts of requests do not fork an
+	// unbounded number of plugin processes
+	release, err := currentPool().acquire(ctx, filepath.Base(pluginPath))
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	// Retry the command on "text file busy" errors
 	for i := 0; i <= maxRetryCount; i++ {
@@ -83,7 +111,7 @@ func (e *DefaultExecutor) ExecutePlugin(ctx context.Context, pluginPath string,
 		// If the plugin is about to be completed, then we wait a
 		// second and try it again
 		if strings.Contains(err.Error(), "text file busy") {
-			logrus.Debugf("command returned text file busy, retrying after %v", waitDuration)
+			logger.GetLogger(ctx, logOpt).Debugf("command returned text file busy, retrying after %v", waitDuration)
 			time.Sleep(waitDuration)
 			continue
 		}
@@ -0,0 +1,163 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// Long running plugins serve the commands of the exec model over gRPC. Ratify
+// starts the plugin once with GRPCEnvKey set, the plugin starts a gRPC server
+// and announces its address with a handshake line on stdout:
+//
+//	<protocol version>|grpc|<network>|<address>
+//
+// Each command is then sent as an Execute call carrying the environment and
+// stdin the plugin would have been executed with. The plugin exits when its
+// stdin is closed, so it does not outlive Ratify.
+const (
+	// GRPCEnvKey is set to GRPCEnvValue when a plugin is started as a gRPC server
+	GRPCEnvKey = "RATIFY_PLUGIN_GRPC"
+	// GRPCEnvValue guards against starting plugins as a server by accident
+	GRPCEnvValue = "b7c1d6d2-5c4f-4a43-9a3d-8f9f39f3d0a1"
+	// GRPCProtocolVersion is the version of the handshake and the service
+	GRPCProtocolVersion = "1"
+
+	grpcServiceName   = "ratify.plugin.v1.Plugin"
+	grpcExecuteMethod = "Execute"
+)
+
+// ExecuteRequest is a plugin command sent over gRPC
+type ExecuteRequest struct {
+	Environ []string `json:"environ"`
+	Stdin   []byte   `json:"stdin"`
+}
+
+// ExecuteResponse is the output of a plugin command sent over gRPC
+type ExecuteResponse struct {
+	Stdout []byte `json:"stdout,omitempty"`
+	Error  *Error `json:"error,omitempty"`
+}
+
+// ExecuteFunc runs a plugin command for the given environment and stdin and
+// returns what the plugin would have written to stdout
+type ExecuteFunc func(ctx context.Context, getEnviron func(string) string, stdin []byte) ([]byte, *Error)
+
+// IsGRPCServerRequested returns true if the plugin was started as a gRPC server
+func IsGRPCServerRequested(getEnviron func(string) string) bool {
+	return getEnviron(GRPCEnvKey) == GRPCEnvValue
+}
+
+// ServeGRPC serves plugin commands over gRPC until stdin is closed.
+func ServeGRPC(execute ExecuteFunc, stdin io.Reader, stdout io.Writer) error {
+	listener, cleanup, err := listen()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	server := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: grpcServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: grpcExecuteMethod,
+				Handler:    executeHandler,
+			},
+		},
+		Streams: []grpc.StreamDesc{},
+	}, execute)
+
+	go func() {
+		// the host holds stdin open for as long as it uses the plugin
+		_, _ = io.Copy(io.Discard, stdin)
+		server.Stop()
+	}()
+
+	if _, err := fmt.Fprintf(stdout, "%s|grpc|%s|%s\n", GRPCProtocolVersion, listener.Addr().Network(), listener.Addr().String()); err != nil {
+		return fmt.Errorf("failed to write handshake: %w", err)
+	}
+	return server.Serve(listener)
+}
+
+func executeHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &ExecuteRequest{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	stdout, pluginErr := srv.(ExecuteFunc)(ctx, environLookup(request.Environ), request.Stdin)
+	return &ExecuteResponse{Stdout: stdout, Error: pluginErr}, nil
+}
+
+func listen() (net.Listener, func(), error) {
+	if grpcNetwork == "unix" {
+		dir, err := os.MkdirTemp("", "ratify-plugin-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create socket directory: %w", err)
+		}
+		listener, err := net.Listen("unix", filepath.Join(dir, "plugin.sock"))
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, nil, fmt.Errorf("failed to listen on socket: %w", err)
+		}
+		return listener, func() { os.RemoveAll(dir) }, nil
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	return listener, func() {}, nil
+}
+
+// environLookup returns a lookup function over the environment of a request,
+// later entries take precedence as they do for os/exec
+func environLookup(environ []string) func(string) string {
+	values := make(map[string]string, len(environ))
+	for _, env := range environ {
+		if key, value, ok := strings.Cut(env, "="); ok {
+			values[key] = value
+		}
+	}
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+// jsonCodec encodes gRPC messages as JSON so that plugins do not need
+// generated protobuf code
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
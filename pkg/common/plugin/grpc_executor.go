@@ -0,0 +1,253 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/featureflag"
+	"github.com/deislabs/ratify/pkg/tracing"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const handshakeTimeout = 10 * time.Second
+
+// GRPCExecutor runs plugins as long running gRPC servers and reuses them
+// across invocations. Plugins that do not support gRPC are executed with the
+// fallback executor.
+type GRPCExecutor struct {
+	Stderr   io.Writer
+	fallback Executor
+
+	mu      sync.Mutex
+	clients map[string]*grpcPluginClient
+	legacy  map[string]struct{}
+}
+
+type grpcPluginClient struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	conn  *grpc.ClientConn
+}
+
+var _ Executor = &GRPCExecutor{}
+var _ Warmer = &GRPCExecutor{}
+
+var sharedGRPCExecutor = NewGRPCExecutor(os.Stderr)
+
+// NewExecutor returns the executor used to invoke external plugins. Plugins
+// are reused over gRPC when the gRPC plugins feature flag is enabled.
+func NewExecutor() Executor {
+	if featureflag.GRPCPlugins.Enabled {
+		return sharedGRPCExecutor
+	}
+	return &DefaultExecutor{Stderr: os.Stderr}
+}
+
+// NewGRPCExecutor creates an executor that falls back to executing plugins as
+// os commands.
+func NewGRPCExecutor(stderr io.Writer) *GRPCExecutor {
+	return &GRPCExecutor{
+		Stderr:   stderr,
+		fallback: &DefaultExecutor{Stderr: stderr},
+		clients:  map[string]*grpcPluginClient{},
+		legacy:   map[string]struct{}{},
+	}
+}
+
+// ExecutePlugin sends the command to the running plugin, starting it first if
+// needed. cmdArgs are only supported by the fallback executor.
+func (e *GRPCExecutor) ExecutePlugin(ctx context.Context, pluginPath string, cmdArgs []string, stdinData []byte, environ []string) ([]byte, error) {
+	if len(cmdArgs) > 0 {
+		return e.fallback.ExecutePlugin(ctx, pluginPath, cmdArgs, stdinData, environ)
+	}
+	client, ok := e.client(pluginPath)
+	if !ok {
+		return e.fallback.ExecutePlugin(ctx, pluginPath, cmdArgs, stdinData, environ)
+	}
+
+	response := &ExecuteResponse{}
+	spanCtx, span := tracing.StartSpan(ctx, "plugin.Invoke", tracing.PluginKey.String(filepath.Base(pluginPath)))
+	err := client.conn.Invoke(spanCtx, fmt.Sprintf("/%s/%s", grpcServiceName, grpcExecuteMethod), &ExecuteRequest{Environ: withTraceID(ctx, environ), Stdin: stdinData}, response, grpc.ForceCodec(jsonCodec{}))
+	if err == nil && response.Error != nil {
+		tracing.EndSpan(span, response.Error)
+	} else {
+		tracing.EndSpan(span, err)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// the plugin may have crashed, restart it on the next invocation
+		logger.GetLogger(ctx, logOpt).Warnf("gRPC call to plugin %s failed, falling back to exec: %v", pluginPath, err)
+		e.remove(pluginPath, client)
+		return e.fallback.ExecutePlugin(ctx, pluginPath, cmdArgs, stdinData, environ)
+	}
+	if response.Error != nil {
+		return nil, &Error{Msg: fmt.Sprintf("plugin failed with error: '%v'", response.Error.Error())}
+	}
+	return response.Stdout, nil
+}
+
+// Warm starts the plugin and completes its handshake ahead of its first
+// invocation.
+func (e *GRPCExecutor) Warm(pluginPath string) {
+	e.client(pluginPath)
+}
+
+func (e *GRPCExecutor) FindInPaths(plugin string, paths []string) (string, error) {
+	return FindInPaths(plugin, paths)
+}
+
+// Close stops all running plugins.
+func (e *GRPCExecutor) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for pluginPath, client := range e.clients {
+		client.close()
+		delete(e.clients, pluginPath)
+	}
+}
+
+// client returns the client of a running plugin, it returns false if the
+// plugin does not support gRPC.
+func (e *GRPCExecutor) client(pluginPath string) (*grpcPluginClient, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if client, ok := e.clients[pluginPath]; ok {
+		return client, true
+	}
+	if _, ok := e.legacy[pluginPath]; ok {
+		return nil, false
+	}
+
+	client, err := e.start(pluginPath)
+	if err != nil {
+		logrus.Infof("plugin %s does not support gRPC, using exec: %v", pluginPath, err)
+		e.legacy[pluginPath] = struct{}{}
+		return nil, false
+	}
+	logrus.Debugf("started gRPC plugin %s", pluginPath)
+	e.clients[pluginPath] = client
+	return client, true
+}
+
+func (e *GRPCExecutor) remove(pluginPath string, client *grpcPluginClient) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.clients[pluginPath] == client {
+		delete(e.clients, pluginPath)
+	}
+	client.close()
+}
+
+// start launches the plugin as a gRPC server and connects to the address
+// announced in its handshake.
+func (e *GRPCExecutor) start(pluginPath string) (*grpcPluginClient, error) {
+	cmd := exec.Command(pluginPath)
+	cmd.Env = MergeDuplicateEnviron(append(os.Environ(), fmt.Sprintf("%s=%s", GRPCEnvKey, GRPCEnvValue)))
+	cmd.Stderr = e.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	client := &grpcPluginClient{cmd: cmd, stdin: stdin}
+
+	handshake := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdout).ReadString('\n')
+		handshake <- line
+		// drain the output of the plugin so it never blocks on writing
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+
+	var line string
+	select {
+	case line = <-handshake:
+	case <-time.After(handshakeTimeout):
+		client.close()
+		return nil, fmt.Errorf("timed out waiting for handshake")
+	}
+
+	target, err := parseHandshake(line)
+	if err != nil {
+		client.close()
+		return nil, err
+	}
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		client.close()
+		return nil, fmt.Errorf("failed to connect to plugin: %w", err)
+	}
+	client.conn = conn
+	return client, nil
+}
+
+func (c *grpcPluginClient) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	// closing stdin asks the plugin to exit
+	_ = c.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		_ = c.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = c.cmd.Process.Kill()
+	}
+}
+
+// parseHandshake returns the dial target announced by the plugin
+func parseHandshake(line string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 || parts[1] != "grpc" {
+		return "", fmt.Errorf("invalid handshake %q", strings.TrimSpace(line))
+	}
+	if parts[0] != GRPCProtocolVersion {
+		return "", fmt.Errorf("unsupported protocol version %s", parts[0])
+	}
+	switch parts[2] {
+	case "unix":
+		return "unix://" + parts[3], nil
+	case "tcp":
+		return parts[3], nil
+	default:
+		return "", fmt.Errorf("unsupported network %s", parts[2])
+	}
+}
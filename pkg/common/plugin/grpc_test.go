@@ -0,0 +1,145 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testCommandEnvKey = "RATIFY_TEST_COMMAND"
+
+// TestMain serves the test binary as a gRPC plugin when started by the executor
+func TestMain(m *testing.M) {
+	if IsGRPCServerRequested(os.Getenv) {
+		err := ServeGRPC(func(_ context.Context, getEnviron func(string) string, stdin []byte) ([]byte, *Error) {
+			if getEnviron(testCommandEnvKey) == "FAIL" {
+				return nil, NewError(1, "command failed", "")
+			}
+			return []byte(fmt.Sprintf("%s:%d:%s", getEnviron(testCommandEnvKey), os.Getpid(), stdin)), nil
+		}, os.Stdin, os.Stdout)
+		if err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestGRPCExecutor_ReusesPlugin(t *testing.T) {
+	executor := NewGRPCExecutor(os.Stderr)
+	defer executor.Close()
+	pluginPath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to find test binary: %v", err)
+	}
+
+	var pids []string
+	for _, command := range []string{"VERIFY", "GETBLOB"} {
+		stdout, err := executor.ExecutePlugin(context.Background(), pluginPath, nil, []byte("input"), []string{testCommandEnvKey + "=" + command})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parts := strings.Split(string(stdout), ":")
+		if len(parts) != 3 || parts[0] != command || parts[2] != "input" {
+			t.Fatalf("unexpected output %s", stdout)
+		}
+		pids = append(pids, parts[1])
+	}
+	if pids[0] != pids[1] || pids[0] == fmt.Sprint(os.Getpid()) {
+		t.Fatalf("expected commands to be served by the same plugin process, got %v", pids)
+	}
+
+	_, err = executor.ExecutePlugin(context.Background(), pluginPath, nil, nil, []string{testCommandEnvKey + "=FAIL"})
+	if err == nil || !strings.Contains(err.Error(), "command failed") {
+		t.Fatalf("expected plugin error, got %v", err)
+	}
+}
+
+func TestGRPCExecutor_Warm(t *testing.T) {
+	executor := NewGRPCExecutor(os.Stderr)
+	defer executor.Close()
+	pluginPath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to find test binary: %v", err)
+	}
+
+	executor.Warm(pluginPath)
+	executor.mu.Lock()
+	client, ok := executor.clients[pluginPath]
+	executor.mu.Unlock()
+	if !ok {
+		t.Fatalf("expected plugin to be started")
+	}
+
+	if _, err := executor.ExecutePlugin(context.Background(), pluginPath, nil, nil, []string{testCommandEnvKey + "=VERIFY"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if executor.clients[pluginPath] != client {
+		t.Fatalf("expected the warmed plugin to serve the command")
+	}
+}
+
+func TestGRPCExecutor_FallsBackToExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on windows")
+	}
+	pluginPath := filepath.Join(t.TempDir(), "legacy")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\necho legacy $RATIFY_TEST_COMMAND\n"), 0o700); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+
+	executor := NewGRPCExecutor(&bytes.Buffer{})
+	defer executor.Close()
+	for i := 0; i < 2; i++ {
+		stdout, err := executor.ExecutePlugin(context.Background(), pluginPath, nil, nil, []string{testCommandEnvKey + "=VERIFY"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.TrimSpace(string(stdout)) != "legacy VERIFY" {
+			t.Fatalf("unexpected output %s", stdout)
+		}
+	}
+	if _, ok := executor.legacy[pluginPath]; !ok {
+		t.Fatalf("expected plugin to be recorded as legacy")
+	}
+}
+
+func TestParseHandshake(t *testing.T) {
+	testCases := []struct {
+		line      string
+		target    string
+		isSuccess bool
+	}{
+		{line: "1|grpc|unix|/tmp/plugin.sock\n", target: "unix:///tmp/plugin.sock", isSuccess: true},
+		{line: "1|grpc|tcp|127.0.0.1:1234", target: "127.0.0.1:1234", isSuccess: true},
+		{line: `{"code": 4, "msg": "missing env variables"}`},
+		{line: "2|grpc|tcp|127.0.0.1:1234"},
+		{line: "1|grpc|udp|127.0.0.1:1234"},
+	}
+	for _, tc := range testCases {
+		target, err := parseHandshake(tc.line)
+		if (err == nil) != tc.isSuccess || target != tc.target {
+			t.Fatalf("handshake %q: expected target %q and success %v, got %q, err: %v", tc.line, tc.target, tc.isSuccess, target, err)
+		}
+	}
+}
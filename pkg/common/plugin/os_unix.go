@@ -18,3 +18,6 @@ package plugin
 
 // Valid file extensions for plugin executables.
 var executableFileExtensions = []string{""}
+
+// Network the gRPC plugin server listens on.
+const grpcNetwork = "unix"
@@ -17,3 +17,6 @@ package plugin
 
 // Valid file extensions for plugin executables.
 var executableFileExtensions = []string{".exe", ""}
+
+// Network the gRPC plugin server listens on.
+const grpcNetwork = "tcp"
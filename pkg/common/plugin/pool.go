@@ -0,0 +1,160 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// PoolConfig limits the number of plugin processes running at the same time.
+// Plugin invocations wait in a queue until a process slot is available.
+type PoolConfig struct {
+	// MaxProcesses is the maximum number of plugin processes across all
+	// plugins. Defaults to 4 times the number of CPUs.
+	MaxProcesses int `json:"maxProcesses,omitempty"`
+	// MaxProcessesPerPlugin is the maximum number of processes of a single
+	// plugin. Defaults to MaxProcesses.
+	MaxProcessesPerPlugin int `json:"maxProcessesPerPlugin,omitempty"`
+	// PluginLimits overrides MaxProcessesPerPlugin for the plugins of the
+	// given names.
+	PluginLimits map[string]int `json:"pluginLimits,omitempty"`
+	// MaxQueueLength is the maximum number of invocations waiting for a
+	// process slot, further invocations fail. 0 means the queue is unbounded
+	// and invocations wait until their request times out.
+	MaxQueueLength int `json:"maxQueueLength,omitempty"`
+}
+
+// processPool hands out process slots to plugin invocations.
+type processPool struct {
+	config  PoolConfig
+	slots   chan struct{}
+	mu      sync.Mutex
+	plugins map[string]chan struct{}
+	queued  int
+}
+
+var (
+	poolMu sync.Mutex
+	pool   = newProcessPool(PoolConfig{})
+)
+
+// ConfigurePool applies the process limits to plugins executed from now on.
+// Processes that are already running keep counting towards the previous
+// limits until they exit.
+func ConfigurePool(config PoolConfig) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if reflect.DeepEqual(pool.config, config) {
+		return
+	}
+	pool = newProcessPool(config)
+	logrus.Infof("plugin process pool configured with at most %d processes", cap(pool.slots))
+}
+
+func currentPool() *processPool {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	return pool
+}
+
+func newProcessPool(config PoolConfig) *processPool {
+	maxProcesses := config.MaxProcesses
+	if maxProcesses <= 0 {
+		maxProcesses = 4 * runtime.NumCPU()
+	}
+	return &processPool{
+		config:  config,
+		slots:   make(chan struct{}, maxProcesses),
+		plugins: map[string]chan struct{}{},
+	}
+}
+
+// acquire waits for a process slot of the plugin and returns the function
+// releasing it.
+func (p *processPool) acquire(ctx context.Context, plugin string) (func(), error) {
+	pluginSlots := p.pluginSlots(plugin)
+
+	p.mu.Lock()
+	if p.config.MaxQueueLength > 0 && p.queued >= p.config.MaxQueueLength {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("plugin %s cannot be executed, %d plugin invocations are already queued", plugin, p.queued)
+	}
+	p.queued++
+	p.mu.Unlock()
+	metrics.ReportPluginQueueDepth(ctx, 1, plugin)
+
+	start := time.Now()
+	err := p.wait(ctx, pluginSlots)
+
+	p.mu.Lock()
+	p.queued--
+	p.mu.Unlock()
+	metrics.ReportPluginQueueDepth(ctx, -1, plugin)
+	metrics.ReportPluginQueueWait(ctx, time.Since(start).Milliseconds(), plugin)
+	if err != nil {
+		return nil, fmt.Errorf("timed out waiting to execute plugin %s: %w", plugin, err)
+	}
+
+	return func() {
+		<-p.slots
+		<-pluginSlots
+	}, nil
+}
+
+// wait takes a slot of the plugin and then a slot of the pool. The plugin slot
+// is taken first so that invocations of a saturated plugin do not hold pool
+// slots needed by other plugins.
+func (p *processPool) wait(ctx context.Context, pluginSlots chan struct{}) error {
+	select {
+	case pluginSlots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		<-pluginSlots
+		return ctx.Err()
+	}
+}
+
+func (p *processPool) pluginSlots(plugin string) chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if slots, ok := p.plugins[plugin]; ok {
+		return slots
+	}
+
+	limit := p.config.PluginLimits[plugin]
+	if limit <= 0 {
+		limit = p.config.MaxProcessesPerPlugin
+	}
+	if limit <= 0 || limit > cap(p.slots) {
+		limit = cap(p.slots)
+	}
+	slots := make(chan struct{}, limit)
+	p.plugins[plugin] = slots
+	return slots
+}
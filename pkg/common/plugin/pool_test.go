@@ -0,0 +1,146 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestProcessPool_Limits(t *testing.T) {
+	p := newProcessPool(PoolConfig{
+		MaxProcesses:          3,
+		MaxProcessesPerPlugin: 2,
+		PluginLimits:          map[string]int{"sbom": 1},
+	})
+
+	// acquireNow returns the release function if a slot is immediately available
+	acquireNow := func(plugin string) func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		release, err := p.acquire(ctx, plugin)
+		if err != nil {
+			return nil
+		}
+		return release
+	}
+
+	releaseSbom := acquireNow("sbom")
+	if releaseSbom == nil {
+		t.Fatalf("expected slot for sbom")
+	}
+	if acquireNow("sbom") != nil {
+		t.Fatalf("expected sbom to be limited to 1 process")
+	}
+
+	releaseSample1 := acquireNow("sample")
+	releaseSample2 := acquireNow("sample")
+	if releaseSample1 == nil || releaseSample2 == nil {
+		t.Fatalf("expected 2 slots for sample")
+	}
+	if acquireNow("other") != nil {
+		t.Fatalf("expected pool to be limited to 3 processes")
+	}
+
+	releaseSbom()
+	releaseOther := acquireNow("other")
+	if releaseOther == nil {
+		t.Fatalf("expected slot after release")
+	}
+	releaseOther()
+	releaseSample1()
+	releaseSample2()
+	if p.queued != 0 || len(p.slots) != 0 {
+		t.Fatalf("expected all slots to be released, got %d queued and %d in use", p.queued, len(p.slots))
+	}
+}
+
+func TestProcessPool_Queue(t *testing.T) {
+	p := newProcessPool(PoolConfig{MaxProcesses: 1, MaxQueueLength: 1})
+	release, err := p.acquire(context.Background(), "sample")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		queuedRelease, err := p.acquire(context.Background(), "sample")
+		if err == nil {
+			queuedRelease()
+		}
+		acquired <- err
+	}()
+	for {
+		p.mu.Lock()
+		queued := p.queued
+		p.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := p.acquire(context.Background(), "sample"); err == nil {
+		t.Fatalf("expected error when the queue is full")
+	}
+
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("expected queued invocation to acquire the released slot, got %v", err)
+	}
+}
+
+func TestProcessPool_Timeout(t *testing.T) {
+	p := newProcessPool(PoolConfig{MaxProcesses: 1})
+	release, err := p.acquire(context.Background(), "sample")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquire(ctx, "other"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(p.plugins["other"]) != 0 {
+		t.Fatalf("expected plugin slot to be returned on timeout")
+	}
+}
+
+func TestConfigurePool(t *testing.T) {
+	original := currentPool()
+	defer func() {
+		pool = original
+	}()
+
+	ConfigurePool(PoolConfig{})
+	if cap(currentPool().slots) != 4*runtime.NumCPU() {
+		t.Fatalf("expected default of %d processes, got %d", 4*runtime.NumCPU(), cap(currentPool().slots))
+	}
+	ConfigurePool(PoolConfig{MaxProcesses: 2})
+	configured := currentPool()
+	if cap(configured.slots) != 2 {
+		t.Fatalf("expected 2 processes, got %d", cap(configured.slots))
+	}
+	ConfigurePool(PoolConfig{MaxProcesses: 2})
+	if currentPool() != configured {
+		t.Fatalf("expected pool to be kept if the config is unchanged")
+	}
+}
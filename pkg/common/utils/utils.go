@@ -20,17 +20,31 @@ import (
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+const (
+	// MediaTypeDockerManifest is the media type of a docker image manifest, it has the same layout as an OCI image manifest
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	// MediaTypeDockerImageConfig is the media type of the image config of a docker image manifest
+	MediaTypeDockerImageConfig = "application/vnd.docker.container.image.v1+json"
+	// MediaTypeDockerManifestList is the media type of a docker manifest list, it has the same layout as an OCI image index
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
 func OciManifestToReferenceManifest(ociManifest oci.Manifest) ocispecs.ReferenceManifest {
 	artifactType := ociManifest.Config.MediaType
 	if artifactType == oci.DescriptorEmptyJSON.MediaType {
 		artifactType = ociManifest.ArtifactType
 	}
 
-	return ocispecs.ReferenceManifest{
+	referenceManifest := ocispecs.ReferenceManifest{
 		MediaType:    ociManifest.MediaType,
 		ArtifactType: artifactType,
 		Blobs:        ociManifest.Layers,
 		Subject:      ociManifest.Subject,
 		Annotations:  ociManifest.Annotations,
 	}
+	if ociManifest.Config.MediaType == oci.MediaTypeImageConfig || ociManifest.Config.MediaType == MediaTypeDockerImageConfig {
+		config := ociManifest.Config
+		referenceManifest.Config = &config
+	}
+	return referenceManifest
 }
@@ -100,6 +100,26 @@ func TestOciManifestToReferenceManifest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "image config",
+			args: args{
+				ociManifest: oci.Manifest{
+					MediaType: "application/vnd.oci.image.manifest.v1+json",
+					Config: oci.Descriptor{
+						MediaType: oci.MediaTypeImageConfig,
+						Size:      2,
+					},
+				},
+			},
+			want: ocispecs.ReferenceManifest{
+				MediaType:    "application/vnd.oci.image.manifest.v1+json",
+				ArtifactType: oci.MediaTypeImageConfig,
+				Config: &oci.Descriptor{
+					MediaType: oci.MediaTypeImageConfig,
+					Size:      2,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
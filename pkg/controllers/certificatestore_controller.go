@@ -18,6 +18,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
 	"github.com/deislabs/ratify/pkg/certificateprovider"
@@ -45,7 +46,11 @@ var (
 	certificatesMap = map[string][]*x509.Certificate{}
 )
 
-const maxBriefErrLength = 30
+const (
+	maxBriefErrLength = 30
+	// minRefreshInterval bounds the requests of refreshes to the certificate providers
+	minRefreshInterval = time.Minute
+)
 
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=certificatestores,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=certificatestores/status,verbs=get;update;patch
@@ -94,9 +99,16 @@ func (r *CertificateStoreReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	refreshInterval, err := getRefreshInterval(certStore.Spec)
+	if err != nil {
+		writeCertStoreStatus(ctx, r, certStore, logger, isFetchSuccessful, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, err
+	}
+
 	certificates, certAttributes, err := provider.GetCertificates(ctx, attributes)
 	if err != nil {
 		writeCertStoreStatus(ctx, r, certStore, logger, isFetchSuccessful, err.Error(), lastFetchedTime, nil)
+		// previously fetched certificates are kept until a refresh succeeds
 		return ctrl.Result{}, fmt.Errorf("Error fetching certificates in store %v with %v provider, error: %w", resource, certStore.Spec.Provider, err)
 	}
 
@@ -107,8 +119,8 @@ func (r *CertificateStoreReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	logger.Infof("%v certificates fetched for certificate store %v", len(certificates), resource)
 
-	// returning empty result and no error to indicate we’ve successfully reconciled this object
-	return ctrl.Result{}, nil
+	// requeue to refresh the certificates, e.g. rotated certificates of the latest version in Key Vault
+	return ctrl.Result{RequeueAfter: refreshInterval}, nil
 }
 
 // returns the internal certificate map
@@ -143,6 +155,27 @@ func getCertStoreConfig(spec configv1beta1.CertificateStoreSpec) (map[string]str
 	return attributes, nil
 }
 
+// getRefreshInterval returns the interval to refresh the certificates, 0 if
+// certificates are only fetched when the resource changes.
+func getRefreshInterval(spec configv1beta1.CertificateStoreSpec) (time.Duration, error) {
+	return parseRefreshInterval(spec.RefreshInterval)
+}
+
+// parseRefreshInterval parses the refresh interval of a resource, 0 if empty.
+func parseRefreshInterval(refreshInterval string) (time.Duration, error) {
+	if refreshInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(refreshInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid refresh interval %s: %w", refreshInterval, err)
+	}
+	if interval < minRefreshInterval {
+		return 0, fmt.Errorf("refresh interval %s must be at least %s", refreshInterval, minRefreshInterval)
+	}
+	return interval, nil
+}
+
 func writeCertStoreStatus(ctx context.Context, r *CertificateStoreReconciler, certStore configv1beta1.CertificateStore, logger *logrus.Entry, isSuccess bool, errorString string, operationTime metav1.Time, certStatus certificateprovider.CertificatesStatus) {
 	if isSuccess {
 		updateSuccessStatus(&certStore, &operationTime, certStatus)
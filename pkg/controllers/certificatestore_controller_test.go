@@ -18,6 +18,7 @@ package controllers
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
 	"github.com/deislabs/ratify/pkg/certificateprovider"
@@ -183,3 +184,43 @@ func TestGetCertificateProvider(t *testing.T) {
 		t.Fatalf("Getting unregistered provider should returns an error")
 	}
 }
+
+func TestGetRefreshInterval(t *testing.T) {
+	testCases := []struct {
+		name           string
+		interval       string
+		expectInterval time.Duration
+		expectErr      bool
+	}{
+		{
+			name: "refresh disabled",
+		},
+		{
+			name:           "valid interval",
+			interval:       "12h",
+			expectInterval: 12 * time.Hour,
+		},
+		{
+			name:      "invalid interval",
+			interval:  "daily",
+			expectErr: true,
+		},
+		{
+			name:      "interval too short",
+			interval:  "10s",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interval, err := getRefreshInterval(configv1beta1.CertificateStoreSpec{RefreshInterval: tc.interval})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+			if interval != tc.expectInterval {
+				t.Fatalf("expected interval %s, got %s", tc.expectInterval, interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright The Ratify Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+// http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	_ "github.com/deislabs/ratify/pkg/keymanagementprovider/azurekeyvault" // register azure keyvault key management provider
+	kmpconfig "github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	_ "github.com/deislabs/ratify/pkg/keymanagementprovider/hashicorpvault" // register hashicorp vault key management provider
+	_ "github.com/deislabs/ratify/pkg/keymanagementprovider/inline"         // register inline key management provider
+	_ "github.com/deislabs/ratify/pkg/keymanagementprovider/kms"            // register kms key management providers
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// KeyManagementProviderReconciler reconciles a KeyManagementProvider object
+type KeyManagementProviderReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=keymanagementproviders,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=keymanagementproviders/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=keymanagementproviders/finalizers,verbs=update
+
+// Reconcile fetches the certificates and keys of the key management provider
+// and caches them for the verifiers, they are fetched again after the refresh
+// interval so that rotated certificates and keys are picked up.
+func (r *KeyManagementProviderReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logrus.WithContext(ctx)
+
+	var resource = req.NamespacedName.String()
+	var kmp configv1beta1.KeyManagementProvider
+
+	logger.Infof("reconciling key management provider '%v'", resource)
+
+	if err := r.Get(ctx, req.NamespacedName, &kmp); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Infof("deletion detected, removing key management provider %v", resource)
+			keymanagementprovider.DeleteResourceFromMap(resource)
+		} else {
+			logger.Error(err, "unable to fetch key management provider")
+		}
+
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	lastFetchedTime := metav1.Now()
+	kmpConfig, err := getKMPConfig(kmp.Spec)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, err
+	}
+
+	provider, err := factory.CreateKeyManagementProviderFromConfig(kmpConfig)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, err
+	}
+
+	refreshInterval, err := parseRefreshInterval(kmp.Spec.RefreshInterval)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, err
+	}
+
+	versionHistory, err := getVersionHistory(kmp.Spec)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, err
+	}
+
+	// previously fetched certificates and keys are kept until a refresh succeeds
+	certificates, certAttributes, err := provider.GetCertificates(ctx)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, fmt.Errorf("error fetching certificates in key management provider %v with %v provider, error: %w", resource, kmp.Spec.Type, err)
+	}
+	keys, keyAttributes, err := provider.GetKeys(ctx)
+	if err != nil {
+		writeKMPStatus(ctx, r, kmp, logger, false, err.Error(), lastFetchedTime, nil)
+		return ctrl.Result{}, fmt.Errorf("error fetching keys in key management provider %v with %v provider, error: %w", resource, kmp.Spec.Type, err)
+	}
+
+	keymanagementprovider.SetCertificatesInMap(ctx, resource, certificates, versionHistory)
+	keymanagementprovider.SetKeysInMap(ctx, resource, keys, versionHistory)
+	writeKMPStatus(ctx, r, kmp, logger, true, "", lastFetchedTime, mergeKMPStatus(certAttributes, keyAttributes))
+
+	logger.Infof("%v certificates and %v keys fetched for key management provider %v", len(certificates), len(keys), resource)
+
+	return ctrl.Result{RequeueAfter: refreshInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KeyManagementProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	pred := predicate.GenerationChangedPredicate{}
+
+	// status updates will trigger a reconcile event
+	// if there are no changes to spec of CRD, this event should be filtered out by using the predicate
+	// see more discussions at https://github.com/kubernetes-sigs/kubebuilder/issues/618
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&configv1beta1.KeyManagementProvider{}).WithEventFilter(pred).
+		Complete(r)
+}
+
+// getKMPConfig returns the config of the provider from its parameters and type
+func getKMPConfig(spec configv1beta1.KeyManagementProviderSpec) (kmpconfig.KeyManagementProviderConfig, error) {
+	kmpConfig := kmpconfig.KeyManagementProviderConfig{}
+
+	if string(spec.Parameters.Raw) == "" {
+		return nil, fmt.Errorf("received empty parameters")
+	}
+
+	if err := json.Unmarshal(spec.Parameters.Raw, &kmpConfig); err != nil {
+		logrus.Error(err, ",unable to decode key management provider parameters", "Parameters.Raw", spec.Parameters.Raw)
+		return nil, err
+	}
+	kmpConfig[kmpconfig.Type] = spec.Type
+
+	return kmpConfig, nil
+}
+
+// getVersionHistory returns the number of previously fetched versions of each
+// certificate and key to keep.
+func getVersionHistory(spec configv1beta1.KeyManagementProviderSpec) (int, error) {
+	if spec.VersionHistory < 0 || spec.VersionHistory > keymanagementprovider.MaxVersionHistory {
+		return 0, fmt.Errorf("version history %d must be between 0 and %d", spec.VersionHistory, keymanagementprovider.MaxVersionHistory)
+	}
+	return spec.VersionHistory, nil
+}
+
+// mergeKMPStatus merges the properties of the certificates and the keys
+func mergeKMPStatus(statuses ...keymanagementprovider.KeyManagementProviderStatus) keymanagementprovider.KeyManagementProviderStatus {
+	var merged keymanagementprovider.KeyManagementProviderStatus
+	for _, status := range statuses {
+		for key, value := range status {
+			if merged == nil {
+				merged = keymanagementprovider.KeyManagementProviderStatus{}
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func writeKMPStatus(ctx context.Context, r client.StatusClient, kmp configv1beta1.KeyManagementProvider, logger *logrus.Entry, isSuccess bool, errorString string, operationTime metav1.Time, kmpStatus keymanagementprovider.KeyManagementProviderStatus) {
+	// the error is kept in memory for the readiness checks of the server
+	keymanagementprovider.SetFetchError(client.ObjectKeyFromObject(&kmp).String(), errorString)
+	if isSuccess {
+		updateKMPSuccessStatus(&kmp, &operationTime, kmpStatus)
+	} else {
+		updateKMPErrorStatus(&kmp, errorString, &operationTime)
+	}
+	if statusErr := r.Status().Update(ctx, &kmp); statusErr != nil {
+		logger.Error(statusErr, ",unable to update key management provider status")
+	}
+}
+
+func updateKMPErrorStatus(kmp *configv1beta1.KeyManagementProvider, errorString string, operationTime *metav1.Time) {
+	// truncate brief error string to maxBriefErrLength
+	briefErr := errorString
+	if len(errorString) > maxBriefErrLength {
+		briefErr = fmt.Sprintf("%s...", errorString[:maxBriefErrLength])
+	}
+	kmp.Status.IsSuccess = false
+	kmp.Status.Error = errorString
+	kmp.Status.BriefError = briefErr
+	kmp.Status.LastFetchedTime = operationTime
+}
+
+func updateKMPSuccessStatus(kmp *configv1beta1.KeyManagementProvider, lastOperationTime *metav1.Time, kmpStatus keymanagementprovider.KeyManagementProviderStatus) {
+	kmp.Status.IsSuccess = true
+	kmp.Status.Error = ""
+	kmp.Status.BriefError = ""
+	kmp.Status.LastFetchedTime = lastOperationTime
+
+	if kmpStatus != nil {
+		jsonString, _ := json.Marshal(kmpStatus)
+
+		raw := runtime.RawExtension{
+			Raw: jsonString,
+		}
+		kmp.Status.Properties = raw
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	_ "github.com/deislabs/ratify/pkg/keymanagementprovider/inline"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetKMPConfig_ValidConfig(t *testing.T) {
+	spec := configv1beta1.KeyManagementProviderSpec{
+		Type: "inline",
+		Parameters: runtime.RawExtension{
+			Raw: []byte(`{"contentType":"key","value":"test"}`),
+		},
+	}
+
+	result, err := getKMPConfig(spec)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(result) != 3 ||
+		result["type"] != "inline" ||
+		result["contentType"] != "key" ||
+		result["value"] != "test" {
+		t.Fatalf("unexpected value %+v", result)
+	}
+}
+
+func TestGetKMPConfig_EmptyStringError(t *testing.T) {
+	spec := configv1beta1.KeyManagementProviderSpec{
+		Type: "inline",
+	}
+
+	_, err := getKMPConfig(spec)
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+
+	expectedError := "received empty parameters"
+	if err.Error() != expectedError {
+		t.Fatalf("Unexpected error, expected %+v, got %+v", expectedError, err.Error())
+	}
+}
+
+func TestUpdateKMPErrorStatus(t *testing.T) {
+	var parametersString = "{\"Keys\":[{\"KeyName\":\"key\"}]}"
+	kmp := configv1beta1.KeyManagementProvider{
+		Status: configv1beta1.KeyManagementProviderStatus{
+			IsSuccess: true,
+			Properties: runtime.RawExtension{
+				Raw: []byte(parametersString),
+			},
+		},
+	}
+	expectedErr := "it's a long error from unit test"
+	lastFetchedTime := metav1.Now()
+	updateKMPErrorStatus(&kmp, expectedErr, &lastFetchedTime)
+
+	if kmp.Status.IsSuccess {
+		t.Fatalf("Unexpected error, expected isSuccess to be false")
+	}
+	if kmp.Status.Error != expectedErr {
+		t.Fatalf("Unexpected error string, expected %+v, got %+v", expectedErr, kmp.Status.Error)
+	}
+	if kmp.Status.BriefError != expectedErr[:30]+"..." {
+		t.Fatalf("Unexpected brief error string, got %+v", kmp.Status.BriefError)
+	}
+
+	//make sure properties of last fetched keys were not overridden
+	if string(kmp.Status.Properties.Raw) != parametersString {
+		t.Fatalf("Unexpected properties, expected %+v, got %+v", parametersString, string(kmp.Status.Properties.Raw))
+	}
+}
+
+func TestUpdateKMPSuccessStatus(t *testing.T) {
+	lastFetchedTime := metav1.Now()
+	kmp := configv1beta1.KeyManagementProvider{
+		Status: configv1beta1.KeyManagementProviderStatus{
+			IsSuccess: false,
+			Error:     "error from last operation",
+		},
+	}
+	kmpStatus := mergeKMPStatus(
+		keymanagementprovider.KeyManagementProviderStatus{"Certificates": []map[string]string{{"CertificateName": "cert"}}},
+		nil,
+		keymanagementprovider.KeyManagementProviderStatus{"Keys": []map[string]string{{"KeyName": "key"}}},
+	)
+
+	updateKMPSuccessStatus(&kmp, &lastFetchedTime, kmpStatus)
+
+	if !kmp.Status.IsSuccess || kmp.Status.Error != "" {
+		t.Fatalf("Expected success status, actual %+v", kmp.Status)
+	}
+	properties := map[string]interface{}{}
+	if err := json.Unmarshal(kmp.Status.Properties.Raw, &properties); err != nil {
+		t.Fatalf("failed to unmarshal properties: %v", err)
+	}
+	if _, ok := properties["Certificates"]; !ok {
+		t.Fatalf("expected certificates properties, got %+v", properties)
+	}
+	if _, ok := properties["Keys"]; !ok {
+		t.Fatalf("expected keys properties, got %+v", properties)
+	}
+}
+
+func TestMergeKMPStatus_Empty(t *testing.T) {
+	if status := mergeKMPStatus(nil, nil); status != nil {
+		t.Fatalf("expected nil status, got %+v", status)
+	}
+}
+
+func TestGetVersionHistory(t *testing.T) {
+	testCases := []struct {
+		versionHistory int
+		expectErr      bool
+	}{
+		{versionHistory: 0},
+		{versionHistory: 3},
+		{versionHistory: keymanagementprovider.MaxVersionHistory},
+		{versionHistory: -1, expectErr: true},
+		{versionHistory: keymanagementprovider.MaxVersionHistory + 1, expectErr: true},
+	}
+	for _, tc := range testCases {
+		versionHistory, err := getVersionHistory(configv1beta1.KeyManagementProviderSpec{VersionHistory: tc.versionHistory})
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("expected error %v for version history %d, got %v", tc.expectErr, tc.versionHistory, err)
+		}
+		if !tc.expectErr && versionHistory != tc.versionHistory {
+			t.Fatalf("expected version history %d, got %d", tc.versionHistory, versionHistory)
+		}
+	}
+}
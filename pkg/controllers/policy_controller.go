@@ -106,6 +106,7 @@ func policyAddOrReplace(spec configv1beta1.PolicySpec) error {
 
 	ActivePolicy.Name = spec.Type
 	ActivePolicy.Enforcer = policyEnforcer
+	configGeneration.Add(1)
 	return nil
 }
 
@@ -144,6 +145,7 @@ func (p *policy) deletePolicy(resource string) {
 	if p.Name == resource {
 		p.Name = ""
 		p.Enforcer = nil
+		configGeneration.Add(1)
 	}
 }
 
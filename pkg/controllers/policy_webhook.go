@@ -0,0 +1,113 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/internal/constants"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/policyprovider"
+	vr "github.com/deislabs/ratify/pkg/verifier"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PolicyValidator rejects policies that cannot be enforced before they replace
+// the active policy.
+type PolicyValidator struct{}
+
+//+kubebuilder:webhook:path=/validate-config-ratify-deislabs-io-v1beta1-policy,mutating=false,failurePolicy=fail,sideEffects=None,groups=config.ratify.deislabs.io,resources=policies,verbs=create;update,versions=v1beta1,name=policy.config.ratify.deislabs.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &PolicyValidator{}
+
+// SetupWebhookWithManager registers the validating webhook of policies.
+func (v *PolicyValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&configv1beta1.Policy{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates the created policy.
+func (v *PolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return validatePolicy(ctx, obj, ActiveVerifiers())
+}
+
+// ValidateUpdate validates the updated policy.
+func (v *PolicyValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return validatePolicy(ctx, newObj, ActiveVerifiers())
+}
+
+// ValidateDelete allows deleting policies.
+func (v *PolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePolicy creates the policy enforcer of the policy, which compiles Rego
+// policies, and checks that the referenced artifact types are handled by the
+// active verifiers.
+func validatePolicy(ctx context.Context, obj runtime.Object, verifiers []vr.ReferenceVerifier) (admission.Warnings, error) {
+	policy, ok := obj.(*configv1beta1.Policy)
+	if !ok {
+		return nil, fmt.Errorf("expected a Policy, got %T", obj)
+	}
+	if policy.Name != constants.RatifyPolicy {
+		return nil, fmt.Errorf("metadata.name must be %s, got %s", constants.RatifyPolicy, policy.Name)
+	}
+	policyEnforcer, err := specToPolicyEnforcer(policy.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactTypesProvider, ok := policyEnforcer.(policyprovider.ArtifactTypesProvider)
+	if !ok {
+		return nil, nil
+	}
+	artifactTypes := artifactTypesProvider.ArtifactTypes()
+	if len(artifactTypes) == 0 {
+		return nil, nil
+	}
+	if len(verifiers) == 0 {
+		// verifiers may be created after the policy, artifact types are only
+		// validated once verifiers are active
+		return admission.Warnings{"no verifiers are active, artifact types of the policy are not validated"}, nil
+	}
+
+	var unknown []string
+	for _, artifactType := range artifactTypes {
+		if !canVerifyArtifactType(ctx, verifiers, artifactType) {
+			unknown = append(unknown, artifactType)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("artifact types %s of the policy are not verified by any active verifier", strings.Join(unknown, ", "))
+	}
+	return nil, nil
+}
+
+func canVerifyArtifactType(ctx context.Context, verifiers []vr.ReferenceVerifier, artifactType string) bool {
+	for _, verifier := range verifiers {
+		if verifier.CanVerify(ctx, ocispecs.ReferenceDescriptor{ArtifactType: artifactType}) {
+			return true
+		}
+	}
+	return false
+}
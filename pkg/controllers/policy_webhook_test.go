@@ -0,0 +1,120 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/internal/constants"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	_ "github.com/deislabs/ratify/pkg/policyprovider/regopolicy"
+	vr "github.com/deislabs/ratify/pkg/verifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const notationArtifactType = "application/vnd.cncf.notary.signature"
+
+func newTestPolicy(name, policyType, parameters string) *configv1beta1.Policy {
+	return &configv1beta1.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: configv1beta1.PolicySpec{
+			Type: policyType,
+			Parameters: runtime.RawExtension{
+				Raw: []byte(parameters),
+			},
+		},
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	notationVerifier := &core.TestVerifier{
+		CanVerifyFunc: func(artifactType string) bool {
+			return artifactType == notationArtifactType
+		},
+	}
+	testCases := []struct {
+		name          string
+		obj           runtime.Object
+		verifiers     []vr.ReferenceVerifier
+		expectErr     bool
+		expectWarning bool
+	}{
+		{
+			name:      "unexpected object",
+			obj:       &configv1beta1.Store{},
+			expectErr: true,
+		},
+		{
+			name:      "invalid name",
+			obj:       newTestPolicy("policy", "configpolicy", `{"name": "configpolicy"}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid rego",
+			obj:       newTestPolicy(constants.RatifyPolicy, "regopolicy", `{"policy": "package ratify.policy\ndefault valid := "}`),
+			expectErr: true,
+		},
+		{
+			name:      "valid rego",
+			obj:       newTestPolicy(constants.RatifyPolicy, "regopolicy", `{"policy": "package ratify.policy\ndefault valid := false"}`),
+			verifiers: []vr.ReferenceVerifier{notationVerifier},
+		},
+		{
+			name:      "known artifact type",
+			obj:       newTestPolicy(constants.RatifyPolicy, "configpolicy", `{"artifactVerificationPolicies": {"application/vnd.cncf.notary.signature": "any"}}`),
+			verifiers: []vr.ReferenceVerifier{notationVerifier},
+		},
+		{
+			name:      "unknown artifact type",
+			obj:       newTestPolicy(constants.RatifyPolicy, "configpolicy", `{"artifactVerificationPolicies": {"application/unknown": "any"}}`),
+			verifiers: []vr.ReferenceVerifier{notationVerifier},
+			expectErr: true,
+		},
+		{
+			name:      "unknown required artifact type",
+			obj:       newTestPolicy(constants.RatifyPolicy, "configpolicy", `{"requiredArtifactTypes": [{"artifactTypes": ["application/unknown"]}]}`),
+			verifiers: []vr.ReferenceVerifier{notationVerifier},
+			expectErr: true,
+		},
+		{
+			name:          "no active verifiers",
+			obj:           newTestPolicy(constants.RatifyPolicy, "configpolicy", `{"artifactVerificationPolicies": {"application/unknown": "any"}}`),
+			expectWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings, err := validatePolicy(context.Background(), tc.obj, tc.verifiers)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+			if tc.expectWarning != (len(warnings) > 0) {
+				t.Fatalf("expected warning %t, got %v", tc.expectWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestPolicyValidator_ValidateDelete(t *testing.T) {
+	validator := &PolicyValidator{}
+	if _, err := validator.ValidateDelete(context.Background(), newTestPolicy("policy", "", "")); err != nil {
+		t.Fatalf("expected deletion to be allowed, got %v", err)
+	}
+}
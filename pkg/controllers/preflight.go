@@ -0,0 +1,147 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/internal/constants"
+	"github.com/deislabs/ratify/pkg/certificateprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	"github.com/deislabs/ratify/pkg/preflight"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LoadResources creates the stores, verifiers and policy of the resources in
+// the cluster as the reconcilers do, recording the resources that fail to load
+// in the preflight report. The certificate stores and key management providers
+// are returned as key providers, fetching their certificates and keys adds them
+// to the maps read by the verifiers.
+func LoadResources(ctx context.Context, c client.Reader, report *preflight.Report) []preflight.KeyProvider {
+	var keyProviders []preflight.KeyProvider
+	var certStores configv1beta1.CertificateStoreList
+	if err := c.List(ctx, &certStores); err != nil {
+		report.Fail(preflight.KindConfig, "certificatestores", err)
+	}
+	for i := range certStores.Items {
+		certStore := certStores.Items[i]
+		resource := client.ObjectKeyFromObject(&certStore).String()
+		attributes, err := getCertStoreConfig(certStore.Spec)
+		if err != nil {
+			report.Fail(preflight.KindConfig, "certificatestore/"+resource, err)
+			continue
+		}
+		provider, err := getCertificateProvider(certificateprovider.GetCertificateProviders(), certStore.Spec.Provider)
+		if err != nil {
+			report.Fail(preflight.KindConfig, "certificatestore/"+resource, err)
+			continue
+		}
+		keyProviders = append(keyProviders, preflight.KeyProvider{
+			Name: "certificatestore/" + resource,
+			Fetch: func(ctx context.Context) error {
+				certificates, _, err := provider.GetCertificates(ctx, attributes)
+				if err != nil {
+					return err
+				}
+				certificatesMap[resource] = certificates
+				return nil
+			},
+		})
+	}
+
+	var kmps configv1beta1.KeyManagementProviderList
+	if err := c.List(ctx, &kmps); err != nil {
+		report.Fail(preflight.KindConfig, "keymanagementproviders", err)
+	}
+	for i := range kmps.Items {
+		kmp := kmps.Items[i]
+		resource := client.ObjectKeyFromObject(&kmp).String()
+		kmpConfig, err := getKMPConfig(kmp.Spec)
+		if err != nil {
+			report.Fail(preflight.KindConfig, "keymanagementprovider/"+resource, err)
+			continue
+		}
+		provider, err := factory.CreateKeyManagementProviderFromConfig(kmpConfig)
+		if err != nil {
+			report.Fail(preflight.KindConfig, "keymanagementprovider/"+resource, err)
+			continue
+		}
+		keyProviders = append(keyProviders, preflight.KeyProvider{
+			Name: "keymanagementprovider/" + resource,
+			Fetch: func(ctx context.Context) error {
+				certificates, _, err := provider.GetCertificates(ctx)
+				if err != nil {
+					return err
+				}
+				keys, _, err := provider.GetKeys(ctx)
+				if err != nil {
+					return err
+				}
+				keymanagementprovider.SetCertificatesInMap(ctx, resource, certificates, kmp.Spec.VersionHistory)
+				keymanagementprovider.SetKeysInMap(ctx, resource, keys, kmp.Spec.VersionHistory)
+				return nil
+			},
+		})
+	}
+
+	var stores configv1beta1.StoreList
+	if err := c.List(ctx, &stores); err != nil {
+		report.Fail(preflight.KindConfig, "stores", err)
+	}
+	for _, store := range stores.Items {
+		if err := storeAddOrReplace(store.Spec, store.Name); err != nil {
+			report.Fail(preflight.KindConfig, "store/"+store.Name, err)
+			continue
+		}
+		report.Pass(preflight.KindConfig, "store/"+store.Name, "store created")
+	}
+
+	var verifiers configv1beta1.VerifierList
+	if err := c.List(ctx, &verifiers); err != nil {
+		report.Fail(preflight.KindConfig, "verifiers", err)
+	}
+	for _, verifier := range verifiers.Items {
+		namespace, err := getCertStoreNamespace(verifier.Namespace)
+		if err == nil {
+			err = verifierAddOrReplace(verifier.Spec, verifier.Name, namespace)
+		}
+		if err != nil {
+			report.Fail(preflight.KindConfig, "verifier/"+verifier.Name, err)
+			continue
+		}
+		report.Pass(preflight.KindConfig, "verifier/"+verifier.Name, "verifier created")
+	}
+
+	var policies configv1beta1.PolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		report.Fail(preflight.KindConfig, "policies", err)
+	}
+	for _, policy := range policies.Items {
+		if policy.Name != constants.RatifyPolicy {
+			report.Fail(preflight.KindConfig, "policy/"+policy.Name, fmt.Errorf("metadata.name must be %s, got %s", constants.RatifyPolicy, policy.Name))
+			continue
+		}
+		if err := policyAddOrReplace(policy.Spec); err != nil {
+			report.Fail(preflight.KindConfig, "policy/"+policy.Name, err)
+			continue
+		}
+		report.Pass(preflight.KindConfig, "policy/"+policy.Name, "policy created")
+	}
+	return keyProviders
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
+	"github.com/deislabs/ratify/internal/constants"
+	"github.com/deislabs/ratify/pkg/preflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLoadResources(t *testing.T) {
+	resetStoreMap()
+	scheme := runtime.NewScheme()
+	if err := configv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&configv1beta1.Store{ObjectMeta: metav1.ObjectMeta{Name: "oras"}, Spec: getOrasStoreSpec()},
+		&configv1beta1.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.RatifyPolicy},
+			Spec: configv1beta1.PolicySpec{
+				Type:       "configpolicy",
+				Parameters: runtime.RawExtension{Raw: []byte(`{"name": "configpolicy"}`)},
+			},
+		},
+		&configv1beta1.CertificateStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "certs", Namespace: "default"},
+			Spec: configv1beta1.CertificateStoreSpec{
+				Provider:   "unknown",
+				Parameters: runtime.RawExtension{Raw: []byte(`{"value": "cert"}`)},
+			},
+		},
+	).Build()
+
+	report := preflight.NewReport()
+	keyProviders := LoadResources(context.Background(), c, report)
+	if len(keyProviders) != 0 {
+		t.Fatalf("expected no key providers, got %d", len(keyProviders))
+	}
+	if report.Success || report.ExitCode != preflight.ExitCodeConfigInvalid {
+		t.Fatalf("expected config failure of the certificate store, got %+v", report)
+	}
+	if _, ok := StoreMap["oras"]; !ok {
+		t.Fatalf("expected store to be created")
+	}
+	if ActivePolicy.IsEmpty() {
+		t.Fatalf("expected policy to be created")
+	}
+	ActivePolicy.deletePolicy(ActivePolicy.Name)
+}
@@ -19,11 +19,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
 	"github.com/deislabs/ratify/config"
@@ -40,9 +45,16 @@ type StoreReconciler struct {
 	Scheme *runtime.Scheme
 }
 
+// storeStatusRefreshInterval is the interval to refresh the registry
+// connectivity reported in the status of a store.
+const storeStatusRefreshInterval = time.Minute
+
 var (
 	// a map to track active stores
 	StoreMap = map[string]referrerstore.ReferrerStore{}
+	// a map to track the generation of the resource each active store was
+	// created from
+	storeGenerations = map[string]int64{}
 )
 
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=stores,verbs=get;list;watch;create;update;patch;delete
@@ -72,19 +84,26 @@ func (r *StoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if err := storeAddOrReplace(store.Spec, resource); err != nil {
-		storeLogger.Error(err, "unable to create store from store crd")
-		return ctrl.Result{}, err
+	// the store is only recreated if the spec changed, requeued reconciles
+	// refresh the status of the active store
+	if _, ok := StoreMap[resource]; !ok || storeGenerations[resource] != store.Generation {
+		if err := storeAddOrReplace(store.Spec, resource); err != nil {
+			storeLogger.Error(err, "unable to create store from store crd")
+			writeStoreStatus(ctx, r, &store, storeLogger, err)
+			return ctrl.Result{}, err
+		}
+		storeGenerations[resource] = store.Generation
 	}
+	writeStoreStatus(ctx, r, &store, storeLogger, nil)
 
-	// returning empty result and no error to indicate we’ve successfully reconciled this object
-	return ctrl.Result{}, nil
+	// requeue to report connectivity problems of the registry in the status
+	return ctrl.Result{RequeueAfter: storeStatusRefreshInterval}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *StoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&configv1beta1.Store{}).
+		For(&configv1beta1.Store{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
@@ -113,6 +132,7 @@ func storeAddOrReplace(spec configv1beta1.StoreSpec, fullname string) error {
 	}
 
 	StoreMap[fullname] = storeReference
+	configGeneration.Add(1)
 	logrus.Infof("store '%v' added to store map", storeReference.Name())
 
 	return nil
@@ -121,6 +141,8 @@ func storeAddOrReplace(spec configv1beta1.StoreSpec, fullname string) error {
 // Remove store from map
 func storeRemove(resourceName string) {
 	delete(StoreMap, resourceName)
+	delete(storeGenerations, resourceName)
+	configGeneration.Add(1)
 }
 
 // Returns a store reference from spec
@@ -140,3 +162,79 @@ func specToStoreConfig(storeSpec configv1beta1.StoreSpec) (rc.StorePluginConfig,
 
 	return storeConfig, nil
 }
+
+// writeStoreStatus updates the status of the store with the result of creating
+// the store and the registry connectivity observed by the active store.
+func writeStoreStatus(ctx context.Context, r client.StatusClient, store *configv1beta1.Store, logger *logrus.Entry, err error) {
+	if err != nil {
+		updateStoreErrorStatus(store, err.Error())
+	} else {
+		updateStoreSuccessStatus(store, StoreMap[store.Name])
+	}
+	if statusErr := r.Status().Update(ctx, store); statusErr != nil {
+		logger.Error(statusErr, ", unable to update store status")
+	}
+}
+
+func updateStoreSuccessStatus(store *configv1beta1.Store, storeReference referrerstore.ReferrerStore) {
+	store.Status.IsSuccess = true
+	store.Status.Error = ""
+	store.Status.BriefError = ""
+	meta.SetStatusCondition(&store.Status.Conditions, metav1.Condition{
+		Type:               configv1beta1.StoreConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "StoreCreated",
+		Message:            "store is active",
+		ObservedGeneration: store.Generation,
+	})
+	meta.SetStatusCondition(&store.Status.Conditions, registryReachableCondition(store.Generation, storeReference))
+}
+
+func updateStoreErrorStatus(store *configv1beta1.Store, errString string) {
+	briefErr := errString
+	if len(errString) > maxBriefErrLength {
+		briefErr = fmt.Sprintf("%s...", errString[:maxBriefErrLength])
+	}
+	store.Status.IsSuccess = false
+	store.Status.Error = errString
+	store.Status.BriefError = briefErr
+	meta.SetStatusCondition(&store.Status.Conditions, metav1.Condition{
+		Type:               configv1beta1.StoreConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "StoreCreationFailed",
+		Message:            errString,
+		ObservedGeneration: store.Generation,
+	})
+	meta.RemoveStatusCondition(&store.Status.Conditions, configv1beta1.StoreConditionRegistryReachable)
+}
+
+// registryReachableCondition returns the condition of the connectivity to the
+// registry, it is unknown until the store sent a request to a registry.
+func registryReachableCondition(generation int64, storeReference referrerstore.ReferrerStore) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               configv1beta1.StoreConditionRegistryReachable,
+		Status:             metav1.ConditionUnknown,
+		Reason:             "NotObserved",
+		Message:            "no registry request was sent by the store",
+		ObservedGeneration: generation,
+	}
+	reporter, ok := storeReference.(referrerstore.ConnectivityReporter)
+	if !ok {
+		condition.Reason = "NotSupported"
+		condition.Message = "the store does not report registry connectivity"
+		return condition
+	}
+	observed, err := reporter.RegistryConnectivity()
+	switch {
+	case !observed:
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "RegistryUnreachable"
+		condition.Message = err.Error()
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RegistryReachable"
+		condition.Message = "the registry responded to the last request"
+	}
+	return condition
+}
@@ -16,13 +16,29 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
 	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+type connectivityStore struct {
+	mocks.TestStore
+	observed bool
+	err      error
+}
+
+func (s *connectivityStore) RegistryConnectivity() (bool, error) {
+	return s.observed, s.err
+}
+
 func TestStoreAdd_EmptyParameter(t *testing.T) {
 	resetStoreMap()
 	var testStoreSpec = configv1beta1.StoreSpec{
@@ -89,8 +105,102 @@ func TestStore_UpdateAndDelete(t *testing.T) {
 	}
 }
 
+func TestWriteStoreStatus(t *testing.T) {
+	logger := logrus.WithContext(context.Background())
+	testCases := []struct {
+		name              string
+		store             referrerstore.ReferrerStore
+		err               error
+		expectIsSuccess   bool
+		expectBriefError  string
+		expectReady       metav1.ConditionStatus
+		expectReachable   metav1.ConditionStatus
+		expectNoReachable bool
+	}{
+		{
+			name:            "connectivity not reported",
+			store:           &mocks.TestStore{},
+			expectIsSuccess: true,
+			expectReady:     metav1.ConditionTrue,
+			expectReachable: metav1.ConditionUnknown,
+		},
+		{
+			name:            "no registry request",
+			store:           &connectivityStore{},
+			expectIsSuccess: true,
+			expectReady:     metav1.ConditionTrue,
+			expectReachable: metav1.ConditionUnknown,
+		},
+		{
+			name:            "registry reachable",
+			store:           &connectivityStore{observed: true},
+			expectIsSuccess: true,
+			expectReady:     metav1.ConditionTrue,
+			expectReachable: metav1.ConditionTrue,
+		},
+		{
+			name:            "registry unreachable",
+			store:           &connectivityStore{observed: true, err: errors.New("dial tcp: connection refused")},
+			expectIsSuccess: true,
+			expectReady:     metav1.ConditionTrue,
+			expectReachable: metav1.ConditionFalse,
+		},
+		{
+			name:              "store creation failed",
+			err:               errors.New("a long error string that exceeds the max length of 30 characters"),
+			expectBriefError:  "a long error string that excee...",
+			expectReady:       metav1.ConditionFalse,
+			expectNoReachable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetStoreMap()
+			if tc.store != nil {
+				StoreMap["oras"] = tc.store
+			}
+			store := &configv1beta1.Store{ObjectMeta: metav1.ObjectMeta{Name: "oras", Generation: 2}}
+			writeStoreStatus(context.Background(), &mockStatusClient{}, store, logger, tc.err)
+
+			if store.Status.IsSuccess != tc.expectIsSuccess {
+				t.Fatalf("expected IsSuccess %v, got %v", tc.expectIsSuccess, store.Status.IsSuccess)
+			}
+			if store.Status.BriefError != tc.expectBriefError {
+				t.Fatalf("expected brief error %q, got %q", tc.expectBriefError, store.Status.BriefError)
+			}
+			ready := meta.FindStatusCondition(store.Status.Conditions, configv1beta1.StoreConditionReady)
+			if ready == nil || ready.Status != tc.expectReady || ready.ObservedGeneration != 2 {
+				t.Fatalf("expected Ready condition %v, got %+v", tc.expectReady, ready)
+			}
+			reachable := meta.FindStatusCondition(store.Status.Conditions, configv1beta1.StoreConditionRegistryReachable)
+			if tc.expectNoReachable {
+				if reachable != nil {
+					t.Fatalf("expected no RegistryReachable condition, got %+v", reachable)
+				}
+				return
+			}
+			if reachable == nil || reachable.Status != tc.expectReachable {
+				t.Fatalf("expected RegistryReachable condition %v, got %+v", tc.expectReachable, reachable)
+			}
+		})
+	}
+}
+
+func TestStoreRemove_DeletesGeneration(t *testing.T) {
+	resetStoreMap()
+	StoreMap["oras"] = &mocks.TestStore{}
+	storeGenerations["oras"] = 1
+
+	storeRemove("oras")
+	if _, ok := storeGenerations["oras"]; ok {
+		t.Fatalf("expected generation of removed store to be deleted")
+	}
+}
+
 func resetStoreMap() {
 	StoreMap = map[string]referrerstore.ReferrerStore{}
+	storeGenerations = map[string]int64{}
 }
 
 func getOrasStoreSpec() configv1beta1.StoreSpec {
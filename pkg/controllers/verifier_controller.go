@@ -20,6 +20,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
+	"time"
 
 	configv1beta1 "github.com/deislabs/ratify/api/v1beta1"
 	"github.com/deislabs/ratify/config"
@@ -43,11 +46,36 @@ type VerifierReconciler struct {
 	Scheme *runtime.Scheme
 }
 
+// verifierWarmTimeout bounds the time spent preparing a verifier before it
+// replaces the active one
+const verifierWarmTimeout = 30 * time.Second
+
 var (
 	// a map to track of active verifiers
 	VerifierMap = map[string]vr.ReferenceVerifier{}
+	// verifierMu guards VerifierMap against concurrent reconciles and reads of
+	// the executor
+	verifierMu sync.RWMutex
 )
 
+// ActiveVerifiers returns the verifiers reconciled from verifier resources,
+// ordered by resource name so that the verifiers of each executor are ordered
+// consistently.
+func ActiveVerifiers() []vr.ReferenceVerifier {
+	verifierMu.RLock()
+	defer verifierMu.RUnlock()
+	names := make([]string, 0, len(VerifierMap))
+	for name := range VerifierMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	verifiers := make([]vr.ReferenceVerifier, 0, len(names))
+	for _, name := range names {
+		verifiers = append(verifiers, VerifierMap[name])
+	}
+	return verifiers
+}
+
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=verifiers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=verifiers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=config.ratify.deislabs.io,resources=verifiers/finalizers,verbs=update
@@ -119,7 +147,19 @@ func verifierAddOrReplace(spec configv1beta1.VerifierSpec, objectName string, na
 		logrus.Error(err, "unable to create verifier from verifier config")
 		return err
 	}
+
+	// prepare the verifier before it replaces the active one, so requests are
+	// not slowed down by starting its plugin or loading its trust stores
+	ctx, cancel := context.WithTimeout(context.Background(), verifierWarmTimeout)
+	if err := vr.Warm(ctx, referenceVerifier); err != nil {
+		logrus.Warnf("failed to warm verifier '%v': %v", referenceVerifier.Name(), err)
+	}
+	cancel()
+
+	verifierMu.Lock()
 	VerifierMap[objectName] = referenceVerifier
+	verifierMu.Unlock()
+	configGeneration.Add(1)
 	logrus.Infof("verifier '%v' added to verifier map", referenceVerifier.Name())
 
 	return nil
@@ -127,7 +167,10 @@ func verifierAddOrReplace(spec configv1beta1.VerifierSpec, objectName string, na
 
 // remove verifier from map
 func verifierRemove(objectName string) {
+	verifierMu.Lock()
 	delete(VerifierMap, objectName)
+	verifierMu.Unlock()
+	configGeneration.Add(1)
 }
 
 // returns a verifier reference from spec
@@ -65,6 +65,24 @@ func TestVerifierAdd_WithParameters(t *testing.T) {
 	}
 }
 
+func TestActiveVerifiers_OrderedByResourceName(t *testing.T) {
+	resetVerifierMap()
+	var testVerifierSpec = configv1beta1.VerifierSpec{
+		Name:          "notation",
+		ArtifactTypes: "application/vnd.cncf.notary.signature",
+	}
+	for _, resource := range []string{"notation-b", "notation-a"} {
+		if err := verifierAddOrReplace(testVerifierSpec, resource, constants.EmptyNamespace); err != nil {
+			t.Fatalf("verifierAddOrReplace() expected no error, actual %v", err)
+		}
+	}
+
+	verifiers := ActiveVerifiers()
+	if len(verifiers) != 2 || verifiers[0].Name() != "notation-a" || verifiers[1].Name() != "notation-b" {
+		t.Fatalf("expected verifiers ordered by resource name, actual %v", verifiers)
+	}
+}
+
 func TestVerifier_UpdateAndDelete(t *testing.T) {
 	resetVerifierMap()
 
@@ -0,0 +1,88 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distributedlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dapr/go-sdk/client"
+	"github.com/deislabs/ratify/pkg/featureflag"
+)
+
+// daprLockClient is the subset of the Dapr client used to lock.
+type daprLockClient interface {
+	TryLockAlpha1(ctx context.Context, storeName string, request *client.LockRequest) (*client.LockResponse, error)
+	UnlockAlpha1(ctx context.Context, storeName string, request *client.UnlockRequest) (*client.UnlockResponse, error)
+}
+
+// daprLock locks with a Dapr distributed lock store.
+type daprLock struct {
+	client    daprLockClient
+	storeName string
+	owner     string
+	ttl       time.Duration
+}
+
+func newDaprLock(storeName string, ttl time.Duration) (*daprLock, error) {
+	if !featureflag.HighAvailability.Enabled {
+		return nil, fmt.Errorf("Dapr distributed lock is not enabled. Please set the environment variable RATIFY_EXPERIMENTAL_HIGH_AVAILABILITY to enable it")
+	}
+	daprClient, err := client.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &daprLock{
+		client:    daprClient,
+		storeName: storeName,
+		owner:     identity(),
+		ttl:       ttl,
+	}, nil
+}
+
+func (l *daprLock) Lock(ctx context.Context, key string) (func(), error) {
+	resourceID := resourceName(key)
+	err := acquire(ctx, func() (bool, error) {
+		resp, err := l.client.TryLockAlpha1(ctx, l.storeName, &client.LockRequest{
+			ResourceID:      resourceID,
+			LockOwner:       l.owner,
+			ExpiryInSeconds: int32(l.ttl.Seconds()),
+		})
+		if err != nil {
+			return false, err
+		}
+		return resp.Success, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		release(key, func(ctx context.Context) error {
+			resp, err := l.client.UnlockAlpha1(ctx, l.storeName, &client.UnlockRequest{
+				ResourceID: resourceID,
+				LockOwner:  l.owner,
+			})
+			if err != nil {
+				return err
+			}
+			if resp.Status != "SUCCESS" {
+				return fmt.Errorf("unlock status %s", resp.Status)
+			}
+			return nil
+		})
+	}, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distributedlock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/utils"
+)
+
+const (
+	// TypeDapr locks with a Dapr distributed lock store, e.g. backed by Redis.
+	TypeDapr = "dapr"
+	// TypeLease locks with Kubernetes Leases in the namespace of Ratify.
+	TypeLease = "lease"
+
+	// DefaultName is the name of the Dapr lock store.
+	DefaultName = "dapr-redis-lock"
+	// DefaultTTL bounds how long a lock of a crashed replica is held.
+	DefaultTTL = 30 * time.Second
+
+	// resourcePrefix prefixes the lock resources, which are named after the
+	// hash of the key to be valid resource names.
+	resourcePrefix   = "ratify-verify-"
+	minRetryInterval = 50 * time.Millisecond
+	maxRetryInterval = time.Second
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.Server,
+}
+
+// Locker is a lock shared by the replicas of Ratify.
+type Locker interface {
+	// Lock blocks until the lock of the key is acquired or the context is
+	// done, and returns a function to release it.
+	Lock(ctx context.Context, key string) (func(), error)
+}
+
+// New creates the locker of the type. The name selects the Dapr lock store and
+// the TTL expires locks that are not released, e.g. by a crashed replica.
+func New(lockType string, name string, ttl time.Duration) (Locker, error) {
+	if ttl < time.Second {
+		return nil, fmt.Errorf("distributed lock TTL must be at least 1s, got %v", ttl)
+	}
+	switch lockType {
+	case TypeDapr:
+		return newDaprLock(name, ttl)
+	case TypeLease:
+		return newLeaseLock(utils.GetNamespace(), ttl)
+	default:
+		return nil, fmt.Errorf("unsupported distributed lock type %q, expected %s or %s", lockType, TypeDapr, TypeLease)
+	}
+}
+
+// acquire retries to acquire the lock with an increasing interval until it
+// succeeds or the context is done.
+func acquire(ctx context.Context, tryLock func() (bool, error)) error {
+	interval := minRetryInterval
+	for {
+		acquired, err := tryLock()
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// resourceName returns the name of the lock resource of the key.
+func resourceName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return resourcePrefix + hex.EncodeToString(sum[:20])
+}
+
+// identity returns the holder identity of the locks of this replica.
+func identity() string {
+	if name := utils.GetPodName(); name != "" {
+		return name
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "ratify"
+	}
+	return hostname
+}
+
+// release releases a lock without the request context, which may be done.
+func release(key string, unlock func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := unlock(ctx); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to release the distributed lock of %s, it expires after its TTL: %v", key, err)
+	}
+}
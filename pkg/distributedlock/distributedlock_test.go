@@ -0,0 +1,187 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distributedlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dapr/go-sdk/client"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testKey = "localhost:5000/net-monitor@sha256:1234"
+
+// mockDaprClient is an in-memory Dapr lock store.
+type mockDaprClient struct {
+	mu     sync.Mutex
+	owners map[string]string
+	err    error
+}
+
+func (c *mockDaprClient) TryLockAlpha1(_ context.Context, _ string, request *client.LockRequest) (*client.LockResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.owners[request.ResourceID]; ok {
+		return &client.LockResponse{Success: false}, nil
+	}
+	c.owners[request.ResourceID] = request.LockOwner
+	return &client.LockResponse{Success: true}, nil
+}
+
+func (c *mockDaprClient) UnlockAlpha1(_ context.Context, _ string, request *client.UnlockRequest) (*client.UnlockResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.owners[request.ResourceID] != request.LockOwner {
+		return &client.UnlockResponse{Status: "LOCK_BELONGS_TO_OTHERS"}, nil
+	}
+	delete(c.owners, request.ResourceID)
+	return &client.UnlockResponse{Status: "SUCCESS"}, nil
+}
+
+// testMutualExclusion locks the key with the first locker and checks that the
+// second one acquires it only after it is released.
+func testMutualExclusion(t *testing.T, first, second Locker) {
+	t.Helper()
+	unlock, err := first.Lock(context.Background(), testKey)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := second.Lock(ctx, testKey); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the lock to be held, got error %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		unlockSecond, err := second.Lock(context.Background(), testKey)
+		if err != nil {
+			t.Errorf("Lock() error = %v", err)
+		}
+		acquired <- unlockSecond
+	}()
+	unlock()
+	select {
+	case unlockSecond := <-acquired:
+		if unlockSecond != nil {
+			unlockSecond()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lock to be acquired after it was released")
+	}
+}
+
+func TestDaprLock(t *testing.T) {
+	daprClient := &mockDaprClient{owners: make(map[string]string)}
+	first := &daprLock{client: daprClient, storeName: DefaultName, owner: "ratify-0", ttl: DefaultTTL}
+	second := &daprLock{client: daprClient, storeName: DefaultName, owner: "ratify-1", ttl: DefaultTTL}
+	testMutualExclusion(t, first, second)
+	if len(daprClient.owners) != 0 {
+		t.Fatalf("expected all locks to be released, got %v", daprClient.owners)
+	}
+}
+
+func TestDaprLock_Error(t *testing.T) {
+	daprClient := &mockDaprClient{owners: make(map[string]string), err: errors.New("lock store unavailable")}
+	locker := &daprLock{client: daprClient, storeName: DefaultName, owner: "ratify-0", ttl: DefaultTTL}
+	if _, err := locker.Lock(context.Background(), testKey); err == nil {
+		t.Fatal("expected error when the lock store is unavailable")
+	}
+}
+
+func TestLeaseLock(t *testing.T) {
+	leases := fake.NewSimpleClientset().CoordinationV1().Leases("gatekeeper-system")
+	first := &leaseLock{client: leases, identity: "ratify-0", ttl: DefaultTTL}
+	second := &leaseLock{client: leases, identity: "ratify-1", ttl: DefaultTTL}
+	testMutualExclusion(t, first, second)
+
+	list, err := leases.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list leases: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Fatalf("expected released leases to be deleted, got %d", len(list.Items))
+	}
+}
+
+func TestLeaseLock_TakesOverExpiredLease(t *testing.T) {
+	leases := fake.NewSimpleClientset().CoordinationV1().Leases("gatekeeper-system")
+	holder := "crashed"
+	seconds := int32(1)
+	renewTime := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	if _, err := leases.Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: resourceName(testKey)},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			RenewTime:            &renewTime,
+		},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create lease: %v", err)
+	}
+
+	locker := &leaseLock{client: leases, identity: "ratify-0", ttl: DefaultTTL}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlock, err := locker.Lock(ctx, testKey)
+	if err != nil {
+		t.Fatalf("expected the expired lease to be taken over, got error %v", err)
+	}
+	lease, err := leases.Get(context.Background(), resourceName(testKey), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get lease: %v", err)
+	}
+	if *lease.Spec.HolderIdentity != "ratify-0" {
+		t.Fatalf("expected lease to be held by ratify-0, got %s", *lease.Spec.HolderIdentity)
+	}
+	unlock()
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	if _, err := New("etcd", DefaultName, DefaultTTL); err == nil {
+		t.Fatal("expected error for unsupported lock type")
+	}
+	if _, err := New(TypeLease, DefaultName, 0); err == nil {
+		t.Fatal("expected error for TTL below 1s")
+	}
+	if _, err := New(TypeDapr, DefaultName, DefaultTTL); err == nil {
+		t.Fatal("expected error when high availability is not enabled")
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	name := resourceName(testKey)
+	if name != resourceName(testKey) {
+		t.Fatal("expected resource names to be stable")
+	}
+	if name == resourceName("localhost:5000/net-monitor:v1") {
+		t.Fatal("expected distinct keys to have distinct resource names")
+	}
+	// lease names must be valid DNS subdomains
+	if len(name) > 63 {
+		t.Fatalf("resource name %s is too long", name)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distributedlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// leaseLock locks with Kubernetes Leases. A lease is deleted when its lock is
+// released, and taken over once it expires if its holder never releases it.
+type leaseLock struct {
+	client   coordinationclientv1.LeaseInterface
+	identity string
+	ttl      time.Duration
+}
+
+func newLeaseLock(namespace string, ttl time.Duration) (*leaseLock, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return &leaseLock{
+		client:   clientset.CoordinationV1().Leases(namespace),
+		identity: identity(),
+		ttl:      ttl,
+	}, nil
+}
+
+func (l *leaseLock) Lock(ctx context.Context, key string) (func(), error) {
+	name := resourceName(key)
+	var held *coordinationv1.Lease
+	err := acquire(ctx, func() (bool, error) {
+		var err error
+		held, err = l.tryLock(ctx, name)
+		return held != nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		release(key, func(ctx context.Context) error {
+			// the precondition keeps a lease taken over after expiring
+			err := l.client.Delete(ctx, name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &held.UID, ResourceVersion: &held.ResourceVersion},
+			})
+			if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+				return nil
+			}
+			return err
+		})
+	}, nil
+}
+
+// tryLock creates the lease, or takes it over if it expired. Returns the held
+// lease, or nil if another replica holds it.
+func (l *leaseLock) tryLock(ctx context.Context, name string) (*coordinationv1.Lease, error) {
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(l.ttl.Seconds())
+	spec := coordinationv1.LeaseSpec{
+		HolderIdentity:       &l.identity,
+		LeaseDurationSeconds: &seconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+	lease, err := l.client.Create(ctx, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}, metav1.CreateOptions{})
+	if err == nil {
+		return lease, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	existing, err := l.client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// released in the meantime
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !leaseExpired(existing, now.Time) {
+		return nil, nil
+	}
+	existing.Spec = spec
+	lease, err = l.client.Update(ctx, existing, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		// taken over by another replica
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
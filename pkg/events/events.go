@@ -0,0 +1,100 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ReasonVerificationFailed is the reason of the events of subjects failing
+	// verification.
+	ReasonVerificationFailed = "VerificationFailed"
+
+	component = "ratify"
+	// maxMessageLength bounds the message of an event, the API server rejects
+	// larger messages.
+	maxMessageLength = 1024
+)
+
+// Recorder records Kubernetes Events of the subjects failing verification, so
+// that denials are listed by `kubectl get events`.
+type Recorder struct {
+	recorder record.EventRecorder
+	object   *corev1.ObjectReference
+}
+
+// NewRecorder returns a recorder of events on the namespace if set, otherwise
+// on the Ratify pod, which is identified by the RATIFY_POD_NAME and
+// RATIFY_NAMESPACE environment variables.
+func NewRecorder(namespace string) (*Recorder, error) {
+	object, err := involvedObject(namespace)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	return newRecorder(broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component}), object), nil
+}
+
+func newRecorder(recorder record.EventRecorder, object *corev1.ObjectReference) *Recorder {
+	return &Recorder{recorder: recorder, object: object}
+}
+
+// involvedObject returns the object the events are recorded on.
+func involvedObject(namespace string) (*corev1.ObjectReference, error) {
+	if namespace != "" {
+		return &corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: namespace, Namespace: namespace}, nil
+	}
+	name := utils.GetPodName()
+	if name == "" {
+		return nil, fmt.Errorf("the name of the Ratify pod is required to record events on it, set the %s environment variable or an events namespace", utils.RatifyPodNameEnvVar)
+	}
+	return &corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: name, Namespace: utils.GetNamespace()}, nil
+}
+
+// RecordDenial records a Warning event of the subject failing verification.
+// The verifiers are the names of the failed verifiers, they are empty if the
+// subject could not be verified, e.g. the registry is unreachable.
+func (r *Recorder) RecordDenial(subject string, verifiers []string, message string) {
+	var text string
+	if len(verifiers) > 0 {
+		text = fmt.Sprintf("subject %s failed verification by %s: %s", subject, strings.Join(verifiers, ", "), message)
+	} else {
+		text = fmt.Sprintf("subject %s failed verification: %s", subject, message)
+	}
+	if len(text) > maxMessageLength {
+		text = text[:maxMessageLength-3] + "..."
+	}
+	r.recorder.Event(r.object, corev1.EventTypeWarning, ReasonVerificationFailed, text)
+}
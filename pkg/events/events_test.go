@@ -0,0 +1,100 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestInvolvedObject(t *testing.T) {
+	testCases := []struct {
+		name      string
+		namespace string
+		podName   string
+		expected  *corev1.ObjectReference
+		expectErr bool
+	}{
+		{
+			name:      "namespace",
+			namespace: "ratify-events",
+			podName:   "ratify-7d4b9c8f6-x2x9z",
+			expected:  &corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: "ratify-events", Namespace: "ratify-events"},
+		},
+		{
+			name:     "pod",
+			podName:  "ratify-7d4b9c8f6-x2x9z",
+			expected: &corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: "ratify-7d4b9c8f6-x2x9z", Namespace: "gatekeeper-system"},
+		},
+		{
+			name:      "unknown pod",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(utils.RatifyNamespaceEnvVar, "gatekeeper-system")
+			t.Setenv(utils.RatifyPodNameEnvVar, tc.podName)
+			object, err := involvedObject(tc.namespace)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectErr, err)
+			}
+			if tc.expected != nil && *object != *tc.expected {
+				t.Fatalf("expected object %+v, got %+v", tc.expected, object)
+			}
+		})
+	}
+}
+
+func TestRecordDenial(t *testing.T) {
+	testCases := []struct {
+		name      string
+		verifiers []string
+		message   string
+		expected  string
+	}{
+		{
+			name:      "failed verifiers",
+			verifiers: []string{"notation", "sbom"},
+			message:   "signature is not produced by a trusted signer",
+			expected:  "Warning VerificationFailed subject registry.io/test:v1 failed verification by notation, sbom: signature is not produced by a trusted signer",
+		},
+		{
+			name:     "verification error",
+			message:  "failed to connect to registry",
+			expected: "Warning VerificationFailed subject registry.io/test:v1 failed verification: failed to connect to registry",
+		},
+		{
+			name:     "truncated message",
+			message:  strings.Repeat("a", 2*maxMessageLength),
+			expected: "Warning VerificationFailed subject registry.io/test:v1 failed verification: " + strings.Repeat("a", maxMessageLength-len("subject registry.io/test:v1 failed verification: ")-3) + "...",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := record.NewFakeRecorder(1)
+			recorder := newRecorder(fake, &corev1.ObjectReference{Kind: "Pod", Name: "ratify", Namespace: "gatekeeper-system"})
+			recorder.RecordDenial("registry.io/test:v1", tc.verifiers, tc.message)
+			if event := <-fake.Events; event != tc.expected {
+				t.Fatalf("expected event %q, got %q", tc.expected, event)
+			}
+		})
+	}
+}
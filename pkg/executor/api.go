@@ -26,6 +26,14 @@ import (
 type VerifyParameters struct {
 	Subject        string   `json:"subjectReference"`
 	ReferenceTypes []string `json:"referenceTypes,omitempty"`
+	// ReferenceAnnotations are the annotations the referrers of the subject must
+	// have to be verified, an empty value matches any value of the annotation.
+	ReferenceAnnotations map[string]string `json:"referenceAnnotations,omitempty"`
+	// Operation is the admission operation, e.g. CREATE or UPDATE, that triggered the verification.
+	Operation string `json:"operation,omitempty"`
+	// VerificationTime is the time to evaluate trust at, e.g. the time the subject
+	// was admitted, instead of the current time.
+	VerificationTime *time.Time `json:"verificationTime,omitempty"`
 }
 
 // Executor is an interface that defines methods to verify a subject
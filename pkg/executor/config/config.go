@@ -15,11 +15,156 @@ limitations under the License.
 
 package config
 
+import (
+	"fmt"
+	"path"
+	"time"
+
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+)
+
+const (
+	// ReportVersionV1 reports the results of the verifiers as returned by the
+	// policy provider.
+	ReportVersionV1 = "v1"
+	// ReportVersionV2 reports a structured result per artifact with nested
+	// artifacts, digests, timestamps and error codes.
+	ReportVersionV2 = "v2"
+
+	// MutationFailClosed fails the mutation of an image whose tag cannot be
+	// resolved.
+	MutationFailClosed = "failClosed"
+	// MutationFailOpen returns the original reference of an image whose tag
+	// cannot be resolved.
+	MutationFailOpen = "failOpen"
+	// MutationRetry resolves the tag again with exponential backoff within the
+	// mutation request timeout, and fails closed if the tag is not resolved.
+	MutationRetry = "retry"
+
+	defaultMutationMaxRetries               = 3
+	defaultMutationRetryBackoffMilliseconds = 100
+)
+
 // ExecutorConfig represents the configuration for the executor
 type ExecutorConfig struct {
 	// Gatekeeper default verification webhook timeout is 3 seconds. 100ms network buffer added
 	VerificationRequestTimeout *int `json:"verificationRequestTimeout"`
 	// Gatekeeper default mutation webhook timeout is 1 seconds. 50ms network buffer added
 	MutationRequestTimeout *int `json:"mutationRequestTimeout"`
+	// MaxNestedDepth is the number of levels of the referrer graph below the
+	// subject whose artifacts are verified, e.g. 2 verifies the signature of an
+	// SBOM attached to the subject. Defaults to 3.
+	MaxNestedDepth *int `json:"maxNestedDepth,omitempty"`
+	// MaxVerificationCount is the maximum number of artifacts in the referrer
+	// graph of a subject, including nested artifacts, that are verified. The
+	// verification fails with VERIFICATION_LIMIT_EXCEEDED if the graph has more
+	// artifacts. 0 verifies all artifacts.
+	MaxVerificationCount int `json:"maxVerificationCount,omitempty"`
+	// MaxConcurrentReferrers limits the number of referrers of a subject verified
+	// at the same time, 0 verifies all referrers at the same time.
+	MaxConcurrentReferrers int `json:"maxConcurrentReferrers,omitempty"`
+	// FailFast stops verifying the remaining artifacts of a subject as soon as a
+	// failure decides the overall result, e.g. if all verifiers must pass.
+	FailFast bool `json:"failFast,omitempty"`
+	// RequiredReferrerTypes are artifact types of which at least one referrer
+	// must be attached to the subject, regardless of whether a verifier is
+	// configured for them. Verification fails with REQUIRED_REFERRER_NOT_FOUND
+	// otherwise. Artifacts attached to referrers are not checked.
+	RequiredReferrerTypes []string `json:"requiredReferrerTypes,omitempty"`
+	// ReportVersion is the format of the verification reports returned to
+	// Gatekeeper, v1 or v2. Defaults to v1.
+	ReportVersion string `json:"reportVersion,omitempty"`
+	// MutationFailurePolicies select how the mutation endpoint handles tags the
+	// mutation store fails to resolve, e.g. during a registry outage. The first
+	// policy matching the registry of the image applies, images of other
+	// registries fail closed.
+	MutationFailurePolicies []MutationFailurePolicy `json:"mutationFailurePolicies,omitempty"`
+	// PluginPool limits the number of external plugin processes running at the same time
+	PluginPool pluginCommon.PoolConfig `json:"pluginPool,omitempty"`
+	// Priority separates the concurrency of the verifications of admission
+	// requests from those of audit requests, so that audit floods do not
+	// starve admission
+	Priority PriorityConfig `json:"priority,omitempty"`
 	// TODO Add cache config
 }
+
+// PriorityConfig limits the number of verifications of each request class
+// running at the same time. Audit verifications wait while admission
+// verifications are queued.
+type PriorityConfig struct {
+	// MaxConcurrentAdmission is the maximum number of verifications of
+	// admission requests running at the same time, 0 means unlimited.
+	MaxConcurrentAdmission int `json:"maxConcurrentAdmission,omitempty"`
+	// MaxConcurrentAudit is the maximum number of verifications of audit
+	// requests running at the same time. Defaults to the number of CPUs.
+	MaxConcurrentAudit int `json:"maxConcurrentAudit,omitempty"`
+}
+
+// MutationFailurePolicy selects how the mutation endpoint handles tags of the
+// images of matching registries that the mutation store fails to resolve.
+type MutationFailurePolicy struct {
+	// Registry is a pattern of the registry hosts the policy applies to, as
+	// matched by path.Match, e.g. *.azurecr.io or * for all registries.
+	Registry string `json:"registry"`
+	// Policy is failClosed, failOpen or retry.
+	Policy string `json:"policy"`
+	// MaxRetries is the number of times the retry policy resolves the tag
+	// again. Defaults to 3.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// RetryBackoffMilliseconds is the delay before the first retry, doubled
+	// before each further retry. Defaults to 100.
+	RetryBackoffMilliseconds int `json:"retryBackoffMilliseconds,omitempty"`
+}
+
+// GetMaxRetries returns the number of retries of the retry policy.
+func (p MutationFailurePolicy) GetMaxRetries() int {
+	if p.MaxRetries > 0 {
+		return p.MaxRetries
+	}
+	return defaultMutationMaxRetries
+}
+
+// GetRetryBackoff returns the delay before the first retry of the retry policy.
+func (p MutationFailurePolicy) GetRetryBackoff() time.Duration {
+	if p.RetryBackoffMilliseconds > 0 {
+		return time.Duration(p.RetryBackoffMilliseconds) * time.Millisecond
+	}
+	return defaultMutationRetryBackoffMilliseconds * time.Millisecond
+}
+
+// MatchMutationFailurePolicy returns the first policy matching the registry,
+// or a fail closed policy if no policy matches.
+func MatchMutationFailurePolicy(policies []MutationFailurePolicy, registry string) MutationFailurePolicy {
+	for _, policy := range policies {
+		if ok, _ := path.Match(policy.Registry, registry); ok {
+			return policy
+		}
+	}
+	return MutationFailurePolicy{Registry: registry, Policy: MutationFailClosed}
+}
+
+// Validate returns an error if the executor configuration is invalid.
+func (c ExecutorConfig) Validate() error {
+	switch c.ReportVersion {
+	case "", ReportVersionV1, ReportVersionV2:
+	default:
+		return fmt.Errorf("report version must be %s or %s, got %s", ReportVersionV1, ReportVersionV2, c.ReportVersion)
+	}
+	for _, policy := range c.MutationFailurePolicies {
+		if _, err := path.Match(policy.Registry, ""); err != nil || policy.Registry == "" {
+			return fmt.Errorf("invalid registry pattern %q of mutation failure policy", policy.Registry)
+		}
+		switch policy.Policy {
+		case MutationFailClosed, MutationFailOpen, MutationRetry:
+		default:
+			return fmt.Errorf("mutation failure policy of registry %s must be %s, %s or %s, got %s", policy.Registry, MutationFailClosed, MutationFailOpen, MutationRetry, policy.Policy)
+		}
+		if policy.MaxRetries < 0 || policy.RetryBackoffMilliseconds < 0 {
+			return fmt.Errorf("retries and backoff of the mutation failure policy of registry %s must not be negative", policy.Registry)
+		}
+	}
+	if c.Priority.MaxConcurrentAdmission < 0 || c.Priority.MaxConcurrentAudit < 0 {
+		return fmt.Errorf("maximum concurrent verifications of the priority config must not be negative")
+	}
+	return nil
+}
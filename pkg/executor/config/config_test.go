@@ -0,0 +1,103 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+)
+
+func TestExecutorConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    ExecutorConfig
+		expectErr bool
+	}{
+		{
+			name:   "empty config",
+			config: ExecutorConfig{},
+		},
+		{
+			name:      "unsupported report version",
+			config:    ExecutorConfig{ReportVersion: "v3"},
+			expectErr: true,
+		},
+		{
+			name: "mutation failure policies",
+			config: ExecutorConfig{MutationFailurePolicies: []MutationFailurePolicy{
+				{Registry: "*.azurecr.io", Policy: MutationRetry, MaxRetries: 2},
+				{Registry: "*", Policy: MutationFailOpen},
+			}},
+		},
+		{
+			name:      "invalid registry pattern",
+			config:    ExecutorConfig{MutationFailurePolicies: []MutationFailurePolicy{{Registry: "[", Policy: MutationFailOpen}}},
+			expectErr: true,
+		},
+		{
+			name:      "missing registry pattern",
+			config:    ExecutorConfig{MutationFailurePolicies: []MutationFailurePolicy{{Policy: MutationFailOpen}}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported policy",
+			config:    ExecutorConfig{MutationFailurePolicies: []MutationFailurePolicy{{Registry: "*", Policy: "ignore"}}},
+			expectErr: true,
+		},
+		{
+			name:      "negative retries",
+			config:    ExecutorConfig{MutationFailurePolicies: []MutationFailurePolicy{{Registry: "*", Policy: MutationRetry, MaxRetries: -1}}},
+			expectErr: true,
+		},
+		{
+			name:   "priority limits",
+			config: ExecutorConfig{Priority: PriorityConfig{MaxConcurrentAdmission: 8, MaxConcurrentAudit: 2}},
+		},
+		{
+			name:      "negative audit limit",
+			config:    ExecutorConfig{Priority: PriorityConfig{MaxConcurrentAudit: -1}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.config.Validate(); tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestMatchMutationFailurePolicy(t *testing.T) {
+	policies := []MutationFailurePolicy{
+		{Registry: "*.azurecr.io", Policy: MutationRetry},
+		{Registry: "docker.io", Policy: MutationFailOpen},
+	}
+	testCases := []struct {
+		registry string
+		expected string
+	}{
+		{registry: "myregistry.azurecr.io", expected: MutationRetry},
+		{registry: "docker.io", expected: MutationFailOpen},
+		{registry: "ghcr.io", expected: MutationFailClosed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.registry, func(t *testing.T) {
+			if policy := MatchMutationFailurePolicy(policies, tc.registry); policy.Policy != tc.expected {
+				t.Fatalf("expected policy %s, got %s", tc.expected, policy.Policy)
+			}
+		})
+	}
+}
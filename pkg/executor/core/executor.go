@@ -18,6 +18,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,16 +33,20 @@ import (
 	"github.com/deislabs/ratify/pkg/policyprovider"
 	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
 	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/inline"
 	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
+	"github.com/deislabs/ratify/pkg/tracing"
 	"github.com/deislabs/ratify/pkg/utils"
 	vr "github.com/deislabs/ratify/pkg/verifier"
 	vt "github.com/deislabs/ratify/pkg/verifier/types"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
 )
 
 const (
 	defaultVerifyRequestTimeoutMilliseconds = 2900
 	defaultMutateRequestTimeoutMilliseconds = 950
+	defaultMaxNestedDepth                   = 3
 )
 
 var logOpt = logger.Option{
@@ -55,23 +60,76 @@ type Executor struct {
 	PolicyEnforcer policyprovider.PolicyProvider
 	Verifiers      []vr.ReferenceVerifier
 	Config         *config.ExecutorConfig
+	// ConfigGeneration is incremented whenever the configuration the executor
+	// is created from changes.
+	ConfigGeneration int64
 }
 
 // TODO Logging within executor
 // VerifySubject verifies the subject and returns results.
 func (executor Executor) VerifySubject(ctx context.Context, verifyParameters e.VerifyParameters) (types.VerifyResult, error) {
+	ctx, release, err := Schedule(ctx)
+	if err != nil {
+		return types.VerifyResult{}, err
+	}
+	defer release()
+	ctx = pt.WithOperation(ctx, verifyParameters.Operation)
+	if subjectReference, err := utils.ParseSubjectReference(verifyParameters.Subject); err == nil {
+		ctx = pt.WithRepository(ctx, subjectReference.Path)
+	}
+	if verifyParameters.VerificationTime != nil {
+		ctx = vr.WithVerificationTime(ctx, *verifyParameters.VerificationTime)
+	}
+	ctx, span := tracing.StartSpan(ctx, "executor.VerifySubject", tracing.SubjectKey.String(verifyParameters.Subject))
 	result, err := executor.verifySubjectInternal(ctx, verifyParameters)
 	if err != nil {
 		// get the result for the error based on the policy.
 		// Do we need to consider no referrers as success or failure?
 		result = executor.PolicyEnforcer.ErrorToVerifyResult(ctx, verifyParameters.Subject, err)
 	}
+	span.SetAttributes(tracing.SuccessKey.Bool(result.IsSuccess))
+	tracing.EndSpan(span, err)
 	if executor.PolicyEnforcer.GetPolicyType(ctx) == pt.ConfigPolicy {
 		return result, nil
 	}
 	return result, err
 }
 
+// VerifyContent verifies a subject using only the manifests and blobs supplied
+// by the caller instead of the configured referrer stores, e.g. to validate
+// signatures before the artifacts are pushed to a registry. If no subject is
+// given in the parameters, the supplied subject manifest is referenced by digest.
+// Plugin verifiers read content through their own store configuration and
+// therefore cannot verify supplied content.
+func (executor Executor) VerifyContent(ctx context.Context, verifyParameters e.VerifyParameters, content inline.Content) (types.VerifyResult, error) {
+	store, err := inline.NewStore(content)
+	if err != nil {
+		return types.VerifyResult{}, err
+	}
+	if verifyParameters.Subject == "" {
+		verifyParameters.Subject = content.SubjectReference("")
+	}
+	executor.ReferrerStores = []referrerstore.ReferrerStore{store}
+	return executor.VerifySubject(ctx, verifyParameters)
+}
+
+// ResolveSubject returns the reference of the subject by digest, the tag of the
+// subject is resolved with the referrer stores.
+func (executor Executor) ResolveSubject(ctx context.Context, subject string) (string, error) {
+	subjectReference, err := utils.ParseSubjectReference(subject)
+	if err != nil {
+		return "", err
+	}
+	if subjectReference.Digest == "" {
+		desc, err := su.ResolveSubjectDescriptor(ctx, &executor.ReferrerStores, subjectReference)
+		if err != nil {
+			return "", err
+		}
+		subjectReference.Digest = desc.Digest
+	}
+	return fmt.Sprintf("%s@%s", subjectReference.Path, subjectReference.Digest), nil
+}
+
 // verifySubjectInternal verifies the subject with results.
 func (executor Executor) verifySubjectInternal(ctx context.Context, verifyParameters e.VerifyParameters) (types.VerifyResult, error) {
 	verifierReports, err := executor.verifySubjectInternalWithoutDecision(ctx, verifyParameters)
@@ -87,7 +145,10 @@ func (executor Executor) verifySubjectInternal(ctx context.Context, verifyParame
 	// OverallVerifyResult to evaluate the overall result based on the policy.
 	// NOTE: if Passthrough Mode is enabled, executor will just return the
 	// VerifierReports without evaluating the policy.
+	_, span := tracing.StartSpan(ctx, "policy.OverallVerifyResult", tracing.PolicyTypeKey.String(executor.PolicyEnforcer.GetPolicyType(ctx)))
 	overallVerifySuccess := executor.PolicyEnforcer.OverallVerifyResult(ctx, verifierReports)
+	span.SetAttributes(tracing.SuccessKey.Bool(overallVerifySuccess))
+	tracing.EndSpan(span, nil)
 	return types.VerifyResult{IsSuccess: overallVerifySuccess, VerifierReports: verifierReports}, nil
 }
 
@@ -107,88 +168,216 @@ func (executor Executor) verifySubjectInternalWithoutDecision(ctx context.Contex
 	logger.GetLogger(ctx, logOpt).Infof("Resolve of the image completed successfully the digest is %s", desc.Digest)
 
 	subjectReference.Digest = desc.Digest
-
-	verifierReports := make([]interface{}, 0)
+	ctx = executor.withVerificationBudget(withNestedAncestor(ctx, desc.Digest))
+
+	// reports are collected by the position of the referrer in the listing of
+	// its store, so that the aggregated reports do not depend on the order the
+	// concurrent verifications complete in
+	storeReports := make([]map[int][]interface{}, len(executor.ReferrerStores))
+	storeReferrers := make([]int, len(executor.ReferrerStores))
+	// decided is set once a failure decides the overall result in fail-fast
+	// mode, the outstanding verifications are then canceled and discarded.
+	decided := false
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	eg, errCtx := errgroup.WithContext(ctx)
 	var mu sync.Mutex
+	isDecided := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return decided
+	}
+	// the limit applies per subject, nested subjects are verified with their own
+	// limit so that they do not wait for the referrers of their parent
+	var limiter *semaphore.Weighted
+	if limit := executor.GetMaxConcurrentReferrers(); limit > 0 {
+		limiter = semaphore.NewWeighted(int64(limit))
+	}
 
-	for _, referrerStore := range executor.ReferrerStores {
-		referrerStore := referrerStore
+	filter := referrerstore.ReferrerFilter{ArtifactTypes: verifyParameters.ReferenceTypes, Annotations: verifyParameters.ReferenceAnnotations}
+	// artifact types of the listed referrers, including referrers no verifier
+	// applies to, to detect missing required referrers
+	listedTypes := map[string]struct{}{}
+	for i, referrerStore := range executor.ReferrerStores {
+		i, referrerStore := i, referrerStore
+		storeReports[i] = map[int][]interface{}{}
 		eg.Go(func() error {
 			var continuationToken string
+			// stopErr stops listing and verifying the referrers of the store
+			var stopErr error
+			referrerIndex := 0
+			defer func() {
+				storeReferrers[i] = referrerIndex
+			}()
 			innerGroup, innerErrCtx := errgroup.WithContext(errCtx)
 			for {
-				referrersResult, err := referrerStore.ListReferrers(errCtx, subjectReference, verifyParameters.ReferenceTypes, continuationToken, desc)
+				referrersResult, err := referrerstore.ListFilteredReferrers(errCtx, referrerStore, subjectReference, filter, continuationToken, desc)
+				if isDecided() {
+					break
+				}
 				if err != nil {
 					return errors.ErrorCodeListReferrersFailure.NewError(errors.ReferrerStore, referrerStore.Name(), errors.EmptyLink, err, nil, errors.HideStackTrace)
 				}
 				continuationToken = referrersResult.NextToken
+				mu.Lock()
+				for _, reference := range referrersResult.Referrers {
+					listedTypes[reference.ArtifactType] = struct{}{}
+				}
+				mu.Unlock()
 				for _, reference := range referrersResult.Referrers {
 					if !executor.PolicyEnforcer.VerifyNeeded(innerErrCtx, subjectReference, reference) {
 						continue
 					}
-					reference := reference
+					if stopErr = checkReferrerCycle(innerErrCtx, reference.Digest); stopErr != nil {
+						break
+					}
+					if stopErr = consumeVerification(innerErrCtx); stopErr != nil {
+						break
+					}
+					if limiter != nil {
+						if stopErr = limiter.Acquire(innerErrCtx, 1); stopErr != nil {
+							break
+						}
+					}
+					reference, index := reference, referrerIndex
+					referrerIndex++
 					innerGroup.Go(func() error {
+						if limiter != nil {
+							defer limiter.Release(1)
+						}
 						if executor.PolicyEnforcer.GetPolicyType(ctx) == pt.RegoPolicy {
 							verifyResult, err := executor.verifyReferenceForRegoPolicy(innerErrCtx, subjectReference, reference, referrerStore)
 							if err != nil {
 								logger.GetLogger(ctx, logOpt).Errorf("error while verifying reference %+v, err: %v", reference, err)
 								return err
 							}
-							mu.Lock() // locks the verifierReports List for write safety
+							mu.Lock() // locks the reports for write safety
 							defer mu.Unlock()
-							verifierReports = append(verifierReports, verifyResult)
+							storeReports[i][index] = []interface{}{verifyResult}
 						} else {
 							verifyResult := executor.verifyReferenceForJSONPolicy(innerErrCtx, subjectReference, reference, referrerStore)
-							mu.Lock() // locks the verifierReports List for write safety
+							mu.Lock() // locks the reports for write safety
 							defer mu.Unlock()
-							verifierReports = append(verifierReports, verifyResult.VerifierReports...)
+							if decided {
+								return nil
+							}
+							storeReports[i][index] = verifyResult.VerifierReports
+							if executor.failFast(ctx, subjectReference, reference, verifyResult) {
+								logger.GetLogger(ctx, logOpt).Infof("verification of reference %s failed, skipping remaining verifications of subject %s", reference.Digest, subjectReference.String())
+								decided = true
+								cancel()
+							}
 						}
 						return nil
 					})
 				}
-				if continuationToken == "" {
+				if continuationToken == "" || stopErr != nil {
 					break
 				}
 			}
-			return innerGroup.Wait()
+			if err := innerGroup.Wait(); err != nil {
+				return err
+			}
+			// waiting for the limiter fails if the outcome is decided or the request times out
+			if isDecided() {
+				return nil
+			}
+			return stopErr
 		})
 	}
 
 	if err = eg.Wait(); err != nil {
 		return nil, err
 	}
+	// the listing is incomplete if the result is decided early
+	if !decided && nestedDepth(ctx) == 1 {
+		if missing := executor.missingReferrerTypes(filter, listedTypes); len(missing) > 0 {
+			return nil, errors.ErrorCodeRequiredReferrerNotFound.WithComponentType(errors.Executor).WithDetail(fmt.Sprintf("no referrers of artifact types %s are attached to subject %s", strings.Join(missing, ", "), subjectReference.String()))
+		}
+	}
 
+	verifierReports := make([]interface{}, 0)
+	for i, reports := range storeReports {
+		for index := 0; index < storeReferrers[i]; index++ {
+			verifierReports = append(verifierReports, reports[index]...)
+		}
+	}
 	return verifierReports, nil
 }
 
+// missingReferrerTypes returns the required referrer types selected by the
+// filter of the request that none of the listed referrers has.
+func (executor Executor) missingReferrerTypes(filter referrerstore.ReferrerFilter, listedTypes map[string]struct{}) []string {
+	if executor.Config == nil {
+		return nil
+	}
+	var missing []string
+	for _, artifactType := range executor.Config.RequiredReferrerTypes {
+		if !filter.MatchesArtifactType(artifactType) {
+			continue
+		}
+		if _, ok := listedTypes[artifactType]; !ok {
+			missing = append(missing, artifactType)
+		}
+	}
+	return missing
+}
+
 // verifyReferenceForJSONPolicy verifies the referenced artifact with results
 // used for the Json-based policy enforcer.
 func (executor Executor) verifyReferenceForJSONPolicy(ctx context.Context, subjectRef common.Reference, referenceDesc ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) types.VerifyResult {
 	var verifyResults []interface{}
 	var isSuccess = true
 
-	for _, verifier := range executor.Verifiers {
-		if verifier.CanVerify(ctx, referenceDesc) {
-			verifierStartTime := time.Now()
-			verifyResult, err := verifier.Verify(ctx, subjectRef, referenceDesc, referrerStore)
+	verifiers, err := executor.jsonPolicyVerifiers(ctx, referenceDesc)
+	if err != nil {
+		verifyResult := vr.VerifierResult{
+			Subject:         subjectRef.String(),
+			IsSuccess:       false,
+			Message:         err.Error(),
+			ArtifactType:    referenceDesc.ArtifactType,
+			ReferenceDigest: referenceDesc.Digest.String(),
+			ErrorCode:       errors.CodeOf(err, errors.ErrorCodeConfigInvalid).String(),
+		}
+		return types.VerifyResult{IsSuccess: false, VerifierReports: []interface{}{verifyResult}}
+	}
+
+	// artifacts attached to the reference are verified once for all verifiers
+	// that declare nested references or if the policy requires it
+	nestedRequired := executor.PolicyEnforcer.NestedVerifyNeeded(ctx, referenceDesc)
+	var nestedResult *types.VerifyResult
+
+	failed := map[string]bool{}
+	for _, verifier := range verifiers {
+		var verifyResult vr.VerifierResult
+		if dependency := failedDependency(verifier, failed); dependency != "" {
+			verifyResult = skippedVerifierResult(verifier, dependency)
 			verifyResult.Subject = subjectRef.String()
+		} else {
+			verifierStartTime := time.Now()
+			verifyResult, err = verifyReference(ctx, verifier, subjectRef, referenceDesc, referrerStore)
+			verifiedAt := time.Now()
 			if err != nil {
-				verifyResult = vr.VerifierResult{
-					IsSuccess: false,
-					Name:      verifier.Name(),
-					Type:      verifier.Type(),
-					Message:   errors.ErrorCodeVerifyReferenceFailure.NewError(errors.Verifier, verifier.Name(), errors.EmptyLink, err, nil, errors.HideStackTrace).Error()}
+				verifyResult = verifierErrorResult(verifier, err)
 			}
+			verifyResult.Subject = subjectRef.String()
+			verifyResult.VerifiedAt = &verifiedAt
 
-			if len(verifier.GetNestedReferences()) > 0 {
-				executor.addNestedVerifierResult(ctx, referenceDesc, subjectRef, &verifyResult)
+			if nestedRequired || len(verifier.GetNestedReferences()) > 0 {
+				if nestedResult == nil {
+					nestedResult = executor.verifyNestedSubject(ctx, referenceDesc, subjectRef)
+				}
+				addNestedVerifierResult(*nestedResult, &verifyResult, referenceDesc.Digest.String())
 			}
+			metrics.ReportVerifierDuration(ctx, time.Since(verifierStartTime).Milliseconds(), verifier.Name(), subjectRef.String(), verifyResult.IsSuccess, err != nil)
+		}
 
-			verifyResult.ArtifactType = referenceDesc.ArtifactType
-			verifyResults = append(verifyResults, verifyResult)
-			isSuccess = verifyResult.IsSuccess
-			metrics.ReportVerifierDuration(ctx, time.Since(verifierStartTime).Milliseconds(), verifier.Name(), subjectRef.String(), isSuccess, err != nil)
+		verifyResult.ArtifactType = referenceDesc.ArtifactType
+		verifyResult.ReferenceDigest = referenceDesc.Digest.String()
+		verifyResults = append(verifyResults, verifyResult)
+		failed[verifier.Name()] = !verifyResult.IsSuccess
+		isSuccess = isSuccess && verifyResult.IsSuccess
+		if executor.failFast(ctx, subjectRef, referenceDesc, types.VerifyResult{IsSuccess: isSuccess, VerifierReports: verifyResults}) {
 			break
 		}
 	}
@@ -197,7 +386,8 @@ func (executor Executor) verifyReferenceForJSONPolicy(ctx context.Context, subje
 }
 
 // verifyReferenceForRegoPolicy verifies the referenced artifact with results
-// used for Rego-based policy enforcer.
+// used for Rego-based policy enforcer. Verifiers run in stages following their
+// declared dependencies, verifiers within a stage run concurrently.
 func (executor Executor) verifyReferenceForRegoPolicy(ctx context.Context, subjectRef common.Reference, referenceDesc ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (types.NestedVerifierReport, error) {
 	nestedReport := types.NestedVerifierReport{
 		Subject:         subjectRef.String(),
@@ -206,6 +396,11 @@ func (executor Executor) verifyReferenceForRegoPolicy(ctx context.Context, subje
 		VerifierReports: make([]vt.VerifierResult, 0),
 		NestedReports:   make([]types.NestedVerifierReport, 0),
 	}
+	stages, err := executor.executionStages(ctx, referenceDesc)
+	if err != nil {
+		return types.NestedVerifierReport{}, err
+	}
+
 	var mu sync.Mutex
 	eg, errCtx := errgroup.WithContext(ctx)
 
@@ -213,33 +408,62 @@ func (executor Executor) verifyReferenceForRegoPolicy(ctx context.Context, subje
 		return executor.addNestedReports(errCtx, referenceDesc, subjectRef, &nestedReport)
 	})
 
-	for _, verifier := range executor.Verifiers {
-		if !verifier.CanVerify(ctx, referenceDesc) {
-			continue
-		}
-		verifier := verifier
-		eg.Go(func() error {
-			var verifierReport vt.VerifierResult
-			verifierStartTime := time.Now()
-			verifierResult, err := verifier.Verify(errCtx, subjectRef, referenceDesc, referrerStore)
-			if err != nil {
-				verifierReport = vt.VerifierResult{
-					IsSuccess: false,
-					Name:      verifier.Name(),
-					Type:      verifier.Type(),
-					Message:   errors.ErrorCodeVerifyReferenceFailure.NewError(errors.Verifier, verifier.Name(), errors.EmptyLink, err, nil, errors.HideStackTrace).Error()}
-			} else {
-				verifierReport = vt.NewVerifierResult(verifierResult)
+	eg.Go(func() error {
+		failed := map[string]bool{}
+		for _, stage := range stages {
+			var wg sync.WaitGroup
+			stageFailed := map[string]bool{}
+			// reports are kept in the execution order of the verifiers
+			stageReports := make([]vt.VerifierResult, len(stage))
+			for i, verifier := range stage {
+				if dependency := failedDependency(verifier, failed); dependency != "" {
+					mu.Lock()
+					stageReports[i] = vt.NewVerifierResult(skippedVerifierResult(verifier, dependency))
+					stageFailed[verifier.Name()] = true
+					mu.Unlock()
+					continue
+				}
+				i, verifier := i, verifier
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var verifierReport vt.VerifierResult
+					verifierStartTime := time.Now()
+					verifierResult, err := verifyReference(errCtx, verifier, subjectRef, referenceDesc, referrerStore)
+					verifiedAt := time.Now()
+					if err != nil {
+						errorResult := verifierErrorResult(verifier, err)
+						verifierReport = vt.VerifierResult{
+							IsSuccess:    false,
+							Inconclusive: errorResult.Inconclusive,
+							Name:         errorResult.Name,
+							Type:         errorResult.Type,
+							Message:      errorResult.Message,
+							ErrorCode:    errorResult.ErrorCode}
+					} else {
+						verifierReport = vt.NewVerifierResult(verifierResult)
+					}
+					verifierReport.VerifiedAt = &verifiedAt
+
+					mu.Lock()
+					stageReports[i] = verifierReport
+					stageFailed[verifier.Name()] = !verifierReport.IsSuccess
+					mu.Unlock()
+
+					metrics.ReportVerifierDuration(errCtx, time.Since(verifierStartTime).Milliseconds(), verifier.Name(), subjectRef.String(), verifierReport.IsSuccess, err != nil)
+				}()
 			}
+			wg.Wait()
 
 			mu.Lock()
-			nestedReport.VerifierReports = append(nestedReport.VerifierReports, verifierReport)
+			nestedReport.VerifierReports = append(nestedReport.VerifierReports, stageReports...)
 			mu.Unlock()
-
-			metrics.ReportVerifierDuration(errCtx, time.Since(verifierStartTime).Milliseconds(), verifier.Name(), subjectRef.String(), verifierReport.IsSuccess, err != nil)
-			return nil
-		})
-	}
+			for name, isFailed := range stageFailed {
+				failed[name] = isFailed
+			}
+		}
+		return nil
+	})
 
 	if err := eg.Wait(); err != nil {
 		return types.NestedVerifierReport{}, err
@@ -247,21 +471,76 @@ func (executor Executor) verifyReferenceForRegoPolicy(ctx context.Context, subje
 	return nestedReport, nil
 }
 
-// addNestedVerifierResult adds the nested verifier result to the parent verify
-// result used for Json-based policy enforcer.
-func (executor Executor) addNestedVerifierResult(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor, subjectRef common.Reference, verifyResult *vr.VerifierResult) {
+// failFast returns true if fail-fast mode is enabled and the failed result of
+// the reference decides the overall result, so the remaining verifications can
+// be skipped.
+func (executor Executor) failFast(ctx context.Context, subjectRef common.Reference, referenceDesc ocispecs.ReferenceDescriptor, verifyResult types.VerifyResult) bool {
+	if executor.Config == nil || !executor.Config.FailFast || verifyResult.IsSuccess {
+		return false
+	}
+	return !executor.PolicyEnforcer.ContinueVerifyOnFailure(ctx, subjectRef, referenceDesc, verifyResult)
+}
+
+// verifyReference verifies the referenced artifact with the verifier in a span
+// of the trace of the request.
+func verifyReference(ctx context.Context, verifier vr.ReferenceVerifier, subjectRef common.Reference, referenceDesc ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (vr.VerifierResult, error) {
+	ctx, span := tracing.StartSpan(ctx, "verifier.Verify",
+		tracing.VerifierKey.String(verifier.Name()),
+		tracing.ArtifactTypeKey.String(referenceDesc.ArtifactType),
+		tracing.ArtifactDigestKey.String(referenceDesc.Digest.String()))
+	result, err := verifier.Verify(ctx, subjectRef, referenceDesc, referrerStore)
+	span.SetAttributes(tracing.SuccessKey.Bool(err == nil && result.IsSuccess))
+	tracing.EndSpan(span, err)
+	return result, err
+}
+
+// verifierErrorResult converts the error returned by the verifier to a failed
+// result, the result is inconclusive if the verifier could not run.
+func verifierErrorResult(verifier vr.ReferenceVerifier, err error) vr.VerifierResult {
+	if vr.IsInconclusive(err) {
+		return vr.NewInconclusiveResult(verifier, err)
+	}
+	return vr.VerifierResult{
+		IsSuccess: false,
+		Name:      verifier.Name(),
+		Type:      verifier.Type(),
+		Message:   errors.ErrorCodeVerifyReferenceFailure.NewError(errors.Verifier, verifier.Name(), errors.EmptyLink, err, nil, errors.HideStackTrace).Error(),
+		ErrorCode: errors.CodeOf(err, errors.ErrorCodeVerifyReferenceFailure).String()}
+}
+
+// verifyNestedSubject verifies the artifacts attached to the referenced
+// artifact, the result is empty if the maximum nested depth is reached.
+func (executor Executor) verifyNestedSubject(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor, subjectRef common.Reference) *types.VerifyResult {
 	verifyParameters := e.VerifyParameters{
 		Subject:        fmt.Sprintf("%s@%s", subjectRef.Path, referenceDesc.Digest),
 		ReferenceTypes: []string{"*"},
 	}
+	ctx, ok := executor.nestedContext(ctx, verifyParameters.Subject)
+	if !ok {
+		return &types.VerifyResult{}
+	}
 
 	nestedVerifyResult, err := executor.VerifySubject(ctx, verifyParameters)
 	if err != nil {
 		nestedVerifyResult = executor.PolicyEnforcer.ErrorToVerifyResult(ctx, verifyParameters.Subject, err)
 	}
+	return &nestedVerifyResult
+}
 
+// addNestedVerifierResult adds the nested verifier result to the parent verify
+// result used for Json-based policy enforcer. Nested results not produced for
+// the verified artifact fail the parent result.
+func addNestedVerifierResult(nestedVerifyResult types.VerifyResult, verifyResult *vr.VerifierResult, referenceDigest string) {
+	verifyResult.LinkedDigest = referenceDigest
 	for _, report := range nestedVerifyResult.VerifierReports {
 		if result, ok := report.(vr.VerifierResult); ok {
+			if digest := result.SubjectDigest(); digest != referenceDigest {
+				result.IsSuccess = false
+				result.Message = fmt.Sprintf("nested result is for artifact %s, expected %s", digest, referenceDigest)
+				result.ErrorCode = errors.ErrorCodeArtifactLinkageMismatch.String()
+				verifyResult.IsSuccess = false
+				verifyResult.Message = "nested verification failed"
+			}
 			verifyResult.NestedResults = append(verifyResult.NestedResults, result)
 			if !nestedVerifyResult.IsSuccess {
 				verifyResult.IsSuccess = false
@@ -278,6 +557,10 @@ func (executor Executor) addNestedReports(ctx context.Context, referenceDes ocis
 		Subject:        fmt.Sprintf("%s@%s", subjectRef.Path, referenceDes.Digest),
 		ReferenceTypes: []string{"*"},
 	}
+	ctx, ok := executor.nestedContext(ctx, verifyParameters.Subject)
+	if !ok {
+		return nil
+	}
 
 	// get nested reports.
 	reports, err := executor.verifySubjectInternal(ctx, verifyParameters)
@@ -306,6 +589,33 @@ func (executor Executor) GetVerifyRequestTimeout() time.Duration {
 	return time.Duration(timeoutMilliSeconds) * time.Millisecond
 }
 
+// GetMaxNestedDepth returns the number of levels of the referrer graph below the
+// subject that are verified.
+func (executor Executor) GetMaxNestedDepth() int {
+	if executor.Config != nil && executor.Config.MaxNestedDepth != nil {
+		return *executor.Config.MaxNestedDepth
+	}
+	return defaultMaxNestedDepth
+}
+
+// GetMaxConcurrentReferrers returns the number of referrers of a subject that
+// are verified at the same time, 0 if unlimited.
+func (executor Executor) GetMaxConcurrentReferrers() int {
+	if executor.Config != nil {
+		return executor.Config.MaxConcurrentReferrers
+	}
+	return 0
+}
+
+// GetReportVersion returns the format of the verification reports returned to
+// Gatekeeper.
+func (executor Executor) GetReportVersion() string {
+	if executor.Config != nil && executor.Config.ReportVersion != "" {
+		return executor.Config.ReportVersion
+	}
+	return config.ReportVersionV1
+}
+
 func (executor Executor) GetMutationRequestTimeout() time.Duration {
 	timeoutMilliSeconds := defaultMutateRequestTimeoutMilliseconds
 	if executor.Config != nil && executor.Config.MutationRequestTimeout != nil {
@@ -313,3 +623,12 @@ func (executor Executor) GetMutationRequestTimeout() time.Duration {
 	}
 	return time.Duration(timeoutMilliSeconds) * time.Millisecond
 }
+
+// GetMutationFailurePolicies returns the policies handling tags the mutation
+// store fails to resolve.
+func (executor Executor) GetMutationFailurePolicies() []config.MutationFailurePolicy {
+	if executor.Config == nil {
+		return nil
+	}
+	return executor.Config.MutationFailurePolicies
+}
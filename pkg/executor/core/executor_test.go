@@ -17,8 +17,11 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,6 +37,7 @@ import (
 	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	storeConfig "github.com/deislabs/ratify/pkg/referrerstore/config"
+	"github.com/deislabs/ratify/pkg/referrerstore/inline"
 	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
 	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/opencontainers/go-digest"
@@ -50,14 +54,19 @@ const (
 )
 
 type mockPolicyProvider struct {
-	result     bool
-	policyType string
+	result             bool
+	policyType         string
+	nestedVerifyNeeded bool
 }
 
 func (p *mockPolicyProvider) VerifyNeeded(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor) bool {
 	return true
 }
 
+func (p *mockPolicyProvider) NestedVerifyNeeded(_ context.Context, _ ocispecs.ReferenceDescriptor) bool {
+	return p.nestedVerifyNeeded
+}
+
 func (p *mockPolicyProvider) ContinueVerifyOnFailure(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ types.VerifyResult) bool {
 	return true
 }
@@ -326,6 +335,53 @@ func TestVerifySubjectInternal_VerifyFailures_ExpectedResults(t *testing.T) {
 	}
 }
 
+func TestVerifySubjectInternal_InconclusiveVerifier(t *testing.T) {
+	store := &mocks.TestStore{
+		References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType1}},
+		ResolveMap: map[string]digest.Digest{"v1": digest.FromString("test")},
+	}
+	ver := &TestVerifier{
+		CanVerifyFunc: func(string) bool { return true },
+		VerifyErr:     ratifyerrors.ErrorCodePluginNotFound.NewError(ratifyerrors.Verifier, "testVerifier", ratifyerrors.EmptyLink, nil, nil, ratifyerrors.HideStackTrace),
+	}
+
+	testCases := []struct {
+		inconclusivePolicy policyTypes.InconclusivePolicy
+		isSuccess          bool
+	}{
+		{inconclusivePolicy: policyTypes.InconclusiveFail, isSuccess: false},
+		{inconclusivePolicy: policyTypes.InconclusiveWarn, isSuccess: true},
+		{inconclusivePolicy: policyTypes.InconclusiveIgnore, isSuccess: false},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.inconclusivePolicy), func(t *testing.T) {
+			ex := &Executor{
+				PolicyEnforcer: policyConfig.PolicyEnforcer{
+					ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{"default": policyTypes.AllVerifySuccess},
+					InconclusivePolicy:   tc.inconclusivePolicy,
+				},
+				ReferrerStores: []referrerstore.ReferrerStore{store},
+				Verifiers:      []verifier.ReferenceVerifier{ver},
+				Config:         &exConfig.ExecutorConfig{},
+			}
+
+			result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: subject1})
+			if err != nil {
+				t.Fatalf("verification failed with err %v", err)
+			}
+			if result.IsSuccess != tc.isSuccess {
+				t.Fatalf("expected success %v, got %v", tc.isSuccess, result.IsSuccess)
+			}
+			if len(result.VerifierReports) != 1 {
+				t.Fatalf("expected 1 report, got %d", len(result.VerifierReports))
+			}
+			if report := result.VerifierReports[0].(verifier.VerifierResult); !report.Inconclusive || report.IsSuccess {
+				t.Fatalf("expected inconclusive report, got %+v", report)
+			}
+		})
+	}
+}
+
 func TestVerifySubjectInternal_VerifySuccess_ExpectedResults(t *testing.T) {
 	testDigest := digest.FromString("test")
 	configPolicy := policyConfig.PolicyEnforcer{
@@ -406,9 +462,7 @@ func TestVerifySubjectInternalWithDecision_MultipleArtifacts_ExpectedResults(t *
 			return true
 		},
 		VerifyResult: func(artifactType string) bool {
-			if artifactType == testArtifactType1 {
-				time.Sleep(2 * time.Second)
-			}
+			time.Sleep(time.Second)
 			return true
 		},
 	}
@@ -427,7 +481,11 @@ func TestVerifySubjectInternalWithDecision_MultipleArtifacts_ExpectedResults(t *
 		Subject: "localhost:5000/net-monitor:v1",
 	}
 
+	start := time.Now()
 	result, err := ex.verifySubjectInternal(context.Background(), verifyParameters)
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Fatalf("verification expected to verify artifacts concurrently, took %s", elapsed)
+	}
 
 	if err != nil {
 		t.Fatalf("verification failed with err %v", err)
@@ -441,8 +499,68 @@ func TestVerifySubjectInternalWithDecision_MultipleArtifacts_ExpectedResults(t *
 		t.Fatalf("verification expected to return two reports but actual count %d", len(result.VerifierReports))
 	}
 
-	if result.VerifierReports[0].(verifier.VerifierResult).ArtifactType != "test-type2" {
-		t.Fatalf("verification expected to return second artifact verifier report first")
+	if result.VerifierReports[0].(verifier.VerifierResult).ArtifactType != testArtifactType1 {
+		t.Fatalf("verification expected to return reports in the order of the referrers")
+	}
+}
+
+// TestVerifySubjectInternal_MaxConcurrentReferrers_ExpectedResults tests the number of referrers verified at the same time is limited
+func TestVerifySubjectInternal_MaxConcurrentReferrers_ExpectedResults(t *testing.T) {
+	configPolicy := policyConfig.PolicyEnforcer{
+		ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+			"default": policyTypes.AllVerifySuccess,
+		}}
+	references := make([]ocispecs.ReferenceDescriptor, 0, 6)
+	for i := 0; i < 6; i++ {
+		references = append(references, ocispecs.ReferenceDescriptor{
+			ArtifactType: testArtifactType1,
+			Descriptor:   oci.Descriptor{Digest: digest.FromString(fmt.Sprint(i))},
+		})
+	}
+	store := &mocks.TestStore{
+		References: references,
+		ResolveMap: map[string]digest.Digest{"v1": subjectDigest},
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	ver := &TestVerifier{
+		CanVerifyFunc: func(at string) bool {
+			return true
+		},
+		VerifyResult: func(artifactType string) bool {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return true
+		},
+	}
+
+	ex := &Executor{
+		PolicyEnforcer: configPolicy,
+		ReferrerStores: []referrerstore.ReferrerStore{store},
+		Verifiers:      []verifier.ReferenceVerifier{ver},
+		Config: &exConfig.ExecutorConfig{
+			MaxConcurrentReferrers: 2,
+		},
+	}
+
+	result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: subject1})
+	if err != nil {
+		t.Fatalf("verification failed with err %v", err)
+	}
+	if !result.IsSuccess || len(result.VerifierReports) != len(references) {
+		t.Fatalf("expected %d successful reports, got %+v", len(references), result)
+	}
+	if maxRunning > 2 {
+		t.Fatalf("expected at most 2 referrers verified at the same time, got %d", maxRunning)
 	}
 }
 
@@ -595,6 +713,340 @@ func TestVerifySubjectInternal_NoNestedReferences_Expected(t *testing.T) {
 	}
 }
 
+// TestVerifySubjectInternal_NestedVerificationPolicy_Expected tests the policy can require nested artifacts to be verified
+func TestVerifySubjectInternal_NestedVerificationPolicy_Expected(t *testing.T) {
+	testcases := []struct {
+		name           string
+		maxNestedDepth int
+		isSuccess      bool
+	}{
+		{name: "nested signature verified", maxNestedDepth: 2, isSuccess: true},
+		{name: "maximum nested depth reached", maxNestedDepth: 1, isSuccess: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			configPolicy := policyConfig.PolicyEnforcer{
+				ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+					"default": "all",
+				},
+				NestedPolicies: map[string]policyTypes.NestedVerificationPolicy{
+					mocks.SbomArtifactType: {ArtifactTypes: []string{mocks.SignatureArtifactType}},
+				},
+			}
+
+			// sbom verifier WITHOUT nested references in config
+			sbomVerifier := &TestVerifier{
+				CanVerifyFunc: func(at string) bool {
+					return at == mocks.SbomArtifactType
+				},
+				VerifyResult: func(artifactType string) bool {
+					return true
+				},
+			}
+			signatureVerifier := &TestVerifier{
+				CanVerifyFunc: func(at string) bool {
+					return at == mocks.SignatureArtifactType
+				},
+				VerifyResult: func(artifactType string) bool {
+					return true
+				},
+			}
+
+			maxNestedDepth := testcase.maxNestedDepth
+			ex := &Executor{
+				PolicyEnforcer: configPolicy,
+				ReferrerStores: []referrerstore.ReferrerStore{mocks.CreateNewTestStoreForNestedSbom()},
+				Verifiers:      []verifier.ReferenceVerifier{sbomVerifier, signatureVerifier},
+				Config: &exConfig.ExecutorConfig{
+					MaxNestedDepth: &maxNestedDepth,
+				},
+			}
+
+			result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: mocks.TestSubjectWithDigest})
+			if err != nil {
+				t.Fatalf("verification failed with err %v", err)
+			}
+			if result.IsSuccess != testcase.isSuccess {
+				t.Fatalf("expected verification success %v, got %v", testcase.isSuccess, result.IsSuccess)
+			}
+
+			for _, report := range result.VerifierReports {
+				castedReport := report.(verifier.VerifierResult)
+				if castedReport.ArtifactType != mocks.SbomArtifactType {
+					continue
+				}
+				expectedNested := 0
+				if testcase.isSuccess {
+					expectedNested = 1
+				}
+				if len(castedReport.NestedResults) != expectedNested {
+					t.Fatalf("expected sbom report to have %d nested results, got %d", expectedNested, len(castedReport.NestedResults))
+				}
+			}
+		})
+	}
+}
+
+// blockingVerifier blocks until the verification is canceled.
+type blockingVerifier struct {
+	canceled chan struct{}
+}
+
+func (v *blockingVerifier) Name() string {
+	return "verifier-blocking"
+}
+
+func (v *blockingVerifier) Type() string {
+	return "blocking"
+}
+
+func (v *blockingVerifier) CanVerify(_ context.Context, referenceDescriptor ocispecs.ReferenceDescriptor) bool {
+	return referenceDescriptor.ArtifactType == testArtifactType2
+}
+
+func (v *blockingVerifier) Verify(ctx context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	<-ctx.Done()
+	close(v.canceled)
+	return verifier.VerifierResult{IsSuccess: false}, ctx.Err()
+}
+
+func (v *blockingVerifier) GetNestedReferences() []string {
+	return nil
+}
+
+func TestVerifySubjectInternal_FailFast_Expected(t *testing.T) {
+	configPolicy := policyConfig.PolicyEnforcer{
+		ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+			"default": "all",
+		},
+	}
+	failingVerifier := &TestVerifier{
+		CanVerifyFunc: func(at string) bool {
+			return at == testArtifactType1
+		},
+		VerifyResult: func(artifactType string) bool {
+			return false
+		},
+	}
+	slowVerifier := &blockingVerifier{canceled: make(chan struct{})}
+
+	ex := &Executor{
+		PolicyEnforcer: configPolicy,
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{
+				{ArtifactType: testArtifactType1},
+				{ArtifactType: testArtifactType2},
+			},
+			ResolveMap: map[string]digest.Digest{"v1": subjectDigest},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{failingVerifier, slowVerifier},
+		Config: &exConfig.ExecutorConfig{
+			FailFast: true,
+		},
+	}
+
+	result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: subject1})
+	if err != nil {
+		t.Fatalf("verification failed with err %v", err)
+	}
+	if result.IsSuccess {
+		t.Fatalf("expected verification to fail")
+	}
+	select {
+	case <-slowVerifier.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected outstanding verifier to be canceled")
+	}
+	if len(result.VerifierReports) != 1 || result.VerifierReports[0].(verifier.VerifierResult).ArtifactType != testArtifactType1 {
+		t.Fatalf("expected only the report of the failed verifier, got %+v", result.VerifierReports)
+	}
+}
+
+func TestVerifySubjectInternal_RequiredReferrerTypes_Expected(t *testing.T) {
+	configPolicy := policyConfig.PolicyEnforcer{
+		ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+			"default": "all",
+		},
+	}
+	signatureVerifier := &TestVerifier{
+		CanVerifyFunc: func(at string) bool {
+			return at == testArtifactType1
+		},
+		VerifyResult: func(artifactType string) bool {
+			return true
+		},
+	}
+	testCases := []struct {
+		name           string
+		requiredTypes  []string
+		referenceTypes []string
+		expectedErr    bool
+	}{
+		{
+			name:          "required referrer without verifier is attached",
+			requiredTypes: []string{testArtifactType1, testArtifactType2},
+		},
+		{
+			name:          "required referrer is missing",
+			requiredTypes: []string{testArtifactType1, "application/spdx+json"},
+			expectedErr:   true,
+		},
+		{
+			name:           "required referrer excluded by the reference types",
+			requiredTypes:  []string{"application/spdx+json"},
+			referenceTypes: []string{testArtifactType1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ex := &Executor{
+				PolicyEnforcer: configPolicy,
+				ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+					References: []ocispecs.ReferenceDescriptor{
+						{ArtifactType: testArtifactType1},
+						{ArtifactType: testArtifactType2},
+					},
+					ResolveMap: map[string]digest.Digest{"v1": subjectDigest},
+				}},
+				Verifiers: []verifier.ReferenceVerifier{signatureVerifier},
+				Config: &exConfig.ExecutorConfig{
+					RequiredReferrerTypes: tc.requiredTypes,
+				},
+			}
+
+			result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: subject1, ReferenceTypes: tc.referenceTypes})
+			if tc.expectedErr {
+				if !errors.Is(err, ratifyerrors.ErrorCodeRequiredReferrerNotFound.WithDetail("")) {
+					t.Fatalf("expected ErrorCodeRequiredReferrerNotFound, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verification failed with err %v", err)
+			}
+			if !result.IsSuccess {
+				t.Fatalf("expected verification to succeed")
+			}
+		})
+	}
+}
+
+func TestVerifySubjectInternal_ReferrerGraphLimits_Expected(t *testing.T) {
+	sbomDigest := digest.FromString("sbom")
+	signature := ocispecs.ReferenceDescriptor{ArtifactType: testArtifactType1, Descriptor: oci.Descriptor{Digest: digest.FromString("signature")}}
+	sbom := ocispecs.ReferenceDescriptor{ArtifactType: testArtifactType2, Descriptor: oci.Descriptor{Digest: sbomDigest}}
+	sbomSignature := ocispecs.ReferenceDescriptor{ArtifactType: testArtifactType1, Descriptor: oci.Descriptor{Digest: digest.FromString("sbom signature")}}
+	subjectAsReferrer := ocispecs.ReferenceDescriptor{ArtifactType: testArtifactType1, Descriptor: oci.Descriptor{Digest: subjectDigest}}
+
+	testCases := []struct {
+		name                 string
+		sbomReferrers        []ocispecs.ReferenceDescriptor
+		maxVerificationCount int
+		expectedErr          error
+		expectedNestedCode   string
+	}{
+		{
+			name:          "no limit",
+			sbomReferrers: []ocispecs.ReferenceDescriptor{sbomSignature},
+		},
+		{
+			name:                 "artifacts within limit",
+			sbomReferrers:        []ocispecs.ReferenceDescriptor{sbomSignature},
+			maxVerificationCount: 3,
+		},
+		{
+			name:                 "nested artifacts exceed limit",
+			sbomReferrers:        []ocispecs.ReferenceDescriptor{sbomSignature},
+			maxVerificationCount: 2,
+			expectedNestedCode:   ratifyerrors.ErrorCodeVerificationLimitExceeded.String(),
+		},
+		{
+			name:                 "referrers of subject exceed limit",
+			sbomReferrers:        []ocispecs.ReferenceDescriptor{sbomSignature},
+			maxVerificationCount: 1,
+			expectedErr:          ratifyerrors.ErrorCodeVerificationLimitExceeded.WithDetail(""),
+		},
+		{
+			name:               "subject listed as referrer of nested artifact",
+			sbomReferrers:      []ocispecs.ReferenceDescriptor{subjectAsReferrer},
+			expectedNestedCode: ratifyerrors.ErrorCodeReferrerCycleDetected.String(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ex := &Executor{
+				PolicyEnforcer: policyConfig.PolicyEnforcer{
+					ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+						"default": "all",
+					},
+				},
+				ReferrerStores: []referrerstore.ReferrerStore{&mockStore{
+					referrers: map[string][]ocispecs.ReferenceDescriptor{
+						subjectDigest:       {signature, sbom},
+						sbomDigest.String(): tc.sbomReferrers,
+					},
+				}},
+				Verifiers: []verifier.ReferenceVerifier{
+					&TestVerifier{
+						CanVerifyFunc: func(at string) bool {
+							return at == testArtifactType1
+						},
+						VerifyResult: func(artifactType string) bool {
+							return true
+						},
+					},
+					&TestVerifier{
+						CanVerifyFunc: func(at string) bool {
+							return at == testArtifactType2
+						},
+						VerifyResult: func(artifactType string) bool {
+							return true
+						},
+						nestedReferences: []string{testArtifactType1},
+					},
+				},
+				Config: &exConfig.ExecutorConfig{
+					MaxVerificationCount: tc.maxVerificationCount,
+				},
+			}
+
+			result, err := ex.verifySubjectInternal(context.Background(), e.VerifyParameters{Subject: subject1})
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verification failed with err %v", err)
+			}
+			if result.IsSuccess != (tc.expectedNestedCode == "") {
+				t.Fatalf("expected verification success to be %t", tc.expectedNestedCode == "")
+			}
+			for _, report := range result.VerifierReports {
+				castedReport := report.(verifier.VerifierResult)
+				if castedReport.ArtifactType != testArtifactType2 || tc.expectedNestedCode == "" {
+					continue
+				}
+				if len(castedReport.NestedResults) != 1 || castedReport.NestedResults[0].ErrorCode != tc.expectedNestedCode {
+					t.Fatalf("expected nested result with error code %s, got %+v", tc.expectedNestedCode, castedReport.NestedResults)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMaxNestedDepth(t *testing.T) {
+	if depth := (Executor{}).GetMaxNestedDepth(); depth != defaultMaxNestedDepth {
+		t.Fatalf("expected default max nested depth %d, got %d", defaultMaxNestedDepth, depth)
+	}
+	maxNestedDepth := 1
+	if depth := (Executor{Config: &exConfig.ExecutorConfig{MaxNestedDepth: &maxNestedDepth}}).GetMaxNestedDepth(); depth != 1 {
+		t.Fatalf("expected configured max nested depth 1, got %d", depth)
+	}
+}
+
 // TestGetVerifyRequestTimeout_ExpectedResults tests the verification request timeout returned
 func TestGetVerifyRequestTimeout_ExpectedResults(t *testing.T) {
 	testcases := []struct {
@@ -867,7 +1319,7 @@ func TestVerifySubject(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ex := &Executor{tc.stores, tc.policyEnforcer, tc.verifiers, nil}
+			ex := &Executor{ReferrerStores: tc.stores, PolicyEnforcer: tc.policyEnforcer, Verifiers: tc.verifiers}
 
 			result, err := ex.VerifySubject(context.Background(), tc.params)
 			if (err != nil) != tc.expectErr {
@@ -879,3 +1331,98 @@ func TestVerifySubject(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyContent_ExpectedResults(t *testing.T) {
+	subjectManifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	referrerManifest, _ := json.Marshal(oci.Manifest{
+		MediaType:    oci.MediaTypeImageManifest,
+		ArtifactType: testArtifactType1,
+		Config:       oci.DescriptorEmptyJSON,
+		Subject: &oci.Descriptor{
+			MediaType: oci.MediaTypeImageManifest,
+			Digest:    digest.FromBytes(subjectManifest),
+			Size:      int64(len(subjectManifest)),
+		},
+	})
+	content := inline.Content{
+		Subject:   inline.Manifest{MediaType: oci.MediaTypeImageManifest, Manifest: subjectManifest},
+		Referrers: []inline.Referrer{{Manifest: referrerManifest}},
+	}
+
+	configPolicy := policyConfig.PolicyEnforcer{
+		ArtifactTypePolicies: map[string]policyTypes.ArtifactTypeVerifyPolicy{
+			"default": policyTypes.AllVerifySuccess,
+		}}
+	ver := &TestVerifier{
+		CanVerifyFunc: func(at string) bool {
+			return at == testArtifactType1
+		},
+		VerifyResult: func(artifactType string) bool {
+			return true
+		},
+	}
+	ex := &Executor{
+		PolicyEnforcer: configPolicy,
+		ReferrerStores: []referrerstore.ReferrerStore{mocks.CreateNewTestStoreForNestedSbom()},
+		Verifiers:      []verifier.ReferenceVerifier{ver},
+		Config: &exConfig.ExecutorConfig{
+			VerificationRequestTimeout: nil,
+			MutationRequestTimeout:     nil,
+		},
+	}
+
+	result, err := ex.VerifyContent(context.Background(), e.VerifyParameters{}, content)
+	if err != nil {
+		t.Fatalf("verification failed with err %v", err)
+	}
+	if !result.IsSuccess || len(result.VerifierReports) != 1 {
+		t.Fatalf("expected one successful report, got %+v", result)
+	}
+	if len(ex.ReferrerStores) != 1 || ex.ReferrerStores[0].Name() != "memoryTestStore" {
+		t.Fatalf("configured referrer stores should not be modified")
+	}
+
+	if _, err := ex.VerifyContent(context.Background(), e.VerifyParameters{}, inline.Content{}); err == nil {
+		t.Fatalf("expected error for missing subject manifest")
+	}
+}
+
+func TestAddNestedVerifierResult_Linkage(t *testing.T) {
+	testCases := []struct {
+		name          string
+		nestedSubject string
+		expectSuccess bool
+		expectedCode  string
+	}{
+		{
+			name:          "nested result for verified artifact",
+			nestedSubject: "localhost:5000/net-monitor@" + signatureDigest,
+			expectSuccess: true,
+		},
+		{
+			name:          "nested result for another artifact",
+			nestedSubject: "localhost:5000/net-monitor@" + subjectDigest,
+			expectSuccess: false,
+			expectedCode:  ratifyerrors.ErrorCodeArtifactLinkageMismatch.String(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nestedVerifyResult := types.VerifyResult{
+				IsSuccess:       true,
+				VerifierReports: []interface{}{verifier.VerifierResult{Subject: tc.nestedSubject, IsSuccess: true}},
+			}
+			verifyResult := verifier.VerifierResult{IsSuccess: true}
+			addNestedVerifierResult(nestedVerifyResult, &verifyResult, signatureDigest)
+			if verifyResult.IsSuccess != tc.expectSuccess {
+				t.Fatalf("expected success %v, got %v", tc.expectSuccess, verifyResult.IsSuccess)
+			}
+			if verifyResult.LinkedDigest != signatureDigest {
+				t.Fatalf("expected linked digest %s, got %s", signatureDigest, verifyResult.LinkedDigest)
+			}
+			if len(verifyResult.NestedResults) != 1 || verifyResult.NestedResults[0].ErrorCode != tc.expectedCode {
+				t.Fatalf("expected nested result with error code %q, got %+v", tc.expectedCode, verifyResult.NestedResults)
+			}
+		})
+	}
+}
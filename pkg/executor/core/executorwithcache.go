@@ -32,6 +32,11 @@ type ExecutorWithCache struct {
 }
 
 func (executor ExecutorWithCache) VerifySubject(ctx context.Context, verifyParameters executor.VerifyParameters) (types.VerifyResult, error) {
+	// results evaluated as of a past time are not cached
+	if verifyParameters.VerificationTime != nil {
+		return executor.base.VerifySubject(ctx, verifyParameters)
+	}
+
 	// check the cache for the existence of item
 	cachedResult, ok := executor.verifierCache.GetVerifyResult(ctx, verifyParameters.Subject)
 
@@ -0,0 +1,111 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/opencontainers/go-digest"
+)
+
+type nestedDepthKey struct{}
+
+// nestedDepth returns the level of the referrer graph being verified, the
+// referrers of the subject are at level 1.
+func nestedDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(nestedDepthKey{}).(int)
+	return depth + 1
+}
+
+// nestedContext returns the context to verify the artifacts attached to a
+// referrer with. It returns false if the maximum nested depth is reached.
+func (executor Executor) nestedContext(ctx context.Context, subject string) (context.Context, bool) {
+	depth := nestedDepth(ctx)
+	if depth >= executor.GetMaxNestedDepth() {
+		logger.GetLogger(ctx, logOpt).Debugf("artifacts attached to %s are not verified, maximum nested depth %d is reached", subject, executor.GetMaxNestedDepth())
+		return ctx, false
+	}
+	return context.WithValue(ctx, nestedDepthKey{}, depth), true
+}
+
+type nestedAncestorsKey struct{}
+
+// nestedAncestors returns the digests of the subject and of the artifacts
+// verified on the path to the artifact being verified.
+func nestedAncestors(ctx context.Context) []digest.Digest {
+	ancestors, _ := ctx.Value(nestedAncestorsKey{}).([]digest.Digest)
+	return ancestors
+}
+
+// withNestedAncestor returns the context with the digest appended to the
+// ancestors of the artifacts attached to it.
+func withNestedAncestor(ctx context.Context, dgst digest.Digest) context.Context {
+	ancestors := nestedAncestors(ctx)
+	path := make([]digest.Digest, len(ancestors), len(ancestors)+1)
+	copy(path, ancestors)
+	return context.WithValue(ctx, nestedAncestorsKey{}, append(path, dgst))
+}
+
+// checkReferrerCycle returns an error if the referrer is one of the ancestors
+// of the artifacts being verified, which only registries listing invalid
+// referrers can cause since artifacts are attached by digest.
+func checkReferrerCycle(ctx context.Context, referrer digest.Digest) error {
+	for _, ancestor := range nestedAncestors(ctx) {
+		if ancestor == referrer {
+			return errors.ErrorCodeReferrerCycleDetected.WithComponentType(errors.Executor).WithDetail(fmt.Sprintf("referrer %s is attached to itself through its nested artifacts", referrer))
+		}
+	}
+	return nil
+}
+
+type verificationBudgetKey struct{}
+
+// verificationBudget counts the artifacts verified for a subject, including
+// nested artifacts.
+type verificationBudget struct {
+	limit    int64
+	verified atomic.Int64
+}
+
+// withVerificationBudget returns the context with the budget of the subject
+// if the executor limits the verified artifacts and the context of a nested
+// subject does not hold the budget of its parent yet.
+func (executor Executor) withVerificationBudget(ctx context.Context) context.Context {
+	if executor.Config == nil || executor.Config.MaxVerificationCount <= 0 {
+		return ctx
+	}
+	if _, ok := ctx.Value(verificationBudgetKey{}).(*verificationBudget); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, verificationBudgetKey{}, &verificationBudget{limit: int64(executor.Config.MaxVerificationCount)})
+}
+
+// consumeVerification counts the verification of an artifact against the
+// budget of the subject, it returns an error if the budget is exhausted.
+func consumeVerification(ctx context.Context) error {
+	budget, ok := ctx.Value(verificationBudgetKey{}).(*verificationBudget)
+	if !ok {
+		return nil
+	}
+	if budget.verified.Add(1) > budget.limit {
+		return errors.ErrorCodeVerificationLimitExceeded.WithComponentType(errors.Executor).WithDetail(fmt.Sprintf("more than %d artifacts are attached to the subject and its nested artifacts", budget.limit))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	vr "github.com/deislabs/ratify/pkg/verifier"
+)
+
+// executionStages returns the verifiers that can verify the reference grouped
+// into stages. Every verifier runs in a later stage than the verifiers it
+// depends on, within a stage verifiers are sorted by descending priority and
+// then by configuration order. Dependencies on verifiers that cannot verify
+// the reference are ignored.
+func (executor Executor) executionStages(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor) ([][]vr.ReferenceVerifier, error) {
+	applicable := executor.applicableVerifiers(ctx, referenceDesc)
+	names := map[string]struct{}{}
+	for _, verifier := range applicable {
+		names[verifier.Name()] = struct{}{}
+	}
+
+	placed := map[string]struct{}{}
+	remaining := applicable
+	var stages [][]vr.ReferenceVerifier
+	for len(remaining) > 0 {
+		var stage, pending []vr.ReferenceVerifier
+		for _, verifier := range remaining {
+			if dependenciesPlaced(verifier, names, placed) {
+				stage = append(stage, verifier)
+			} else {
+				pending = append(pending, verifier)
+			}
+		}
+		if len(stage) == 0 {
+			return nil, errors.ErrorCodeConfigInvalid.WithComponentType(errors.Executor).WithDetail(fmt.Sprintf("verifier dependencies for artifact type %s contain a cycle", referenceDesc.ArtifactType))
+		}
+		for _, verifier := range stage {
+			placed[verifier.Name()] = struct{}{}
+		}
+		stages = append(stages, stage)
+		remaining = pending
+	}
+	return stages, nil
+}
+
+// applicableVerifiers returns the verifiers that can verify the reference,
+// sorted by descending priority and then by configuration order.
+func (executor Executor) applicableVerifiers(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor) []vr.ReferenceVerifier {
+	var applicable []vr.ReferenceVerifier
+	for _, verifier := range executor.Verifiers {
+		if verifier.CanVerify(ctx, referenceDesc) {
+			applicable = append(applicable, verifier)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return vr.GetPriority(applicable[i]) > vr.GetPriority(applicable[j])
+	})
+	return applicable
+}
+
+// jsonPolicyVerifiers returns the verifiers to run for the json-based policy in
+// execution order. Only a single verifier reports on each artifact: the verifier
+// with the highest priority that no other applicable verifier depends on. It is
+// preceded by the applicable verifiers it transitively depends on.
+func (executor Executor) jsonPolicyVerifiers(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor) ([]vr.ReferenceVerifier, error) {
+	stages, err := executor.executionStages(ctx, referenceDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]vr.ReferenceVerifier{}
+	dependedOn := map[string]struct{}{}
+	for _, stage := range stages {
+		for _, verifier := range stage {
+			byName[verifier.Name()] = verifier
+			for _, dependency := range vr.GetDependencies(verifier) {
+				dependedOn[dependency] = struct{}{}
+			}
+		}
+	}
+
+	var primary vr.ReferenceVerifier
+	for _, verifier := range executor.applicableVerifiers(ctx, referenceDesc) {
+		if _, ok := dependedOn[verifier.Name()]; !ok {
+			primary = verifier
+			break
+		}
+	}
+	if primary == nil {
+		return nil, nil
+	}
+
+	required := map[string]struct{}{}
+	var require func(verifier vr.ReferenceVerifier)
+	require = func(verifier vr.ReferenceVerifier) {
+		if _, ok := required[verifier.Name()]; ok {
+			return
+		}
+		required[verifier.Name()] = struct{}{}
+		for _, dependency := range vr.GetDependencies(verifier) {
+			if dependencyVerifier, ok := byName[dependency]; ok {
+				require(dependencyVerifier)
+			}
+		}
+	}
+	require(primary)
+
+	var ordered []vr.ReferenceVerifier
+	for _, stage := range stages {
+		for _, verifier := range stage {
+			if _, ok := required[verifier.Name()]; ok {
+				ordered = append(ordered, verifier)
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// failedDependency returns the name of a dependency of the verifier that did
+// not succeed, or an empty string if all dependencies succeeded.
+func failedDependency(verifier vr.ReferenceVerifier, failed map[string]bool) string {
+	for _, dependency := range vr.GetDependencies(verifier) {
+		if failed[dependency] {
+			return dependency
+		}
+	}
+	return ""
+}
+
+// skippedVerifierResult is reported for a verifier that did not run because
+// one of its dependencies failed.
+func skippedVerifierResult(verifier vr.ReferenceVerifier, dependency string) vr.VerifierResult {
+	return vr.VerifierResult{
+		IsSuccess: false,
+		Name:      verifier.Name(),
+		Type:      verifier.Type(),
+		Message:   fmt.Sprintf("verification skipped: dependency %s did not succeed", dependency),
+	}
+}
+
+func dependenciesPlaced(verifier vr.ReferenceVerifier, names, placed map[string]struct{}) bool {
+	for _, dependency := range vr.GetDependencies(verifier) {
+		if _, applicable := names[dependency]; !applicable {
+			continue
+		}
+		if _, ok := placed[dependency]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/inline"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type namedVerifier struct {
+	name      string
+	isSuccess bool
+	mu        *sync.Mutex
+	calls     *[]string
+}
+
+func (v *namedVerifier) Name() string {
+	return v.name
+}
+
+func (v *namedVerifier) Type() string {
+	return v.name
+}
+
+func (v *namedVerifier) CanVerify(_ context.Context, _ ocispecs.ReferenceDescriptor) bool {
+	return true
+}
+
+func (v *namedVerifier) Verify(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	v.mu.Lock()
+	*v.calls = append(*v.calls, v.name)
+	v.mu.Unlock()
+	return verifier.VerifierResult{Name: v.name, IsSuccess: v.isSuccess}, nil
+}
+
+func (v *namedVerifier) GetNestedReferences() []string {
+	return nil
+}
+
+// createOrderedVerifiers returns a schema verifier, a content verifier depending
+// on it and an independent signature verifier
+func createOrderedVerifiers(schemaSuccess bool) ([]verifier.ReferenceVerifier, *[]string) {
+	mu := &sync.Mutex{}
+	calls := &[]string{}
+	return []verifier.ReferenceVerifier{
+		&namedVerifier{name: "schema", isSuccess: schemaSuccess, mu: mu, calls: calls},
+		verifier.WithOrdering(&namedVerifier{name: "content", isSuccess: true, mu: mu, calls: calls}, 10, []string{"schema"}),
+		verifier.WithOrdering(&namedVerifier{name: "signature", isSuccess: true, mu: mu, calls: calls}, 5, nil),
+	}, calls
+}
+
+func stageNames(stages [][]verifier.ReferenceVerifier) [][]string {
+	var names [][]string
+	for _, stage := range stages {
+		var stageNames []string
+		for _, v := range stage {
+			stageNames = append(stageNames, v.Name())
+		}
+		names = append(names, stageNames)
+	}
+	return names
+}
+
+func TestExecutionStages(t *testing.T) {
+	verifiers, _ := createOrderedVerifiers(true)
+	ex := Executor{Verifiers: verifiers}
+
+	stages, err := ex.executionStages(context.Background(), ocispecs.ReferenceDescriptor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := stageNames(stages)
+	if len(names) != 2 || len(names[0]) != 2 || names[0][0] != "signature" || names[0][1] != "schema" || names[1][0] != "content" {
+		t.Fatalf("unexpected stages %v", names)
+	}
+
+	ex.Verifiers = []verifier.ReferenceVerifier{
+		verifier.WithOrdering(&namedVerifier{name: "a"}, 0, []string{"b"}),
+		verifier.WithOrdering(&namedVerifier{name: "b"}, 0, []string{"a"}),
+	}
+	if _, err := ex.executionStages(context.Background(), ocispecs.ReferenceDescriptor{}); err == nil {
+		t.Fatalf("expected error for dependency cycle")
+	}
+}
+
+func TestVerifyReferenceForJSONPolicy_Ordering(t *testing.T) {
+	testCases := []struct {
+		name          string
+		schemaSuccess bool
+		calls         []string
+		isSuccess     bool
+	}{
+		{name: "dependency succeeded", schemaSuccess: true, calls: []string{"schema", "content"}, isSuccess: true},
+		{name: "dependency failed", schemaSuccess: false, calls: []string{"schema"}, isSuccess: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifiers, calls := createOrderedVerifiers(tc.schemaSuccess)
+			ex := Executor{Verifiers: verifiers, PolicyEnforcer: &mockPolicyProvider{}}
+
+			result := ex.verifyReferenceForJSONPolicy(context.Background(), common.Reference{}, ocispecs.ReferenceDescriptor{}, nil)
+			if result.IsSuccess != tc.isSuccess {
+				t.Fatalf("expected success %v, got %v", tc.isSuccess, result.IsSuccess)
+			}
+			if len(*calls) != len(tc.calls) || (*calls)[0] != tc.calls[0] {
+				t.Fatalf("expected verifiers %v to run, got %v", tc.calls, *calls)
+			}
+			if len(result.VerifierReports) != 2 {
+				t.Fatalf("expected reports of content verifier and its dependency, got %v", result.VerifierReports)
+			}
+			if report := result.VerifierReports[1].(verifier.VerifierResult); report.Name != "content" || report.IsSuccess != tc.isSuccess {
+				t.Fatalf("unexpected content report %+v", report)
+			}
+		})
+	}
+}
+
+func TestVerifyReferenceForRegoPolicy_Ordering(t *testing.T) {
+	verifiers, calls := createOrderedVerifiers(false)
+	// the store resolves the nested subject, which has no referrers
+	subjectManifest := []byte(`{"schemaVersion":2}`)
+	store, _ := inline.NewStore(inline.Content{Subject: inline.Manifest{Manifest: subjectManifest}})
+	ex := Executor{
+		Verifiers:      verifiers,
+		ReferrerStores: []referrerstore.ReferrerStore{store},
+		PolicyEnforcer: &mockPolicyProvider{policyType: pt.RegoPolicy},
+	}
+	subjectRef := common.Reference{Path: inline.DefaultRepository}
+	referenceDesc := ocispecs.ReferenceDescriptor{Descriptor: oci.Descriptor{Digest: digest.FromBytes(subjectManifest)}}
+
+	report, err := ex.verifyReferenceForRegoPolicy(context.Background(), subjectRef, referenceDesc, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*calls) != 2 {
+		t.Fatalf("expected content verifier to be skipped, got calls %v", *calls)
+	}
+	if len(report.VerifierReports) != 3 {
+		t.Fatalf("expected 3 reports, got %d", len(report.VerifierReports))
+	}
+	for _, verifierReport := range report.VerifierReports {
+		if verifierReport.Name == "content" && verifierReport.IsSuccess {
+			t.Fatalf("skipped verifier should not succeed")
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	e "github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/config"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// scheduler hands out the slots of the concurrency pools of the request
+// classes to verifications. Admission verifications take priority: audit
+// verifications only start while no admission verification is queued.
+type scheduler struct {
+	config  config.PriorityConfig
+	limits  map[e.RequestClass]int
+	mu      sync.Mutex
+	running map[e.RequestClass]int
+	queued  map[e.RequestClass]int
+	// released is closed and replaced whenever a slot is released or an
+	// admission verification leaves the queue, to wake up waiting verifications
+	released chan struct{}
+}
+
+var (
+	schedulerMu     sync.Mutex
+	activeScheduler = newScheduler(config.PriorityConfig{})
+)
+
+type scheduledKey struct{}
+
+// ConfigureScheduler applies the concurrency limits of the request classes to
+// verifications started from now on. Verifications that are already running
+// keep counting towards the previous limits until they complete.
+func ConfigureScheduler(config config.PriorityConfig) {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+	if reflect.DeepEqual(activeScheduler.config, config) {
+		return
+	}
+	activeScheduler = newScheduler(config)
+	logrus.Infof("verification scheduler configured with at most %d concurrent audit verifications", activeScheduler.limits[e.RequestClassAudit])
+}
+
+func currentScheduler() *scheduler {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+	return activeScheduler
+}
+
+func newScheduler(config config.PriorityConfig) *scheduler {
+	maxAudit := config.MaxConcurrentAudit
+	if maxAudit <= 0 {
+		maxAudit = runtime.NumCPU()
+	}
+	return &scheduler{
+		config: config,
+		limits: map[e.RequestClass]int{
+			e.RequestClassAdmission: config.MaxConcurrentAdmission,
+			e.RequestClassAudit:     maxAudit,
+		},
+		running:  map[e.RequestClass]int{},
+		queued:   map[e.RequestClass]int{},
+		released: make(chan struct{}),
+	}
+}
+
+// Schedule waits for a slot of the request class of the context and returns
+// the context to verify with and the function releasing the slot. Nested
+// verifications and verifications with a context returned by Schedule run in
+// the slot of the context, so callers taking locks of a subject schedule the
+// verification before locking it.
+func Schedule(ctx context.Context) (context.Context, func(), error) {
+	if scheduled, _ := ctx.Value(scheduledKey{}).(bool); scheduled {
+		return ctx, func() {}, nil
+	}
+	release, err := currentScheduler().acquire(ctx, e.RequestClassFromContext(ctx))
+	if err != nil {
+		return ctx, nil, err
+	}
+	return context.WithValue(ctx, scheduledKey{}, true), release, nil
+}
+
+// acquire waits for a slot of the class and returns the function releasing it.
+func (s *scheduler) acquire(ctx context.Context, class e.RequestClass) (func(), error) {
+	start := time.Now()
+	s.mu.Lock()
+	s.queued[class]++
+	for !s.available(class) {
+		released := s.released
+		s.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.dequeue(class)
+			s.mu.Unlock()
+			return nil, fmt.Errorf("timed out waiting to schedule the %s verification: %w", class, ctx.Err())
+		}
+		s.mu.Lock()
+	}
+	s.dequeue(class)
+	s.running[class]++
+	s.mu.Unlock()
+	metrics.ReportPriorityQueueWait(ctx, time.Since(start).Milliseconds(), string(class))
+	metrics.ReportPriorityInflight(ctx, 1, string(class))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.running[class]--
+			s.wake()
+			s.mu.Unlock()
+			metrics.ReportPriorityInflight(ctx, -1, string(class))
+		})
+	}, nil
+}
+
+// available returns whether a verification of the class may start, s.mu
+// must be held.
+func (s *scheduler) available(class e.RequestClass) bool {
+	if limit := s.limits[class]; limit > 0 && s.running[class] >= limit {
+		return false
+	}
+	return class == e.RequestClassAdmission || s.queued[e.RequestClassAdmission] == 0
+}
+
+// dequeue removes a verification of the class from the queue, s.mu must be
+// held. Audit verifications may start once no admission verification is
+// queued.
+func (s *scheduler) dequeue(class e.RequestClass) {
+	s.queued[class]--
+	if class == e.RequestClassAdmission && s.queued[class] == 0 {
+		s.wake()
+	}
+}
+
+// wake wakes up the waiting verifications, s.mu must be held.
+func (s *scheduler) wake() {
+	close(s.released)
+	s.released = make(chan struct{})
+}
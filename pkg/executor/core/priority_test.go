@@ -0,0 +1,145 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	e "github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/config"
+)
+
+func TestScheduler_LimitsConcurrency(t *testing.T) {
+	s := newScheduler(config.PriorityConfig{MaxConcurrentAudit: 1})
+	release, err := s.acquire(context.Background(), e.RequestClassAudit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		next, _ := s.acquire(context.Background(), e.RequestClassAudit)
+		acquired <- next
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected audit verification to wait for a slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	release()
+	select {
+	case next := <-acquired:
+		next()
+	case <-time.After(time.Second):
+		t.Fatal("expected audit verification to start once the slot is released")
+	}
+	if s.running[e.RequestClassAudit] != 0 {
+		t.Fatalf("expected no running audit verifications, got %d", s.running[e.RequestClassAudit])
+	}
+}
+
+func TestScheduler_AdmissionPreemptsAudit(t *testing.T) {
+	s := newScheduler(config.PriorityConfig{MaxConcurrentAdmission: 1, MaxConcurrentAudit: 2})
+	releaseAdmission, err := s.acquire(context.Background(), e.RequestClassAdmission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	order := make(chan e.RequestClass, 2)
+	go func() {
+		release, _ := s.acquire(context.Background(), e.RequestClassAdmission)
+		order <- e.RequestClassAdmission
+		release()
+	}()
+	waitQueued(t, s, e.RequestClassAdmission)
+	go func() {
+		release, _ := s.acquire(context.Background(), e.RequestClassAudit)
+		order <- e.RequestClassAudit
+		release()
+	}()
+	waitQueued(t, s, e.RequestClassAudit)
+
+	select {
+	case class := <-order:
+		t.Fatalf("expected verifications to wait while an admission verification is queued, %s verification started", class)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	releaseAdmission()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-order:
+		case <-time.After(time.Second):
+			t.Fatal("expected queued verifications to start once the admission slot is released")
+		}
+	}
+}
+
+func TestScheduler_CanceledWhileQueued(t *testing.T) {
+	s := newScheduler(config.PriorityConfig{MaxConcurrentAdmission: 1})
+	release, err := s.acquire(context.Background(), e.RequestClassAdmission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, e.RequestClassAdmission); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if s.queued[e.RequestClassAdmission] != 0 {
+		t.Fatalf("expected the canceled verification to leave the queue, got %d queued", s.queued[e.RequestClassAdmission])
+	}
+}
+
+func TestSchedule_NestedVerificationsShareSlot(t *testing.T) {
+	ConfigureScheduler(config.PriorityConfig{MaxConcurrentAdmission: 1})
+	defer ConfigureScheduler(config.PriorityConfig{})
+
+	ctx, release, err := Schedule(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	nestedCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, nestedRelease, err := Schedule(nestedCtx); err != nil {
+		t.Fatalf("expected nested verification to run in the slot of its subject, got %v", err)
+	} else {
+		nestedRelease()
+	}
+}
+
+func waitQueued(t *testing.T, s *scheduler, class e.RequestClass) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		queued := s.queued[class]
+		s.mu.Unlock()
+		if queued > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected a queued %s verification", class)
+}
@@ -27,6 +27,7 @@ import (
 type TestVerifier struct {
 	CanVerifyFunc    func(artifactType string) bool
 	VerifyResult     func(artifactType string) bool
+	VerifyErr        error
 	nestedReferences []string
 }
 
@@ -46,6 +47,9 @@ func (s *TestVerifier) Verify(_ context.Context,
 	_ common.Reference,
 	referenceDescriptor ocispecs.ReferenceDescriptor,
 	_ referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	if s.VerifyErr != nil {
+		return verifier.VerifierResult{IsSuccess: false}, s.VerifyErr
+	}
 	return verifier.VerifierResult{
 		IsSuccess: s.VerifyResult(referenceDescriptor.ArtifactType),
 	}, nil
@@ -0,0 +1,48 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/deislabs/ratify/internal/logger"
+	vr "github.com/deislabs/ratify/pkg/verifier"
+)
+
+// warmConcurrency is the maximum number of verifiers warmed at once
+const warmConcurrency = 16
+
+// Warm prepares the verifiers of the executor before it serves requests, so
+// that replacing the executor does not slow down the first verifications.
+// Verifiers that fail to warm are logged and prepared on their first
+// verification instead.
+func (executor Executor) Warm(ctx context.Context) {
+	sem := make(chan struct{}, warmConcurrency)
+	wg := sync.WaitGroup{}
+	for _, verifier := range executor.Verifiers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(verifier vr.ReferenceVerifier) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := vr.Warm(ctx, verifier); err != nil {
+				logger.GetLogger(ctx, logOpt).Warnf("failed to warm verifier %s: %v", verifier.Name(), err)
+			}
+		}(verifier)
+	}
+	wg.Wait()
+}
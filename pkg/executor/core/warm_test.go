@@ -0,0 +1,57 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/verifier"
+)
+
+type warmingVerifier struct {
+	namedVerifier
+	err error
+}
+
+func (v *warmingVerifier) Warm(_ context.Context) error {
+	v.mu.Lock()
+	*v.calls = append(*v.calls, v.name)
+	v.mu.Unlock()
+	return v.err
+}
+
+func TestWarm_WarmsVerifiers(t *testing.T) {
+	mu := &sync.Mutex{}
+	var warmed []string
+	executor := Executor{
+		Verifiers: []verifier.ReferenceVerifier{
+			&warmingVerifier{namedVerifier: namedVerifier{name: "a", mu: mu, calls: &warmed}},
+			&warmingVerifier{namedVerifier: namedVerifier{name: "b", mu: mu, calls: &warmed}, err: errors.New("plugin not found")},
+			verifier.WithOrdering(&warmingVerifier{namedVerifier: namedVerifier{name: "c", mu: mu, calls: &warmed}}, 1, nil),
+			&namedVerifier{name: "d", mu: mu, calls: &warmed},
+		},
+	}
+
+	executor.Warm(context.Background())
+	sort.Strings(warmed)
+	if len(warmed) != 3 || warmed[0] != "a" || warmed[1] != "b" || warmed[2] != "c" {
+		t.Fatalf("expected verifiers a, b and c to be warmed, got %v", warmed)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RequestClass is the class of the request a verification is made for, which
+// selects the priority and the concurrency pool of the verification.
+type RequestClass string
+
+const (
+	// RequestClassAdmission is a verification of an admission request, it
+	// takes priority over audit verifications.
+	RequestClassAdmission RequestClass = "admission"
+	// RequestClassAudit is a background verification, e.g. of the Gatekeeper
+	// audit, it waits while admission verifications are queued.
+	RequestClassAudit RequestClass = "audit"
+)
+
+type requestClassKey struct{}
+
+// WithRequestClass returns a context carrying the class of the request the
+// verification is made for.
+func WithRequestClass(ctx context.Context, class RequestClass) context.Context {
+	return context.WithValue(ctx, requestClassKey{}, class)
+}
+
+// RequestClassFromContext returns the class of the request the verification
+// is made for, admission if the context does not carry one.
+func RequestClassFromContext(ctx context.Context) RequestClass {
+	if class, ok := ctx.Value(requestClassKey{}).(RequestClass); ok {
+		return class
+	}
+	return RequestClassAdmission
+}
+
+// ParseRequestClass parses a request class, case-insensitively.
+func ParseRequestClass(value string) (RequestClass, error) {
+	switch class := RequestClass(strings.ToLower(value)); class {
+	case RequestClassAdmission, RequestClassAudit:
+		return class, nil
+	default:
+		return "", fmt.Errorf("invalid request class %q, expected %s or %s", value, RequestClassAdmission, RequestClassAudit)
+	}
+}
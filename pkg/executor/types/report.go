@@ -0,0 +1,171 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/types"
+)
+
+// ArtifactReport describes the results of verifying an artifact attached to a
+// subject in the v2 report format.
+type ArtifactReport struct {
+	// Subject is the reference of the subject the artifact is attached to.
+	Subject string `json:"subject"`
+	// ArtifactDigest is the digest of the artifact, it is empty if the subject
+	// could not be verified, e.g. no artifacts are attached to it.
+	ArtifactDigest string `json:"artifactDigest,omitempty"`
+	ArtifactType   string `json:"artifactType,omitempty"`
+	// IsSuccess is true if all verifiers of the artifact and of its nested
+	// artifacts succeeded. The overall result is decided by the policy.
+	IsSuccess       bool             `json:"isSuccess"`
+	VerifierReports []VerifierReport `json:"verifierReports"`
+	// NestedReports are the reports of the artifacts attached to the artifact.
+	NestedReports []ArtifactReport `json:"nestedReports,omitempty"`
+}
+
+// VerifierReport describes the result of a verifier in the v2 report format.
+type VerifierReport struct {
+	Name         string            `json:"name,omitempty"`
+	Type         string            `json:"type,omitempty"`
+	IsSuccess    bool              `json:"isSuccess"`
+	Inconclusive bool              `json:"inconclusive,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	ErrorCode    string            `json:"errorCode,omitempty"`
+	Signers      []verifier.Signer `json:"signers,omitempty"`
+	Extensions   interface{}       `json:"extensions,omitempty"`
+	VerifiedAt   *time.Time        `json:"verifiedAt,omitempty"`
+	ValidUntil   *time.Time        `json:"validUntil,omitempty"`
+}
+
+// NewArtifactReports converts the verifier reports of a verify result to
+// reports per artifact. Results of the Json-based policy enforcer are grouped
+// by artifact, reports of the Rego-based policy enforcer are converted as is.
+func NewArtifactReports(verifierReports []interface{}) []ArtifactReport {
+	reports := make([]ArtifactReport, 0)
+	// index of the report of each artifact of the Json-based policy results
+	indexes := map[string]int{}
+	// the nested results are verified once per artifact and attached to each
+	// verifier declaring nested references
+	nestedResults := map[int][]verifier.VerifierResult{}
+	for _, report := range verifierReports {
+		switch r := normalizeReport(report).(type) {
+		case NestedVerifierReport:
+			reports = append(reports, fromNestedVerifierReport(r))
+		case verifier.VerifierResult:
+			key := r.Subject + "|" + r.ReferenceDigest
+			index, ok := indexes[key]
+			if !ok {
+				index = len(reports)
+				indexes[key] = index
+				reports = append(reports, ArtifactReport{
+					Subject:         r.Subject,
+					ArtifactDigest:  r.ReferenceDigest,
+					ArtifactType:    r.ArtifactType,
+					IsSuccess:       true,
+					VerifierReports: make([]VerifierReport, 0),
+				})
+			}
+			reports[index].VerifierReports = append(reports[index].VerifierReports, VerifierReport{
+				Name:         r.Name,
+				Type:         r.Type,
+				IsSuccess:    r.IsSuccess,
+				Inconclusive: r.Inconclusive,
+				Message:      r.Message,
+				ErrorCode:    r.ErrorCode,
+				Signers:      r.Signers,
+				Extensions:   r.Extensions,
+				VerifiedAt:   r.VerifiedAt,
+				ValidUntil:   r.ValidUntil,
+			})
+			reports[index].IsSuccess = reports[index].IsSuccess && r.IsSuccess
+			if _, ok := nestedResults[index]; !ok && len(r.NestedResults) > 0 {
+				nestedResults[index] = r.NestedResults
+			}
+		}
+	}
+	for index, results := range nestedResults {
+		nested := make([]interface{}, 0, len(results))
+		for _, result := range results {
+			nested = append(nested, result)
+		}
+		reports[index].NestedReports = NewArtifactReports(nested)
+	}
+	return reports
+}
+
+// normalizeReport decodes a report read from the cache, which is unmarshaled
+// to a map, to the type of the report.
+func normalizeReport(report interface{}) interface{} {
+	values, ok := report.(map[string]interface{})
+	if !ok {
+		return report
+	}
+	body, err := json.Marshal(values)
+	if err != nil {
+		return report
+	}
+	if _, ok := values["nestedReports"]; ok {
+		var nestedReport NestedVerifierReport
+		if err := json.Unmarshal(body, &nestedReport); err == nil {
+			return nestedReport
+		}
+		return report
+	}
+	var result verifier.VerifierResult
+	if err := json.Unmarshal(body, &result); err == nil {
+		return result
+	}
+	return report
+}
+
+func fromNestedVerifierReport(nestedReport NestedVerifierReport) ArtifactReport {
+	report := ArtifactReport{
+		Subject:         nestedReport.Subject,
+		ArtifactDigest:  nestedReport.ReferenceDigest,
+		ArtifactType:    nestedReport.ArtifactType,
+		IsSuccess:       true,
+		VerifierReports: make([]VerifierReport, 0, len(nestedReport.VerifierReports)),
+	}
+	for _, result := range nestedReport.VerifierReports {
+		report.VerifierReports = append(report.VerifierReports, fromVerifierResult(result))
+		report.IsSuccess = report.IsSuccess && result.IsSuccess
+	}
+	for _, nested := range nestedReport.NestedReports {
+		nestedArtifactReport := fromNestedVerifierReport(nested)
+		report.NestedReports = append(report.NestedReports, nestedArtifactReport)
+		report.IsSuccess = report.IsSuccess && nestedArtifactReport.IsSuccess
+	}
+	return report
+}
+
+func fromVerifierResult(result types.VerifierResult) VerifierReport {
+	return VerifierReport{
+		Name:         result.Name,
+		Type:         result.Type,
+		IsSuccess:    result.IsSuccess,
+		Inconclusive: result.Inconclusive,
+		Message:      result.Message,
+		ErrorCode:    result.ErrorCode,
+		Signers:      result.Signers,
+		Extensions:   result.Extensions,
+		VerifiedAt:   result.VerifiedAt,
+		ValidUntil:   result.ValidUntil,
+	}
+}
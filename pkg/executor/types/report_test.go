@@ -0,0 +1,158 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/types"
+)
+
+const (
+	testSubject       = "localhost:5000/net-monitor@sha256:1"
+	testNestedSubject = "localhost:5000/net-monitor@sha256:2"
+)
+
+func TestNewArtifactReports_JSONPolicy(t *testing.T) {
+	nested := []verifier.VerifierResult{{Subject: testNestedSubject, ReferenceDigest: "sha256:3", Name: "notation", IsSuccess: false, ErrorCode: "VERIFY_REFERENCE_FAILURE"}}
+	reports := []interface{}{
+		verifier.VerifierResult{Subject: testSubject, ReferenceDigest: "sha256:2", ArtifactType: "sbom", Name: "sbom", IsSuccess: true, NestedResults: nested},
+		verifier.VerifierResult{Subject: testSubject, ReferenceDigest: "sha256:2", ArtifactType: "sbom", Name: "licensechecker", IsSuccess: true, NestedResults: nested},
+		verifier.VerifierResult{Subject: testSubject, ReferenceDigest: "sha256:4", ArtifactType: "signature", Name: "notation", IsSuccess: true},
+	}
+	expected := []ArtifactReport{
+		{
+			Subject:         testSubject,
+			ArtifactDigest:  "sha256:2",
+			ArtifactType:    "sbom",
+			IsSuccess:       true,
+			VerifierReports: []VerifierReport{{Name: "sbom", IsSuccess: true}, {Name: "licensechecker", IsSuccess: true}},
+			NestedReports: []ArtifactReport{{
+				Subject:         testNestedSubject,
+				ArtifactDigest:  "sha256:3",
+				VerifierReports: []VerifierReport{{Name: "notation", ErrorCode: "VERIFY_REFERENCE_FAILURE"}},
+			}},
+		},
+		{
+			Subject:         testSubject,
+			ArtifactDigest:  "sha256:4",
+			ArtifactType:    "signature",
+			IsSuccess:       true,
+			VerifierReports: []VerifierReport{{Name: "notation", IsSuccess: true}},
+		},
+	}
+	if actual := NewArtifactReports(reports); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected reports %+v, got %+v", expected, actual)
+	}
+
+	// reports read from the cache are unmarshaled to maps
+	body, _ := json.Marshal(reports)
+	var cached []interface{}
+	if err := json.Unmarshal(body, &cached); err != nil {
+		t.Fatalf("failed to unmarshal reports: %v", err)
+	}
+	if actual := NewArtifactReports(cached); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected cached reports %+v, got %+v", expected, actual)
+	}
+}
+
+func TestNewArtifactReports_RegoPolicy(t *testing.T) {
+	reports := []interface{}{NestedVerifierReport{
+		Subject:         testSubject,
+		ReferenceDigest: "sha256:2",
+		ArtifactType:    "sbom",
+		VerifierReports: []types.VerifierResult{{Name: "sbom", IsSuccess: true}},
+		NestedReports: []NestedVerifierReport{{
+			Subject:         testNestedSubject,
+			ReferenceDigest: "sha256:3",
+			VerifierReports: []types.VerifierResult{{Name: "notation", IsSuccess: false, ErrorCode: "VERIFY_REFERENCE_FAILURE"}},
+		}},
+	}}
+	expected := []ArtifactReport{{
+		Subject:         testSubject,
+		ArtifactDigest:  "sha256:2",
+		ArtifactType:    "sbom",
+		VerifierReports: []VerifierReport{{Name: "sbom", IsSuccess: true}},
+		NestedReports: []ArtifactReport{{
+			Subject:         testNestedSubject,
+			ArtifactDigest:  "sha256:3",
+			VerifierReports: []VerifierReport{{Name: "notation", ErrorCode: "VERIFY_REFERENCE_FAILURE"}},
+		}},
+	}}
+	if actual := NewArtifactReports(reports); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected reports %+v, got %+v", expected, actual)
+	}
+}
+
+func TestVerifyResult_ValidUntil(t *testing.T) {
+	earliest := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earliest.Add(time.Hour)
+
+	cached, err := json.Marshal(verifier.VerifierResult{IsSuccess: true, ValidUntil: &earliest})
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	var cachedReport map[string]interface{}
+	if err := json.Unmarshal(cached, &cachedReport); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		reports  []interface{}
+		expected *time.Time
+	}{
+		{
+			name:    "no expiry",
+			reports: []interface{}{verifier.VerifierResult{IsSuccess: true}},
+		},
+		{
+			name: "nested result",
+			reports: []interface{}{verifier.VerifierResult{
+				ValidUntil:    &later,
+				NestedResults: []verifier.VerifierResult{{ValidUntil: &earliest}},
+			}},
+			expected: &earliest,
+		},
+		{
+			name: "nested report",
+			reports: []interface{}{NestedVerifierReport{
+				VerifierReports: []types.VerifierResult{{ValidUntil: &later}},
+				NestedReports: []NestedVerifierReport{{
+					VerifierReports: []types.VerifierResult{{ValidUntil: &earliest}},
+				}},
+			}},
+			expected: &earliest,
+		},
+		{
+			name:     "cached report",
+			reports:  []interface{}{verifier.VerifierResult{ValidUntil: &later}, cachedReport},
+			expected: &earliest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validUntil := VerifyResult{VerifierReports: tc.reports}.ValidUntil()
+			if (validUntil == nil) != (tc.expected == nil) || (validUntil != nil && !validUntil.Equal(*tc.expected)) {
+				t.Fatalf("expected %v, got %v", tc.expected, validUntil)
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ package types
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/deislabs/ratify/pkg/verifier/types"
 )
 
@@ -27,6 +29,21 @@ type VerifyResult struct {
 	VerifierReports []interface{} `json:"verifierReports"`
 }
 
+// ValidUntil returns the earliest expiry of the trust material the verifier
+// reports and their nested reports rely on, nil if none of them expire.
+func (r VerifyResult) ValidUntil() *time.Time {
+	var earliest *time.Time
+	for _, report := range r.VerifierReports {
+		switch report := normalizeReport(report).(type) {
+		case verifier.VerifierResult:
+			earliest = earliestValidUntil(earliest, report)
+		case NestedVerifierReport:
+			earliest = report.validUntil(earliest)
+		}
+	}
+	return earliest
+}
+
 // NestedVerifierReport describes the results of verifying an artifact and its
 // nested artifacts by available verifiers.
 type NestedVerifierReport struct {
@@ -44,3 +61,28 @@ func NewNestedVerifierReport(report interface{}) (NestedVerifierReport, error) {
 	}
 	return NestedVerifierReport{}, fmt.Errorf("unable to convert %v to NestedVerifierReport", report)
 }
+
+func (r NestedVerifierReport) validUntil(earliest *time.Time) *time.Time {
+	for _, result := range r.VerifierReports {
+		earliest = earlier(earliest, result.ValidUntil)
+	}
+	for _, nested := range r.NestedReports {
+		earliest = nested.validUntil(earliest)
+	}
+	return earliest
+}
+
+func earliestValidUntil(earliest *time.Time, result verifier.VerifierResult) *time.Time {
+	earliest = earlier(earliest, result.ValidUntil)
+	for _, nested := range result.NestedResults {
+		earliest = earliestValidUntil(earliest, nested)
+	}
+	return earliest
+}
+
+func earlier(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
@@ -28,6 +28,9 @@ var (
 	DynamicPlugins   = newFeatureFlag("EXPERIMENTAL_DYNAMIC_PLUGINS", false)
 	CertRotation     = newFeatureFlag("CERT_ROTATION", false)
 	HighAvailability = newFeatureFlag("EXPERIMENTAL_HIGH_AVAILABILITY", false)
+	GRPCPlugins      = newFeatureFlag("EXPERIMENTAL_GRPC_PLUGINS", false)
+	WasmPlugins      = newFeatureFlag("EXPERIMENTAL_WASM_PLUGINS", false)
+	PolicyWebhook    = newFeatureFlag("POLICY_VALIDATION_WEBHOOK", false)
 )
 
 var flags = make(map[string]*FeatureFlag)
@@ -0,0 +1,258 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azurekeyvault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	kv "github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/certificateprovider/azurekeyvault"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	"github.com/deislabs/ratify/pkg/metrics"
+)
+
+const (
+	providerType = "azurekeyvault"
+
+	// CertificatesStatus is the key of the certificate status property
+	CertificatesStatus = "Certificates"
+	// KeysStatus is the key of the key status property
+	KeysStatus = "Keys"
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.KeyManagementProvider,
+}
+
+// keyVaultClient fetches secrets and keys from a key vault
+type keyVaultClient interface {
+	GetSecret(ctx context.Context, vaultBaseURL string, secretName string, secretVersion string) (kv.SecretBundle, error)
+	GetKey(ctx context.Context, vaultBaseURL string, keyName string, keyVersion string) (kv.KeyBundle, error)
+}
+
+// newKeyVaultClient creates the key vault client, it is replaced in tests
+var newKeyVaultClient = func(ctx context.Context, cloudName, tenantID, clientID string) (keyVaultClient, error) {
+	return azurekeyvault.NewKeyVaultClient(ctx, cloudName, tenantID, clientID)
+}
+
+// AKVKeyManagementProviderConfig describes the configuration of the Azure Key
+// Vault key management provider.
+type AKVKeyManagementProviderConfig struct { //nolint:revive // ignore linter to have unique type name
+	Type      string `json:"type"`
+	VaultURI  string `json:"vaultURI"`
+	TenantID  string `json:"tenantID"`
+	ClientID  string `json:"clientID"`
+	CloudName string `json:"cloudName,omitempty"`
+	// Certificates are the certificates to fetch, the latest version of a
+	// certificate is fetched if the version is empty.
+	Certificates []KeyVaultValue `json:"certificates,omitempty"`
+	// Keys are the keys to fetch, the latest version of a key is fetched if
+	// the version is empty.
+	Keys []KeyVaultValue `json:"keys,omitempty"`
+}
+
+// KeyVaultValue identifies a certificate or a key of the key vault.
+type KeyVaultValue struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type akvKeyManagementProvider struct {
+	config AKVKeyManagementProviderConfig
+}
+
+type akvKeyManagementProviderFactory struct{}
+
+func init() {
+	factory.Register(providerType, &akvKeyManagementProviderFactory{})
+}
+
+// Create validates the configuration of the key vault.
+func (f *akvKeyManagementProviderFactory) Create(kmpConfig config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error) {
+	conf := AKVKeyManagementProviderConfig{}
+	configBytes, err := json.Marshal(kmpConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	if err := json.Unmarshal(configBytes, &conf); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, "failed to parse azure key vault key management provider configuration", re.HideStackTrace)
+	}
+
+	if conf.VaultURI == "" {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.AKVLink, nil, "vaultURI is not set", re.HideStackTrace)
+	}
+	if conf.TenantID == "" {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.AKVLink, nil, "tenantID is not set", re.HideStackTrace)
+	}
+	if conf.ClientID == "" {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.AKVLink, nil, "clientID is not set", re.HideStackTrace)
+	}
+	if len(conf.Certificates) == 0 && len(conf.Keys) == 0 {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, "no certificates or keys configured", re.HideStackTrace)
+	}
+	for _, value := range append(conf.Certificates, conf.Keys...) {
+		if strings.TrimSpace(value.Name) == "" {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, "name of the certificate or key is not set", re.HideStackTrace)
+		}
+	}
+	return &akvKeyManagementProvider{config: conf}, nil
+}
+
+// GetCertificates fetches the certificate chains of the certificates from the
+// key vault. The secrets of the certificates are fetched so that the entire
+// chain is returned.
+func (p *akvKeyManagementProvider) GetCertificates(ctx context.Context) (map[keymanagementprovider.KMPMapKey][]*x509.Certificate, keymanagementprovider.KeyManagementProviderStatus, error) {
+	certificates := map[keymanagementprovider.KMPMapKey][]*x509.Certificate{}
+	if len(p.config.Certificates) == 0 {
+		return certificates, nil, nil
+	}
+	kvClient, err := newKeyVaultClient(ctx, p.config.CloudName, p.config.TenantID, p.config.ClientID)
+	if err != nil {
+		return nil, nil, re.ErrorCodePluginInitFailure.NewError(re.KeyManagementProvider, providerType, re.AKVLink, err, "failed to get keyvault client", re.HideStackTrace)
+	}
+
+	certsStatus := []map[string]string{}
+	for _, cert := range p.config.Certificates {
+		logger.GetLogger(ctx, logOpt).Debugf("fetching secret from key vault, certName %v, keyvault %v", cert.Name, p.config.VaultURI)
+		startTime := time.Now()
+		secretBundle, err := kvClient.GetSecret(ctx, p.config.VaultURI, cert.Name, cert.Version)
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, providerType, re.AKVLink, err, fmt.Sprintf("failed to get secret objectName:%s, objectVersion:%s", cert.Name, cert.Version), re.HideStackTrace)
+		}
+		certs, version, err := azurekeyvault.GetCertificatesFromSecretBundle(ctx, secretBundle, cert.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		metrics.ReportAKVCertificateDuration(ctx, time.Since(startTime).Milliseconds(), cert.Name)
+
+		certificates[keymanagementprovider.KMPMapKey{Name: cert.Name, Version: version}] = certs
+		certsStatus = append(certsStatus, map[string]string{
+			"CertificateName": cert.Name,
+			"Version":         version,
+			"LastRefreshed":   time.Now().Format(time.RFC3339),
+		})
+	}
+	return certificates, keymanagementprovider.KeyManagementProviderStatus{CertificatesStatus: certsStatus}, nil
+}
+
+// GetKeys fetches the public keys from the key vault.
+func (p *akvKeyManagementProvider) GetKeys(ctx context.Context) (map[keymanagementprovider.KMPMapKey]crypto.PublicKey, keymanagementprovider.KeyManagementProviderStatus, error) {
+	keys := map[keymanagementprovider.KMPMapKey]crypto.PublicKey{}
+	if len(p.config.Keys) == 0 {
+		return keys, nil, nil
+	}
+	kvClient, err := newKeyVaultClient(ctx, p.config.CloudName, p.config.TenantID, p.config.ClientID)
+	if err != nil {
+		return nil, nil, re.ErrorCodePluginInitFailure.NewError(re.KeyManagementProvider, providerType, re.AKVLink, err, "failed to get keyvault client", re.HideStackTrace)
+	}
+
+	keysStatus := []map[string]string{}
+	for _, key := range p.config.Keys {
+		logger.GetLogger(ctx, logOpt).Debugf("fetching key from key vault, keyName %v, keyvault %v", key.Name, p.config.VaultURI)
+		keyBundle, err := kvClient.GetKey(ctx, p.config.VaultURI, key.Name, key.Version)
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, providerType, re.AKVLink, err, fmt.Sprintf("failed to get key objectName:%s, objectVersion:%s", key.Name, key.Version), re.HideStackTrace)
+		}
+		if keyBundle.Key == nil || keyBundle.Key.Kid == nil {
+			return nil, nil, re.ErrorCodeKeyInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, fmt.Sprintf("found invalid key bundle for key %s, key and kid must not be nil", key.Name), re.HideStackTrace)
+		}
+		publicKey, err := getPublicKey(keyBundle.Key)
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, fmt.Sprintf("failed to parse key %s", key.Name), re.HideStackTrace)
+		}
+		version := getObjectVersion(*keyBundle.Key.Kid)
+		keys[keymanagementprovider.KMPMapKey{Name: key.Name, Version: version}] = publicKey
+		keysStatus = append(keysStatus, map[string]string{
+			"KeyName":       key.Name,
+			"Version":       version,
+			"LastRefreshed": time.Now().Format(time.RFC3339),
+		})
+	}
+	return keys, keymanagementprovider.KeyManagementProviderStatus{KeysStatus: keysStatus}, nil
+}
+
+// getPublicKey converts the JSON web key of a RSA or EC key to a public key.
+func getPublicKey(key *kv.JSONWebKey) (crypto.PublicKey, error) {
+	switch key.Kty {
+	case kv.RSA, kv.RSAHSM:
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case kv.EC, kv.ECHSM:
+		var curve elliptic.Curve
+		switch key.Crv {
+		case kv.P256:
+			curve = elliptic.P256()
+		case kv.P384:
+			curve = elliptic.P384()
+		case kv.P521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", key.Crv)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", key.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(value *string) (*big.Int, error) {
+	if value == nil {
+		return nil, fmt.Errorf("value is not set")
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(*value, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+// getObjectVersion parses the version of the object from its id, e.g.
+// https://myvault.vault.azure.net/keys/mykey/1f304204f3624873aab40231241243eb
+func getObjectVersion(id string) string {
+	splitID := strings.Split(id, "/")
+	return splitID[len(splitID)-1]
+}
@@ -0,0 +1,204 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azurekeyvault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	kv "github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	"github.com/deislabs/ratify/pkg/certificateprovider/azurekeyvault"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+)
+
+type testKeyVaultClient struct {
+	secrets map[string]kv.SecretBundle
+	keys    map[string]kv.KeyBundle
+}
+
+func (c *testKeyVaultClient) GetSecret(_ context.Context, _ string, secretName string, _ string) (kv.SecretBundle, error) {
+	if secret, ok := c.secrets[secretName]; ok {
+		return secret, nil
+	}
+	return kv.SecretBundle{}, fmt.Errorf("secret %s not found", secretName)
+}
+
+func (c *testKeyVaultClient) GetKey(_ context.Context, _ string, keyName string, _ string) (kv.KeyBundle, error) {
+	if key, ok := c.keys[keyName]; ok {
+		return key, nil
+	}
+	return kv.KeyBundle{}, fmt.Errorf("key %s not found", keyName)
+}
+
+func stubKeyVaultClient(t *testing.T, client keyVaultClient) {
+	previous := newKeyVaultClient
+	newKeyVaultClient = func(_ context.Context, _, _, _ string) (keyVaultClient, error) {
+		return client, nil
+	}
+	t.Cleanup(func() {
+		newKeyVaultClient = previous
+	})
+}
+
+func encodeBigInt(value *big.Int) *string {
+	encoded := base64.RawURLEncoding.EncodeToString(value.Bytes())
+	return &encoded
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    config.KeyManagementProviderConfig
+		expectErr bool
+	}{
+		{
+			name:   "certificates and keys",
+			config: config.KeyManagementProviderConfig{"type": providerType, "vaultURI": "https://test.vault.azure.net/", "tenantID": "tenant", "clientID": "client", "certificates": []interface{}{map[string]interface{}{"name": "cert"}}, "keys": []interface{}{map[string]interface{}{"name": "key", "version": "v1"}}},
+		},
+		{
+			name:      "missing vaultURI",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "tenantID": "tenant", "clientID": "client", "keys": []interface{}{map[string]interface{}{"name": "key"}}},
+			expectErr: true,
+		},
+		{
+			name:      "no certificates or keys",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "vaultURI": "https://test.vault.azure.net/", "tenantID": "tenant", "clientID": "client"},
+			expectErr: true,
+		},
+		{
+			name:      "key without name",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "vaultURI": "https://test.vault.azure.net/", "tenantID": "tenant", "clientID": "client", "keys": []interface{}{map[string]interface{}{"name": " "}}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := factory.CreateKeyManagementProviderFromConfig(tc.config)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	stubKeyVaultClient(t, &testKeyVaultClient{secrets: map[string]kv.SecretBundle{
+		"cert": {
+			ID:          stringPtr("https://test.vault.azure.net/secrets/cert/v1"),
+			ContentType: stringPtr(azurekeyvault.PEMContentType),
+			Value:       stringPtr(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))),
+		},
+	}})
+
+	provider := &akvKeyManagementProvider{config: AKVKeyManagementProviderConfig{Certificates: []KeyVaultValue{{Name: "cert"}}}}
+	certificates, status, err := provider.GetCertificates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	certs := certificates[keymanagementprovider.KMPMapKey{Name: "cert", Version: "v1"}]
+	if len(certs) != 1 || certs[0].Subject.CommonName != "test" {
+		t.Fatalf("unexpected certificates %+v", certificates)
+	}
+	if certsStatus, ok := status[CertificatesStatus].([]map[string]string); !ok || len(certsStatus) != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	provider.config.Certificates = []KeyVaultValue{{Name: "missing"}}
+	if _, _, err := provider.GetCertificates(context.Background()); err == nil {
+		t.Fatalf("expected error for missing certificate")
+	}
+}
+
+func TestGetKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	stubKeyVaultClient(t, &testKeyVaultClient{keys: map[string]kv.KeyBundle{
+		"ec": {Key: &kv.JSONWebKey{
+			Kid: stringPtr("https://test.vault.azure.net/keys/ec/v1"),
+			Kty: kv.EC,
+			Crv: kv.P384,
+			X:   encodeBigInt(ecKey.X),
+			Y:   encodeBigInt(ecKey.Y),
+		}},
+		"rsa": {Key: &kv.JSONWebKey{
+			Kid: stringPtr("https://test.vault.azure.net/keys/rsa/v2"),
+			Kty: kv.RSAHSM,
+			N:   encodeBigInt(rsaKey.N),
+			E:   encodeBigInt(big.NewInt(int64(rsaKey.E))),
+		}},
+		"oct": {Key: &kv.JSONWebKey{
+			Kid: stringPtr("https://test.vault.azure.net/keys/oct/v1"),
+			Kty: kv.Oct,
+		}},
+	}})
+
+	provider := &akvKeyManagementProvider{config: AKVKeyManagementProviderConfig{Keys: []KeyVaultValue{{Name: "ec"}, {Name: "rsa"}}}}
+	keys, status, err := provider.GetKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ecKey.PublicKey.Equal(keys[keymanagementprovider.KMPMapKey{Name: "ec", Version: "v1"}]) {
+		t.Fatalf("unexpected ec key %+v", keys)
+	}
+	if !rsaKey.PublicKey.Equal(keys[keymanagementprovider.KMPMapKey{Name: "rsa", Version: "v2"}]) {
+		t.Fatalf("unexpected rsa key %+v", keys)
+	}
+	if keysStatus, ok := status[KeysStatus].([]map[string]string); !ok || len(keysStatus) != 2 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	provider.config.Keys = []KeyVaultValue{{Name: "oct"}}
+	if _, _, err := provider.GetKeys(context.Background()); err == nil {
+		t.Fatalf("expected error for unsupported key type")
+	}
+}
@@ -0,0 +1,24 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// Type is the key of the provider type in the config of a key management
+// provider.
+const Type = "type"
+
+// KeyManagementProviderConfig is the config of a key management provider, it
+// contains the type of the provider and its parameters.
+type KeyManagementProviderConfig map[string]interface{}
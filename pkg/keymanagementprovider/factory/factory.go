@@ -0,0 +1,58 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"fmt"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/utils"
+)
+
+// builtInKeyManagementProviders maps the provider types to their factories
+var builtInKeyManagementProviders = make(map[string]KeyManagementProviderFactory)
+
+// KeyManagementProviderFactory creates key management providers of a type.
+type KeyManagementProviderFactory interface {
+	Create(config config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error)
+}
+
+// Register adds the factory to the built in providers map
+func Register(name string, factory KeyManagementProviderFactory) {
+	if factory == nil {
+		panic("key management provider factory cannot be nil")
+	}
+	if _, registered := builtInKeyManagementProviders[name]; registered {
+		panic(fmt.Sprintf("key management provider factory named %s already registered", name))
+	}
+	builtInKeyManagementProviders[name] = factory
+}
+
+// CreateKeyManagementProviderFromConfig creates a key management provider of
+// the type in the config.
+func CreateKeyManagementProviderFromConfig(kmpConfig config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error) {
+	providerType, ok := kmpConfig[config.Type].(string)
+	if !ok || providerType == "" {
+		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.KeyManagementProvider).WithDetail(fmt.Sprintf("failed to find key management provider type in the config with key %s", config.Type))
+	}
+	factory, ok := builtInKeyManagementProviders[utils.TrimSpaceAndToLower(providerType)]
+	if !ok {
+		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.KeyManagementProvider).WithDetail(fmt.Sprintf("unknown key management provider type %s", providerType))
+	}
+	return factory.Create(kmpConfig)
+}
@@ -0,0 +1,475 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashicorpvault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/certificateprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const (
+	providerType = "hashicorpvault"
+
+	// EngineTransit reads the public key of a key of the transit secrets engine.
+	EngineTransit = "transit"
+	// EngineKV reads a PEM encoded value of the KV secrets engine.
+	EngineKV = "kv"
+
+	defaultTransitPath       = "transit"
+	defaultKVPath            = "secret"
+	defaultKVVersion         = 2
+	defaultKubernetesPath    = "kubernetes"
+	defaultServiceTokenPath  = "/var/run/secrets/kubernetes.io/serviceaccount/token" // #nosec G101
+	defaultCertificateField  = "certificate"
+	defaultKeyField          = "key"
+	tokenMinRemainingRenewal = 10 * time.Second
+
+	// CertificatesStatus is the key of the certificate status property
+	CertificatesStatus = "Certificates"
+	// KeysStatus is the key of the key status property
+	KeysStatus = "Keys"
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.KeyManagementProvider,
+}
+
+// HashiCorpVaultKeyManagementProviderConfig describes the configuration of the
+// HashiCorp Vault key management provider.
+type HashiCorpVaultKeyManagementProviderConfig struct { //nolint:revive // ignore linter to have unique type name
+	Type string `json:"type"`
+	// Address of the Vault server, defaults to the VAULT_ADDR environment
+	// variable.
+	Address string `json:"address,omitempty"`
+	// Namespace of Vault Enterprise the engines are mounted in.
+	Namespace string `json:"namespace,omitempty"`
+	// Auth configures the Kubernetes auth method, the token of the VAULT_TOKEN
+	// environment variable is used if not set.
+	Auth *AuthConfig `json:"auth,omitempty"`
+	// TransitPath is the mount path of the transit secrets engine, defaults to
+	// transit.
+	TransitPath string `json:"transitPath,omitempty"`
+	// KVPath is the mount path of the KV secrets engine, defaults to secret.
+	KVPath string `json:"kvPath,omitempty"`
+	// KVVersion is the version of the KV secrets engine, 1 or 2. Defaults to 2.
+	KVVersion int `json:"kvVersion,omitempty"`
+	// Certificates are the PEM encoded certificates (chains) to read from the
+	// KV secrets engine.
+	Certificates []VaultValue `json:"certificates,omitempty"`
+	// Keys are the public keys to read from the transit or KV secrets engine.
+	Keys []VaultValue `json:"keys,omitempty"`
+}
+
+// AuthConfig describes the Kubernetes auth method used to log in to Vault.
+type AuthConfig struct {
+	// Role is the Vault role bound to the service account of Ratify.
+	Role string `json:"role"`
+	// MountPath is the mount path of the Kubernetes auth method, defaults to
+	// kubernetes.
+	MountPath string `json:"mountPath,omitempty"`
+	// TokenPath is the path of the service account token, defaults to the
+	// token mounted in the pod.
+	TokenPath string `json:"tokenPath,omitempty"`
+}
+
+// VaultValue identifies a certificate or key stored in Vault.
+type VaultValue struct {
+	// Name is the name of the key of the transit secrets engine, or the name
+	// the value of the KV secrets engine is reported with.
+	Name string `json:"name"`
+	// Engine is transit or kv, defaults to transit for keys. Certificates are
+	// always read from the KV secrets engine.
+	Engine string `json:"engine,omitempty"`
+	// Path of the secret of the KV secrets engine, defaults to the name.
+	Path string `json:"path,omitempty"`
+	// Field of the secret holding the PEM encoded value, defaults to
+	// certificate for certificates and key for keys.
+	Field string `json:"field,omitempty"`
+	// Version of the key or of the KV v2 secret, the latest version is read if
+	// empty.
+	Version string `json:"version,omitempty"`
+}
+
+// vaultToken is a token issued by the Kubernetes auth method.
+type vaultToken struct {
+	token     string
+	ttl       time.Duration
+	expiry    time.Time
+	renewable bool
+}
+
+var (
+	tokensMu sync.Mutex
+	// tokens issued by the Kubernetes auth method, keyed by server and role,
+	// are kept across refreshes so that they are renewed instead of logging in
+	// on every refresh
+	tokens = map[string]vaultToken{}
+)
+
+type hashiCorpVaultKeyManagementProvider struct {
+	config HashiCorpVaultKeyManagementProviderConfig
+	client *vault.Client
+}
+
+type hashiCorpVaultKeyManagementProviderFactory struct{}
+
+func init() {
+	factory.Register(providerType, &hashiCorpVaultKeyManagementProviderFactory{})
+}
+
+// Create validates the config and creates the Vault client.
+func (f *hashiCorpVaultKeyManagementProviderFactory) Create(kmpConfig config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error) {
+	conf := HashiCorpVaultKeyManagementProviderConfig{}
+	configBytes, err := json.Marshal(kmpConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	if err := json.Unmarshal(configBytes, &conf); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, "failed to parse hashicorp vault key management provider configuration", re.HideStackTrace)
+	}
+	if err := setDefaults(&conf); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+
+	vaultConfig := vault.DefaultConfig()
+	if vaultConfig.Error != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, vaultConfig.Error, "failed to read vault configuration", re.HideStackTrace)
+	}
+	if conf.Address != "" {
+		vaultConfig.Address = conf.Address
+	}
+	client, err := vault.NewClient(vaultConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, "failed to create vault client", re.HideStackTrace)
+	}
+	if conf.Namespace != "" {
+		client.SetNamespace(conf.Namespace)
+	}
+
+	return &hashiCorpVaultKeyManagementProvider{config: conf, client: client}, nil
+}
+
+// GetCertificates reads the certificates from the KV secrets engine.
+func (p *hashiCorpVaultKeyManagementProvider) GetCertificates(ctx context.Context) (map[keymanagementprovider.KMPMapKey][]*x509.Certificate, keymanagementprovider.KeyManagementProviderStatus, error) {
+	certificates := map[keymanagementprovider.KMPMapKey][]*x509.Certificate{}
+	certsStatus := []map[string]string{}
+	if len(p.config.Certificates) == 0 {
+		return certificates, nil, nil
+	}
+	if err := p.authenticate(ctx); err != nil {
+		return nil, nil, err
+	}
+	for _, cert := range p.config.Certificates {
+		logger.GetLogger(ctx, logOpt).Debugf("fetching certificate %s from vault %s", cert.Name, p.client.Address())
+		value, version, err := p.readKV(ctx, cert)
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, fmt.Sprintf("failed to read certificate %s", cert.Name), re.HideStackTrace)
+		}
+		decoded, err := certificateprovider.DecodeCertificates([]byte(value))
+		if err != nil {
+			return nil, nil, re.ErrorCodeCertInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, fmt.Sprintf("failed to decode certificate %s", cert.Name), re.HideStackTrace)
+		}
+		certificates[keymanagementprovider.KMPMapKey{Name: cert.Name, Version: version}] = decoded
+		certsStatus = append(certsStatus, map[string]string{
+			"CertificateName": cert.Name,
+			"Version":         version,
+			"LastRefreshed":   time.Now().Format(time.RFC3339),
+		})
+	}
+	return certificates, keymanagementprovider.KeyManagementProviderStatus{CertificatesStatus: certsStatus}, nil
+}
+
+// GetKeys reads the public keys from the transit or KV secrets engine.
+func (p *hashiCorpVaultKeyManagementProvider) GetKeys(ctx context.Context) (map[keymanagementprovider.KMPMapKey]crypto.PublicKey, keymanagementprovider.KeyManagementProviderStatus, error) {
+	keys := map[keymanagementprovider.KMPMapKey]crypto.PublicKey{}
+	keysStatus := []map[string]string{}
+	if len(p.config.Keys) == 0 {
+		return keys, nil, nil
+	}
+	if err := p.authenticate(ctx); err != nil {
+		return nil, nil, err
+	}
+	for _, key := range p.config.Keys {
+		logger.GetLogger(ctx, logOpt).Debugf("fetching key %s from vault %s", key.Name, p.client.Address())
+		var publicKey crypto.PublicKey
+		var version string
+		var err error
+		if key.Engine == EngineKV {
+			var value string
+			if value, version, err = p.readKV(ctx, key); err == nil {
+				publicKey, err = keymanagementprovider.DecodeKey([]byte(value))
+			}
+		} else {
+			publicKey, version, err = p.readTransitKey(ctx, key)
+		}
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, fmt.Sprintf("failed to read key %s", key.Name), re.HideStackTrace)
+		}
+		keys[keymanagementprovider.KMPMapKey{Name: key.Name, Version: version}] = publicKey
+		keysStatus = append(keysStatus, map[string]string{
+			"KeyName":       key.Name,
+			"Version":       version,
+			"Fingerprint":   keymanagementprovider.KeyFingerprint(publicKey),
+			"LastRefreshed": time.Now().Format(time.RFC3339),
+		})
+	}
+	return keys, keymanagementprovider.KeyManagementProviderStatus{KeysStatus: keysStatus}, nil
+}
+
+// authenticate sets the token of the Kubernetes auth method on the client. The
+// token is reused while at least half of its TTL remains, renewed if it is
+// renewable and replaced by logging in again otherwise.
+func (p *hashiCorpVaultKeyManagementProvider) authenticate(ctx context.Context) error {
+	if p.config.Auth == nil {
+		return nil
+	}
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	cacheKey := strings.Join([]string{p.client.Address(), p.config.Namespace, p.config.Auth.MountPath, p.config.Auth.Role}, "|")
+	token, ok := tokens[cacheKey]
+	now := time.Now()
+	if ok && (token.expiry.IsZero() || token.expiry.Sub(now) > token.ttl/2) {
+		p.client.SetToken(token.token)
+		return nil
+	}
+	if ok && token.renewable && token.expiry.Sub(now) > tokenMinRemainingRenewal {
+		p.client.SetToken(token.token)
+		secret, err := p.client.Auth().Token().RenewSelfWithContext(ctx, 0)
+		if err == nil && secret != nil && secret.Auth != nil {
+			tokens[cacheKey] = newVaultToken(token.token, secret.Auth, now)
+			logger.GetLogger(ctx, logOpt).Debugf("renewed vault token of role %s", p.config.Auth.Role)
+			return nil
+		}
+		logger.GetLogger(ctx, logOpt).Warnf("failed to renew vault token of role %s, logging in again: %v", p.config.Auth.Role, err)
+	}
+
+	jwt, err := os.ReadFile(p.config.Auth.TokenPath)
+	if err != nil {
+		return re.ErrorCodeAuthDenied.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, "failed to read service account token", re.HideStackTrace)
+	}
+	secret, err := p.client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", p.config.Auth.MountPath), map[string]interface{}{
+		"role": p.config.Auth.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return re.ErrorCodeAuthDenied.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, fmt.Sprintf("failed to log in to vault with role %s", p.config.Auth.Role), re.HideStackTrace)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return re.ErrorCodeAuthDenied.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, fmt.Sprintf("no token returned logging in to vault with role %s", p.config.Auth.Role), re.HideStackTrace)
+	}
+	tokens[cacheKey] = newVaultToken(secret.Auth.ClientToken, secret.Auth, now)
+	p.client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+// readTransitKey returns the public key of the version of the transit key.
+func (p *hashiCorpVaultKeyManagementProvider) readTransitKey(ctx context.Context, key VaultValue) (crypto.PublicKey, string, error) {
+	secret, err := p.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/keys/%s", p.config.TransitPath, key.Name))
+	if err != nil {
+		return nil, "", err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, "", fmt.Errorf("transit key %s not found", key.Name)
+	}
+	version := key.Version
+	if version == "" {
+		latest, ok := secret.Data["latest_version"].(json.Number)
+		if !ok {
+			return nil, "", fmt.Errorf("latest version of transit key %s not found", key.Name)
+		}
+		version = latest.String()
+	}
+	versions, ok := secret.Data["keys"].(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("transit key %s has no versions", key.Name)
+	}
+	keyVersion, ok := versions[version].(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("version %s of transit key %s not found", version, key.Name)
+	}
+	value, ok := keyVersion["public_key"].(string)
+	if !ok || value == "" {
+		return nil, "", fmt.Errorf("transit key %s is not an asymmetric key", key.Name)
+	}
+	publicKey, err := decodeTransitPublicKey(value)
+	if err != nil {
+		return nil, "", err
+	}
+	return publicKey, version, nil
+}
+
+// readKV returns the field of the secret of the KV secrets engine and its
+// version, the version is empty for the KV v1 secrets engine.
+func (p *hashiCorpVaultKeyManagementProvider) readKV(ctx context.Context, value VaultValue) (string, string, error) {
+	var secret *vault.Secret
+	var err error
+	if p.config.KVVersion == 1 {
+		secret, err = p.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/%s", p.config.KVPath, value.Path))
+	} else {
+		var data map[string][]string
+		if value.Version != "" {
+			data = map[string][]string{"version": {value.Version}}
+		}
+		secret, err = p.client.Logical().ReadWithDataWithContext(ctx, fmt.Sprintf("%s/data/%s", p.config.KVPath, value.Path), data)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if secret == nil || secret.Data == nil {
+		return "", "", fmt.Errorf("secret %s not found", value.Path)
+	}
+
+	data, version := secret.Data, ""
+	if p.config.KVVersion != 1 {
+		if data, _ = secret.Data["data"].(map[string]interface{}); data == nil {
+			return "", "", fmt.Errorf("secret %s is deleted", value.Path)
+		}
+		if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+			if number, ok := metadata["version"].(json.Number); ok {
+				version = number.String()
+			}
+		}
+	}
+	field, ok := data[value.Field].(string)
+	if !ok || field == "" {
+		return "", "", fmt.Errorf("field %s of secret %s not found", value.Field, value.Path)
+	}
+	return field, version, nil
+}
+
+// decodeTransitPublicKey decodes the PEM encoded RSA and ECDSA keys and the
+// base64 encoded ed25519 keys returned by the transit secrets engine.
+func decodeTransitPublicKey(value string) (crypto.PublicKey, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return keymanagementprovider.DecodeKey([]byte(value))
+	}
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, re.ErrorCodeKeyInvalid.WithComponentType(re.KeyManagementProvider).WithDetail("failed to decode transit public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+func newVaultToken(token string, auth *vault.SecretAuth, now time.Time) vaultToken {
+	ttl := time.Duration(auth.LeaseDuration) * time.Second
+	result := vaultToken{token: token, ttl: ttl, renewable: auth.Renewable}
+	if ttl > 0 {
+		result.expiry = now.Add(ttl)
+	}
+	return result
+}
+
+func setDefaults(conf *HashiCorpVaultKeyManagementProviderConfig) error {
+	if len(conf.Certificates) == 0 && len(conf.Keys) == 0 {
+		return fmt.Errorf("no certificates or keys configured")
+	}
+	if conf.TransitPath == "" {
+		conf.TransitPath = defaultTransitPath
+	}
+	if conf.KVPath == "" {
+		conf.KVPath = defaultKVPath
+	}
+	conf.TransitPath = strings.Trim(conf.TransitPath, "/")
+	conf.KVPath = strings.Trim(conf.KVPath, "/")
+	switch conf.KVVersion {
+	case 0:
+		conf.KVVersion = defaultKVVersion
+	case 1, 2:
+	default:
+		return fmt.Errorf("kvVersion must be 1 or 2, got %d", conf.KVVersion)
+	}
+	if conf.Auth != nil {
+		if conf.Auth.Role == "" {
+			return fmt.Errorf("role of the kubernetes auth method is not set")
+		}
+		if conf.Auth.MountPath == "" {
+			conf.Auth.MountPath = defaultKubernetesPath
+		}
+		conf.Auth.MountPath = strings.Trim(conf.Auth.MountPath, "/")
+		if conf.Auth.TokenPath == "" {
+			conf.Auth.TokenPath = defaultServiceTokenPath
+		}
+	}
+
+	for i := range conf.Certificates {
+		cert := &conf.Certificates[i]
+		if cert.Engine != "" && cert.Engine != EngineKV {
+			return fmt.Errorf("certificate %s must be read from the %s secrets engine", cert.Name, EngineKV)
+		}
+		cert.Engine = EngineKV
+		if cert.Field == "" {
+			cert.Field = defaultCertificateField
+		}
+		if err := setValueDefaults(cert, conf.KVVersion); err != nil {
+			return err
+		}
+	}
+	for i := range conf.Keys {
+		key := &conf.Keys[i]
+		switch key.Engine {
+		case "":
+			key.Engine = EngineTransit
+		case EngineTransit, EngineKV:
+		default:
+			return fmt.Errorf("engine of key %s must be %s or %s, got %s", key.Name, EngineTransit, EngineKV, key.Engine)
+		}
+		if key.Field == "" {
+			key.Field = defaultKeyField
+		}
+		if err := setValueDefaults(key, conf.KVVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValueDefaults(value *VaultValue, kvVersion int) error {
+	if value.Name == "" {
+		return fmt.Errorf("name of the certificate or key is not set")
+	}
+	if value.Path == "" {
+		value.Path = value.Name
+	}
+	value.Path = strings.Trim(value.Path, "/")
+	if value.Version == "" {
+		return nil
+	}
+	if value.Engine == EngineKV && kvVersion == 1 {
+		return fmt.Errorf("version of %s is not supported by the KV v1 secrets engine", value.Name)
+	}
+	if _, err := strconv.Atoi(value.Version); err != nil {
+		return fmt.Errorf("version of %s must be a number, got %s", value.Name, value.Version)
+	}
+	return nil
+}
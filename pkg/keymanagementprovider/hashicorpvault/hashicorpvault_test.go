@@ -0,0 +1,351 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashicorpvault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+)
+
+func newTestKeyPEM(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func newTestCertificatePEM(t *testing.T) string {
+	t.Helper()
+	key, _ := newTestKeyPEM(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ratify"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func writeVaultResponse(t *testing.T, w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		t.Errorf("failed to write response: %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	keys := []interface{}{map[string]interface{}{"name": "cosign"}}
+	testCases := []struct {
+		name      string
+		config    config.KeyManagementProviderConfig
+		expectErr bool
+	}{
+		{
+			name:   "transit key",
+			config: config.KeyManagementProviderConfig{"type": providerType, "keys": keys},
+		},
+		{
+			name:   "kv certificate with kubernetes auth",
+			config: config.KeyManagementProviderConfig{"type": providerType, "auth": map[string]interface{}{"role": "ratify"}, "certificates": []interface{}{map[string]interface{}{"name": "ca", "version": "2"}}},
+		},
+		{
+			name:      "no certificates or keys",
+			config:    config.KeyManagementProviderConfig{"type": providerType},
+			expectErr: true,
+		},
+		{
+			name:      "auth without role",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "auth": map[string]interface{}{}, "keys": keys},
+			expectErr: true,
+		},
+		{
+			name:      "invalid kv version",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "kvVersion": 3, "keys": keys},
+			expectErr: true,
+		},
+		{
+			name:      "invalid key engine",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "keys": []interface{}{map[string]interface{}{"name": "cosign", "engine": "pki"}}},
+			expectErr: true,
+		},
+		{
+			name:      "certificate from transit engine",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "certificates": []interface{}{map[string]interface{}{"name": "ca", "engine": EngineTransit}}},
+			expectErr: true,
+		},
+		{
+			name:      "versioned kv v1 secret",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "kvVersion": 1, "certificates": []interface{}{map[string]interface{}{"name": "ca", "version": "1"}}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid version",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "keys": []interface{}{map[string]interface{}{"name": "cosign", "version": "latest"}}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &hashiCorpVaultKeyManagementProviderFactory{}
+			_, err := factory.Create(tc.config)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestGetCertificatesAndKeys(t *testing.T) {
+	_, transitKey1 := newTestKeyPEM(t)
+	_, transitKey2 := newTestKeyPEM(t)
+	_, kvKey := newTestKeyPEM(t)
+	certificate := newTestCertificatePEM(t)
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("service-account-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write service account token: %v", err)
+	}
+
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/k8s/login" {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role"] != "ratify" || body["jwt"] != "service-account-token" {
+				t.Errorf("unexpected login request %v: %v", body, err)
+			}
+			logins++
+			writeVaultResponse(t, w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "vault-token", "lease_duration": 3600, "renewable": true}})
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/transit/keys/cosign":
+			writeVaultResponse(t, w, map[string]interface{}{"data": map[string]interface{}{
+				"latest_version": 2,
+				"keys": map[string]interface{}{
+					"1": map[string]interface{}{"public_key": transitKey1},
+					"2": map[string]interface{}{"public_key": transitKey2},
+				},
+			}})
+		case "/v1/secret/data/cosign/pub":
+			writeVaultResponse(t, w, map[string]interface{}{"data": map[string]interface{}{
+				"data":     map[string]interface{}{"key": kvKey},
+				"metadata": map[string]interface{}{"version": 1},
+			}})
+		case "/v1/secret/data/notation/ca":
+			if r.URL.Query().Get("version") != "3" {
+				t.Errorf("expected version 3 of the certificate, got %q", r.URL.Query().Get("version"))
+			}
+			writeVaultResponse(t, w, map[string]interface{}{"data": map[string]interface{}{
+				"data":     map[string]interface{}{"certificate": certificate},
+				"metadata": map[string]interface{}{"version": 3},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	factory := &hashiCorpVaultKeyManagementProviderFactory{}
+	provider, err := factory.Create(config.KeyManagementProviderConfig{
+		"type":    providerType,
+		"address": server.URL,
+		"auth":    map[string]interface{}{"role": "ratify", "mountPath": "/k8s/", "tokenPath": tokenPath},
+		"certificates": []interface{}{
+			map[string]interface{}{"name": "notation-ca", "path": "notation/ca", "version": "3"},
+		},
+		"keys": []interface{}{
+			map[string]interface{}{"name": "cosign"},
+			map[string]interface{}{"name": "cosign-kv", "engine": EngineKV, "path": "cosign/pub"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx := context.Background()
+	certificates, certStatus, err := provider.GetCertificates(ctx)
+	if err != nil {
+		t.Fatalf("failed to get certificates: %v", err)
+	}
+	if len(certificates[keymanagementprovider.KMPMapKey{Name: "notation-ca", Version: "3"}]) != 1 {
+		t.Fatalf("expected version 3 of certificate notation-ca, got %v", certificates)
+	}
+	if len(certStatus[CertificatesStatus].([]map[string]string)) != 1 {
+		t.Fatalf("expected status of 1 certificate, got %v", certStatus)
+	}
+
+	keys, keyStatus, err := provider.GetKeys(ctx)
+	if err != nil {
+		t.Fatalf("failed to get keys: %v", err)
+	}
+	expectedKey, _ := keymanagementprovider.DecodeKey([]byte(transitKey2))
+	if key, ok := keys[keymanagementprovider.KMPMapKey{Name: "cosign", Version: "2"}]; !ok || keymanagementprovider.KeyFingerprint(key) != keymanagementprovider.KeyFingerprint(expectedKey) {
+		t.Fatalf("expected latest version of transit key cosign, got %v", keys)
+	}
+	if _, ok := keys[keymanagementprovider.KMPMapKey{Name: "cosign-kv", Version: "1"}]; !ok {
+		t.Fatalf("expected kv key cosign-kv, got %v", keys)
+	}
+	if len(keyStatus[KeysStatus].([]map[string]string)) != 2 {
+		t.Fatalf("expected status of 2 keys, got %v", keyStatus)
+	}
+	if logins != 1 {
+		t.Fatalf("expected the token to be reused, got %d logins", logins)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("service-account-token"), 0o600); err != nil {
+		t.Fatalf("failed to write service account token: %v", err)
+	}
+	var logins, renewals int
+	renewFails := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			logins++
+			writeVaultResponse(t, w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "new-token", "lease_duration": 3600, "renewable": true}})
+		case "/v1/auth/token/renew-self":
+			renewals++
+			if renewFails || r.Header.Get("X-Vault-Token") != "old-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			writeVaultResponse(t, w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "old-token", "lease_duration": 3600, "renewable": true}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	factory := &hashiCorpVaultKeyManagementProviderFactory{}
+	created, err := factory.Create(config.KeyManagementProviderConfig{
+		"type":    providerType,
+		"address": server.URL,
+		"auth":    map[string]interface{}{"role": "renew", "tokenPath": tokenPath},
+		"keys":    []interface{}{map[string]interface{}{"name": "cosign"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	provider := created.(*hashiCorpVaultKeyManagementProvider)
+	cacheKey := server.URL + "||kubernetes|renew"
+	defer func() {
+		tokensMu.Lock()
+		delete(tokens, cacheKey)
+		tokensMu.Unlock()
+	}()
+
+	testCases := []struct {
+		name          string
+		token         vaultToken
+		renewFails    bool
+		expectedToken string
+		logins        int
+		renewals      int
+	}{
+		{
+			name:          "valid token",
+			token:         vaultToken{token: "old-token", ttl: time.Hour, expiry: time.Now().Add(50 * time.Minute), renewable: true},
+			expectedToken: "old-token",
+		},
+		{
+			name:          "renewable token expiring",
+			token:         vaultToken{token: "old-token", ttl: time.Hour, expiry: time.Now().Add(10 * time.Minute), renewable: true},
+			expectedToken: "old-token",
+			renewals:      1,
+		},
+		{
+			name:          "renewal failure",
+			token:         vaultToken{token: "old-token", ttl: time.Hour, expiry: time.Now().Add(10 * time.Minute), renewable: true},
+			renewFails:    true,
+			expectedToken: "new-token",
+			logins:        1,
+			renewals:      1,
+		},
+		{
+			name:          "expired token",
+			token:         vaultToken{token: "old-token", ttl: time.Hour, expiry: time.Now().Add(-time.Minute), renewable: true},
+			expectedToken: "new-token",
+			logins:        1,
+		},
+		{
+			name:          "token not renewable",
+			token:         vaultToken{token: "old-token", ttl: time.Hour, expiry: time.Now().Add(10 * time.Minute)},
+			expectedToken: "new-token",
+			logins:        1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logins, renewals, renewFails = 0, 0, tc.renewFails
+			tokensMu.Lock()
+			tokens[cacheKey] = tc.token
+			tokensMu.Unlock()
+			if err := provider.authenticate(context.Background()); err != nil {
+				t.Fatalf("failed to authenticate: %v", err)
+			}
+			if provider.client.Token() != tc.expectedToken {
+				t.Fatalf("expected token %s, got %s", tc.expectedToken, provider.client.Token())
+			}
+			if logins != tc.logins || renewals != tc.renewals {
+				t.Fatalf("expected %d logins and %d renewals, got %d and %d", tc.logins, tc.renewals, logins, renewals)
+			}
+		})
+	}
+}
+
+func TestDecodeTransitPublicKey(t *testing.T) {
+	_, pemKey := newTestKeyPEM(t)
+	if _, err := decodeTransitPublicKey(pemKey); err != nil {
+		t.Fatalf("failed to decode PEM key: %v", err)
+	}
+	if _, err := decodeTransitPublicKey("mOLkZ4B9FoHqLpwGvU0m3HRrvOpNjG03xqb2iN3uN3Y="); err != nil {
+		t.Fatalf("failed to decode ed25519 key: %v", err)
+	}
+	if _, err := decodeTransitPublicKey("not a key"); err == nil {
+		t.Fatalf("expected error decoding invalid key")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inline
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/certificateprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+)
+
+const (
+	providerType = "inline"
+
+	// ContentTypeCertificate is the content type of PEM encoded certificates.
+	ContentTypeCertificate = "certificate"
+	// ContentTypeKey is the content type of a PEM encoded public key.
+	ContentTypeKey = "key"
+)
+
+// InlineKeyManagementProviderConfig describes the configuration of the inline
+// key management provider.
+type InlineKeyManagementProviderConfig struct { //nolint:revive // ignore linter to have unique type name
+	Type string `json:"type"`
+	// ContentType is certificate or key.
+	ContentType string `json:"contentType"`
+	// Value is the PEM encoded certificate (chain) or public key.
+	Value string `json:"value"`
+}
+
+type inlineKeyManagementProvider struct {
+	certificates map[keymanagementprovider.KMPMapKey][]*x509.Certificate
+	keys         map[keymanagementprovider.KMPMapKey]crypto.PublicKey
+}
+
+type inlineKeyManagementProviderFactory struct{}
+
+func init() {
+	factory.Register(providerType, &inlineKeyManagementProviderFactory{})
+}
+
+// Create decodes the certificates or the key of the config.
+func (f *inlineKeyManagementProviderFactory) Create(kmpConfig config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error) {
+	conf := InlineKeyManagementProviderConfig{}
+	configBytes, err := json.Marshal(kmpConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	if err := json.Unmarshal(configBytes, &conf); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, "failed to parse inline key management provider configuration", re.HideStackTrace)
+	}
+	if conf.Value == "" {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, "value parameter is not set", re.HideStackTrace)
+	}
+
+	provider := &inlineKeyManagementProvider{
+		certificates: map[keymanagementprovider.KMPMapKey][]*x509.Certificate{},
+		keys:         map[keymanagementprovider.KMPMapKey]crypto.PublicKey{},
+	}
+	switch conf.ContentType {
+	case ContentTypeCertificate:
+		certs, err := certificateprovider.DecodeCertificates([]byte(conf.Value))
+		if err != nil {
+			return nil, re.ErrorCodeCertInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+		}
+		provider.certificates[keymanagementprovider.KMPMapKey{}] = certs
+	case ContentTypeKey:
+		key, err := keymanagementprovider.DecodeKey([]byte(conf.Value))
+		if err != nil {
+			return nil, err
+		}
+		provider.keys[keymanagementprovider.KMPMapKey{}] = key
+	default:
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, providerType, re.EmptyLink, nil, fmt.Sprintf("contentType must be %s or %s, got %q", ContentTypeCertificate, ContentTypeKey, conf.ContentType), re.HideStackTrace)
+	}
+	return provider, nil
+}
+
+// GetCertificates returns the inline certificates.
+func (p *inlineKeyManagementProvider) GetCertificates(_ context.Context) (map[keymanagementprovider.KMPMapKey][]*x509.Certificate, keymanagementprovider.KeyManagementProviderStatus, error) {
+	return p.certificates, nil, nil
+}
+
+// GetKeys returns the inline key.
+func (p *inlineKeyManagementProvider) GetKeys(_ context.Context) (map[keymanagementprovider.KMPMapKey]crypto.PublicKey, keymanagementprovider.KeyManagementProviderStatus, error) {
+	return p.keys, nil, nil
+}
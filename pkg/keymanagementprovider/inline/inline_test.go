@@ -0,0 +1,117 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+)
+
+const (
+	testKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE6H7nl2xFyQ4wMflMLSYVKWwBjnAE
+gn0jMcCLLimfgrL7kIH8TLT9eUkfEBAplYd+ORpWbRwy9ifvsF4l7wCoBg==
+-----END PUBLIC KEY-----`
+	testCert = `-----BEGIN CERTIFICATE-----
+MIIDNTCCAh2gAwIBAgIUbLzOMsPOGflj7TS34tzjs5vBWYIwDQYJKoZIhvcNAQEL
+BQAwKjEPMA0GA1UECgwGUmF0aWZ5MRcwFQYDVQQDDA5SYXRpZnkgUm9vdCBDQTAe
+Fw0yMzAzMTAwMTEwMjlaFw0yNDAzMDkwMTEwMjlaMCoxDzANBgNVBAoMBlJhdGlm
+eTEXMBUGA1UEAwwOUmF0aWZ5IFJvb3QgQ0EwggEiMA0GCSqGSIb3DQEBAQUAA4IB
+DwAwggEKAoIBAQCeNlh95GnkHLBVSCoYmlPztNKw5jwmlZYWLgwZMOK0qKedlsZs
+axbb5YzQlIV/z8D+/DnsZ3hTmokset0hE6JDQ1lw5wjk1I8DijkjwiE3oVH5Kyv/
+PSUtbSP7LmNDG/2vtWBkyTltXf3SfqAazLXVd0IQqGTXie+2SJa9Q6UAZFWKB1t7
+0Js6rDyQULcUMSzvF39QBPHFcd9iuSZLPw9CGG7hHNTlaOQryukr6U9tjY4hLK7e
+1OdgyP17nXELIlL81ngoWufi3rLgePqfkg8GgwnWyFrDS2eZSofFqW0X80gDvEQ+
+5WGj4XklyGGHpwyR4qNMnv5hp7QMtDd62iyPAgMBAAGjUzBRMB0GA1UdDgQWBBQQ
+Gxu39HR2ynlSR40ZySohaxKGMDAfBgNVHSMEGDAWgBQQGxu39HR2ynlSR40ZySoh
+axKGMDAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUAA4IBAQB22EixNuBZ
+yeGtrtSpkVPer+nxKU+6upwXmLfTe3ZEbv1NqC2auUvD+EN/86+mJfeXhkdVHHoV
+teuXNVU0oJ4ocRUXkgA/jPUEnjXwYK661/N67mqr5wLHcKIt48yKNdMvfXO6tXSE
+x/xLQlX7v2JehEQtTv7axnYGoHOvKL2H0+lq5VN0rDnB5hb7LyRX9kQq6mszLzG5
+bypfWX1aReWViWU2d6hvVegDYFpRpPXE3o5FGWvrad91RXdSJLsjJGdYJMlUe3xR
++guQHepJ0iCoreAr+0XkctcV0qlyOiIuikWMdrQiXXTIDCGTRNmD9XCTu5+2tZe+
+W2O8QLkvH3Fv
+-----END CERTIFICATE-----`
+)
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    config.KeyManagementProviderConfig
+		certs     int
+		keys      int
+		expectErr bool
+	}{
+		{
+			name:   "certificate",
+			config: config.KeyManagementProviderConfig{"type": "Inline", "contentType": ContentTypeCertificate, "value": testCert},
+			certs:  1,
+		},
+		{
+			name:   "key",
+			config: config.KeyManagementProviderConfig{"type": providerType, "contentType": ContentTypeKey, "value": testKey},
+			keys:   1,
+		},
+		{
+			name:      "invalid key",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "contentType": ContentTypeKey, "value": testCert},
+			expectErr: true,
+		},
+		{
+			name:      "unknown content type",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "contentType": "secret", "value": testKey},
+			expectErr: true,
+		},
+		{
+			name:      "missing value",
+			config:    config.KeyManagementProviderConfig{"type": providerType, "contentType": ContentTypeKey},
+			expectErr: true,
+		},
+		{
+			name:      "unknown type",
+			config:    config.KeyManagementProviderConfig{"type": "unknown", "contentType": ContentTypeKey, "value": testKey},
+			expectErr: true,
+		},
+		{
+			name:      "missing type",
+			config:    config.KeyManagementProviderConfig{"contentType": ContentTypeKey, "value": testKey},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, err := factory.CreateKeyManagementProviderFromConfig(tc.config)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			certs, _, err := provider.GetCertificates(context.Background())
+			if err != nil || len(certs[keymanagementprovider.KMPMapKey{}]) != tc.certs {
+				t.Fatalf("expected %d certificates, got %+v, error %v", tc.certs, certs, err)
+			}
+			keys, _, err := provider.GetKeys(context.Background())
+			if err != nil || len(keys) != tc.keys {
+				t.Fatalf("expected %d keys, got %+v, error %v", tc.keys, keys, err)
+			}
+		})
+	}
+}
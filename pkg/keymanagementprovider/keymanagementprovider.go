@@ -0,0 +1,321 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keymanagementprovider
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"sort"
+	"sync"
+
+	"github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.KeyManagementProvider,
+}
+
+// KMPMapKey identifies a certificate or key of a key management provider.
+type KMPMapKey struct {
+	Name    string
+	Version string
+}
+
+// KeyManagementProviderStatus is a map of properties of the fetched
+// certificates and keys, the keys and values are specific to each provider.
+type KeyManagementProviderStatus map[string]interface{}
+
+// KeyManagementProvider is an interface that defines methods to be implemented
+// by each key management provider.
+type KeyManagementProvider interface {
+	// GetCertificates returns the certificates of the provider keyed by name and
+	// version, and the provider specific properties of the certificates.
+	GetCertificates(ctx context.Context) (map[KMPMapKey][]*x509.Certificate, KeyManagementProviderStatus, error)
+	// GetKeys returns the public keys of the provider keyed by name and
+	// version, and the provider specific properties of the keys.
+	GetKeys(ctx context.Context) (map[KMPMapKey]crypto.PublicKey, KeyManagementProviderStatus, error)
+}
+
+// MaxVersionHistory bounds the number of previously fetched versions kept for
+// each certificate and key.
+const MaxVersionHistory = 10
+
+var (
+	mu sync.RWMutex
+	// a map between the resource name of a key management provider and its certificates
+	certificatesMap = map[string]map[KMPMapKey][]*x509.Certificate{}
+	// a map between the resource name of a key management provider and its keys
+	keysMap = map[string]map[KMPMapKey]crypto.PublicKey{}
+	// the previously fetched versions of the certificates of each resource by
+	// name, newest first
+	certificatesHistory = map[string]map[string][]versionEntry{}
+	// the previously fetched versions of the keys of each resource by name,
+	// newest first
+	keysHistory = map[string]map[string][]versionEntry{}
+	// the error of the last fetch of each resource, until a fetch succeeds
+	fetchErrors = map[string]string{}
+)
+
+// versionEntry is a previously fetched version of a certificate or a key.
+type versionEntry struct {
+	version      string
+	certificates []*x509.Certificate
+	key          crypto.PublicKey
+}
+
+// SetCertificatesInMap replaces the certificates of the resource, rotated
+// certificates are logged. Up to maxHistory replaced versions of each
+// certificate are kept so that artifacts signed before a rotation still verify.
+func SetCertificatesInMap(ctx context.Context, resource string, certificates map[KMPMapKey][]*x509.Certificate, maxHistory int) {
+	mu.Lock()
+	defer mu.Unlock()
+	replaced := map[string][]versionEntry{}
+	for _, key := range sortKeys(certificateKeys(certificatesMap[resource])) {
+		certs := certificatesMap[resource][key]
+		// newest version first
+		replaced[key.Name] = append([]versionEntry{{version: certificatesVersion(key, certs), certificates: certs}}, replaced[key.Name]...)
+	}
+	current := map[string]map[string]bool{}
+	for key, certs := range certificates {
+		version := certificatesVersion(key, certs)
+		if previous, ok := replaced[key.Name]; ok && previous[0].version != version {
+			logger.GetLogger(ctx, logOpt).Infof("certificate %s of key management provider %s rotated from version %s to %s", key.Name, resource, previous[0].version, version)
+		}
+		addVersion(current, key.Name, version)
+	}
+	certificatesMap[resource] = certificates
+	certificatesHistory[resource] = mergeHistory(current, replaced, certificatesHistory[resource], maxHistory)
+}
+
+// SetKeysInMap replaces the keys of the resource, rotated keys are logged. Up
+// to maxHistory replaced versions of each key are kept so that artifacts signed
+// before a rotation still verify.
+func SetKeysInMap(ctx context.Context, resource string, keys map[KMPMapKey]crypto.PublicKey, maxHistory int) {
+	mu.Lock()
+	defer mu.Unlock()
+	replaced := map[string][]versionEntry{}
+	for _, key := range sortKeys(publicKeyKeys(keysMap[resource])) {
+		publicKey := keysMap[resource][key]
+		// newest version first
+		replaced[key.Name] = append([]versionEntry{{version: keyVersion(key, publicKey), key: publicKey}}, replaced[key.Name]...)
+	}
+	current := map[string]map[string]bool{}
+	for key, publicKey := range keys {
+		version := keyVersion(key, publicKey)
+		if previous, ok := replaced[key.Name]; ok && previous[0].version != version {
+			logger.GetLogger(ctx, logOpt).Infof("key %s of key management provider %s rotated from version %s to %s", key.Name, resource, previous[0].version, version)
+		}
+		addVersion(current, key.Name, version)
+	}
+	keysMap[resource] = keys
+	keysHistory[resource] = mergeHistory(current, replaced, keysHistory[resource], maxHistory)
+}
+
+// GetCertificatesFromMap returns the certificates of the resource ordered by
+// name and version, followed by the previously fetched versions kept in the
+// history, newest first.
+func GetCertificatesFromMap(resource string) []*x509.Certificate {
+	mu.RLock()
+	defer mu.RUnlock()
+	certificates := certificatesMap[resource]
+	var result []*x509.Certificate
+	for _, key := range sortKeys(certificateKeys(certificates)) {
+		result = append(result, certificates[key]...)
+	}
+	history := certificatesHistory[resource]
+	for _, name := range sortedNames(history) {
+		for _, entry := range history[name] {
+			result = append(result, entry.certificates...)
+		}
+	}
+	return result
+}
+
+// GetKeysFromMap returns the keys of the resource ordered by name and version,
+// followed by the previously fetched versions kept in the history, newest
+// first.
+func GetKeysFromMap(resource string) []crypto.PublicKey {
+	mu.RLock()
+	defer mu.RUnlock()
+	publicKeys := keysMap[resource]
+	keys := sortKeys(publicKeyKeys(publicKeys))
+	result := make([]crypto.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, publicKeys[key])
+	}
+	history := keysHistory[resource]
+	for _, name := range sortedNames(history) {
+		for _, entry := range history[name] {
+			result = append(result, entry.key)
+		}
+	}
+	return result
+}
+
+// DeleteResourceFromMap removes the certificates and keys of the resource and
+// their history.
+func DeleteResourceFromMap(resource string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(certificatesMap, resource)
+	delete(keysMap, resource)
+	delete(certificatesHistory, resource)
+	delete(keysHistory, resource)
+	delete(fetchErrors, resource)
+}
+
+// SetFetchError records the error of the last fetch of the certificates and
+// keys of the resource, an empty error clears it. Previously fetched
+// certificates and keys are still used while the fetch fails.
+func SetFetchError(resource string, errorString string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if errorString == "" {
+		delete(fetchErrors, resource)
+		return
+	}
+	fetchErrors[resource] = errorString
+}
+
+// GetFetchErrors returns the errors of the resources whose last fetch failed
+// by resource name.
+func GetFetchErrors() map[string]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make(map[string]string, len(fetchErrors))
+	for resource, errorString := range fetchErrors {
+		result[resource] = errorString
+	}
+	return result
+}
+
+// DecodeKey decodes a PEM encoded public key.
+func DecodeKey(value []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(value)
+	if block == nil {
+		return nil, errors.ErrorCodeKeyInvalid.WithComponentType(errors.KeyManagementProvider).WithDetail("failed to decode pem block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.ErrorCodeKeyInvalid.WithComponentType(errors.KeyManagementProvider).WithError(err).WithDetail("failed to parse public key")
+	}
+	return key, nil
+}
+
+// KeyFingerprint returns the hex encoded sha256 digest of the DER encoded
+// public key, an empty string if the key cannot be encoded.
+func KeyFingerprint(key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return ""
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:])
+}
+
+// certificatesVersion returns the version of the certificates, the
+// fingerprint of the leaf certificate if the provider does not version them.
+func certificatesVersion(key KMPMapKey, certificates []*x509.Certificate) string {
+	if key.Version != "" || len(certificates) == 0 {
+		return key.Version
+	}
+	digest := sha256.Sum256(certificates[0].Raw)
+	return hex.EncodeToString(digest[:])
+}
+
+// keyVersion returns the version of the key, the fingerprint of the key if the
+// provider does not version it.
+func keyVersion(key KMPMapKey, publicKey crypto.PublicKey) string {
+	if key.Version != "" {
+		return key.Version
+	}
+	return KeyFingerprint(publicKey)
+}
+
+func sortKeys(keys []KMPMapKey) []KMPMapKey {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].Version < keys[j].Version
+	})
+	return keys
+}
+
+func certificateKeys(certificates map[KMPMapKey][]*x509.Certificate) []KMPMapKey {
+	keys := make([]KMPMapKey, 0, len(certificates))
+	for key := range certificates {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func publicKeyKeys(publicKeys map[KMPMapKey]crypto.PublicKey) []KMPMapKey {
+	keys := make([]KMPMapKey, 0, len(publicKeys))
+	for key := range publicKeys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func addVersion(versions map[string]map[string]bool, name string, version string) {
+	if versions[name] == nil {
+		versions[name] = map[string]bool{}
+	}
+	versions[name][version] = true
+}
+
+// mergeHistory returns the history of the names still fetched, the versions
+// replaced by the refresh are followed by the older history, at most
+// maxHistory versions are kept per name. The history of names that are no
+// longer fetched is dropped.
+func mergeHistory(current map[string]map[string]bool, replaced map[string][]versionEntry, previous map[string][]versionEntry, maxHistory int) map[string][]versionEntry {
+	history := map[string][]versionEntry{}
+	if maxHistory <= 0 {
+		return history
+	}
+	for name, versions := range current {
+		var entries []versionEntry
+		seen := map[string]bool{}
+		for _, candidates := range [][]versionEntry{replaced[name], previous[name]} {
+			for _, entry := range candidates {
+				if versions[entry.version] || seen[entry.version] || len(entries) == maxHistory {
+					continue
+				}
+				seen[entry.version] = true
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) > 0 {
+			history[name] = entries
+		}
+	}
+	return history
+}
+
+func sortedNames(history map[string][]versionEntry) []string {
+	names := make([]string, 0, len(history))
+	for name := range history {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,204 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keymanagementprovider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func TestKeysMap(t *testing.T) {
+	resource := "default/kmp"
+	defer DeleteResourceFromMap(resource)
+	key1, key2 := generateKey(t), generateKey(t)
+
+	SetKeysInMap(context.Background(), resource, map[KMPMapKey]crypto.PublicKey{
+		{Name: "b", Version: "1"}: key2.Public(),
+		{Name: "a", Version: "1"}: key1.Public(),
+	}, 0)
+	keys := GetKeysFromMap(resource)
+	if len(keys) != 2 || !key1.PublicKey.Equal(keys[0]) || !key2.PublicKey.Equal(keys[1]) {
+		t.Fatalf("expected keys ordered by name, got %+v", keys)
+	}
+
+	// the rotated key replaces the previous version
+	SetKeysInMap(context.Background(), resource, map[KMPMapKey]crypto.PublicKey{
+		{Name: "a", Version: "2"}: key2.Public(),
+	}, 0)
+	keys = GetKeysFromMap(resource)
+	if len(keys) != 1 || !key2.PublicKey.Equal(keys[0]) {
+		t.Fatalf("expected rotated key, got %+v", keys)
+	}
+
+	DeleteResourceFromMap(resource)
+	if keys := GetKeysFromMap(resource); len(keys) != 0 {
+		t.Fatalf("expected no keys after deletion, got %+v", keys)
+	}
+}
+
+func TestCertificatesMap(t *testing.T) {
+	resource := "default/kmp"
+	defer DeleteResourceFromMap(resource)
+	cert1, cert2 := &x509.Certificate{Raw: []byte("cert1")}, &x509.Certificate{Raw: []byte("cert2")}
+
+	SetCertificatesInMap(context.Background(), resource, map[KMPMapKey][]*x509.Certificate{
+		{Name: "a", Version: "2"}: {cert2},
+		{Name: "a", Version: "1"}: {cert1},
+	}, 0)
+	certs := GetCertificatesFromMap(resource)
+	if len(certs) != 2 || certs[0] != cert1 || certs[1] != cert2 {
+		t.Fatalf("expected certificates ordered by version, got %+v", certs)
+	}
+	if certs := GetCertificatesFromMap("default/other"); len(certs) != 0 {
+		t.Fatalf("expected no certificates of other resource, got %+v", certs)
+	}
+}
+
+func TestKeysMap_History(t *testing.T) {
+	resource := "default/kmp"
+	defer DeleteResourceFromMap(resource)
+	key1, key2, key3, key4, other := generateKey(t), generateKey(t), generateKey(t), generateKey(t), generateKey(t)
+
+	for _, keys := range []map[KMPMapKey]crypto.PublicKey{
+		{{Name: "a", Version: "1"}: key1.Public(), {Name: "b", Version: "1"}: other.Public()},
+		{{Name: "a", Version: "2"}: key2.Public(), {Name: "b", Version: "1"}: other.Public()},
+		{{Name: "a", Version: "3"}: key3.Public(), {Name: "b", Version: "1"}: other.Public()},
+	} {
+		SetKeysInMap(context.Background(), resource, keys, 2)
+	}
+	// the current keys are followed by the replaced versions, newest first
+	expected := []*ecdsa.PrivateKey{key3, other, key2, key1}
+	keys := GetKeysFromMap(resource)
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %d keys, got %d", len(expected), len(keys))
+	}
+	for i, key := range expected {
+		if !key.PublicKey.Equal(keys[i]) {
+			t.Fatalf("unexpected key at index %d", i)
+		}
+	}
+
+	// the history is bounded and a version fetched again is not duplicated
+	SetKeysInMap(context.Background(), resource, map[KMPMapKey]crypto.PublicKey{{Name: "a", Version: "4"}: key4.Public(), {Name: "b", Version: "1"}: other.Public()}, 2)
+	SetKeysInMap(context.Background(), resource, map[KMPMapKey]crypto.PublicKey{{Name: "a", Version: "3"}: key3.Public(), {Name: "b", Version: "1"}: other.Public()}, 2)
+	expected = []*ecdsa.PrivateKey{key3, other, key4, key2}
+	keys = GetKeysFromMap(resource)
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %d keys, got %d", len(expected), len(keys))
+	}
+	for i, key := range expected {
+		if !key.PublicKey.Equal(keys[i]) {
+			t.Fatalf("unexpected key at index %d", i)
+		}
+	}
+
+	// the history of keys that are no longer fetched is dropped
+	SetKeysInMap(context.Background(), resource, map[KMPMapKey]crypto.PublicKey{{Name: "b", Version: "1"}: other.Public()}, 2)
+	if keys = GetKeysFromMap(resource); len(keys) != 1 {
+		t.Fatalf("expected history of removed key to be dropped, got %d keys", len(keys))
+	}
+}
+
+func TestCertificatesMap_History(t *testing.T) {
+	resource := "default/kmp"
+	defer DeleteResourceFromMap(resource)
+	cert1, cert2 := &x509.Certificate{Raw: []byte("cert1")}, &x509.Certificate{Raw: []byte("cert2")}
+
+	// unversioned certificates are kept by fingerprint
+	SetCertificatesInMap(context.Background(), resource, map[KMPMapKey][]*x509.Certificate{{Name: "a"}: {cert1}}, 1)
+	SetCertificatesInMap(context.Background(), resource, map[KMPMapKey][]*x509.Certificate{{Name: "a"}: {cert2}}, 1)
+	certs := GetCertificatesFromMap(resource)
+	if len(certs) != 2 || certs[0] != cert2 || certs[1] != cert1 {
+		t.Fatalf("expected current and previous certificate, got %+v", certs)
+	}
+
+	// the history is dropped if it is disabled
+	SetCertificatesInMap(context.Background(), resource, map[KMPMapKey][]*x509.Certificate{{Name: "a"}: {cert2}}, 0)
+	if certs = GetCertificatesFromMap(resource); len(certs) != 1 || certs[0] != cert2 {
+		t.Fatalf("expected current certificate only, got %+v", certs)
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	key := generateKey(t)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	decoded, err := DecodeKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !key.PublicKey.Equal(decoded) {
+		t.Fatalf("decoded key does not match")
+	}
+
+	if _, err := DecodeKey([]byte("not a key")); err == nil {
+		t.Fatalf("expected error for invalid pem")
+	}
+	if _, err := DecodeKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("invalid")})); err == nil {
+		t.Fatalf("expected error for invalid key")
+	}
+}
+
+func TestVersions(t *testing.T) {
+	key := generateKey(t)
+	if version := keyVersion(KMPMapKey{Name: "a", Version: "1"}, key.Public()); version != "1" {
+		t.Fatalf("expected version 1, got %s", version)
+	}
+	if version := keyVersion(KMPMapKey{Name: "a"}, key.Public()); version != KeyFingerprint(key.Public()) {
+		t.Fatalf("expected fingerprint of unversioned key, got %s", version)
+	}
+	cert := &x509.Certificate{Raw: []byte("cert")}
+	if version := certificatesVersion(KMPMapKey{Name: "a"}, []*x509.Certificate{cert}); version == "" {
+		t.Fatalf("expected fingerprint of unversioned certificates")
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	SetFetchError("ns/kmp", "failed to fetch")
+	errs := GetFetchErrors()
+	if errs["ns/kmp"] != "failed to fetch" {
+		t.Fatalf("expected fetch error to be recorded, got %v", errs)
+	}
+	errs["ns/kmp"] = "modified"
+	if GetFetchErrors()["ns/kmp"] != "failed to fetch" {
+		t.Fatalf("expected a copy of the fetch errors")
+	}
+	SetFetchError("ns/kmp", "")
+	if _, ok := GetFetchErrors()["ns/kmp"]; ok {
+		t.Fatalf("expected fetch error to be cleared")
+	}
+	SetFetchError("ns/kmp", "failed to fetch")
+	DeleteResourceFromMap("ns/kmp")
+	if _, ok := GetFetchErrors()["ns/kmp"]; ok {
+		t.Fatalf("expected fetch error to be deleted with the resource")
+	}
+}
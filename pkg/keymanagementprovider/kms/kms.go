@@ -0,0 +1,178 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"        // register AWS KMS
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/gcp"        // register GCP KMS
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/hashivault" // register HashiCorp Vault
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+const (
+	// AWSKMS is the type of the provider of AWS KMS keys.
+	AWSKMS = "awskms"
+	// GCPKMS is the type of the provider of GCP Cloud KMS keys.
+	GCPKMS = "gcpkms"
+	// HashiVault is the type of the provider of keys of the HashiCorp Vault
+	// transit secrets engine.
+	HashiVault = "hashivault"
+
+	// KeysStatus is the key of the key status property
+	KeysStatus = "Keys"
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.KeyManagementProvider,
+}
+
+// getSignerVerifier loads a key of a KMS, it is replaced in tests
+var getSignerVerifier = sigkms.Get
+
+// KMSKeyManagementProviderConfig describes the configuration of the providers
+// of keys stored in a KMS.
+type KMSKeyManagementProviderConfig struct { //nolint:revive // ignore linter to have unique type name
+	Type string `json:"type"`
+	// Keys are the keys to fetch.
+	Keys []KeySpec `json:"keys"`
+	// Address of the Vault server, defaults to the VAULT_ADDR environment
+	// variable. Only applies to hashivault.
+	Address string `json:"address,omitempty"`
+	// TransitPath is the mount path of the transit secrets engine, defaults to
+	// transit. Only applies to hashivault.
+	TransitPath string `json:"transitPath,omitempty"`
+}
+
+// KeySpec identifies a key of the KMS.
+type KeySpec struct {
+	// Name is the key ID, alias or ARN of an AWS KMS key, the resource name of
+	// a GCP Cloud KMS key, e.g. projects/p/locations/l/keyRings/r/cryptoKeys/k,
+	// or the name of a key of the Vault transit secrets engine.
+	Name string `json:"name"`
+	// Version of the key, the latest version is fetched if empty. AWS KMS keys
+	// are not versioned.
+	Version string `json:"version,omitempty"`
+}
+
+type kmsKeyManagementProvider struct {
+	providerType string
+	keys         []KeySpec
+	rpcAuth      options.RPCAuth
+}
+
+type kmsKeyManagementProviderFactory struct {
+	providerType string
+}
+
+func init() {
+	for _, providerType := range []string{AWSKMS, GCPKMS, HashiVault} {
+		factory.Register(providerType, &kmsKeyManagementProviderFactory{providerType: providerType})
+	}
+}
+
+// Create validates the keys of the config.
+func (f *kmsKeyManagementProviderFactory) Create(kmpConfig config.KeyManagementProviderConfig) (keymanagementprovider.KeyManagementProvider, error) {
+	conf := KMSKeyManagementProviderConfig{}
+	configBytes, err := json.Marshal(kmpConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, f.providerType, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	if err := json.Unmarshal(configBytes, &conf); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, f.providerType, re.EmptyLink, err, "failed to parse kms key management provider configuration", re.HideStackTrace)
+	}
+	if len(conf.Keys) == 0 {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, f.providerType, re.EmptyLink, nil, "no keys configured", re.HideStackTrace)
+	}
+	for _, key := range conf.Keys {
+		if key.Name == "" {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, f.providerType, re.EmptyLink, nil, "name of the key is not set", re.HideStackTrace)
+		}
+		if key.Version != "" && f.providerType == AWSKMS {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.KeyManagementProvider, f.providerType, re.EmptyLink, nil, fmt.Sprintf("version of key %s is not supported, AWS KMS keys are not versioned", key.Name), re.HideStackTrace)
+		}
+	}
+
+	return &kmsKeyManagementProvider{
+		providerType: f.providerType,
+		keys:         conf.Keys,
+		rpcAuth:      options.RPCAuth{Address: conf.Address, Path: conf.TransitPath},
+	}, nil
+}
+
+// GetCertificates returns no certificates, KMS only store keys.
+func (p *kmsKeyManagementProvider) GetCertificates(_ context.Context) (map[keymanagementprovider.KMPMapKey][]*x509.Certificate, keymanagementprovider.KeyManagementProviderStatus, error) {
+	return map[keymanagementprovider.KMPMapKey][]*x509.Certificate{}, nil, nil
+}
+
+// GetKeys fetches the public keys from the KMS.
+func (p *kmsKeyManagementProvider) GetKeys(ctx context.Context) (map[keymanagementprovider.KMPMapKey]crypto.PublicKey, keymanagementprovider.KeyManagementProviderStatus, error) {
+	keys := map[keymanagementprovider.KMPMapKey]crypto.PublicKey{}
+	keysStatus := []map[string]string{}
+	for _, key := range p.keys {
+		reference, opts := p.reference(key)
+		logger.GetLogger(ctx, logOpt).Debugf("fetching key %s from %s", reference, p.providerType)
+		signerVerifier, err := getSignerVerifier(ctx, reference, crypto.SHA256, opts...)
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, p.providerType, re.EmptyLink, err, fmt.Sprintf("failed to load key %s", key.Name), re.HideStackTrace)
+		}
+		publicKey, err := signerVerifier.PublicKey()
+		if err != nil {
+			return nil, nil, re.ErrorCodeKeyVaultOperationFailure.NewError(re.KeyManagementProvider, p.providerType, re.EmptyLink, err, fmt.Sprintf("failed to get public key %s", key.Name), re.HideStackTrace)
+		}
+		keys[keymanagementprovider.KMPMapKey{Name: key.Name, Version: key.Version}] = publicKey
+		keysStatus = append(keysStatus, map[string]string{
+			"KeyName":       key.Name,
+			"Version":       key.Version,
+			"Fingerprint":   keymanagementprovider.KeyFingerprint(publicKey),
+			"LastRefreshed": time.Now().Format(time.RFC3339),
+		})
+	}
+	return keys, keymanagementprovider.KeyManagementProviderStatus{KeysStatus: keysStatus}, nil
+}
+
+// reference returns the sigstore KMS reference of the key and the options to
+// load it.
+func (p *kmsKeyManagementProvider) reference(key KeySpec) (string, []signature.RPCOption) {
+	switch p.providerType {
+	case AWSKMS:
+		return "awskms:///" + key.Name, nil
+	case GCPKMS:
+		if key.Version != "" {
+			return fmt.Sprintf("gcpkms://%s/cryptoKeyVersions/%s", key.Name, key.Version), nil
+		}
+		return "gcpkms://" + key.Name, nil
+	default:
+		opts := []signature.RPCOption{options.WithRPCAuthOpts(p.rpcAuth)}
+		if key.Version != "" {
+			opts = append(opts, options.WithKeyVersion(key.Version))
+		}
+		return "hashivault://" + key.Name, opts
+	}
+}
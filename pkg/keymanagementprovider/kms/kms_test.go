@@ -0,0 +1,137 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/config"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider/factory"
+	"github.com/sigstore/sigstore/pkg/signature"
+	sigkms "github.com/sigstore/sigstore/pkg/signature/kms"
+)
+
+type testSignerVerifier struct {
+	sigkms.SignerVerifier
+	publicKey crypto.PublicKey
+}
+
+func (s testSignerVerifier) PublicKey(_ ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return s.publicKey, nil
+}
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    config.KeyManagementProviderConfig
+		expectErr bool
+	}{
+		{
+			name:   "aws key",
+			config: config.KeyManagementProviderConfig{"type": AWSKMS, "keys": []interface{}{map[string]interface{}{"name": "alias/key"}}},
+		},
+		{
+			name:      "aws key with version",
+			config:    config.KeyManagementProviderConfig{"type": AWSKMS, "keys": []interface{}{map[string]interface{}{"name": "alias/key", "version": "1"}}},
+			expectErr: true,
+		},
+		{
+			name:   "vault key with version",
+			config: config.KeyManagementProviderConfig{"type": HashiVault, "keys": []interface{}{map[string]interface{}{"name": "key", "version": "1"}}},
+		},
+		{
+			name:      "no keys",
+			config:    config.KeyManagementProviderConfig{"type": GCPKMS},
+			expectErr: true,
+		},
+		{
+			name:      "key without name",
+			config:    config.KeyManagementProviderConfig{"type": GCPKMS, "keys": []interface{}{map[string]interface{}{"version": "1"}}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := factory.CreateKeyManagementProviderFromConfig(tc.config)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	testCases := []struct {
+		providerType string
+		key          KeySpec
+		expected     string
+		options      int
+	}{
+		{providerType: AWSKMS, key: KeySpec{Name: "alias/key"}, expected: "awskms:///alias/key"},
+		{providerType: GCPKMS, key: KeySpec{Name: "projects/p/locations/l/keyRings/r/cryptoKeys/k"}, expected: "gcpkms://projects/p/locations/l/keyRings/r/cryptoKeys/k"},
+		{providerType: GCPKMS, key: KeySpec{Name: "projects/p/locations/l/keyRings/r/cryptoKeys/k", Version: "2"}, expected: "gcpkms://projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/2"},
+		{providerType: HashiVault, key: KeySpec{Name: "key"}, expected: "hashivault://key", options: 1},
+		{providerType: HashiVault, key: KeySpec{Name: "key", Version: "2"}, expected: "hashivault://key", options: 2},
+	}
+	for _, tc := range testCases {
+		provider := &kmsKeyManagementProvider{providerType: tc.providerType}
+		reference, opts := provider.reference(tc.key)
+		if reference != tc.expected || len(opts) != tc.options {
+			t.Fatalf("expected reference %s with %d options, got %s with %d options", tc.expected, tc.options, reference, len(opts))
+		}
+	}
+}
+
+func TestGetKeys(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	defer func(get func(context.Context, string, crypto.Hash, ...signature.RPCOption) (sigkms.SignerVerifier, error)) {
+		getSignerVerifier = get
+	}(getSignerVerifier)
+	getSignerVerifier = func(_ context.Context, reference string, _ crypto.Hash, _ ...signature.RPCOption) (sigkms.SignerVerifier, error) {
+		if reference != "awskms:///alias/key" {
+			return nil, fmt.Errorf("key %s not found", reference)
+		}
+		return testSignerVerifier{publicKey: privateKey.Public()}, nil
+	}
+
+	provider := &kmsKeyManagementProvider{providerType: AWSKMS, keys: []KeySpec{{Name: "alias/key"}}}
+	keys, status, err := provider.GetKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !privateKey.PublicKey.Equal(keys[keymanagementprovider.KMPMapKey{Name: "alias/key"}]) {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+	keysStatus, ok := status[KeysStatus].([]map[string]string)
+	if !ok || len(keysStatus) != 1 || keysStatus[0]["Fingerprint"] != keymanagementprovider.KeyFingerprint(privateKey.Public()) {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	provider.keys = []KeySpec{{Name: "alias/missing"}}
+	if _, _, err := provider.GetKeys(context.Background()); err == nil {
+		t.Fatalf("expected error for missing key")
+	}
+}
@@ -17,6 +17,7 @@ package manager
 
 import (
 	"context"
+	"crypto"
 	"crypto/x509"
 	"flag"
 	"fmt"
@@ -30,13 +31,19 @@ import (
 	"github.com/deislabs/ratify/config"
 	"github.com/deislabs/ratify/httpserver"
 	"github.com/deislabs/ratify/pkg/featureflag"
+	"github.com/deislabs/ratify/pkg/metrics"
 	"github.com/deislabs/ratify/pkg/policyprovider"
 	_ "github.com/deislabs/ratify/pkg/policyprovider/configpolicy" // register config policy provider
 	_ "github.com/deislabs/ratify/pkg/policyprovider/regopolicy"   // register rego policy provider
-	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"          // register ORAS referrer store
+	"github.com/deislabs/ratify/pkg/preflight"
+	_ "github.com/deislabs/ratify/pkg/referrerstore/oras" // register ORAS referrer store
+	su "github.com/deislabs/ratify/pkg/referrerstore/utils"
+	"github.com/deislabs/ratify/pkg/selfverify"
 	"github.com/deislabs/ratify/pkg/utils"
 	_ "github.com/deislabs/ratify/pkg/verifier/notation" // register notation verifier
+	_ "github.com/deislabs/ratify/pkg/verifier/static"   // register static verifier
 	"github.com/open-policy-agent/cert-controller/pkg/rotator"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // import additional authentication methods
 
@@ -45,6 +52,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	configv1alpha1 "github.com/deislabs/ratify/api/v1alpha1"
@@ -59,6 +67,9 @@ import (
 const (
 	caOrganization = "Ratify"
 	certDir        = "/usr/local/tls"
+	// policyWebhookName is the name of the ValidatingWebhookConfiguration
+	// of policies installed by the chart.
+	policyWebhookName = "ratify-policy-validation"
 )
 
 var (
@@ -74,7 +85,7 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
-func StartServer(httpServerAddress, configFilePath, certDirectory, caCertFile string, cacheTTL time.Duration, metricsEnabled bool, metricsType string, metricsPort int, certRotatorReady chan struct{}) {
+func StartServer(httpServerAddress, configFilePath, certDirectory, caCertFile string, cacheTTL time.Duration, metricsEnabled bool, metricsType string, metricsPort int, metricsPush metrics.PushConfig, rateLimit httpserver.RateLimitConfig, clientAuth httpserver.ClientAuthConfig, admin httpserver.AdminConfig, healthChecks httpserver.HealthCheckConfig, requestLimit httpserver.RequestLimitConfig, reportSigner crypto.Signer, denialRecorder httpserver.DenialRecorder, auditSink httpserver.AuditSink, distributedLock httpserver.DistributedLock, mutationStores []string, mutationPlatform *oci.Platform, mutationDigested su.DigestedReferenceMode, grpcAddress string, drainTimeout time.Duration, certRotatorReady chan struct{}) {
 	logrus.Info("initializing executor with config file at default config path")
 
 	cf, err := config.Load(configFilePath)
@@ -89,52 +100,43 @@ func StartServer(httpServerAddress, configFilePath, certDirectory, caCertFile st
 		os.Exit(1)
 	}
 
+	// verify the Ratify image and plugins with the verifiers of the configuration file
+	// since verifier resources are not reconciled yet
+	if err := selfverify.Run(context.Background(), cf.SelfVerifyConfig, &ef.Executor{
+		Verifiers:      configVerifiers,
+		ReferrerStores: configStores,
+		PolicyEnforcer: policy,
+		Config:         &cf.ExecutorConfig,
+	}, config.GetPluginDirs(cf)); err != nil {
+		logrus.Errorf("server start failed %v", err)
+		os.Exit(1)
+	}
+
 	// initialize server
 	server, err := httpserver.NewServer(context.Background(), httpServerAddress, func() *ef.Executor {
-		var activeVerifiers []vr.ReferenceVerifier
-		var activeStores []referrerstore.ReferrerStore
-		var activePolicyEnforcer policyprovider.PolicyProvider
-
-		// check if there are active verifiers from crd controller
-		// else use verifiers from configuration
-		if len(controllers.VerifierMap) > 0 {
-			for _, value := range controllers.VerifierMap {
-				activeVerifiers = append(activeVerifiers, value)
-			}
-		} else {
-			activeVerifiers = configVerifiers
-		}
-
-		// check if there are active stores from crd controller
-		// else use stores from configuration
-		if len(controllers.StoreMap) > 0 {
-			for _, value := range controllers.StoreMap {
-				activeStores = append(activeStores, value)
-			}
-		} else {
-			activeStores = configStores
-		}
-
-		if !controllers.ActivePolicy.IsEmpty() {
-			activePolicyEnforcer = controllers.ActivePolicy.Enforcer
-		} else {
-			activePolicyEnforcer = policy
-		}
-
-		// return executor with latest configuration
-		executor := ef.Executor{
-			Verifiers:      activeVerifiers,
-			ReferrerStores: activeStores,
-			PolicyEnforcer: activePolicyEnforcer,
-			Config:         &cf.ExecutorConfig,
-		}
-		return &executor
+		return activeExecutor(cf, configStores, configVerifiers, policy)
 	}, certDirectory, caCertFile, cacheTTL, metricsEnabled, metricsType, metricsPort)
 
 	if err != nil {
 		logrus.Errorf("initialize server failed with error %v, exiting..", err)
 		os.Exit(1)
 	}
+	server.MetricsPush = metricsPush
+	server.RateLimit = rateLimit
+	server.ClientAuth = clientAuth
+	server.Admin = admin
+	server.HealthChecks = healthChecks
+	server.RequestLimit = requestLimit
+	server.ReportSigner = reportSigner
+	server.DenialRecorder = denialRecorder
+	server.AuditSink = auditSink
+	server.DistributedLock = distributedLock
+	server.DrainTimeout = drainTimeout
+	server.MutationStoreName = mutationStores[0]
+	server.MutationFallbackStoreNames = mutationStores[1:]
+	server.MutationPlatform = mutationPlatform
+	server.MutationDigestedReferences = mutationDigested
+	server.GRPCAddress = grpcAddress
 	logrus.Infof("starting server at" + httpServerAddress)
 	if err := server.Run(certRotatorReady); err != nil {
 		logrus.Errorf("starting server failed with error %v, exiting..", err)
@@ -142,6 +144,83 @@ func StartServer(httpServerAddress, configFilePath, certDirectory, caCertFile st
 	}
 }
 
+// Preflight loads the configuration file and, if enableCrdManager is set, the
+// resources in the cluster, creates all components and probes them without
+// starting the server.
+func Preflight(ctx context.Context, configFilePath string, enableCrdManager bool, opts preflight.Options) *preflight.Report {
+	report := preflight.NewReport()
+	cf, err := config.Load(configFilePath)
+	if err != nil {
+		report.Fail(preflight.KindConfig, "config", fmt.Errorf("error loading config %w", err))
+		return report
+	}
+	configStores, configVerifiers, policy, err := config.CreateFromConfig(cf)
+	if err != nil {
+		report.Fail(preflight.KindConfig, "config", fmt.Errorf("error initializing from config %w", err))
+		return report
+	}
+	report.Pass(preflight.KindConfig, "config", "components of the configuration file created")
+
+	var keyProviders []preflight.KeyProvider
+	if enableCrdManager {
+		restConfig, err := ctrl.GetConfig()
+		if err != nil {
+			report.Fail(preflight.KindConfig, "kubeconfig", err)
+			return report
+		}
+		c, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			report.Fail(preflight.KindConfig, "kubeconfig", err)
+			return report
+		}
+		keyProviders = controllers.LoadResources(ctx, c, report)
+	}
+	if !report.Success {
+		return report
+	}
+	return preflight.Run(ctx, report, activeExecutor(cf, configStores, configVerifiers, policy), keyProviders, opts)
+}
+
+// activeExecutor returns an executor with the components reconciled from the
+// resources, falling back to the components of the configuration file.
+func activeExecutor(cf config.Config, configStores []referrerstore.ReferrerStore, configVerifiers []vr.ReferenceVerifier, policy policyprovider.PolicyProvider) *ef.Executor {
+	var activeStores []referrerstore.ReferrerStore
+	var activePolicyEnforcer policyprovider.PolicyProvider
+
+	// check if there are active verifiers from crd controller
+	// else use verifiers from configuration
+	activeVerifiers := controllers.ActiveVerifiers()
+	if len(activeVerifiers) == 0 {
+		activeVerifiers = configVerifiers
+	}
+
+	// check if there are active stores from crd controller
+	// else use stores from configuration
+	if len(controllers.StoreMap) > 0 {
+		for _, value := range controllers.StoreMap {
+			activeStores = append(activeStores, value)
+		}
+	} else {
+		activeStores = configStores
+	}
+
+	if !controllers.ActivePolicy.IsEmpty() {
+		activePolicyEnforcer = controllers.ActivePolicy.Enforcer
+	} else {
+		activePolicyEnforcer = policy
+	}
+
+	// return executor with latest configuration
+	executor := ef.Executor{
+		Verifiers:        activeVerifiers,
+		ReferrerStores:   activeStores,
+		PolicyEnforcer:   activePolicyEnforcer,
+		Config:           &cf.ExecutorConfig,
+		ConfigGeneration: controllers.ConfigGeneration(),
+	}
+	return &executor
+}
+
 func StartManager(certRotatorReady chan struct{}, probeAddr string) {
 	var metricsAddr string
 	var enableLeaderElection bool
@@ -158,6 +237,7 @@ func StartManager(certRotatorReady chan struct{}, probeAddr string) {
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
+		CertDir:                certDir,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "1a306109.github.com/deislabs/ratify",
@@ -200,6 +280,12 @@ func StartManager(certRotatorReady chan struct{}, probeAddr string) {
 				Type: rotator.ExternalDataProvider,
 			},
 		}
+		if featureflag.PolicyWebhook.Enabled {
+			webhooks = append(webhooks, rotator.WebhookInfo{
+				Name: policyWebhookName,
+				Type: rotator.Validating,
+			})
+		}
 		namespace := utils.GetNamespace()
 		serviceName := utils.GetServiceName()
 
@@ -244,6 +330,13 @@ func StartManager(certRotatorReady chan struct{}, probeAddr string) {
 		setupLog.Error(err, "unable to create controller", "controller", "Certificate Store")
 		os.Exit(1)
 	}
+	if err = (&controllers.KeyManagementProviderReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Key Management Provider")
+		os.Exit(1)
+	}
 	if err = (&controllers.PolicyReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -251,6 +344,12 @@ func StartManager(certRotatorReady chan struct{}, probeAddr string) {
 		setupLog.Error(err, "unable to create controller", "controller", "Policy")
 		os.Exit(1)
 	}
+	if featureflag.PolicyWebhook.Enabled {
+		if err = (&controllers.PolicyValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Policy")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -16,27 +16,49 @@ limitations under the License.
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/sdk/metric"
 )
 
-const prometheusExporter = "prometheus"
+const (
+	prometheusExporter = "prometheus"
+	otlpExporter       = "otlp"
+	statsdExporter     = "statsd"
+
+	// DefaultPushInterval is the default interval at which the otlp and statsd
+	// backends push the metrics.
+	DefaultPushInterval = 15 * time.Second
+)
 
 var MetricReader metric.Reader
 
-// InitMetricsExporter initializes the metrics exporter for the specified metrics backend and port
-func InitMetricsExporter(metricsBackend string, port int) error {
+// PushConfig configures the metrics backends that push the metrics to a
+// receiver instead of being scraped.
+type PushConfig struct {
+	// Endpoint is the host and port of the receiver: the OTLP gRPC receiver of
+	// an OpenTelemetry Collector for otlp, or the StatsD agent for statsd.
+	Endpoint string
+	// Insecure disables TLS to the OTLP receiver.
+	Insecure bool
+	// Interval is the interval at which the metrics are pushed.
+	Interval time.Duration
+}
+
+// InitMetricsExporter initializes the metrics exporter for the specified metrics backend and port.
+// The port is only served by the prometheus backend, the otlp and statsd backends push the metrics to the receiver of the push config.
+func InitMetricsExporter(metricsBackend string, port int, push PushConfig) error {
 	if port < 0 || port > 65535 {
 		return fmt.Errorf("invalid port %v", port)
 	}
 	mb := strings.ToLower(metricsBackend)
 	logrus.Info("intializing metrics backend: ", mb)
 	switch mb {
-	// Prometheus is the only exporter for now
 	case prometheusExporter:
 		var err error
 		MetricReader, err = prometheus.New()
@@ -47,6 +69,26 @@ func InitMetricsExporter(metricsBackend string, port int) error {
 		if err := initPrometheusExporter(port); err != nil {
 			return err
 		}
+	case otlpExporter, statsdExporter:
+		if push.Endpoint == "" {
+			return fmt.Errorf("metrics backend %v requires an endpoint", mb)
+		}
+		var exporter metric.Exporter
+		var err error
+		if mb == otlpExporter {
+			exporter, err = newOTLPExporter(context.Background(), push)
+		} else {
+			exporter, err = newStatsdExporter(push.Endpoint)
+		}
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		interval := push.Interval
+		if interval <= 0 {
+			interval = DefaultPushInterval
+		}
+		MetricReader = metric.NewPeriodicReader(exporter, metric.WithInterval(interval))
 	default:
 		return fmt.Errorf("unsupported metrics backend %v", metricsBackend)
 	}
@@ -26,6 +26,7 @@ func TestInitMetricsExporter_InvalidPortExporter(t *testing.T) {
 		name        string
 		port        int
 		exporter    string
+		push        PushConfig
 		expectedErr error
 	}{
 		{
@@ -46,10 +47,22 @@ func TestInitMetricsExporter_InvalidPortExporter(t *testing.T) {
 			exporter:    "invalid",
 			expectedErr: fmt.Errorf("unsupported metrics backend %v", "invalid"),
 		},
+		{
+			name:        "otlp without endpoint",
+			port:        8888,
+			exporter:    "otlp",
+			expectedErr: fmt.Errorf("metrics backend %v requires an endpoint", "otlp"),
+		},
+		{
+			name:        "statsd without endpoint",
+			port:        8888,
+			exporter:    "statsd",
+			expectedErr: fmt.Errorf("metrics backend %v requires an endpoint", "statsd"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := InitMetricsExporter(tt.exporter, tt.port); errors.Is(err, tt.expectedErr) {
+			if err := InitMetricsExporter(tt.exporter, tt.port, tt.push); errors.Is(err, tt.expectedErr) {
 				t.Errorf("InitMetricsExporter() error = %v, expectedErr %v", err, tt.expectedErr)
 			}
 		})
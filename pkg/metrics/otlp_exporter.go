@@ -0,0 +1,33 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// newOTLPExporter returns an exporter pushing the metrics to the OTLP gRPC
+// receiver of the push config, e.g. an OpenTelemetry Collector.
+func newOTLPExporter(ctx context.Context, push PushConfig) (metric.Exporter, error) {
+	options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(push.Endpoint)}
+	if push.Insecure {
+		options = append(options, otlpmetricgrpc.WithInsecure())
+	}
+	return otlpmetricgrpc.New(ctx, options...)
+}
@@ -18,12 +18,12 @@ package metrics
 import (
 	"context"
 
+	"github.com/deislabs/ratify/internal/logger"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
 	instrument "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/metric/aggregation"
 )
 
 var (
@@ -34,6 +34,21 @@ var (
 	systemErrorCount     instrument.Int64Counter
 	registryRequestCount instrument.Int64Counter
 	cacheBlobCount       instrument.Int64Counter
+	responseCacheCount   instrument.Int64Counter
+	rateLimitedCount     instrument.Int64Counter
+	pluginQueueDepth     instrument.Int64UpDownCounter
+	pluginQueueWait      instrument.Int64Histogram
+	blobTransferSize     instrument.Int64Counter
+	deduplicatedCount    instrument.Int64Counter
+	auditedFailureCount  instrument.Int64Counter
+	policyEvalDuration   instrument.Int64Histogram
+	namespaceVerifyCount instrument.Int64Counter
+	referrersSourceCount instrument.Int64Counter
+	inflightRequests     instrument.Int64UpDownCounter
+	configReloadCount    instrument.Int64Counter
+	revocationDuration   instrument.Int64Histogram
+	priorityQueueWait    instrument.Int64Histogram
+	priorityInflight     instrument.Int64UpDownCounter
 
 	// Azure Metrics
 	aadExchangeDuration    instrument.Int64Histogram
@@ -51,6 +66,21 @@ const (
 	metricNameSystemErrorCount     = "ratify_system_error_count"
 	metricNameRegistryRequestCount = "ratify_registry_request_count"
 	metricNameBlobCacheCount       = "ratify_blob_cache_count"
+	metricNameResponseCacheCount   = "ratify_response_cache_count"
+	metricNameRateLimitedCount     = "ratify_rate_limited_request_count"
+	metricNamePluginQueueDepth     = "ratify_plugin_queue_depth"
+	metricNamePluginQueueWait      = "ratify_plugin_queue_wait_duration"
+	metricNameBlobTransferSize     = "ratify_blob_transfer_bytes"
+	metricNameDeduplicatedCount    = "ratify_deduplicated_verification_count"
+	metricNameAuditedFailureCount  = "ratify_audited_verification_failure_count"
+	metricNamePolicyEvalDuration   = "ratify_policy_evaluation_duration"
+	metricNameNamespaceVerifyCount = "ratify_namespace_verification_count"
+	metricNameReferrersSourceCount = "ratify_referrers_source_count"
+	metricNameInflightRequests     = "ratify_inflight_request_count"
+	metricNameConfigReloadCount    = "ratify_config_reload_count"
+	metricNameRevocationDuration   = "ratify_revocation_check_duration"
+	metricNamePriorityQueueWait    = "ratify_priority_queue_wait_duration"
+	metricNamePriorityInflight     = "ratify_priority_inflight_verifications"
 
 	// Azure Metrics
 	metricNameAADExchangeDuration    = "ratify_aad_exchange_duration"
@@ -71,7 +101,7 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 30, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1100, 1200, 1400, 1600, 1800, 2000, 2300, 2600, 4000, 4400, 4900},
 				},
 			},
@@ -82,7 +112,7 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 30, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1100, 1200, 1400, 1600, 1800},
 				},
 			},
@@ -93,7 +123,7 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 600, 800, 1100, 1500, 2000},
 				},
 			},
@@ -104,7 +134,7 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1200},
 				},
 			},
@@ -115,7 +145,7 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1200},
 				},
 			},
@@ -126,11 +156,55 @@ func initStatsReporter() error {
 				Scope: instrumentation.Scope{Name: scope},
 			},
 			sdkmetric.Stream{
-				Aggregation: aggregation.ExplicitBucketHistogram{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
 					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1200},
 				},
 			},
 		),
+		sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  metricNamePluginQueueWait,
+				Scope: instrumentation.Scope{Name: scope},
+			},
+			sdkmetric.Stream{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 600, 800, 1100, 1500, 2000, 3000, 5000},
+				},
+			},
+		),
+		sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  metricNamePolicyEvalDuration,
+				Scope: instrumentation.Scope{Name: scope},
+			},
+			sdkmetric.Stream{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+					Boundaries: []float64{0, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 50000, 100000},
+				},
+			},
+		),
+		sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  metricNameRevocationDuration,
+				Scope: instrumentation.Scope{Name: scope},
+			},
+			sdkmetric.Stream{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+					Boundaries: []float64{0, 1, 5, 10, 50, 100, 200, 300, 500, 800, 1200, 2000, 3000, 5000},
+				},
+			},
+		),
+		sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  metricNamePriorityQueueWait,
+				Scope: instrumentation.Scope{Name: scope},
+			},
+			sdkmetric.Stream{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+					Boundaries: []float64{0, 10, 50, 100, 200, 300, 400, 600, 800, 1100, 1500, 2000, 3000, 5000},
+				},
+			},
+		),
 	}
 	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(MetricReader), sdkmetric.WithView(views...))
 	meter := provider.Meter(scope)
@@ -179,6 +253,81 @@ func initStatsReporter() error {
 		logrus.Error(err)
 		return err
 	}
+	responseCacheCount, err = meter.Int64Counter(metricNameResponseCacheCount, instrument.WithDescription("verification response cache hit/miss count"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	rateLimitedCount, err = meter.Int64Counter(metricNameRateLimitedCount, instrument.WithDescription("count of requests rejected by the rate limit"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	pluginQueueDepth, err = meter.Int64UpDownCounter(metricNamePluginQueueDepth, instrument.WithDescription("number of plugin invocations waiting for a process slot"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	pluginQueueWait, err = meter.Int64Histogram(metricNamePluginQueueWait, instrument.WithUnit("millisecond"), instrument.WithDescription("time plugin invocations waited for a process slot in ms"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	blobTransferSize, err = meter.Int64Counter(metricNameBlobTransferSize, instrument.WithUnit("byte"), instrument.WithDescription("size of blobs fetched from registries on the wire and after decoding in bytes"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	deduplicatedCount, err = meter.Int64Counter(metricNameDeduplicatedCount, instrument.WithDescription("count of request keys whose subject resolved to a subject already verified in the same request"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	auditedFailureCount, err = meter.Int64Counter(metricNameAuditedFailureCount, instrument.WithDescription("count of failed verifications allowed by the audit enforcement mode"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	policyEvalDuration, err = meter.Int64Histogram(metricNamePolicyEvalDuration, instrument.WithUnit("microsecond"), instrument.WithDescription("policy evaluation duration in microseconds"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	namespaceVerifyCount, err = meter.Int64Counter(metricNameNamespaceVerifyCount, instrument.WithDescription("count of verified request keys per namespace"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	referrersSourceCount, err = meter.Int64Counter(metricNameReferrersSourceCount, instrument.WithDescription("count of referrer listings merging the Referrers API and the tag schema by the source of the referrers"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	inflightRequests, err = meter.Int64UpDownCounter(metricNameInflightRequests, instrument.WithDescription("number of requests being served by the http server"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	configReloadCount, err = meter.Int64Counter(metricNameConfigReloadCount, instrument.WithDescription("count of reloads of the executor after the configuration file changed"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	revocationDuration, err = meter.Int64Histogram(metricNameRevocationDuration, instrument.WithUnit("millisecond"), instrument.WithDescription("certificate revocation check duration in ms"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	priorityQueueWait, err = meter.Int64Histogram(metricNamePriorityQueueWait, instrument.WithUnit("millisecond"), instrument.WithDescription("time verifications waited for a slot of the concurrency pool of their request class in ms"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	priorityInflight, err = meter.Int64UpDownCounter(metricNamePriorityInflight, instrument.WithDescription("number of verifications running per request class"))
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
 	return nil
 }
 
@@ -202,9 +351,11 @@ func ReportMutationRequest(ctx context.Context, duration int64) {
 // subjectReference: the subject reference of the verification
 // success: whether the verification succeeded
 // isError: whether the verification failed due to an error
+// namespace: the namespace of the request, empty for cluster-wide requests
 func ReportVerifierDuration(ctx context.Context, duration int64, veriferName string, subjectReference string, success bool, isError bool) {
 	if verifierDuration != nil {
 		verifierDuration.Record(ctx, duration, instrument.WithAttributes(
+			namespaceAttribute(ctx),
 			attribute.KeyValue{
 				Key:   "verifier",
 				Value: attribute.StringValue(veriferName),
@@ -279,3 +430,170 @@ func ReportBlobCacheCount(ctx context.Context, hit bool) {
 		cacheBlobCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "hit", Value: attribute.BoolValue(hit)}))
 	}
 }
+
+// ReportResponseCacheCount reports a verification response cache hit or miss
+// Attributes:
+// hit: whether the response to the request was found in the cache
+func ReportResponseCacheCount(ctx context.Context, hit bool) {
+	if responseCacheCount != nil {
+		responseCacheCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "hit", Value: attribute.BoolValue(hit)}))
+	}
+}
+
+// ReportRateLimitedRequest reports a request rejected by the rate limit
+// Attributes:
+// path: the path of the request
+func ReportRateLimitedRequest(ctx context.Context, path string) {
+	if rateLimitedCount != nil {
+		rateLimitedCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "path", Value: attribute.StringValue(path)}))
+	}
+}
+
+// ReportPluginQueueDepth reports a change in the number of plugin invocations
+// waiting for a process slot
+// Attributes:
+// plugin: the name of the plugin
+func ReportPluginQueueDepth(ctx context.Context, delta int64, plugin string) {
+	if pluginQueueDepth != nil {
+		pluginQueueDepth.Add(ctx, delta, instrument.WithAttributes(attribute.KeyValue{Key: "plugin", Value: attribute.StringValue(plugin)}))
+	}
+}
+
+// ReportPluginQueueWait reports the time a plugin invocation waited for a
+// process slot
+// Attributes:
+// plugin: the name of the plugin
+func ReportPluginQueueWait(ctx context.Context, duration int64, plugin string) {
+	if pluginQueueWait != nil {
+		pluginQueueWait.Record(ctx, duration, instrument.WithAttributes(attribute.KeyValue{Key: "plugin", Value: attribute.StringValue(plugin)}))
+	}
+}
+
+// ReportBlobTransferSize reports the size of a blob fetched from a registry on
+// the wire and of its decoded content
+// Attributes:
+// encoding: the content encoding of the response, identity if not encoded
+// size: wire or content
+func ReportBlobTransferSize(ctx context.Context, encoding string, wireSize int64, contentSize int64) {
+	if blobTransferSize != nil {
+		encodingAttribute := attribute.KeyValue{Key: "encoding", Value: attribute.StringValue(encoding)}
+		blobTransferSize.Add(ctx, wireSize, instrument.WithAttributes(encodingAttribute, attribute.KeyValue{Key: "size", Value: attribute.StringValue("wire")}))
+		blobTransferSize.Add(ctx, contentSize, instrument.WithAttributes(encodingAttribute, attribute.KeyValue{Key: "size", Value: attribute.StringValue("content")}))
+	}
+}
+
+// ReportDeduplicatedVerification reports a request key whose result is shared
+// with another key of the same request resolving to the same subject
+// Attributes:
+// namespace: the namespace of the request, empty for cluster-wide requests
+func ReportDeduplicatedVerification(ctx context.Context) {
+	if deduplicatedCount != nil {
+		deduplicatedCount.Add(ctx, 1, instrument.WithAttributes(namespaceAttribute(ctx)))
+	}
+}
+
+// ReportAuditedVerificationFailure reports a failed verification allowed by the
+// audit enforcement mode
+// Attributes:
+// policy_type: the type of the policy provider
+// namespace: the namespace of the request, empty for cluster-wide requests
+func ReportAuditedVerificationFailure(ctx context.Context, policyType string) {
+	if auditedFailureCount != nil {
+		auditedFailureCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "policy_type", Value: attribute.StringValue(policyType)}, namespaceAttribute(ctx)))
+	}
+}
+
+// ReportNamespaceVerification reports a verified request key
+// Attributes:
+// namespace: the namespace of the request, empty for cluster-wide requests
+// error: whether the verification failed due to an error
+func ReportNamespaceVerification(ctx context.Context, isError bool) {
+	if namespaceVerifyCount != nil {
+		namespaceVerifyCount.Add(ctx, 1, instrument.WithAttributes(namespaceAttribute(ctx), attribute.KeyValue{Key: "error", Value: attribute.BoolValue(isError)}))
+	}
+}
+
+// ReportReferrersSource reports a referrer listing merging the Referrers API
+// and the tag schema
+// Attributes:
+// source: api, tag_schema, both or none, the queries that returned referrers
+func ReportReferrersSource(ctx context.Context, source string) {
+	if referrersSourceCount != nil {
+		referrersSourceCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "source", Value: attribute.StringValue(source)}))
+	}
+}
+
+// namespaceAttribute returns the namespace of the request in the context, so
+// that metrics are attributed to tenants
+func namespaceAttribute(ctx context.Context) attribute.KeyValue {
+	return attribute.KeyValue{Key: "namespace", Value: attribute.StringValue(logger.GetNamespace(ctx))}
+}
+
+// ReportPolicyEvaluationDuration reports the duration of evaluating a policy
+// Attributes:
+// query_language: the query language of the policy, e.g. rego
+// result: allow, deny or error
+func ReportPolicyEvaluationDuration(ctx context.Context, duration int64, queryLanguage string, result string) {
+	if policyEvalDuration != nil {
+		policyEvalDuration.Record(ctx, duration, instrument.WithAttributes(
+			attribute.KeyValue{Key: "query_language", Value: attribute.StringValue(queryLanguage)},
+			attribute.KeyValue{Key: "result", Value: attribute.StringValue(result)},
+		))
+	}
+}
+
+// ReportInflightRequest reports a change in the number of requests being
+// served by the http server
+// Attributes:
+// path: the path template of the route of the request
+func ReportInflightRequest(ctx context.Context, delta int64, path string) {
+	if inflightRequests != nil {
+		inflightRequests.Add(ctx, delta, instrument.WithAttributes(attribute.KeyValue{Key: "path", Value: attribute.StringValue(path)}))
+	}
+}
+
+// ReportConfigReload reports a reload of the executor after the configuration
+// file changed
+// Attributes:
+// success: whether the reloaded executor replaced the active one
+func ReportConfigReload(ctx context.Context, success bool) {
+	if configReloadCount != nil {
+		configReloadCount.Add(ctx, 1, instrument.WithAttributes(attribute.KeyValue{Key: "success", Value: attribute.BoolValue(success)}))
+	}
+}
+
+// ReportRevocationCheckDuration reports the duration of checking the
+// revocation status of a certificate
+// Attributes:
+// protocol: ocsp, crl or none
+// result: the revocation result, e.g. OK, Revoked or Unknown
+// cached: whether the revocation response was served from the cache
+func ReportRevocationCheckDuration(ctx context.Context, duration int64, protocol string, result string, cached bool) {
+	if revocationDuration != nil {
+		revocationDuration.Record(ctx, duration, instrument.WithAttributes(
+			attribute.KeyValue{Key: "protocol", Value: attribute.StringValue(protocol)},
+			attribute.KeyValue{Key: "result", Value: attribute.StringValue(result)},
+			attribute.KeyValue{Key: "cached", Value: attribute.BoolValue(cached)},
+		))
+	}
+}
+
+// ReportPriorityQueueWait reports the time a verification waited for a slot
+// of the concurrency pool of its request class
+// Attributes:
+// class: the request class, admission or audit
+func ReportPriorityQueueWait(ctx context.Context, duration int64, class string) {
+	if priorityQueueWait != nil {
+		priorityQueueWait.Record(ctx, duration, instrument.WithAttributes(attribute.KeyValue{Key: "class", Value: attribute.StringValue(class)}))
+	}
+}
+
+// ReportPriorityInflight reports a change in the number of verifications
+// running for a request class
+// Attributes:
+// class: the request class, admission or audit
+func ReportPriorityInflight(ctx context.Context, delta int64, class string) {
+	if priorityInflight != nil {
+		priorityInflight.Add(ctx, delta, instrument.WithAttributes(attribute.KeyValue{Key: "class", Value: attribute.StringValue(class)}))
+	}
+}
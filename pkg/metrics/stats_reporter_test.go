@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/deislabs/ratify/internal/logger"
 	"go.opentelemetry.io/otel/attribute"
 	instrument "go.opentelemetry.io/otel/metric"
 )
@@ -99,8 +100,8 @@ func TestReportVerifierDuration(t *testing.T) {
 	if mockDuration.Value != 5 {
 		t.Fatalf("ReportVerifierDuration() mockDuration.Value = %v, expected %v", mockDuration.Value, 5)
 	}
-	if len(mockDuration.Attributes) != 4 {
-		t.Fatalf("ReportVerifierDuration() len(mockDuration.Attributes) = %v, expected %v", len(mockDuration.Attributes), 2)
+	if len(mockDuration.Attributes) != 5 {
+		t.Fatalf("ReportVerifierDuration() len(mockDuration.Attributes) = %v, expected %v", len(mockDuration.Attributes), 5)
 	}
 	if mockDuration.Attributes["verifier"] != "test_verifier" {
 		t.Fatalf("expected verifer attribute to be test_verifier but got %s", mockDuration.Attributes["verifier"])
@@ -229,3 +230,238 @@ func TestReportBlobCacheCount(t *testing.T) {
 		t.Fatalf("expected hit attribute to be true but got %s", mockCounter.Attributes["hit"])
 	}
 }
+
+type MockInt64UpDownCounter struct {
+	instrument.Int64UpDownCounter
+	Value      int64
+	Attributes map[string]string
+}
+
+func (m *MockInt64UpDownCounter) Add(_ context.Context, incr int64, options ...instrument.AddOption) {
+	m.Value += incr
+	opts := instrument.NewAddConfig(options).Attributes()
+	for _, attr := range opts.ToSlice() {
+		m.Attributes[string(attr.Key)] = attr.Value.AsString()
+	}
+}
+
+func TestReportPluginQueue(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64UpDownCounter{Attributes: make(map[string]string)}
+	pluginQueueDepth = mockCounter
+	ReportPluginQueueDepth(context.Background(), 1, "sbom")
+	ReportPluginQueueDepth(context.Background(), 1, "sbom")
+	ReportPluginQueueDepth(context.Background(), -1, "sbom")
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportPluginQueueDepth() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["plugin"] != "sbom" {
+		t.Fatalf("expected plugin attribute to be sbom but got %s", mockCounter.Attributes["plugin"])
+	}
+
+	mockDuration := &MockInt64Histogram{Attributes: make(map[string]string)}
+	pluginQueueWait = mockDuration
+	ReportPluginQueueWait(context.Background(), 5, "sbom")
+	if mockDuration.Value != 5 {
+		t.Fatalf("ReportPluginQueueWait() mockDuration.Value = %v, expected %v", mockDuration.Value, 5)
+	}
+	if mockDuration.Attributes["plugin"] != "sbom" {
+		t.Fatalf("expected plugin attribute to be sbom but got %s", mockDuration.Attributes["plugin"])
+	}
+}
+
+func TestReportBlobTransferSize(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	blobTransferSize = mockCounter
+	ReportBlobTransferSize(context.Background(), "zstd", 10, 100)
+	if mockCounter.Value != 100 {
+		t.Fatalf("ReportBlobTransferSize() mockCounter.Value = %v, expected %v", mockCounter.Value, 100)
+	}
+	if mockCounter.Attributes["encoding"] != "zstd" || mockCounter.Attributes["size"] != "content" {
+		t.Fatalf("expected encoding attribute to be zstd and size attribute to be content but got %v", mockCounter.Attributes)
+	}
+}
+
+func TestReportResponseCacheCount(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	responseCacheCount = mockCounter
+	ReportResponseCacheCount(context.Background(), false)
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportResponseCacheCount() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["hit"] != "false" {
+		t.Fatalf("expected hit attribute to be false but got %s", mockCounter.Attributes["hit"])
+	}
+}
+
+func TestReportDeduplicatedVerification(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	deduplicatedCount = mockCounter
+	ReportDeduplicatedVerification(context.Background())
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportDeduplicatedVerification() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+}
+
+func TestReportAuditedVerificationFailure(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	auditedFailureCount = mockCounter
+	ReportAuditedVerificationFailure(context.Background(), "configpolicy")
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportAuditedVerificationFailure() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["policy_type"] != "configpolicy" {
+		t.Fatalf("expected policy_type attribute to be configpolicy but got %v", mockCounter.Attributes)
+	}
+}
+
+func TestReportNamespaceVerification(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	namespaceVerifyCount = mockCounter
+	ReportNamespaceVerification(logger.WithNamespace(context.Background(), "team-a"), false)
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportNamespaceVerification() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["namespace"] != "team-a" || mockCounter.Attributes["error"] != "false" {
+		t.Fatalf("unexpected attributes %v", mockCounter.Attributes)
+	}
+}
+
+func TestReportPolicyEvaluationDuration(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockDuration := &MockInt64Histogram{Attributes: make(map[string]string)}
+	policyEvalDuration = mockDuration
+	ReportPolicyEvaluationDuration(context.Background(), 42, "rego", "allow")
+	if mockDuration.Value != 42 {
+		t.Fatalf("ReportPolicyEvaluationDuration() mockDuration.Value = %v, expected %v", mockDuration.Value, 42)
+	}
+	if mockDuration.Attributes["query_language"] != "rego" || mockDuration.Attributes["result"] != "allow" {
+		t.Fatalf("unexpected attributes %v", mockDuration.Attributes)
+	}
+}
+
+func TestReportReferrersSource(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	referrersSourceCount = mockCounter
+	ReportReferrersSource(context.Background(), "tag_schema")
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportReferrersSource() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["source"] != "tag_schema" {
+		t.Fatalf("expected source attribute to be tag_schema but got %v", mockCounter.Attributes)
+	}
+}
+
+func TestReportInflightRequest(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64UpDownCounter{Attributes: make(map[string]string)}
+	inflightRequests = mockCounter
+	ReportInflightRequest(context.Background(), 1, "/ratify/gatekeeper/v1/verify")
+	ReportInflightRequest(context.Background(), 1, "/ratify/gatekeeper/v1/verify")
+	ReportInflightRequest(context.Background(), -1, "/ratify/gatekeeper/v1/verify")
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportInflightRequest() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["path"] != "/ratify/gatekeeper/v1/verify" {
+		t.Fatalf("expected path attribute to be /ratify/gatekeeper/v1/verify but got %s", mockCounter.Attributes["path"])
+	}
+}
+
+func TestReportConfigReload(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64Counter{Attributes: make(map[string]string)}
+	configReloadCount = mockCounter
+	ReportConfigReload(context.Background(), false)
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportConfigReload() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["success"] != "false" {
+		t.Fatalf("expected success attribute to be false but got %s", mockCounter.Attributes["success"])
+	}
+}
+
+func TestReportRevocationCheckDuration(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockDuration := &MockInt64Histogram{Attributes: make(map[string]string)}
+	revocationDuration = mockDuration
+	ReportRevocationCheckDuration(context.Background(), 12, "ocsp", "OK", true)
+	if mockDuration.Value != 12 {
+		t.Fatalf("ReportRevocationCheckDuration() mockDuration.Value = %v, expected %v", mockDuration.Value, 12)
+	}
+	if mockDuration.Attributes["protocol"] != "ocsp" || mockDuration.Attributes["result"] != "OK" || mockDuration.Attributes["cached"] != "true" {
+		t.Fatalf("unexpected attributes %v", mockDuration.Attributes)
+	}
+}
+
+func TestReportPriorityQueueWait(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockDuration := &MockInt64Histogram{Attributes: make(map[string]string)}
+	priorityQueueWait = mockDuration
+	ReportPriorityQueueWait(context.Background(), 7, "audit")
+	if mockDuration.Value != 7 {
+		t.Fatalf("ReportPriorityQueueWait() mockDuration.Value = %v, expected %v", mockDuration.Value, 7)
+	}
+	if mockDuration.Attributes["class"] != "audit" {
+		t.Fatalf("expected class attribute to be audit but got %s", mockDuration.Attributes["class"])
+	}
+}
+
+func TestReportPriorityInflight(t *testing.T) {
+	if err := initStatsReporter(); err != nil {
+		t.Fatalf("initStatsReporter() error = %v", err)
+	}
+
+	mockCounter := &MockInt64UpDownCounter{Attributes: make(map[string]string)}
+	priorityInflight = mockCounter
+	ReportPriorityInflight(context.Background(), 1, "admission")
+	ReportPriorityInflight(context.Background(), 1, "admission")
+	ReportPriorityInflight(context.Background(), -1, "admission")
+	if mockCounter.Value != 1 {
+		t.Fatalf("ReportPriorityInflight() mockCounter.Value = %v, expected %v", mockCounter.Value, 1)
+	}
+	if mockCounter.Attributes["class"] != "admission" {
+		t.Fatalf("expected class attribute to be admission but got %s", mockCounter.Attributes["class"])
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// maxStatsdPacketSize is the maximum size of a UDP packet sent to the StatsD
+// agent, it fits into the MTU of most networks.
+const maxStatsdPacketSize = 1432
+
+// statsdValueReplacer replaces the characters delimiting the fields of the
+// StatsD protocol in the names and tags of the metrics.
+var statsdValueReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", ",", "_", "#", "_", "\n", "_")
+
+// statsdPushExporter pushes the metrics to a StatsD agent in the DogStatsD format,
+// so that attributes are sent as tags. Counters are sent as the increment
+// since the last push, up-down counters as gauges, and histograms as the
+// count and sum of the recorded values with their min and max as gauges.
+type statsdPushExporter struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newStatsdExporter(endpoint string) (metric.Exporter, error) {
+	conn, err := net.Dial("udp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd agent %s: %w", endpoint, err)
+	}
+	return &statsdPushExporter{conn: conn}, nil
+}
+
+func (e *statsdPushExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case metric.InstrumentKindUpDownCounter, metric.InstrumentKindObservableUpDownCounter, metric.InstrumentKindObservableGauge:
+		return metricdata.CumulativeTemporality
+	default:
+		return metricdata.DeltaTemporality
+	}
+}
+
+func (e *statsdPushExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	return metric.DefaultAggregationSelector(kind)
+}
+
+func (e *statsdPushExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	var lines []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			lines = append(lines, statsdLines(m)...)
+		}
+	}
+	return e.send(lines)
+}
+
+func (e *statsdPushExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *statsdPushExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn.Close()
+}
+
+// send writes the lines to the agent, packing as many lines as fit into each
+// packet.
+func (e *statsdPushExporter) send(lines []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := e.conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxStatsdPacketSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return flush()
+}
+
+// statsdLines formats the data points of the metric in the DogStatsD format.
+func statsdLines(m metricdata.Metrics) []string {
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		return sumLines(m.Name, data)
+	case metricdata.Sum[float64]:
+		return sumLines(m.Name, data)
+	case metricdata.Gauge[int64]:
+		return gaugeLines(m.Name, data.DataPoints)
+	case metricdata.Gauge[float64]:
+		return gaugeLines(m.Name, data.DataPoints)
+	case metricdata.Histogram[int64]:
+		return histogramLines(m.Name, data)
+	case metricdata.Histogram[float64]:
+		return histogramLines(m.Name, data)
+	default:
+		return nil
+	}
+}
+
+func sumLines[N int64 | float64](name string, sum metricdata.Sum[N]) []string {
+	if !sum.IsMonotonic {
+		return gaugeLines(name, sum.DataPoints)
+	}
+	var lines []string
+	for _, dp := range sum.DataPoints {
+		// skip series that did not change since the last push
+		if dp.Value == 0 {
+			continue
+		}
+		lines = append(lines, statsdLine(name, formatStatsdValue(dp.Value), "c", dp.Attributes))
+	}
+	return lines
+}
+
+func gaugeLines[N int64 | float64](name string, dataPoints []metricdata.DataPoint[N]) []string {
+	lines := make([]string, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		lines = append(lines, statsdLine(name, formatStatsdValue(dp.Value), "g", dp.Attributes))
+	}
+	return lines
+}
+
+func histogramLines[N int64 | float64](name string, histogram metricdata.Histogram[N]) []string {
+	var lines []string
+	for _, dp := range histogram.DataPoints {
+		if dp.Count == 0 {
+			continue
+		}
+		lines = append(lines,
+			statsdLine(name+".count", strconv.FormatUint(dp.Count, 10), "c", dp.Attributes),
+			statsdLine(name+".sum", formatStatsdValue(dp.Sum), "c", dp.Attributes))
+		if v, ok := dp.Min.Value(); ok {
+			lines = append(lines, statsdLine(name+".min", formatStatsdValue(v), "g", dp.Attributes))
+		}
+		if v, ok := dp.Max.Value(); ok {
+			lines = append(lines, statsdLine(name+".max", formatStatsdValue(v), "g", dp.Attributes))
+		}
+	}
+	return lines
+}
+
+// statsdLine formats a single value, e.g.
+// ratify_verifier_duration.count:2|c|#verifier:notation,success:true
+func statsdLine(name, value, metricType string, attributes attribute.Set) string {
+	line := fmt.Sprintf("%s:%s|%s", statsdValueReplacer.Replace(name), value, metricType)
+	if attributes.Len() == 0 {
+		return line
+	}
+	tags := make([]string, 0, attributes.Len())
+	iter := attributes.Iter()
+	for iter.Next() {
+		attr := iter.Attribute()
+		tags = append(tags, statsdValueReplacer.Replace(string(attr.Key))+":"+statsdValueReplacer.Replace(attr.Value.Emit()))
+	}
+	return line + "|#" + strings.Join(tags, ",")
+}
+
+func formatStatsdValue[N int64 | float64](value N) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 64)
+}
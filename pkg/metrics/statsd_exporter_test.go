@@ -0,0 +1,139 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestStatsdLines(t *testing.T) {
+	attrs := attribute.NewSet(attribute.String("verifier", "notation"), attribute.Bool("success", true))
+	tests := []struct {
+		name     string
+		metric   metricdata.Metrics
+		expected []string
+	}{
+		{
+			name: "counter",
+			metric: metricdata.Metrics{
+				Name: metricNameRegistryRequestCount,
+				Data: metricdata.Sum[int64]{
+					IsMonotonic: true,
+					DataPoints: []metricdata.DataPoint[int64]{
+						{Attributes: attribute.NewSet(attribute.String("registry_host", "myregistry.azurecr.io")), Value: 3},
+						{Value: 0},
+					},
+				},
+			},
+			expected: []string{"ratify_registry_request_count:3|c|#registry_host:myregistry.azurecr.io"},
+		},
+		{
+			name: "up down counter",
+			metric: metricdata.Metrics{
+				Name: metricNameInflightRequests,
+				Data: metricdata.Sum[int64]{
+					DataPoints: []metricdata.DataPoint[int64]{{Attributes: attribute.NewSet(attribute.String("path", "/ratify/gatekeeper/v1/verify")), Value: 2}},
+				},
+			},
+			expected: []string{"ratify_inflight_request_count:2|g|#path:/ratify/gatekeeper/v1/verify"},
+		},
+		{
+			name: "histogram",
+			metric: metricdata.Metrics{
+				Name: metricNameVerifierDuration,
+				Data: metricdata.Histogram[int64]{
+					DataPoints: []metricdata.HistogramDataPoint[int64]{
+						{Attributes: attrs, Count: 2, Sum: 30, Min: metricdata.NewExtrema[int64](10), Max: metricdata.NewExtrema[int64](20)},
+						{Attributes: attrs},
+					},
+				},
+			},
+			expected: []string{
+				"ratify_verifier_duration.count:2|c|#success:true,verifier:notation",
+				"ratify_verifier_duration.sum:30|c|#success:true,verifier:notation",
+				"ratify_verifier_duration.min:10|g|#success:true,verifier:notation",
+				"ratify_verifier_duration.max:20|g|#success:true,verifier:notation",
+			},
+		},
+		{
+			name: "tag values are escaped",
+			metric: metricdata.Metrics{
+				Name: metricNameSystemErrorCount,
+				Data: metricdata.Sum[int64]{
+					IsMonotonic: true,
+					DataPoints:  []metricdata.DataPoint[int64]{{Attributes: attribute.NewSet(attribute.String("error", "dial tcp: i/o timeout|retry")), Value: 1}},
+				},
+			},
+			expected: []string{"ratify_system_error_count:1|c|#error:dial tcp_ i/o timeout_retry"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := statsdLines(tt.metric)
+			if strings.Join(lines, "\n") != strings.Join(tt.expected, "\n") {
+				t.Fatalf("expected lines %v, got %v", tt.expected, lines)
+			}
+		})
+	}
+}
+
+func TestStatsdExporter_Export(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	exporter, err := newStatsdExporter(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("newStatsdExporter() error = %v", err)
+	}
+	defer exporter.Shutdown(context.Background()) //nolint:errcheck
+
+	rm := &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Metrics: []metricdata.Metrics{{
+				Name: metricNameBlobCacheCount,
+				Data: metricdata.Sum[int64]{
+					IsMonotonic: true,
+					DataPoints:  []metricdata.DataPoint[int64]{{Attributes: attribute.NewSet(attribute.Bool("hit", true)), Value: 5}},
+				},
+			}},
+		}},
+	}
+	if err := exporter.Export(context.Background(), rm); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	buf := make([]byte, maxStatsdPacketSize)
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("failed to set deadline: %v", err)
+	}
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+	if expected := "ratify_blob_cache_count:5|c|#hit:true"; string(buf[:n]) != expected {
+		t.Fatalf("expected packet %s, got %s", expected, buf[:n])
+	}
+}
@@ -35,6 +35,8 @@ type ReferenceManifest struct {
 	Blobs        []oci.Descriptor  `json:"blobs"`
 	Subject      *oci.Descriptor   `json:"subject,omitempty"`
 	Annotations  map[string]string `json:"annotations,omitempty"`
+	// Config is set for image manifests carrying an image config
+	Config *oci.Descriptor `json:"config,omitempty"`
 }
 
 type SubjectDescriptor struct {
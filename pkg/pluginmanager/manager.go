@@ -0,0 +1,224 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pluginmanager installs verifier and store plugins published as OCI
+// artifacts to the plugin directory.
+package pluginmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deislabs/ratify/internal/version"
+	"github.com/deislabs/ratify/pkg/common/oras/authprovider"
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+	commonutils "github.com/deislabs/ratify/pkg/common/utils"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// ArtifactType is the artifact type of plugins published as OCI artifacts.
+	ArtifactType = "application/vnd.ratify.plugin.v1"
+
+	// artifact types of artifacts pushed by ORAS without an artifact type
+	mediaTypeUnknownConfig   = "application/vnd.unknown.config.v1+json"
+	mediaTypeUnknownArtifact = "application/vnd.unknown.artifact.v1"
+)
+
+// newRepository creates the repository of the plugin artifact, it is replaced in tests.
+var newRepository = func(source pluginCommon.PluginSource) (*remote.Repository, error) {
+	repository, err := remote.NewRepository(source.Artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	repository.Client = &auth.Client{
+		Client: &http.Client{Timeout: 10 * time.Second, Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		Header: http.Header{
+			"User-Agent": {version.UserAgent},
+		},
+		Cache: auth.NewCache(),
+		Credential: func(ctx context.Context, registry string) (auth.Credential, error) {
+			authProvider, err := authprovider.CreateAuthProviderFromConfig(source.AuthProvider)
+			if err != nil {
+				return auth.EmptyCredential, err
+			}
+
+			authConfig, err := authProvider.Provide(ctx, registry)
+			if err != nil {
+				return auth.EmptyCredential, err
+			}
+
+			if authConfig.Username != "" || authConfig.Password != "" || authConfig.IdentityToken != "" {
+				return auth.Credential{
+					Username:     authConfig.Username,
+					Password:     authConfig.Password,
+					RefreshToken: authConfig.IdentityToken,
+				}, nil
+			}
+			return auth.EmptyCredential, nil
+		},
+	}
+	return repository, nil
+}
+
+// Install downloads the plugin from the source to the target path. The plugin
+// artifact must have the plugin artifact type and is signature verified if the
+// source configures verification. The plugin is replaced atomically so that
+// running plugins are not affected, and is not downloaded again if the
+// installed plugin is up to date. It returns whether the plugin was downloaded.
+func Install(ctx context.Context, source pluginCommon.PluginSource, targetPath string) (bool, error) {
+	repository, err := newRepository(source)
+	if err != nil {
+		return false, err
+	}
+
+	manifestDesc, err := repository.Resolve(ctx, source.Artifact)
+	if err != nil {
+		return false, err
+	}
+	logrus.Debugf("Resolved plugin manifest: %v", manifestDesc)
+
+	manifest, err := fetchManifest(ctx, repository, manifestDesc)
+	if err != nil {
+		return false, err
+	}
+	switch manifest.ArtifactType {
+	case ArtifactType:
+	case "", mediaTypeUnknownConfig, mediaTypeUnknownArtifact:
+		logrus.Warnf("plugin artifact %s has no artifact type, plugins should be published with artifact type %s", source.Artifact, ArtifactType)
+	default:
+		return false, fmt.Errorf("artifact %s of type %s is not a plugin, expected artifact type %s", source.Artifact, manifest.ArtifactType, ArtifactType)
+	}
+	if len(manifest.Blobs) == 0 {
+		return false, fmt.Errorf("plugin artifact %s has no blobs", source.Artifact)
+	}
+
+	if source.Verification != nil {
+		reference := fmt.Sprintf("%s/%s@%s", repository.Reference.Registry, repository.Reference.Repository, manifestDesc.Digest)
+		if err := verifySignature(ctx, repository, reference, *source.Verification); err != nil {
+			return false, fmt.Errorf("failed to verify signature of plugin artifact %s: %w", source.Artifact, err)
+		}
+		logrus.Infof("verified signature of plugin artifact %s", reference)
+	} else {
+		logrus.Warnf("signature verification is not configured for plugin artifact %s", source.Artifact)
+	}
+
+	// the first blob is the plugin
+	blobDesc := manifest.Blobs[0]
+	if isInstalled(targetPath, blobDesc.Digest) {
+		logrus.Debugf("plugin %s is up to date", targetPath)
+		return false, nil
+	}
+
+	logrus.Debugf("Downloading blob %s", blobDesc.Digest)
+	blobReader, err := repository.Blobs().Fetch(ctx, blobDesc)
+	if err != nil {
+		return false, err
+	}
+	defer blobReader.Close()
+
+	if err := writePlugin(targetPath, content.NewVerifyReader(blobReader, blobDesc)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func fetchManifest(ctx context.Context, repository *remote.Repository, manifestDesc oci.Descriptor) (ocispecs.ReferenceManifest, error) {
+	manifestBytes, err := content.FetchAll(ctx, repository, manifestDesc)
+	if err != nil {
+		return ocispecs.ReferenceManifest{}, err
+	}
+
+	referenceManifest := ocispecs.ReferenceManifest{}
+	switch manifestDesc.MediaType {
+	case oci.MediaTypeImageManifest:
+		var imageManifest oci.Manifest
+		if err := json.Unmarshal(manifestBytes, &imageManifest); err != nil {
+			return referenceManifest, err
+		}
+		referenceManifest = commonutils.OciManifestToReferenceManifest(imageManifest)
+	case ocispecs.MediaTypeArtifactManifest:
+		if err := json.Unmarshal(manifestBytes, &referenceManifest); err != nil {
+			return referenceManifest, err
+		}
+	default:
+		return referenceManifest, fmt.Errorf("unsupported manifest media type: %s", manifestDesc.MediaType)
+	}
+	return referenceManifest, nil
+}
+
+// isInstalled returns true if the plugin at the path has the digest.
+func isInstalled(path string, d digest.Digest) bool {
+	if d.Algorithm() != digest.SHA256 {
+		return false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return false
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == d.Encoded()
+}
+
+// writePlugin writes the verified plugin content to a temporary file in the
+// plugin directory and renames it to the target path.
+func writePlugin(targetPath string, reader *content.VerifyReader) error {
+	file, err := os.CreateTemp(filepath.Dir(targetPath), "."+filepath.Base(targetPath)+"-*")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath)
+
+	logrus.Debugf("writing plugin bytes to %s", tempPath)
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	if err := reader.Verify(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// mark the plugin as executable
+	logrus.Debugf("marking %s as executable", tempPath)
+	if err := os.Chmod(tempPath, 0700); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, targetPath)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pluginmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+	"github.com/deislabs/ratify/pkg/testregistry"
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/signer"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const pluginContent = "#!/bin/sh\necho plugin\n"
+
+// usePlainHTTP makes the manager reach the test registry over plain HTTP
+func usePlainHTTP(t *testing.T) {
+	original := newRepository
+	newRepository = func(source pluginCommon.PluginSource) (*remote.Repository, error) {
+		repository, err := original(source)
+		if err != nil {
+			return nil, err
+		}
+		repository.PlainHTTP = true
+		return repository, nil
+	}
+	t.Cleanup(func() {
+		newRepository = original
+	})
+}
+
+// pushPlugin pushes a plugin whose content ends with the tag, so that plugins of different tags have different digests
+func pushPlugin(t *testing.T, r *testregistry.Registry, tag string, artifactType string) {
+	if _, err := r.PushArtifact(context.Background(), "plugins", tag, artifactType, testregistry.Blob{
+		MediaType: "application/octet-stream",
+		Content:   []byte(pluginContent + "# " + tag + "\n"),
+	}); err != nil {
+		t.Fatalf("failed to push plugin: %v", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	usePlainHTTP(t)
+	r := testregistry.New()
+	defer r.Close()
+	pushPlugin(t, r, "v1", ArtifactType)
+	pushPlugin(t, r, "untyped", "application/vnd.unknown.artifact.v1")
+	pushPlugin(t, r, "sbom", "application/spdx+json")
+
+	targetPath := filepath.Join(t.TempDir(), "sample")
+	source := pluginCommon.PluginSource{Artifact: r.Reference("plugins", "v1")}
+	downloaded, err := Install(context.Background(), source, targetPath)
+	if err != nil || !downloaded {
+		t.Fatalf("expected plugin to be downloaded, got %v, %v", downloaded, err)
+	}
+	content, err := os.ReadFile(targetPath)
+	if err != nil || !strings.HasPrefix(string(content), pluginContent) {
+		t.Fatalf("unexpected plugin content %q, %v", content, err)
+	}
+	if info, _ := os.Stat(targetPath); info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("expected plugin to be executable, got mode %v", info.Mode())
+	}
+
+	downloaded, err = Install(context.Background(), source, targetPath)
+	if err != nil || downloaded {
+		t.Fatalf("expected installed plugin to be up to date, got %v, %v", downloaded, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(targetPath)); len(entries) != 1 {
+		t.Fatalf("expected only the plugin in the plugin directory, got %d entries", len(entries))
+	}
+
+	if _, err := Install(context.Background(), pluginCommon.PluginSource{Artifact: r.Reference("plugins", "untyped")}, filepath.Join(t.TempDir(), "sample")); err != nil {
+		t.Fatalf("expected plugin without artifact type to be installed, got %v", err)
+	}
+	if _, err := Install(context.Background(), pluginCommon.PluginSource{Artifact: r.Reference("plugins", "sbom")}, filepath.Join(t.TempDir(), "sample")); err == nil || !strings.Contains(err.Error(), "is not a plugin") {
+		t.Fatalf("expected artifact of other type to be rejected, got %v", err)
+	}
+}
+
+func TestInstall_Verification(t *testing.T) {
+	usePlainHTTP(t)
+	r := testregistry.New()
+	defer r.Close()
+	pushPlugin(t, r, "signed", ArtifactType)
+	pushPlugin(t, r, "unsigned", ArtifactType)
+
+	dir := t.TempDir()
+	trustedCert := writeSigningCertificate(t, r, "signed", filepath.Join(dir, "trusted.crt"))
+	otherCert := writeSigningCertificate(t, nil, "", filepath.Join(dir, "other.crt"))
+
+	testCases := []struct {
+		name         string
+		tag          string
+		verification pluginCommon.PluginVerification
+		expectErr    bool
+	}{
+		{name: "trusted signature", tag: "signed", verification: pluginCommon.PluginVerification{Certificates: []string{trustedCert}}},
+		{name: "trusted identity", tag: "signed", verification: pluginCommon.PluginVerification{Certificates: []string{trustedCert}, TrustedIdentities: []string{"x509.subject: C=US, ST=WA, O=ratify, CN=ratify plugins"}}},
+		{name: "untrusted identity", tag: "signed", verification: pluginCommon.PluginVerification{Certificates: []string{trustedCert}, TrustedIdentities: []string{"x509.subject: C=US, ST=WA, O=other"}}, expectErr: true},
+		{name: "untrusted certificate", tag: "signed", verification: pluginCommon.PluginVerification{Certificates: []string{otherCert}}, expectErr: true},
+		{name: "unsigned", tag: "unsigned", verification: pluginCommon.PluginVerification{Certificates: []string{trustedCert}}, expectErr: true},
+		{name: "no certificates", tag: "signed", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verification := tc.verification
+			targetPath := filepath.Join(t.TempDir(), "sample")
+			_, err := Install(context.Background(), pluginCommon.PluginSource{Artifact: r.Reference("plugins", tc.tag), Verification: &verification}, targetPath)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if _, statErr := os.Stat(targetPath); tc.expectErr != os.IsNotExist(statErr) {
+				t.Fatalf("expected plugin to be installed only if verified, got %v", statErr)
+			}
+		})
+	}
+}
+
+// writeSigningCertificate creates a self-signed code signing certificate,
+// signs the plugin with tag in the registry if given, and writes the
+// certificate to the path
+func writeSigningCertificate(t *testing.T, r *testregistry.Registry, tag string, path string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"ratify"}, CommonName: "ratify plugins"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	if r != nil {
+		repository, err := remote.NewRepository(r.Host + "/plugins")
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		repository.PlainHTTP = true
+		s, err := signer.New(key, []*x509.Certificate{cert})
+		if err != nil {
+			t.Fatalf("failed to create signer: %v", err)
+		}
+		if _, err := notation.Sign(context.Background(), s, registry.NewRepository(repository), notation.SignOptions{
+			SignerSignOptions: notation.SignerSignOptions{SignatureMediaType: jws.MediaTypeEnvelope},
+			ArtifactReference: r.Reference("plugins", tag),
+		}); err != nil {
+			t.Fatalf("failed to sign plugin: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		t.Fatalf("failed to encode certificate: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	return path
+}
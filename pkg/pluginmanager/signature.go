@@ -0,0 +1,86 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pluginmanager
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+	_ "github.com/notaryproject/notation-core-go/signature/cose" // register COSE signature
+	_ "github.com/notaryproject/notation-core-go/signature/jws"  // register JWS signature
+	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	notationVerifier "github.com/notaryproject/notation-go/verifier"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const (
+	trustStoreName       = "plugins"
+	maxSignatureAttempts = 50
+)
+
+// certificateTrustStore is a trust store holding the certificates trusted to sign plugins
+type certificateTrustStore []*x509.Certificate
+
+func (s certificateTrustStore) GetCertificates(_ context.Context, _ truststore.Type, _ string) ([]*x509.Certificate, error) {
+	return s, nil
+}
+
+// verifySignature verifies that the artifact has a notation signature by a trusted identity.
+func verifySignature(ctx context.Context, repository *remote.Repository, reference string, verification pluginCommon.PluginVerification) error {
+	if len(verification.Certificates) == 0 {
+		return errors.New("no certificates are configured to verify plugin signatures")
+	}
+	var certificates []*x509.Certificate
+	for _, path := range verification.Certificates {
+		certs, err := corex509.ReadCertificateFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read certificate %s: %w", path, err)
+		}
+		certificates = append(certificates, certs...)
+	}
+
+	trustedIdentities := verification.TrustedIdentities
+	if len(trustedIdentities) == 0 {
+		trustedIdentities = []string{"*"}
+	}
+	policy := &trustpolicy.Document{
+		Version: "1.0",
+		TrustPolicies: []trustpolicy.TrustPolicy{{
+			Name:                  "ratify-plugins",
+			RegistryScopes:        []string{"*"},
+			SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: trustpolicy.LevelStrict.Name},
+			TrustStores:           []string{fmt.Sprintf("%s:%s", truststore.TypeCA, trustStoreName)},
+			TrustedIdentities:     trustedIdentities,
+		}},
+	}
+	verifier, err := notationVerifier.New(policy, certificateTrustStore(certificates), nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = notation.Verify(ctx, verifier, registry.NewRepository(repository), notation.VerifyOptions{
+		ArtifactReference:    reference,
+		MaxSignatureAttempts: maxSignatureAttempts,
+	})
+	return err
+}
@@ -21,12 +21,15 @@ import (
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/executor/types"
 	"github.com/deislabs/ratify/pkg/ocispecs"
+	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
 )
 
 // PolicyProvider is an interface with methods that represents policy decisions.
 type PolicyProvider interface {
 	// VerifyNeeded determines if the given reference needs verification
 	VerifyNeeded(ctx context.Context, subjectReference common.Reference, referenceDesc ocispecs.ReferenceDescriptor) bool
+	// NestedVerifyNeeded determines if the artifacts attached to the given reference need verification
+	NestedVerifyNeeded(ctx context.Context, referenceDesc ocispecs.ReferenceDescriptor) bool
 	// ContinueVerifyOnFailure determines if the given error can be ignored and verification can be continued.
 	ContinueVerifyOnFailure(ctx context.Context, subjectReference common.Reference, referenceDesc ocispecs.ReferenceDescriptor, partialVerifyResult types.VerifyResult) bool
 	// ErrorToVerifyResult converts an error to a properly formatted verify result
@@ -37,3 +40,28 @@ type PolicyProvider interface {
 	// GetPolicyType returns the type of the policy.
 	GetPolicyType(ctx context.Context) string
 }
+
+// EnforcementModeProvider is implemented by policy providers supporting the
+// audit enforcement mode.
+type EnforcementModeProvider interface {
+	// EnforcementMode returns whether failed verifications deny the subject.
+	EnforcementMode(ctx context.Context) pt.EnforcementMode
+}
+
+// GetEnforcementMode returns the enforcement mode of the policy provider,
+// enforce if it does not declare one.
+func GetEnforcementMode(ctx context.Context, provider PolicyProvider) pt.EnforcementMode {
+	if modeProvider, ok := provider.(EnforcementModeProvider); ok {
+		if mode := modeProvider.EnforcementMode(ctx); mode != "" {
+			return mode
+		}
+	}
+	return pt.EnforcementModeEnforce
+}
+
+// ArtifactTypesProvider is implemented by policy providers referencing
+// artifact types in their configuration.
+type ArtifactTypesProvider interface {
+	// ArtifactTypes returns the artifact types the policy references.
+	ArtifactTypes() []string
+}
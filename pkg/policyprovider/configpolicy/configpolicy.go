@@ -19,8 +19,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"sort"
+	"strings"
 
 	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/executor/types"
 	"github.com/deislabs/ratify/pkg/ocispecs"
@@ -28,17 +32,34 @@ import (
 	"github.com/deislabs/ratify/pkg/policyprovider/config"
 	pf "github.com/deislabs/ratify/pkg/policyprovider/factory"
 	vt "github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/utils"
 	"github.com/deislabs/ratify/pkg/verifier"
 )
 
 // PolicyEnforcer describes different polices that are enforced during verification
 type PolicyEnforcer struct {
-	ArtifactTypePolicies map[string]vt.ArtifactTypeVerifyPolicy
+	ArtifactTypePolicies  map[string]vt.ArtifactTypeVerifyPolicy
+	SignerPolicies        map[string]vt.SignerPolicy
+	ThresholdPolicies     map[string]vt.ThresholdPolicy
+	RequiredArtifactTypes []vt.RequiredArtifactTypesPolicy
+	NestedPolicies        map[string]vt.NestedVerificationPolicy
+	OperationPolicies     map[string]vt.OperationPolicy
+	RepositoryPolicies    []vt.RepositoryPolicy
+	InconclusivePolicy    vt.InconclusivePolicy
+	Mode                  vt.EnforcementMode
 }
 
 type configPolicyEnforcerConf struct {
 	Name                         string                                 `json:"name"`
 	ArtifactVerificationPolicies map[string]vt.ArtifactTypeVerifyPolicy `json:"artifactVerificationPolicies,omitempty"`
+	SignerPolicies               map[string]vt.SignerPolicy             `json:"signerPolicies,omitempty"`
+	ThresholdPolicies            map[string]vt.ThresholdPolicy          `json:"thresholdPolicies,omitempty"`
+	RequiredArtifactTypes        []vt.RequiredArtifactTypesPolicy       `json:"requiredArtifactTypes,omitempty"`
+	NestedVerificationPolicies   map[string]vt.NestedVerificationPolicy `json:"nestedVerificationPolicies,omitempty"`
+	OperationPolicies            map[string]vt.OperationPolicy          `json:"operationPolicies,omitempty"`
+	RepositoryPolicies           []vt.RepositoryPolicy                  `json:"repositoryPolicies,omitempty"`
+	InconclusivePolicy           vt.InconclusivePolicy                  `json:"inconclusivePolicy,omitempty"`
+	EnforcementMode              vt.EnforcementMode                     `json:"enforcementMode,omitempty"`
 }
 
 const (
@@ -47,6 +68,10 @@ const (
 
 type configPolicyFactory struct{}
 
+var logOpt = logger.Option{
+	ComponentType: logger.PolicyProvider,
+}
+
 // init calls Register for our config policy provider
 func init() {
 	pf.Register(vt.ConfigPolicy, &configPolicyFactory{})
@@ -74,6 +99,79 @@ func (f *configPolicyFactory) Create(policyConfig config.PolicyPluginConfig) (po
 	if policyEnforcer.ArtifactTypePolicies[defaultPolicyName] == "" {
 		policyEnforcer.ArtifactTypePolicies[defaultPolicyName] = vt.AllVerifySuccess
 	}
+
+	for artifactType, signerPolicy := range conf.SignerPolicies {
+		if signerPolicy.MinimumSigners < 1 {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("minimumSigners of signer policy for artifact type %s must be at least 1", artifactType), re.HideStackTrace)
+		}
+		switch signerPolicy.IdentityAttribute {
+		case "", verifier.SignerAttributeID, verifier.SignerAttributeIssuer, verifier.SignerAttributeKey:
+		default:
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("identityAttribute of signer policy for artifact type %s must be one of %s, %s or %s", artifactType, verifier.SignerAttributeID, verifier.SignerAttributeIssuer, verifier.SignerAttributeKey), re.HideStackTrace)
+		}
+	}
+	policyEnforcer.SignerPolicies = conf.SignerPolicies
+
+	for artifactType, thresholdPolicy := range conf.ThresholdPolicies {
+		if thresholdPolicy.MinimumVerifiers < 1 {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("minimumVerifiers of threshold policy for artifact type %s must be at least 1", artifactType), re.HideStackTrace)
+		}
+	}
+	policyEnforcer.ThresholdPolicies = conf.ThresholdPolicies
+
+	for i, requiredPolicy := range conf.RequiredArtifactTypes {
+		if len(requiredPolicy.ArtifactTypes) == 0 {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("required artifact types policy %d must require at least one artifact type", i), re.HideStackTrace)
+		}
+		for _, pattern := range requiredPolicy.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, err, fmt.Sprintf("invalid repository pattern %s of required artifact types policy %d", pattern, i), re.HideStackTrace)
+			}
+		}
+	}
+	policyEnforcer.RequiredArtifactTypes = conf.RequiredArtifactTypes
+
+	for artifactType, nestedPolicy := range conf.NestedVerificationPolicies {
+		if len(nestedPolicy.ArtifactTypes) == 0 {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("nested verification policy for artifact type %s must require at least one artifact type", artifactType), re.HideStackTrace)
+		}
+	}
+	policyEnforcer.NestedPolicies = conf.NestedVerificationPolicies
+
+	policyEnforcer.OperationPolicies = map[string]vt.OperationPolicy{}
+	for operation, operationPolicy := range conf.OperationPolicies {
+		policyEnforcer.OperationPolicies[strings.ToUpper(operation)] = operationPolicy
+	}
+
+	for i, repositoryPolicy := range conf.RepositoryPolicies {
+		if len(repositoryPolicy.Repositories) == 0 {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("repository policy %d must match at least one repository", i), re.HideStackTrace)
+		}
+		for _, pattern := range repositoryPolicy.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, err, fmt.Sprintf("invalid repository pattern %s of repository policy %d", pattern, i), re.HideStackTrace)
+			}
+		}
+	}
+	policyEnforcer.RepositoryPolicies = conf.RepositoryPolicies
+
+	switch conf.InconclusivePolicy {
+	case "":
+		policyEnforcer.InconclusivePolicy = vt.InconclusiveFail
+	case vt.InconclusiveFail, vt.InconclusiveWarn, vt.InconclusiveIgnore:
+		policyEnforcer.InconclusivePolicy = conf.InconclusivePolicy
+	default:
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("inconclusivePolicy must be one of %s, %s or %s, got %s", vt.InconclusiveFail, vt.InconclusiveWarn, vt.InconclusiveIgnore, conf.InconclusivePolicy), re.HideStackTrace)
+	}
+
+	switch conf.EnforcementMode {
+	case "":
+		policyEnforcer.Mode = vt.EnforcementModeEnforce
+	case vt.EnforcementModeEnforce, vt.EnforcementModeAudit:
+		policyEnforcer.Mode = conf.EnforcementMode
+	default:
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, vt.ConfigPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("enforcementMode must be %s or %s, got %s", vt.EnforcementModeEnforce, vt.EnforcementModeAudit, conf.EnforcementMode), re.HideStackTrace)
+	}
 	return &policyEnforcer, nil
 }
 
@@ -82,17 +180,51 @@ func (enforcer PolicyEnforcer) VerifyNeeded(_ context.Context, _ common.Referenc
 	return true
 }
 
+// NestedVerifyNeeded determines if the artifacts attached to the given reference
+// should be verified, which is the case if a nested verification policy exists
+// for its artifact type.
+func (enforcer PolicyEnforcer) NestedVerifyNeeded(_ context.Context, referenceDesc ocispecs.ReferenceDescriptor) bool {
+	_, ok := enforcer.NestedPolicies[referenceDesc.ArtifactType]
+	return ok
+}
+
 // ContinueVerifyOnFailure determines if the given error can be ignored and verification can be continued.
-func (enforcer PolicyEnforcer) ContinueVerifyOnFailure(_ context.Context, _ common.Reference, referenceDesc ocispecs.ReferenceDescriptor, _ types.VerifyResult) bool {
+func (enforcer PolicyEnforcer) ContinueVerifyOnFailure(ctx context.Context, _ common.Reference, referenceDesc ocispecs.ReferenceDescriptor, partialVerifyResult types.VerifyResult) bool {
+	if enforcer.auditOnly(ctx) {
+		return true
+	}
 	artifactType := referenceDesc.ArtifactType
-	policy := enforcer.ArtifactTypePolicies[artifactType]
+	if _, ok := enforcer.ThresholdPolicies[artifactType]; ok {
+		// other verifiers may still reach the threshold
+		return true
+	}
+	artifactTypePolicies := enforcer.artifactTypePolicies(ctx)
+	policy := artifactTypePolicies[artifactType]
 	if policy == "" {
-		policy = enforcer.ArtifactTypePolicies[defaultPolicyName]
+		policy = artifactTypePolicies[defaultPolicyName]
 	}
 	if policy == vt.AnyVerifySuccess {
 		return true
 	}
-	return false
+	// inconclusive results only decide the verification if they count as failures
+	return enforcer.InconclusivePolicy != vt.InconclusiveFail && onlyInconclusiveFailures(partialVerifyResult)
+}
+
+// onlyInconclusiveFailures returns true if the result has failed reports and
+// all of them are inconclusive.
+func onlyInconclusiveFailures(verifyResult types.VerifyResult) bool {
+	failed := false
+	for _, report := range verifyResult.VerifierReports {
+		castedReport, ok := report.(verifier.VerifierResult)
+		if !ok || castedReport.IsSuccess {
+			continue
+		}
+		if !castedReport.Inconclusive {
+			return false
+		}
+		failed = true
+	}
+	return failed
 }
 
 // ErrorToVerifyResult converts an error to a properly formatted verify result
@@ -101,6 +233,7 @@ func (enforcer PolicyEnforcer) ErrorToVerifyResult(_ context.Context, subjectRef
 		Subject:   subjectRefString,
 		IsSuccess: false,
 		Message:   fmt.Sprintf("verification failed: %v", verifyError),
+		ErrorCode: re.CodeOf(verifyError, re.ErrorCodeExecutorFailure).String(),
 	}
 	var reports []interface{}
 	reports = append(reports, errorReport)
@@ -109,34 +242,72 @@ func (enforcer PolicyEnforcer) ErrorToVerifyResult(_ context.Context, subjectRef
 
 // OverallVerifyResult determines the final outcome of verification that is constructed using the results from
 // individual verifications
-func (enforcer PolicyEnforcer) OverallVerifyResult(_ context.Context, verifierReports []interface{}) bool {
+func (enforcer PolicyEnforcer) OverallVerifyResult(ctx context.Context, verifierReports []interface{}) bool {
 	if len(verifierReports) <= 0 {
 		return false
 	}
+	if enforcer.auditOnly(ctx) {
+		return true
+	}
 
+	artifactTypePolicies := enforcer.artifactTypePolicies(ctx)
 	// use boolean map to track if each artifact type policy constraint is satisfied
 	verifySuccess := map[string]bool{}
-	for artifactType := range enforcer.ArtifactTypePolicies {
+	for artifactType := range artifactTypePolicies {
 		// add all policies except for default
 		if artifactType != defaultPolicyName {
 			verifySuccess[artifactType] = false
 		}
 	}
+	// successful verifiers of each artifact of the artifact types with a
+	// threshold policy
+	thresholdSuccesses := map[string]map[string]map[string]struct{}{}
+	for artifactType := range enforcer.ThresholdPolicies {
+		verifySuccess[artifactType] = false
+		thresholdSuccesses[artifactType] = map[string]map[string]struct{}{}
+	}
+	// required artifact types fail the verification if no artifact of them
+	// is attached to the subject
+	for _, artifactType := range enforcer.requiredArtifactTypes(verifierReports) {
+		verifySuccess[artifactType] = false
+	}
 
+	counted := 0
 	for _, report := range verifierReports {
 		castedReport := report.(verifier.VerifierResult)
+		if castedReport.Inconclusive {
+			switch enforcer.InconclusivePolicy {
+			case vt.InconclusiveIgnore:
+				continue
+			case vt.InconclusiveWarn:
+				logger.GetLogger(ctx, logOpt).Warnf("verifier %s could not run, counting inconclusive result as success: %s", castedReport.Name, castedReport.Message)
+				castedReport.IsSuccess = true
+			}
+		}
+		if castedReport.IsSuccess && !enforcer.nestedPolicySatisfied(castedReport) {
+			logger.GetLogger(ctx, logOpt).Infof("verifier %s succeeded but the artifacts required to be attached to the %s artifact were not verified", castedReport.Name, castedReport.ArtifactType)
+			castedReport.IsSuccess = false
+		}
+		counted++
 		// extract the policy for the artifact type of the verified artifact if specified
-		policyType, ok := enforcer.ArtifactTypePolicies[castedReport.ArtifactType]
+		policyType, ok := artifactTypePolicies[castedReport.ArtifactType]
 		// if artifact type policy not specified, set policy to be default policy and add artifact type to success map
 		if !ok {
-			policyType = enforcer.ArtifactTypePolicies[defaultPolicyName]
+			policyType = artifactTypePolicies[defaultPolicyName]
 		}
 		// set the artifact type success field in map to false to start
 		if _, ok = verifySuccess[castedReport.ArtifactType]; !ok {
 			verifySuccess[castedReport.ArtifactType] = false
 		}
 
-		if policyType == vt.AnyVerifySuccess && castedReport.IsSuccess {
+		if successes, ok := thresholdSuccesses[castedReport.ArtifactType]; ok {
+			if castedReport.IsSuccess {
+				if successes[castedReport.ReferenceDigest] == nil {
+					successes[castedReport.ReferenceDigest] = map[string]struct{}{}
+				}
+				successes[castedReport.ReferenceDigest][castedReport.Name] = struct{}{}
+			}
+		} else if policyType == vt.AnyVerifySuccess && castedReport.IsSuccess {
 			// if policy is 'any' and report is successful
 			verifySuccess[castedReport.ArtifactType] = true
 		} else if policyType == vt.AllVerifySuccess {
@@ -149,15 +320,211 @@ func (enforcer PolicyEnforcer) OverallVerifyResult(_ context.Context, verifierRe
 		}
 	}
 
+	// fail if all reports are inconclusive and ignored
+	if counted == 0 {
+		return false
+	}
+
+	for artifactType, successes := range thresholdSuccesses {
+		for _, verifiers := range successes {
+			if len(verifiers) >= enforcer.ThresholdPolicies[artifactType].MinimumVerifiers {
+				verifySuccess[artifactType] = true
+			}
+		}
+	}
+
 	// all booleans in map must be true for overall success to be true
 	for artifactType := range verifySuccess {
 		if !verifySuccess[artifactType] {
 			return false
 		}
 	}
+	return enforcer.signerPoliciesSatisfied(verifierReports)
+}
+
+// auditOnly returns true if the policy of the admission operation or of the
+// subject repository in the context only audits failures
+func (enforcer PolicyEnforcer) auditOnly(ctx context.Context) bool {
+	if enforcer.OperationPolicies[vt.OperationFromContext(ctx)].AuditOnly {
+		return true
+	}
+	repositoryPolicy, ok := enforcer.repositoryPolicy(ctx)
+	return ok && repositoryPolicy.AuditOnly
+}
+
+// repositoryPolicy returns the first repository policy matching the subject
+// repository in the context
+func (enforcer PolicyEnforcer) repositoryPolicy(ctx context.Context) (vt.RepositoryPolicy, bool) {
+	repository := vt.RepositoryFromContext(ctx)
+	if repository == "" {
+		return vt.RepositoryPolicy{}, false
+	}
+	for _, repositoryPolicy := range enforcer.RepositoryPolicies {
+		if matchRepository(repositoryPolicy.Repositories, repository) {
+			return repositoryPolicy, true
+		}
+	}
+	return vt.RepositoryPolicy{}, false
+}
+
+// artifactTypePolicies returns the artifact type policies with the overrides of
+// the subject repository and of the admission operation in the context applied,
+// the overrides of the operation take precedence
+func (enforcer PolicyEnforcer) artifactTypePolicies(ctx context.Context) map[string]vt.ArtifactTypeVerifyPolicy {
+	repositoryPolicy, _ := enforcer.repositoryPolicy(ctx)
+	operationPolicy := enforcer.OperationPolicies[vt.OperationFromContext(ctx)]
+	if len(repositoryPolicy.ArtifactVerificationPolicies) == 0 && len(operationPolicy.ArtifactVerificationPolicies) == 0 {
+		return enforcer.ArtifactTypePolicies
+	}
+
+	policies := make(map[string]vt.ArtifactTypeVerifyPolicy, len(enforcer.ArtifactTypePolicies)+len(repositoryPolicy.ArtifactVerificationPolicies)+len(operationPolicy.ArtifactVerificationPolicies))
+	for _, overrides := range []map[string]vt.ArtifactTypeVerifyPolicy{enforcer.ArtifactTypePolicies, repositoryPolicy.ArtifactVerificationPolicies, operationPolicy.ArtifactVerificationPolicies} {
+		for artifactType, policy := range overrides {
+			policies[artifactType] = policy
+		}
+	}
+	return policies
+}
+
+// requiredArtifactTypes returns the artifact types required for the subject of
+// the reports by the policies matching its repository
+func (enforcer PolicyEnforcer) requiredArtifactTypes(verifierReports []interface{}) []string {
+	if len(enforcer.RequiredArtifactTypes) == 0 {
+		return nil
+	}
+	// the artifact types of all policies are required if the repository of the
+	// subject is unknown
+	repository := ""
+	if subjectReference, err := utils.ParseSubjectReference(verifierReports[0].(verifier.VerifierResult).Subject); err == nil {
+		repository = subjectReference.Path
+	}
+
+	var artifactTypes []string
+	for _, requiredPolicy := range enforcer.RequiredArtifactTypes {
+		if repository != "" && !matchRepository(requiredPolicy.Repositories, repository) {
+			continue
+		}
+		artifactTypes = append(artifactTypes, requiredPolicy.ArtifactTypes...)
+	}
+	return artifactTypes
+}
+
+// matchRepository returns true if the repository matches one of the patterns or
+// no patterns are given
+func matchRepository(patterns []string, repository string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedPolicySatisfied returns true if the nested results of the report include
+// a successful result for every artifact type required by its nested policy.
+// Only nested results produced for the verified artifact itself are counted,
+// so a signature over one artifact cannot vouch for the content of another.
+func (enforcer PolicyEnforcer) nestedPolicySatisfied(report verifier.VerifierResult) bool {
+	nestedPolicy, ok := enforcer.NestedPolicies[report.ArtifactType]
+	if !ok {
+		return true
+	}
+	linkedResults := report.LinkedNestedResults()
+	for _, artifactType := range nestedPolicy.ArtifactTypes {
+		verified := false
+		for _, nestedResult := range linkedResults {
+			if nestedResult.ArtifactType == artifactType && nestedResult.IsSuccess {
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			return false
+		}
+	}
 	return true
 }
 
+// signerPoliciesSatisfied returns true if every signer policy has at least the
+// required number of distinct signer identities among the successful reports
+func (enforcer PolicyEnforcer) signerPoliciesSatisfied(verifierReports []interface{}) bool {
+	if len(enforcer.SignerPolicies) == 0 {
+		return true
+	}
+
+	signers := map[string]map[string]struct{}{}
+	for _, report := range verifierReports {
+		castedReport := report.(verifier.VerifierResult)
+		signerPolicy, ok := enforcer.SignerPolicies[castedReport.ArtifactType]
+		if !ok {
+			continue
+		}
+		for _, signer := range verifier.Signers(castedReport) {
+			identity := signer.Identity(signerPolicy.IdentityAttribute)
+			if identity == "" {
+				continue
+			}
+			if signers[castedReport.ArtifactType] == nil {
+				signers[castedReport.ArtifactType] = map[string]struct{}{}
+			}
+			signers[castedReport.ArtifactType][identity] = struct{}{}
+		}
+	}
+
+	for artifactType, signerPolicy := range enforcer.SignerPolicies {
+		if len(signers[artifactType]) < signerPolicy.MinimumSigners {
+			return false
+		}
+	}
+	return true
+}
+
+// EnforcementMode returns whether failed verifications deny the subject.
+func (enforcer PolicyEnforcer) EnforcementMode(_ context.Context) vt.EnforcementMode {
+	return enforcer.Mode
+}
+
+// ArtifactTypes returns the sorted artifact types referenced by the policies,
+// excluding the default artifact type policy.
+func (enforcer PolicyEnforcer) ArtifactTypes() []string {
+	artifactTypes := map[string]struct{}{}
+	addPolicies := func(policies map[string]vt.ArtifactTypeVerifyPolicy) {
+		for artifactType := range policies {
+			if artifactType != defaultPolicyName {
+				artifactTypes[artifactType] = struct{}{}
+			}
+		}
+	}
+	addPolicies(enforcer.ArtifactTypePolicies)
+	for _, operationPolicy := range enforcer.OperationPolicies {
+		addPolicies(operationPolicy.ArtifactVerificationPolicies)
+	}
+	for _, repositoryPolicy := range enforcer.RepositoryPolicies {
+		addPolicies(repositoryPolicy.ArtifactVerificationPolicies)
+	}
+	for _, requiredPolicy := range enforcer.RequiredArtifactTypes {
+		for _, artifactType := range requiredPolicy.ArtifactTypes {
+			artifactTypes[artifactType] = struct{}{}
+		}
+	}
+	for artifactType, nestedPolicy := range enforcer.NestedPolicies {
+		artifactTypes[artifactType] = struct{}{}
+		for _, nestedArtifactType := range nestedPolicy.ArtifactTypes {
+			artifactTypes[nestedArtifactType] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(artifactTypes))
+	for artifactType := range artifactTypes {
+		result = append(result, artifactType)
+	}
+	sort.Strings(result)
+	return result
+}
+
 // GetPolicyType returns the type of the policy.
 func (enforcer PolicyEnforcer) GetPolicyType(_ context.Context) string {
 	return vt.ConfigPolicy
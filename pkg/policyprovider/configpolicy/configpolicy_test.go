@@ -17,11 +17,13 @@ package configpolicy
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/deislabs/ratify/pkg/common"
 	vt "github.com/deislabs/ratify/pkg/executor/types"
 	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/policyprovider"
 	pc "github.com/deislabs/ratify/pkg/policyprovider/config"
 	pf "github.com/deislabs/ratify/pkg/policyprovider/factory"
 	"github.com/deislabs/ratify/pkg/policyprovider/types"
@@ -86,6 +88,20 @@ func TestPolicyEnforcer_ContinueVerifyOnFailure(t *testing.T) {
 	if check != true {
 		t.Fatalf("For artifact types without a policy the default policy should be followed")
 	}
+
+	referenceDesc.ArtifactType = "application/spdx+json"
+	inconclusiveResult := vt.VerifyResult{
+		IsSuccess:       false,
+		VerifierReports: []interface{}{vr.VerifierResult{IsSuccess: false, Inconclusive: true}},
+	}
+	if policyEnforcer.ContinueVerifyOnFailure(ctx, subjectReference, referenceDesc, inconclusiveResult) {
+		t.Fatalf("Inconclusive results should be failures by default")
+	}
+	enforcer := policyEnforcer.(*PolicyEnforcer)
+	enforcer.InconclusivePolicy = types.InconclusiveIgnore
+	if !enforcer.ContinueVerifyOnFailure(ctx, subjectReference, referenceDesc, inconclusiveResult) {
+		t.Fatalf("Ignored inconclusive results should not stop the verification")
+	}
 }
 
 func TestPolicyEnforcer_OverallVerifyResult(t *testing.T) {
@@ -305,9 +321,700 @@ func TestPolicyEnforcer_OverallVerifyResult(t *testing.T) {
 	}
 }
 
+func TestPolicyEnforcer_SignerPolicies(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	signedBy := func(subject string, isSuccess bool) vr.VerifierResult {
+		return vr.VerifierResult{
+			IsSuccess:    isSuccess,
+			ArtifactType: notationSignature,
+			Extensions:   map[string]string{"Issuer": "CN=ca", "SN": subject},
+		}
+	}
+
+	testcases := []struct {
+		name              string
+		minimumSigners    int
+		identityAttribute string
+		verifierReports   []interface{}
+		output            bool
+	}{
+		{
+			name:           "two distinct signers",
+			minimumSigners: 2,
+			verifierReports: []interface{}{
+				signedBy("CN=builder", true),
+				signedBy("CN=security", true),
+			},
+			output: true,
+		},
+		{
+			name:           "same signer signed twice",
+			minimumSigners: 2,
+			verifierReports: []interface{}{
+				signedBy("CN=builder", true),
+				signedBy("CN=builder", true),
+			},
+			output: false,
+		},
+		{
+			name:           "failed signature does not count",
+			minimumSigners: 2,
+			verifierReports: []interface{}{
+				signedBy("CN=builder", true),
+				signedBy("CN=security", false),
+			},
+			output: false,
+		},
+		{
+			name:           "signature without identity does not count",
+			minimumSigners: 2,
+			verifierReports: []interface{}{
+				signedBy("CN=builder", true),
+				vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+			},
+			output: false,
+		},
+		{
+			name:              "distinct signers of the same issuer",
+			minimumSigners:    2,
+			identityAttribute: "issuer",
+			verifierReports: []interface{}{
+				signedBy("CN=builder", true),
+				signedBy("CN=security", true),
+			},
+			output: false,
+		},
+		{
+			name:           "signers reported by verifier",
+			minimumSigners: 2,
+			verifierReports: []interface{}{
+				vr.VerifierResult{
+					IsSuccess:    true,
+					ArtifactType: notationSignature,
+					Signers: []vr.Signer{
+						{ID: "key:sha256:abc", Kind: vr.SignerKindKey, KeyFingerprint: "sha256:abc"},
+						{ID: "fulcio:https://issuer/builder@example.com", Kind: vr.SignerKindFulcio, Issuer: "https://issuer"},
+					},
+				},
+			},
+			output: true,
+		},
+		{
+			name:           "no signatures",
+			minimumSigners: 1,
+			verifierReports: []interface{}{
+				vr.VerifierResult{IsSuccess: true, ArtifactType: "application/spdx+json"},
+			},
+			output: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			config := pc.PoliciesConfig{
+				Version: "1.0.0",
+				PolicyPlugin: map[string]interface{}{
+					"name": "configPolicy",
+					"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+						notationSignature: "any",
+						"default":         "any",
+					},
+					"signerPolicies": map[string]types.SignerPolicy{
+						notationSignature: {MinimumSigners: testcase.minimumSigners, IdentityAttribute: testcase.identityAttribute},
+					},
+				},
+			}
+
+			policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+			if err != nil {
+				t.Fatalf("PolicyEnforcer should create from PoliciesConfig, err: %v", err)
+			}
+
+			if result := policyEnforcer.OverallVerifyResult(context.Background(), testcase.verifierReports); result != testcase.output {
+				t.Fatalf("Expected %v from OverallVerifyResult but got %v", testcase.output, result)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_ThresholdPolicies(t *testing.T) {
+	sbom := "application/spdx+json"
+	verifiedBy := func(name string, digest string, isSuccess bool) vr.VerifierResult {
+		return vr.VerifierResult{
+			Name:            name,
+			IsSuccess:       isSuccess,
+			ArtifactType:    sbom,
+			ReferenceDigest: digest,
+		}
+	}
+
+	testcases := []struct {
+		name             string
+		minimumVerifiers int
+		verifierReports  []interface{}
+		output           bool
+	}{
+		{
+			name:             "two of three verifiers succeeded",
+			minimumVerifiers: 2,
+			verifierReports: []interface{}{
+				verifiedBy("sbom", "sha256:a", true),
+				verifiedBy("licensechecker", "sha256:a", false),
+				verifiedBy("schemavalidator", "sha256:a", true),
+			},
+			output: true,
+		},
+		{
+			name:             "one of three verifiers succeeded",
+			minimumVerifiers: 2,
+			verifierReports: []interface{}{
+				verifiedBy("sbom", "sha256:a", true),
+				verifiedBy("licensechecker", "sha256:a", false),
+				verifiedBy("schemavalidator", "sha256:a", false),
+			},
+			output: false,
+		},
+		{
+			name:             "same verifier succeeded twice",
+			minimumVerifiers: 2,
+			verifierReports: []interface{}{
+				verifiedBy("sbom", "sha256:a", true),
+				verifiedBy("sbom", "sha256:a", true),
+			},
+			output: false,
+		},
+		{
+			name:             "verifiers succeeded for different artifacts",
+			minimumVerifiers: 2,
+			verifierReports: []interface{}{
+				verifiedBy("sbom", "sha256:a", true),
+				verifiedBy("licensechecker", "sha256:b", true),
+			},
+			output: false,
+		},
+		{
+			name:             "other artifact types follow artifact verification policies",
+			minimumVerifiers: 1,
+			verifierReports: []interface{}{
+				verifiedBy("sbom", "sha256:a", true),
+				vr.VerifierResult{Name: "notation", IsSuccess: false, ArtifactType: "application/vnd.cncf.notary.signature"},
+			},
+			output: false,
+		},
+		{
+			name:             "no artifacts of the artifact type",
+			minimumVerifiers: 1,
+			verifierReports: []interface{}{
+				vr.VerifierResult{Name: "notation", IsSuccess: true, ArtifactType: "application/vnd.cncf.notary.signature"},
+			},
+			output: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			config := pc.PoliciesConfig{
+				Version: "1.0.0",
+				PolicyPlugin: map[string]interface{}{
+					"name": "configPolicy",
+					"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+						sbom:      "all",
+						"default": "all",
+					},
+					"thresholdPolicies": map[string]types.ThresholdPolicy{
+						sbom: {MinimumVerifiers: testcase.minimumVerifiers},
+					},
+				},
+			}
+
+			policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+			if err != nil {
+				t.Fatalf("PolicyEnforcer should create from PoliciesConfig, err: %v", err)
+			}
+
+			if result := policyEnforcer.OverallVerifyResult(context.Background(), testcase.verifierReports); result != testcase.output {
+				t.Fatalf("Expected %v from OverallVerifyResult but got %v", testcase.output, result)
+			}
+			if !policyEnforcer.ContinueVerifyOnFailure(context.Background(), common.Reference{}, ocispecs.ReferenceDescriptor{ArtifactType: sbom}, vt.VerifyResult{}) {
+				t.Fatalf("expected verification to continue on failure of an artifact type with a threshold policy")
+			}
+		})
+	}
+}
+
+func TestCreate_InvalidThresholdPolicy(t *testing.T) {
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name": "configPolicy",
+			"thresholdPolicies": map[string]types.ThresholdPolicy{
+				"application/spdx+json": {MinimumVerifiers: 0},
+			},
+		},
+	}
+
+	if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+		t.Fatalf("expected error creating policy provider with invalid threshold policy")
+	}
+}
+
+func TestPolicyEnforcer_RequiredArtifactTypes(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	sbom := "application/spdx+json"
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name": "configPolicy",
+			"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+				"default": "all",
+			},
+			"requiredArtifactTypes": []types.RequiredArtifactTypesPolicy{
+				{ArtifactTypes: []string{notationSignature}},
+				{Repositories: []string{"myregistry.io/prod/*"}, ArtifactTypes: []string{sbom}},
+			},
+		},
+	}
+	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+	if err != nil {
+		t.Fatalf("PolicyEnforcer should create from PoliciesConfig, err: %v", err)
+	}
+
+	testcases := []struct {
+		name            string
+		verifierReports []interface{}
+		output          bool
+	}{
+		{
+			name: "required artifact types verified",
+			verifierReports: []interface{}{
+				vr.VerifierResult{Subject: "myregistry.io/prod/app:v1", IsSuccess: true, ArtifactType: notationSignature},
+				vr.VerifierResult{Subject: "myregistry.io/prod/app:v1", IsSuccess: true, ArtifactType: sbom},
+			},
+			output: true,
+		},
+		{
+			name: "artifact type required for repository missing",
+			verifierReports: []interface{}{
+				vr.VerifierResult{Subject: "myregistry.io/prod/app:v1", IsSuccess: true, ArtifactType: notationSignature},
+			},
+			output: false,
+		},
+		{
+			name: "artifact type not required for repository",
+			verifierReports: []interface{}{
+				vr.VerifierResult{Subject: "myregistry.io/dev/app:v1", IsSuccess: true, ArtifactType: notationSignature},
+			},
+			output: true,
+		},
+		{
+			name: "artifact type required for all repositories missing",
+			verifierReports: []interface{}{
+				vr.VerifierResult{Subject: "myregistry.io/dev/app:v1", IsSuccess: true, ArtifactType: sbom},
+			},
+			output: false,
+		},
+		{
+			name: "unknown repository requires all artifact types",
+			verifierReports: []interface{}{
+				vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+			},
+			output: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if result := policyEnforcer.OverallVerifyResult(context.Background(), testcase.verifierReports); result != testcase.output {
+				t.Fatalf("Expected %v from OverallVerifyResult but got %v", testcase.output, result)
+			}
+		})
+	}
+}
+
+func TestCreate_InvalidRequiredArtifactTypes(t *testing.T) {
+	for _, requiredPolicy := range []types.RequiredArtifactTypesPolicy{
+		{Repositories: []string{"myregistry.io/*"}},
+		{Repositories: []string{"myregistry.io/["}, ArtifactTypes: []string{"application/spdx+json"}},
+	} {
+		config := pc.PoliciesConfig{
+			Version: "1.0.0",
+			PolicyPlugin: map[string]interface{}{
+				"name":                  "configPolicy",
+				"requiredArtifactTypes": []types.RequiredArtifactTypesPolicy{requiredPolicy},
+			},
+		}
+
+		if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+			t.Fatalf("expected error creating policy provider with invalid required artifact types policy %+v", requiredPolicy)
+		}
+	}
+}
+
+func TestPolicyEnforcer_OperationPolicies(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	sbom := "application/spdx+json"
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name": "configPolicy",
+			"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+				notationSignature: "all",
+				"default":         "all",
+			},
+			"operationPolicies": map[string]types.OperationPolicy{
+				"update": {
+					ArtifactVerificationPolicies: map[string]types.ArtifactTypeVerifyPolicy{
+						notationSignature: "any",
+					},
+				},
+				"DELETE": {AuditOnly: true},
+			},
+		},
+	}
+	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+	if err != nil {
+		t.Fatalf("PolicyEnforcer should create from PoliciesConfig: %v", err)
+	}
+
+	verifierReports := []interface{}{
+		vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: false, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: true, ArtifactType: sbom},
+	}
+	failedSbomReports := []interface{}{
+		vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: false, ArtifactType: sbom},
+	}
+
+	testcases := []struct {
+		name            string
+		operation       string
+		verifierReports []interface{}
+		output          bool
+	}{
+		{name: "no operation uses base policy", verifierReports: verifierReports, output: false},
+		{name: "create uses base policy", operation: "CREATE", verifierReports: verifierReports, output: false},
+		{name: "update overrides artifact type policy", operation: "UPDATE", verifierReports: verifierReports, output: true},
+		{name: "update keeps policies not overridden", operation: "UPDATE", verifierReports: failedSbomReports, output: false},
+		{name: "audit only operation", operation: "DELETE", verifierReports: failedSbomReports, output: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			ctx := types.WithOperation(context.Background(), testcase.operation)
+			if result := policyEnforcer.OverallVerifyResult(ctx, testcase.verifierReports); result != testcase.output {
+				t.Fatalf("expected overall verify result %v, got %v", testcase.output, result)
+			}
+		})
+	}
+
+	referenceDesc := ocispecs.ReferenceDescriptor{ArtifactType: notationSignature}
+	if policyEnforcer.ContinueVerifyOnFailure(context.Background(), common.Reference{}, referenceDesc, vt.VerifyResult{}) {
+		t.Fatalf("base policy 'all' should not allow continuing on verify failure")
+	}
+	if !policyEnforcer.ContinueVerifyOnFailure(types.WithOperation(context.Background(), "update"), common.Reference{}, referenceDesc, vt.VerifyResult{}) {
+		t.Fatalf("update policy 'any' should allow continuing on verify failure")
+	}
+}
+
+func TestPolicyEnforcer_RepositoryPolicies(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	sbom := "application/spdx+json"
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name": "configPolicy",
+			"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+				notationSignature: "any",
+				"default":         "all",
+			},
+			"repositoryPolicies": []types.RepositoryPolicy{
+				{
+					Repositories: []string{"prod.registry.io/*"},
+					ArtifactVerificationPolicies: map[string]types.ArtifactTypeVerifyPolicy{
+						notationSignature: "all",
+					},
+				},
+				{Repositories: []string{"dev.registry.io/*"}, AuditOnly: true},
+			},
+			"operationPolicies": map[string]types.OperationPolicy{
+				"update": {
+					ArtifactVerificationPolicies: map[string]types.ArtifactTypeVerifyPolicy{
+						notationSignature: "any",
+					},
+				},
+			},
+		},
+	}
+	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+	if err != nil {
+		t.Fatalf("PolicyEnforcer should create from PoliciesConfig: %v", err)
+	}
+
+	verifierReports := []interface{}{
+		vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: false, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: true, ArtifactType: sbom},
+	}
+
+	testcases := []struct {
+		name       string
+		repository string
+		operation  string
+		output     bool
+	}{
+		{name: "unknown repository uses base policy", output: true},
+		{name: "unmatched repository uses base policy", repository: "test.registry.io/app", output: true},
+		{name: "repository overrides artifact type policy", repository: "prod.registry.io/app", output: false},
+		{name: "operation overrides repository policy", repository: "prod.registry.io/app", operation: "UPDATE", output: true},
+		{name: "audit only repository", repository: "dev.registry.io/app", output: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			ctx := types.WithRepository(types.WithOperation(context.Background(), testcase.operation), testcase.repository)
+			if result := policyEnforcer.OverallVerifyResult(ctx, verifierReports); result != testcase.output {
+				t.Fatalf("expected overall verify result %v, got %v", testcase.output, result)
+			}
+		})
+	}
+
+	referenceDesc := ocispecs.ReferenceDescriptor{ArtifactType: notationSignature}
+	if !policyEnforcer.ContinueVerifyOnFailure(context.Background(), common.Reference{}, referenceDesc, vt.VerifyResult{}) {
+		t.Fatalf("base policy 'any' should allow continuing on verify failure")
+	}
+	if policyEnforcer.ContinueVerifyOnFailure(types.WithRepository(context.Background(), "prod.registry.io/app"), common.Reference{}, referenceDesc, vt.VerifyResult{}) {
+		t.Fatalf("repository policy 'all' should not allow continuing on verify failure")
+	}
+}
+
+func TestCreate_InvalidRepositoryPolicy(t *testing.T) {
+	for _, repositoryPolicy := range []types.RepositoryPolicy{
+		{AuditOnly: true},
+		{Repositories: []string{"prod.registry.io/["}},
+	} {
+		config := pc.PoliciesConfig{
+			Version: "1.0.0",
+			PolicyPlugin: map[string]interface{}{
+				"name":               "configPolicy",
+				"repositoryPolicies": []types.RepositoryPolicy{repositoryPolicy},
+			},
+		}
+
+		if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+			t.Fatalf("expected error creating policy provider with invalid repository policy %+v", repositoryPolicy)
+		}
+	}
+}
+
+func TestCreate_InvalidSignerPolicy(t *testing.T) {
+	for _, signerPolicy := range []types.SignerPolicy{
+		{MinimumSigners: 0},
+		{MinimumSigners: 1, IdentityAttribute: "email"},
+	} {
+		config := pc.PoliciesConfig{
+			Version: "1.0.0",
+			PolicyPlugin: map[string]interface{}{
+				"name": "configPolicy",
+				"signerPolicies": map[string]types.SignerPolicy{
+					"application/vnd.cncf.notary.signature": signerPolicy,
+				},
+			},
+		}
+
+		if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+			t.Fatalf("expected error creating policy provider with invalid signer policy %+v", signerPolicy)
+		}
+	}
+}
+
 func TestGetPolicyType(t *testing.T) {
 	enforcer := PolicyEnforcer{}
 	if policyType := enforcer.GetPolicyType(context.Background()); policyType != "configpolicy" {
 		t.Fatalf("expected policy type: configpolicy, got %v", policyType)
 	}
 }
+
+func TestPolicyEnforcer_InconclusivePolicy(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	sbom := "application/spdx+json"
+	verifierReports := []interface{}{
+		vr.VerifierResult{IsSuccess: true, ArtifactType: notationSignature},
+		vr.VerifierResult{IsSuccess: false, Inconclusive: true, ArtifactType: sbom},
+	}
+	onlyInconclusiveReports := []interface{}{
+		vr.VerifierResult{IsSuccess: false, Inconclusive: true, ArtifactType: sbom},
+	}
+
+	testcases := []struct {
+		inconclusivePolicy string
+		verifierReports    []interface{}
+		output             bool
+	}{
+		{inconclusivePolicy: "", verifierReports: verifierReports, output: false},
+		{inconclusivePolicy: "fail", verifierReports: verifierReports, output: false},
+		{inconclusivePolicy: "warn", verifierReports: verifierReports, output: true},
+		{inconclusivePolicy: "warn", verifierReports: onlyInconclusiveReports, output: true},
+		{inconclusivePolicy: "ignore", verifierReports: verifierReports, output: true},
+		{inconclusivePolicy: "ignore", verifierReports: onlyInconclusiveReports, output: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.inconclusivePolicy, func(t *testing.T) {
+			config := pc.PoliciesConfig{
+				Version: "1.0.0",
+				PolicyPlugin: map[string]interface{}{
+					"name":               "configPolicy",
+					"inconclusivePolicy": testcase.inconclusivePolicy,
+				},
+			}
+			policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+			if err != nil {
+				t.Fatalf("PolicyEnforcer should create from PoliciesConfig: %v", err)
+			}
+			if result := policyEnforcer.OverallVerifyResult(context.Background(), testcase.verifierReports); result != testcase.output {
+				t.Fatalf("expected overall verify result %v, got %v", testcase.output, result)
+			}
+		})
+	}
+}
+
+func TestCreate_InvalidInconclusivePolicy(t *testing.T) {
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name":               "configPolicy",
+			"inconclusivePolicy": "pass",
+		},
+	}
+
+	if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+		t.Fatalf("expected error creating policy provider with invalid inconclusive policy")
+	}
+}
+
+func TestCreate_EnforcementMode(t *testing.T) {
+	testcases := []struct {
+		enforcementMode string
+		expected        types.EnforcementMode
+		expectErr       bool
+	}{
+		{enforcementMode: "", expected: types.EnforcementModeEnforce},
+		{enforcementMode: "enforce", expected: types.EnforcementModeEnforce},
+		{enforcementMode: "audit", expected: types.EnforcementModeAudit},
+		{enforcementMode: "warn", expectErr: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.enforcementMode, func(t *testing.T) {
+			config := pc.PoliciesConfig{
+				Version: "1.0.0",
+				PolicyPlugin: map[string]interface{}{
+					"name":            "configPolicy",
+					"enforcementMode": testcase.enforcementMode,
+				},
+			}
+			policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+			if testcase.expectErr != (err != nil) {
+				t.Fatalf("error = %v, expectErr = %v", err, testcase.expectErr)
+			}
+			if err != nil {
+				return
+			}
+			if mode := policyprovider.GetEnforcementMode(context.Background(), policyEnforcer); mode != testcase.expected {
+				t.Fatalf("expected enforcement mode %s, got %s", testcase.expected, mode)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_NestedVerificationPolicies(t *testing.T) {
+	notationSignature := "application/vnd.cncf.notary.signature"
+	sbom := "application/spdx+json"
+	config := pc.PoliciesConfig{
+		Version: "1.0.0",
+		PolicyPlugin: map[string]interface{}{
+			"name": "configPolicy",
+			"artifactVerificationPolicies": map[string]types.ArtifactTypeVerifyPolicy{
+				"default": "all",
+			},
+			"nestedVerificationPolicies": map[string]types.NestedVerificationPolicy{
+				sbom: {ArtifactTypes: []string{notationSignature}},
+			},
+		},
+	}
+	policyEnforcer, err := pf.CreatePolicyProviderFromConfig(config)
+	if err != nil {
+		t.Fatalf("PolicyEnforcer should create from PoliciesConfig, err: %v", err)
+	}
+
+	if !policyEnforcer.NestedVerifyNeeded(context.Background(), ocispecs.ReferenceDescriptor{ArtifactType: sbom}) {
+		t.Fatalf("expected artifacts attached to sbom to be verified")
+	}
+	if policyEnforcer.NestedVerifyNeeded(context.Background(), ocispecs.ReferenceDescriptor{ArtifactType: notationSignature}) {
+		t.Fatalf("expected artifacts attached to signatures not to be verified")
+	}
+
+	sbomDigest := "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	otherDigest := "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	testcases := []struct {
+		name          string
+		nestedResults []vr.VerifierResult
+		output        bool
+	}{
+		{
+			name:          "nested signature verified",
+			nestedResults: []vr.VerifierResult{{Subject: "localhost:5000/net-monitor@" + sbomDigest, IsSuccess: true, ArtifactType: notationSignature}},
+			output:        true,
+		},
+		{
+			name:          "nested signature failed",
+			nestedResults: []vr.VerifierResult{{Subject: "localhost:5000/net-monitor@" + sbomDigest, IsSuccess: false, ArtifactType: notationSignature}},
+			output:        false,
+		},
+		{
+			name:          "nested signature over another artifact",
+			nestedResults: []vr.VerifierResult{{Subject: "localhost:5000/net-monitor@" + otherDigest, IsSuccess: true, ArtifactType: notationSignature}},
+			output:        false,
+		},
+		{
+			name:   "no nested signature",
+			output: false,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			reports := []interface{}{vr.VerifierResult{IsSuccess: true, ArtifactType: sbom, ReferenceDigest: sbomDigest, NestedResults: testcase.nestedResults}}
+			if result := policyEnforcer.OverallVerifyResult(context.Background(), reports); result != testcase.output {
+				t.Fatalf("Expected %v from OverallVerifyResult but got %v", testcase.output, result)
+			}
+		})
+	}
+
+	config.PolicyPlugin["nestedVerificationPolicies"] = map[string]types.NestedVerificationPolicy{sbom: {}}
+	if _, err := pf.CreatePolicyProviderFromConfig(config); err == nil {
+		t.Fatalf("expected error creating policy provider with empty nested verification policy")
+	}
+}
+
+func TestPolicyEnforcer_ArtifactTypes(t *testing.T) {
+	enforcer := PolicyEnforcer{
+		ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+			defaultPolicyName:                       types.AllVerifySuccess,
+			"application/vnd.cncf.notary.signature": types.AnyVerifySuccess,
+		},
+		RequiredArtifactTypes: []types.RequiredArtifactTypesPolicy{
+			{ArtifactTypes: []string{"application/spdx+json"}},
+		},
+		NestedPolicies: map[string]types.NestedVerificationPolicy{
+			"application/spdx+json": {ArtifactTypes: []string{"application/vnd.cncf.notary.signature"}},
+		},
+		RepositoryPolicies: []types.RepositoryPolicy{
+			{ArtifactVerificationPolicies: map[string]types.ArtifactTypeVerifyPolicy{"application/vnd.example.sarif": types.AllVerifySuccess}},
+		},
+	}
+	expected := []string{"application/spdx+json", "application/vnd.cncf.notary.signature", "application/vnd.example.sarif"}
+	if got := enforcer.ArtifactTypes(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected artifact types %v, got %v", expected, got)
+	}
+}
@@ -30,6 +30,10 @@ func (p *TestPolicyProvider) VerifyNeeded(_ context.Context, _ common.Reference,
 	return true
 }
 
+func (p *TestPolicyProvider) NestedVerifyNeeded(_ context.Context, _ ocispecs.ReferenceDescriptor) bool {
+	return false
+}
+
 func (p *TestPolicyProvider) ContinueVerifyOnFailure(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ types.VerifyResult) bool {
 	return true
 }
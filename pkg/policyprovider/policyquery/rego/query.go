@@ -17,8 +17,13 @@ package query
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/deislabs/ratify/pkg/metrics"
 	"github.com/deislabs/ratify/pkg/policyprovider/policyquery"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/pkg/errors"
@@ -28,8 +33,54 @@ const (
 	query = "data.ratify.policy.valid"
 	// RegoName is a constant for "rego"
 	RegoName = "rego"
+
+	// maxPreparedQueries bounds the number of compiled policies kept for reuse.
+	maxPreparedQueries = 16
+
+	resultAllow = "allow"
+	resultDeny  = "deny"
+	resultError = "error"
 )
 
+// preparedQueries caches the compiled queries by the digest of the policy, so
+// that recreating the policy provider on a configuration change or a policy
+// resource resync does not recompile an unchanged policy. Prepared queries
+// are safe for concurrent evaluation.
+var preparedQueries = &queryCache{queries: map[string]rego.PreparedEvalQuery{}}
+
+type queryCache struct {
+	mu      sync.Mutex
+	queries map[string]rego.PreparedEvalQuery
+	// keys are the digests of the cached queries in insertion order
+	keys []string
+}
+
+// getOrPrepare returns the cached query of the policy or compiles it.
+func (c *queryCache) getOrPrepare(policy string) (rego.PreparedEvalQuery, error) {
+	digest := sha256.Sum256([]byte(policy))
+	key := hex.EncodeToString(digest[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prepared, ok := c.queries[key]; ok {
+		return prepared, nil
+	}
+	prepared, err := rego.New(
+		rego.Query(query),
+		rego.Module("policy.rego", policy),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+	if len(c.keys) >= maxPreparedQueries {
+		delete(c.queries, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.queries[key] = prepared
+	c.keys = append(c.keys, key)
+	return prepared, nil
+}
+
 // Rego is a wrapper around the OPA rego library.
 type Rego struct {
 	query rego.PreparedEvalQuery
@@ -44,10 +95,7 @@ func init() {
 
 // Create creates a new Rego query object.
 func (f *RegoFactory) Create(policy string) (policyquery.PolicyQuery, error) {
-	query, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", policy),
-	).PrepareForEval(context.Background())
+	query, err := preparedQueries.getOrPrepare(policy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare rego query, err: %+w", err)
 	}
@@ -56,7 +104,18 @@ func (f *RegoFactory) Create(policy string) (policyquery.PolicyQuery, error) {
 }
 
 // Evaluate evaluates the policy against the input.
-func (r *Rego) Evaluate(ctx context.Context, input map[string]interface{}) (bool, error) {
+func (r *Rego) Evaluate(ctx context.Context, input map[string]interface{}) (result bool, err error) {
+	startTime := time.Now()
+	defer func() {
+		evaluationResult := resultDeny
+		if err != nil {
+			evaluationResult = resultError
+		} else if result {
+			evaluationResult = resultAllow
+		}
+		metrics.ReportPolicyEvaluationDuration(ctx, time.Since(startTime).Microseconds(), RegoName, evaluationResult)
+	}()
+
 	results, err := r.query.Eval(ctx, rego.EvalInput(input))
 
 	if err != nil {
@@ -64,10 +123,10 @@ func (r *Rego) Evaluate(ctx context.Context, input map[string]interface{}) (bool
 	} else if len(results) == 0 || len(results[0].Expressions) == 0 {
 		return false, errors.New("no results returned from query")
 	} else {
-		result, ok := results[0].Expressions[0].Value.(bool)
+		valid, ok := results[0].Expressions[0].Value.(bool)
 		if !ok {
 			return false, fmt.Errorf("unexpected result type: %v", results[0].Expressions[0].Value)
 		}
-		return result, nil
+		return valid, nil
 	}
 }
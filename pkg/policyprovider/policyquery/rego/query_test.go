@@ -17,6 +17,7 @@ package query
 
 import (
 	"context"
+	"fmt"
 	"testing"
 )
 
@@ -117,3 +118,27 @@ func TestEvaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestCreate_ReusesPreparedQuery(t *testing.T) {
+	factory := &RegoFactory{}
+	if _, err := factory.Create(policy1); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	cached := len(preparedQueries.keys)
+	if _, err := factory.Create(policy1); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(preparedQueries.keys) != cached {
+		t.Fatalf("expected prepared query of unchanged policy to be reused, cached queries %d, expected %d", len(preparedQueries.keys), cached)
+	}
+
+	for i := 0; i < maxPreparedQueries+1; i++ {
+		policy := fmt.Sprintf("package ratify.policy\ndefault valid := false\nvalid { input.index == %d }", i)
+		if _, err := factory.Create(policy); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if len(preparedQueries.keys) != maxPreparedQueries || len(preparedQueries.queries) != maxPreparedQueries {
+		t.Fatalf("expected at most %d prepared queries, got %d", maxPreparedQueries, len(preparedQueries.queries))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/internal/logger"
@@ -33,12 +34,14 @@ import (
 	opa "github.com/deislabs/ratify/pkg/policyprovider/policyengine/opaengine"
 	query "github.com/deislabs/ratify/pkg/policyprovider/policyquery/rego"
 	policyTypes "github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/verifier"
 )
 
 type policyEnforcer struct {
 	Policy             string
 	OpaEngine          policyengine.PolicyEngine
 	passthroughEnabled bool
+	enforcementMode    policyTypes.EnforcementMode
 }
 
 type policyEnforcerConf struct {
@@ -46,6 +49,8 @@ type policyEnforcerConf struct {
 	Policy             string `json:"policy"`
 	PolicyPath         string `json:"policyPath"`
 	PassthroughEnabled bool   `json:"passthroughEnabled"`
+	// EnforcementMode is enforce or audit, defaults to enforce.
+	EnforcementMode policyTypes.EnforcementMode `json:"enforcementMode,omitempty"`
 }
 
 // Factory is a factory for creating rego policy enforcers.
@@ -82,6 +87,19 @@ func (f *Factory) Create(policyConfig config.PolicyPluginConfig) (policyprovider
 		return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, policyTypes.RegoPolicy, re.PolicyProviderLink, nil, "policy is required for rego policy provider", re.HideStackTrace)
 	}
 
+	switch conf.EnforcementMode {
+	case "":
+		conf.EnforcementMode = policyTypes.EnforcementModeEnforce
+	case policyTypes.EnforcementModeEnforce:
+	case policyTypes.EnforcementModeAudit:
+		// the decision is made by Gatekeeper in passthrough mode
+		if conf.PassthroughEnabled {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, policyTypes.RegoPolicy, re.PolicyProviderLink, nil, "audit enforcement mode is not supported in passthrough mode", re.HideStackTrace)
+		}
+	default:
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.PolicyProvider, policyTypes.RegoPolicy, re.PolicyProviderLink, nil, fmt.Sprintf("enforcementMode must be %s or %s, got %s", policyTypes.EnforcementModeEnforce, policyTypes.EnforcementModeAudit, conf.EnforcementMode), re.HideStackTrace)
+	}
+
 	engine, err := policyengine.CreateEngineFromConfig(policyengine.Config{
 		Name:          opa.OPA,
 		QueryLanguage: query.RegoName,
@@ -95,6 +113,7 @@ func (f *Factory) Create(policyConfig config.PolicyPluginConfig) (policyprovider
 		Policy:             conf.Policy,
 		OpaEngine:          engine,
 		passthroughEnabled: conf.PassthroughEnabled,
+		enforcementMode:    conf.EnforcementMode,
 	}
 
 	return policyEnforcer, nil
@@ -105,6 +124,12 @@ func (e *policyEnforcer) VerifyNeeded(_ context.Context, _ common.Reference, _ o
 	return true
 }
 
+// NestedVerifyNeeded determines if the artifacts attached to the given reference
+// should be verified. All nested artifacts are reported to the rego policy.
+func (e *policyEnforcer) NestedVerifyNeeded(_ context.Context, _ ocispecs.ReferenceDescriptor) bool {
+	return true
+}
+
 // ContinueVerifyOnFailure determines if verification should continue if a previous verification failed.
 func (e *policyEnforcer) ContinueVerifyOnFailure(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ types.VerifyResult) bool {
 	return true
@@ -123,6 +148,12 @@ func (e *policyEnforcer) OverallVerifyResult(ctx context.Context, verifierReport
 
 	nestedReports := map[string]interface{}{}
 	nestedReports["verifierReports"] = verifierReports
+	if operation := policyTypes.OperationFromContext(ctx); operation != "" {
+		nestedReports["operation"] = operation
+	}
+	if verificationTime, ok := verifier.VerificationTime(ctx); ok {
+		nestedReports["verificationTime"] = verificationTime.UTC().Format(time.RFC3339Nano)
+	}
 	result, err := e.OpaEngine.Evaluate(ctx, nestedReports)
 	if err != nil {
 		logger.GetLogger(ctx, logOpt).Errorf("failed to evaluate policy: %v", err)
@@ -131,6 +162,11 @@ func (e *policyEnforcer) OverallVerifyResult(ctx context.Context, verifierReport
 	return result
 }
 
+// EnforcementMode returns whether failed verifications deny the subject.
+func (e *policyEnforcer) EnforcementMode(_ context.Context) policyTypes.EnforcementMode {
+	return e.enforcementMode
+}
+
 // GetPolicyType returns the type of the policy.
 func (e *policyEnforcer) GetPolicyType(_ context.Context) string {
 	return policyTypes.RegoPolicy
@@ -18,6 +18,7 @@ package regopolicy
 import (
 	"context"
 	"errors"
+	"os"
 	"reflect"
 	"testing"
 
@@ -25,6 +26,8 @@ import (
 	"github.com/deislabs/ratify/pkg/executor/types"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/policyprovider/config"
+	vt "github.com/deislabs/ratify/pkg/verifier/types"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -86,6 +89,34 @@ func TestCreate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "config with invalid enforcement mode",
+			config: map[string]interface{}{
+				"name":            "test",
+				"policy":          policy1,
+				"enforcementMode": "warn",
+			},
+			expectErr: true,
+		},
+		{
+			name: "audit mode with passthrough enabled",
+			config: map[string]interface{}{
+				"name":               "test",
+				"policy":             policy1,
+				"passthroughEnabled": true,
+				"enforcementMode":    "audit",
+			},
+			expectErr: true,
+		},
+		{
+			name: "config with audit mode",
+			config: map[string]interface{}{
+				"name":            "test",
+				"policy":          policy1,
+				"enforcementMode": "audit",
+			},
+			expectErr: false,
+		},
 		{
 			name: "config with valid policy",
 			config: map[string]interface{}{
@@ -187,6 +218,63 @@ func TestOverallVerifyResult(t *testing.T) {
 	}
 }
 
+func TestOverallVerifyResult_SamplePolicy(t *testing.T) {
+	body, err := os.ReadFile("../../../config/samples/policy/config_v1beta1_policy_rego_signature_and_sbom_or_provenance.yaml")
+	if err != nil {
+		t.Fatalf("failed to read sample policy: %v", err)
+	}
+	var sample struct {
+		Spec struct {
+			Parameters config.PolicyPluginConfig `yaml:"parameters"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(body, &sample); err != nil {
+		t.Fatalf("failed to unmarshal sample policy: %v", err)
+	}
+	enforcer, err := (&Factory{}).Create(sample.Spec.Parameters)
+	if err != nil {
+		t.Fatalf("failed to create policy enforcer: %v", err)
+	}
+
+	report := func(artifactType string, isSuccess bool) types.NestedVerifierReport {
+		return types.NestedVerifierReport{
+			ArtifactType:    artifactType,
+			VerifierReports: []vt.VerifierResult{{IsSuccess: isSuccess}},
+		}
+	}
+	testCases := []struct {
+		name         string
+		reports      []interface{}
+		expectResult bool
+	}{
+		{
+			name:         "signature and SBOM",
+			reports:      []interface{}{report("application/vnd.cncf.notary.signature", true), report("application/spdx+json", true)},
+			expectResult: true,
+		},
+		{
+			name:         "signature, invalid SBOM and provenance",
+			reports:      []interface{}{report("application/vnd.cncf.notary.signature", true), report("application/spdx+json", false), report("application/vnd.in-toto+json", true)},
+			expectResult: true,
+		},
+		{
+			name:    "signature only",
+			reports: []interface{}{report("application/vnd.cncf.notary.signature", true)},
+		},
+		{
+			name:    "invalid signature and SBOM",
+			reports: []interface{}{report("application/vnd.cncf.notary.signature", false), report("application/spdx+json", true)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := enforcer.OverallVerifyResult(context.Background(), tc.reports); result != tc.expectResult {
+				t.Fatalf("result = %v, expectResult = %v", result, tc.expectResult)
+			}
+		})
+	}
+}
+
 func TestGetPolicyType(t *testing.T) {
 	enforcer := policyEnforcer{}
 	if policyType := enforcer.GetPolicyType(context.Background()); policyType != "regopolicy" {
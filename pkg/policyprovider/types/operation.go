@@ -0,0 +1,41 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+	"strings"
+)
+
+type contextKey string
+
+const contextKeyOperation contextKey = "admissionOperation"
+
+// WithOperation returns a context carrying the admission operation, e.g. CREATE
+// or UPDATE, that triggered the verification.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	if operation == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyOperation, strings.ToUpper(operation))
+}
+
+// OperationFromContext returns the admission operation in upper case, or an
+// empty string if the verification was not triggered by an admission request.
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(contextKeyOperation).(string)
+	return operation
+}
@@ -0,0 +1,36 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "context"
+
+const contextKeyRepository contextKey = "subjectRepository"
+
+// WithRepository returns a context carrying the repository of the subject being
+// verified, e.g. myregistry.io/team/app.
+func WithRepository(ctx context.Context, repository string) context.Context {
+	if repository == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyRepository, repository)
+}
+
+// RepositoryFromContext returns the repository of the subject being verified,
+// or an empty string if it is unknown.
+func RepositoryFromContext(ctx context.Context) string {
+	repository, _ := ctx.Value(contextKeyRepository).(string)
+	return repository
+}
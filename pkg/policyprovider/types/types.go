@@ -18,6 +18,91 @@ package types
 // ArtifactTypeVerifyPolicy represents an artifact type policy
 type ArtifactTypeVerifyPolicy string
 
+// SignerPolicy requires a minimum number of successfully verified signatures
+// produced by distinct signer identities for an artifact type.
+type SignerPolicy struct {
+	// MinimumSigners is the number of distinct signer identities required.
+	MinimumSigners int `json:"minimumSigners"`
+	// IdentityAttribute is the attribute signers are told apart by: id (the
+	// default), issuer or key.
+	IdentityAttribute string `json:"identityAttribute,omitempty"`
+}
+
+// ThresholdPolicy requires a minimum number of verifiers to succeed for an
+// artifact of an artifact type, e.g. 2 of 3 verifiers, instead of any or all of
+// them.
+type ThresholdPolicy struct {
+	// MinimumVerifiers is the number of distinct verifiers that must succeed
+	// for the same artifact.
+	MinimumVerifiers int `json:"minimumVerifiers"`
+}
+
+// RequiredArtifactTypesPolicy requires artifacts of the artifact types to be
+// attached to the subjects of matching repositories and verified, subjects
+// without referrers of one of the artifact types are denied.
+type RequiredArtifactTypesPolicy struct {
+	// Repositories are patterns of the subject repository, e.g.
+	// myregistry.io/team/*. The policy applies to all subjects if empty.
+	Repositories []string `json:"repositories,omitempty"`
+	// ArtifactTypes are the artifact types required for the subjects.
+	ArtifactTypes []string `json:"artifactTypes"`
+}
+
+// NestedVerificationPolicy requires the artifacts attached to the referrers of
+// an artifact type, e.g. the signatures of an SBOM, to be verified.
+type NestedVerificationPolicy struct {
+	// ArtifactTypes are the artifact types that must be attached to the
+	// referrer and verified successfully.
+	ArtifactTypes []string `json:"artifactTypes"`
+}
+
+// OperationPolicy overrides the policy for a specific admission operation such
+// as CREATE or UPDATE.
+type OperationPolicy struct {
+	// ArtifactVerificationPolicies override the artifact type policies of the
+	// same name, artifact types that are not listed keep their policy.
+	ArtifactVerificationPolicies map[string]ArtifactTypeVerifyPolicy `json:"artifactVerificationPolicies,omitempty"`
+	// AuditOnly reports verification failures without failing the overall result.
+	AuditOnly bool `json:"auditOnly,omitempty"`
+}
+
+// RepositoryPolicy overrides the policy for subjects of matching repositories,
+// e.g. to enforce stricter rules for production registries.
+type RepositoryPolicy struct {
+	// Repositories are patterns of the subject repository, e.g.
+	// prod.registry.io/*.
+	Repositories []string `json:"repositories"`
+	// ArtifactVerificationPolicies override the artifact type policies of the
+	// same name, artifact types that are not listed keep their policy.
+	ArtifactVerificationPolicies map[string]ArtifactTypeVerifyPolicy `json:"artifactVerificationPolicies,omitempty"`
+	// AuditOnly reports verification failures without failing the overall result.
+	AuditOnly bool `json:"auditOnly,omitempty"`
+}
+
+// InconclusivePolicy determines how the results of verifiers that could not run
+// count towards the overall result.
+type InconclusivePolicy string
+
+const (
+	// InconclusiveFail counts inconclusive results as failures.
+	InconclusiveFail InconclusivePolicy = "fail"
+	// InconclusiveWarn counts inconclusive results as successes and logs a warning.
+	InconclusiveWarn InconclusivePolicy = "warn"
+	// InconclusiveIgnore leaves inconclusive results out of the overall result.
+	InconclusiveIgnore InconclusivePolicy = "ignore"
+)
+
+// EnforcementMode determines whether failed verifications deny the subject.
+type EnforcementMode string
+
+const (
+	// EnforcementModeEnforce denies subjects failing verification.
+	EnforcementModeEnforce EnforcementMode = "enforce"
+	// EnforcementModeAudit allows subjects failing verification and reports
+	// the failure as a warning, e.g. while rolling out Ratify.
+	EnforcementModeAudit EnforcementMode = "audit"
+)
+
 const (
 	AnyVerifySuccess ArtifactTypeVerifyPolicy = "any"
 	AllVerifySuccess ArtifactTypeVerifyPolicy = "all"
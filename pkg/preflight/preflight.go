@@ -0,0 +1,184 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	e "github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/utils"
+)
+
+// Exit codes of the preflight run, if several checks fail the code of the
+// earliest stage is returned.
+const (
+	// ExitCodeSuccess is returned if all checks passed.
+	ExitCodeSuccess = 0
+	// ExitCodeConfigInvalid is returned if the configuration could not be
+	// loaded or a component could not be created from it.
+	ExitCodeConfigInvalid = 2
+	// ExitCodeStoreUnreachable is returned if a referrer store could not
+	// resolve the canary image.
+	ExitCodeStoreUnreachable = 3
+	// ExitCodeKeyProviderFailure is returned if a key provider could not fetch
+	// its keys or certificates.
+	ExitCodeKeyProviderFailure = 4
+	// ExitCodeCanaryFailure is returned if the canary image did not pass the
+	// configured policy.
+	ExitCodeCanaryFailure = 5
+
+	// DefaultTimeout bounds each probe of the preflight run.
+	DefaultTimeout = time.Minute
+)
+
+// Kinds of the checks in the preflight report.
+const (
+	KindConfig      = "config"
+	KindStore       = "store"
+	KindKeyProvider = "keyProvider"
+	KindCanary      = "canary"
+)
+
+// Options configures the probes of the preflight run.
+type Options struct {
+	// CanaryImage is the reference of an image that must pass the configured
+	// policy, stores are probed by resolving it. Probes are skipped if empty.
+	CanaryImage string
+	// Timeout bounds each probe, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// KeyProvider is a named probe fetching the keys or certificates of a key
+// provider.
+type KeyProvider struct {
+	Name  string
+	Fetch func(ctx context.Context) error
+}
+
+// Check is the result of a preflight check.
+type Check struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report describes the result of a preflight run.
+type Report struct {
+	Success  bool    `json:"success"`
+	ExitCode int     `json:"exitCode"`
+	Checks   []Check `json:"checks"`
+}
+
+// NewReport returns a successful report without checks.
+func NewReport() *Report {
+	return &Report{Success: true, Checks: []Check{}}
+}
+
+// Pass records a successful check.
+func (r *Report) Pass(kind, name, message string) {
+	r.Checks = append(r.Checks, Check{Kind: kind, Name: name, Success: true, Message: message})
+}
+
+// Skip records a check that was not run.
+func (r *Report) Skip(kind, name, message string) {
+	r.Checks = append(r.Checks, Check{Kind: kind, Name: name, Success: true, Skipped: true, Message: message})
+}
+
+// Fail records a failed check and sets the exit code of its kind unless a
+// check of an earlier stage failed.
+func (r *Report) Fail(kind, name string, err error) {
+	r.Checks = append(r.Checks, Check{Kind: kind, Name: name, Message: err.Error()})
+	r.Success = false
+	if code := exitCode(kind); r.ExitCode == ExitCodeSuccess || code < r.ExitCode {
+		r.ExitCode = code
+	}
+}
+
+func exitCode(kind string) int {
+	switch kind {
+	case KindStore:
+		return ExitCodeStoreUnreachable
+	case KindKeyProvider:
+		return ExitCodeKeyProviderFailure
+	case KindCanary:
+		return ExitCodeCanaryFailure
+	default:
+		return ExitCodeConfigInvalid
+	}
+}
+
+// Run probes the key providers and the referrer stores of the executor, and
+// verifies the canary image with the executor. The components of the executor
+// must be created by the caller, failures to create them are recorded with
+// Fail before.
+func Run(ctx context.Context, report *Report, executor *core.Executor, keyProviders []KeyProvider, opts Options) *Report {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	for _, provider := range keyProviders {
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		if err := provider.Fetch(probeCtx); err != nil {
+			report.Fail(KindKeyProvider, provider.Name, err)
+		} else {
+			report.Pass(KindKeyProvider, provider.Name, "keys fetched")
+		}
+		cancel()
+	}
+
+	if opts.CanaryImage == "" {
+		for _, store := range executor.ReferrerStores {
+			report.Skip(KindStore, store.Name(), "no canary image is configured")
+		}
+		report.Skip(KindCanary, "", "no canary image is configured")
+		return report
+	}
+	subjectReference, err := utils.ParseSubjectReference(opts.CanaryImage)
+	if err != nil {
+		report.Fail(KindConfig, "canaryImage", err)
+		return report
+	}
+
+	for _, store := range executor.ReferrerStores {
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		if _, err := store.GetSubjectDescriptor(probeCtx, subjectReference); err != nil {
+			report.Fail(KindStore, store.Name(), fmt.Errorf("failed to resolve canary image %s: %w", opts.CanaryImage, err))
+		} else {
+			report.Pass(KindStore, store.Name(), fmt.Sprintf("resolved canary image %s", opts.CanaryImage))
+		}
+		cancel()
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := executor.VerifySubject(probeCtx, e.VerifyParameters{Subject: opts.CanaryImage})
+	switch {
+	case err != nil:
+		report.Fail(KindCanary, opts.CanaryImage, fmt.Errorf("failed to verify canary image: %w", err))
+	case !result.IsSuccess:
+		report.Fail(KindCanary, opts.CanaryImage, errors.New("canary image did not pass the configured policy"))
+	default:
+		report.Pass(KindCanary, opts.CanaryImage, "canary image passed the configured policy")
+	}
+	return report
+}
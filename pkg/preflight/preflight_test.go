@@ -0,0 +1,139 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	testImage        = "ghcr.io/deislabs/ratify:v1"
+	testArtifactType = "application/vnd.cncf.notary.signature"
+)
+
+func testExecutor(verified bool) *core.Executor {
+	return &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				"default": types.AllVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("ratify")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return verified
+			},
+		}},
+	}
+}
+
+func TestRun(t *testing.T) {
+	failingKeyProvider := KeyProvider{Name: "akv", Fetch: func(_ context.Context) error {
+		return errors.New("forbidden")
+	}}
+	testCases := []struct {
+		name           string
+		verified       bool
+		canaryImage    string
+		keyProviders   []KeyProvider
+		configFailure  bool
+		expectExitCode int
+		expectSkipped  int
+	}{
+		{
+			name:           "all checks passed",
+			verified:       true,
+			canaryImage:    testImage,
+			expectExitCode: ExitCodeSuccess,
+		},
+		{
+			name:           "no canary image",
+			expectExitCode: ExitCodeSuccess,
+			expectSkipped:  2,
+		},
+		{
+			name:           "invalid canary image",
+			canaryImage:    "INVALID@@",
+			expectExitCode: ExitCodeConfigInvalid,
+		},
+		{
+			name:           "store cannot resolve canary image",
+			verified:       true,
+			canaryImage:    "ghcr.io/deislabs/ratify:unknown",
+			expectExitCode: ExitCodeStoreUnreachable,
+		},
+		{
+			name:           "key provider failure",
+			verified:       true,
+			canaryImage:    testImage,
+			keyProviders:   []KeyProvider{failingKeyProvider},
+			expectExitCode: ExitCodeKeyProviderFailure,
+		},
+		{
+			name:           "canary image failed verification",
+			canaryImage:    testImage,
+			expectExitCode: ExitCodeCanaryFailure,
+		},
+		{
+			name:           "earliest stage decides the exit code",
+			canaryImage:    testImage,
+			keyProviders:   []KeyProvider{failingKeyProvider},
+			configFailure:  true,
+			expectExitCode: ExitCodeConfigInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := NewReport()
+			if tc.configFailure {
+				report.Fail(KindConfig, "verifier/notation", errors.New("invalid verifier"))
+			}
+			report = Run(context.Background(), report, testExecutor(tc.verified), tc.keyProviders, Options{CanaryImage: tc.canaryImage})
+			if report.ExitCode != tc.expectExitCode {
+				t.Fatalf("expected exit code %d, got %d: %+v", tc.expectExitCode, report.ExitCode, report.Checks)
+			}
+			if report.Success != (tc.expectExitCode == ExitCodeSuccess) {
+				t.Fatalf("expected success %t, got %t", tc.expectExitCode == ExitCodeSuccess, report.Success)
+			}
+			skipped := 0
+			for _, check := range report.Checks {
+				if check.Skipped {
+					skipped++
+				}
+			}
+			if skipped != tc.expectSkipped {
+				t.Fatalf("expected %d skipped checks, got %d", tc.expectSkipped, skipped)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore/config"
 	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // ListReferrersResult represents the result of ListReferrers API
@@ -52,3 +53,19 @@ type ReferrerStore interface {
 	// GetSubjectDescriptor returns the descriptor for the given subject.
 	GetSubjectDescriptor(ctx context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error)
 }
+
+// ConnectivityReporter is implemented by referrer stores reporting problems
+// connecting to registries.
+type ConnectivityReporter interface {
+	// RegistryConnectivity returns whether the store sent a request to a
+	// registry, and the error if the last request failed to connect.
+	RegistryConnectivity() (bool, error)
+}
+
+// ManifestFetcher is implemented by referrer stores fetching the raw content
+// of manifests, e.g. of the image index referenced by a subject.
+type ManifestFetcher interface {
+	// GetManifestContent returns the content of the manifest described by the
+	// descriptor.
+	GetManifestContent(ctx context.Context, subjectReference common.Reference, desc oci.Descriptor) ([]byte, error)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package blobprovider decouples the blob content read by verifiers from the
+// storage referrer stores cache it in.
+package blobprovider
+
+import (
+	"context"
+	"fmt"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/opencontainers/go-digest"
+)
+
+var logOpt = logger.Option{ComponentType: logger.ReferrerStore}
+
+// BlobProvider stores the content of blobs by digest. Referrer stores read
+// blobs through a provider so that the content returned by GetBlobContent is
+// cached and verified the same way for all backends.
+type BlobProvider interface {
+	// Get returns the content of the blob, ok is false if it is not stored.
+	Get(ctx context.Context, blobDigest digest.Digest) (content []byte, ok bool, err error)
+
+	// Put stores the content of the blob.
+	Put(ctx context.Context, blobDigest digest.Digest, content []byte) error
+}
+
+// Fetcher fetches the content of a blob from its source, e.g. a registry.
+type Fetcher func(ctx context.Context) ([]byte, error)
+
+// ReadThrough returns the content of the blob stored by the provider, or
+// fetches and stores it. The content must match the digest, failures of the
+// provider are logged and the blob is fetched from its source instead.
+func ReadThrough(ctx context.Context, provider BlobProvider, blobDigest digest.Digest, fetch Fetcher) ([]byte, error) {
+	if err := blobDigest.Validate(); err != nil {
+		return nil, re.ErrorCodeGetBlobContentFailure.WithError(err).WithComponentType(re.ReferrerStore)
+	}
+
+	content, ok, err := provider.Get(ctx, blobDigest)
+	if err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to read blob %s from blob provider: %v", blobDigest, err)
+	}
+	if ok && err == nil {
+		if verifyContent(blobDigest, content) == nil {
+			metrics.ReportBlobCacheCount(ctx, true)
+			return content, nil
+		}
+		logger.GetLogger(ctx, logOpt).Warnf("blob %s stored by blob provider does not match its digest, fetching it again", blobDigest)
+	}
+	metrics.ReportBlobCacheCount(ctx, false)
+
+	content, err = fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyContent(blobDigest, content); err != nil {
+		return nil, re.ErrorCodeGetBlobContentFailure.WithError(err).WithComponentType(re.ReferrerStore)
+	}
+	if err = provider.Put(ctx, blobDigest, content); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to store blob %s in blob provider: %v", blobDigest, err)
+	}
+	return content, nil
+}
+
+func verifyContent(blobDigest digest.Digest, content []byte) error {
+	if actual := blobDigest.Algorithm().FromBytes(content); actual != blobDigest {
+		return fmt.Errorf("content of blob %s does not match its digest, got %s", blobDigest, actual)
+	}
+	return nil
+}
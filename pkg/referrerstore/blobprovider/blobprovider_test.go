@@ -0,0 +1,139 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+type testCache struct {
+	values map[string]string
+}
+
+func (c *testCache) Get(_ context.Context, key string) (string, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *testCache) Set(ctx context.Context, key string, value interface{}) bool {
+	return c.SetWithTTL(ctx, key, value, 0)
+}
+
+func (c *testCache) SetWithTTL(_ context.Context, key string, value interface{}, _ time.Duration) bool {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	c.values[key] = string(bytes)
+	return true
+}
+
+func (c *testCache) Delete(_ context.Context, key string) bool {
+	delete(c.values, key)
+	return true
+}
+
+func TestReadThrough(t *testing.T) {
+	content := []byte("test content")
+	blobDigest := digest.FromBytes(content)
+	providers := map[string]BlobProvider{
+		"memory": NewMemoryProvider(0),
+		"cache":  NewCacheProvider(&testCache{values: map[string]string{}}, time.Minute),
+	}
+	for name, provider := range providers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			fetches := 0
+			fetch := func(context.Context) ([]byte, error) {
+				fetches++
+				return content, nil
+			}
+			for i := 0; i < 2; i++ {
+				blob, err := ReadThrough(ctx, provider, blobDigest, fetch)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !bytes.Equal(blob, content) {
+					t.Fatalf("expected content %s, got %s", content, blob)
+				}
+			}
+			if fetches != 1 {
+				t.Fatalf("expected blob to be fetched once, got %d fetches", fetches)
+			}
+		})
+	}
+}
+
+func TestReadThrough_DigestMismatch(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("test content")
+	blobDigest := digest.FromBytes(content)
+	provider := NewMemoryProvider(0)
+
+	if _, err := ReadThrough(ctx, provider, blobDigest, func(context.Context) ([]byte, error) {
+		return []byte("other content"), nil
+	}); err == nil {
+		t.Fatalf("expected error for content not matching the digest")
+	}
+	if _, ok, _ := provider.Get(ctx, blobDigest); ok {
+		t.Fatalf("expected content not matching the digest not to be stored")
+	}
+
+	// corrupted content of the provider is fetched again
+	if err := provider.Put(ctx, blobDigest, []byte("other content")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blob, err := ReadThrough(ctx, provider, blobDigest, func(context.Context) ([]byte, error) {
+		return content, nil
+	})
+	if err != nil || !bytes.Equal(blob, content) {
+		t.Fatalf("expected content to be fetched again, got %s, %v", blob, err)
+	}
+}
+
+func TestReadThrough_FetchFailure(t *testing.T) {
+	content := []byte("test content")
+	if _, err := ReadThrough(context.Background(), NewMemoryProvider(0), digest.FromBytes(content), func(context.Context) ([]byte, error) {
+		return nil, fmt.Errorf("registry unavailable")
+	}); err == nil {
+		t.Fatalf("expected fetch error")
+	}
+}
+
+func TestMemoryProvider_Eviction(t *testing.T) {
+	ctx := context.Background()
+	provider := NewMemoryProvider(10)
+	first, second := []byte("123456"), []byte("abcdef")
+	if err := provider.Put(ctx, digest.FromBytes(first), first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := provider.Put(ctx, digest.FromBytes(second), second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := provider.Get(ctx, digest.FromBytes(first)); ok {
+		t.Fatalf("expected oldest blob to be evicted")
+	}
+	if _, ok, _ := provider.Get(ctx, digest.FromBytes(second)); !ok {
+		t.Fatalf("expected newest blob to be stored")
+	}
+}
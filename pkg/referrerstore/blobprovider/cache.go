@@ -0,0 +1,57 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/cache"
+	"github.com/opencontainers/go-digest"
+)
+
+type cacheProvider struct {
+	cache cache.CacheProvider
+	ttl   time.Duration
+}
+
+// NewCacheProvider returns a provider storing blobs in a cache, e.g. the dapr
+// cache shared by the replicas, for the ttl.
+func NewCacheProvider(provider cache.CacheProvider, ttl time.Duration) BlobProvider {
+	return &cacheProvider{cache: provider, ttl: ttl}
+}
+
+func (p *cacheProvider) Get(ctx context.Context, blobDigest digest.Digest) ([]byte, bool, error) {
+	value, found := p.cache.Get(ctx, fmt.Sprintf(cache.CacheKeyBlob, blobDigest))
+	if !found || value == "" {
+		return nil, false, nil
+	}
+	// the cache marshals values to json, blobs are stored base64 encoded
+	var blob []byte
+	if err := json.Unmarshal([]byte(value), &blob); err != nil {
+		return nil, false, err
+	}
+	return blob, true, nil
+}
+
+func (p *cacheProvider) Put(ctx context.Context, blobDigest digest.Digest, blob []byte) error {
+	if !p.cache.SetWithTTL(ctx, fmt.Sprintf(cache.CacheKeyBlob, blobDigest), blob, p.ttl) {
+		return fmt.Errorf("failed to add blob %s to cache", blobDigest)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobprovider
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// DefaultMemoryMaxBytes is the default size of the blobs kept in memory.
+const DefaultMemoryMaxBytes int64 = 64 * 1024 * 1024
+
+type memoryProvider struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	blobs    map[digest.Digest][]byte
+	// order is the order blobs were stored in, the oldest blobs are evicted
+	// first once maxBytes is exceeded
+	order []digest.Digest
+}
+
+// NewMemoryProvider returns a provider keeping up to maxBytes of blobs in
+// memory, the size is unlimited if maxBytes is 0.
+func NewMemoryProvider(maxBytes int64) BlobProvider {
+	return &memoryProvider{
+		maxBytes: maxBytes,
+		blobs:    map[digest.Digest][]byte{},
+	}
+}
+
+func (p *memoryProvider) Get(_ context.Context, blobDigest digest.Digest) ([]byte, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	content, ok := p.blobs[blobDigest]
+	return content, ok, nil
+}
+
+func (p *memoryProvider) Put(_ context.Context, blobDigest digest.Digest, content []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.blobs[blobDigest]; ok {
+		return nil
+	}
+	// blobs larger than the provider are not stored
+	if p.maxBytes > 0 && int64(len(content)) > p.maxBytes {
+		return nil
+	}
+	for p.maxBytes > 0 && p.size+int64(len(content)) > p.maxBytes {
+		oldest := p.order[0]
+		p.order = p.order[1:]
+		p.size -= int64(len(p.blobs[oldest]))
+		delete(p.blobs, oldest)
+	}
+	p.blobs[blobDigest] = content
+	p.order = append(p.order, blobDigest)
+	p.size += int64(len(content))
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const blobMediaType = "application/octet-stream"
+
+type storageProvider struct {
+	storage content.Storage
+}
+
+// NewStorageProvider returns a provider storing blobs in an ORAS content
+// storage, e.g. the OCI layout on disk used as local cache by the ORAS store.
+func NewStorageProvider(storage content.Storage) BlobProvider {
+	return &storageProvider{storage: storage}
+}
+
+func (p *storageProvider) Get(ctx context.Context, blobDigest digest.Digest) ([]byte, bool, error) {
+	desc := oci.Descriptor{Digest: blobDigest}
+	exists, err := p.storage.Exists(ctx, desc)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	reader, err := p.storage.Fetch(ctx, desc)
+	if err != nil {
+		return nil, false, err
+	}
+	defer reader.Close()
+	blob := new(bytes.Buffer)
+	if _, err = blob.ReadFrom(reader); err != nil {
+		return nil, false, err
+	}
+	return blob.Bytes(), true, nil
+}
+
+func (p *storageProvider) Put(ctx context.Context, blobDigest digest.Digest, blob []byte) error {
+	desc := oci.Descriptor{
+		MediaType: blobMediaType,
+		Digest:    blobDigest,
+		Size:      int64(len(blob)),
+	}
+	if err := p.storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
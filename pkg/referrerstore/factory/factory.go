@@ -16,6 +16,7 @@ limitations under the License.
 package factory
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -24,6 +25,7 @@ import (
 	re "github.com/deislabs/ratify/errors"
 	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
 	"github.com/deislabs/ratify/pkg/featureflag"
+	"github.com/deislabs/ratify/pkg/pluginmanager"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	"github.com/deislabs/ratify/pkg/referrerstore/config"
 	"github.com/deislabs/ratify/pkg/referrerstore/plugin"
@@ -70,11 +72,15 @@ func CreateStoreFromConfig(storeConfig config.StorePluginConfig, configVersion s
 			}
 
 			targetPath := path.Join(pluginBinDir[0], storeNameStr)
-			err = pluginCommon.DownloadPlugin(source, targetPath)
+			downloaded, err := pluginmanager.Install(context.TODO(), source, targetPath)
 			if err != nil {
-				return nil, re.ErrorCodeDownloadPluginFailure.WithComponentType(re.ReferrerStore)
+				return nil, re.ErrorCodeDownloadPluginFailure.WithComponentType(re.ReferrerStore).WithError(err)
+			}
+			if downloaded {
+				logrus.Infof("downloaded store plugin %s from %s to %s", storeNameStr, source.Artifact, targetPath)
+			} else {
+				logrus.Infof("store plugin %s from %s is up to date at %s", storeNameStr, source.Artifact, targetPath)
 			}
-			logrus.Infof("downloaded store plugin %s from %s to %s", storeNameStr, source.Artifact, targetPath)
 		} else {
 			logrus.Warnf("%s was specified for store plugin %s, but dynamic plugins are currently disabled", types.Source, storeNameStr)
 		}
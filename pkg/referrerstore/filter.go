@@ -0,0 +1,145 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package referrerstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+)
+
+// ReferrerFilter selects the referrers returned by ListReferrers.
+type ReferrerFilter struct {
+	// ArtifactTypes are the artifact types of the referrers, all artifact types
+	// match if it is empty or contains * or an empty artifact type.
+	ArtifactTypes []string
+	// Annotations the referrers must have, an empty value matches any value of
+	// the annotation.
+	Annotations map[string]string
+}
+
+// FilteringReferrerStore is implemented by referrer stores applying a filter
+// while listing referrers, e.g. by pushing it down to registries supporting
+// the filtering parameters of the Referrers API.
+type FilteringReferrerStore interface {
+	// ListFilteredReferrers returns the immediate set of supply chain objects
+	// for the given subject matching the filter.
+	ListFilteredReferrers(ctx context.Context, subjectReference common.Reference, filter ReferrerFilter, nextToken string, subjectDesc *ocispecs.SubjectDescriptor) (ListReferrersResult, error)
+}
+
+// ListFilteredReferrers returns the referrers of the subject matching the
+// filter. The filter is passed to stores implementing FilteringReferrerStore
+// and applied to the referrers returned by other stores.
+func ListFilteredReferrers(ctx context.Context, store ReferrerStore, subjectReference common.Reference, filter ReferrerFilter, nextToken string, subjectDesc *ocispecs.SubjectDescriptor) (ListReferrersResult, error) {
+	if filteringStore, ok := store.(FilteringReferrerStore); ok {
+		return filteringStore.ListFilteredReferrers(ctx, subjectReference, filter, nextToken, subjectDesc)
+	}
+	result, err := store.ListReferrers(ctx, subjectReference, filter.ArtifactTypes, nextToken, subjectDesc)
+	if err != nil {
+		return result, err
+	}
+	result.Referrers = filter.Apply(result.Referrers)
+	return result, nil
+}
+
+// IsEmpty returns true if all referrers match the filter.
+func (f ReferrerFilter) IsEmpty() bool {
+	return f.MatchesAllArtifactTypes() && len(f.Annotations) == 0
+}
+
+// MatchesAllArtifactTypes returns true if the filter does not restrict the
+// artifact types.
+func (f ReferrerFilter) MatchesAllArtifactTypes() bool {
+	if len(f.ArtifactTypes) == 0 {
+		return true
+	}
+	for _, artifactType := range f.ArtifactTypes {
+		if artifactType == "" || artifactType == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesArtifactType returns true if referrers of the artifact type may match
+// the filter.
+func (f ReferrerFilter) MatchesArtifactType(artifactType string) bool {
+	if f.MatchesAllArtifactTypes() {
+		return true
+	}
+	for _, filterType := range f.ArtifactTypes {
+		if filterType == artifactType {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the referrer has one of the artifact types and all
+// annotations of the filter.
+func (f ReferrerFilter) Matches(referrer ocispecs.ReferenceDescriptor) bool {
+	if !f.MatchesArtifactType(referrer.ArtifactType) {
+		return false
+	}
+	for key, value := range f.Annotations {
+		annotation, ok := referrer.Annotations[key]
+		if !ok || (value != "" && annotation != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the referrers matching the filter.
+func (f ReferrerFilter) Apply(referrers []ocispecs.ReferenceDescriptor) []ocispecs.ReferenceDescriptor {
+	if f.IsEmpty() {
+		return referrers
+	}
+	filtered := make([]ocispecs.ReferenceDescriptor, 0, len(referrers))
+	for _, referrer := range referrers {
+		if f.Matches(referrer) {
+			filtered = append(filtered, referrer)
+		}
+	}
+	return filtered
+}
+
+// String returns the canonical form of the filter, e.g. to key cached results,
+// an empty string if all referrers match.
+func (f ReferrerFilter) String() string {
+	if f.IsEmpty() {
+		return ""
+	}
+	var parts []string
+	if !f.MatchesAllArtifactTypes() {
+		artifactTypes := append([]string{}, f.ArtifactTypes...)
+		sort.Strings(artifactTypes)
+		parts = append(parts, "artifactType="+strings.Join(artifactTypes, ","))
+	}
+	keys := make([]string, 0, len(f.Annotations))
+	for key := range f.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("annotation=%s=%s", key, f.Annotations[key]))
+	}
+	return strings.Join(parts, "&")
+}
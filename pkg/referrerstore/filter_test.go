@@ -0,0 +1,144 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package referrerstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore/config"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	signatureType = "application/vnd.cncf.notary.signature"
+	sbomType      = "application/spdx+json"
+)
+
+var (
+	signature = ocispecs.ReferenceDescriptor{
+		Descriptor:   oci.Descriptor{Digest: digest.FromString("signature"), Annotations: map[string]string{"team": "a", "env": "prod"}},
+		ArtifactType: signatureType,
+	}
+	sbom = ocispecs.ReferenceDescriptor{
+		Descriptor:   oci.Descriptor{Digest: digest.FromString("sbom"), Annotations: map[string]string{"team": "b"}},
+		ArtifactType: sbomType,
+	}
+)
+
+type testStore struct {
+	referrers []ocispecs.ReferenceDescriptor
+}
+
+func (s *testStore) Name() string {
+	return "test"
+}
+
+func (s *testStore) ListReferrers(_ context.Context, _ common.Reference, _ []string, _ string, _ *ocispecs.SubjectDescriptor) (ListReferrersResult, error) {
+	return ListReferrersResult{Referrers: s.referrers}, nil
+}
+
+func (s *testStore) GetBlobContent(_ context.Context, _ common.Reference, _ digest.Digest) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *testStore) GetReferenceManifest(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor) (ocispecs.ReferenceManifest, error) {
+	return ocispecs.ReferenceManifest{}, nil
+}
+
+func (s *testStore) GetConfig() *config.StoreConfig {
+	return &config.StoreConfig{}
+}
+
+func (s *testStore) GetSubjectDescriptor(_ context.Context, _ common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	return nil, nil
+}
+
+func TestReferrerFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   ReferrerFilter
+		expected []digest.Digest
+		key      string
+	}{
+		{
+			name:     "empty filter",
+			expected: []digest.Digest{signature.Digest, sbom.Digest},
+		},
+		{
+			name:     "wildcard artifact type",
+			filter:   ReferrerFilter{ArtifactTypes: []string{sbomType, "*"}},
+			expected: []digest.Digest{signature.Digest, sbom.Digest},
+		},
+		{
+			name:     "empty artifact type",
+			filter:   ReferrerFilter{ArtifactTypes: []string{""}},
+			expected: []digest.Digest{signature.Digest, sbom.Digest},
+		},
+		{
+			name:     "artifact type",
+			filter:   ReferrerFilter{ArtifactTypes: []string{sbomType}},
+			expected: []digest.Digest{sbom.Digest},
+			key:      "artifactType=" + sbomType,
+		},
+		{
+			name:     "annotation value",
+			filter:   ReferrerFilter{Annotations: map[string]string{"team": "a"}},
+			expected: []digest.Digest{signature.Digest},
+			key:      "annotation=team=a",
+		},
+		{
+			name:     "annotation with any value",
+			filter:   ReferrerFilter{Annotations: map[string]string{"team": ""}},
+			expected: []digest.Digest{signature.Digest, sbom.Digest},
+			key:      "annotation=team=",
+		},
+		{
+			name:     "all annotations must match",
+			filter:   ReferrerFilter{Annotations: map[string]string{"team": "b", "env": ""}},
+			expected: []digest.Digest{},
+			key:      "annotation=env=&annotation=team=b",
+		},
+		{
+			name:     "artifact types and annotations",
+			filter:   ReferrerFilter{ArtifactTypes: []string{signatureType, sbomType}, Annotations: map[string]string{"env": "prod"}},
+			expected: []digest.Digest{signature.Digest},
+			key:      "artifactType=" + sbomType + "," + signatureType + "&annotation=env=prod",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ListFilteredReferrers(context.Background(), &testStore{referrers: []ocispecs.ReferenceDescriptor{signature, sbom}}, common.Reference{}, tc.filter, "", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Referrers) != len(tc.expected) {
+				t.Fatalf("expected %d referrers, got %d", len(tc.expected), len(result.Referrers))
+			}
+			for i, expected := range tc.expected {
+				if result.Referrers[i].Digest != expected {
+					t.Fatalf("expected referrer %s at index %d, got %s", expected, i, result.Referrers[i].Digest)
+				}
+			}
+			if key := tc.filter.String(); key != tc.key {
+				t.Fatalf("expected filter key %q, got %q", tc.key, key)
+			}
+		})
+	}
+}
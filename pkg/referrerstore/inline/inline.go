@@ -0,0 +1,178 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inline provides a referrer store backed by content supplied by the
+// caller, so that a subject and its referrers can be verified before they are
+// pushed to a registry.
+package inline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common"
+	commonutils "github.com/deislabs/ratify/pkg/common/utils"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/config"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// StoreName is the name of the inline referrer store.
+	StoreName = "inline"
+	// DefaultRepository is the repository used to reference supplied content
+	// that does not have a name yet.
+	DefaultRepository = "localhost/inline"
+)
+
+// Content is the subject manifest and the referrers supplied by the caller.
+type Content struct {
+	// Subject is the raw manifest of the subject.
+	Subject Manifest `json:"subject"`
+	// Referrers are the artifacts referring to the subject or to other referrers.
+	// The subject of each referrer manifest determines what it refers to.
+	Referrers []Referrer `json:"referrers,omitempty"`
+}
+
+// Manifest is a raw manifest with its media type.
+type Manifest struct {
+	MediaType string `json:"mediaType"`
+	Manifest  []byte `json:"manifest"`
+}
+
+// SubjectReference returns a digest reference of the supplied subject in the
+// given repository, or in DefaultRepository if the repository is empty.
+func (c Content) SubjectReference(repository string) string {
+	if repository == "" {
+		repository = DefaultRepository
+	}
+	return fmt.Sprintf("%s@%s", repository, digest.FromBytes(c.Subject.Manifest))
+}
+
+// Referrer is a raw OCI image manifest of a referrer and the blobs it references.
+type Referrer struct {
+	Manifest []byte   `json:"manifest"`
+	Blobs    [][]byte `json:"blobs,omitempty"`
+}
+
+type inlineStore struct {
+	subject   *ocispecs.SubjectDescriptor
+	referrers map[digest.Digest][]ocispecs.ReferenceDescriptor
+	manifests map[digest.Digest]ocispecs.ReferenceManifest
+	blobs     map[digest.Digest][]byte
+}
+
+// NewStore creates a referrer store serving the supplied content. Digests are
+// computed from the raw bytes, so the content does not need to be trusted.
+func NewStore(content Content) (referrerstore.ReferrerStore, error) {
+	if len(content.Subject.Manifest) == 0 {
+		return nil, re.ErrorCodeManifestInvalid.NewError(re.ReferrerStore, StoreName, re.EmptyLink, nil, "subject manifest is required", re.HideStackTrace)
+	}
+	store := &inlineStore{
+		subject: &ocispecs.SubjectDescriptor{
+			Descriptor: oci.Descriptor{
+				MediaType: content.Subject.MediaType,
+				Digest:    digest.FromBytes(content.Subject.Manifest),
+				Size:      int64(len(content.Subject.Manifest)),
+			},
+		},
+		referrers: map[digest.Digest][]ocispecs.ReferenceDescriptor{},
+		manifests: map[digest.Digest]ocispecs.ReferenceManifest{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+
+	for i, referrer := range content.Referrers {
+		var manifest oci.Manifest
+		if err := json.Unmarshal(referrer.Manifest, &manifest); err != nil {
+			return nil, re.ErrorCodeManifestInvalid.NewError(re.ReferrerStore, StoreName, re.EmptyLink, err, fmt.Sprintf("failed to parse manifest of referrer %d", i), re.HideStackTrace)
+		}
+		if manifest.Subject == nil {
+			return nil, re.ErrorCodeManifestInvalid.NewError(re.ReferrerStore, StoreName, re.EmptyLink, nil, fmt.Sprintf("manifest of referrer %d has no subject", i), re.HideStackTrace)
+		}
+		referenceManifest := commonutils.OciManifestToReferenceManifest(manifest)
+		referenceDesc := ocispecs.ReferenceDescriptor{
+			Descriptor: oci.Descriptor{
+				MediaType:   manifest.MediaType,
+				Digest:      digest.FromBytes(referrer.Manifest),
+				Size:        int64(len(referrer.Manifest)),
+				Annotations: manifest.Annotations,
+			},
+			ArtifactType: referenceManifest.ArtifactType,
+		}
+		store.manifests[referenceDesc.Digest] = referenceManifest
+		store.referrers[manifest.Subject.Digest] = append(store.referrers[manifest.Subject.Digest], referenceDesc)
+		for _, blob := range referrer.Blobs {
+			store.blobs[digest.FromBytes(blob)] = blob
+		}
+	}
+	return store, nil
+}
+
+// Name returns the name of the store.
+func (s *inlineStore) Name() string {
+	return StoreName
+}
+
+// ListReferrers returns all supplied referrers of the subject in a single page.
+func (s *inlineStore) ListReferrers(_ context.Context, subjectReference common.Reference, _ []string, _ string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
+	subjectDigest := subjectReference.Digest
+	if subjectDesc != nil {
+		subjectDigest = subjectDesc.Digest
+	}
+	return referrerstore.ListReferrersResult{Referrers: s.referrers[subjectDigest]}, nil
+}
+
+// GetBlobContent returns a supplied blob.
+func (s *inlineStore) GetBlobContent(_ context.Context, _ common.Reference, blobDigest digest.Digest) ([]byte, error) {
+	if blob, ok := s.blobs[blobDigest]; ok {
+		return blob, nil
+	}
+	return nil, re.ErrorCodeGetBlobContentFailure.NewError(re.ReferrerStore, StoreName, re.EmptyLink, nil, fmt.Sprintf("blob %s was not supplied", blobDigest), re.HideStackTrace)
+}
+
+// GetReferenceManifest returns a supplied referrer manifest.
+func (s *inlineStore) GetReferenceManifest(_ context.Context, _ common.Reference, referenceDesc ocispecs.ReferenceDescriptor) (ocispecs.ReferenceManifest, error) {
+	if manifest, ok := s.manifests[referenceDesc.Digest]; ok {
+		return manifest, nil
+	}
+	return ocispecs.ReferenceManifest{}, re.ErrorCodeGetReferenceManifestFailure.NewError(re.ReferrerStore, StoreName, re.EmptyLink, nil, fmt.Sprintf("manifest %s was not supplied", referenceDesc.Digest), re.HideStackTrace)
+}
+
+// GetConfig returns the configuration of the store.
+func (s *inlineStore) GetConfig() *config.StoreConfig {
+	return &config.StoreConfig{}
+}
+
+// GetSubjectDescriptor returns the descriptor of the supplied subject, or of a
+// supplied referrer when verifying nested references. A reference without a
+// digest resolves to the supplied subject.
+func (s *inlineStore) GetSubjectDescriptor(_ context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	if subjectReference.Digest == "" || subjectReference.Digest == s.subject.Digest {
+		return s.subject, nil
+	}
+	if manifest, ok := s.manifests[subjectReference.Digest]; ok {
+		return &ocispecs.SubjectDescriptor{
+			Descriptor: oci.Descriptor{
+				MediaType: manifest.MediaType,
+				Digest:    subjectReference.Digest,
+			},
+		}, nil
+	}
+	return nil, re.ErrorCodeGetSubjectDescriptorFailure.NewError(re.ReferrerStore, StoreName, re.EmptyLink, nil, fmt.Sprintf("subject %s was not supplied", subjectReference.Digest), re.HideStackTrace)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testArtifactType = "application/vnd.cncf.notary.signature"
+
+var (
+	testSubjectManifest = []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	testBlob            = []byte("signature")
+)
+
+func createReferrer(t *testing.T, subject []byte, blob []byte) []byte {
+	t.Helper()
+	manifest := oci.Manifest{
+		MediaType:    oci.MediaTypeImageManifest,
+		ArtifactType: testArtifactType,
+		Config:       oci.DescriptorEmptyJSON,
+		Layers: []oci.Descriptor{{
+			MediaType: "application/jose+json",
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		}},
+		Subject: &oci.Descriptor{
+			MediaType: oci.MediaTypeImageManifest,
+			Digest:    digest.FromBytes(subject),
+			Size:      int64(len(subject)),
+		},
+	}
+	manifest.SchemaVersion = 2
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	return manifestBytes
+}
+
+func TestNewStore(t *testing.T) {
+	referrerManifest := createReferrer(t, testSubjectManifest, testBlob)
+	store, err := NewStore(Content{
+		Subject:   Manifest{MediaType: oci.MediaTypeImageManifest, Manifest: testSubjectManifest},
+		Referrers: []Referrer{{Manifest: referrerManifest, Blobs: [][]byte{testBlob}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	subjectDesc, err := store.GetSubjectDescriptor(ctx, common.Reference{})
+	if err != nil || subjectDesc.Digest != digest.FromBytes(testSubjectManifest) {
+		t.Fatalf("expected subject descriptor of supplied manifest, got %v, err: %v", subjectDesc, err)
+	}
+	if _, err := store.GetSubjectDescriptor(ctx, common.Reference{Digest: digest.FromString("other")}); err == nil {
+		t.Fatalf("expected error for subject that was not supplied")
+	}
+
+	result, err := store.ListReferrers(ctx, common.Reference{}, nil, "", subjectDesc)
+	if err != nil || len(result.Referrers) != 1 {
+		t.Fatalf("expected one referrer, got %v, err: %v", result.Referrers, err)
+	}
+	referrer := result.Referrers[0]
+	if referrer.ArtifactType != testArtifactType || referrer.Digest != digest.FromBytes(referrerManifest) {
+		t.Fatalf("unexpected referrer %v", referrer)
+	}
+
+	manifest, err := store.GetReferenceManifest(ctx, common.Reference{}, referrer)
+	if err != nil || len(manifest.Blobs) != 1 {
+		t.Fatalf("expected manifest with one blob, got %v, err: %v", manifest, err)
+	}
+	blob, err := store.GetBlobContent(ctx, common.Reference{}, manifest.Blobs[0].Digest)
+	if err != nil || string(blob) != string(testBlob) {
+		t.Fatalf("expected supplied blob, got %s, err: %v", blob, err)
+	}
+	if _, err := store.GetBlobContent(ctx, common.Reference{}, digest.FromString("other")); err == nil {
+		t.Fatalf("expected error for blob that was not supplied")
+	}
+	if _, err := store.GetReferenceManifest(ctx, common.Reference{}, ocispecs.ReferenceDescriptor{}); err == nil {
+		t.Fatalf("expected error for manifest that was not supplied")
+	}
+}
+
+func TestNewStore_InvalidContent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content Content
+	}{
+		{name: "missing subject", content: Content{}},
+		{
+			name: "invalid referrer manifest",
+			content: Content{
+				Subject:   Manifest{Manifest: testSubjectManifest},
+				Referrers: []Referrer{{Manifest: []byte("invalid")}},
+			},
+		},
+		{
+			name: "referrer without subject",
+			content: Content{
+				Subject:   Manifest{Manifest: testSubjectManifest},
+				Referrers: []Referrer{{Manifest: []byte(`{"schemaVersion":2}`)}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewStore(tc.content); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestSubjectReference(t *testing.T) {
+	content := Content{Subject: Manifest{Manifest: testSubjectManifest}}
+	expected := DefaultRepository + "@" + digest.FromBytes(testSubjectManifest).String()
+	if result := content.SubjectReference(""); result != expected {
+		t.Fatalf("expected %s, got %s", expected, result)
+	}
+	expected = "registry.io/app@" + digest.FromBytes(testSubjectManifest).String()
+	if result := content.SubjectReference("registry.io/app"); result != expected {
+		t.Fatalf("expected %s, got %s", expected, result)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 const defaultTTL = 10
@@ -51,9 +52,18 @@ func createCachedStore(storeBase referrerstore.ReferrerStore, cacheConf *cacheCo
 }
 
 func (store *orasStoreWithInMemoryCache) ListReferrers(ctx context.Context, subjectReference common.Reference, artifactTypes []string, nextToken string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
+	return store.ListFilteredReferrers(ctx, subjectReference, referrerstore.ReferrerFilter{ArtifactTypes: artifactTypes}, nextToken, subjectDesc)
+}
+
+// ListFilteredReferrers caches the referrers matching the filter, filtered
+// results are cached separately from the results of the other filters.
+func (store *orasStoreWithInMemoryCache) ListFilteredReferrers(ctx context.Context, subjectReference common.Reference, filter referrerstore.ReferrerFilter, nextToken string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
 	var err error
 	var result referrerstore.ListReferrersResult
 	cacheKey := fmt.Sprintf(cache.CacheKeyListReferrers, subjectReference.Original)
+	if filterKey := filter.String(); filterKey != "" {
+		cacheKey += "?" + filterKey
+	}
 	cacheProvider := cache.GetCacheProvider()
 	if cacheProvider == nil {
 		logger.GetLogger(ctx, logOpt).Warnf("failed to get cache provider")
@@ -69,7 +79,7 @@ func (store *orasStoreWithInMemoryCache) ListReferrers(ctx context.Context, subj
 		}
 	}
 	logger.GetLogger(ctx, logOpt).Debugf("list referrers cache miss for value: %s", subjectReference.Original)
-	result, err = store.ReferrerStore.ListReferrers(ctx, subjectReference, artifactTypes, nextToken, subjectDesc)
+	result, err = referrerstore.ListFilteredReferrers(ctx, store.ReferrerStore, subjectReference, filter, nextToken, subjectDesc)
 	if err == nil {
 		if cacheProvider != nil {
 			if added := cacheProvider.SetWithTTL(ctx, cacheKey, result, time.Duration(store.cacheConf.TTL)*time.Second); !added { // TODO: convert ttl to duration in helm values
@@ -81,6 +91,15 @@ func (store *orasStoreWithInMemoryCache) ListReferrers(ctx context.Context, subj
 	return result, err
 }
 
+// GetManifestContent returns the manifest content fetched by the decorated store.
+func (store *orasStoreWithInMemoryCache) GetManifestContent(ctx context.Context, subjectReference common.Reference, desc oci.Descriptor) ([]byte, error) {
+	fetcher, ok := store.ReferrerStore.(referrerstore.ManifestFetcher)
+	if !ok {
+		return nil, fmt.Errorf("store %s does not support fetching manifests", store.Name())
+	}
+	return fetcher.GetManifestContent(ctx, subjectReference, desc)
+}
+
 func (store *orasStoreWithInMemoryCache) GetSubjectDescriptor(ctx context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error) {
 	result := &ocispecs.SubjectDescriptor{}
 	var err error
@@ -268,6 +268,35 @@ func TestListReferrers_CacheMiss(t *testing.T) {
 	}
 }
 
+func TestListFilteredReferrers_CacheKeyedByFilter(t *testing.T) {
+	store, _ := createCachedStore(base, conf)
+	ctx := context.Background()
+	if cache.GetCacheProvider() == nil {
+		// if no cache provider has been initialized, initialize one
+		if _, err := cache.NewCacheProvider(ctx, cache.DefaultCacheType, cache.DefaultCacheName, cache.DefaultCacheSize); err != nil {
+			t.Errorf("Expected no error, but got %v", err)
+		}
+	}
+	reference := common.Reference{Original: "testRegistry/testRepo@sha256:filtered", Path: testReference.Path, Digest: testDigest}
+	filter := referrerstore.ReferrerFilter{ArtifactTypes: []string{"application/spdx+json"}}
+	filteringStore := store.(referrerstore.FilteringReferrerStore)
+
+	result, err := filteringStore.ListFilteredReferrers(ctx, reference, referrerstore.ReferrerFilter{}, testNextToken1, nil)
+	if err != nil || !reflect.DeepEqual(result, testResult1) {
+		t.Fatalf("expected unfiltered result %+v, got %+v %v", testResult1, result, err)
+	}
+	// the filtered result is not served from the entry of the unfiltered result
+	filtered, err := filteringStore.ListFilteredReferrers(ctx, reference, filter, testNextToken2, nil)
+	if err != nil || filtered.NextToken != testResult2.NextToken || len(filtered.Referrers) != 0 {
+		t.Fatalf("expected filtered result %+v, got %+v %v", testResult2, filtered, err)
+	}
+	time.Sleep(1 * time.Second) // wait for cache to populate
+	cached, err := filteringStore.ListFilteredReferrers(ctx, reference, filter, testNextToken1, nil)
+	if err != nil || cached.NextToken != filtered.NextToken || len(cached.Referrers) != 0 {
+		t.Fatalf("expected cached filtered result %+v, got %+v %v", filtered, cached, err)
+	}
+}
+
 func TestToCacheConfig(t *testing.T) {
 	resultCache, err := toCacheConfig(pluginConfig)
 	if err != nil {
@@ -0,0 +1,89 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// connectivityTracker records whether the last request of the store to a
+// registry failed to connect, so that it is reported in the store status.
+type connectivityTracker struct {
+	mu       sync.Mutex
+	observed bool
+	err      error
+}
+
+// observe records the result of a registry request. Errors returned by a
+// reachable registry, e.g. a missing manifest, do not indicate connectivity
+// problems.
+func (t *connectivityTracker) observe(err error) {
+	if t == nil || errors.Is(err, context.Canceled) {
+		// the request was canceled by the caller
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observed = true
+	t.err = nil
+	if isConnectivityError(err) {
+		t.err = err
+	}
+}
+
+func (t *connectivityTracker) get() (bool, error) {
+	if t == nil {
+		return false, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.observed, t.err
+}
+
+// isConnectivityError returns true if the registry could not be reached or
+// failed to serve the request.
+func isConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ec *errcode.ErrorResponse
+	if errors.As(err, &ec) {
+		return ec.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RegistryConnectivity returns whether the store sent a request to a registry,
+// and the error if the last request failed to connect.
+func (store *orasStore) RegistryConnectivity() (bool, error) {
+	return store.connectivity.get()
+}
+
+// RegistryConnectivity returns the connectivity of the decorated store.
+func (store *orasStoreWithInMemoryCache) RegistryConnectivity() (bool, error) {
+	if reporter, ok := store.ReferrerStore.(referrerstore.ConnectivityReporter); ok {
+		return reporter.RegistryConnectivity()
+	}
+	return false, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+func TestConnectivityTracker(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	testCases := []struct {
+		name          string
+		errs          []error
+		expectObserve bool
+		expectErr     bool
+	}{
+		{
+			name: "no request",
+		},
+		{
+			name:          "successful request",
+			errs:          []error{nil},
+			expectObserve: true,
+		},
+		{
+			name:          "not found",
+			errs:          []error{fmt.Errorf("manifest: %w", errdef.ErrNotFound)},
+			expectObserve: true,
+		},
+		{
+			name:          "unauthorized",
+			errs:          []error{&errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}},
+			expectObserve: true,
+		},
+		{
+			name:          "server error",
+			errs:          []error{&errcode.ErrorResponse{StatusCode: http.StatusServiceUnavailable}},
+			expectObserve: true,
+			expectErr:     true,
+		},
+		{
+			name:          "dial error",
+			errs:          []error{fmt.Errorf("resolve: %w", dialErr)},
+			expectObserve: true,
+			expectErr:     true,
+		},
+		{
+			name:          "recovered",
+			errs:          []error{dialErr, nil},
+			expectObserve: true,
+		},
+		{
+			name:          "canceled",
+			errs:          []error{dialErr, context.Canceled},
+			expectObserve: true,
+			expectErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &orasStore{connectivity: &connectivityTracker{}}
+			for _, err := range tc.errs {
+				store.connectivity.observe(err)
+			}
+			observed, err := store.RegistryConnectivity()
+			if observed != tc.expectObserve {
+				t.Fatalf("expected observed %v, got %v", tc.expectObserve, observed)
+			}
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRegistryConnectivity_InMemoryCache(t *testing.T) {
+	store := &orasStore{connectivity: &connectivityTracker{}}
+	store.connectivity.observe(nil)
+	cached := &orasStoreWithInMemoryCache{ReferrerStore: store}
+	if observed, err := cached.RegistryConnectivity(); !observed || err != nil {
+		t.Fatalf("expected connectivity of the decorated store, got %v %v", observed, err)
+	}
+}
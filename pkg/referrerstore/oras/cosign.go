@@ -24,6 +24,7 @@ import (
 	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"oras.land/oras-go/v2/errdef"
@@ -31,36 +32,87 @@ import (
 )
 
 const CosignArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+const CosignAttestationArtifactType = "application/vnd.dev.cosign.artifact.att.v1+json"
 const CosignSignatureTagSuffix = ".sig"
+const CosignAttestationTagSuffix = ".att"
 
-func getCosignReferences(ctx context.Context, subjectReference common.Reference, repository registry.Repository) (*[]ocispecs.ReferenceDescriptor, error) {
-	var references []ocispecs.ReferenceDescriptor
-	signatureTag, err := attachedImageTag(subjectReference, CosignSignatureTagSuffix)
-	if err != nil {
-		return nil, err
-	}
+// cosignTagConventions maps the tag suffixes cosign attaches signatures and
+// attestations with to the artifact type of the discovered references
+var cosignTagConventions = []struct {
+	tagSuffix    string
+	artifactType string
+}{
+	{tagSuffix: CosignSignatureTagSuffix, artifactType: CosignArtifactType},
+	{tagSuffix: CosignAttestationTagSuffix, artifactType: CosignAttestationArtifactType},
+}
 
-	desc, err := repository.Resolve(ctx, signatureTag)
-	if err != nil {
-		if errors.Is(err, errdef.ErrNotFound) {
-			return nil, nil
+// matchesCosignArtifactTypes reports whether the filter selects any cosign references
+func matchesCosignArtifactTypes(filter referrerstore.ReferrerFilter) bool {
+	for _, convention := range cosignTagConventions {
+		if filter.MatchesArtifactType(convention.artifactType) {
+			return true
 		}
-		evictOnError(ctx, err, subjectReference.Original)
-		return nil, re.ErrorCodeRepositoryOperationFailure.WithError(err).WithComponentType(re.ReferrerStore)
 	}
+	return false
+}
 
-	references = append(references, ocispecs.ReferenceDescriptor{
-		ArtifactType: CosignArtifactType,
-		Descriptor: oci.Descriptor{
-			MediaType: desc.MediaType,
-			Digest:    desc.Digest,
-			Size:      desc.Size,
-		},
-	})
+// getCosignReferences discovers the signatures and attestations attached to
+// the subject with the cosign tag convention. Only the tags of artifact types
+// matched by the filter are resolved.
+func getCosignReferences(ctx context.Context, subjectReference common.Reference, repository registry.Repository, filter referrerstore.ReferrerFilter) (*[]ocispecs.ReferenceDescriptor, error) {
+	var references []ocispecs.ReferenceDescriptor
+	for _, convention := range cosignTagConventions {
+		if !filter.MatchesArtifactType(convention.artifactType) {
+			continue
+		}
+		attachedTag, err := attachedImageTag(subjectReference, convention.tagSuffix)
+		if err != nil {
+			return nil, err
+		}
+
+		desc, err := repository.Resolve(ctx, attachedTag)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			evictOnError(ctx, err, subjectReference.Original)
+			return nil, re.ErrorCodeRepositoryOperationFailure.WithError(err).WithComponentType(re.ReferrerStore)
+		}
 
+		references = append(references, ocispecs.ReferenceDescriptor{
+			ArtifactType: convention.artifactType,
+			Descriptor: oci.Descriptor{
+				MediaType: desc.MediaType,
+				Digest:    desc.Digest,
+				Size:      desc.Size,
+			},
+		})
+	}
+
+	if len(references) == 0 {
+		return nil, nil
+	}
 	return &references, nil
 }
 
+// mergeCosignReferences appends the references discovered with the cosign tag
+// convention to the referrers listed by the registry. Newer cosign versions
+// publish OCI 1.1 referrers and may also push the tags, so references already
+// listed are skipped.
+func mergeCosignReferences(referrers, cosignReferences []ocispecs.ReferenceDescriptor) []ocispecs.ReferenceDescriptor {
+	listed := make(map[string]struct{}, len(referrers))
+	for _, referrer := range referrers {
+		listed[referrer.Digest.String()] = struct{}{}
+	}
+	for _, reference := range cosignReferences {
+		if _, ok := listed[reference.Digest.String()]; !ok {
+			listed[reference.Digest.String()] = struct{}{}
+			referrers = append(referrers, reference)
+		}
+	}
+	return referrers
+}
+
 func attachedImageTag(subjectReference common.Reference, tagSuffix string) (string, error) {
 	// sha256:d34db33f -> sha256-d34db33f.suffix
 	if subjectReference.Digest.String() == "" {
@@ -28,6 +28,7 @@ import (
 	_ "github.com/deislabs/ratify/pkg/cache/ristretto"
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
 	"github.com/deislabs/ratify/pkg/referrerstore/oras/mocks"
 	"github.com/opencontainers/go-digest"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
@@ -92,10 +93,13 @@ func TestGetCosignReferences(t *testing.T) {
 	testSubjectDigest := digest.FromString("test")
 	testCosignSubjectTag := fmt.Sprintf("%s-%s.sig", testSubjectDigest.Algorithm().String(), testSubjectDigest.Hex())
 	testCosignImageDigest := digest.FromString("test_cosign")
+	testCosignAttestationTag := fmt.Sprintf("%s-%s.att", testSubjectDigest.Algorithm().String(), testSubjectDigest.Hex())
+	testCosignAttestationDigest := digest.FromString("test_cosign_attestation")
 	testcases := []struct {
 		name       string
 		subjectRef common.Reference
 		repository registry.Repository
+		filter     referrerstore.ReferrerFilter
 		output     *[]ocispecs.ReferenceDescriptor
 		err        error
 	}{
@@ -150,6 +154,67 @@ func TestGetCosignReferences(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "cosign signature and attestation",
+			subjectRef: common.Reference{
+				Path:   "localhost:5000/net-monitor",
+				Tag:    "v1",
+				Digest: testSubjectDigest,
+			},
+			repository: mocks.TestRepository{
+				ResolveMap: map[string]oci.Descriptor{
+					fmt.Sprintf("localhost:5000/net-monitor:%s", testCosignSubjectTag): {
+						Digest: testCosignImageDigest,
+					},
+					fmt.Sprintf("localhost:5000/net-monitor:%s", testCosignAttestationTag): {
+						Digest: testCosignAttestationDigest,
+					},
+				},
+			},
+			output: &[]ocispecs.ReferenceDescriptor{
+				{
+					Descriptor: oci.Descriptor{
+						Digest: testCosignImageDigest,
+					},
+					ArtifactType: CosignArtifactType,
+				},
+				{
+					Descriptor: oci.Descriptor{
+						Digest: testCosignAttestationDigest,
+					},
+					ArtifactType: CosignAttestationArtifactType,
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "filter selects cosign attestations",
+			subjectRef: common.Reference{
+				Path:   "localhost:5000/net-monitor",
+				Tag:    "v1",
+				Digest: testSubjectDigest,
+			},
+			repository: mocks.TestRepository{
+				ResolveMap: map[string]oci.Descriptor{
+					fmt.Sprintf("localhost:5000/net-monitor:%s", testCosignSubjectTag): {
+						Digest: testCosignImageDigest,
+					},
+					fmt.Sprintf("localhost:5000/net-monitor:%s", testCosignAttestationTag): {
+						Digest: testCosignAttestationDigest,
+					},
+				},
+			},
+			filter: referrerstore.ReferrerFilter{ArtifactTypes: []string{CosignAttestationArtifactType}},
+			output: &[]ocispecs.ReferenceDescriptor{
+				{
+					Descriptor: oci.Descriptor{
+						Digest: testCosignAttestationDigest,
+					},
+					ArtifactType: CosignAttestationArtifactType,
+				},
+			},
+			err: nil,
+		},
 		{
 			name: "resolve error non-standard error code",
 			subjectRef: common.Reference{
@@ -194,7 +259,7 @@ func TestGetCosignReferences(t *testing.T) {
 	}
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			refs, err := getCosignReferences(ctx, testcase.subjectRef, testcase.repository)
+			refs, err := getCosignReferences(ctx, testcase.subjectRef, testcase.repository, testcase.filter)
 			if !errors.Is(err, testcase.err) {
 				t.Fatalf("test case: %s; expected error to be %v, but got %v", testcase.name, testcase.err, err)
 			}
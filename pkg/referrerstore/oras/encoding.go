@@ -0,0 +1,198 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/klauspost/compress/zstd"
+)
+
+const identityEncoding = "identity"
+
+// Decompressor returns a reader of the decoded content of a response body.
+type Decompressor func(body io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		"gzip": func(body io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(body)
+		},
+		"zstd": func(body io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(body)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	}
+)
+
+// RegisterDecompressor registers the decompressor of a content encoding, so
+// that it can be negotiated when fetching blobs.
+func RegisterDecompressor(encoding string, decompressor Decompressor) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors[strings.ToLower(encoding)] = decompressor
+}
+
+func getDecompressor(encoding string) (Decompressor, bool) {
+	decompressorsMu.RLock()
+	defer decompressorsMu.RUnlock()
+	decompressor, ok := decompressors[encoding]
+	return decompressor, ok
+}
+
+// validateContentEncodings returns an error if no decompressor is registered
+// for one of the encodings.
+func validateContentEncodings(encodings []string) error {
+	for _, encoding := range encodings {
+		if _, ok := getDecompressor(strings.ToLower(encoding)); !ok {
+			return fmt.Errorf("unsupported content encoding %s", encoding)
+		}
+	}
+	return nil
+}
+
+// encodingTransport negotiates the content encoding of blob downloads and
+// decodes the responses, so that large blobs are transferred compressed by
+// registries supporting it.
+type encodingTransport struct {
+	base http.RoundTripper
+	// acceptEncoding lists the encodings in order of preference
+	acceptEncoding string
+}
+
+func newEncodingTransport(base http.RoundTripper, encodings []string) http.RoundTripper {
+	if len(encodings) == 0 {
+		return base
+	}
+	return &encodingTransport{
+		base:           base,
+		acceptEncoding: strings.ToLower(strings.Join(encodings, ", ")),
+	}
+}
+
+func (t *encodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// range requests resume downloads at offsets of the decoded content
+	if req.Method != http.MethodGet || !isBlobRequest(req) || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", t.acceptEncoding)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" {
+		encoding = identityEncoding
+	}
+	wire := &countingReader{reader: resp.Body}
+	body := &measuredBody{wire: wire, closer: resp.Body, encoding: encoding, req: req}
+	if encoding == identityEncoding {
+		body.content = wire
+		resp.Body = body
+		return resp, nil
+	}
+
+	decompressor, ok := getDecompressor(encoding)
+	if !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry responded with unsupported content encoding %s", encoding)
+	}
+	decoded, err := decompressor(wire)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode %s response: %w", encoding, err)
+	}
+	body.content = &countingReader{reader: decoded}
+	body.decoder = decoded
+	resp.Body = body
+
+	// the size of the decoded content is unknown and the registry cannot
+	// resume the download at an offset of it
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.Header.Del("Accept-Ranges")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// isBlobRequest returns true if the request fetches a blob, including requests
+// redirected from the registry to a storage backend.
+func isBlobRequest(req *http.Request) bool {
+	for ; req != nil; req = redirectedFrom(req) {
+		if strings.Contains(req.URL.Path, "/blobs/") {
+			return true
+		}
+	}
+	return false
+}
+
+func redirectedFrom(req *http.Request) *http.Request {
+	if req.Response == nil {
+		return nil
+	}
+	return req.Response.Request
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// measuredBody reports the size of the blob on the wire and of its content
+// once the body is closed.
+type measuredBody struct {
+	wire     *countingReader
+	content  *countingReader
+	decoder  io.ReadCloser
+	closer   io.Closer
+	encoding string
+	req      *http.Request
+	once     sync.Once
+}
+
+func (b *measuredBody) Read(p []byte) (int, error) {
+	return b.content.Read(p)
+}
+
+func (b *measuredBody) Close() error {
+	b.once.Do(func() {
+		metrics.ReportBlobTransferSize(b.req.Context(), b.encoding, b.wire.count, b.content.count)
+	})
+	if b.decoder != nil {
+		b.decoder.Close()
+	}
+	return b.closer.Close()
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func encode(t *testing.T, encoding string, content []byte) []byte {
+	var buf bytes.Buffer
+	switch encoding {
+	case "gzip":
+		writer := gzip.NewWriter(&buf)
+		writer.Write(content)
+		writer.Close()
+	case "zstd":
+		writer, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatalf("failed to create zstd writer: %v", err)
+		}
+		writer.Write(content)
+		writer.Close()
+	default:
+		buf.Write(content)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodingTransport(t *testing.T) {
+	content := []byte(strings.Repeat(`{"spdxVersion":"SPDX-2.3"}`, 100))
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/repo/blobs/redirect" {
+			http.Redirect(w, r, "/storage/blob", http.StatusTemporaryRedirect)
+			return
+		}
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		encoding := strings.Split(acceptEncoding, ", ")[0]
+		if r.URL.Path == "/v2/repo/manifests/latest" || r.URL.Query().Get("encoding") == "identity" {
+			encoding = ""
+		}
+		if encoding != "" {
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Write(encode(t, encoding, content))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name           string
+		encodings      []string
+		path           string
+		acceptEncoding string
+		decoded        bool
+	}{
+		{name: "zstd blob", encodings: []string{"zstd", "gzip"}, path: "/v2/repo/blobs/sha256:abc", acceptEncoding: "zstd, gzip", decoded: true},
+		{name: "gzip blob", encodings: []string{"GZIP"}, path: "/v2/repo/blobs/sha256:abc", acceptEncoding: "gzip", decoded: true},
+		{name: "redirected blob", encodings: []string{"zstd"}, path: "/v2/repo/blobs/redirect", acceptEncoding: "zstd", decoded: true},
+		{name: "unencoded blob", encodings: []string{"zstd"}, path: "/v2/repo/blobs/sha256:abc?encoding=identity", acceptEncoding: "zstd"},
+		{name: "manifest", encodings: []string{"zstd"}, path: "/v2/repo/manifests/latest"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			acceptEncoding = ""
+			base := http.DefaultTransport.(*http.Transport).Clone()
+			client := &http.Client{Transport: newEncodingTransport(base, tc.encodings)}
+			resp, err := client.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			if !bytes.Equal(body, content) {
+				t.Fatalf("expected decoded content, got %d bytes", len(body))
+			}
+			if tc.acceptEncoding != "" && acceptEncoding != tc.acceptEncoding {
+				t.Fatalf("expected Accept-Encoding %q, got %q", tc.acceptEncoding, acceptEncoding)
+			}
+			if tc.decoded && (resp.ContentLength != -1 || resp.Header.Get("Accept-Ranges") != "") {
+				t.Fatalf("expected unknown length without range support for decoded content, got %d %q", resp.ContentLength, resp.Header.Get("Accept-Ranges"))
+			}
+		})
+	}
+}
+
+func TestValidateContentEncodings(t *testing.T) {
+	if err := validateContentEncodings([]string{"zstd", "GZIP"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateContentEncodings([]string{"br"}); err == nil {
+		t.Fatalf("expected error for unsupported encoding")
+	}
+	RegisterDecompressor("BR", func(body io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(body), nil
+	})
+	defer func() {
+		decompressorsMu.Lock()
+		delete(decompressors, "br")
+		decompressorsMu.Unlock()
+	}()
+	if err := validateContentEncodings([]string{"br"}); err != nil {
+		t.Fatalf("expected registered encoding to be supported, got %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	paths "path/filepath"
+	"sync"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	ocitarget "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+)
+
+const (
+	// localCacheIndexFile records the digest and size of every blob completely
+	// written to the local cache.
+	localCacheIndexFile = "ratify-index.json"
+	// localCacheIngestDir holds the temporary files blobs are written to before
+	// being renamed into place.
+	localCacheIngestDir = "ingest"
+)
+
+// localCacheIndex is the persisted integrity index of the local cache.
+type localCacheIndex struct {
+	Blobs map[digest.Digest]int64 `json:"blobs"`
+}
+
+// localCache is the OCI layout the ORAS store caches content in, hardened
+// against partial writes. A blob is only served once it is recorded in the
+// integrity index, which is written after the blob is synced to disk, and its
+// content is verified against the digest when read. Corrupted blobs are
+// deleted so that they are fetched from the registry again.
+type localCache struct {
+	root  string
+	store content.Storage
+
+	mu    sync.Mutex
+	blobs map[digest.Digest]int64
+}
+
+// newLocalCache checks the local cache at root, dropping incomplete and
+// corrupted entries left by a crash, and opens it.
+func newLocalCache(ctx context.Context, root string) (*localCache, error) {
+	root, err := paths.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", root, err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local cache directory: %w", err)
+	}
+	cache := &localCache{root: root}
+	if err := cache.fsck(ctx); err != nil {
+		return nil, err
+	}
+
+	store, err := ocitarget.NewWithContext(ctx, root)
+	if err != nil {
+		// the OCI index only tags cached manifests, it is rebuilt as manifests
+		// are pushed again
+		logger.GetLogger(ctx, logOpt).Warnf("resetting invalid index of local cache %s: %v", root, err)
+		if err := os.Remove(paths.Join(root, oci.ImageIndexFile)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if store, err = ocitarget.NewWithContext(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+	cache.store = store
+	return cache, nil
+}
+
+// Exists returns true if the blob is completely written to the local cache.
+func (c *localCache) Exists(ctx context.Context, target oci.Descriptor) (bool, error) {
+	size, ok := c.lookup(target.Digest)
+	if !ok {
+		return false, nil
+	}
+	exists, err := c.store.Exists(ctx, oci.Descriptor{Digest: target.Digest, Size: size})
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		c.evict(ctx, target.Digest)
+	}
+	return exists, nil
+}
+
+// Fetch returns a reader of the blob that verifies its content, the blob is
+// evicted if it does not match its digest.
+func (c *localCache) Fetch(ctx context.Context, target oci.Descriptor) (io.ReadCloser, error) {
+	size, ok := c.lookup(target.Digest)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+	}
+	desc := oci.Descriptor{MediaType: target.MediaType, Digest: target.Digest, Size: size}
+	reader, err := c.store.Fetch(ctx, desc)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			c.evict(ctx, target.Digest)
+		}
+		return nil, err
+	}
+	return &verifyingReadCloser{
+		ReadCloser: reader,
+		verifier:   content.NewVerifyReader(reader, desc),
+		onCorrupt: func(err error) {
+			logger.GetLogger(ctx, logOpt).Warnf("evicting corrupted blob %s from local cache: %v", target.Digest, err)
+			c.evict(ctx, target.Digest)
+		},
+	}, nil
+}
+
+// Push writes the blob to the local cache and records it in the integrity
+// index once it is synced to disk.
+func (c *localCache) Push(ctx context.Context, expected oci.Descriptor, reader io.Reader) error {
+	if _, ok := c.lookup(expected.Digest); ok {
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, errdef.ErrAlreadyExists)
+	}
+	err := c.store.Push(ctx, expected, reader)
+	if errors.Is(err, errdef.ErrAlreadyExists) {
+		// the blob was renamed into place but is not indexed, e.g. the process
+		// crashed before the index was written, write it again since it is
+		// not known to be complete
+		if err = c.removeBlob(expected.Digest); err != nil {
+			return err
+		}
+		err = c.store.Push(ctx, expected, reader)
+	}
+	if err != nil {
+		return err
+	}
+
+	blobPath, err := c.blobPath(expected.Digest)
+	if err != nil {
+		return err
+	}
+	if err := syncFile(blobPath); err != nil {
+		return err
+	}
+	if err := syncFile(paths.Dir(blobPath)); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blobs[expected.Digest] = expected.Size
+	return c.saveIndex()
+}
+
+func (c *localCache) lookup(blobDigest digest.Digest) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size, ok := c.blobs[blobDigest]
+	return size, ok
+}
+
+// evict removes the blob from the integrity index and deletes it.
+func (c *localCache) evict(ctx context.Context, blobDigest digest.Digest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.blobs[blobDigest]; !ok {
+		return
+	}
+	delete(c.blobs, blobDigest)
+	if err := c.saveIndex(); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to save index of local cache: %v", err)
+	}
+	if err := c.removeBlob(blobDigest); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to remove blob %s from local cache: %v", blobDigest, err)
+	}
+}
+
+func (c *localCache) removeBlob(blobDigest digest.Digest) error {
+	blobPath, err := c.blobPath(blobDigest)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(blobPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *localCache) blobPath(blobDigest digest.Digest) (string, error) {
+	if err := blobDigest.Validate(); err != nil {
+		return "", fmt.Errorf("%s: %w", blobDigest, errdef.ErrInvalidDigest)
+	}
+	return paths.Join(c.root, oci.ImageBlobsDir, blobDigest.Algorithm().String(), blobDigest.Encoded()), nil
+}
+
+// fsck drops the temporary files of interrupted writes and the blobs that are
+// not indexed or do not match their digest and size, then rewrites the
+// integrity index and the OCI index with the remaining blobs.
+func (c *localCache) fsck(ctx context.Context) error {
+	if err := os.RemoveAll(paths.Join(c.root, localCacheIngestDir)); err != nil {
+		return fmt.Errorf("failed to remove incomplete writes of local cache: %w", err)
+	}
+	for _, name := range []string{localCacheIndexFile, oci.ImageIndexFile} {
+		tempFiles, _ := paths.Glob(paths.Join(c.root, "."+name+"-*"))
+		for _, tempFile := range tempFiles {
+			os.Remove(tempFile)
+		}
+	}
+
+	indexed := c.loadIndex(ctx)
+	c.blobs = map[digest.Digest]int64{}
+	blobsDir := paths.Join(c.root, oci.ImageBlobsDir)
+	err := paths.WalkDir(blobsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == blobsDir {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		blobDigest := digest.NewDigestFromEncoded(digest.Algorithm(paths.Base(paths.Dir(path))), entry.Name())
+		size, ok := indexed[blobDigest]
+		if ok && blobDigest.Validate() == nil {
+			if err = verifyBlobFile(path, oci.Descriptor{Digest: blobDigest, Size: size}); err == nil {
+				c.blobs[blobDigest] = size
+				return nil
+			}
+			logger.GetLogger(ctx, logOpt).Warnf("dropping corrupted blob %s from local cache: %v", path, err)
+		} else {
+			logger.GetLogger(ctx, logOpt).Infof("dropping incomplete blob %s from local cache", path)
+		}
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check local cache: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.saveIndex(); err != nil {
+		return err
+	}
+	return c.pruneOCIIndex(ctx)
+}
+
+// loadIndex reads the integrity index, an unreadable index is treated as
+// empty so that all blobs are dropped.
+func (c *localCache) loadIndex(ctx context.Context) map[digest.Digest]int64 {
+	indexBytes, err := os.ReadFile(paths.Join(c.root, localCacheIndexFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.GetLogger(ctx, logOpt).Warnf("failed to read index of local cache: %v", err)
+		}
+		return nil
+	}
+	var index localCacheIndex
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("dropping invalid index of local cache: %v", err)
+		return nil
+	}
+	return index.Blobs
+}
+
+// saveIndex writes the integrity index to a temporary file and renames it into
+// place, the caller must hold the lock.
+func (c *localCache) saveIndex() error {
+	indexBytes, err := json.Marshal(localCacheIndex{Blobs: c.blobs})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(paths.Join(c.root, localCacheIndexFile), indexBytes)
+}
+
+// pruneOCIIndex removes the manifests of dropped blobs from the OCI index, which
+// is not written atomically, so that the OCI layout can be opened. The OCI
+// index is removed if it cannot be decoded, the caller must hold the lock.
+func (c *localCache) pruneOCIIndex(ctx context.Context) error {
+	indexPath := paths.Join(c.root, oci.ImageIndexFile)
+	indexBytes, err := os.ReadFile(indexPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var index oci.Index
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("dropping invalid OCI index of local cache: %v", err)
+		return os.Remove(indexPath)
+	}
+	manifests := make([]oci.Descriptor, 0, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		if _, ok := c.blobs[desc.Digest]; ok {
+			manifests = append(manifests, desc)
+		}
+	}
+	if len(manifests) == len(index.Manifests) {
+		return nil
+	}
+	index.Manifests = manifests
+	if indexBytes, err = json.Marshal(index); err != nil {
+		return err
+	}
+	return writeFileAtomic(indexPath, indexBytes)
+}
+
+// verifyingReadCloser verifies the content read from the local cache against
+// its descriptor and reports corrupted content.
+type verifyingReadCloser struct {
+	io.ReadCloser
+	verifier  *content.VerifyReader
+	onCorrupt func(error)
+	reported  bool
+}
+
+func (r *verifyingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.verifier.Read(p)
+	if err == io.EOF {
+		if verifyErr := r.verifier.Verify(); verifyErr != nil {
+			err = verifyErr
+		}
+	}
+	if err != nil && err != io.EOF && !r.reported {
+		r.reported = true
+		r.onCorrupt(err)
+	}
+	return n, err
+}
+
+func verifyBlobFile(path string, desc oci.Descriptor) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != desc.Size {
+		return fmt.Errorf("expected size %d, got %d", desc.Size, info.Size())
+	}
+	verifier := content.NewVerifyReader(file, desc)
+	if _, err := io.Copy(io.Discard, verifier); err != nil {
+		return err
+	}
+	return verifier.Verify()
+}
+
+// writeFileAtomic writes the file to a temporary file in the same directory,
+// syncs it and renames it into place, so that a crash leaves either the old or
+// the new content.
+func writeFileAtomic(path string, data []byte) (err error) {
+	file, err := os.CreateTemp(paths.Dir(path), "."+paths.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+	return syncFile(paths.Dir(path))
+}
+
+// syncFile flushes the file or directory to disk.
+func syncFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	paths "path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func newTestBlob(content string) (oci.Descriptor, []byte) {
+	blob := []byte(content)
+	return oci.Descriptor{
+		MediaType: "application/octet-stream",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}, blob
+}
+
+func pushTestBlob(t *testing.T, cache *localCache, desc oci.Descriptor, blob []byte) {
+	t.Helper()
+	if err := cache.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("failed to push blob: %v", err)
+	}
+}
+
+func testBlobPath(root string, desc oci.Descriptor) string {
+	return paths.Join(root, oci.ImageBlobsDir, desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+}
+
+func overwriteTestBlob(t *testing.T, path string, content []byte) {
+	t.Helper()
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("failed to change mode of blob: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("failed to overwrite blob: %v", err)
+	}
+}
+
+func TestLocalCache_PushFetch(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	cache, err := newLocalCache(ctx, root)
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	desc, blob := newTestBlob("test blob")
+	pushTestBlob(t, cache, desc, blob)
+	if err := cache.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Fatalf("expected already exists error, got %v", err)
+	}
+
+	// the blob survives a restart and is found without its size
+	if cache, err = newLocalCache(ctx, root); err != nil {
+		t.Fatalf("failed to reopen local cache: %v", err)
+	}
+	exists, err := cache.Exists(ctx, oci.Descriptor{Digest: desc.Digest})
+	if err != nil || !exists {
+		t.Fatalf("expected cached blob to exist, got %v %v", exists, err)
+	}
+	reader, err := cache.Fetch(ctx, oci.Descriptor{Digest: desc.Digest})
+	if err != nil {
+		t.Fatalf("failed to fetch blob: %v", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read blob: %v", err)
+	}
+	if !bytes.Equal(content, blob) {
+		t.Fatalf("expected content %q, got %q", blob, content)
+	}
+}
+
+func TestLocalCache_Fsck(t *testing.T) {
+	ctx := context.Background()
+	valid, validBlob := newTestBlob("valid blob")
+	corrupted, corruptedBlob := newTestBlob("corrupted blob")
+	truncated, truncatedBlob := newTestBlob("truncated blob")
+	unindexed, unindexedBlob := newTestBlob("unindexed blob")
+
+	root := t.TempDir()
+	cache, err := newLocalCache(ctx, root)
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	pushTestBlob(t, cache, valid, validBlob)
+	pushTestBlob(t, cache, corrupted, corruptedBlob)
+	pushTestBlob(t, cache, truncated, truncatedBlob)
+	overwriteTestBlob(t, testBlobPath(root, corrupted), []byte("CORRUPTED BLOB"))
+	overwriteTestBlob(t, testBlobPath(root, truncated), truncatedBlob[:4])
+	// a blob renamed into place before the crash but never indexed
+	if err := os.WriteFile(testBlobPath(root, unindexed), unindexedBlob, 0o444); err != nil {
+		t.Fatalf("failed to write unindexed blob: %v", err)
+	}
+	// temporary files of interrupted writes
+	if err := os.MkdirAll(paths.Join(root, localCacheIngestDir), 0o755); err != nil {
+		t.Fatalf("failed to create ingest directory: %v", err)
+	}
+	if err := os.WriteFile(paths.Join(root, localCacheIngestDir, "partial"), []byte("part"), 0o644); err != nil {
+		t.Fatalf("failed to write partial blob: %v", err)
+	}
+	if err := os.WriteFile(paths.Join(root, "."+localCacheIndexFile+"-1"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("failed to write partial index: %v", err)
+	}
+	// index.json truncated by the crash
+	if err := os.WriteFile(paths.Join(root, oci.ImageIndexFile), []byte(`{"schemaVersion":2,"manif`), 0o644); err != nil {
+		t.Fatalf("failed to truncate OCI index: %v", err)
+	}
+
+	if cache, err = newLocalCache(ctx, root); err != nil {
+		t.Fatalf("failed to reopen local cache: %v", err)
+	}
+	testCases := []struct {
+		name   string
+		desc   oci.Descriptor
+		exists bool
+	}{
+		{name: "valid blob", desc: valid, exists: true},
+		{name: "corrupted blob", desc: corrupted},
+		{name: "truncated blob", desc: truncated},
+		{name: "unindexed blob", desc: unindexed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exists, err := cache.Exists(ctx, tc.desc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exists != tc.exists {
+				t.Fatalf("expected exists %v, got %v", tc.exists, exists)
+			}
+			if _, err := os.Stat(testBlobPath(root, tc.desc)); os.IsNotExist(err) == tc.exists {
+				t.Fatalf("expected blob file to exist %v, got %v", tc.exists, err)
+			}
+		})
+	}
+	for _, path := range []string{paths.Join(root, localCacheIngestDir), paths.Join(root, "."+localCacheIndexFile+"-1")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", path, err)
+		}
+	}
+
+	// dropped blobs are written again
+	pushTestBlob(t, cache, unindexed, unindexedBlob)
+	pushTestBlob(t, cache, corrupted, corruptedBlob)
+}
+
+func TestLocalCache_FetchCorrupted(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	cache, err := newLocalCache(ctx, root)
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	desc, blob := newTestBlob("test blob")
+	pushTestBlob(t, cache, desc, blob)
+	overwriteTestBlob(t, testBlobPath(root, desc), []byte("TEST BLOB"))
+
+	reader, err := cache.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatalf("failed to fetch blob: %v", err)
+	}
+	defer reader.Close()
+	if _, err = io.ReadAll(reader); err == nil {
+		t.Fatalf("expected error reading corrupted blob")
+	}
+	exists, err := cache.Exists(ctx, desc)
+	if err != nil || exists {
+		t.Fatalf("expected corrupted blob to be evicted, got %v %v", exists, err)
+	}
+	if _, err = cache.Fetch(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	pushTestBlob(t, cache, desc, blob)
+}
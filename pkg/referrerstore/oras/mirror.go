@@ -0,0 +1,69 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"oras.land/oras-go/v2/registry"
+)
+
+// validateMirrors returns an error if a registry or a mirror is invalid.
+func validateMirrors(mirrors map[string]string) error {
+	for upstream, mirror := range mirrors {
+		if upstream == "" || strings.Contains(upstream, "/") {
+			return fmt.Errorf("invalid upstream registry %q of mirror %s, expected a registry host", upstream, mirror)
+		}
+		if mirror == "" {
+			return fmt.Errorf("mirror of registry %s must not be empty", upstream)
+		}
+		if _, err := registry.ParseReference(mirror + "/repository"); err != nil {
+			return fmt.Errorf("invalid mirror %s of registry %s, expected a registry host optionally followed by a repository prefix: %w", mirror, upstream, err)
+		}
+	}
+	return nil
+}
+
+// mirrorReference returns the reference of the subject in the mirror of its
+// registry, e.g. mirror.example.com/library/nginx:v1 for
+// docker.io/library/nginx:v1, or the reference as is if its registry has no
+// mirror. The repository prefix of the mirror, e.g. of a pull-through cache
+// project, is prepended to the repository.
+func (store *orasStore) mirrorReference(subjectReference common.Reference) common.Reference {
+	upstream, repository, ok := strings.Cut(subjectReference.Path, "/")
+	if !ok {
+		return subjectReference
+	}
+	mirror, ok := store.config.Mirrors[upstream]
+	if !ok {
+		return subjectReference
+	}
+
+	mirrored := subjectReference
+	mirrored.Path = path.Join(mirror, repository)
+	switch {
+	case subjectReference.Digest != "":
+		mirrored.Original = fmt.Sprintf("%s@%s", mirrored.Path, subjectReference.Digest)
+	case subjectReference.Tag != "":
+		mirrored.Original = fmt.Sprintf("%s:%s", mirrored.Path, subjectReference.Tag)
+	default:
+		mirrored.Original = mirrored.Path
+	}
+	return mirrored
+}
@@ -0,0 +1,150 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/referrerstore/config"
+	"github.com/deislabs/ratify/pkg/testregistry"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestValidateMirrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mirrors   map[string]string
+		expectErr bool
+	}{
+		{name: "no mirrors"},
+		{name: "mirror host", mirrors: map[string]string{"docker.io": "mirror.example.com:5000"}},
+		{name: "mirror with repository prefix", mirrors: map[string]string{"docker.io": "harbor.example.com/dockerhub-proxy"}},
+		{name: "upstream with repository", mirrors: map[string]string{"docker.io/library": "mirror.example.com"}, expectErr: true},
+		{name: "empty mirror", mirrors: map[string]string{"docker.io": ""}, expectErr: true},
+		{name: "invalid mirror", mirrors: map[string]string{"docker.io": "Mirror.example.com/UPPER"}, expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateMirrors(tc.mirrors); tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestMirrorReference(t *testing.T) {
+	store := &orasStore{config: &OrasStoreConf{Mirrors: map[string]string{
+		"docker.io": "harbor.example.com/dockerhub-proxy",
+		"ghcr.io":   "mirror.example.com",
+	}}}
+	testDigest := digest.FromString("test")
+	testCases := []struct {
+		name     string
+		ref      common.Reference
+		expected common.Reference
+	}{
+		{
+			name:     "tag with repository prefix",
+			ref:      common.Reference{Original: "docker.io/library/nginx:v1", Path: "docker.io/library/nginx", Tag: "v1"},
+			expected: common.Reference{Original: "harbor.example.com/dockerhub-proxy/library/nginx:v1", Path: "harbor.example.com/dockerhub-proxy/library/nginx", Tag: "v1"},
+		},
+		{
+			name:     "digest",
+			ref:      common.Reference{Original: "ghcr.io/org/app@" + testDigest.String(), Path: "ghcr.io/org/app", Digest: testDigest},
+			expected: common.Reference{Original: "mirror.example.com/org/app@" + testDigest.String(), Path: "mirror.example.com/org/app", Digest: testDigest},
+		},
+		{
+			name:     "registry without mirror",
+			ref:      common.Reference{Original: "quay.io/org/app:v1", Path: "quay.io/org/app", Tag: "v1"},
+			expected: common.Reference{Original: "quay.io/org/app:v1", Path: "quay.io/org/app", Tag: "v1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if mirrored := store.mirrorReference(tc.ref); mirrored != tc.expected {
+				t.Fatalf("expected %+v, got %+v", tc.expected, mirrored)
+			}
+		})
+	}
+}
+
+// TestORASStore_Mirror tests that subjects of an upstream registry are
+// resolved and their referrers and blobs fetched from a pull-through cache.
+func TestORASStore_Mirror(t *testing.T) {
+	ctx := context.Background()
+	reg := testregistry.New()
+	defer reg.Close()
+
+	imageDesc, err := reg.PushImage(ctx, "proxy/net-monitor", "v1", []byte("layer"))
+	if err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	signature := []byte("signature")
+	if _, err := reg.PushReferrer(ctx, "proxy/net-monitor", imageDesc, "application/vnd.cncf.notary.signature", testregistry.Blob{MediaType: "application/jose+json", Content: signature}); err != nil {
+		t.Fatalf("failed to push referrer: %v", err)
+	}
+
+	store, err := createBaseStore("1.0.0", config.StorePluginConfig{
+		"name":    "oras",
+		"useHttp": true,
+		"mirrors": map[string]interface{}{"upstream.example.com": reg.Host + "/proxy"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create oras store: %v", err)
+	}
+	subjectRef := common.Reference{
+		Original: "upstream.example.com/net-monitor:v1",
+		Path:     "upstream.example.com/net-monitor",
+		Tag:      "v1",
+	}
+
+	subjectDesc, err := store.GetSubjectDescriptor(ctx, subjectRef)
+	if err != nil {
+		t.Fatalf("failed to get subject descriptor: %v", err)
+	}
+	if subjectDesc.Digest != imageDesc.Digest {
+		t.Fatalf("expected subject digest %s, got %s", imageDesc.Digest, subjectDesc.Digest)
+	}
+	subjectRef.Digest = subjectDesc.Digest
+
+	referrers, err := store.ListReferrers(ctx, subjectRef, nil, "", subjectDesc)
+	if err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	if len(referrers.Referrers) != 1 {
+		t.Fatalf("expected 1 referrer, got %v", referrers.Referrers)
+	}
+	manifest, err := store.GetReferenceManifest(ctx, subjectRef, referrers.Referrers[0])
+	if err != nil {
+		t.Fatalf("failed to get reference manifest: %v", err)
+	}
+	blob, err := store.GetBlobContent(ctx, subjectRef, manifest.Blobs[0].Digest)
+	if err != nil {
+		t.Fatalf("failed to get blob content: %v", err)
+	}
+	if !bytes.Equal(blob, signature) {
+		t.Fatalf("expected blob %s, got %s", signature, blob)
+	}
+}
+
+func TestORASCreate_InvalidMirror(t *testing.T) {
+	if _, err := createBaseStore("1.0.0", config.StorePluginConfig{"name": "oras", "mirrors": map[string]interface{}{"docker.io": ""}}); err == nil {
+		t.Fatalf("expected error for invalid mirror")
+	}
+}
@@ -30,8 +30,10 @@ type TestRepository struct {
 	ResolveErr    error
 	ResolveMap    map[string]oci.Descriptor
 	ReferrersList []oci.Descriptor
-	FetchMap      map[digest.Digest]io.ReadCloser
-	BlobStoreTest TestBlobStore
+	// ArtifactTypeFilters records the artifact types referrers are filtered by
+	ArtifactTypeFilters *[]string
+	FetchMap            map[digest.Digest]io.ReadCloser
+	BlobStoreTest       TestBlobStore
 }
 
 type TestBlobStore struct {
@@ -54,7 +56,10 @@ func (r TestRepository) Resolve(_ context.Context, reference string) (oci.Descri
 	return oci.Descriptor{}, errdef.ErrNotFound
 }
 
-func (r TestRepository) Referrers(_ context.Context, _ oci.Descriptor, _ string, fn func(referrers []oci.Descriptor) error) error {
+func (r TestRepository) Referrers(_ context.Context, _ oci.Descriptor, artifactType string, fn func(referrers []oci.Descriptor) error) error {
+	if r.ArtifactTypeFilters != nil {
+		*r.ArtifactTypeFilters = append(*r.ArtifactTypeFilters, artifactType)
+	}
 	return fn(r.ReferrersList)
 }
 
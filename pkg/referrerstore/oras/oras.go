@@ -29,7 +29,6 @@ import (
 
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
-	ocitarget "oras.land/oras-go/v2/content/oci"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
@@ -51,8 +50,10 @@ import (
 	"github.com/deislabs/ratify/pkg/metrics"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/blobprovider"
 	"github.com/deislabs/ratify/pkg/referrerstore/config"
 	"github.com/deislabs/ratify/pkg/referrerstore/factory"
+	"github.com/deislabs/ratify/pkg/tracing"
 	"github.com/opencontainers/go-digest"
 )
 
@@ -70,6 +71,15 @@ const (
 	defaultLocalCachePath = "local_oras_cache"
 	dockerConfigFileName  = "config.json"
 	ratifyUserAgent       = "ratify"
+
+	// blobProviderDisk stores blobs in the local ORAS cache
+	blobProviderDisk = "disk"
+	// blobProviderMemory keeps blobs in memory
+	blobProviderMemory = "memory"
+	// blobProviderCache stores blobs in the cache configured for ratify
+	blobProviderCache = "cache"
+	// blobCacheTTL is the ttl of blobs in the cache, blobs are immutable
+	blobCacheTTL = time.Hour
 )
 
 var logOpt = logger.Option{ComponentType: logger.ReferrerStore}
@@ -81,6 +91,21 @@ type OrasStoreConf struct { //nolint:revive // ignore linter to have unique type
 	CosignEnabled  bool                            `json:"cosignEnabled,omitempty"`
 	AuthProvider   authprovider.AuthProviderConfig `json:"authProvider,omitempty"`
 	LocalCachePath string                          `json:"localCachePath,omitempty"`
+	// ContentEncodings are the encodings, e.g. zstd or gzip, accepted for blobs
+	// in order of preference. Blobs are fetched unencoded if empty.
+	ContentEncodings []string `json:"contentEncodings,omitempty"`
+	// BlobProvider is the backend blobs are cached in: disk (the default),
+	// memory or cache.
+	BlobProvider string `json:"blobProvider,omitempty"`
+	// ReferrersQueryMode selects how referrers are listed: auto (the default)
+	// uses the Referrers API and falls back to the tag schema if the registry
+	// does not support it, merge queries both and merges the results.
+	ReferrersQueryMode string `json:"referrersQueryMode,omitempty"`
+	// Mirrors map the hosts of upstream registries, e.g. docker.io, to the
+	// mirrors subjects are resolved from and referrers and blobs are fetched
+	// from instead, e.g. mirror.example.com or harbor.example.com/dockerhub-proxy
+	// for a pull-through cache whose repositories have a prefix.
+	Mirrors map[string]string `json:"mirrors,omitempty"`
 }
 
 type orasStoreFactory struct{}
@@ -89,10 +114,12 @@ type orasStore struct {
 	config             *OrasStoreConf
 	rawConfig          config.StoreConfig
 	localCache         content.Storage
+	blobProvider       blobprovider.BlobProvider
 	authProvider       authprovider.AuthProvider
 	httpClient         *http.Client
 	httpClientInsecure *http.Client
 	createRepository   func(ctx context.Context, store *orasStore, targetRef common.Reference) (registry.Repository, error)
+	connectivity       *connectivityTracker
 }
 
 func init() {
@@ -132,6 +159,18 @@ func createBaseStore(version string, storeConfig config.StorePluginConfig) (*ora
 		return nil, re.ErrorCodeConfigInvalid.NewError(re.ReferrerStore, "", re.EmptyLink, err, "failed to parse oras store configuration", re.HideStackTrace)
 	}
 
+	if err := validateContentEncodings(conf.ContentEncodings); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.ReferrerStore, "", re.EmptyLink, err, "invalid oras store configuration", re.HideStackTrace)
+	}
+
+	if err := validateReferrersQueryMode(conf.ReferrersQueryMode); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.ReferrerStore, "", re.EmptyLink, err, "invalid oras store configuration", re.HideStackTrace)
+	}
+
+	if err := validateMirrors(conf.Mirrors); err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.ReferrerStore, "", re.EmptyLink, err, "invalid oras store configuration", re.HideStackTrace)
+	}
+
 	authenticationProvider, err := authprovider.CreateAuthProviderFromConfig(conf.AuthProvider)
 	if err != nil {
 		return nil, re.ErrorCodePluginInitFailure.NewError(re.ReferrerStore, "", re.EmptyLink, err, "failed to create auth provider from configuration", re.HideStackTrace)
@@ -142,11 +181,16 @@ func createBaseStore(version string, storeConfig config.StorePluginConfig) (*ora
 		conf.LocalCachePath = paths.Join(homedir.Get(), ratifyconfig.ConfigFileDir, defaultLocalCachePath)
 	}
 
-	localRegistry, err := ocitarget.New(conf.LocalCachePath)
+	localRegistry, err := newLocalCache(context.Background(), conf.LocalCachePath)
 	if err != nil {
 		return nil, re.ErrorCodePluginInitFailure.WithError(err).WithComponentType(re.ReferrerStore).WithDetail(fmt.Sprintf("could not create local oras cache at path: %s", conf.LocalCachePath))
 	}
 
+	blobProvider, err := createBlobProvider(conf.BlobProvider, localRegistry)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.ReferrerStore, "", re.EmptyLink, err, "invalid oras store configuration", re.HideStackTrace)
+	}
+
 	var customPredicate retry.Predicate = func(resp *http.Response, err error) (bool, error) {
 		host := ""
 		if resp != nil {
@@ -173,7 +217,7 @@ func createBaseStore(version string, storeConfig config.StorePluginConfig) (*ora
 	secureTransport.MaxIdleConns = HTTPMaxIdleConns
 	secureTransport.MaxConnsPerHost = HTTPMaxConnsPerHost
 	secureTransport.MaxIdleConnsPerHost = HTTPMaxIdleConnsPerHost
-	secureRetryTransport := retry.NewTransport(secureTransport)
+	secureRetryTransport := retry.NewTransport(tracing.NewTransport(newEncodingTransport(secureTransport, conf.ContentEncodings)))
 	secureRetryTransport.Policy = customRetryPolicy
 
 	// define the http client for TLS disabled
@@ -185,16 +229,18 @@ func createBaseStore(version string, storeConfig config.StorePluginConfig) (*ora
 	insecureTransport.TLSClientConfig = &tls.Config{
 		InsecureSkipVerify: true,
 	}
-	insecureRetryTransport := retry.NewTransport(insecureTransport)
+	insecureRetryTransport := retry.NewTransport(tracing.NewTransport(newEncodingTransport(insecureTransport, conf.ContentEncodings)))
 	insecureRetryTransport.Policy = customRetryPolicy
 
 	return &orasStore{config: &conf,
 		rawConfig:          config.StoreConfig{Version: version, Store: storeConfig},
 		localCache:         localRegistry,
+		blobProvider:       blobProvider,
 		authProvider:       authenticationProvider,
 		httpClient:         &http.Client{Transport: secureRetryTransport},
 		httpClientInsecure: &http.Client{Transport: insecureRetryTransport},
-		createRepository:   createDefaultRepository}, nil
+		createRepository:   createDefaultRepository,
+		connectivity:       &connectivityTracker{}}, nil
 }
 
 func (store *orasStore) Name() string {
@@ -205,7 +251,17 @@ func (store *orasStore) GetConfig() *config.StoreConfig {
 	return &store.rawConfig
 }
 
-func (store *orasStore) ListReferrers(ctx context.Context, subjectReference common.Reference, _ []string, _ string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
+func (store *orasStore) ListReferrers(ctx context.Context, subjectReference common.Reference, artifactTypes []string, nextToken string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
+	return store.ListFilteredReferrers(ctx, subjectReference, referrerstore.ReferrerFilter{ArtifactTypes: artifactTypes}, nextToken, subjectDesc)
+}
+
+// ListFilteredReferrers lists the referrers matching the filter. A single
+// artifact type is pushed down to the registry, registries that do not apply
+// it return all referrers, which are filtered by ORAS. The annotations are
+// matched after listing since the Referrers API does not filter by them.
+func (store *orasStore) ListFilteredReferrers(ctx context.Context, subjectReference common.Reference, filter referrerstore.ReferrerFilter, _ string, subjectDesc *ocispecs.SubjectDescriptor) (referrerstore.ListReferrersResult, error) {
+	upstreamReference := subjectReference
+	subjectReference = store.mirrorReference(subjectReference)
 	repository, err := store.createRepository(ctx, store, subjectReference)
 	if err != nil {
 		return referrerstore.ListReferrersResult{}, re.ErrorCodeCreateRepositoryFailure.WithError(err).WithComponentType(re.ReferrerStore)
@@ -216,7 +272,7 @@ func (store *orasStore) ListReferrers(ctx context.Context, subjectReference comm
 	if subjectDesc != nil {
 		resolvedSubjectDesc = subjectDesc
 	} else {
-		if resolvedSubjectDesc, err = store.GetSubjectDescriptor(ctx, subjectReference); err != nil {
+		if resolvedSubjectDesc, err = store.GetSubjectDescriptor(ctx, upstreamReference); err != nil {
 			evictOnError(ctx, err, subjectReference.Original)
 			return referrerstore.ListReferrersResult{}, err
 		}
@@ -224,79 +280,75 @@ func (store *orasStore) ListReferrers(ctx context.Context, subjectReference comm
 
 	// find all referrers referencing subject descriptor
 	artifactTypeFilter := ""
+	if !filter.MatchesAllArtifactTypes() && len(filter.ArtifactTypes) == 1 {
+		artifactTypeFilter = filter.ArtifactTypes[0]
+	}
 	var referrerDescriptors []oci.Descriptor
-	if err := repository.Referrers(ctx, resolvedSubjectDesc.Descriptor, artifactTypeFilter, func(referrers []oci.Descriptor) error {
+	err = repository.Referrers(ctx, resolvedSubjectDesc.Descriptor, artifactTypeFilter, func(referrers []oci.Descriptor) error {
 		referrerDescriptors = append(referrerDescriptors, referrers...)
 		return nil
-	}); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+	})
+	store.connectivity.observe(err)
+	if err != nil && !errors.Is(err, errdef.ErrNotFound) {
 		evictOnError(ctx, err, subjectReference.Original)
 		return referrerstore.ListReferrersResult{}, err
 	}
 
+	// registries migrating to the Referrers API may only list some referrers
+	// with the API while the others are still in the tag schema
+	if store.config.ReferrersQueryMode == referrersQueryModeMerge {
+		tagSchemaReferrers, err := listReferrersByTagSchema(ctx, subjectReference, repository, resolvedSubjectDesc.Descriptor, artifactTypeFilter)
+		if err != nil {
+			evictOnError(ctx, err, subjectReference.Original)
+			return referrerstore.ListReferrersResult{}, re.ErrorCodeRepositoryOperationFailure.WithError(err).WithComponentType(re.ReferrerStore).WithDetail("failed to list referrers from the tag schema")
+		}
+		referrerDescriptors = mergeReferrers(ctx, referrerDescriptors, tagSchemaReferrers)
+	}
+
 	// convert artifact descriptors to oci descriptor with artifact type
 	referrers := []ocispecs.ReferenceDescriptor{}
 	for _, referrer := range referrerDescriptors {
 		referrers = append(referrers, OciDescriptorToReferenceDescriptor(referrer))
 	}
 
-	if store.config.CosignEnabled {
-		// add cosign descriptor if exists
-		cosignReferences, err := getCosignReferences(ctx, subjectReference, repository)
+	if store.config.CosignEnabled && matchesCosignArtifactTypes(filter) {
+		// cosign references published as OCI 1.1 referrers are already listed,
+		// add the ones attached with the tag convention
+		cosignReferences, err := getCosignReferences(ctx, subjectReference, repository, filter)
 		if err != nil {
 			return referrerstore.ListReferrersResult{}, err
 		}
 
 		if cosignReferences != nil {
-			referrers = append(referrers, *cosignReferences...)
+			referrers = mergeCosignReferences(referrers, *cosignReferences)
 		}
 	}
 
-	return referrerstore.ListReferrersResult{Referrers: referrers}, nil
+	return referrerstore.ListReferrersResult{Referrers: filter.Apply(referrers)}, nil
 }
 
 func (store *orasStore) GetBlobContent(ctx context.Context, subjectReference common.Reference, digest digest.Digest) ([]byte, error) {
-	var err error
-	repository, err := store.createRepository(ctx, store, subjectReference)
-	if err != nil {
-		return nil, err
-	}
-
-	// create a dummy Descriptor to check the local store cache
-	blobDescriptor := oci.Descriptor{
-		Digest: digest,
-		Size:   0, // dummy size value
-	}
-
-	// check if blob exists in local ORAS cache
-	isCached, err := store.localCache.Exists(ctx, blobDescriptor)
-	if err != nil {
-		return nil, err
-	}
-	metrics.ReportBlobCacheCount(ctx, isCached)
-
-	if !isCached {
-		// generate the reference path with digest
-		ref := fmt.Sprintf("%s@%s", subjectReference.Path, digest)
-
-		// fetch blob content from remote repository
-		blobDesc, rc, err := repository.Blobs().FetchReference(ctx, ref)
+	subjectReference = store.mirrorReference(subjectReference)
+	return blobprovider.ReadThrough(ctx, store.blobProvider, digest, func(ctx context.Context) ([]byte, error) {
+		repository, err := store.createRepository(ctx, store, subjectReference)
 		if err != nil {
-			evictOnError(ctx, err, subjectReference.Original)
 			return nil, err
 		}
 
-		// push fetched content to local ORAS cache
-		orasExistsExpectedError := fmt.Errorf("%s: %s: %w", blobDesc.Digest, blobDesc.MediaType, errdef.ErrAlreadyExists)
-		err = store.localCache.Push(ctx, blobDesc, rc)
-		if err != nil && err.Error() != orasExistsExpectedError.Error() {
+		// fetch blob content from remote repository
+		blobDesc, rc, err := repository.Blobs().FetchReference(ctx, fmt.Sprintf("%s@%s", subjectReference.Path, digest))
+		store.connectivity.observe(err)
+		if err != nil {
+			evictOnError(ctx, err, subjectReference.Original)
 			return nil, err
 		}
-	}
-
-	return store.getRawContentFromCache(ctx, blobDescriptor)
+		defer rc.Close()
+		return content.ReadAll(rc, blobDesc)
+	})
 }
 
 func (store *orasStore) GetReferenceManifest(ctx context.Context, subjectReference common.Reference, referenceDesc ocispecs.ReferenceDescriptor) (ocispecs.ReferenceManifest, error) {
+	subjectReference = store.mirrorReference(subjectReference)
 	repository, err := store.createRepository(ctx, store, subjectReference)
 	if err != nil {
 		return ocispecs.ReferenceManifest{}, re.ErrorCodeCreateRepositoryFailure.NewError(re.ReferrerStore, storeName, re.EmptyLink, err, nil, re.HideStackTrace)
@@ -307,11 +359,20 @@ func (store *orasStore) GetReferenceManifest(ctx context.Context, subjectReferen
 	if err != nil {
 		return ocispecs.ReferenceManifest{}, err
 	}
+	if isCached {
+		manifestBytes, err = store.getRawContentFromCache(ctx, referenceDesc.Descriptor)
+		if err != nil {
+			// corrupted content is evicted from the local cache, fetch it again
+			logger.GetLogger(ctx, logOpt).Warnf("failed to read manifest %s from local cache: %v", referenceDesc.Digest, err)
+			isCached = false
+		}
+	}
 	metrics.ReportBlobCacheCount(ctx, isCached)
 
 	if !isCached {
 		// fetch manifest content from repository
 		manifestReader, err := repository.Fetch(ctx, referenceDesc.Descriptor)
+		store.connectivity.observe(err)
 		if err != nil {
 			evictOnError(ctx, err, subjectReference.Original)
 			return ocispecs.ReferenceManifest{}, re.ErrorCodeRepositoryOperationFailure.NewError(re.ReferrerStore, storeName, re.EmptyLink, err, nil, re.HideStackTrace)
@@ -321,6 +382,11 @@ func (store *orasStore) GetReferenceManifest(ctx context.Context, subjectReferen
 		if err != nil {
 			return ocispecs.ReferenceManifest{}, re.ErrorCodeManifestInvalid.WithError(err).WithPluginName(storeName).WithComponentType(re.ReferrerStore)
 		}
+		// the manifest must be the one identified by the reference digest,
+		// otherwise verifiers would parse content the signatures do not cover
+		if err := verifyContentDigest(referenceDesc.Digest, manifestBytes); err != nil {
+			return ocispecs.ReferenceManifest{}, re.ErrorCodeManifestInvalid.WithError(err).WithPluginName(storeName).WithComponentType(re.ReferrerStore)
+		}
 
 		// push fetched manifest to local ORAS cache
 		orasExistsExpectedError := fmt.Errorf("%s: %s: %w", referenceDesc.Descriptor.Digest, referenceDesc.Descriptor.MediaType, errdef.ErrAlreadyExists)
@@ -328,17 +394,12 @@ func (store *orasStore) GetReferenceManifest(ctx context.Context, subjectReferen
 		if err != nil && err.Error() != orasExistsExpectedError.Error() {
 			return ocispecs.ReferenceManifest{}, err
 		}
-	} else {
-		manifestBytes, err = store.getRawContentFromCache(ctx, referenceDesc.Descriptor)
-		if err != nil {
-			return ocispecs.ReferenceManifest{}, err
-		}
 	}
 
 	referenceManifest := ocispecs.ReferenceManifest{}
 
 	// marshal manifest bytes into reference manifest descriptor
-	if referenceDesc.Descriptor.MediaType == oci.MediaTypeImageManifest {
+	if referenceDesc.Descriptor.MediaType == oci.MediaTypeImageManifest || referenceDesc.Descriptor.MediaType == commonutils.MediaTypeDockerManifest {
 		var imageManifest oci.Manifest
 		if err := json.Unmarshal(manifestBytes, &imageManifest); err != nil {
 			return ocispecs.ReferenceManifest{}, re.ErrorCodeDataDecodingFailure.WithError(err).WithComponentType(re.ReferrerStore)
@@ -355,13 +416,39 @@ func (store *orasStore) GetReferenceManifest(ctx context.Context, subjectReferen
 	return referenceManifest, nil
 }
 
+// GetManifestContent returns the content of the manifest described by the
+// descriptor, the content is verified against the size and digest of the descriptor.
+func (store *orasStore) GetManifestContent(ctx context.Context, subjectReference common.Reference, desc oci.Descriptor) ([]byte, error) {
+	subjectReference = store.mirrorReference(subjectReference)
+	repository, err := store.createRepository(ctx, store, subjectReference)
+	if err != nil {
+		return nil, re.ErrorCodeCreateRepositoryFailure.NewError(re.ReferrerStore, storeName, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+
+	rc, err := repository.Fetch(ctx, desc)
+	store.connectivity.observe(err)
+	if err != nil {
+		evictOnError(ctx, err, subjectReference.Original)
+		return nil, re.ErrorCodeRepositoryOperationFailure.NewError(re.ReferrerStore, storeName, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	defer rc.Close()
+
+	manifestBytes, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, re.ErrorCodeManifestInvalid.WithError(err).WithPluginName(storeName).WithComponentType(re.ReferrerStore)
+	}
+	return manifestBytes, nil
+}
+
 func (store *orasStore) GetSubjectDescriptor(ctx context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	subjectReference = store.mirrorReference(subjectReference)
 	repository, err := store.createRepository(ctx, store, subjectReference)
 	if err != nil {
 		return nil, re.ErrorCodeCreateRepositoryFailure.WithError(err).WithComponentType(re.ReferrerStore).WithPluginName(storeName)
 	}
 
 	desc, err := repository.Resolve(ctx, subjectReference.Original)
+	store.connectivity.observe(err)
 	if err != nil {
 		evictOnError(ctx, err, subjectReference.Original)
 		return nil, re.ErrorCodeRepositoryOperationFailure.WithError(err).WithPluginName(storeName)
@@ -471,11 +558,31 @@ func createDefaultRepository(ctx context.Context, store *orasStore, targetRef co
 	return repository, nil
 }
 
+// createBlobProvider creates the provider blobs are cached in, the local ORAS
+// cache is used by default.
+func createBlobProvider(providerType string, localCache content.Storage) (blobprovider.BlobProvider, error) {
+	switch providerType {
+	case "", blobProviderDisk:
+		return blobprovider.NewStorageProvider(localCache), nil
+	case blobProviderMemory:
+		return blobprovider.NewMemoryProvider(blobprovider.DefaultMemoryMaxBytes), nil
+	case blobProviderCache:
+		cacheProvider := cache.GetCacheProvider()
+		if cacheProvider == nil {
+			return nil, fmt.Errorf("blob provider %s requires the cache to be enabled", blobProviderCache)
+		}
+		return blobprovider.NewCacheProvider(cacheProvider, blobCacheTTL), nil
+	default:
+		return nil, fmt.Errorf("blob provider must be %s, %s or %s, got %s", blobProviderDisk, blobProviderMemory, blobProviderCache, providerType)
+	}
+}
+
 func (store *orasStore) getRawContentFromCache(ctx context.Context, descriptor oci.Descriptor) ([]byte, error) {
 	reader, err := store.localCache.Fetch(ctx, descriptor)
 	if err != nil {
 		return nil, err
 	}
+	defer reader.Close()
 
 	buf, err := io.ReadAll(reader)
 	if err != nil {
@@ -483,3 +590,14 @@ func (store *orasStore) getRawContentFromCache(ctx context.Context, descriptor o
 	}
 	return buf, nil
 }
+
+// verifyContentDigest checks that content matches the expected digest.
+func verifyContentDigest(expected digest.Digest, content []byte) error {
+	if err := expected.Validate(); err != nil {
+		return err
+	}
+	if actual := expected.Algorithm().FromBytes(content); actual != expected {
+		return fmt.Errorf("content digest %s does not match expected digest %s", actual, expected)
+	}
+	return nil
+}
@@ -29,11 +29,15 @@ import (
 	"testing"
 	"time"
 
+	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/pkg/cache"
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/blobprovider"
 	"github.com/deislabs/ratify/pkg/referrerstore/config"
 	"github.com/deislabs/ratify/pkg/referrerstore/oras/mocks"
+	"github.com/deislabs/ratify/pkg/testregistry"
 	"github.com/opencontainers/go-digest"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry"
@@ -130,6 +134,102 @@ func TestORASListReferrers_SubjectDesc(t *testing.T) {
 	}
 }
 
+// TestORASListFilteredReferrers tests that a single artifact type is pushed down to the registry and that the filter is applied to the referrers
+func TestORASListFilteredReferrers(t *testing.T) {
+	conf := config.StorePluginConfig{
+		"name":          "oras",
+		"cosignEnabled": false,
+	}
+	ctx := context.Background()
+	subjectDesc := ocispecs.SubjectDescriptor{
+		Descriptor: oci.Descriptor{
+			Digest: digest.FromString("testDigest"),
+		},
+	}
+	signature := oci.Descriptor{
+		Digest:       digest.FromString("signature"),
+		ArtifactType: "application/vnd.cncf.notary.signature",
+		Annotations:  map[string]string{"io.ratify.team": "a"},
+	}
+	otherSignature := oci.Descriptor{
+		Digest:       digest.FromString("otherSignature"),
+		ArtifactType: "application/vnd.cncf.notary.signature",
+		Annotations:  map[string]string{"io.ratify.team": "b"},
+	}
+	sbom := oci.Descriptor{
+		Digest:       digest.FromString("sbom"),
+		ArtifactType: "application/spdx+json",
+	}
+	testCases := []struct {
+		name              string
+		filter            referrerstore.ReferrerFilter
+		expectedFilter    string
+		expectedReferrers []digest.Digest
+	}{
+		{
+			name:              "no filter",
+			expectedReferrers: []digest.Digest{signature.Digest, otherSignature.Digest, sbom.Digest},
+		},
+		{
+			name:              "wildcard artifact type",
+			filter:            referrerstore.ReferrerFilter{ArtifactTypes: []string{"*"}},
+			expectedReferrers: []digest.Digest{signature.Digest, otherSignature.Digest, sbom.Digest},
+		},
+		{
+			name:              "single artifact type",
+			filter:            referrerstore.ReferrerFilter{ArtifactTypes: []string{"application/spdx+json"}},
+			expectedFilter:    "application/spdx+json",
+			expectedReferrers: []digest.Digest{sbom.Digest},
+		},
+		{
+			name:              "multiple artifact types",
+			filter:            referrerstore.ReferrerFilter{ArtifactTypes: []string{"application/spdx+json", "application/vnd.cncf.notary.signature"}},
+			expectedReferrers: []digest.Digest{signature.Digest, otherSignature.Digest, sbom.Digest},
+		},
+		{
+			name:              "annotation",
+			filter:            referrerstore.ReferrerFilter{ArtifactTypes: []string{"application/vnd.cncf.notary.signature"}, Annotations: map[string]string{"io.ratify.team": "b"}},
+			expectedFilter:    "application/vnd.cncf.notary.signature",
+			expectedReferrers: []digest.Digest{otherSignature.Digest},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := createBaseStore("1.0.0", conf)
+			if err != nil {
+				t.Fatalf("failed to create oras store: %v", err)
+			}
+			artifactTypeFilters := []string{}
+			testRepo := mocks.TestRepository{
+				ReferrersList:       []oci.Descriptor{signature, otherSignature, sbom},
+				ArtifactTypeFilters: &artifactTypeFilters,
+			}
+			store.createRepository = func(ctx context.Context, store *orasStore, targetRef common.Reference) (registry.Repository, error) {
+				return testRepo, nil
+			}
+			inputRef := common.Reference{
+				Original: inputOriginalPath,
+				Digest:   subjectDesc.Digest,
+			}
+			result, err := store.ListFilteredReferrers(ctx, inputRef, tc.filter, "", &subjectDesc)
+			if err != nil {
+				t.Fatalf("failed to list referrers: %v", err)
+			}
+			if len(artifactTypeFilters) != 1 || artifactTypeFilters[0] != tc.expectedFilter {
+				t.Fatalf("expected artifact type %q pushed down, got %v", tc.expectedFilter, artifactTypeFilters)
+			}
+			if len(result.Referrers) != len(tc.expectedReferrers) {
+				t.Fatalf("expected %d referrers, got %d", len(tc.expectedReferrers), len(result.Referrers))
+			}
+			for i, expected := range tc.expectedReferrers {
+				if result.Referrers[i].Digest != expected {
+					t.Fatalf("expected referrer %s at index %d, got %s", expected, i, result.Referrers[i].Digest)
+				}
+			}
+		})
+	}
+}
+
 func TestORASListReferrers_NoSubjectDesc(t *testing.T) {
 	conf := config.StorePluginConfig{
 		"name":          "oras",
@@ -259,7 +359,6 @@ func TestORASGetReferenceManifest_NotCachedDesc(t *testing.T) {
 	}
 	ctx := context.Background()
 	firstDigest := digest.FromString("testDigest")
-	artifactDigest := digest.FromString("testArtifactDigest")
 	artifactDigestNotCached := digest.FromString("testArtifactDigestNotCached")
 	expectedReferenceMediatype := "application/vnd.oci.image.manifest.right.v1+json"
 	wrongReferenceMediatype := "application/vnd.oci.image.manifest.wrong.v1+json"
@@ -285,6 +384,7 @@ func TestORASGetReferenceManifest_NotCachedDesc(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to marshal not cached manifest: %v", err)
 	}
+	artifactDigest := digest.FromBytes(manifestNotCachedBytes)
 	testRepo := mocks.TestRepository{
 		FetchMap: map[digest.Digest]io.ReadCloser{
 			artifactDigest: io.NopCloser(bytes.NewReader(manifestNotCachedBytes)),
@@ -316,6 +416,47 @@ func TestORASGetReferenceManifest_NotCachedDesc(t *testing.T) {
 	}
 }
 
+// TestORASGetReferenceManifest_DigestMismatch tests that a fetched manifest not matching the reference digest is rejected
+func TestORASGetReferenceManifest_DigestMismatch(t *testing.T) {
+	conf := config.StorePluginConfig{
+		"name": "oras",
+	}
+	ctx := context.Background()
+	artifactDigest := digest.FromString("testArtifactDigest")
+	store, err := createBaseStore("1.0.0", conf)
+	if err != nil {
+		t.Fatalf("failed to create oras store: %v", err)
+	}
+	manifestBytes, err := json.Marshal(oci.Manifest{MediaType: oci.MediaTypeImageManifest})
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	testRepo := mocks.TestRepository{
+		FetchMap: map[digest.Digest]io.ReadCloser{
+			artifactDigest: io.NopCloser(bytes.NewReader(manifestBytes)),
+		},
+	}
+	store.createRepository = func(ctx context.Context, store *orasStore, targetRef common.Reference) (registry.Repository, error) {
+		return testRepo, nil
+	}
+	store.localCache = mocks.TestStorage{
+		ExistsMap: map[digest.Digest]io.Reader{},
+	}
+	inputRef := common.Reference{
+		Original: inputOriginalPath,
+		Digest:   digest.FromString("testDigest"),
+	}
+	_, err = store.GetReferenceManifest(ctx, inputRef, ocispecs.ReferenceDescriptor{
+		Descriptor: oci.Descriptor{
+			MediaType: oci.MediaTypeImageManifest,
+			Digest:    artifactDigest,
+		},
+	})
+	if re.CodeOf(err, re.ErrorCodeUnknown) != re.ErrorCodeManifestInvalid {
+		t.Fatalf("expected error code %s, got %v", re.ErrorCodeManifestInvalid, err)
+	}
+}
+
 // TestORASGetBlobContent_CachedDesc tests that the blob content is fetched from the cache if it is cached
 func TestORASGetBlobContent_CachedDesc(t *testing.T) {
 	conf := config.StorePluginConfig{
@@ -323,8 +464,8 @@ func TestORASGetBlobContent_CachedDesc(t *testing.T) {
 	}
 	ctx := context.Background()
 	firstDigest := digest.FromString("testDigest")
-	blobDigest := digest.FromString("testBlobDigest")
 	expectedContent := []byte("test content")
+	blobDigest := digest.FromBytes(expectedContent)
 	inputRef := common.Reference{
 		Original: inputOriginalPath,
 		Path:     inputOriginalPath,
@@ -340,6 +481,7 @@ func TestORASGetBlobContent_CachedDesc(t *testing.T) {
 				fmt.Sprintf("%s@%s", inputRef.Path, blobDigest.String()): {
 					Descriptor: oci.Descriptor{
 						Digest: blobDigest,
+						Size:   int64(len(expectedContent)),
 					},
 					Reader: io.NopCloser(bytes.NewReader(expectedContent)),
 				},
@@ -349,11 +491,11 @@ func TestORASGetBlobContent_CachedDesc(t *testing.T) {
 	store.createRepository = func(ctx context.Context, store *orasStore, targetRef common.Reference) (registry.Repository, error) {
 		return testRepo, nil
 	}
-	store.localCache = mocks.TestStorage{
+	store.blobProvider = blobprovider.NewStorageProvider(mocks.TestStorage{
 		ExistsMap: map[digest.Digest]io.Reader{
 			blobDigest: bytes.NewReader(expectedContent),
 		},
-	}
+	})
 	content, err := store.GetBlobContent(ctx, inputRef, blobDigest)
 	if err != nil {
 		t.Fatalf("failed to get blob content: %v", err)
@@ -370,8 +512,8 @@ func TestORASGetBlobContent_NotCachedDesc(t *testing.T) {
 	}
 	ctx := context.Background()
 	firstDigest := digest.FromString("testDigest")
-	blobDigest := digest.FromString("testBlobDigest")
 	expectedContent := []byte("test content")
+	blobDigest := digest.FromBytes(expectedContent)
 	inputRef := common.Reference{
 		Original: inputOriginalPath,
 		Path:     inputOriginalPath,
@@ -387,6 +529,7 @@ func TestORASGetBlobContent_NotCachedDesc(t *testing.T) {
 				fmt.Sprintf("%s@%s", inputRef.Path, blobDigest.String()): {
 					Descriptor: oci.Descriptor{
 						Digest: blobDigest,
+						Size:   int64(len(expectedContent)),
 					},
 					Reader: io.NopCloser(bytes.NewReader(expectedContent)),
 				},
@@ -535,3 +678,230 @@ func TestORASCreate_CacheProvider_Nil(t *testing.T) {
 		t.Fatalf("expected oras store")
 	}
 }
+
+// TestORASStore_TestRegistry tests the oras store against an in-memory registry.
+func TestORASStore_TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	reg := testregistry.New()
+	defer reg.Close()
+
+	imageDesc, err := reg.PushImage(ctx, "net-monitor", "v1", []byte("layer"))
+	if err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	signature := []byte("signature")
+	signatureDesc, err := reg.PushReferrer(ctx, "net-monitor", imageDesc, "application/vnd.cncf.notary.signature", testregistry.Blob{MediaType: "application/jose+json", Content: signature})
+	if err != nil {
+		t.Fatalf("failed to push referrer: %v", err)
+	}
+
+	store, err := createBaseStore("1.0.0", config.StorePluginConfig{
+		"name":    "oras",
+		"useHttp": true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create oras store: %v", err)
+	}
+	subjectRef := common.Reference{
+		Original: reg.Reference("net-monitor", "v1"),
+		Path:     reg.Host + "/net-monitor",
+		Tag:      "v1",
+	}
+
+	subjectDesc, err := store.GetSubjectDescriptor(ctx, subjectRef)
+	if err != nil {
+		t.Fatalf("failed to get subject descriptor: %v", err)
+	}
+	if subjectDesc.Digest != imageDesc.Digest {
+		t.Fatalf("expected subject digest %s, got %s", imageDesc.Digest, subjectDesc.Digest)
+	}
+	subjectRef.Digest = subjectDesc.Digest
+
+	referrers, err := store.ListReferrers(ctx, subjectRef, nil, "", subjectDesc)
+	if err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	if len(referrers.Referrers) != 1 || referrers.Referrers[0].Digest != signatureDesc.Digest {
+		t.Fatalf("expected referrer %s, got %v", signatureDesc.Digest, referrers.Referrers)
+	}
+
+	manifest, err := store.GetReferenceManifest(ctx, subjectRef, referrers.Referrers[0])
+	if err != nil {
+		t.Fatalf("failed to get reference manifest: %v", err)
+	}
+	if manifest.ArtifactType != "application/vnd.cncf.notary.signature" || len(manifest.Blobs) != 1 {
+		t.Fatalf("unexpected reference manifest %+v", manifest)
+	}
+
+	blob, err := store.GetBlobContent(ctx, subjectRef, manifest.Blobs[0].Digest)
+	if err != nil {
+		t.Fatalf("failed to get blob content: %v", err)
+	}
+	if !bytes.Equal(blob, signature) {
+		t.Fatalf("expected blob %s, got %s", signature, blob)
+	}
+}
+
+// TestORASListReferrers_MergeTagSchema tests that referrers only listed in the
+// tag schema are merged with the referrers of the Referrers API
+func TestORASListReferrers_MergeTagSchema(t *testing.T) {
+	subjectDigest := digest.FromString("testDigest")
+	apiReferrer := oci.Descriptor{Digest: digest.FromString("apiArtifact"), ArtifactType: "application/vnd.cncf.notary.signature"}
+	tagSchemaReferrer := oci.Descriptor{Digest: digest.FromString("tagSchemaArtifact"), ArtifactType: "application/spdx+json"}
+	index, err := json.Marshal(oci.Index{Manifests: []oci.Descriptor{apiReferrer, tagSchemaReferrer}})
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	indexDesc := oci.Descriptor{MediaType: oci.MediaTypeImageIndex, Digest: digest.FromBytes(index), Size: int64(len(index))}
+	subjectDesc := ocispecs.SubjectDescriptor{Descriptor: oci.Descriptor{Digest: subjectDigest}}
+	inputRef := common.Reference{
+		Original: inputOriginalPath,
+		Path:     "localhost:5000/net-monitor",
+		Digest:   subjectDigest,
+	}
+
+	tests := []struct {
+		name              string
+		queryMode         string
+		expectedReferrers []digest.Digest
+	}{
+		{
+			name:              "auto mode lists referrers with the Referrers API",
+			queryMode:         referrersQueryModeAuto,
+			expectedReferrers: []digest.Digest{apiReferrer.Digest},
+		},
+		{
+			name:              "merge mode adds referrers of the tag schema",
+			queryMode:         referrersQueryModeMerge,
+			expectedReferrers: []digest.Digest{apiReferrer.Digest, tagSchemaReferrer.Digest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := createBaseStore("1.0.0", config.StorePluginConfig{
+				"name":               "oras",
+				"referrersQueryMode": tt.queryMode,
+			})
+			if err != nil {
+				t.Fatalf("failed to create oras store: %v", err)
+			}
+			testRepo := mocks.TestRepository{
+				ResolveMap: map[string]oci.Descriptor{
+					"localhost:5000/net-monitor:" + subjectDigest.Algorithm().String() + "-" + subjectDigest.Encoded(): indexDesc,
+				},
+				ReferrersList: []oci.Descriptor{apiReferrer},
+				FetchMap: map[digest.Digest]io.ReadCloser{
+					indexDesc.Digest: io.NopCloser(bytes.NewReader(index)),
+				},
+			}
+			store.createRepository = func(_ context.Context, _ *orasStore, _ common.Reference) (registry.Repository, error) {
+				return testRepo, nil
+			}
+
+			result, err := store.ListReferrers(context.Background(), inputRef, nil, "", &subjectDesc)
+			if err != nil {
+				t.Fatalf("failed to list referrers: %v", err)
+			}
+			if len(result.Referrers) != len(tt.expectedReferrers) {
+				t.Fatalf("expected %d referrers, got %d", len(tt.expectedReferrers), len(result.Referrers))
+			}
+			for i, expected := range tt.expectedReferrers {
+				if result.Referrers[i].Digest != expected {
+					t.Fatalf("expected referrer %d to be %s, got %s", i, expected, result.Referrers[i].Digest)
+				}
+			}
+		})
+	}
+}
+
+func TestORASListReferrers_Cosign(t *testing.T) {
+	subjectDigest := digest.FromString("testDigest")
+	subjectDesc := ocispecs.SubjectDescriptor{Descriptor: oci.Descriptor{Digest: subjectDigest}}
+	inputRef := common.Reference{
+		Original: inputOriginalPath,
+		Path:     "localhost:5000/net-monitor",
+		Digest:   subjectDigest,
+	}
+	signatureTag := "localhost:5000/net-monitor:" + subjectDigest.Algorithm().String() + "-" + subjectDigest.Encoded() + CosignSignatureTagSuffix
+	attestationTag := "localhost:5000/net-monitor:" + subjectDigest.Algorithm().String() + "-" + subjectDigest.Encoded() + CosignAttestationTagSuffix
+	referrerSignature := oci.Descriptor{Digest: digest.FromString("referrerSignature"), ArtifactType: CosignArtifactType}
+	taggedSignature := oci.Descriptor{Digest: digest.FromString("taggedSignature")}
+	taggedAttestation := oci.Descriptor{Digest: digest.FromString("taggedAttestation")}
+
+	tests := []struct {
+		name              string
+		cosignEnabled     bool
+		referrers         []oci.Descriptor
+		tags              map[string]oci.Descriptor
+		filter            referrerstore.ReferrerFilter
+		expectedReferrers []digest.Digest
+	}{
+		{
+			name:              "cosign disabled lists referrers only",
+			referrers:         []oci.Descriptor{referrerSignature},
+			tags:              map[string]oci.Descriptor{signatureTag: taggedSignature},
+			expectedReferrers: []digest.Digest{referrerSignature.Digest},
+		},
+		{
+			name:              "referrers and tag convention are merged",
+			cosignEnabled:     true,
+			referrers:         []oci.Descriptor{referrerSignature},
+			tags:              map[string]oci.Descriptor{signatureTag: taggedSignature, attestationTag: taggedAttestation},
+			expectedReferrers: []digest.Digest{referrerSignature.Digest, taggedSignature.Digest, taggedAttestation.Digest},
+		},
+		{
+			name:              "references listed by both are not duplicated",
+			cosignEnabled:     true,
+			referrers:         []oci.Descriptor{referrerSignature},
+			tags:              map[string]oci.Descriptor{signatureTag: referrerSignature},
+			expectedReferrers: []digest.Digest{referrerSignature.Digest},
+		},
+		{
+			name:              "filter selects cosign signatures",
+			cosignEnabled:     true,
+			referrers:         []oci.Descriptor{referrerSignature},
+			tags:              map[string]oci.Descriptor{signatureTag: taggedSignature, attestationTag: taggedAttestation},
+			filter:            referrerstore.ReferrerFilter{ArtifactTypes: []string{CosignArtifactType}},
+			expectedReferrers: []digest.Digest{referrerSignature.Digest, taggedSignature.Digest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := createBaseStore("1.0.0", config.StorePluginConfig{
+				"name":          "oras",
+				"cosignEnabled": tt.cosignEnabled,
+			})
+			if err != nil {
+				t.Fatalf("failed to create oras store: %v", err)
+			}
+			testRepo := mocks.TestRepository{
+				ResolveMap:    tt.tags,
+				ReferrersList: tt.referrers,
+			}
+			store.createRepository = func(_ context.Context, _ *orasStore, _ common.Reference) (registry.Repository, error) {
+				return testRepo, nil
+			}
+
+			result, err := store.ListFilteredReferrers(context.Background(), inputRef, tt.filter, "", &subjectDesc)
+			if err != nil {
+				t.Fatalf("failed to list referrers: %v", err)
+			}
+			if len(result.Referrers) != len(tt.expectedReferrers) {
+				t.Fatalf("expected %d referrers, got %d", len(tt.expectedReferrers), len(result.Referrers))
+			}
+			for i, expected := range tt.expectedReferrers {
+				if result.Referrers[i].Digest != expected {
+					t.Fatalf("expected referrer %d to be %s, got %s", i, expected, result.Referrers[i].Digest)
+				}
+			}
+		})
+	}
+}
+
+func TestORASCreate_InvalidReferrersQueryMode(t *testing.T) {
+	if _, err := createBaseStore("1.0.0", config.StorePluginConfig{"name": "oras", "referrersQueryMode": "tags"}); err == nil {
+		t.Fatalf("expected error for unsupported referrers query mode")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/metrics"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// referrersQueryModeAuto lists referrers with the Referrers API, the tag
+	// schema is only queried if the registry does not support the API
+	referrersQueryModeAuto = "auto"
+	// referrersQueryModeMerge lists referrers with both the Referrers API and
+	// the tag schema and merges the results, for registries migrating between
+	// them
+	referrersQueryModeMerge = "merge"
+
+	// maxTagSchemaIndexSize limits the size of the referrers index fetched
+	// from the tag schema
+	maxTagSchemaIndexSize = 4 * 1024 * 1024
+)
+
+// validateReferrersQueryMode returns an error if the mode is not supported.
+func validateReferrersQueryMode(mode string) error {
+	switch mode {
+	case "", referrersQueryModeAuto, referrersQueryModeMerge:
+		return nil
+	}
+	return fmt.Errorf("unsupported referrers query mode %s, supported modes are %s and %s", mode, referrersQueryModeAuto, referrersQueryModeMerge)
+}
+
+// listReferrersByTagSchema lists the referrers in the referrers index tagged
+// with the digest of the subject, e.g. sha256-d34db33f, as defined by the
+// fallback of the OCI distribution spec.
+func listReferrersByTagSchema(ctx context.Context, subjectReference common.Reference, repository registry.Repository, subjectDesc oci.Descriptor, artifactType string) ([]oci.Descriptor, error) {
+	tag := strings.ReplaceAll(subjectDesc.Digest.String(), ":", "-")
+	indexDesc, err := repository.Resolve(ctx, fmt.Sprintf("%s:%s", subjectReference.Path, tag))
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if indexDesc.Size > maxTagSchemaIndexSize {
+		return nil, fmt.Errorf("referrers index %s of size %d exceeds the maximum size of %d bytes", indexDesc.Digest, indexDesc.Size, maxTagSchemaIndexSize)
+	}
+	indexBytes, err := content.FetchAll(ctx, repository, indexDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	var index oci.Index
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse referrers index %s: %w", indexDesc.Digest, err)
+	}
+	referrers := make([]oci.Descriptor, 0, len(index.Manifests))
+	for _, referrer := range index.Manifests {
+		if artifactType == "" || referrer.ArtifactType == artifactType {
+			referrers = append(referrers, referrer)
+		}
+	}
+	return referrers, nil
+}
+
+// mergeReferrers returns the referrers listed by the Referrers API followed by
+// the referrers only listed by the tag schema, and reports which of them found
+// referrers.
+func mergeReferrers(ctx context.Context, apiReferrers, tagSchemaReferrers []oci.Descriptor) []oci.Descriptor {
+	source := "none"
+	switch {
+	case len(apiReferrers) > 0 && len(tagSchemaReferrers) > 0:
+		source = "both"
+	case len(apiReferrers) > 0:
+		source = "api"
+	case len(tagSchemaReferrers) > 0:
+		source = "tag_schema"
+	}
+	metrics.ReportReferrersSource(ctx, source)
+
+	merged := append([]oci.Descriptor{}, apiReferrers...)
+	listed := make(map[string]struct{}, len(apiReferrers))
+	for _, referrer := range apiReferrers {
+		listed[referrer.Digest.String()] = struct{}{}
+	}
+	for _, referrer := range tagSchemaReferrers {
+		if _, ok := listed[referrer.Digest.String()]; !ok {
+			listed[referrer.Digest.String()] = struct{}{}
+			merged = append(merged, referrer)
+		}
+	}
+	return merged
+}
@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/deislabs/ratify/pkg/common"
@@ -51,7 +50,7 @@ func NewStore(version string, storeConfig config.StorePluginConfig, pluginPaths
 		version:   version,
 		path:      pluginPaths,
 		rawConfig: storeConfig,
-		executor:  &pluginCommon.DefaultExecutor{Stderr: os.Stderr},
+		executor:  pluginCommon.NewExecutor(),
 	}, nil
 }
 
@@ -16,6 +16,8 @@ limitations under the License.
 package skel
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -52,10 +54,31 @@ type CmdArgs struct {
 	Args       string
 	subjectRef common.Reference
 	StdinData  []byte
+	// TraceID is the trace ID of the Ratify request the plugin is invoked
+	// for, empty if the plugin is not invoked for a request.
+	TraceID string
 }
 
 // PluginMain is the core "main" for a plugin which includes error handling.
+// When Ratify starts the plugin as a gRPC server, the plugin keeps running and
+// serves every command over gRPC.
 func PluginMain(name, version string, listReferrers ListReferrers, getBlobContent GetBlobContent, getRefManifest GetReferenceManifest, getSubDesc GetSubjectDescriptor, supportedVersions []string) {
+	if plugin.IsGRPCServerRequested(os.Getenv) {
+		if err := plugin.ServeGRPC(func(_ context.Context, getEnviron func(string) string, stdin []byte) ([]byte, *plugin.Error) {
+			stdout := &bytes.Buffer{}
+			e := (&pcontext{
+				GetEnviron: getEnviron,
+				Stdin:      bytes.NewReader(stdin),
+				Stdout:     stdout,
+				Stderr:     os.Stderr,
+			}).pluginMainCore(name, version, listReferrers, getBlobContent, getRefManifest, getSubDesc, supportedVersions)
+			return stdout.Bytes(), e
+		}, os.Stdin, os.Stdout); err != nil {
+			log.Fatal("Error serving plugin over gRPC: ", err)
+		}
+		return
+	}
+
 	if e := (&pcontext{
 		GetEnviron: os.Getenv,
 		Stdin:      os.Stdin,
@@ -269,6 +292,7 @@ func (c *pcontext) getCmdArgsFromEnv() (string, *CmdArgs, *plugin.Error) {
 		Args:       args,
 		StdinData:  stdinData,
 		subjectRef: subRef,
+		TraceID:    c.GetEnviron(plugin.TraceIDEnvKey),
 	}
 
 	return cmd, cmdArgs, nil
@@ -17,14 +17,27 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/internal/logger"
 	"github.com/deislabs/ratify/pkg/common"
+	commonutils "github.com/deislabs/ratify/pkg/common/utils"
+	exConfig "github.com/deislabs/ratify/pkg/executor/config"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/utils"
+	"github.com/distribution/reference"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// DefaultMutationStoreName is the name of the store resolving the tags of
+// images to digests in mutation requests.
+const DefaultMutationStoreName = "oras"
+
 var logOpt = logger.Option{
 	ComponentType: logger.ReferrerStore,
 }
@@ -40,3 +53,294 @@ func ResolveSubjectDescriptor(ctx context.Context, stores *[]referrerstore.Refer
 
 	return nil, errors.ErrorCodeReferrerStoreFailure.WithDetail("could not resolve descriptor for a subject from any stores").WithComponentType(errors.ReferrerStore)
 }
+
+// MutationOptions configures how references are resolved by the mutation
+// endpoint.
+type MutationOptions struct {
+	// StoreName is the name of the store resolving the references
+	StoreName string
+	// FallbackStoreNames are the names of the stores resolving the references
+	// in order if the store fails to resolve them, e.g. of the upstream
+	// registry of a mirror
+	FallbackStoreNames []string
+	// Platform selects the manifest tags resolved to an image index are
+	// resolved to, the index is kept if nil
+	Platform *oci.Platform
+	// DigestedReferences selects how references already pinned to a digest
+	// are handled, they are passed through if empty
+	DigestedReferences DigestedReferenceMode
+	// FailurePolicies select how tags the store fails to resolve are handled
+	// per registry, they fail closed if no policy matches
+	FailurePolicies []exConfig.MutationFailurePolicy
+}
+
+// DigestedReferenceMode selects how the mutation endpoint handles references
+// already pinned to a digest.
+type DigestedReferenceMode string
+
+const (
+	// DigestedReferencesPassthrough returns references by digest as is.
+	DigestedReferencesPassthrough DigestedReferenceMode = "passthrough"
+	// DigestedReferencesValidate fails references whose digest is not found
+	// by the store.
+	DigestedReferencesValidate DigestedReferenceMode = "validate"
+	// DigestedReferencesVerifyTag fails references whose digest is not found
+	// and references pinning a tag, e.g. myrepo:v1@sha256:..., whose tag no
+	// longer points at the digest.
+	DigestedReferencesVerifyTag DigestedReferenceMode = "verify-tag"
+)
+
+// ParseDigestedReferenceMode returns the mode of the given name, the empty
+// name selects passthrough.
+func ParseDigestedReferenceMode(mode string) (DigestedReferenceMode, error) {
+	switch DigestedReferenceMode(mode) {
+	case "", DigestedReferencesPassthrough:
+		return DigestedReferencesPassthrough, nil
+	case DigestedReferencesValidate, DigestedReferencesVerifyTag:
+		return DigestedReferenceMode(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported mode %s for references by digest, supported modes are %s, %s, %s", mode, DigestedReferencesPassthrough, DigestedReferencesValidate, DigestedReferencesVerifyTag)
+	}
+}
+
+// ResolveMutatedReference returns the reference of the image by digest as the
+// mutation endpoint does: tags are resolved by the first of the stores
+// selected by the options resolving them, to the manifest of the platform of
+// the options if the tag references an image index. References by digest are
+// returned as is once checked as selected by the options.
+func ResolveMutatedReference(ctx context.Context, stores []referrerstore.ReferrerStore, image string, opts MutationOptions) (string, error) {
+	subRef, err := utils.ParseSubjectReference(image)
+	if err != nil {
+		return "", errors.ErrorCodeReferenceInvalid.WithError(err).WithDetail(fmt.Sprintf("failed to parse image reference %s", image))
+	}
+	if subRef.Digest != "" && (opts.DigestedReferences == "" || opts.DigestedReferences == DigestedReferencesPassthrough) {
+		return subRef.Original, nil
+	}
+
+	selectedStores := selectMutationStores(stores, opts)
+	if len(selectedStores) == 0 {
+		return "", errors.ErrorCodeReferrerStoreFailure.WithDetail(fmt.Sprintf("failed to mutate image reference %s: could not find matching store %s", subRef.Original, strings.Join(append([]string{opts.StoreName}, opts.FallbackStoreNames...), ", "))).WithComponentType(errors.ReferrerStore)
+	}
+
+	if subRef.Digest != "" {
+		if err := checkDigestedReference(ctx, selectedStores, image, subRef, opts); err != nil {
+			return "", err
+		}
+		return subRef.Original, nil
+	}
+
+	resolved, err := resolveTag(ctx, selectedStores, subRef, opts.Platform)
+	if err != nil {
+		policy := exConfig.MatchMutationFailurePolicy(opts.FailurePolicies, registryOf(subRef))
+		switch policy.Policy {
+		case exConfig.MutationFailOpen:
+			logger.GetLogger(ctx, logOpt).Warnf("returning the original reference of image %s by the fail open mutation policy of registry %s: %v", image, policy.Registry, err)
+			return image, nil
+		case exConfig.MutationRetry:
+			if resolved, err = retryResolveTag(ctx, selectedStores, subRef, opts.Platform, policy, err); err != nil {
+				return "", err
+			}
+		default:
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s@%s", subRef.Path, resolved.Digest.String()), nil
+}
+
+// retryResolveTag resolves the tag again with exponential backoff as
+// configured by the retry policy, until the context is done. The error of the
+// last attempt is returned if the tag is not resolved.
+func retryResolveTag(ctx context.Context, stores []referrerstore.ReferrerStore, subRef common.Reference, platform *oci.Platform, policy exConfig.MutationFailurePolicy, err error) (oci.Descriptor, error) {
+	backoff := policy.GetRetryBackoff()
+	for retry := 1; retry <= policy.GetMaxRetries(); retry++ {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return oci.Descriptor{}, err
+		case <-timer.C:
+		}
+		var resolved oci.Descriptor
+		if resolved, err = resolveTag(ctx, stores, subRef, platform); err == nil {
+			return resolved, nil
+		}
+		logger.GetLogger(ctx, logOpt).Debugf("retry %d of resolving image %s failed: %v", retry, subRef.Original, err)
+		backoff *= 2
+	}
+	return oci.Descriptor{}, err
+}
+
+// registryOf returns the registry host of the normalized reference.
+func registryOf(subRef common.Reference) string {
+	registry, _, _ := strings.Cut(subRef.Path, "/")
+	return registry
+}
+
+// selectMutationStores returns the configured stores of the options in order.
+func selectMutationStores(stores []referrerstore.ReferrerStore, opts MutationOptions) []referrerstore.ReferrerStore {
+	var selected []referrerstore.ReferrerStore
+	for _, name := range append([]string{opts.StoreName}, opts.FallbackStoreNames...) {
+		for _, store := range stores {
+			if store.Name() == name {
+				selected = append(selected, store)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// resolveTag returns the descriptor resolved by the first store resolving the
+// tag, the error of the last store is returned if no store resolves it.
+func resolveTag(ctx context.Context, stores []referrerstore.ReferrerStore, subRef common.Reference, platform *oci.Platform) (oci.Descriptor, error) {
+	var err error
+	for i, store := range stores {
+		var resolved oci.Descriptor
+		if resolved, err = resolveStoreTag(ctx, store, subRef, platform); err == nil {
+			return resolved, nil
+		}
+		if i < len(stores)-1 {
+			logger.GetLogger(ctx, logOpt).Warnf("store %s failed to resolve image %s, falling back to store %s: %v", store.Name(), subRef.Original, stores[i+1].Name(), err)
+		}
+	}
+	return oci.Descriptor{}, err
+}
+
+// resolveStoreTag returns the descriptor the tag of the reference points at, or
+// the descriptor of the manifest of the platform if the tag points at an index.
+func resolveStoreTag(ctx context.Context, store referrerstore.ReferrerStore, subRef common.Reference, platform *oci.Platform) (oci.Descriptor, error) {
+	descriptor, err := store.GetSubjectDescriptor(ctx, subRef)
+	if err != nil {
+		return oci.Descriptor{}, errors.ErrorCodeGetSubjectDescriptorFailure.NewError(errors.ReferrerStore, store.Name(), errors.EmptyLink, err, fmt.Sprintf("failed to get subject descriptor for image %s", subRef.Original), errors.HideStackTrace)
+	}
+	if platform != nil && isIndex(descriptor.MediaType) {
+		return resolvePlatformManifest(ctx, store, subRef, descriptor.Descriptor, *platform)
+	}
+	return descriptor.Descriptor, nil
+}
+
+// checkDigestedReference checks the reference pinned to a digest with the
+// stores in order until a store confirms it. A tag pointing at another digest
+// fails the check without falling back.
+func checkDigestedReference(ctx context.Context, stores []referrerstore.ReferrerStore, image string, subRef common.Reference, opts MutationOptions) error {
+	var err error
+	for i, store := range stores {
+		if err = checkStoreDigestedReference(ctx, store, image, subRef, opts); err == nil || errors.ErrorCodeTagDigestMismatch.WithDetail("").Is(err) {
+			return err
+		}
+		if i < len(stores)-1 {
+			logger.GetLogger(ctx, logOpt).Warnf("store %s failed to check image %s, falling back to store %s: %v", store.Name(), subRef.Original, stores[i+1].Name(), err)
+		}
+	}
+	return err
+}
+
+// checkStoreDigestedReference checks the reference pinned to a digest exists
+// and, in verify-tag mode, that the tag pinned with the digest still points at
+// it. The index a tag points at and the manifest of the platform in the index
+// are both accepted.
+func checkStoreDigestedReference(ctx context.Context, store referrerstore.ReferrerStore, image string, subRef common.Reference, opts MutationOptions) error {
+	if _, err := store.GetSubjectDescriptor(ctx, subRef); err != nil {
+		return errors.ErrorCodeDigestNotFound.NewError(errors.ReferrerStore, store.Name(), errors.EmptyLink, err, fmt.Sprintf("failed to find digest %s of image %s", subRef.Digest, subRef.Original), errors.HideStackTrace)
+	}
+	if opts.DigestedReferences != DigestedReferencesVerifyTag {
+		return nil
+	}
+
+	tag := pinnedTag(image)
+	if tag == "" {
+		return nil
+	}
+	tagRef, err := utils.ParseSubjectReference(subRef.Path + ":" + tag)
+	if err != nil {
+		return errors.ErrorCodeReferenceInvalid.WithError(err).WithDetail(fmt.Sprintf("failed to parse image reference %s", image))
+	}
+	descriptor, err := store.GetSubjectDescriptor(ctx, tagRef)
+	if err != nil {
+		return errors.ErrorCodeGetSubjectDescriptorFailure.NewError(errors.ReferrerStore, store.Name(), errors.EmptyLink, err, fmt.Sprintf("failed to get subject descriptor for image %s", tagRef.Original), errors.HideStackTrace)
+	}
+	if descriptor.Digest == subRef.Digest {
+		return nil
+	}
+	if opts.Platform != nil && isIndex(descriptor.MediaType) {
+		manifest, err := resolvePlatformManifest(ctx, store, tagRef, descriptor.Descriptor, *opts.Platform)
+		if err == nil && manifest.Digest == subRef.Digest {
+			return nil
+		}
+	}
+	return errors.ErrorCodeTagDigestMismatch.WithDetail(fmt.Sprintf("tag %s of image %s points at %s instead of the pinned digest %s", tag, subRef.Path, descriptor.Digest, subRef.Digest)).WithComponentType(errors.ReferrerStore)
+}
+
+// pinnedTag returns the tag of a reference pinned to both a tag and a digest,
+// the tag is dropped when the reference is parsed as subject.
+func pinnedTag(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+	return ""
+}
+
+// ParsePlatform parses a platform in the os/arch[/variant] format, e.g.
+// linux/arm64/v8.
+func ParsePlatform(platform string) (*oci.Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid platform %s, expected os/arch[/variant]", platform)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid platform %s, expected os/arch[/variant]", platform)
+		}
+	}
+	parsed := &oci.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		parsed.Variant = parts[2]
+	}
+	return parsed, nil
+}
+
+func isIndex(mediaType string) bool {
+	return mediaType == oci.MediaTypeImageIndex || mediaType == commonutils.MediaTypeDockerManifestList
+}
+
+// resolvePlatformManifest returns the descriptor of the first manifest of the
+// index matching the platform.
+func resolvePlatformManifest(ctx context.Context, store referrerstore.ReferrerStore, subRef common.Reference, indexDesc oci.Descriptor, platform oci.Platform) (oci.Descriptor, error) {
+	fetcher, ok := store.(referrerstore.ManifestFetcher)
+	if !ok {
+		return oci.Descriptor{}, errors.ErrorCodeReferrerStoreFailure.WithDetail(fmt.Sprintf("failed to mutate image reference %s: store %s does not support resolving platform manifests", subRef.Original, store.Name())).WithComponentType(errors.ReferrerStore)
+	}
+	content, err := fetcher.GetManifestContent(ctx, subRef, indexDesc)
+	if err != nil {
+		return oci.Descriptor{}, errors.ErrorCodeReferrerStoreFailure.NewError(errors.ReferrerStore, store.Name(), errors.EmptyLink, err, fmt.Sprintf("failed to fetch the index of image %s", subRef.Original), errors.HideStackTrace)
+	}
+	var index oci.Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return oci.Descriptor{}, errors.ErrorCodeDataDecodingFailure.WithError(err).WithDetail(fmt.Sprintf("failed to parse the index of image %s", subRef.Original)).WithComponentType(errors.ReferrerStore)
+	}
+	for _, manifest := range index.Manifests {
+		if manifest.Platform != nil && platformMatches(*manifest.Platform, platform) {
+			return manifest, nil
+		}
+	}
+	return oci.Descriptor{}, errors.ErrorCodeReferrerStoreFailure.WithDetail(fmt.Sprintf("failed to mutate image reference %s: no manifest for platform %s", subRef.Original, formatPlatform(platform))).WithComponentType(errors.ReferrerStore)
+}
+
+// platformMatches returns true if the platform of a manifest matches the
+// wanted platform, the variant is only compared if the wanted platform has one.
+func platformMatches(manifest, wanted oci.Platform) bool {
+	return manifest.OS == wanted.OS &&
+		manifest.Architecture == wanted.Architecture &&
+		(wanted.Variant == "" || manifest.Variant == wanted.Variant)
+}
+
+func formatPlatform(platform oci.Platform) string {
+	if platform.Variant == "" {
+		return platform.OS + "/" + platform.Architecture
+	}
+	return platform.OS + "/" + platform.Architecture + "/" + platform.Variant
+}
@@ -17,12 +17,21 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common"
+	exConfig "github.com/deislabs/ratify/pkg/executor/config"
+	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
 	"github.com/deislabs/ratify/pkg/utils"
 	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func TestResolveSubjectDescriptor_Success(t *testing.T) {
@@ -63,3 +72,389 @@ func TestResolveSubjectDescriptor_Failure(t *testing.T) {
 		t.Fatalf("expected resolve to fail but didnot get any error")
 	}
 }
+
+func TestResolveMutatedReference(t *testing.T) {
+	testDigest := digest.FromString("test")
+	store := &mocks.TestStore{
+		ResolveMap: map[string]digest.Digest{
+			"v1": testDigest,
+		},
+	}
+	stores := []referrerstore.ReferrerStore{store}
+	testCases := []struct {
+		name      string
+		subject   string
+		storeName string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "tag resolved by store",
+			subject:   "localhost:5000/net-monitor:v1",
+			storeName: "testStore",
+			expected:  "localhost:5000/net-monitor@" + testDigest.String(),
+		},
+		{
+			name:      "digest kept",
+			subject:   "localhost:5000/net-monitor@" + testDigest.String(),
+			storeName: "oras",
+			expected:  "localhost:5000/net-monitor@" + testDigest.String(),
+		},
+		{
+			name:      "store not configured",
+			subject:   "localhost:5000/net-monitor:v1",
+			storeName: "oras",
+			expectErr: true,
+		},
+		{
+			name:      "tag not found",
+			subject:   "localhost:5000/net-monitor:v2",
+			storeName: "testStore",
+			expectErr: true,
+		},
+		{
+			name:      "invalid reference",
+			subject:   "localhost:5000/net&monitor:v1",
+			storeName: "testStore",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mutated, err := ResolveMutatedReference(context.Background(), stores, tc.subject, MutationOptions{StoreName: tc.storeName})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if mutated != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, mutated)
+			}
+		})
+	}
+}
+
+// indexStore resolves all tags to an image index.
+type indexStore struct {
+	mocks.TestStore
+	index     oci.Descriptor
+	manifests map[digest.Digest][]byte
+}
+
+func (s *indexStore) GetSubjectDescriptor(_ context.Context, _ common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	return &ocispecs.SubjectDescriptor{Descriptor: s.index}, nil
+}
+
+func (s *indexStore) GetManifestContent(_ context.Context, _ common.Reference, desc oci.Descriptor) ([]byte, error) {
+	return s.manifests[desc.Digest], nil
+}
+
+func TestResolveMutatedReference_Platform(t *testing.T) {
+	amd64Digest := digest.FromString("amd64")
+	armDigest := digest.FromString("arm")
+	index, err := json.Marshal(oci.Index{
+		Manifests: []oci.Descriptor{
+			{MediaType: oci.MediaTypeImageManifest, Digest: amd64Digest, Platform: &oci.Platform{OS: "linux", Architecture: "amd64"}},
+			{MediaType: oci.MediaTypeImageManifest, Digest: armDigest, Platform: &oci.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexDigest := digest.FromBytes(index)
+	store := &indexStore{
+		index:     oci.Descriptor{MediaType: oci.MediaTypeImageIndex, Digest: indexDigest},
+		manifests: map[digest.Digest][]byte{indexDigest: index},
+	}
+	stores := []referrerstore.ReferrerStore{store}
+
+	testCases := []struct {
+		name      string
+		platform  *oci.Platform
+		expected  digest.Digest
+		expectErr bool
+	}{
+		{
+			name:     "index kept without platform",
+			expected: indexDigest,
+		},
+		{
+			name:     "manifest of platform",
+			platform: &oci.Platform{OS: "linux", Architecture: "amd64"},
+			expected: amd64Digest,
+		},
+		{
+			name:     "manifest of platform with any variant",
+			platform: &oci.Platform{OS: "linux", Architecture: "arm"},
+			expected: armDigest,
+		},
+		{
+			name:      "variant not in index",
+			platform:  &oci.Platform{OS: "linux", Architecture: "arm", Variant: "v6"},
+			expectErr: true,
+		},
+		{
+			name:      "platform not in index",
+			platform:  &oci.Platform{OS: "windows", Architecture: "amd64"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mutated, err := ResolveMutatedReference(context.Background(), stores, "localhost:5000/net-monitor:v1", MutationOptions{StoreName: "testStore", Platform: tc.platform})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if !tc.expectErr && mutated != "localhost:5000/net-monitor@"+tc.expected.String() {
+				t.Fatalf("expected digest %s, got %s", tc.expected, mutated)
+			}
+		})
+	}
+}
+
+// digestStore resolves tags and the digests it stores.
+type digestStore struct {
+	mocks.TestStore
+	tags    map[string]digest.Digest
+	digests map[digest.Digest]bool
+}
+
+func (s *digestStore) GetSubjectDescriptor(_ context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	dgst := subjectReference.Digest
+	if dgst == "" {
+		dgst = s.tags[subjectReference.Tag]
+	}
+	if !s.digests[dgst] {
+		return nil, fmt.Errorf("manifest %s not found", subjectReference.Original)
+	}
+	return &ocispecs.SubjectDescriptor{Descriptor: oci.Descriptor{MediaType: oci.MediaTypeImageManifest, Digest: dgst}}, nil
+}
+
+func TestResolveMutatedReference_Digested(t *testing.T) {
+	v1Digest := digest.FromString("v1")
+	v2Digest := digest.FromString("v2")
+	store := &digestStore{
+		tags:    map[string]digest.Digest{"v1": v2Digest},
+		digests: map[digest.Digest]bool{v1Digest: true, v2Digest: true},
+	}
+	stores := []referrerstore.ReferrerStore{store}
+	missingDigest := digest.FromString("missing")
+
+	testCases := []struct {
+		name        string
+		subject     string
+		mode        DigestedReferenceMode
+		expectedErr error
+	}{
+		{name: "passthrough of missing digest", subject: "localhost:5000/net-monitor@" + missingDigest.String(), mode: DigestedReferencesPassthrough},
+		{name: "validate digest", subject: "localhost:5000/net-monitor@" + v1Digest.String(), mode: DigestedReferencesValidate},
+		{name: "validate missing digest", subject: "localhost:5000/net-monitor@" + missingDigest.String(), mode: DigestedReferencesValidate, expectedErr: re.ErrorCodeDigestNotFound.WithDetail("")},
+		{name: "validate ignores moved tag", subject: "localhost:5000/net-monitor:v1@" + v1Digest.String(), mode: DigestedReferencesValidate},
+		{name: "verify tag", subject: "localhost:5000/net-monitor:v1@" + v2Digest.String(), mode: DigestedReferencesVerifyTag},
+		{name: "verify moved tag", subject: "localhost:5000/net-monitor:v1@" + v1Digest.String(), mode: DigestedReferencesVerifyTag, expectedErr: re.ErrorCodeTagDigestMismatch.WithDetail("")},
+		{name: "verify tag of missing digest", subject: "localhost:5000/net-monitor:v1@" + missingDigest.String(), mode: DigestedReferencesVerifyTag, expectedErr: re.ErrorCodeDigestNotFound.WithDetail("")},
+		{name: "verify tag without tag", subject: "localhost:5000/net-monitor@" + v1Digest.String(), mode: DigestedReferencesVerifyTag},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mutated, err := ResolveMutatedReference(context.Background(), stores, tc.subject, MutationOptions{StoreName: "testStore", DigestedReferences: tc.mode})
+			if tc.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				subjectReference, _ := utils.ParseSubjectReference(tc.subject)
+				if mutated != subjectReference.Original {
+					t.Fatalf("expected %s, got %s", subjectReference.Original, mutated)
+				}
+				return
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// flakyStore fails to resolve tags a number of times before resolving them.
+type flakyStore struct {
+	mocks.TestStore
+	failures int
+}
+
+func (s *flakyStore) GetSubjectDescriptor(ctx context.Context, subjectReference common.Reference) (*ocispecs.SubjectDescriptor, error) {
+	if s.failures > 0 {
+		s.failures--
+		return nil, fmt.Errorf("registry unavailable")
+	}
+	return s.TestStore.GetSubjectDescriptor(ctx, subjectReference)
+}
+
+func TestResolveMutatedReference_FailurePolicies(t *testing.T) {
+	testDigest := digest.FromString("test")
+	policies := []exConfig.MutationFailurePolicy{
+		{Registry: "open.io", Policy: exConfig.MutationFailOpen},
+		{Registry: "retry.io", Policy: exConfig.MutationRetry, MaxRetries: 2, RetryBackoffMilliseconds: 1},
+	}
+	testCases := []struct {
+		name      string
+		subject   string
+		failures  int
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "fail closed by default",
+			subject:   "closed.io/net-monitor:v1",
+			failures:  1,
+			expectErr: true,
+		},
+		{
+			name:     "fail open",
+			subject:  "open.io/net-monitor:v1",
+			failures: 1,
+			expected: "open.io/net-monitor:v1",
+		},
+		{
+			name:     "resolved by retry",
+			subject:  "retry.io/net-monitor:v1",
+			failures: 2,
+			expected: "retry.io/net-monitor@" + testDigest.String(),
+		},
+		{
+			name:      "retries exhausted",
+			subject:   "retry.io/net-monitor:v1",
+			failures:  3,
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &flakyStore{
+				TestStore: mocks.TestStore{ResolveMap: map[string]digest.Digest{"v1": testDigest}},
+				failures:  tc.failures,
+			}
+			mutated, err := ResolveMutatedReference(context.Background(), []referrerstore.ReferrerStore{store}, tc.subject, MutationOptions{StoreName: "testStore", FailurePolicies: policies})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if mutated != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, mutated)
+			}
+		})
+	}
+}
+
+// namedStore is a flaky store with a configurable name.
+type namedStore struct {
+	flakyStore
+	name string
+}
+
+func (s *namedStore) Name() string {
+	return s.name
+}
+
+func TestResolveMutatedReference_FallbackStores(t *testing.T) {
+	mirrorDigest := digest.FromString("mirror")
+	upstreamDigest := digest.FromString("upstream")
+	testCases := []struct {
+		name             string
+		mirrorFailures   int
+		upstreamFailures int
+		fallbacks        []string
+		expected         string
+		expectErr        bool
+	}{
+		{
+			name:      "resolved by primary store",
+			fallbacks: []string{"upstream"},
+			expected:  "localhost:5000/net-monitor@" + mirrorDigest.String(),
+		},
+		{
+			name:           "resolved by fallback store",
+			mirrorFailures: 1,
+			fallbacks:      []string{"upstream"},
+			expected:       "localhost:5000/net-monitor@" + upstreamDigest.String(),
+		},
+		{
+			name:           "fallback store not configured",
+			mirrorFailures: 1,
+			fallbacks:      []string{"missing", "upstream"},
+			expected:       "localhost:5000/net-monitor@" + upstreamDigest.String(),
+		},
+		{
+			name:             "all stores failing",
+			mirrorFailures:   1,
+			upstreamFailures: 1,
+			fallbacks:        []string{"upstream"},
+			expectErr:        true,
+		},
+		{
+			name:           "no fallback",
+			mirrorFailures: 1,
+			expectErr:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mirror := &namedStore{name: "mirror", flakyStore: flakyStore{TestStore: mocks.TestStore{ResolveMap: map[string]digest.Digest{"v1": mirrorDigest}}, failures: tc.mirrorFailures}}
+			upstream := &namedStore{name: "upstream", flakyStore: flakyStore{TestStore: mocks.TestStore{ResolveMap: map[string]digest.Digest{"v1": upstreamDigest}}, failures: tc.upstreamFailures}}
+			stores := []referrerstore.ReferrerStore{upstream, mirror}
+			mutated, err := ResolveMutatedReference(context.Background(), stores, "localhost:5000/net-monitor:v1", MutationOptions{StoreName: "mirror", FallbackStoreNames: tc.fallbacks})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if mutated != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, mutated)
+			}
+		})
+	}
+}
+
+func TestResolveMutatedReference_RetryStopsWithContext(t *testing.T) {
+	store := &flakyStore{failures: 10}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	policies := []exConfig.MutationFailurePolicy{{Registry: "*", Policy: exConfig.MutationRetry, MaxRetries: 10, RetryBackoffMilliseconds: 1000}}
+	if _, err := ResolveMutatedReference(ctx, []referrerstore.ReferrerStore{store}, "localhost:5000/net-monitor:v1", MutationOptions{StoreName: "testStore", FailurePolicies: policies}); err == nil {
+		t.Fatal("expected resolving to fail once the context is done")
+	}
+	if store.failures != 9 {
+		t.Fatalf("expected no retry after the context is done, %d failures left", store.failures)
+	}
+}
+
+func TestParseDigestedReferenceMode(t *testing.T) {
+	if mode, err := ParseDigestedReferenceMode(""); err != nil || mode != DigestedReferencesPassthrough {
+		t.Fatalf("expected default mode %s, got %s, %v", DigestedReferencesPassthrough, mode, err)
+	}
+	if mode, err := ParseDigestedReferenceMode("verify-tag"); err != nil || mode != DigestedReferencesVerifyTag {
+		t.Fatalf("expected mode %s, got %s, %v", DigestedReferencesVerifyTag, mode, err)
+	}
+	if _, err := ParseDigestedReferenceMode("resolve"); err == nil {
+		t.Fatal("expected unsupported mode to fail")
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	testCases := []struct {
+		platform  string
+		expected  oci.Platform
+		expectErr bool
+	}{
+		{platform: "linux/amd64", expected: oci.Platform{OS: "linux", Architecture: "amd64"}},
+		{platform: "linux/arm64/v8", expected: oci.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{platform: "linux", expectErr: true},
+		{platform: "linux//v8", expectErr: true},
+		{platform: "linux/arm64/v8/extra", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			platform, err := ParsePlatform(tc.platform)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if err == nil && !reflect.DeepEqual(*platform, tc.expected) {
+				t.Fatalf("expected platform %+v, got %+v", tc.expected, *platform)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/testregistry"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const verifyPath = "/ratify/gatekeeper/v1/verify"
+
+var invalidRepositoryChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Result is the verification result of a subject.
+type Result struct {
+	IsSuccess       bool                     `json:"isSuccess"`
+	VerifierReports []map[string]interface{} `json:"verifierReports,omitempty"`
+}
+
+// Target verifies subjects with a Ratify instance.
+type Target interface {
+	Verify(ctx context.Context, subject string) (Result, error)
+}
+
+// ExecutorTarget verifies subjects with an executor running in process.
+type ExecutorTarget struct {
+	Executor executor.Executor
+}
+
+// ServerTarget verifies subjects with a running Ratify server through the
+// Gatekeeper external data provider API.
+type ServerTarget struct {
+	// URL is the base URL of the server, e.g. http://localhost:6001
+	URL    string
+	Client *http.Client
+}
+
+// Outcome is the outcome of running a scenario.
+type Outcome struct {
+	Name    string
+	Subject string
+	Passed  bool
+	// Failures lists the expectations that were not met.
+	Failures []string
+	Result   Result
+}
+
+// Run pushes the subject and the artifacts of the scenario to the registry and
+// verifies the subject with the target.
+func Run(ctx context.Context, registry *testregistry.Registry, target Target, scenario Scenario) (Outcome, error) {
+	outcome := Outcome{Name: scenario.Name}
+	repository := scenario.Subject.Repository
+	if repository == "" {
+		repository = strings.Trim(invalidRepositoryChars.ReplaceAllString(strings.ToLower(scenario.Name), "-"), "-")
+	}
+	layer := scenario.Subject.Layer
+	if layer == "" {
+		layer = scenario.Name
+	}
+
+	subjectDesc, err := registry.PushImage(ctx, repository, scenario.Subject.Tag, []byte(layer))
+	if err != nil {
+		return outcome, fmt.Errorf("scenario %s: %w", scenario.Name, err)
+	}
+	if err := pushArtifacts(ctx, registry, repository, subjectDesc, scenario.Artifacts, scenario.dir); err != nil {
+		return outcome, fmt.Errorf("scenario %s: %w", scenario.Name, err)
+	}
+
+	outcome.Subject = registry.Reference(repository, subjectDesc.Digest.String())
+	outcome.Result, err = target.Verify(ctx, outcome.Subject)
+	if err != nil {
+		return outcome, fmt.Errorf("scenario %s: failed to verify %s: %w", scenario.Name, outcome.Subject, err)
+	}
+	outcome.Failures = evaluate(scenario.Expect, outcome.Result)
+	outcome.Passed = len(outcome.Failures) == 0
+	return outcome, nil
+}
+
+func pushArtifacts(ctx context.Context, registry *testregistry.Registry, repository string, subject oci.Descriptor, artifacts []Artifact, dir string) error {
+	for _, artifact := range artifacts {
+		blobs := make([]testregistry.Blob, 0, len(artifact.Blobs))
+		for _, blob := range artifact.Blobs {
+			content, err := blob.content(dir)
+			if err != nil {
+				return err
+			}
+			blobs = append(blobs, testregistry.Blob{MediaType: blob.MediaType, Content: content})
+		}
+		desc, err := registry.PushReferrer(ctx, repository, subject, artifact.ArtifactType, blobs...)
+		if err != nil {
+			return err
+		}
+		if err := pushArtifacts(ctx, registry, repository, desc, artifact.Artifacts, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate returns the expectations the result does not meet.
+func evaluate(expect Expectation, result Result) []string {
+	var failures []string
+	if result.IsSuccess != expect.IsSuccess {
+		failures = append(failures, fmt.Sprintf("expected isSuccess %v, got %v", expect.IsSuccess, result.IsSuccess))
+	}
+
+	names := make([]string, 0, len(expect.Verifiers))
+	for name := range expect.Verifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for _, report := range result.VerifierReports {
+			if report["name"] != name {
+				continue
+			}
+			found = true
+			if isSuccess, _ := report["isSuccess"].(bool); isSuccess != expect.Verifiers[name] {
+				failures = append(failures, fmt.Sprintf("expected verifier %s isSuccess %v, got %v: %v", name, expect.Verifiers[name], isSuccess, report["message"]))
+			}
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("expected a report of verifier %s", name))
+		}
+	}
+	return failures
+}
+
+// Verify verifies the subject with the executor.
+func (t ExecutorTarget) Verify(ctx context.Context, subject string) (Result, error) {
+	verifyResult, err := t.Executor.VerifySubject(ctx, executor.VerifyParameters{Subject: subject})
+	if err != nil {
+		return Result{}, err
+	}
+	return toResult(verifyResult)
+}
+
+// Verify sends the subject to the verify endpoint of the server.
+func (t ServerTarget) Verify(ctx context.Context, subject string) (Result, error) {
+	endpoint, err := url.JoinPath(t.URL, verifyPath)
+	if err != nil {
+		return Result{}, err
+	}
+	request := externaldata.ProviderRequest{
+		APIVersion: "externaldata.gatekeeper.sh/v1beta1",
+		Kind:       "ProviderRequest",
+	}
+	request.Request.Keys = []string{subject}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return Result{}, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return Result{}, err
+	}
+	defer httpResponse.Body.Close()
+
+	var response externaldata.ProviderResponse
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return Result{}, fmt.Errorf("failed to decode response with status %d: %w", httpResponse.StatusCode, err)
+	}
+	if response.Response.SystemError != "" {
+		return Result{}, fmt.Errorf("server error: %s", response.Response.SystemError)
+	}
+	if len(response.Response.Items) != 1 {
+		return Result{}, fmt.Errorf("expected 1 item in response, got %d", len(response.Response.Items))
+	}
+	item := response.Response.Items[0]
+	if item.Error != "" {
+		return Result{}, fmt.Errorf("verification error: %s", item.Error)
+	}
+	return toResult(item.Value)
+}
+
+// toResult converts a verify result or a verification response to a result
+// with generic verifier reports, so that results of both targets compare alike.
+func toResult(value interface{}) (Result, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return Result{}, err
+	}
+	var result Result
+	if err := json.Unmarshal(content, &result); err != nil {
+		return Result{}, fmt.Errorf("failed to decode verification result: %w", err)
+	}
+	return result, nil
+}
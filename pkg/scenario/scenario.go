@@ -0,0 +1,117 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scenario runs declarative verification scenarios. A scenario
+// describes a subject, the artifacts attached to it and the expected decision.
+// The runner pushes the subject and its artifacts to an embedded registry and
+// verifies the subject with Ratify, so that users can encode their own policy
+// regression suites in YAML.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a YAML file holding scenarios.
+type File struct {
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// Scenario is a subject with attached artifacts and the expected verification decision.
+type Scenario struct {
+	Name      string      `yaml:"name"`
+	Subject   Subject     `yaml:"subject"`
+	Artifacts []Artifact  `yaml:"artifacts,omitempty"`
+	Expect    Expectation `yaml:"expect"`
+
+	// dir is the directory of the scenario file, blob files are relative to it
+	dir string
+}
+
+// Subject is the image to verify.
+type Subject struct {
+	// Repository defaults to the scenario name.
+	Repository string `yaml:"repository,omitempty"`
+	// Tag is optional, the subject is always verified by digest.
+	Tag string `yaml:"tag,omitempty"`
+	// Layer is the content of the single image layer, it defaults to the scenario name.
+	Layer string `yaml:"layer,omitempty"`
+}
+
+// Artifact is an artifact attached to the subject or to another artifact.
+type Artifact struct {
+	ArtifactType string `yaml:"artifactType"`
+	Blobs        []Blob `yaml:"blobs,omitempty"`
+	// Artifacts are attached to this artifact.
+	Artifacts []Artifact `yaml:"artifacts,omitempty"`
+}
+
+// Blob is a blob of an artifact given inline or as a file.
+type Blob struct {
+	MediaType string `yaml:"mediaType"`
+	Content   string `yaml:"content,omitempty"`
+	// File is a path relative to the scenario file.
+	File string `yaml:"file,omitempty"`
+}
+
+// Expectation is the expected verification decision.
+type Expectation struct {
+	IsSuccess bool `yaml:"isSuccess"`
+	// Verifiers maps verifier names to the expected result of their reports on
+	// artifacts attached to the subject.
+	Verifiers map[string]bool `yaml:"verifiers,omitempty"`
+}
+
+// Load reads the scenarios of a YAML file.
+func Load(path string) ([]Scenario, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file %s: %w", path, err)
+	}
+	var file File
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file %s: %w", path, err)
+	}
+	if len(file.Scenarios) == 0 {
+		return nil, fmt.Errorf("scenario file %s has no scenarios", path)
+	}
+	for i := range file.Scenarios {
+		if file.Scenarios[i].Name == "" {
+			return nil, fmt.Errorf("scenario %d of file %s has no name", i, path)
+		}
+		file.Scenarios[i].dir = filepath.Dir(path)
+	}
+	return file.Scenarios, nil
+}
+
+// content returns the content of the blob.
+func (b Blob) content(dir string) ([]byte, error) {
+	if b.File == "" {
+		return []byte(b.Content), nil
+	}
+	path := b.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob file: %w", err)
+	}
+	return content, nil
+}
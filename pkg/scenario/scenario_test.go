@@ -0,0 +1,190 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	pt "github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/config"
+	sf "github.com/deislabs/ratify/pkg/referrerstore/factory"
+	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
+	"github.com/deislabs/ratify/pkg/testregistry"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+)
+
+const approvalArtifactType = "application/vnd.example.approval"
+
+const scenarios = `
+scenarios:
+- name: approved image
+  artifacts:
+  - artifactType: application/vnd.example.approval
+    blobs:
+    - mediaType: text/plain
+      file: approval.txt
+  expect:
+    isSuccess: true
+    verifiers:
+      approval: true
+- name: rejected image
+  artifacts:
+  - artifactType: application/vnd.example.approval
+    blobs:
+    - mediaType: text/plain
+      content: rejected
+  expect:
+    isSuccess: false
+    verifiers:
+      approval: false
+- name: unexpected decision
+  artifacts:
+  - artifactType: application/vnd.example.approval
+    blobs:
+    - mediaType: text/plain
+      content: rejected
+  expect:
+    isSuccess: true
+`
+
+// approvalVerifier succeeds if the first blob of the artifact is "approved"
+type approvalVerifier struct{}
+
+func (v *approvalVerifier) Name() string {
+	return "approval"
+}
+
+func (v *approvalVerifier) Type() string {
+	return "approval"
+}
+
+func (v *approvalVerifier) CanVerify(_ context.Context, referenceDescriptor ocispecs.ReferenceDescriptor) bool {
+	return referenceDescriptor.ArtifactType == approvalArtifactType
+}
+
+func (v *approvalVerifier) Verify(ctx context.Context, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, store referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	manifest, err := store.GetReferenceManifest(ctx, subjectReference, referenceDescriptor)
+	if err != nil {
+		return verifier.VerifierResult{}, err
+	}
+	blob, err := store.GetBlobContent(ctx, subjectReference, manifest.Blobs[0].Digest)
+	if err != nil {
+		return verifier.VerifierResult{}, err
+	}
+	return verifier.VerifierResult{Name: v.Name(), IsSuccess: string(blob) == "approved"}, nil
+}
+
+func (v *approvalVerifier) GetNestedReferences() []string {
+	return nil
+}
+
+func TestRun_ExecutorTarget(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "scenarios.yaml"), []byte(scenarios), 0o600); err != nil {
+		t.Fatalf("failed to write scenarios: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "approval.txt"), []byte("approved"), 0o600); err != nil {
+		t.Fatalf("failed to write blob: %v", err)
+	}
+	loaded, err := Load(filepath.Join(dir, "scenarios.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load scenarios: %v", err)
+	}
+
+	store, err := sf.CreateStoreFromConfig(config.StorePluginConfig{"name": "oras", "useHttp": true}, "1.0.0", nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	target := ExecutorTarget{Executor: &core.Executor{
+		ReferrerStores: []referrerstore.ReferrerStore{store},
+		Verifiers:      []verifier.ReferenceVerifier{&approvalVerifier{}},
+		PolicyEnforcer: configpolicy.PolicyEnforcer{ArtifactTypePolicies: map[string]pt.ArtifactTypeVerifyPolicy{"default": pt.AllVerifySuccess}},
+	}}
+	registry := testregistry.New()
+	defer registry.Close()
+
+	expected := []bool{true, true, false}
+	for i, scenario := range loaded {
+		outcome, err := Run(context.Background(), registry, target, scenario)
+		if err != nil {
+			t.Fatalf("scenario %s failed with error: %v", scenario.Name, err)
+		}
+		if outcome.Passed != expected[i] {
+			t.Fatalf("expected scenario %s to pass: %v, got failures %v", scenario.Name, expected[i], outcome.Failures)
+		}
+	}
+}
+
+func TestServerTarget_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request externaldata.ProviderRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || r.URL.Path != verifyPath {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response := externaldata.ProviderResponse{}
+		response.Response.Items = []externaldata.Item{{
+			Key: request.Request.Keys[0],
+			Value: map[string]interface{}{
+				"isSuccess":       true,
+				"verifierReports": []interface{}{map[string]interface{}{"name": "approval", "isSuccess": true}},
+			},
+		}}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	result, err := ServerTarget{URL: server.URL}.Verify(context.Background(), "localhost/image@sha256:abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures := evaluate(Expectation{IsSuccess: true, Verifiers: map[string]bool{"approval": true}}, result); len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	if failures := evaluate(Expectation{IsSuccess: true, Verifiers: map[string]bool{"sbom": true}}, result); len(failures) != 1 {
+		t.Fatalf("expected missing verifier report to fail, got %v", failures)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	testCases := map[string]string{
+		"empty.yaml":   "scenarios: []",
+		"noname.yaml":  "scenarios:\n- expect:\n    isSuccess: true",
+		"invalid.yaml": "scenarios: {",
+	}
+	for name, content := range testCases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Fatalf("expected error loading %s", name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selfverify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	e "github.com/deislabs/ratify/pkg/executor"
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/utils"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ModeWarn logs a warning if the self-verification fails.
+	ModeWarn = "warn"
+	// ModeEnforce refuses to serve if the self-verification fails.
+	ModeEnforce = "enforce"
+
+	// verifyTimeout bounds the verification of the Ratify image at startup.
+	verifyTimeout = time.Minute
+)
+
+// Config is the configuration of the self-verification at startup.
+type Config struct {
+	// Mode is warn or enforce, self-verification is disabled if empty.
+	Mode string `json:"mode,omitempty"`
+	// Image is the reference of the Ratify image verified by the configured
+	// verifiers and policy. Defaults to the image in the RATIFY_IMAGE
+	// environment variable.
+	Image string `json:"image,omitempty"`
+	// PluginDigests are the expected digests of the plugin binaries keyed by
+	// file name, every binary in the plugin directories must be listed.
+	PluginDigests map[string]string `json:"pluginDigests,omitempty"`
+}
+
+// Run verifies the Ratify image and plugin binaries if self-verification is
+// enabled. A failure is only returned in enforce mode, in warn mode it is
+// logged.
+func Run(ctx context.Context, conf Config, executor *core.Executor, pluginDirs []string) error {
+	switch conf.Mode {
+	case "":
+		return nil
+	case ModeWarn, ModeEnforce:
+	default:
+		return re.ErrorCodeConfigInvalid.WithComponentType(re.Executor).WithDetail(fmt.Sprintf("self-verification mode must be %s or %s, got %s", ModeWarn, ModeEnforce, conf.Mode))
+	}
+
+	if err := Verify(ctx, conf, executor, pluginDirs); err != nil {
+		if conf.Mode == ModeWarn {
+			logrus.Warnf("%v", err)
+			return nil
+		}
+		return err
+	}
+	logrus.Info("self-verification of Ratify image and plugins succeeded")
+	return nil
+}
+
+// Verify verifies the Ratify image with the executor and the digests of the
+// binaries in the plugin directories.
+func Verify(ctx context.Context, conf Config, executor *core.Executor, pluginDirs []string) error {
+	var failures []string
+	image := conf.Image
+	if image == "" {
+		image = utils.GetImage()
+	}
+	if image == "" {
+		failures = append(failures, "image of Ratify is unknown")
+	} else if err := verifyImage(ctx, executor, image); err != nil {
+		failures = append(failures, err.Error())
+	}
+	failures = append(failures, verifyPlugins(conf.PluginDigests, pluginDirs)...)
+
+	if len(failures) > 0 {
+		return fmt.Errorf("self-verification failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func verifyImage(ctx context.Context, executor *core.Executor, image string) error {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+	result, err := executor.VerifySubject(ctx, e.VerifyParameters{Subject: image})
+	if err != nil {
+		return fmt.Errorf("failed to verify image %s: %w", image, err)
+	}
+	if !result.IsSuccess {
+		return fmt.Errorf("image %s did not pass the configured policy", image)
+	}
+	return nil
+}
+
+// verifyPlugins returns the plugin binaries that are not listed in the digests
+// or whose digest does not match.
+func verifyPlugins(digests map[string]string, pluginDirs []string) []string {
+	var failures []string
+	visited := map[string]struct{}{}
+	for _, dir := range pluginDirs {
+		if _, ok := visited[dir]; ok {
+			continue
+		}
+		visited[dir] = struct{}{}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				failures = append(failures, fmt.Sprintf("failed to read plugin directory %s: %v", dir, err))
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			name := entry.Name()
+			expected, ok := digests[name]
+			if !ok {
+				failures = append(failures, fmt.Sprintf("plugin %s has no configured digest", name))
+				continue
+			}
+			actual, err := fileDigest(filepath.Join(dir, name))
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("failed to compute digest of plugin %s: %v", name, err))
+				continue
+			}
+			if actual.String() != expected {
+				failures = append(failures, fmt.Sprintf("digest of plugin %s is %s, expected %s", name, actual, expected))
+			}
+		}
+	}
+	sort.Strings(failures)
+	return failures
+}
+
+func fileDigest(path string) (digest.Digest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return digest.FromReader(file)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selfverify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/executor/core"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	config "github.com/deislabs/ratify/pkg/policyprovider/configpolicy"
+	"github.com/deislabs/ratify/pkg/policyprovider/types"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	testImage        = "ghcr.io/deislabs/ratify:v1"
+	testArtifactType = "application/vnd.cncf.notary.signature"
+)
+
+func testExecutor(verified bool) *core.Executor {
+	return &core.Executor{
+		PolicyEnforcer: config.PolicyEnforcer{
+			ArtifactTypePolicies: map[string]types.ArtifactTypeVerifyPolicy{
+				"default": types.AllVerifySuccess,
+			}},
+		ReferrerStores: []referrerstore.ReferrerStore{&mocks.TestStore{
+			References: []ocispecs.ReferenceDescriptor{{ArtifactType: testArtifactType}},
+			ResolveMap: map[string]digest.Digest{"v1": digest.FromString("ratify")},
+		}},
+		Verifiers: []verifier.ReferenceVerifier{&core.TestVerifier{
+			CanVerifyFunc: func(at string) bool {
+				return at == testArtifactType
+			},
+			VerifyResult: func(_ string) bool {
+				return verified
+			},
+		}},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pluginDir := t.TempDir()
+	plugin := []byte("plugin binary")
+	if err := os.WriteFile(filepath.Join(pluginDir, "sbom"), plugin, 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(pluginDir, "cache"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		conf      Config
+		verified  bool
+		expectErr bool
+	}{
+		{
+			name:     "image and plugins verified",
+			conf:     Config{Image: testImage, PluginDigests: map[string]string{"sbom": digest.FromBytes(plugin).String()}},
+			verified: true,
+		},
+		{
+			name:      "image not verified",
+			conf:      Config{Image: testImage, PluginDigests: map[string]string{"sbom": digest.FromBytes(plugin).String()}},
+			expectErr: true,
+		},
+		{
+			name:      "plugin digest mismatch",
+			conf:      Config{Image: testImage, PluginDigests: map[string]string{"sbom": digest.FromString("other").String()}},
+			verified:  true,
+			expectErr: true,
+		},
+		{
+			name:      "plugin without digest",
+			conf:      Config{Image: testImage},
+			verified:  true,
+			expectErr: true,
+		},
+		{
+			name:      "unknown image",
+			conf:      Config{PluginDigests: map[string]string{"sbom": digest.FromBytes(plugin).String()}},
+			verified:  true,
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("RATIFY_IMAGE", "")
+			err := Verify(context.Background(), tc.conf, testExecutor(tc.verified), []string{pluginDir, pluginDir, filepath.Join(pluginDir, "missing")})
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("RATIFY_IMAGE", testImage)
+	executor := testExecutor(false)
+	testCases := []struct {
+		mode      string
+		expectErr bool
+	}{
+		{mode: ""},
+		{mode: ModeWarn},
+		{mode: ModeEnforce, expectErr: true},
+		{mode: "invalid", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			err := Run(context.Background(), Config{Mode: tc.mode}, executor, nil)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testregistry provides an embeddable in-memory OCI registry with
+// referrers API support, so that Ratify and plugin tests can run without an
+// external registry such as a localhost:5000 fixture.
+package testregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// Registry is an in-memory OCI registry served over plain HTTP on a random
+// local port. Content is lost when the registry is closed.
+type Registry struct {
+	server *httptest.Server
+	// Host is the host:port of the registry, e.g. 127.0.0.1:38281
+	Host string
+}
+
+// Blob is a blob to push with a referrer.
+type Blob struct {
+	MediaType string
+	Content   []byte
+}
+
+// New starts a new in-memory registry. Callers must Close it when done.
+func New() *Registry {
+	handler := registry.New(
+		registry.WithReferrersSupport(true),
+		registry.Logger(log.New(io.Discard, "", 0)),
+	)
+	server := httptest.NewServer(handler)
+	return &Registry{
+		server: server,
+		Host:   strings.TrimPrefix(server.URL, "http://"),
+	}
+}
+
+// Close shuts down the registry.
+func (r *Registry) Close() {
+	r.server.Close()
+}
+
+// Reference returns the reference of a tag or digest in a repository of the
+// registry, e.g. 127.0.0.1:38281/net-monitor:v1.
+func (r *Registry) Reference(repository string, tagOrDigest string) string {
+	if strings.Contains(tagOrDigest, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Host, repository, tagOrDigest)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Host, repository, tagOrDigest)
+}
+
+// PushBlob pushes a blob to the repository.
+func (r *Registry) PushBlob(ctx context.Context, repository string, mediaType string, content []byte) (oci.Descriptor, error) {
+	repo, err := r.repository(repository)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc := descriptor(mediaType, content)
+	if err := repo.Push(ctx, desc, bytes.NewReader(content)); err != nil {
+		return oci.Descriptor{}, fmt.Errorf("failed to push blob: %w", err)
+	}
+	return desc, nil
+}
+
+// PushManifest pushes a manifest to the repository and tags it if tag is not empty.
+func (r *Registry) PushManifest(ctx context.Context, repository string, tag string, mediaType string, content []byte) (oci.Descriptor, error) {
+	repo, err := r.repository(repository)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc := descriptor(mediaType, content)
+	if tag == "" {
+		err = repo.Push(ctx, desc, bytes.NewReader(content))
+	} else {
+		err = repo.PushReference(ctx, desc, bytes.NewReader(content), tag)
+	}
+	if err != nil {
+		return oci.Descriptor{}, fmt.Errorf("failed to push manifest: %w", err)
+	}
+	return desc, nil
+}
+
+// PushImage pushes a minimal OCI image with a single layer holding the given
+// content and tags it if tag is not empty.
+func (r *Registry) PushImage(ctx context.Context, repository string, tag string, layer []byte) (oci.Descriptor, error) {
+	config, err := r.PushBlob(ctx, repository, oci.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`))
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	layerDesc, err := r.PushBlob(ctx, repository, oci.MediaTypeImageLayer, layer)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	return r.pushImageManifest(ctx, repository, tag, oci.Manifest{
+		MediaType: oci.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []oci.Descriptor{layerDesc},
+	})
+}
+
+// PushReferrer pushes an artifact of the given type referring to the subject,
+// along with its blobs. The referrer is returned by the referrers API of the
+// subject. The artifact type is also set as the media type of an empty config,
+// since the embedded registry derives the artifact type of referrers from it.
+func (r *Registry) PushReferrer(ctx context.Context, repository string, subject oci.Descriptor, artifactType string, blobs ...Blob) (oci.Descriptor, error) {
+	subject = oci.Descriptor{MediaType: subject.MediaType, Digest: subject.Digest, Size: subject.Size}
+	return r.pushArtifact(ctx, repository, "", &subject, artifactType, blobs)
+}
+
+// PushArtifact pushes an artifact of the given type without a subject along
+// with its blobs, and tags it if tag is not empty.
+func (r *Registry) PushArtifact(ctx context.Context, repository string, tag string, artifactType string, blobs ...Blob) (oci.Descriptor, error) {
+	return r.pushArtifact(ctx, repository, tag, nil, artifactType, blobs)
+}
+
+func (r *Registry) pushArtifact(ctx context.Context, repository string, tag string, subject *oci.Descriptor, artifactType string, blobs []Blob) (oci.Descriptor, error) {
+	config, err := r.PushBlob(ctx, repository, artifactType, oci.DescriptorEmptyJSON.Data)
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	layers := make([]oci.Descriptor, 0, len(blobs))
+	for _, blob := range blobs {
+		layer, err := r.PushBlob(ctx, repository, blob.MediaType, blob.Content)
+		if err != nil {
+			return oci.Descriptor{}, err
+		}
+		layers = append(layers, layer)
+	}
+	desc, err := r.pushImageManifest(ctx, repository, tag, oci.Manifest{
+		MediaType:    oci.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       config,
+		Layers:       layers,
+		Subject:      subject,
+	})
+	if err != nil {
+		return oci.Descriptor{}, err
+	}
+	desc.ArtifactType = artifactType
+	return desc, nil
+}
+
+func (r *Registry) pushImageManifest(ctx context.Context, repository string, tag string, manifest oci.Manifest) (oci.Descriptor, error) {
+	manifest.SchemaVersion = 2
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return oci.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return r.PushManifest(ctx, repository, tag, oci.MediaTypeImageManifest, content)
+}
+
+func (r *Registry) repository(repository string) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", r.Host, repository))
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository %s: %w", repository, err)
+	}
+	repo.PlainHTTP = true
+	return repo, nil
+}
+
+func descriptor(mediaType string, content []byte) oci.Descriptor {
+	return oci.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+}
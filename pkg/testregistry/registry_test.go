@@ -0,0 +1,69 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testregistry
+
+import (
+	"context"
+	"testing"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestReference(t *testing.T) {
+	reg := &Registry{Host: "127.0.0.1:5000"}
+	if result := reg.Reference("net-monitor", "v1"); result != "127.0.0.1:5000/net-monitor:v1" {
+		t.Fatalf("unexpected tag reference %s", result)
+	}
+	digest := "sha256:b556844e6e59451caf4429eb1de50aa7c50e4b1cc985f9f5893affe4b73f9935"
+	if result := reg.Reference("net-monitor", digest); result != "127.0.0.1:5000/net-monitor@"+digest {
+		t.Fatalf("unexpected digest reference %s", result)
+	}
+}
+
+func TestPushReferrer(t *testing.T) {
+	ctx := context.Background()
+	reg := New()
+	defer reg.Close()
+
+	imageDesc, err := reg.PushImage(ctx, "net-monitor", "v1", []byte("layer"))
+	if err != nil {
+		t.Fatalf("failed to push image: %v", err)
+	}
+	sbomDesc, err := reg.PushReferrer(ctx, "net-monitor", imageDesc, "application/spdx+json", Blob{MediaType: "application/spdx+json", Content: []byte("{}")})
+	if err != nil {
+		t.Fatalf("failed to push referrer: %v", err)
+	}
+
+	repo, err := reg.repository("net-monitor")
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	resolved, err := repo.Resolve(ctx, "v1")
+	if err != nil || resolved.Digest != imageDesc.Digest {
+		t.Fatalf("expected tag to resolve to %s, got %v, err: %v", imageDesc.Digest, resolved.Digest, err)
+	}
+
+	var referrers []oci.Descriptor
+	if err := repo.Referrers(ctx, imageDesc, "", func(page []oci.Descriptor) error {
+		referrers = append(referrers, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	if len(referrers) != 1 || referrers[0].Digest != sbomDesc.Digest || referrers[0].ArtifactType != "application/spdx+json" {
+		t.Fatalf("expected sbom referrer %s, got %v", sbomDesc.Digest, referrers)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	scope       = "github.com/deislabs/ratify"
+	serviceName = "ratify"
+
+	// DefaultSampleRatio is the default ratio of the requests traced.
+	DefaultSampleRatio = 1.0
+
+	// SubjectKey is the attribute of the reference of the verified subject.
+	SubjectKey = attribute.Key("ratify.subject")
+	// VerifierKey is the attribute of the name of a verifier.
+	VerifierKey = attribute.Key("ratify.verifier")
+	// ArtifactTypeKey is the attribute of the artifact type of a referrer.
+	ArtifactTypeKey = attribute.Key("ratify.artifact_type")
+	// ArtifactDigestKey is the attribute of the digest of a referrer.
+	ArtifactDigestKey = attribute.Key("ratify.artifact_digest")
+	// PluginKey is the attribute of the name of a plugin binary.
+	PluginKey = attribute.Key("ratify.plugin")
+	// PolicyTypeKey is the attribute of the type of the policy provider.
+	PolicyTypeKey = attribute.Key("ratify.policy_type")
+	// SuccessKey is the attribute of the outcome of a verification.
+	SuccessKey = attribute.Key("ratify.success")
+)
+
+// Config is the configuration of the exporter of the spans.
+type Config struct {
+	// Endpoint is the host and port of the OTLP gRPC receiver, e.g. of the
+	// OpenTelemetry Collector, Jaeger or Tempo. Tracing is disabled if empty.
+	Endpoint string
+	// Insecure disables TLS to the receiver.
+	Insecure bool
+	// SampleRatio is the ratio of the requests traced, the sampling decision
+	// of an incoming trace context is honored.
+	SampleRatio float64
+}
+
+// InitTracing sets the global tracer provider exporting spans to the OTLP
+// receiver of the config. The returned function flushes and stops the export.
+// Spans are not recorded if tracing is not initialized.
+func InitTracing(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid trace sample ratio %v, it must be between 0 and 1", config.SampleRatio)
+	}
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	logrus.Infof("exporting traces to %s", config.Endpoint)
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span of the operation as a child of the span in the
+// context.
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(scope).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// StartServerSpan starts the span of an incoming request, continuing the trace
+// of the caller if the request carries a trace context.
+func StartServerSpan(ctx context.Context, name string, header http.Header) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+	return otel.Tracer(scope).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// EndSpan ends the span, recording the error if the operation failed.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// transport records a client span of each request sent with the base
+// transport. The trace context is not propagated to the server since
+// registries are not part of the trace.
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport returns a transport recording a span of each request, e.g. to
+// measure the latency of the registries. The span ends once the headers of the
+// response are received.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(scope).Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.URLPath(req.URL.Path),
+		))
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err == nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, resp.Status)
+		}
+	}
+	EndSpan(span, err)
+	return resp, err
+}
@@ -0,0 +1,168 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+func withRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInitTracing(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    Config
+		expectErr bool
+	}{
+		{
+			name:   "disabled",
+			config: Config{},
+		},
+		{
+			name:      "negative ratio",
+			config:    Config{Endpoint: "localhost:4317", SampleRatio: -0.5},
+			expectErr: true,
+		},
+		{
+			name:      "ratio above one",
+			config:    Config{Endpoint: "localhost:4317", SampleRatio: 2},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shutdown, err := InitTracing(context.Background(), tc.config)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectErr, err)
+			}
+			if err == nil {
+				if err := shutdown(context.Background()); err != nil {
+					t.Fatalf("unexpected shutdown error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestEndSpan(t *testing.T) {
+	recorder := withRecorder(t)
+
+	_, span := StartSpan(context.Background(), "ok", SubjectKey.String("localhost:5000/net-monitor:v1"))
+	EndSpan(span, nil)
+	_, span = StartSpan(context.Background(), "failed")
+	EndSpan(span, errors.New("verification failed"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Unset {
+		t.Fatalf("expected unset status, got %v", spans[0].Status().Code)
+	}
+	if len(spans[0].Attributes()) != 1 || spans[0].Attributes()[0].Value.AsString() != "localhost:5000/net-monitor:v1" {
+		t.Fatalf("expected subject attribute, got %v", spans[0].Attributes())
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "verification failed" {
+		t.Fatalf("expected error status, got %v", spans[1].Status())
+	}
+}
+
+func TestStartServerSpan(t *testing.T) {
+	recorder := withRecorder(t)
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := StartServerSpan(context.Background(), "POST /ratify/gatekeeper/v1/verify", header)
+	EndSpan(span, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected parent %v", spans[0].Parent())
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := withRecorder(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/missing/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport)}
+	for _, path := range []string{"/v2/", "/v2/missing/manifests/v1"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	expected := []struct {
+		status int64
+		code   codes.Code
+	}{
+		{http.StatusOK, codes.Unset},
+		{http.StatusNotFound, codes.Error},
+	}
+	for i, span := range spans {
+		if span.Name() != "HTTP GET" {
+			t.Fatalf("expected span name HTTP GET, got %s", span.Name())
+		}
+		var status int64
+		for _, attr := range span.Attributes() {
+			if attr.Key == semconv.HTTPResponseStatusCodeKey {
+				status = attr.Value.AsInt64()
+			}
+		}
+		if status != expected[i].status {
+			t.Fatalf("expected status %d, got %d", expected[i].status, status)
+		}
+		if span.Status().Code != expected[i].code {
+			t.Fatalf("expected status code %v, got %v", expected[i].code, span.Status().Code)
+		}
+	}
+}
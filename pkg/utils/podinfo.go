@@ -34,3 +34,13 @@ func GetServiceName() string {
 	}
 	return name
 }
+
+// GetImage returns the reference of the Ratify image, or an empty string if unknown.
+func GetImage() string {
+	return os.Getenv("RATIFY_IMAGE")
+}
+
+// GetPodName returns the name of the Ratify pod, or an empty string if unknown.
+func GetPodName() string {
+	return os.Getenv(RatifyPodNameEnvVar)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	_ "crypto/sha256" // required package for digest.Parse
 
@@ -30,7 +31,9 @@ import (
 
 const (
 	RatifyNamespaceEnvVar = "RATIFY_NAMESPACE"
+	RatifyPodNameEnvVar   = "RATIFY_POD_NAME"
 	subjectPattern        = `(\[(.*?)\])?(.*)`
+	qualifierPattern      = `^\[(operation|time|imageID):([^\]]*)\](.*)`
 )
 
 // RequestKey is a structured external data request key.
@@ -39,6 +42,13 @@ type RequestKey struct {
 	Subject string
 	// Namespace is the scope of the image.
 	Namespace string
+	// Operation is the admission operation, e.g. CREATE or UPDATE, if provided.
+	Operation string
+	// VerificationTime is the time as of which the subject is verified, if provided.
+	VerificationTime time.Time
+	// ImageID is the image ID reported in the status of the container running
+	// the subject, if provided. The subject is then referenced by its digest.
+	ImageID string
 }
 
 // ParseDigest parses the given string and returns a validated Digest object.
@@ -77,21 +87,105 @@ func TrimSpaceAndToLower(input string) string {
 	return strings.ToLower(strings.TrimSpace(input))
 }
 
+// SubjectFromImageID returns the subject referenced by the repository digest
+// of the image ID a container runtime reports in the pod status, e.g.
+// docker.io/library/nginx@sha256:<digest> or docker-pullable://nginx@sha256:<digest>,
+// so that the image actually running is verified even if its tag has been
+// removed or pushed again. The repository of the subject is kept if given.
+func SubjectFromImageID(subject, imageID string) (string, error) {
+	id := imageID
+	if i := strings.Index(id, "://"); i >= 0 {
+		id = id[i+len("://"):]
+	}
+	// image IDs without repository are digests of the image config, which
+	// referrers are not attached to
+	if !strings.Contains(id, "@") {
+		return "", errors.ErrorCodeReferenceInvalid.WithDetail(fmt.Sprintf("image ID %s is not a repository digest", imageID))
+	}
+	parsedID, err := reference.ParseDockerRef(id)
+	if err != nil {
+		return "", errors.ErrorCodeReferenceInvalid.WithDetail(fmt.Sprintf("failed to parse image ID %s", imageID))
+	}
+	digested, ok := parsedID.(reference.Digested)
+	if !ok {
+		return "", errors.ErrorCodeReferenceInvalid.WithDetail(fmt.Sprintf("image ID %s is not a repository digest", imageID))
+	}
+
+	repository := parsedID.Name()
+	if subject != "" {
+		parsedSubject, err := reference.ParseDockerRef(subject)
+		if err != nil {
+			return "", errors.ErrorCodeReferenceInvalid.WithDetail("failed to parse subject reference")
+		}
+		repository = parsedSubject.Name()
+	}
+	return fmt.Sprintf("%s@%s", repository, digested.Digest()), nil
+}
+
 // ParseRequestKey parses key string to a structured RequestKey object.
+// The admission operation, the verification time and the image ID of the
+// running container may follow the namespace as [operation:<OPERATION>],
+// [time:<RFC3339 timestamp>] and [imageID:<image ID>] in any order.
 // Example 1:
 // key: [gatekeeper-system]docker.io/test/hello:v1
 // match slice: ["[gatekeeper-system]docker.io/test/hello:v1" "[gatekeeper-system]" "gatekeeper-system" "docker.io/test/hello:v1"]
 // Example 2:
 // key: docker.io/test/hello:v1
 // match slice: ["docker.io/test/hello:v1" "" "" "docker.io/test/hello:v1"]
+// Example 3:
+// key: [gatekeeper-system][operation:UPDATE]docker.io/test/hello:v1
+// result: namespace "gatekeeper-system", operation "UPDATE", subject "docker.io/test/hello:v1"
+// Example 4:
+// key: [time:2023-06-01T00:00:00Z]docker.io/test/hello:v1
+// result: verification time 2023-06-01T00:00:00Z, subject "docker.io/test/hello:v1"
+// Example 5:
+// key: [gatekeeper-system][imageID:docker.io/test/hello@sha256:<digest>]docker.io/test/hello:v1
+// result: namespace "gatekeeper-system", subject "docker.io/test/hello@sha256:<digest>"
 func ParseRequestKey(key string) (RequestKey, error) {
-	re := regexp.MustCompile(subjectPattern)
-	match := re.FindStringSubmatch(key)
-	if match == nil || len(match) < 4 {
-		return RequestKey{}, fmt.Errorf("invalid request key: %s", key)
+	requestKey := RequestKey{}
+	subject, err := parseQualifiers(key, &requestKey)
+	if err != nil {
+		return RequestKey{}, err
+	}
+	if subject == key {
+		// the key starts with a namespace or the subject
+		re := regexp.MustCompile(subjectPattern)
+		match := re.FindStringSubmatch(key)
+		if match == nil || len(match) < 4 {
+			return RequestKey{}, fmt.Errorf("invalid request key: %s", key)
+		}
+		requestKey.Namespace = match[2]
+		if subject, err = parseQualifiers(match[3], &requestKey); err != nil {
+			return RequestKey{}, err
+		}
+	}
+	if requestKey.ImageID != "" {
+		if subject, err = SubjectFromImageID(subject, requestKey.ImageID); err != nil {
+			return RequestKey{}, err
+		}
+	}
+	requestKey.Subject = subject
+	return requestKey, nil
+}
+
+// parseQualifiers parses the leading qualifiers of the key into the request
+// key and returns the rest of the key.
+func parseQualifiers(key string, requestKey *RequestKey) (string, error) {
+	re := regexp.MustCompile(qualifierPattern)
+	for match := re.FindStringSubmatch(key); match != nil; match = re.FindStringSubmatch(key) {
+		switch match[1] {
+		case "operation":
+			requestKey.Operation = strings.ToUpper(match[2])
+		case "time":
+			verificationTime, err := time.Parse(time.RFC3339, match[2])
+			if err != nil {
+				return "", fmt.Errorf("invalid verification time %s in request key: %w", match[2], err)
+			}
+			requestKey.VerificationTime = verificationTime
+		case "imageID":
+			requestKey.ImageID = match[2]
+		}
+		key = match[3]
 	}
-	return RequestKey{
-		Namespace: match[2],
-		Subject:   match[3],
-	}, nil
+	return key, nil
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/opencontainers/go-digest"
@@ -27,6 +28,7 @@ import (
 const (
 	testRepo      = "docker.io/test/hello:v1"
 	testNamespace = "test"
+	testDigest    = "sha256:a0fc570a245b09ed752c42d600ee3bb5b4f77bbd70d8898780b7ab43454530eb"
 )
 
 func TestParseDigest_ReturnsExpected(t *testing.T) {
@@ -150,6 +152,70 @@ func TestTrimSpaceAndToLower_ReturnsExpected(t *testing.T) {
 	}
 }
 
+func TestSubjectFromImageID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		subject   string
+		imageID   string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "containerd image ID",
+			subject:  testRepo,
+			imageID:  fmt.Sprintf("docker.io/test/hello@%s", testDigest),
+			expected: fmt.Sprintf("docker.io/test/hello@%s", testDigest),
+		},
+		{
+			name:     "docker image ID with scheme",
+			subject:  "test/hello:v2",
+			imageID:  fmt.Sprintf("docker-pullable://test/hello@%s", testDigest),
+			expected: fmt.Sprintf("docker.io/test/hello@%s", testDigest),
+		},
+		{
+			name:     "repository of subject is kept",
+			subject:  "localhost:5000/hello:v1",
+			imageID:  fmt.Sprintf("mirror.io/test/hello@%s", testDigest),
+			expected: fmt.Sprintf("localhost:5000/hello@%s", testDigest),
+		},
+		{
+			name:     "repository of image ID without subject",
+			imageID:  fmt.Sprintf("mirror.io/test/hello@%s", testDigest),
+			expected: fmt.Sprintf("mirror.io/test/hello@%s", testDigest),
+		},
+		{
+			name:      "image config digest",
+			subject:   testRepo,
+			imageID:   fmt.Sprintf("docker://%s", testDigest),
+			expectErr: true,
+		},
+		{
+			name:      "invalid image ID",
+			subject:   testRepo,
+			imageID:   "docker.io/test/hello@sha256:invalid",
+			expectErr: true,
+		},
+		{
+			name:      "invalid subject",
+			subject:   "INVALID",
+			imageID:   fmt.Sprintf("docker.io/test/hello@%s", testDigest),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject, err := SubjectFromImageID(tc.subject, tc.imageID)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %t, got %v", tc.expectErr, err)
+			}
+			if subject != tc.expected {
+				t.Fatalf("expected subject %s, got %s", tc.expected, subject)
+			}
+		})
+	}
+}
+
 func TestParseRequestKey(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -180,6 +246,61 @@ func TestParseRequestKey(t *testing.T) {
 				Namespace: testNamespace,
 			},
 		},
+		{
+			name: "namespaced image with operation",
+			key:  fmt.Sprintf("[%s][operation:update]%s", testNamespace, testRepo),
+			result: RequestKey{
+				Subject:   testRepo,
+				Namespace: testNamespace,
+				Operation: "UPDATE",
+			},
+		},
+		{
+			name: "clustered image with operation",
+			key:  fmt.Sprintf("[operation:CREATE]%s", testRepo),
+			result: RequestKey{
+				Subject:   testRepo,
+				Operation: "CREATE",
+			},
+		},
+		{
+			name: "namespaced image with verification time and operation",
+			key:  fmt.Sprintf("[%s][time:2023-06-01T00:00:00Z][operation:create]%s", testNamespace, testRepo),
+			result: RequestKey{
+				Subject:          testRepo,
+				Namespace:        testNamespace,
+				Operation:        "CREATE",
+				VerificationTime: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "clustered image with verification time",
+			key:  fmt.Sprintf("[operation:UPDATE][time:2023-06-01T00:00:00Z]%s", testRepo),
+			result: RequestKey{
+				Subject:          testRepo,
+				Operation:        "UPDATE",
+				VerificationTime: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "namespaced image with image ID",
+			key:  fmt.Sprintf("[%s][imageID:docker-pullable://localhost:5000/net-monitor@%s]%s", testNamespace, testDigest, testRepo),
+			result: RequestKey{
+				Subject:   fmt.Sprintf("docker.io/test/hello@%s", testDigest),
+				Namespace: testNamespace,
+				ImageID:   fmt.Sprintf("docker-pullable://localhost:5000/net-monitor@%s", testDigest),
+			},
+		},
+		{
+			name:   "image ID without repository digest",
+			key:    fmt.Sprintf("[imageID:%s]%s", testDigest, testRepo),
+			result: RequestKey{},
+		},
+		{
+			name:   "invalid verification time",
+			key:    fmt.Sprintf("[%s][time:yesterday]%s", testNamespace, testRepo),
+			result: RequestKey{},
+		},
 		{
 			name:   "empty string",
 			key:    "",
@@ -194,8 +315,8 @@ func TestParseRequestKey(t *testing.T) {
 
 	for _, tc := range testCases {
 		result, _ := ParseRequestKey(tc.key)
-		if result.Subject != tc.result.Subject {
-			t.Fatalf("ParseRequestKey output expected %v actual %v", tc.result.Subject, result.Subject)
+		if result.Subject != tc.result.Subject || result.Namespace != tc.result.Namespace || result.Operation != tc.result.Operation || !result.VerificationTime.Equal(tc.result.VerificationTime) || result.ImageID != tc.result.ImageID {
+			t.Fatalf("ParseRequestKey output expected %v actual %v", tc.result, result)
 		}
 	}
 }
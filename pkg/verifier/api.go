@@ -17,6 +17,7 @@ package verifier
 
 import (
 	"context"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
@@ -30,9 +31,25 @@ type VerifierResult struct { //nolint:revive // ignore linter to have unique typ
 	Name          string           `json:"name,omitempty"`
 	Type          string           `json:"type,omitempty"`
 	Message       string           `json:"message,omitempty"`
+	Inconclusive  bool             `json:"inconclusive,omitempty"`
+	Signers       []Signer         `json:"signers,omitempty"`
 	Extensions    interface{}      `json:"extensions,omitempty"`
 	NestedResults []VerifierResult `json:"nestedResults,omitempty"`
 	ArtifactType  string           `json:"artifactType,omitempty"`
+	// ReferenceDigest is the digest of the verified artifact.
+	ReferenceDigest string `json:"referenceDigest,omitempty"`
+	// LinkedDigest is the digest of the artifact the nested results were
+	// verified for, e.g. the manifest covered by nested signatures. It equals
+	// ReferenceDigest when the nested results cover the evaluated artifact.
+	LinkedDigest string `json:"linkedDigest,omitempty"`
+	// ErrorCode is the code of the error that failed the verification.
+	ErrorCode string `json:"errorCode,omitempty"`
+	// VerifiedAt is the time the verifier completed.
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+	// ValidUntil is the earliest expiry of the trust material the result
+	// relies on, e.g. the notAfter of a certificate or the nextUpdate of a CRL.
+	// The result must not be reused after it.
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
 }
 
 // ReferenceVerifier is an interface that defines methods to verify a reference
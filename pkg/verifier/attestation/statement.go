@@ -0,0 +1,88 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common/dsse"
+	"github.com/opencontainers/go-digest"
+)
+
+// StatementTypePrefix is the prefix of the _type field of all in-toto statement versions.
+const StatementTypePrefix = "https://in-toto.io/Statement/"
+
+// Statement is the subset of an in-toto statement needed to cross-check the
+// attestation against the artifact it is attached to.
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate,omitempty"`
+}
+
+// Subject is an artifact an in-toto statement refers to.
+type Subject struct {
+	Name   string            `json:"name,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+// SubjectMismatchDetail is the error detail returned when an attestation was
+// attached to a subject it does not refer to.
+type SubjectMismatchDetail struct {
+	Expected string    `json:"expected"`
+	Subjects []Subject `json:"subjects"`
+}
+
+// ParseStatement parses an in-toto statement from the blob. The statement may
+// be wrapped in a DSSE envelope. Returns false if the blob is not an in-toto
+// statement.
+func ParseStatement(blob []byte) (*Statement, bool, error) {
+	if envelope, err := dsse.Decode(blob); err == nil {
+		payload, err := envelope.DecodePayload()
+		if err != nil {
+			return nil, false, err
+		}
+		blob = payload
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(blob, &statement); err != nil {
+		return nil, false, fmt.Errorf("failed to parse attestation: %w", err)
+	}
+	if !strings.HasPrefix(statement.Type, StatementTypePrefix) {
+		return nil, false, nil
+	}
+	return &statement, true, nil
+}
+
+// VerifySubject returns an error with code ErrorCodeSubjectMismatch if none of
+// the statement subjects carries the given digest.
+func (s *Statement) VerifySubject(subjectDigest digest.Digest) error {
+	for _, subject := range s.Subject {
+		if value, ok := subject.Digest[subjectDigest.Algorithm().String()]; ok && strings.EqualFold(value, subjectDigest.Encoded()) {
+			return nil
+		}
+	}
+	detail := SubjectMismatchDetail{
+		Expected: subjectDigest.String(),
+		Subjects: s.Subject,
+	}
+	return re.ErrorCodeSubjectMismatch.NewError(re.Verifier, "", re.EmptyLink, nil, detail, re.HideStackTrace)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	testDigest  = digest.Digest("sha256:b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e1")
+	otherDigest = digest.Digest("sha256:1111111111111111111111111111111111111111111111111111111111111111")
+)
+
+func statementFor(d digest.Digest) string {
+	return fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2","subject":[{"name":"test","digest":{"%s":"%s"}}],"predicate":{}}`, d.Algorithm(), d.Encoded())
+}
+
+func TestParseStatement(t *testing.T) {
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":"%s","signatures":[]}`, base64.StdEncoding.EncodeToString([]byte(statementFor(testDigest))))
+
+	tests := []struct {
+		name        string
+		blob        string
+		isStatement bool
+		expectErr   bool
+	}{
+		{
+			name:        "in-toto statement",
+			blob:        statementFor(testDigest),
+			isStatement: true,
+		},
+		{
+			name:        "statement in DSSE envelope",
+			blob:        envelope,
+			isStatement: true,
+		},
+		{
+			name:        "not an attestation",
+			blob:        `{"version":"2.1.0","runs":[]}`,
+			isStatement: false,
+		},
+		{
+			name:      "invalid json",
+			blob:      `{`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid envelope payload",
+			blob:      `{"payloadType":"application/vnd.in-toto+json","payload":"!!"}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, ok, err := ParseStatement([]byte(tt.blob))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tt.expectErr, err)
+			}
+			if ok != tt.isStatement {
+				t.Fatalf("expected statement: %t, got: %t", tt.isStatement, ok)
+			}
+			if ok && len(statement.Subject) != 1 {
+				t.Fatalf("expected 1 subject, got %d", len(statement.Subject))
+			}
+		})
+	}
+}
+
+func TestVerifySubject(t *testing.T) {
+	statement, _, err := ParseStatement([]byte(statementFor(testDigest)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := statement.VerifySubject(testDigest); err != nil {
+		t.Fatalf("expected subject to match, got: %v", err)
+	}
+
+	err = statement.VerifySubject(otherDigest)
+	if !errors.Is(err, re.ErrorCodeSubjectMismatch.WithDetail("")) {
+		t.Fatalf("expected subject mismatch error, got: %v", err)
+	}
+	var ratifyErr re.Error
+	if !errors.As(err, &ratifyErr) {
+		t.Fatalf("expected ratify error, got: %v", err)
+	}
+	detail, ok := ratifyErr.Detail.(SubjectMismatchDetail)
+	if !ok || detail.Expected != otherDigest.String() || len(detail.Subjects) != 1 {
+		t.Fatalf("unexpected error detail: %+v", ratifyErr.Detail)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+)
+
+// CircuitBreaker skips a verifier for a while after repeated timeouts or
+// errors. Once the open duration elapsed the verifier is tried again and a
+// single further failure opens the breaker again.
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+	now              func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker returns a breaker that opens after failureThreshold
+// consecutive failures and skips the verifier for openDuration.
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		now:              time.Now,
+	}
+}
+
+// allow returns true if the verifier may run.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.openUntil)
+}
+
+// record counts the outcome of a verifier run and opens the breaker once the
+// failure threshold is reached.
+func (b *CircuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.openUntil = b.now().Add(b.openDuration)
+		// retry after the open duration, one failure opens the breaker again
+		b.failures = b.failureThreshold - 1
+	}
+}
+
+type guardedVerifier struct {
+	ReferenceVerifier
+	timeout time.Duration
+	breaker *CircuitBreaker
+}
+
+// WithGuard wraps the verifier to bound each verification by the timeout and
+// to skip the verifier while the circuit breaker is open. A zero timeout or a
+// nil breaker disables the respective guard. Skipped and timed out
+// verifications are inconclusive, so their outcome is decided by the policy.
+func WithGuard(verifier ReferenceVerifier, timeout time.Duration, breaker *CircuitBreaker) ReferenceVerifier {
+	return &guardedVerifier{
+		ReferenceVerifier: verifier,
+		timeout:           timeout,
+		breaker:           breaker,
+	}
+}
+
+func (v *guardedVerifier) Verify(ctx context.Context, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (VerifierResult, error) {
+	if v.breaker != nil && !v.breaker.allow() {
+		return VerifierResult{IsSuccess: false}, re.ErrorCodeVerificationInconclusive.NewError(re.Verifier, v.Name(), re.EmptyLink, nil, "verifier is skipped after repeated failures", re.HideStackTrace)
+	}
+
+	result, err := v.verifyWithTimeout(ctx, subjectReference, referenceDescriptor, referrerStore)
+	// failures caused by the request being canceled are not the verifier's fault
+	if v.breaker != nil && ctx.Err() == nil {
+		v.breaker.record(err != nil)
+	}
+	return result, err
+}
+
+// CheckHealth reports the health of the wrapped verifier, an open circuit
+// breaker does not make the verifier unhealthy since it is tried again later.
+func (v *guardedVerifier) CheckHealth(ctx context.Context) error {
+	return CheckHealth(ctx, v.ReferenceVerifier)
+}
+
+func (v *guardedVerifier) Warm(ctx context.Context) error {
+	return Warm(ctx, v.ReferenceVerifier)
+}
+
+type verifyOutcome struct {
+	result VerifierResult
+	err    error
+}
+
+// verifyWithTimeout returns once the verifier finished or the timeout elapsed,
+// even if the verifier does not honor the canceled context.
+func (v *guardedVerifier) verifyWithTimeout(ctx context.Context, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (VerifierResult, error) {
+	if v.timeout <= 0 {
+		return v.ReferenceVerifier.Verify(ctx, subjectReference, referenceDescriptor, referrerStore)
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	done := make(chan verifyOutcome, 1)
+	go func() {
+		result, err := v.ReferenceVerifier.Verify(verifyCtx, subjectReference, referenceDescriptor, referrerStore)
+		done <- verifyOutcome{result: result, err: err}
+	}()
+
+	select {
+	case outcome := <-done:
+		if outcome.err != nil && ctx.Err() == nil && errors.Is(verifyCtx.Err(), context.DeadlineExceeded) {
+			return outcome.result, v.timeoutError()
+		}
+		return outcome.result, outcome.err
+	case <-verifyCtx.Done():
+		if ctx.Err() != nil {
+			return VerifierResult{IsSuccess: false}, ctx.Err()
+		}
+		return VerifierResult{IsSuccess: false}, v.timeoutError()
+	}
+}
+
+func (v *guardedVerifier) timeoutError() error {
+	return re.ErrorCodeVerificationInconclusive.NewError(re.Verifier, v.Name(), re.EmptyLink, nil, fmt.Sprintf("verifier timed out after %s", v.timeout), re.HideStackTrace)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+)
+
+type testVerifier struct {
+	calls  int
+	delay  time.Duration
+	verify func() (VerifierResult, error)
+}
+
+func (v *testVerifier) Name() string {
+	return "test-verifier"
+}
+
+func (v *testVerifier) Type() string {
+	return "test"
+}
+
+func (v *testVerifier) CanVerify(_ context.Context, _ ocispecs.ReferenceDescriptor) bool {
+	return true
+}
+
+func (v *testVerifier) Verify(_ context.Context, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ referrerstore.ReferrerStore) (VerifierResult, error) {
+	v.calls++
+	// ignores the context like a misbehaving plugin
+	time.Sleep(v.delay)
+	return v.verify()
+}
+
+func (v *testVerifier) GetNestedReferences() []string {
+	return nil
+}
+
+func TestGuardedVerifier_Timeout(t *testing.T) {
+	slow := &testVerifier{delay: time.Second, verify: func() (VerifierResult, error) {
+		return VerifierResult{IsSuccess: true}, nil
+	}}
+	guarded := WithGuard(slow, 10*time.Millisecond, nil)
+
+	start := time.Now()
+	_, err := guarded.Verify(context.Background(), common.Reference{}, ocispecs.ReferenceDescriptor{}, nil)
+	if !IsInconclusive(err) {
+		t.Fatalf("expected inconclusive timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= slow.delay {
+		t.Fatalf("expected verification to return after the timeout, took %s", elapsed)
+	}
+	if guarded.Name() != slow.Name() {
+		t.Fatalf("expected guarded verifier to keep its name, got %s", guarded.Name())
+	}
+}
+
+func TestGuardedVerifier_CircuitBreaker(t *testing.T) {
+	failing := true
+	flaky := &testVerifier{verify: func() (VerifierResult, error) {
+		if failing {
+			return VerifierResult{IsSuccess: false}, errors.New("plugin crashed")
+		}
+		return VerifierResult{IsSuccess: true}, nil
+	}}
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	guarded := WithGuard(flaky, 0, breaker)
+	verify := func() error {
+		_, err := guarded.Verify(context.Background(), common.Reference{}, ocispecs.ReferenceDescriptor{}, nil)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := verify(); err == nil || IsInconclusive(err) {
+			t.Fatalf("expected verifier error, got %v", err)
+		}
+	}
+	if err := verify(); !IsInconclusive(err) || flaky.calls != 2 {
+		t.Fatalf("expected open breaker to skip the verifier, got %v after %d calls", err, flaky.calls)
+	}
+
+	// a single failure after the open duration opens the breaker again
+	now = now.Add(time.Minute)
+	if err := verify(); err == nil || IsInconclusive(err) {
+		t.Fatalf("expected verifier to be retried, got %v", err)
+	}
+	if err := verify(); !IsInconclusive(err) {
+		t.Fatalf("expected breaker to open again, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	failing = false
+	if err := verify(); err != nil {
+		t.Fatalf("expected verifier to recover, got %v", err)
+	}
+	failing = true
+	if err := verify(); err == nil || IsInconclusive(err) {
+		t.Fatalf("expected breaker to stay closed after a success, got %v", err)
+	}
+}
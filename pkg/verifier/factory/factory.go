@@ -16,21 +16,30 @@ limitations under the License.
 package factory
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/internal/constants"
 	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
 	"github.com/deislabs/ratify/pkg/featureflag"
+	"github.com/deislabs/ratify/pkg/pluginmanager"
 	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/deislabs/ratify/pkg/verifier/config"
 	"github.com/deislabs/ratify/pkg/verifier/plugin"
 	"github.com/deislabs/ratify/pkg/verifier/types"
+	"github.com/deislabs/ratify/pkg/verifier/wasm"
 	"github.com/sirupsen/logrus"
 )
 
+// defaultOpenDuration is how long a verifier is skipped once its circuit breaker opens
+const defaultOpenDuration = "30s"
+
 var builtInVerifiers = make(map[string]VerifierFactory)
 
 type VerifierFactory interface {
@@ -77,21 +86,159 @@ func CreateVerifierFromConfig(verifierConfig config.VerifierConfig, configVersio
 			}
 
 			targetPath := path.Join(pluginBinDir[0], verifierTypeStr)
-			err = pluginCommon.DownloadPlugin(source, targetPath)
+			downloaded, err := pluginmanager.Install(context.TODO(), source, targetPath)
 			if err != nil {
 				return nil, re.ErrorCodeDownloadPluginFailure.NewError(re.Verifier, "", re.EmptyLink, err, "failed to download plugin", re.HideStackTrace)
 			}
-			logrus.Infof("downloaded verifier plugin %s from %s to %s", verifierTypeStr, source.Artifact, targetPath)
+			if downloaded {
+				logrus.Infof("downloaded verifier plugin %s from %s to %s", verifierTypeStr, source.Artifact, targetPath)
+			} else {
+				logrus.Infof("verifier plugin %s from %s is up to date at %s", verifierTypeStr, source.Artifact, targetPath)
+			}
 		} else {
 			logrus.Warnf("%s was specified for verifier plugin type %s, but dynamic plugins are currently disabled", types.Source, verifierTypeStr)
 		}
 	}
 
-	verifierFactory, ok := builtInVerifiers[verifierTypeStr]
-	if ok {
-		return verifierFactory.Create(configVersion, verifierConfig, pluginBinDir[0], namespace)
+	var referenceVerifier verifier.ReferenceVerifier
+	var err error
+	if verifierFactory, ok := builtInVerifiers[verifierTypeStr]; ok {
+		referenceVerifier, err = verifierFactory.Create(configVersion, verifierConfig, pluginBinDir[0], namespace)
+	} else if modulePath := findWasmModule(verifierTypeStr, pluginBinDir); modulePath != "" {
+		referenceVerifier, err = wasm.NewVerifier(configVersion, verifierConfig, modulePath)
+	} else {
+		referenceVerifier, err = plugin.NewVerifier(configVersion, withKeyManagementProviderNamespace(verifierConfig, namespace), pluginBinDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if referenceVerifier, err = withGuard(referenceVerifier, verifierConfig); err != nil {
+		return nil, err
 	}
-	return plugin.NewVerifier(configVersion, verifierConfig, pluginBinDir)
+	return withOrdering(referenceVerifier, verifierConfig)
+}
+
+// findWasmModule returns the path of the WebAssembly module of the verifier
+// type if WebAssembly plugins are enabled, modules take precedence over plugin
+// executables of the same name
+func findWasmModule(verifierType string, pluginBinDir []string) string {
+	if !featureflag.WasmPlugins.Enabled {
+		return ""
+	}
+	return wasm.FindModule(verifierType, pluginBinDir)
+}
+
+// withOrdering wraps the verifier if the config declares a priority or dependencies
+func withOrdering(referenceVerifier verifier.ReferenceVerifier, verifierConfig config.VerifierConfig) (verifier.ReferenceVerifier, error) {
+	priorityValue, hasPriority := verifierConfig[types.Priority]
+	dependsOnValue, hasDependsOn := verifierConfig[types.DependsOn]
+	if !hasPriority && !hasDependsOn {
+		return referenceVerifier, nil
+	}
+
+	var priority int
+	switch value := priorityValue.(type) {
+	case nil:
+	case int:
+		priority = value
+	case float64:
+		priority = int(value)
+	default:
+		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("%s of verifier %s must be an integer", types.Priority, referenceVerifier.Name()))
+	}
+
+	var dependsOn []string
+	switch value := dependsOnValue.(type) {
+	case nil:
+	case string:
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				dependsOn = append(dependsOn, name)
+			}
+		}
+	case []string:
+		dependsOn = value
+	case []interface{}:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("%s of verifier %s must be a list of verifier names", types.DependsOn, referenceVerifier.Name()))
+			}
+			dependsOn = append(dependsOn, name)
+		}
+	default:
+		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("%s of verifier %s must be a list of verifier names", types.DependsOn, referenceVerifier.Name()))
+	}
+	for _, name := range dependsOn {
+		if name == referenceVerifier.Name() {
+			return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("verifier %s cannot depend on itself", name))
+		}
+	}
+	return verifier.WithOrdering(referenceVerifier, priority, dependsOn), nil
+}
+
+// circuitBreakerConfig opens the circuit breaker of a verifier after
+// FailureThreshold consecutive timeouts or errors for OpenDuration.
+type circuitBreakerConfig struct {
+	FailureThreshold int    `json:"failureThreshold"`
+	OpenDuration     string `json:"openDuration,omitempty"`
+}
+
+// withKeyManagementProviderNamespace returns a copy of the config with the
+// namespace prepended to the key management provider if it is not namespaced.
+func withKeyManagementProviderNamespace(verifierConfig config.VerifierConfig, namespace string) config.VerifierConfig {
+	kmp, ok := verifierConfig[types.KeyManagementProvider].(string)
+	if !ok || namespace == "" || strings.Contains(kmp, constants.NamespaceSeperator) {
+		return verifierConfig
+	}
+	namespacedConfig := make(config.VerifierConfig, len(verifierConfig))
+	for key, value := range verifierConfig {
+		namespacedConfig[key] = value
+	}
+	namespacedConfig[types.KeyManagementProvider] = namespace + constants.NamespaceSeperator + kmp
+	return namespacedConfig
+}
+
+// withGuard wraps the verifier if the config declares a timeout or a circuit breaker
+func withGuard(referenceVerifier verifier.ReferenceVerifier, verifierConfig config.VerifierConfig) (verifier.ReferenceVerifier, error) {
+	timeoutValue, hasTimeout := verifierConfig[types.Timeout]
+	breakerValue, hasBreaker := verifierConfig[types.CircuitBreaker]
+	if !hasTimeout && !hasBreaker {
+		return referenceVerifier, nil
+	}
+
+	var timeout time.Duration
+	if hasTimeout {
+		value, ok := timeoutValue.(string)
+		if !ok {
+			return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("%s of verifier %s must be a duration, e.g. 1s", types.Timeout, referenceVerifier.Name()))
+		}
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 {
+			return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("%s of verifier %s must be a positive duration, got %s", types.Timeout, referenceVerifier.Name(), value))
+		}
+	}
+
+	var breaker *verifier.CircuitBreaker
+	if hasBreaker {
+		breakerConfig := circuitBreakerConfig{OpenDuration: defaultOpenDuration}
+		raw, err := json.Marshal(breakerValue)
+		if err == nil {
+			err = json.Unmarshal(raw, &breakerConfig)
+		}
+		if err != nil {
+			return nil, re.ErrorCodeConfigInvalid.NewError(re.Verifier, referenceVerifier.Name(), re.EmptyLink, err, fmt.Sprintf("failed to parse %s of verifier", types.CircuitBreaker), re.HideStackTrace)
+		}
+		if breakerConfig.FailureThreshold <= 0 {
+			return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("failureThreshold of the %s of verifier %s must be positive", types.CircuitBreaker, referenceVerifier.Name()))
+		}
+		openDuration, err := time.ParseDuration(breakerConfig.OpenDuration)
+		if err != nil || openDuration <= 0 {
+			return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("openDuration of the %s of verifier %s must be a positive duration, got %s", types.CircuitBreaker, referenceVerifier.Name(), breakerConfig.OpenDuration))
+		}
+		breaker = verifier.NewCircuitBreaker(breakerConfig.FailureThreshold, openDuration)
+	}
+	return verifier.WithGuard(referenceVerifier, timeout, breaker), nil
 }
 
 // TODO pointer to avoid copy
@@ -126,9 +273,58 @@ func CreateVerifiersFromConfig(verifiersConfig config.VerifiersConfig, defaultPl
 		verifiers = append(verifiers, verifier)
 	}
 
+	if err := validateVerifierDependencies(verifiers); err != nil {
+		return nil, err
+	}
 	return verifiers, nil
 }
 
+// validateVerifierDependencies checks that verifiers only depend on configured
+// verifiers and that the dependencies do not form a cycle
+func validateVerifierDependencies(verifiers []verifier.ReferenceVerifier) error {
+	dependencies := map[string][]string{}
+	for _, referenceVerifier := range verifiers {
+		dependencies[referenceVerifier.Name()] = verifier.GetDependencies(referenceVerifier)
+	}
+	for name, dependsOn := range dependencies {
+		for _, dependency := range dependsOn {
+			if _, ok := dependencies[dependency]; !ok {
+				return re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("verifier %s depends on unknown verifier %s", name, dependency))
+			}
+		}
+	}
+
+	// depth first search, visiting verifiers are on the current path
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithDetail(fmt.Sprintf("verifier dependencies contain a cycle through %s", name))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, referenceVerifier := range verifiers {
+		if err := visit(referenceVerifier.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateVerifiersConfig(_ *config.VerifiersConfig) error {
 	// TODO check for existence of plugin dirs
 	// TODO check if version is supported
@@ -27,6 +27,7 @@ import (
 	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/deislabs/ratify/pkg/verifier/config"
 	"github.com/deislabs/ratify/pkg/verifier/plugin"
+	"github.com/deislabs/ratify/pkg/verifier/types"
 )
 
 type TestVerifier struct {
@@ -128,3 +129,154 @@ func TestCreateVerifiersFromConfig_PluginVerifiers_ReturnsExpected(t *testing.T)
 		t.Fatalf("type assertion failed expected a plugin in verifier")
 	}
 }
+
+func TestCreateVerifiersFromConfig_Ordering(t *testing.T) {
+	testCases := []struct {
+		name      string
+		verifiers []config.VerifierConfig
+		priority  int
+		isSuccess bool
+	}{
+		{
+			name: "priority and dependencies",
+			verifiers: []config.VerifierConfig{
+				{"name": "schema"},
+				{"name": "content", "priority": float64(10), "dependsOn": []interface{}{"schema"}},
+			},
+			priority:  10,
+			isSuccess: true,
+		},
+		{
+			name: "comma separated dependencies",
+			verifiers: []config.VerifierConfig{
+				{"name": "schema"},
+				{"name": "signature"},
+				{"name": "content", "dependsOn": "schema, signature"},
+			},
+			isSuccess: true,
+		},
+		{
+			name: "unknown dependency",
+			verifiers: []config.VerifierConfig{
+				{"name": "content", "dependsOn": []interface{}{"schema"}},
+			},
+		},
+		{
+			name: "dependency cycle",
+			verifiers: []config.VerifierConfig{
+				{"name": "schema", "dependsOn": []interface{}{"content"}},
+				{"name": "content", "dependsOn": []interface{}{"schema"}},
+			},
+		},
+		{
+			name: "self dependency",
+			verifiers: []config.VerifierConfig{
+				{"name": "content", "dependsOn": []interface{}{"content"}},
+			},
+		},
+		{
+			name: "invalid priority",
+			verifiers: []config.VerifierConfig{
+				{"name": "content", "priority": "high"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifiers, err := CreateVerifiersFromConfig(config.VerifiersConfig{Verifiers: tc.verifiers}, "", "")
+			if (err == nil) != tc.isSuccess {
+				t.Fatalf("expected success %v, got error %v", tc.isSuccess, err)
+			}
+			if err != nil {
+				return
+			}
+			content := verifiers[len(verifiers)-1]
+			if content.Name() != "content" {
+				t.Fatalf("expected wrapped verifier to keep its name, got %s", content.Name())
+			}
+			if dependencies := verifier.GetDependencies(content); len(dependencies) != len(tc.verifiers)-1 {
+				t.Fatalf("expected %d dependencies, got %v", len(tc.verifiers)-1, dependencies)
+			}
+			if priority := verifier.GetPriority(content); priority != tc.priority {
+				t.Fatalf("expected priority %d, got %d", tc.priority, priority)
+			}
+		})
+	}
+}
+
+func TestCreateVerifiersFromConfig_Guard(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    config.VerifierConfig
+		isSuccess bool
+	}{
+		{
+			name:      "timeout and circuit breaker",
+			config:    config.VerifierConfig{"name": "content", "timeout": "1s", "circuitBreaker": map[string]interface{}{"failureThreshold": float64(3), "openDuration": "1m"}},
+			isSuccess: true,
+		},
+		{
+			name:      "default open duration",
+			config:    config.VerifierConfig{"name": "content", "circuitBreaker": map[string]interface{}{"failureThreshold": float64(3)}},
+			isSuccess: true,
+		},
+		{
+			name:   "invalid timeout",
+			config: config.VerifierConfig{"name": "content", "timeout": float64(1000)},
+		},
+		{
+			name:   "negative timeout",
+			config: config.VerifierConfig{"name": "content", "timeout": "-1s"},
+		},
+		{
+			name:   "missing failure threshold",
+			config: config.VerifierConfig{"name": "content", "circuitBreaker": map[string]interface{}{"openDuration": "1m"}},
+		},
+		{
+			name:   "invalid open duration",
+			config: config.VerifierConfig{"name": "content", "circuitBreaker": map[string]interface{}{"failureThreshold": float64(3), "openDuration": "soon"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifiers, err := CreateVerifiersFromConfig(config.VerifiersConfig{Verifiers: []config.VerifierConfig{tc.config}}, "", "")
+			if (err == nil) != tc.isSuccess {
+				t.Fatalf("expected success %v, got error %v", tc.isSuccess, err)
+			}
+			if err == nil && verifiers[0].Name() != "content" {
+				t.Fatalf("expected guarded verifier to keep its name, got %s", verifiers[0].Name())
+			}
+		})
+	}
+}
+
+func TestWithKeyManagementProviderNamespace(t *testing.T) {
+	testCases := []struct {
+		name      string
+		kmp       interface{}
+		namespace string
+		expected  interface{}
+	}{
+		{name: "no key management provider", namespace: "default"},
+		{name: "name prefixed with namespace", kmp: "kmp", namespace: "default", expected: "default/kmp"},
+		{name: "namespaced name kept", kmp: "other/kmp", namespace: "default", expected: "other/kmp"},
+		{name: "no namespace", kmp: "kmp", expected: "kmp"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifierConfig := config.VerifierConfig{types.Name: "test"}
+			if tc.kmp != nil {
+				verifierConfig[types.KeyManagementProvider] = tc.kmp
+			}
+			result := withKeyManagementProviderNamespace(verifierConfig, tc.namespace)
+			if result[types.KeyManagementProvider] != tc.expected {
+				t.Fatalf("expected key management provider %v, got %v", tc.expected, result[types.KeyManagementProvider])
+			}
+			if verifierConfig[types.KeyManagementProvider] != tc.kmp {
+				t.Fatalf("verifier config must not be modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import "context"
+
+// HealthChecker is implemented by verifiers that can report whether they are
+// able to run, e.g. whether their plugin is installed.
+type HealthChecker interface {
+	// CheckHealth returns an error if the verifier cannot run.
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckHealth returns an error if the verifier reports that it cannot run,
+// verifiers not implementing HealthChecker are considered healthy.
+func CheckHealth(ctx context.Context, verifier ReferenceVerifier) error {
+	if checker, ok := verifier.(HealthChecker); ok {
+		return checker.CheckHealth(ctx)
+	}
+	return nil
+}
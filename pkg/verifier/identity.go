@@ -0,0 +1,203 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// IssuerExtensionKey is the extension key holding the issuer of the signing certificate.
+	IssuerExtensionKey = "Issuer"
+	// SubjectExtensionKey is the extension key holding the subject of the signing certificate.
+	SubjectExtensionKey = "SN"
+)
+
+const (
+	// SignerKindX509 is a signer identified by the subject of its certificate.
+	SignerKindX509 = "x509"
+	// SignerKindFulcio is a keyless signer identified by the OIDC issuer and
+	// subject alternative name of its Fulcio certificate.
+	SignerKindFulcio = "fulcio"
+	// SignerKindKey is a signer identified by the fingerprint of its public key.
+	SignerKindKey = "key"
+)
+
+const (
+	// SignerAttributeID identifies signers by their normalized identity.
+	SignerAttributeID = "id"
+	// SignerAttributeIssuer identifies signers by the issuer of their certificate.
+	SignerAttributeIssuer = "issuer"
+	// SignerAttributeKey identifies signers by the fingerprint of their public key.
+	SignerAttributeKey = "key"
+)
+
+var (
+	// OIDs of the Fulcio certificate extensions holding the OIDC issuer
+	// https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md
+	oidFulcioIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// Signer is the identity of the signer of a verified signature. It is
+// normalized across signature formats so that policies and audits reference
+// signers consistently.
+type Signer struct {
+	// ID is the normalized identity of the signer: x509:<issuer>/<subject>,
+	// fulcio:<OIDC issuer>/<subject alternative name> or key:<fingerprint>.
+	ID string `json:"id"`
+	// Kind is one of x509, fulcio or key.
+	Kind string `json:"kind"`
+	// Subject is the distinguished name of the signing certificate.
+	Subject string `json:"subject,omitempty"`
+	// Issuer is the distinguished name of the certificate issuer, or the OIDC
+	// issuer for Fulcio certificates.
+	Issuer string `json:"issuer,omitempty"`
+	// SANs are the subject alternative names of the signing certificate.
+	SANs []string `json:"sans,omitempty"`
+	// KeyFingerprint is the SHA-256 digest of the DER encoded public key.
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+}
+
+// NewCertificateSigner returns the signer of the signing certificate.
+// Certificates issued by Fulcio are identified by their OIDC issuer and
+// subject alternative name since their subject is empty.
+func NewCertificateSigner(cert *x509.Certificate) Signer {
+	signer := Signer{
+		Kind:    SignerKindX509,
+		Subject: cert.Subject.String(),
+		Issuer:  cert.Issuer.String(),
+		SANs:    subjectAlternativeNames(cert),
+	}
+	if fingerprint, err := keyFingerprint(cert.PublicKey); err == nil {
+		signer.KeyFingerprint = fingerprint
+	}
+
+	if oidcIssuer := fulcioIssuer(cert); oidcIssuer != "" && len(signer.SANs) > 0 {
+		signer.Kind = SignerKindFulcio
+		signer.Issuer = oidcIssuer
+		signer.ID = fmt.Sprintf("%s:%s/%s", SignerKindFulcio, oidcIssuer, signer.SANs[0])
+		return signer
+	}
+	signer.ID = fmt.Sprintf("%s:%s/%s", SignerKindX509, signer.Issuer, signer.Subject)
+	return signer
+}
+
+// NewKeySigner returns the signer of the public key.
+func NewKeySigner(publicKey crypto.PublicKey) (Signer, error) {
+	fingerprint, err := keyFingerprint(publicKey)
+	if err != nil {
+		return Signer{}, err
+	}
+	return Signer{
+		ID:             fmt.Sprintf("%s:%s", SignerKindKey, fingerprint),
+		Kind:           SignerKindKey,
+		KeyFingerprint: fingerprint,
+	}, nil
+}
+
+// Identity returns the identity of the signer by the attribute, or an empty
+// string if the signer does not have the attribute.
+func (s Signer) Identity(attribute string) string {
+	switch attribute {
+	case SignerAttributeIssuer:
+		if s.Issuer == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s:%s", s.Kind, s.Issuer)
+	case SignerAttributeKey:
+		return s.KeyFingerprint
+	default:
+		return s.ID
+	}
+}
+
+// Signers returns the signers of the signatures verified by a successful
+// result. Results of verifiers that do not report signers are identified by
+// the issuer and subject of the signing certificate in their extensions.
+func Signers(result VerifierResult) []Signer {
+	if !result.IsSuccess {
+		return nil
+	}
+	if len(result.Signers) > 0 {
+		return result.Signers
+	}
+
+	var issuer, subject string
+	switch extensions := result.Extensions.(type) {
+	case map[string]string:
+		issuer = extensions[IssuerExtensionKey]
+		subject = extensions[SubjectExtensionKey]
+	case map[string]interface{}:
+		// results returned by verifier plugins are decoded from JSON
+		issuer, _ = extensions[IssuerExtensionKey].(string)
+		subject, _ = extensions[SubjectExtensionKey].(string)
+	}
+
+	if subject == "" {
+		return nil
+	}
+	return []Signer{{
+		ID:      fmt.Sprintf("%s:%s/%s", SignerKindX509, issuer, subject),
+		Kind:    SignerKindX509,
+		Subject: subject,
+		Issuer:  issuer,
+	}}
+}
+
+func keyFingerprint(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func subjectAlternativeNames(cert *x509.Certificate) []string {
+	var sans []string
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+// fulcioIssuer returns the OIDC issuer of a Fulcio certificate.
+func fulcioIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidFulcioIssuer):
+			// the deprecated extension holds the issuer as raw string
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"strings"
+	"testing"
+)
+
+func TestSigners(t *testing.T) {
+	reported := Signer{ID: "key:sha256:abc", Kind: SignerKindKey, KeyFingerprint: "sha256:abc"}
+	tests := []struct {
+		name     string
+		result   VerifierResult
+		expected string
+	}{
+		{
+			name: "reported signers",
+			result: VerifierResult{
+				IsSuccess:  true,
+				Signers:    []Signer{reported},
+				Extensions: map[string]string{"Issuer": "CN=ca", "SN": "CN=builder"},
+			},
+			expected: "key:sha256:abc",
+		},
+		{
+			name: "built-in verifier extensions",
+			result: VerifierResult{
+				IsSuccess:  true,
+				Extensions: map[string]string{"Issuer": "CN=ca", "SN": "CN=builder"},
+			},
+			expected: "x509:CN=ca/CN=builder",
+		},
+		{
+			name: "plugin verifier extensions",
+			result: VerifierResult{
+				IsSuccess:  true,
+				Extensions: map[string]interface{}{"Issuer": "CN=ca", "SN": "CN=security"},
+			},
+			expected: "x509:CN=ca/CN=security",
+		},
+		{
+			name: "failed verification",
+			result: VerifierResult{
+				IsSuccess: false,
+				Signers:   []Signer{reported},
+			},
+		},
+		{
+			name: "no signer information",
+			result: VerifierResult{
+				IsSuccess:  true,
+				Extensions: map[string]interface{}{"creationInfo": "test"},
+			},
+		},
+		{
+			name:   "no extensions",
+			result: VerifierResult{IsSuccess: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signers := Signers(tt.result)
+			if tt.expected == "" {
+				if len(signers) != 0 {
+					t.Fatalf("expected no signers, got %+v", signers)
+				}
+				return
+			}
+			if len(signers) != 1 || signers[0].ID != tt.expected {
+				t.Fatalf("expected signer %s, got %+v", tt.expected, signers)
+			}
+		})
+	}
+}
+
+func TestNewCertificateSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	issuerV2, _ := asn1.Marshal("https://token.actions.githubusercontent.com")
+
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{Organization: []string{"ratify"}, CommonName: "builder"},
+		Issuer:         pkix.Name{CommonName: "ca"},
+		EmailAddresses: []string{"builder@example.com"},
+		PublicKey:      &key.PublicKey,
+	}
+	signer := NewCertificateSigner(cert)
+	if signer.Kind != SignerKindX509 || signer.ID != "x509:CN=ca/CN=builder,O=ratify" {
+		t.Fatalf("unexpected x509 signer %+v", signer)
+	}
+	if !strings.HasPrefix(signer.KeyFingerprint, "sha256:") || signer.SANs[0] != "builder@example.com" {
+		t.Fatalf("unexpected x509 signer attributes %+v", signer)
+	}
+
+	for _, ext := range []pkix.Extension{
+		{Id: oidFulcioIssuerV2, Value: issuerV2},
+		{Id: oidFulcioIssuer, Value: []byte("https://token.actions.githubusercontent.com")},
+	} {
+		fulcioCert := &x509.Certificate{
+			Issuer:         pkix.Name{Organization: []string{"sigstore.dev"}, CommonName: "sigstore-intermediate"},
+			EmailAddresses: []string{"builder@example.com"},
+			Extensions:     []pkix.Extension{ext},
+			PublicKey:      &key.PublicKey,
+		}
+		signer = NewCertificateSigner(fulcioCert)
+		if signer.Kind != SignerKindFulcio || signer.ID != "fulcio:https://token.actions.githubusercontent.com/builder@example.com" {
+			t.Fatalf("unexpected fulcio signer %+v", signer)
+		}
+	}
+
+	keySigner, err := NewKeySigner(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keySigner.ID != "key:"+signer.KeyFingerprint {
+		t.Fatalf("expected key signer to have the fingerprint of the certificate key, got %+v", keySigner)
+	}
+	if _, err := NewKeySigner("invalid"); err == nil {
+		t.Fatalf("expected error for invalid public key")
+	}
+}
+
+func TestSignerIdentity(t *testing.T) {
+	signer := Signer{ID: "fulcio:https://issuer/builder@example.com", Kind: SignerKindFulcio, Issuer: "https://issuer", KeyFingerprint: "sha256:abc"}
+	for attribute, expected := range map[string]string{
+		"":                    "fulcio:https://issuer/builder@example.com",
+		SignerAttributeID:     "fulcio:https://issuer/builder@example.com",
+		SignerAttributeIssuer: "fulcio:https://issuer",
+		SignerAttributeKey:    "sha256:abc",
+	} {
+		if identity := signer.Identity(attribute); identity != expected {
+			t.Fatalf("expected identity %s by attribute %q, got %s", expected, attribute, identity)
+		}
+	}
+	if identity := (Signer{ID: "key:sha256:abc", Kind: SignerKindKey}).Identity(SignerAttributeIssuer); identity != "" {
+		t.Fatalf("expected no issuer identity for key signer, got %s", identity)
+	}
+}
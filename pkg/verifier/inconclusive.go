@@ -0,0 +1,55 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"errors"
+	"fmt"
+
+	re "github.com/deislabs/ratify/errors"
+)
+
+// inconclusiveErrors are the errors of verifiers that could not run, as opposed
+// to verifiers that ran and found the artifact untrusted.
+var inconclusiveErrors = []error{
+	re.ErrorCodePluginNotFound.WithDetail(""),
+	re.ErrorCodeVerificationInconclusive.WithDetail(""),
+}
+
+// IsInconclusive returns true if the verification error indicates the verifier
+// could not run, e.g. the plugin is missing or the key management provider is
+// not ready.
+func IsInconclusive(err error) bool {
+	for _, inconclusiveErr := range inconclusiveErrors {
+		if errors.Is(err, inconclusiveErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewInconclusiveResult returns the result of a verifier that could not run
+// with the verification error as the reason.
+func NewInconclusiveResult(verifier ReferenceVerifier, err error) VerifierResult {
+	return VerifierResult{
+		IsSuccess:    false,
+		Inconclusive: true,
+		Name:         verifier.Name(),
+		Type:         verifier.Type(),
+		Message:      fmt.Sprintf("verification inconclusive: %v", err),
+		ErrorCode:    re.CodeOf(err, re.ErrorCodeVerificationInconclusive).String(),
+	}
+}
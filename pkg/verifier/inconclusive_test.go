@@ -0,0 +1,47 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	re "github.com/deislabs/ratify/errors"
+)
+
+func TestIsInconclusive(t *testing.T) {
+	pluginNotFound := re.ErrorCodePluginNotFound.NewError(re.Verifier, "sample", re.EmptyLink, errors.New("not found"), nil, re.HideStackTrace)
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "plugin not found", err: pluginNotFound, expected: true},
+		{name: "wrapped plugin not found", err: re.ErrorCodeVerifyReferenceFailure.NewError(re.Verifier, "sample", re.EmptyLink, pluginNotFound, nil, re.HideStackTrace), expected: true},
+		{name: "inconclusive", err: fmt.Errorf("failed: %w", re.ErrorCodeVerificationInconclusive.WithDetail("certificates not fetched")), expected: true},
+		{name: "verification failure", err: re.ErrorCodeVerifyPluginFailure.WithDetail("invalid signature"), expected: false},
+		{name: "other error", err: errors.New("failed"), expected: false},
+		{name: "no error", err: nil, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := IsInconclusive(tc.err); actual != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
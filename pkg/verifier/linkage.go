@@ -0,0 +1,41 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import "strings"
+
+// SubjectDigest returns the digest of the subject the result was produced for,
+// or an empty string if the subject is not referenced by digest.
+func (vr VerifierResult) SubjectDigest() string {
+	if i := strings.LastIndex(vr.Subject, "@"); i >= 0 {
+		return vr.Subject[i+1:]
+	}
+	return ""
+}
+
+// LinkedNestedResults returns the nested results produced for the artifact of
+// the result itself, e.g. the signatures over the exact manifest the verifier
+// evaluated. Nested results for any other artifact must not be accepted as
+// proof that the evaluated artifact is signed.
+func (vr VerifierResult) LinkedNestedResults() []VerifierResult {
+	var linked []VerifierResult
+	for _, nestedResult := range vr.NestedResults {
+		if vr.ReferenceDigest != "" && nestedResult.SubjectDigest() == vr.ReferenceDigest {
+			linked = append(linked, nestedResult)
+		}
+	}
+	return linked
+}
@@ -0,0 +1,41 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import "testing"
+
+func TestLinkedNestedResults(t *testing.T) {
+	digest := "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	result := VerifierResult{
+		ReferenceDigest: digest,
+		NestedResults: []VerifierResult{
+			{Name: "linked", Subject: "localhost:5000/net-monitor@" + digest},
+			{Name: "other", Subject: "localhost:5000/net-monitor@sha256:2222222222222222222222222222222222222222222222222222222222222222"},
+			{Name: "tag", Subject: "localhost:5000/net-monitor:v1"},
+		},
+	}
+	linked := result.LinkedNestedResults()
+	if len(linked) != 1 || linked[0].Name != "linked" {
+		t.Fatalf("expected only the linked nested result, got %+v", linked)
+	}
+	if got := result.NestedResults[2].SubjectDigest(); got != "" {
+		t.Fatalf("expected no digest for subject referenced by tag, got %s", got)
+	}
+	result.ReferenceDigest = ""
+	if linked := result.LinkedNestedResults(); len(linked) != 0 {
+		t.Fatalf("expected no linked nested results without reference digest, got %+v", linked)
+	}
+}
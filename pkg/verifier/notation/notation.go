@@ -21,6 +21,7 @@ import (
 	"fmt"
 	paths "path/filepath"
 	"strings"
+	"time"
 
 	ratifyconfig "github.com/deislabs/ratify/config"
 	re "github.com/deislabs/ratify/errors"
@@ -34,6 +35,7 @@ import (
 	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/deislabs/ratify/pkg/verifier/config"
 	"github.com/deislabs/ratify/pkg/verifier/factory"
+	"github.com/deislabs/ratify/pkg/verifier/revocation"
 	"github.com/deislabs/ratify/pkg/verifier/types"
 	"github.com/notaryproject/notation-go/log"
 
@@ -42,6 +44,7 @@ import (
 	"github.com/notaryproject/notation-go"
 	notationVerifier "github.com/notaryproject/notation-go/verifier"
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
 	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -61,6 +64,16 @@ type NotationPluginVerifierConfig struct { //nolint:revive // ignore linter to h
 	VerificationCertStores map[string][]string `json:"verificationCertStores"`
 	// TrustPolicyDoc represents a trustpolicy.json document. Reference: https://pkg.go.dev/github.com/notaryproject/notation-go@v0.12.0-beta.1.0.20221125022016-ab113ebd2a6c/verifier/trustpolicy#Document
 	TrustPolicyDoc trustpolicy.Document `json:"trustPolicyDoc"`
+	// TSARootCerts are the files or directories of the root certificates
+	// trusted to issue RFC 3161 timestamps of signatures.
+	TSARootCerts []string `json:"tsaRootCerts,omitempty"`
+	// RequireTimestamp rejects signatures without a timestamp verified with
+	// the TSA root certificates.
+	RequireTimestamp bool `json:"requireTimestamp,omitempty"`
+	// Revocation configures the OCSP and CRL revocation checks of the
+	// certificate chains of signatures, which are enforced as set by the
+	// verification level of the trust policy.
+	Revocation revocation.Config `json:"revocation,omitempty"`
 }
 
 type notationPluginVerifier struct {
@@ -68,6 +81,13 @@ type notationPluginVerifier struct {
 	verifierType     string
 	artifactTypes    []string
 	notationVerifier *notation.Verifier
+	// pastVerifier verifies signatures as of a verification time in the past
+	pastVerifier   *notation.Verifier
+	trustPolicyDoc trustpolicy.Document
+	trustStore     *trustStore
+	// tsaRootCerts are the paths of the TSA root certificates
+	tsaRootCerts     []string
+	requireTimestamp bool
 }
 
 type notationPluginVerifierFactory struct{}
@@ -88,7 +108,18 @@ func (f *notationPluginVerifierFactory) Create(_ string, verifierConfig config.V
 		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithPluginName(verifierName)
 	}
 
-	verifyService, err := getVerifierService(conf, pluginDirectory)
+	revocationChecker, err := revocation.NewChecker(conf.Revocation)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.WithComponentType(re.Verifier).WithPluginName(verifierName).WithError(err)
+	}
+
+	verifyService, err := getVerifierService(conf, pluginDirectory, revocationChecker)
+	if err != nil {
+		return nil, re.ErrorCodePluginInitFailure.WithComponentType(re.Verifier).WithPluginName(verifierName).WithError(err)
+	}
+	pastConf := *conf
+	pastConf.TrustPolicyDoc = pastTrustPolicyDoc(conf.TrustPolicyDoc)
+	pastVerifyService, err := getVerifierService(&pastConf, pluginDirectory, revocationChecker)
 	if err != nil {
 		return nil, re.ErrorCodePluginInitFailure.WithComponentType(re.Verifier).WithPluginName(verifierName).WithError(err)
 	}
@@ -99,6 +130,11 @@ func (f *notationPluginVerifierFactory) Create(_ string, verifierConfig config.V
 		verifierType:     verifierTypeStr,
 		artifactTypes:    artifactTypes,
 		notationVerifier: &verifyService,
+		pastVerifier:     &pastVerifyService,
+		trustPolicyDoc:   conf.TrustPolicyDoc,
+		trustStore:       newTrustStore(conf),
+		tsaRootCerts:     conf.TSARootCerts,
+		requireTimestamp: conf.RequireTimestamp,
 	}, nil
 }
 
@@ -124,6 +160,8 @@ func (v *notationPluginVerifier) Verify(ctx context.Context,
 	referenceDescriptor ocispecs.ReferenceDescriptor,
 	store referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
 	extensions := make(map[string]string)
+	var signers []verifier.Signer
+	var expiry *time.Time
 
 	subjectDesc, err := store.GetSubjectDescriptor(ctx, subjectReference)
 	if err != nil {
@@ -152,11 +190,32 @@ func (v *notationPluginVerifier) Verify(ctx context.Context,
 		if err != nil {
 			return verifier.VerifierResult{IsSuccess: false, Extensions: extensions}, re.ErrorCodeVerifyPluginFailure.NewError(re.Verifier, v.name, re.NotationTsgLink, err, "failed to verify signature of digest", re.HideStackTrace)
 		}
+		verificationTime, past := verifier.VerificationTime(ctx)
+		if !past {
+			verificationTime = time.Now()
+		}
+		timestamped, err := verifyTimestamp(&v.trustPolicyDoc, subjectRef, &outcome.EnvelopeContent.SignerInfo, v.tsaRootCerts, v.requireTimestamp, verificationTime)
+		if err != nil {
+			return verifier.VerifierResult{IsSuccess: false, Extensions: extensions}, re.ErrorCodeVerifyPluginFailure.NewError(re.Verifier, v.name, re.NotationTsgLink, err, "failed to verify timestamp of signature", re.HideStackTrace)
+		}
+		if timestamped != nil {
+			extensions[timestampExtensionKey] = timestamped.UTC().Format(time.RFC3339)
+		}
+		if past {
+			if err := verifyAtTime(&v.trustPolicyDoc, subjectRef, outcome, verificationTime); err != nil {
+				return verifier.VerifierResult{IsSuccess: false, Extensions: extensions}, re.ErrorCodeVerifyPluginFailure.NewError(re.Verifier, v.name, re.NotationTsgLink, err, "signature of digest is not valid at the verification time", re.HideStackTrace)
+			}
+		} else if until := validUntil(&outcome.EnvelopeContent.SignerInfo, timestamped != nil); until != nil && (expiry == nil || until.Before(*expiry)) {
+			// only results as of the current time depend on the trust material
+			// staying valid
+			expiry = until
+		}
 
 		// Note: notation verifier already validates certificate chain is not empty.
 		cert := outcome.EnvelopeContent.SignerInfo.CertificateChain[0]
-		extensions["Issuer"] = cert.Issuer.String()
-		extensions["SN"] = cert.Subject.String()
+		extensions[verifier.IssuerExtensionKey] = cert.Issuer.String()
+		extensions[verifier.SubjectExtensionKey] = cert.Subject.String()
+		signers = append(signers, verifier.NewCertificateSigner(cert))
 	}
 
 	return verifier.VerifierResult{
@@ -164,17 +223,50 @@ func (v *notationPluginVerifier) Verify(ctx context.Context,
 		Type:       v.verifierType,
 		IsSuccess:  true,
 		Message:    "signature verification success",
+		Signers:    signers,
 		Extensions: extensions,
+		ValidUntil: expiry,
 	}, nil
 }
 
-func getVerifierService(conf *NotationPluginVerifierConfig, pluginDirectory string) (notation.Verifier, error) {
-	store := &trustStore{
+func getVerifierService(conf *NotationPluginVerifierConfig, pluginDirectory string, revocationChecker *revocation.Checker) (notation.Verifier, error) {
+	return notationVerifier.NewWithOptions(&conf.TrustPolicyDoc, newTrustStore(conf), NewRatifyPluginManager(pluginDirectory), notationVerifier.VerifierOptions{
+		RevocationClient: revocationChecker,
+	})
+}
+
+func newTrustStore(conf *NotationPluginVerifierConfig) *trustStore {
+	return &trustStore{
 		certPaths:  conf.VerificationCerts,
 		certStores: conf.VerificationCertStores,
 	}
+}
 
-	return notationVerifier.New(&conf.TrustPolicyDoc, store, NewRatifyPluginManager(pluginDirectory))
+// Warm loads the certificates of the trust stores of the trust policy, so that
+// trust stores without valid certificates are reported before verifying.
+func (v *notationPluginVerifier) Warm(ctx context.Context) error {
+	loaded := map[string]struct{}{}
+	for _, policy := range v.trustPolicyDoc.TrustPolicies {
+		for _, store := range policy.TrustStores {
+			if _, ok := loaded[store]; ok {
+				continue
+			}
+			loaded[store] = struct{}{}
+			storeType, namedStore, found := strings.Cut(store, ":")
+			if !found {
+				continue
+			}
+			if _, err := v.trustStore.GetCertificates(ctx, truststore.Type(storeType), namedStore); err != nil {
+				return fmt.Errorf("failed to load certificates of trust store %s: %w", store, err)
+			}
+		}
+	}
+	if len(v.tsaRootCerts) > 0 {
+		if _, err := loadTSARoots(v.tsaRootCerts); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (v *notationPluginVerifier) verifySignature(ctx context.Context, subjectRef, mediaType string, subjectDesc oci.Descriptor, refBlob []byte) (*notation.VerificationOutcome, error) {
@@ -184,6 +276,10 @@ func (v *notationPluginVerifier) verifySignature(ctx context.Context, subjectRef
 	}
 	ctx = log.WithLogger(ctx, logger.GetLogger(ctx, logOpt))
 
+	// the time validations against the verification time are performed by verifyAtTime
+	if _, ok := verifier.VerificationTime(ctx); ok && v.pastVerifier != nil {
+		return (*v.pastVerifier).Verify(ctx, subjectDesc, refBlob, opts)
+	}
 	return (*v.notationVerifier).Verify(ctx, subjectDesc, refBlob, opts)
 }
 
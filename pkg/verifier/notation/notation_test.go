@@ -18,6 +18,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"os"
 	paths "path/filepath"
 	"reflect"
 	"testing"
@@ -31,6 +32,7 @@ import (
 	"github.com/deislabs/ratify/pkg/verifier"
 	sig "github.com/notaryproject/notation-core-go/signature"
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -159,6 +161,22 @@ func TestName(t *testing.T) {
 	}
 }
 
+func TestCreate_InvalidRevocationConfig(t *testing.T) {
+	verifierConfig := map[string]interface{}{
+		"name":           "notation-verifier-0",
+		"type":           "notation",
+		"trustPolicyDoc": testTrustPolicy,
+		"revocation": map[string]interface{}{
+			"failureMode": "lenient",
+		},
+	}
+
+	f := &notationPluginVerifierFactory{}
+	if _, err := f.Create(testVersion, verifierConfig, "", ""); err == nil {
+		t.Fatal("expected error for invalid revocation failure mode")
+	}
+}
+
 func TestCanVerify(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -396,6 +414,9 @@ func TestVerify(t *testing.T) {
 			if result.IsSuccess != tt.expect.IsSuccess {
 				t.Fatalf("expect %+v, got %+v", tt.expect, result)
 			}
+			if result.IsSuccess && (len(result.Signers) != 1 || result.Signers[0].Kind != verifier.SignerKindX509) {
+				t.Fatalf("expect x509 signer to be reported, got %+v", result.Signers)
+			}
 		})
 	}
 }
@@ -408,3 +429,46 @@ func TestGetNestedReferences(t *testing.T) {
 		t.Fatalf("notation signature should not have nested references")
 	}
 }
+
+func TestWarm(t *testing.T) {
+	certFile := paths.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte(certStr), 0600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	policyDoc := trustpolicy.Document{
+		TrustPolicies: []trustpolicy.TrustPolicy{
+			{Name: "default", TrustStores: []string{"ca:certs"}},
+			{Name: "other", TrustStores: []string{"ca:certs"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		store     *trustStore
+		expectErr bool
+	}{
+		{
+			name:  "certificates loaded from path",
+			store: &trustStore{certPaths: []string{certFile}},
+		},
+		{
+			name:      "certificate path not found",
+			store:     &trustStore{certPaths: []string{paths.Join(t.TempDir(), "missing")}},
+			expectErr: true,
+		},
+		{
+			name:      "certificate store not fetched",
+			store:     &trustStore{certStores: map[string][]string{"certs": {"default/kv1"}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &notationPluginVerifier{trustPolicyDoc: policyDoc, trustStore: tt.store}
+			if err := v.Warm(context.Background()); (err != nil) != tt.expectErr {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
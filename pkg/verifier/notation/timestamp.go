@@ -0,0 +1,167 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/utils"
+	"github.com/digitorus/pkcs7"
+	"github.com/digitorus/timestamp"
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+// timestampExtensionKey is the extension key holding the time a signature was
+// timestamped at by a trusted TSA.
+const timestampExtensionKey = "Timestamp"
+
+// verifyTimestamp verifies the RFC 3161 timestamp countersignature of a
+// signature with the TSA root certificates and returns the time the signature
+// was timestamped at. The certificate chain of a signature with a verified
+// timestamp only needs to be valid at that time, so the signature stays valid
+// after the certificates expire. notation does not verify timestamps, so the
+// certificates of timestamped signatures are verified at the given time if no
+// TSA root certificates are configured. Returns nil if the signature is not
+// timestamped or the timestamp is not verified.
+func verifyTimestamp(doc *trustpolicy.Document, artifactRef string, signerInfo *signature.SignerInfo, tsaRootCerts []string, requireTimestamp bool, at time.Time) (*time.Time, error) {
+	if signerInfo.SignedAttributes.SigningScheme != signature.SigningSchemeX509 {
+		// signatures of the signing authority scheme carry an authentic signing time
+		return nil, nil
+	}
+	token := signerInfo.UnsignedAttributes.TimestampSignature
+	if len(token) == 0 {
+		if requireTimestamp {
+			return nil, errors.New("signature is not timestamped")
+		}
+		return nil, nil
+	}
+
+	if !requireTimestamp {
+		enforced, err := authenticTimestampEnforced(doc, artifactRef)
+		if err != nil || !enforced {
+			return nil, err
+		}
+	}
+	if len(tsaRootCerts) == 0 {
+		if requireTimestamp {
+			return nil, errors.New("timestamp cannot be verified without TSA root certificates")
+		}
+		return nil, verifyCertificatesAt(signerInfo.CertificateChain, at, at)
+	}
+
+	roots, err := loadTSARoots(tsaRootCerts)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := verifyTimestampToken(token, signerInfo.Signature, roots)
+	if err != nil {
+		return nil, err
+	}
+	// the accuracy bounds the time the signature was actually timestamped at
+	if err := verifyCertificatesAt(signerInfo.CertificateChain, ts.Time.Add(-ts.Accuracy), ts.Time.Add(ts.Accuracy)); err != nil {
+		return nil, err
+	}
+	return &ts.Time, nil
+}
+
+// verifyTimestampToken verifies that the timestamp token is a countersignature
+// of the signature value by a TSA trusted by the roots.
+func verifyTimestampToken(token, signatureValue []byte, roots *x509.CertPool) (*timestamp.Timestamp, error) {
+	ts, err := timestamp.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp token: %w", err)
+	}
+	if len(ts.Certificates) == 0 {
+		return nil, errors.New("timestamp token does not contain the TSA certificate")
+	}
+	if !ts.HashAlgorithm.Available() {
+		return nil, fmt.Errorf("timestamp token uses unavailable hash algorithm %v", ts.HashAlgorithm)
+	}
+	hash := ts.HashAlgorithm.New()
+	hash.Write(signatureValue)
+	if !bytes.Equal(hash.Sum(nil), ts.HashedMessage) {
+		return nil, errors.New("timestamp token is not a countersignature of the signature")
+	}
+
+	p7, err := pkcs7.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp token: %w", err)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range p7.Certificates {
+		intermediates.AddCert(cert)
+	}
+	if err := p7.VerifyWithOpts(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   ts.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to verify timestamp token: %w", err)
+	}
+	return ts, nil
+}
+
+// verifyCertificatesAt returns an error if a certificate of the chain is not
+// valid during the time range.
+func verifyCertificatesAt(chain []*x509.Certificate, from, to time.Time) error {
+	for _, cert := range chain {
+		if from.Before(cert.NotBefore) || to.After(cert.NotAfter) {
+			return fmt.Errorf("certificate %q is not valid at the signing time %q, it is valid from %q to %q", cert.Subject, from.Format(time.RFC1123Z), cert.NotBefore.Format(time.RFC1123Z), cert.NotAfter.Format(time.RFC1123Z))
+		}
+	}
+	return nil
+}
+
+// authenticTimestampEnforced returns true if the trust policy applicable to
+// the artifact enforces the authentic timestamp validation.
+func authenticTimestampEnforced(doc *trustpolicy.Document, artifactRef string) (bool, error) {
+	policy, err := doc.GetApplicableTrustPolicy(artifactRef)
+	if err != nil {
+		return false, err
+	}
+	level, err := policy.SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		return false, err
+	}
+	return level.Enforcement[trustpolicy.TypeAuthenticTimestamp] == trustpolicy.ActionEnforce, nil
+}
+
+// loadTSARoots loads the TSA root certificates from the certificate files or
+// directories.
+func loadTSARoots(paths []string) (*x509.CertPool, error) {
+	roots := x509.NewCertPool()
+	loaded := 0
+	for _, path := range paths {
+		certs, err := utils.GetCertificatesFromPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TSA root certificates from %s: %w", path, err)
+		}
+		for _, cert := range certs {
+			roots.AddCert(cert)
+		}
+		loaded += len(certs)
+	}
+	if loaded == 0 {
+		return nil, errors.New("no TSA root certificates found")
+	}
+	return roots, nil
+}
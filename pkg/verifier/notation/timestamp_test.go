@@ -0,0 +1,247 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitorus/timestamp"
+	sig "github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+type testTSA struct {
+	rootPath string
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+}
+
+// newTestTSA creates a TSA with a root certificate written to a temporary
+// directory and a timestamping certificate valid for five years from notBefore.
+func newTestTSA(t *testing.T, notBefore time.Time) *testTSA {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test TSA root"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("failed to create root certificate: %v", err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatalf("failed to parse root certificate: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test TSA"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.AddDate(5, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, root, &key.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("failed to create TSA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse TSA certificate: %v", err)
+	}
+
+	rootPath := filepath.Join(t.TempDir(), "tsa-root.crt")
+	if err := os.WriteFile(rootPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER}), 0600); err != nil {
+		t.Fatalf("failed to write root certificate: %v", err)
+	}
+	return &testTSA{rootPath: rootPath, cert: cert, key: key}
+}
+
+// timestamp returns a timestamp token of the signature value at the time.
+func (tsa *testTSA) timestamp(t *testing.T, signatureValue []byte, at time.Time) []byte {
+	t.Helper()
+	hashed := sha256.Sum256(signatureValue)
+	ts := timestamp.Timestamp{
+		HashAlgorithm:     crypto.SHA256,
+		HashedMessage:     hashed[:],
+		Time:              at,
+		SerialNumber:      big.NewInt(1),
+		Policy:            asn1.ObjectIdentifier{1, 2, 3, 4, 1},
+		AddTSACertificate: true,
+	}
+	resp, err := ts.CreateResponse(tsa.cert, tsa.key)
+	if err != nil {
+		t.Fatalf("failed to create timestamp response: %v", err)
+	}
+	parsed, err := timestamp.ParseResponse(resp)
+	if err != nil {
+		t.Fatalf("failed to parse timestamp response: %v", err)
+	}
+	return parsed.RawToken
+}
+
+func TestVerifyTimestamp(t *testing.T) {
+	signed := time.Now().AddDate(-1, 0, 0).Truncate(time.Second)
+	tsa := newTestTSA(t, signed.AddDate(0, -1, 0))
+	untrustedTSA := newTestTSA(t, signed.AddDate(0, -1, 0))
+	signatureValue := []byte("signature")
+	// the signing certificate expired after the signature was timestamped
+	expiredChain := []*x509.Certificate{{NotBefore: signed.AddDate(0, -1, 0), NotAfter: signed.AddDate(0, 1, 0)}}
+	validChain := []*x509.Certificate{{NotBefore: signed.AddDate(0, -1, 0), NotAfter: time.Now().AddDate(1, 0, 0)}}
+	signerInfo := func(chain []*x509.Certificate, token []byte) *sig.SignerInfo {
+		return &sig.SignerInfo{
+			SignedAttributes:   sig.SignedAttributes{SigningScheme: sig.SigningSchemeX509},
+			UnsignedAttributes: sig.UnsignedAttributes{TimestampSignature: token},
+			Signature:          signatureValue,
+			CertificateChain:   chain,
+		}
+	}
+	strict := testTimePolicyDoc(trustpolicy.LevelStrict.Name, nil)
+	permissive := testTimePolicyDoc(trustpolicy.LevelPermissive.Name, nil)
+
+	testCases := []struct {
+		name             string
+		doc              trustpolicy.Document
+		signerInfo       *sig.SignerInfo
+		tsaRootCerts     []string
+		requireTimestamp bool
+		expectTimestamp  bool
+		expectErr        bool
+	}{
+		{
+			name:       "signature without timestamp",
+			doc:        strict,
+			signerInfo: signerInfo(expiredChain, nil),
+		},
+		{
+			name:             "timestamp required",
+			doc:              strict,
+			signerInfo:       signerInfo(validChain, nil),
+			requireTimestamp: true,
+			expectErr:        true,
+		},
+		{
+			name:            "expired certificate valid at the timestamp",
+			doc:             strict,
+			signerInfo:      signerInfo(expiredChain, tsa.timestamp(t, signatureValue, signed)),
+			tsaRootCerts:    []string{tsa.rootPath},
+			expectTimestamp: true,
+		},
+		{
+			name:         "certificate not valid at the timestamp",
+			doc:          strict,
+			signerInfo:   signerInfo(expiredChain, tsa.timestamp(t, signatureValue, signed.AddDate(0, 2, 0))),
+			tsaRootCerts: []string{tsa.rootPath},
+			expectErr:    true,
+		},
+		{
+			name:         "timestamp of another signature",
+			doc:          strict,
+			signerInfo:   signerInfo(expiredChain, tsa.timestamp(t, []byte("other"), signed)),
+			tsaRootCerts: []string{tsa.rootPath},
+			expectErr:    true,
+		},
+		{
+			name:         "timestamp by an untrusted TSA",
+			doc:          strict,
+			signerInfo:   signerInfo(expiredChain, untrustedTSA.timestamp(t, signatureValue, signed)),
+			tsaRootCerts: []string{tsa.rootPath},
+			expectErr:    true,
+		},
+		{
+			name:       "timestamp not verified without TSA root certificates",
+			doc:        strict,
+			signerInfo: signerInfo(validChain, tsa.timestamp(t, signatureValue, signed)),
+		},
+		{
+			name:       "expired certificate without TSA root certificates",
+			doc:        strict,
+			signerInfo: signerInfo(expiredChain, tsa.timestamp(t, signatureValue, signed)),
+			expectErr:  true,
+		},
+		{
+			name:       "authentic timestamp is not enforced",
+			doc:        permissive,
+			signerInfo: signerInfo(expiredChain, untrustedTSA.timestamp(t, signatureValue, signed)),
+		},
+		{
+			name:             "required timestamp is verified regardless of the trust policy",
+			doc:              permissive,
+			signerInfo:       signerInfo(expiredChain, untrustedTSA.timestamp(t, signatureValue, signed)),
+			tsaRootCerts:     []string{tsa.rootPath},
+			requireTimestamp: true,
+			expectErr:        true,
+		},
+		{
+			name: "signing authority scheme",
+			doc:  strict,
+			signerInfo: &sig.SignerInfo{
+				SignedAttributes: sig.SignedAttributes{SigningScheme: sig.SigningSchemeX509SigningAuthority},
+			},
+			requireTimestamp: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timestamped, err := verifyTimestamp(&tc.doc, "registry.io/repo@sha256:123456", tc.signerInfo, tc.tsaRootCerts, tc.requireTimestamp, time.Now())
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if tc.expectTimestamp != (timestamped != nil) {
+				t.Fatalf("expected timestamp %v, got %v", tc.expectTimestamp, timestamped)
+			}
+			if timestamped != nil && !timestamped.Equal(signed) {
+				t.Fatalf("expected timestamp %v, got %v", signed, timestamped)
+			}
+		})
+	}
+}
+
+func TestLoadTSARoots(t *testing.T) {
+	tsa := newTestTSA(t, time.Now())
+	if _, err := loadTSARoots([]string{tsa.rootPath}); err != nil {
+		t.Fatalf("failed to load TSA root certificates: %v", err)
+	}
+	if _, err := loadTSARoots([]string{t.TempDir()}); err == nil {
+		t.Fatalf("expected an error for a directory without certificates")
+	}
+}
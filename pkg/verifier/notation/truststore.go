@@ -21,8 +21,10 @@ import (
 	"errors"
 	"fmt"
 
+	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/internal/logger"
 	"github.com/deislabs/ratify/pkg/controllers"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
 	"github.com/deislabs/ratify/pkg/utils"
 	"github.com/notaryproject/notation-go/verifier/truststore"
 )
@@ -55,14 +57,17 @@ func (s trustStore) getCertificatesInternal(ctx context.Context, namedStore stri
 	if certGroup := s.certStores[namedStore]; len(certGroup) > 0 {
 		for _, certStore := range certGroup {
 			logger.GetLogger(ctx, logOpt).Debugf("truststore getting certStore %v", certStore)
+			// the certificate store may be a certificate store or a key management provider
 			result := certificatesMap[certStore]
+			result = append(result[:len(result):len(result)], keymanagementprovider.GetCertificatesFromMap(certStore)...)
 			if len(result) == 0 {
 				logger.GetLogger(ctx, logOpt).Warnf("no certificate fetched for certStore %+v", certStore)
 			}
 			certs = append(certs, result...)
 		}
 		if len(certs) == 0 {
-			return certs, fmt.Errorf("unable to fetch certificates for namedStore: %+v", namedStore)
+			// the certificate stores have not been fetched by the key management provider yet
+			return certs, re.ErrorCodeVerificationInconclusive.NewError(re.Verifier, "", re.EmptyLink, nil, fmt.Sprintf("unable to fetch certificates for namedStore: %+v", namedStore), re.HideStackTrace)
 		}
 	} else {
 		for _, path := range s.certPaths {
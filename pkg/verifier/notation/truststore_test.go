@@ -20,6 +20,9 @@ import (
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
+	"github.com/deislabs/ratify/pkg/verifier"
 )
 
 const (
@@ -38,8 +41,8 @@ func TestGetCertificates_EmptyCertMap(t *testing.T) {
 	}
 
 	certificatesMap := map[string][]*x509.Certificate{}
-	if _, err := store.getCertificatesInternal(context.Background(), "store1", certificatesMap); err == nil {
-		t.Fatalf("error expected if cert map is empty")
+	if _, err := store.getCertificatesInternal(context.Background(), "store1", certificatesMap); !verifier.IsInconclusive(err) {
+		t.Fatalf("inconclusive error expected if cert map is empty, got %v", err)
 	}
 }
 
@@ -72,6 +75,29 @@ func TestGetCertificates_NamedStore(t *testing.T) {
 	}
 }
 
+func TestGetCertificates_KeyManagementProvider(t *testing.T) {
+	store := &trustStore{
+		certStores: map[string][]string{"store1": {"default/kv1", "default/kmp1"}},
+	}
+	kv1Cert := getCert(certStr)
+	kmpCert := getCert(certStr2)
+	certificatesMap := map[string][]*x509.Certificate{"default/kv1": {kv1Cert}}
+	keymanagementprovider.SetCertificatesInMap(context.Background(), "default/kmp1", map[keymanagementprovider.KMPMapKey][]*x509.Certificate{{Name: "cert1"}: {kmpCert}}, 0)
+	defer keymanagementprovider.DeleteResourceFromMap("default/kmp1")
+
+	// certificates of both the certificate store and the key management provider should be returned
+	result, err := store.getCertificatesInternal(context.Background(), "store1", certificatesMap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || !kv1Cert.Equal(result[0]) || !kmpCert.Equal(result[1]) {
+		t.Fatalf("unexpected certificates returned: %+v", result)
+	}
+	if len(certificatesMap["default/kv1"]) != 1 {
+		t.Fatalf("certificates map must not be modified")
+	}
+}
+
 func TestGetCertificates_certPath(t *testing.T) {
 	// create a temporary certificate file
 	tmpFile, err := os.CreateTemp("", "*.pem")
@@ -0,0 +1,112 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+// timeValidations are the validations notation performs against the current
+// time. They are evaluated by ratify when verifying as of a verification time.
+var timeValidations = []trustpolicy.ValidationType{trustpolicy.TypeExpiry, trustpolicy.TypeAuthenticTimestamp}
+
+// pastTrustPolicyDoc returns a copy of the trust policy document that only
+// logs failures of the time validations, so that signatures verified as of a
+// past verification time are not rejected for being expired now.
+func pastTrustPolicyDoc(doc trustpolicy.Document) trustpolicy.Document {
+	past := doc
+	past.TrustPolicies = make([]trustpolicy.TrustPolicy, len(doc.TrustPolicies))
+	for i, policy := range doc.TrustPolicies {
+		past.TrustPolicies[i] = policy
+		// overrides are not allowed for the skip level, which skips time validations anyway
+		if policy.SignatureVerification.VerificationLevel == trustpolicy.LevelSkip.Name {
+			continue
+		}
+		override := map[trustpolicy.ValidationType]trustpolicy.ValidationAction{}
+		for validationType, action := range policy.SignatureVerification.Override {
+			override[validationType] = action
+		}
+		for _, validationType := range timeValidations {
+			override[validationType] = trustpolicy.ActionLog
+		}
+		past.TrustPolicies[i].SignatureVerification.Override = override
+	}
+	return past
+}
+
+// verifyAtTime performs the time validations enforced by the trust policy
+// applicable to the artifact as of the verification time.
+func verifyAtTime(doc *trustpolicy.Document, artifactRef string, outcome *notation.VerificationOutcome, verificationTime time.Time) error {
+	policy, err := doc.GetApplicableTrustPolicy(artifactRef)
+	if err != nil {
+		return err
+	}
+	level, err := policy.SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		return err
+	}
+
+	signerInfo := outcome.EnvelopeContent.SignerInfo
+	if level.Enforcement[trustpolicy.TypeExpiry] == trustpolicy.ActionEnforce {
+		if expiry := signerInfo.SignedAttributes.Expiry; !expiry.IsZero() && !verificationTime.Before(expiry) {
+			return fmt.Errorf("digital signature has expired on %q before the verification time %q", expiry.Format(time.RFC1123Z), verificationTime.Format(time.RFC1123Z))
+		}
+	}
+	if level.Enforcement[trustpolicy.TypeAuthenticTimestamp] == trustpolicy.ActionEnforce {
+		// signatures of the signing authority scheme are verified against the signing time
+		// and signatures with a TSA signature are not verified against the current time,
+		// so only the x509 scheme without TSA signature depends on the verification time
+		if signerInfo.SignedAttributes.SigningScheme == signature.SigningSchemeX509 && len(signerInfo.UnsignedAttributes.TimestampSignature) == 0 {
+			for _, cert := range signerInfo.CertificateChain {
+				if verificationTime.Before(cert.NotBefore) || verificationTime.After(cert.NotAfter) {
+					return fmt.Errorf("certificate %q is not valid at the verification time %q, it is valid from %q to %q", cert.Subject, verificationTime.Format(time.RFC1123Z), cert.NotBefore.Format(time.RFC1123Z), cert.NotAfter.Format(time.RFC1123Z))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validUntil returns the earliest time the trust material of a verified
+// signature expires, i.e. the expiry of the signature or the notAfter of a
+// certificate of its chain. The certificates of signatures with a verified
+// timestamp do not expire. Returns nil if the trust material does not expire.
+func validUntil(signerInfo *signature.SignerInfo, timestamped bool) *time.Time {
+	var earliest time.Time
+	if expiry := signerInfo.SignedAttributes.Expiry; !expiry.IsZero() {
+		earliest = expiry
+	}
+	if timestamped {
+		if earliest.IsZero() {
+			return nil
+		}
+		return &earliest
+	}
+	for _, cert := range signerInfo.CertificateChain {
+		if !cert.NotAfter.IsZero() && (earliest.IsZero() || cert.NotAfter.Before(earliest)) {
+			earliest = cert.NotAfter
+		}
+	}
+	if earliest.IsZero() {
+		return nil
+	}
+	return &earliest
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	sig "github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+func testTimePolicyDoc(level string, override map[trustpolicy.ValidationType]trustpolicy.ValidationAction) trustpolicy.Document {
+	return trustpolicy.Document{
+		Version: "1.0",
+		TrustPolicies: []trustpolicy.TrustPolicy{{
+			Name:                  "default",
+			RegistryScopes:        []string{"*"},
+			SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: level, Override: override},
+			TrustStores:           []string{"ca:certs"},
+			TrustedIdentities:     []string{"*"},
+		}},
+	}
+}
+
+func TestPastTrustPolicyDoc(t *testing.T) {
+	doc := testTimePolicyDoc(trustpolicy.LevelStrict.Name, map[trustpolicy.ValidationType]trustpolicy.ValidationAction{trustpolicy.TypeRevocation: trustpolicy.ActionSkip})
+	past := pastTrustPolicyDoc(doc)
+
+	level, err := past.TrustPolicies[0].SignatureVerification.GetVerificationLevel()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for validationType, expected := range map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+		trustpolicy.TypeExpiry:             trustpolicy.ActionLog,
+		trustpolicy.TypeAuthenticTimestamp: trustpolicy.ActionLog,
+		trustpolicy.TypeRevocation:         trustpolicy.ActionSkip,
+		trustpolicy.TypeAuthenticity:       trustpolicy.ActionEnforce,
+	} {
+		if level.Enforcement[validationType] != expected {
+			t.Fatalf("expected %s to be %s, got %s", validationType, expected, level.Enforcement[validationType])
+		}
+	}
+	if len(doc.TrustPolicies[0].SignatureVerification.Override) != 1 {
+		t.Fatalf("expected original trust policy to be unchanged")
+	}
+
+	skip := pastTrustPolicyDoc(testTimePolicyDoc(trustpolicy.LevelSkip.Name, nil))
+	if _, err := skip.TrustPolicies[0].SignatureVerification.GetVerificationLevel(); err != nil {
+		t.Fatalf("expected skip level to be kept, got %v", err)
+	}
+}
+
+func TestVerifyAtTime(t *testing.T) {
+	signed := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	outcome := &notation.VerificationOutcome{
+		EnvelopeContent: &sig.EnvelopeContent{
+			SignerInfo: sig.SignerInfo{
+				SignedAttributes: sig.SignedAttributes{
+					SigningScheme: sig.SigningSchemeX509,
+					Expiry:        signed.AddDate(1, 0, 0),
+				},
+				CertificateChain: []*x509.Certificate{
+					{NotBefore: signed.AddDate(0, -1, 0), NotAfter: signed.AddDate(0, 6, 0)},
+				},
+			},
+		},
+	}
+	strict := testTimePolicyDoc(trustpolicy.LevelStrict.Name, nil)
+	permissive := testTimePolicyDoc(trustpolicy.LevelPermissive.Name, nil)
+
+	testCases := []struct {
+		name             string
+		doc              trustpolicy.Document
+		verificationTime time.Time
+		expectErr        bool
+	}{
+		{name: "valid at verification time", doc: strict, verificationTime: signed.AddDate(0, 1, 0)},
+		{name: "certificate expired", doc: strict, verificationTime: signed.AddDate(0, 7, 0), expectErr: true},
+		{name: "certificate not valid yet", doc: strict, verificationTime: signed.AddDate(0, -2, 0), expectErr: true},
+		{name: "signature expired", doc: testTimePolicyDoc(trustpolicy.LevelStrict.Name, map[trustpolicy.ValidationType]trustpolicy.ValidationAction{trustpolicy.TypeAuthenticTimestamp: trustpolicy.ActionLog}), verificationTime: signed.AddDate(2, 0, 0), expectErr: true},
+		{name: "time validations are logged", doc: permissive, verificationTime: signed.AddDate(2, 0, 0)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyAtTime(&tc.doc, "registry.io/repo@sha256:123456", outcome, tc.verificationTime)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidUntil(t *testing.T) {
+	signed := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		signerInfo  sig.SignerInfo
+		timestamped bool
+		expected    *time.Time
+	}{
+		{
+			name: "certificate expires first",
+			signerInfo: sig.SignerInfo{
+				SignedAttributes: sig.SignedAttributes{Expiry: signed.AddDate(1, 0, 0)},
+				CertificateChain: []*x509.Certificate{
+					{NotAfter: signed.AddDate(0, 6, 0)},
+					{NotAfter: signed.AddDate(0, 3, 0)},
+				},
+			},
+			expected: func() *time.Time { t := signed.AddDate(0, 3, 0); return &t }(),
+		},
+		{
+			name: "signature expires first",
+			signerInfo: sig.SignerInfo{
+				SignedAttributes: sig.SignedAttributes{Expiry: signed.AddDate(0, 1, 0)},
+				CertificateChain: []*x509.Certificate{{NotAfter: signed.AddDate(0, 6, 0)}},
+			},
+			expected: func() *time.Time { t := signed.AddDate(0, 1, 0); return &t }(),
+		},
+		{
+			name: "timestamped signature",
+			signerInfo: sig.SignerInfo{
+				SignedAttributes: sig.SignedAttributes{Expiry: signed.AddDate(1, 0, 0)},
+				CertificateChain: []*x509.Certificate{{NotAfter: signed.AddDate(0, 6, 0)}},
+			},
+			timestamped: true,
+			expected:    func() *time.Time { t := signed.AddDate(1, 0, 0); return &t }(),
+		},
+		{
+			name: "no expiry",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validUntil := validUntil(&tc.signerInfo, tc.timestamped)
+			if (validUntil == nil) != (tc.expected == nil) || (validUntil != nil && !validUntil.Equal(*tc.expected)) {
+				t.Fatalf("expected %v, got %v", tc.expected, validUntil)
+			}
+		})
+	}
+}
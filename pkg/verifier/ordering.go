@@ -0,0 +1,75 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import "context"
+
+// OrderedVerifier is implemented by verifiers that declare an execution
+// priority and the verifiers that must succeed on the same artifact first.
+type OrderedVerifier interface {
+	// Priority returns the execution priority, verifiers with a higher priority run first.
+	Priority() int
+	// DependsOn returns the names of the verifiers that must succeed on the same
+	// artifact before this verifier runs.
+	DependsOn() []string
+}
+
+type orderedVerifier struct {
+	ReferenceVerifier
+	priority  int
+	dependsOn []string
+}
+
+// WithOrdering wraps the verifier to declare its priority and dependencies.
+func WithOrdering(verifier ReferenceVerifier, priority int, dependsOn []string) ReferenceVerifier {
+	return &orderedVerifier{
+		ReferenceVerifier: verifier,
+		priority:          priority,
+		dependsOn:         dependsOn,
+	}
+}
+
+func (v *orderedVerifier) Priority() int {
+	return v.priority
+}
+
+func (v *orderedVerifier) DependsOn() []string {
+	return v.dependsOn
+}
+
+func (v *orderedVerifier) CheckHealth(ctx context.Context) error {
+	return CheckHealth(ctx, v.ReferenceVerifier)
+}
+
+func (v *orderedVerifier) Warm(ctx context.Context) error {
+	return Warm(ctx, v.ReferenceVerifier)
+}
+
+// GetPriority returns the priority of the verifier, 0 if it does not declare one.
+func GetPriority(verifier ReferenceVerifier) int {
+	if ordered, ok := verifier.(OrderedVerifier); ok {
+		return ordered.Priority()
+	}
+	return 0
+}
+
+// GetDependencies returns the names of the verifiers the verifier depends on.
+func GetDependencies(verifier ReferenceVerifier) []string {
+	if ordered, ok := verifier.(OrderedVerifier); ok {
+		return ordered.DependsOn()
+	}
+	return nil
+}
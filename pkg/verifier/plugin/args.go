@@ -27,6 +27,7 @@ type VerifierPluginArgs struct {
 	Command          string
 	Version          string
 	SubjectReference string
+	VerificationTime string
 }
 
 var _ pluginCommon.PluginArgs = &VerifierPluginArgs{}
@@ -39,5 +40,8 @@ func (args *VerifierPluginArgs) AsEnviron() []string {
 		fmt.Sprintf("%s=%s", SubjectEnvKey, args.SubjectReference),
 		fmt.Sprintf("%s=%s", VersionEnvKey, args.Version),
 	)
+	if args.VerificationTime != "" {
+		env = append(env, fmt.Sprintf("%s=%s", TimeEnvKey, args.VerificationTime))
+	}
 	return pluginCommon.MergeDuplicateEnviron(env)
 }
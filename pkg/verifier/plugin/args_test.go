@@ -57,3 +57,23 @@ func TestAsEnviron_ReturnsExpected(t *testing.T) {
 		t.Fatalf("missing version env")
 	}
 }
+
+func TestAsEnviron_VerificationTime(t *testing.T) {
+	args := VerifierPluginArgs{
+		Command:          "testCommand",
+		Version:          "1.0.0",
+		SubjectReference: "testref",
+		VerificationTime: "2023-06-01T12:00:00Z",
+	}
+
+	verifierPluginArgs := args.AsEnviron()
+	if len(os.Environ())+4 != len(verifierPluginArgs) {
+		t.Fatalf("mismatch of the plugin env")
+	}
+	for _, e := range verifierPluginArgs {
+		if e == "RATIFY_VERIFIER_TIME=2023-06-01T12:00:00Z" {
+			return
+		}
+	}
+	t.Fatalf("missing verification time env")
+}
@@ -20,4 +20,7 @@ const (
 	CommandEnvKey = "RATIFY_VERIFIER_COMMAND"
 	SubjectEnvKey = "RATIFY_VERIFIER_SUBJECT"
 	VersionEnvKey = "RATIFY_VERIFIER_VERSION"
+	// TimeEnvKey is set to the RFC 3339 time to evaluate trust at if the
+	// verification is evaluated as of a time other than now.
+	TimeEnvKey = "RATIFY_VERIFIER_TIME"
 )
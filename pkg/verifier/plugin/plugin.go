@@ -17,14 +17,17 @@ package plugin
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
-	"os"
 	"strings"
+	"time"
 
 	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/pkg/common"
 	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	rc "github.com/deislabs/ratify/pkg/referrerstore/config"
@@ -78,7 +81,7 @@ func NewVerifier(version string, verifierConfig config.VerifierConfig, pluginPat
 		rawConfig:        verifierConfig,
 		artifactTypes:    artifactTypes,
 		nestedReferences: nestedReferences,
-		executor:         &pluginCommon.DefaultExecutor{Stderr: os.Stderr},
+		executor:         pluginCommon.NewExecutor(),
 	}, nil
 }
 
@@ -112,16 +115,41 @@ func (vp *VerifierPlugin) Verify(ctx context.Context,
 	return *vr, nil
 }
 
+// CheckHealth returns an error if the plugin cannot be found in the plugin paths.
+func (vp *VerifierPlugin) CheckHealth(_ context.Context) error {
+	if _, err := vp.executor.FindInPaths(vp.pluginName(), vp.path); err != nil {
+		return re.ErrorCodePluginNotFound.NewError(re.Verifier, vp.name, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	return nil
+}
+
+// Warm locates the plugin and starts it ahead of the first verification if
+// the plugin executor keeps plugins running.
+func (vp *VerifierPlugin) Warm(_ context.Context) error {
+	pluginPath, err := vp.executor.FindInPaths(vp.pluginName(), vp.path)
+	if err != nil {
+		return re.ErrorCodePluginNotFound.NewError(re.Verifier, vp.name, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	if warmer, ok := vp.executor.(pluginCommon.Warmer); ok {
+		warmer.Warm(pluginPath)
+	}
+	return nil
+}
+
+// pluginName returns the name of the plugin executable.
+func (vp *VerifierPlugin) pluginName() string {
+	if vp.verifierType != "" {
+		return vp.verifierType
+	}
+	return vp.name
+}
+
 func (vp *VerifierPlugin) verifyReference(
 	ctx context.Context,
 	subjectReference common.Reference,
 	referenceDescriptor ocispecs.ReferenceDescriptor,
 	referrerStoreConfig *rc.StoreConfig) (*verifier.VerifierResult, error) {
-	verifierTypeStr := vp.name
-	if vp.verifierType != "" {
-		verifierTypeStr = vp.verifierType
-	}
-	pluginPath, err := vp.executor.FindInPaths(verifierTypeStr, vp.path)
+	pluginPath, err := vp.executor.FindInPaths(vp.pluginName(), vp.path)
 	if err != nil {
 		return nil, re.ErrorCodePluginNotFound.NewError(re.Verifier, vp.name, re.EmptyLink, err, nil, re.HideStackTrace)
 	}
@@ -131,9 +159,17 @@ func (vp *VerifierPlugin) verifyReference(
 		Version:          vp.version,
 		SubjectReference: subjectReference.String(),
 	}
+	if verificationTime, ok := verifier.VerificationTime(ctx); ok {
+		pluginArgs.VerificationTime = verificationTime.Format(time.RFC3339Nano)
+	}
+
+	pluginConfig, err := vp.getPluginConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	inputConfig := config.PluginInputConfig{
-		Config:       vp.rawConfig,
+		Config:       pluginConfig,
 		StoreConfig:  *referrerStoreConfig,
 		ReferencDesc: referenceDescriptor,
 	}
@@ -156,6 +192,37 @@ func (vp *VerifierPlugin) verifyReference(
 	return result, nil
 }
 
+// getPluginConfig returns the config passed to the plugin, the public keys of
+// the key management provider of the verifier are added as PEM encoded keys so
+// that rotated keys are picked up without restarting the plugin.
+func (vp *VerifierPlugin) getPluginConfig() (config.VerifierConfig, error) {
+	kmp, ok := vp.rawConfig[types.KeyManagementProvider]
+	if !ok {
+		return vp.rawConfig, nil
+	}
+	resource := fmt.Sprintf("%s", kmp)
+	keys := keymanagementprovider.GetKeysFromMap(resource)
+	if len(keys) == 0 {
+		// the keys have not been fetched by the key management provider yet
+		return nil, re.ErrorCodeVerificationInconclusive.NewError(re.Verifier, vp.name, re.EmptyLink, nil, fmt.Sprintf("no keys fetched for key management provider %s", resource), re.HideStackTrace)
+	}
+	pemKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, re.ErrorCodeKeyInvalid.NewError(re.Verifier, vp.name, re.EmptyLink, err, fmt.Sprintf("failed to encode key of key management provider %s", resource), re.HideStackTrace)
+		}
+		pemKeys = append(pemKeys, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	}
+
+	pluginConfig := make(config.VerifierConfig, len(vp.rawConfig)+1)
+	for key, value := range vp.rawConfig {
+		pluginConfig[key] = value
+	}
+	pluginConfig[types.Keys] = pemKeys
+	return pluginConfig, nil
+}
+
 func (vp *VerifierPlugin) GetNestedReferences() []string {
 	return vp.nestedReferences
 }
@@ -17,12 +17,19 @@ package plugin
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"strings"
 	"testing"
 
 	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/keymanagementprovider"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	sm "github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/types"
 )
 
 const (
@@ -218,3 +225,45 @@ func TestVerify_IsSuccessFalse_Expected(t *testing.T) {
 		t.Fatal("plugin expected to return isSuccess as false but got as true")
 	}
 }
+
+func TestGetPluginConfig_KeyManagementProvider(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	verifierConfig := map[string]interface{}{
+		"name":                  testPlugin,
+		"keyManagementProvider": "default/kmp",
+	}
+	verifierPlugin := &VerifierPlugin{
+		name:      testPlugin,
+		rawConfig: verifierConfig,
+	}
+
+	// keys not fetched yet
+	if _, err := verifierPlugin.getPluginConfig(); !verifier.IsInconclusive(err) {
+		t.Fatalf("expected inconclusive error, got %v", err)
+	}
+
+	keymanagementprovider.SetKeysInMap(context.Background(), "default/kmp", map[keymanagementprovider.KMPMapKey]crypto.PublicKey{{Name: "key"}: privateKey.Public()}, 0)
+	defer keymanagementprovider.DeleteResourceFromMap("default/kmp")
+
+	pluginConfig, err := verifierPlugin.getPluginConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys, ok := pluginConfig[types.Keys].([]string)
+	if !ok || len(keys) != 1 {
+		t.Fatalf("expected one key in plugin config, got %+v", pluginConfig[types.Keys])
+	}
+	key, err := keymanagementprovider.DecodeKey([]byte(keys[0]))
+	if err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	if !privateKey.PublicKey.Equal(key) {
+		t.Fatalf("unexpected key in plugin config")
+	}
+	if _, ok := verifierConfig[types.Keys]; ok {
+		t.Fatalf("raw config must not be modified")
+	}
+}
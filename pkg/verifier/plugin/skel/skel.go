@@ -16,12 +16,16 @@ limitations under the License.
 package skel
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/common/plugin"
@@ -43,6 +47,9 @@ type pcontext struct {
 	Stderr     io.Writer
 }
 
+// stores caches the referrer stores created from the store configs passed in
+var stores sync.Map
+
 type VerifyReference func(args *CmdArgs, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (*verifier.VerifierResult, error)
 
 // CmdArgs describes arguments that are passed when the plugin is invoked
@@ -51,10 +58,34 @@ type CmdArgs struct {
 	Subject    string
 	subjectRef common.Reference
 	StdinData  []byte
+	// VerificationTime is the time to evaluate trust at, it is the current
+	// time unless Ratify requested to evaluate trust as of another time.
+	VerificationTime time.Time
+	// TraceID is the trace ID of the Ratify request the plugin is invoked
+	// for, empty if the plugin is not invoked for a request.
+	TraceID string
 }
 
 // PluginMain is the core "main" for a plugin which includes error handling.
+// When Ratify starts the plugin as a gRPC server, the plugin keeps running and
+// serves every verification over gRPC.
 func PluginMain(name, version string, verifyReference VerifyReference, supportedVersions []string) {
+	if plugin.IsGRPCServerRequested(os.Getenv) {
+		if err := plugin.ServeGRPC(func(_ context.Context, getEnviron func(string) string, stdin []byte) ([]byte, *plugin.Error) {
+			stdout := &bytes.Buffer{}
+			e := (&pcontext{
+				GetEnviron: getEnviron,
+				Stdin:      bytes.NewReader(stdin),
+				Stdout:     stdout,
+				Stderr:     os.Stderr,
+			}).pluginMainCore(name, version, verifyReference, supportedVersions)
+			return stdout.Bytes(), e
+		}, os.Stdin, os.Stdout); err != nil {
+			log.Fatal("Error serving plugin over gRPC: ", err)
+		}
+		return
+	}
+
 	if e := (&pcontext{
 		GetEnviron: os.Getenv,
 		Stdin:      os.Stdin,
@@ -89,11 +120,10 @@ func (pc *pcontext) pluginMainCore(_, version string, verifyReference VerifyRefe
 		PluginBinDirs: input.StoreConfig.PluginBinDirs,
 		Stores:        []storeConfig.StorePluginConfig{input.StoreConfig.Store},
 	}
-	stores, storeErr := factory.CreateStoresFromConfig(storeConfigs, "")
-	if storeErr != nil || stores == nil || len(stores) == 0 {
+	store, storeErr := getStore(storeConfigs)
+	if storeErr != nil {
 		return plugin.NewError(types.ErrArgsParsingFailure, fmt.Sprintf("create store from input config failed with error %v", storeErr), "")
 	}
-	store := stores[0]
 
 	// This is the original implementation for initialization of a referrer store which does not support built-ins
 	//store, serr := rp.NewStore(input.StoreConfig.Version, input.StoreConfig.Store, input.StoreConfig.PluginBinDirs)
@@ -120,6 +150,27 @@ func (pc *pcontext) pluginMainCore(_, version string, verifyReference VerifyRefe
 	}
 }
 
+// getStore returns the store for the config, stores are reused across
+// invocations of plugins served over gRPC to keep their caches warm.
+func getStore(storeConfigs storeConfig.StoresConfig) (referrerstore.ReferrerStore, error) {
+	key, err := json.Marshal(storeConfigs)
+	if err != nil {
+		return nil, err
+	}
+	if store, ok := stores.Load(string(key)); ok {
+		return store.(referrerstore.ReferrerStore), nil
+	}
+	created, err := factory.CreateStoresFromConfig(storeConfigs, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 0 {
+		return nil, fmt.Errorf("no store created")
+	}
+	store, _ := stores.LoadOrStore(string(key), created[0])
+	return store.(referrerstore.ReferrerStore), nil
+}
+
 func (pc *pcontext) getCmdArgsFromEnv() (string, *CmdArgs, *plugin.Error) {
 	argsMissing := make([]string, 0)
 
@@ -156,11 +207,20 @@ func (pc *pcontext) getCmdArgsFromEnv() (string, *CmdArgs, *plugin.Error) {
 		return "", nil, plugin.NewError(types.ErrArgsParsingFailure, fmt.Sprintf("cannot parse subject reference %s", subject), err.Error())
 	}
 
+	verificationTime := time.Now()
+	if value := pc.GetEnviron(vp.TimeEnvKey); value != "" {
+		if verificationTime, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return "", nil, plugin.NewError(types.ErrArgsParsingFailure, fmt.Sprintf("cannot parse verification time %s", value), err.Error())
+		}
+	}
+
 	cmdArgs := &CmdArgs{
-		Version:    version,
-		Subject:    subject,
-		StdinData:  stdinData,
-		subjectRef: subRef,
+		Version:          version,
+		Subject:          subject,
+		StdinData:        stdinData,
+		subjectRef:       subRef,
+		VerificationTime: verificationTime,
+		TraceID:          pc.GetEnviron(plugin.TraceIDEnvKey),
 	}
 
 	return cmd, cmdArgs, nil
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/common"
 	"github.com/deislabs/ratify/pkg/ocispecs"
@@ -120,6 +121,54 @@ func TestPluginMain_VerifyReference_CanUseBuiltinStores(t *testing.T) {
 	}
 }
 
+func TestPluginMain_VerificationTime(t *testing.T) {
+	verificationTime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	verifyReference := func(args *CmdArgs, _ common.Reference, _ ocispecs.ReferenceDescriptor, _ referrerstore.ReferrerStore) (*verifier.VerifierResult, error) {
+		return &verifier.VerifierResult{IsSuccess: args.VerificationTime.Equal(verificationTime)}, nil
+	}
+
+	testCases := []struct {
+		name      string
+		time      string
+		expectErr bool
+	}{
+		{name: "verification time", time: verificationTime.Format(time.RFC3339Nano)},
+		{name: "invalid verification time", time: "yesterday", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			environment := map[string]string{
+				plugin.CommandEnvKey: plugin.VerifyCommand,
+				plugin.VersionEnvKey: "1.0.0",
+				plugin.SubjectEnvKey: "localhost:5000/net-monitor:v1@sha256:a0fc570a245b09ed752c42d600ee3bb5b4f77bbd70d8898780b7ab43454530eb",
+				plugin.TimeEnvKey:    tc.time,
+			}
+			stdinData := `{ "storeConfig" : {"store": {"name":"oras"}}, "config": {"name": "skel-test-case"}, "referenceDesc": {"artifactType": "test-type"}}`
+			stdout := &bytes.Buffer{}
+			pluginContext := &pcontext{
+				GetEnviron: func(key string) string { return environment[key] },
+				Stdin:      strings.NewReader(stdinData),
+				Stdout:     stdout,
+				Stderr:     &bytes.Buffer{},
+			}
+
+			err := pluginContext.pluginMainCore("skel-test-case", "1.0.0", verifyReference, []string{"1.0.0"})
+			if tc.expectErr {
+				if err == nil || err.Code != types.ErrArgsParsingFailure {
+					t.Fatalf("expected args parsing failure, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("plugin execution failed %v", err)
+			}
+			if !strings.Contains(stdout.String(), `"isSuccess":true`) {
+				t.Fatalf("expected plugin to receive the verification time, got %s", stdout.String())
+			}
+		})
+	}
+}
+
 func TestPluginMain_ErrorCases(t *testing.T) {
 	verifyReference := func(args *CmdArgs, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (*verifier.VerifierResult, error) {
 		return nil, fmt.Errorf("simulated error")
@@ -0,0 +1,106 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revocation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+)
+
+// defaultCacheTTL is how long responses without a next update time are cached
+const defaultCacheTTL = time.Hour
+
+type cacheEntry struct {
+	raw    []byte
+	expiry time.Time
+}
+
+// responseCache caches the DER encoded OCSP responses and CRLs in memory and,
+// if a directory is set, on disk. Entries on disk are validated again when
+// they are loaded as their expiry is not persisted.
+type responseCache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+	dir     string
+}
+
+func newResponseCache(dir string) *responseCache {
+	return &responseCache{
+		entries: make(map[string]cacheEntry),
+		dir:     dir,
+	}
+}
+
+// get returns the cached response of the key if it has not expired.
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		if time.Now().Before(entry.expiry) {
+			return entry.raw, true
+		}
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return nil, false
+	}
+	if c.dir == "" {
+		return nil, false
+	}
+	raw, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+// set caches the response of the key until the next update time.
+func (c *responseCache) set(key string, raw []byte, nextUpdate time.Time) {
+	expiry := nextUpdate
+	if expiry.IsZero() {
+		expiry = time.Now().Add(defaultCacheTTL)
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{raw: raw, expiry: expiry}
+	c.mu.Unlock()
+	// responses without a next update time are not persisted as their
+	// expiry could not be told apart on load
+	if c.dir == "" || nextUpdate.IsZero() {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		logger.GetLogger(context.Background(), logOpt).Warnf("failed to create revocation cache directory %s: %v", c.dir, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, key), raw, 0o600); err != nil {
+		logger.GetLogger(context.Background(), logOpt).Warnf("failed to persist revocation response: %v", err)
+	}
+}
+
+// cacheKey derives the file name of the cached response of a certificate for
+// OCSP or of a distribution point URL for CRLs.
+func cacheKey(protocol string, id []byte) string {
+	sum := sha256.Sum256(id)
+	return protocol + "-" + hex.EncodeToString(sum[:]) + ".der"
+}
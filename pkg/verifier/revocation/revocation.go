@@ -0,0 +1,369 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deislabs/ratify/internal/logger"
+	"github.com/deislabs/ratify/pkg/metrics"
+	"github.com/notaryproject/notation-core-go/revocation/result"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// FailureModeHard reports certificates whose revocation status could not
+	// be retrieved as unknown, which fails their verification.
+	FailureModeHard = "hard"
+	// FailureModeSoft accepts certificates whose revocation status could not
+	// be retrieved and logs a warning.
+	FailureModeSoft = "soft"
+
+	protocolOCSP = "ocsp"
+	protocolCRL  = "crl"
+	protocolNone = "none"
+
+	defaultTimeout = 5 * time.Second
+	// maxResponseSize bounds the size of the OCSP responses and CRLs fetched
+	maxResponseSize = 32 << 20
+)
+
+var logOpt = logger.Option{
+	ComponentType: logger.Verifier,
+}
+
+// Config describes how the revocation status of certificates is checked.
+type Config struct {
+	// FailureMode is either hard or soft, defaults to hard.
+	FailureMode string `json:"failureMode,omitempty"`
+	// Timeout bounds a request to an OCSP responder or CRL distribution
+	// point, e.g. 5s.
+	Timeout string `json:"timeout,omitempty"`
+	// CacheDir persists the fetched OCSP responses and CRLs until they
+	// expire so that they survive restarts. Responses are cached in memory
+	// only if empty.
+	CacheDir string `json:"cacheDir,omitempty"`
+}
+
+// Checker checks the revocation status of certificate chains with OCSP,
+// falling back to the CRL distribution points of certificates without an
+// OCSP responder or whose responders are unavailable. It implements the
+// revocation.Revocation interface of notation.
+type Checker struct {
+	httpClient  *http.Client
+	failureMode string
+	cache       *responseCache
+}
+
+// NewChecker creates a Checker from the config.
+func NewChecker(config Config) (*Checker, error) {
+	failureMode := strings.ToLower(config.FailureMode)
+	switch failureMode {
+	case "":
+		failureMode = FailureModeHard
+	case FailureModeHard, FailureModeSoft:
+	default:
+		return nil, fmt.Errorf("invalid revocation failure mode %q, expected %s or %s", config.FailureMode, FailureModeHard, FailureModeSoft)
+	}
+	timeout := defaultTimeout
+	if config.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(config.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid revocation timeout %q", config.Timeout)
+		}
+	}
+	return &Checker{
+		httpClient:  &http.Client{Timeout: timeout},
+		failureMode: failureMode,
+		cache:       newResponseCache(config.CacheDir),
+	}, nil
+}
+
+// Validate checks the revocation status of every certificate of the chain,
+// which is ordered from the leaf to the root. Certificates revoked after the
+// signing time are still valid for signatures made before their revocation
+// if the signing time is set.
+func (c *Checker) Validate(certChain []*x509.Certificate, signingTime time.Time) ([]*result.CertRevocationResult, error) {
+	if len(certChain) == 0 {
+		return nil, result.InvalidChainError{Err: errors.New("chain does not contain any certificates")}
+	}
+	ctx := context.Background()
+	results := make([]*result.CertRevocationResult, len(certChain))
+	for i, cert := range certChain {
+		var issuer *x509.Certificate
+		if i+1 < len(certChain) {
+			issuer = certChain[i+1]
+		} else if isSelfSigned(cert) {
+			results[i] = nonRevokable()
+			continue
+		}
+		results[i] = c.checkCertificate(ctx, cert, issuer, signingTime)
+	}
+	return results, nil
+}
+
+// CheckChain validates the revocation status of the chain and returns an
+// error if a certificate is revoked or, in hard failure mode, its status is
+// unknown.
+func (c *Checker) CheckChain(certChain []*x509.Certificate, signingTime time.Time) error {
+	results, err := c.Validate(certChain, signingTime)
+	if err != nil {
+		return err
+	}
+	for i, certResult := range results {
+		switch certResult.Result {
+		case result.ResultRevoked:
+			return fmt.Errorf("certificate %q is revoked", certChain[i].Subject)
+		case result.ResultUnknown:
+			return fmt.Errorf("revocation status of certificate %q is unknown: %w", certChain[i].Subject, serverErrors(certResult))
+		}
+	}
+	return nil
+}
+
+// checkCertificate checks the revocation status of a certificate issued by
+// the issuer and applies the failure mode to unknown results.
+func (c *Checker) checkCertificate(ctx context.Context, cert, issuer *x509.Certificate, signingTime time.Time) *result.CertRevocationResult {
+	start := time.Now()
+	protocol := protocolNone
+	cached := false
+	var certResult *result.CertRevocationResult
+	switch {
+	case len(cert.OCSPServer) == 0 && len(cert.CRLDistributionPoints) == 0:
+		certResult = nonRevokable()
+	case issuer == nil:
+		certResult = unknown("", fmt.Errorf("issuer of certificate %q is not in the chain", cert.Subject))
+	default:
+		if len(cert.OCSPServer) > 0 {
+			protocol = protocolOCSP
+			certResult, cached = c.checkOCSP(cert, issuer, signingTime)
+		}
+		if (certResult == nil || certResult.Result == result.ResultUnknown) && len(cert.CRLDistributionPoints) > 0 {
+			var crlResult *result.CertRevocationResult
+			crlResult, cached = c.checkCRL(cert, issuer, signingTime)
+			if certResult != nil {
+				crlResult.ServerResults = append(certResult.ServerResults, crlResult.ServerResults...)
+			}
+			protocol = protocolCRL
+			certResult = crlResult
+		}
+	}
+	metrics.ReportRevocationCheckDuration(ctx, time.Since(start).Milliseconds(), protocol, certResult.Result.String(), cached)
+
+	if certResult.Result == result.ResultUnknown && c.failureMode == FailureModeSoft {
+		logger.GetLogger(ctx, logOpt).Warnf("revocation status of certificate %q is unknown, accepting it as the failure mode is soft: %v", cert.Subject, serverErrors(certResult))
+		certResult.Result = result.ResultOK
+	}
+	return certResult
+}
+
+// checkOCSP queries the OCSP responders of the certificate in order until one
+// of them returns a valid response.
+func (c *Checker) checkOCSP(cert, issuer *x509.Certificate, signingTime time.Time) (*result.CertRevocationResult, bool) {
+	key := cacheKey(protocolOCSP, cert.Raw)
+	if raw, ok := c.cache.get(key); ok {
+		if resp, err := ocsp.ParseResponseForCert(raw, cert, issuer); err == nil && isFresh(resp.NextUpdate) {
+			status := ocspResult(resp, signingTime)
+			return &result.CertRevocationResult{
+				Result:        status,
+				ServerResults: []*result.ServerResult{result.NewServerResult(status, "", nil)},
+			}, true
+		}
+	}
+
+	request, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return unknown("", fmt.Errorf("failed to create OCSP request: %w", err)), false
+	}
+	serverResults := make([]*result.ServerResult, 0, len(cert.OCSPServer))
+	for _, server := range cert.OCSPServer {
+		raw, err := c.fetch(http.MethodPost, server, request)
+		if err != nil {
+			serverResults = append(serverResults, result.NewServerResult(result.ResultUnknown, server, err))
+			continue
+		}
+		resp, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+		if err != nil {
+			serverResults = append(serverResults, result.NewServerResult(result.ResultUnknown, server, fmt.Errorf("failed to parse OCSP response: %w", err)))
+			continue
+		}
+		if !isFresh(resp.NextUpdate) {
+			serverResults = append(serverResults, result.NewServerResult(result.ResultUnknown, server, fmt.Errorf("OCSP response expired at %v", resp.NextUpdate)))
+			continue
+		}
+		if resp.Status != ocsp.Unknown {
+			c.cache.set(key, raw, resp.NextUpdate)
+		}
+		status := ocspResult(resp, signingTime)
+		return &result.CertRevocationResult{
+			Result:        status,
+			ServerResults: []*result.ServerResult{result.NewServerResult(status, server, nil)},
+		}, false
+	}
+	return &result.CertRevocationResult{Result: result.ResultUnknown, ServerResults: serverResults}, false
+}
+
+// checkCRL looks the certificate up in the CRL of the first distribution
+// point that returns a valid CRL signed by the issuer.
+func (c *Checker) checkCRL(cert, issuer *x509.Certificate, signingTime time.Time) (*result.CertRevocationResult, bool) {
+	serverResults := make([]*result.ServerResult, 0, len(cert.CRLDistributionPoints))
+	for _, url := range cert.CRLDistributionPoints {
+		key := cacheKey(protocolCRL, []byte(url))
+		raw, cached := c.cache.get(key)
+		if cached {
+			if crl, err := parseCRL(raw, issuer); err == nil {
+				status := crlResult(crl, cert, signingTime)
+				return &result.CertRevocationResult{
+					Result:        status,
+					ServerResults: []*result.ServerResult{result.NewServerResult(status, url, nil)},
+				}, true
+			}
+		}
+
+		raw, err := c.fetch(http.MethodGet, url, nil)
+		if err != nil {
+			serverResults = append(serverResults, result.NewServerResult(result.ResultUnknown, url, err))
+			continue
+		}
+		crl, err := parseCRL(raw, issuer)
+		if err != nil {
+			serverResults = append(serverResults, result.NewServerResult(result.ResultUnknown, url, err))
+			continue
+		}
+		c.cache.set(key, raw, crl.NextUpdate)
+		status := crlResult(crl, cert, signingTime)
+		return &result.CertRevocationResult{
+			Result:        status,
+			ServerResults: []*result.ServerResult{result.NewServerResult(status, url, nil)},
+		}, false
+	}
+	return &result.CertRevocationResult{Result: result.ResultUnknown, ServerResults: serverResults}, false
+}
+
+// fetch sends a request for an OCSP response or CRL to the server.
+func (c *Checker) fetch(method string, url string, body []byte) ([]byte, error) {
+	if !strings.HasPrefix(strings.ToLower(url), "http://") && !strings.HasPrefix(strings.ToLower(url), "https://") {
+		return nil, fmt.Errorf("unsupported revocation server URL %q", url)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxResponseSize {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", url, maxResponseSize)
+	}
+	return raw, nil
+}
+
+// parseCRL parses a DER encoded CRL and verifies that it was signed by the
+// issuer and has not expired.
+func parseCRL(raw []byte, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	crl, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CRL: %w", err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("CRL is not signed by the issuer: %w", err)
+	}
+	if !isFresh(crl.NextUpdate) {
+		return nil, fmt.Errorf("CRL expired at %v", crl.NextUpdate)
+	}
+	return crl, nil
+}
+
+func ocspResult(resp *ocsp.Response, signingTime time.Time) result.Result {
+	switch resp.Status {
+	case ocsp.Good:
+		return result.ResultOK
+	case ocsp.Revoked:
+		if !signingTime.IsZero() && signingTime.Before(resp.RevokedAt) {
+			return result.ResultOK
+		}
+		return result.ResultRevoked
+	default:
+		return result.ResultUnknown
+	}
+}
+
+func crlResult(crl *x509.RevocationList, cert *x509.Certificate, signingTime time.Time) result.Result {
+	//nolint:staticcheck // RevokedCertificateEntries requires go 1.21
+	for _, revoked := range crl.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+			continue
+		}
+		if !signingTime.IsZero() && signingTime.Before(revoked.RevocationTime) {
+			return result.ResultOK
+		}
+		return result.ResultRevoked
+	}
+	return result.ResultOK
+}
+
+func isFresh(nextUpdate time.Time) bool {
+	return nextUpdate.IsZero() || time.Now().Before(nextUpdate)
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+func nonRevokable() *result.CertRevocationResult {
+	return &result.CertRevocationResult{
+		Result:        result.ResultNonRevokable,
+		ServerResults: []*result.ServerResult{result.NewServerResult(result.ResultNonRevokable, "", nil)},
+	}
+}
+
+func unknown(server string, err error) *result.CertRevocationResult {
+	return &result.CertRevocationResult{
+		Result:        result.ResultUnknown,
+		ServerResults: []*result.ServerResult{result.NewServerResult(result.ResultUnknown, server, err)},
+	}
+}
+
+func serverErrors(certResult *result.CertRevocationResult) error {
+	var errs []error
+	for _, serverResult := range certResult.ServerResults {
+		if serverResult.Error != nil {
+			errs = append(errs, serverResult.Error)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,355 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revocation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/revocation/result"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testPKI struct {
+	root    *x509.Certificate
+	rootKey *ecdsa.PrivateKey
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testPKI{root: root, rootKey: key}
+}
+
+func (p *testPKI) leaf(t *testing.T, serial int64, ocspServer string, crlURL string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "test leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if ocspServer != "" {
+		template.OCSPServer = []string{ocspServer}
+	}
+	if crlURL != "" {
+		template.CRLDistributionPoints = []string{crlURL}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.root, &key.PublicKey, p.rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// ocspServer responds with the status to every request and counts them.
+func (p *testPKI) ocspServer(t *testing.T, status int, revokedAt time.Time) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp, err := ocsp.CreateResponse(p.root, p.root, ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    revokedAt,
+		}, p.rootKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// crlServer serves a CRL revoking the serial numbers.
+func (p *testPKI) crlServer(t *testing.T, revokedAt time.Time, serials ...int64) *httptest.Server {
+	t.Helper()
+	revoked := make([]pkix.RevokedCertificate, 0, len(serials))
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: revokedAt})
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          time.Now().Add(-time.Minute),
+		NextUpdate:          time.Now().Add(time.Hour),
+		RevokedCertificates: revoked,
+	}, p.root, p.rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(crl)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func unavailableServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "default", config: Config{}},
+		{name: "soft failure mode", config: Config{FailureMode: "Soft", Timeout: "2s"}},
+		{name: "invalid failure mode", config: Config{FailureMode: "lenient"}, wantErr: true},
+		{name: "invalid timeout", config: Config{Timeout: "soon"}, wantErr: true},
+		{name: "negative timeout", config: Config{Timeout: "-1s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewChecker(tt.config); (err != nil) != tt.wantErr {
+				t.Fatalf("NewChecker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_OCSP(t *testing.T) {
+	pki := newTestPKI(t)
+	revokedAt := time.Now().Add(-10 * time.Minute)
+	good, requests := pki.ocspServer(t, ocsp.Good, time.Time{})
+	revoked, _ := pki.ocspServer(t, ocsp.Revoked, revokedAt)
+
+	checker, err := NewChecker(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := []*x509.Certificate{pki.leaf(t, 2, good.URL, ""), pki.root}
+	for i := 0; i < 2; i++ {
+		results, err := checker.Validate(chain, time.Time{})
+		if err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if results[0].Result != result.ResultOK || results[1].Result != result.ResultNonRevokable {
+			t.Fatalf("expected OK and NonRevokable results, got %v and %v", results[0].Result, results[1].Result)
+		}
+	}
+	if *requests != 1 {
+		t.Fatalf("expected the OCSP response to be cached, got %d requests", *requests)
+	}
+
+	chain = []*x509.Certificate{pki.leaf(t, 3, revoked.URL, ""), pki.root}
+	results, err := checker.Validate(chain, time.Time{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if results[0].Result != result.ResultRevoked {
+		t.Fatalf("expected Revoked result, got %v", results[0].Result)
+	}
+	// signatures made before the revocation stay valid
+	results, err = checker.Validate(chain, revokedAt.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if results[0].Result != result.ResultOK {
+		t.Fatalf("expected OK result for signing time before revocation, got %v", results[0].Result)
+	}
+}
+
+func TestValidate_CRLFallback(t *testing.T) {
+	pki := newTestPKI(t)
+	crl := pki.crlServer(t, time.Now().Add(-time.Minute), 4)
+	ocspDown := unavailableServer(t)
+
+	checker, err := NewChecker(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := checker.Validate([]*x509.Certificate{pki.leaf(t, 4, ocspDown.URL, crl.URL), pki.root}, time.Time{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if results[0].Result != result.ResultRevoked {
+		t.Fatalf("expected Revoked result, got %v", results[0].Result)
+	}
+	if len(results[0].ServerResults) != 2 || results[0].ServerResults[0].Error == nil {
+		t.Fatalf("expected the OCSP failure and the CRL result, got %+v", results[0].ServerResults)
+	}
+
+	results, err = checker.Validate([]*x509.Certificate{pki.leaf(t, 5, "", crl.URL), pki.root}, time.Time{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if results[0].Result != result.ResultOK {
+		t.Fatalf("expected OK result, got %v", results[0].Result)
+	}
+}
+
+func TestValidate_FailureMode(t *testing.T) {
+	pki := newTestPKI(t)
+	ocspDown := unavailableServer(t)
+	chain := []*x509.Certificate{pki.leaf(t, 6, ocspDown.URL, ""), pki.root}
+
+	tests := []struct {
+		failureMode string
+		want        result.Result
+	}{
+		{failureMode: FailureModeHard, want: result.ResultUnknown},
+		{failureMode: FailureModeSoft, want: result.ResultOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failureMode, func(t *testing.T) {
+			checker, err := NewChecker(Config{FailureMode: tt.failureMode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := checker.Validate(chain, time.Time{})
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if results[0].Result != tt.want {
+				t.Fatalf("expected %v result, got %v", tt.want, results[0].Result)
+			}
+		})
+	}
+}
+
+func TestValidate_NonRevokable(t *testing.T) {
+	pki := newTestPKI(t)
+	checker, err := NewChecker(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := checker.Validate([]*x509.Certificate{pki.leaf(t, 7, "", ""), pki.root}, time.Time{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, certResult := range results {
+		if certResult.Result != result.ResultNonRevokable {
+			t.Fatalf("expected NonRevokable result, got %v", certResult.Result)
+		}
+	}
+
+	if _, err := checker.Validate(nil, time.Time{}); err == nil {
+		t.Fatal("expected error for empty chain")
+	}
+}
+
+func TestValidate_DiskCache(t *testing.T) {
+	pki := newTestPKI(t)
+	crl := pki.crlServer(t, time.Now().Add(-time.Minute), 8)
+	cacheDir := t.TempDir()
+	chain := []*x509.Certificate{pki.leaf(t, 8, "", crl.URL), pki.root}
+
+	checker, err := NewChecker(Config{CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := checker.Validate(chain, time.Time{}); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	crl.Close()
+
+	// a new checker loads the CRL from the cache directory
+	checker, err = NewChecker(Config{CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := checker.Validate(chain, time.Time{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if results[0].Result != result.ResultRevoked {
+		t.Fatalf("expected Revoked result from the cached CRL, got %v", results[0].Result)
+	}
+}
+
+func TestCheckChain(t *testing.T) {
+	pki := newTestPKI(t)
+	revoked, _ := pki.ocspServer(t, ocsp.Revoked, time.Now().Add(-time.Minute))
+	ocspDown := unavailableServer(t)
+
+	hard, err := NewChecker(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	soft, err := NewChecker(Config{FailureMode: FailureModeSoft})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hard.CheckChain([]*x509.Certificate{pki.leaf(t, 9, revoked.URL, ""), pki.root}, time.Time{}); err == nil {
+		t.Fatal("expected error for revoked certificate")
+	}
+	if err := hard.CheckChain([]*x509.Certificate{pki.leaf(t, 10, ocspDown.URL, ""), pki.root}, time.Time{}); err == nil {
+		t.Fatal("expected error for unknown revocation status in hard failure mode")
+	}
+	if err := soft.CheckChain([]*x509.Certificate{pki.leaf(t, 11, ocspDown.URL, ""), pki.root}, time.Time{}); err != nil {
+		t.Fatalf("expected no error in soft failure mode, got %v", err)
+	}
+	// the issuer of the leaf is missing from the chain
+	if err := hard.CheckChain([]*x509.Certificate{pki.leaf(t, 12, revoked.URL, "")}, time.Time{}); err == nil {
+		t.Fatal("expected error for incomplete chain")
+	}
+}
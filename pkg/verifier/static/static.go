@@ -0,0 +1,217 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/config"
+	"github.com/deislabs/ratify/pkg/verifier/factory"
+	"github.com/deislabs/ratify/pkg/verifier/types"
+)
+
+const (
+	verifierType = "static"
+
+	// ResultSuccess passes the verification.
+	ResultSuccess = "success"
+	// ResultFailure fails the verification.
+	ResultFailure = "failure"
+	// ResultInconclusive reports the verification as inconclusive, as if the
+	// verifier could not run.
+	ResultInconclusive = "inconclusive"
+)
+
+// VerifierConfig describes the configuration of the static verifier.
+type VerifierConfig struct {
+	Name          string `json:"name"`
+	ArtifactTypes string `json:"artifactTypes"`
+	// Rules are evaluated in order, the first rule matching the subject and
+	// the artifact type decides the result.
+	Rules []Rule `json:"rules,omitempty"`
+	// Result is returned if no rule matches. Defaults to failure.
+	Result string `json:"result,omitempty"`
+	// Message is reported if no rule matches.
+	Message string `json:"message,omitempty"`
+}
+
+// Rule returns a configured result for matching subjects and artifact types.
+type Rule struct {
+	// Subjects are patterns of the subject repository, e.g.
+	// myregistry.io/team/*, or of the subject reference by digest, e.g.
+	// myregistry.io/team/app@sha256:*. The rule matches all subjects if empty.
+	Subjects []string `json:"subjects,omitempty"`
+	// ArtifactTypes restrict the rule to artifact types, the rule matches all
+	// artifact types of the verifier if empty.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+	// Result is success, failure or inconclusive.
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+type staticVerifier struct {
+	name          string
+	verifierType  string
+	artifactTypes []string
+	rules         []Rule
+	result        string
+	message       string
+}
+
+type staticVerifierFactory struct{}
+
+func init() {
+	factory.Register(verifierType, &staticVerifierFactory{})
+}
+
+func (f *staticVerifierFactory) Create(_ string, verifierConfig config.VerifierConfig, _ string, _ string) (verifier.ReferenceVerifier, error) {
+	verifierName := fmt.Sprintf("%s", verifierConfig[types.Name])
+	verifierTypeStr := ""
+	if _, ok := verifierConfig[types.Type]; ok {
+		verifierTypeStr = fmt.Sprintf("%s", verifierConfig[types.Type])
+	}
+	conf, err := parseVerifierConfig(verifierConfig)
+	if err != nil {
+		return nil, re.ErrorCodeConfigInvalid.NewError(re.Verifier, verifierName, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+
+	return &staticVerifier{
+		name:          verifierName,
+		verifierType:  verifierTypeStr,
+		artifactTypes: strings.Split(conf.ArtifactTypes, ","),
+		rules:         conf.Rules,
+		result:        conf.Result,
+		message:       conf.Message,
+	}, nil
+}
+
+func (v *staticVerifier) Name() string {
+	return v.name
+}
+
+func (v *staticVerifier) Type() string {
+	return v.verifierType
+}
+
+func (v *staticVerifier) CanVerify(_ context.Context, referenceDescriptor ocispecs.ReferenceDescriptor) bool {
+	return matchArtifactType(v.artifactTypes, referenceDescriptor.ArtifactType)
+}
+
+func (v *staticVerifier) Verify(_ context.Context,
+	subjectReference common.Reference,
+	referenceDescriptor ocispecs.ReferenceDescriptor,
+	_ referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	result, message := v.result, v.message
+	for _, rule := range v.rules {
+		if rule.matches(subjectReference, referenceDescriptor.ArtifactType) {
+			result, message = rule.Result, rule.Message
+			break
+		}
+	}
+	if message == "" {
+		message = fmt.Sprintf("static verification %s", result)
+	}
+
+	if result == ResultInconclusive {
+		return verifier.VerifierResult{IsSuccess: false}, re.ErrorCodeVerificationInconclusive.WithDetail(message)
+	}
+	return verifier.VerifierResult{
+		Name:      v.name,
+		Type:      v.verifierType,
+		IsSuccess: result == ResultSuccess,
+		Message:   message,
+	}, nil
+}
+
+func (v *staticVerifier) GetNestedReferences() []string {
+	return []string{}
+}
+
+// matches returns true if the rule applies to the subject and artifact type.
+func (r Rule) matches(subjectReference common.Reference, artifactType string) bool {
+	if len(r.ArtifactTypes) > 0 && !matchArtifactType(r.ArtifactTypes, artifactType) {
+		return false
+	}
+	if len(r.Subjects) == 0 {
+		return true
+	}
+	reference := fmt.Sprintf("%s@%s", subjectReference.Path, subjectReference.Digest)
+	for _, pattern := range r.Subjects {
+		if ok, _ := path.Match(pattern, subjectReference.Path); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, reference); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchArtifactType(artifactTypes []string, artifactType string) bool {
+	for _, at := range artifactTypes {
+		if at == "*" || at == artifactType {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVerifierConfig(verifierConfig config.VerifierConfig) (*VerifierConfig, error) {
+	conf := &VerifierConfig{}
+	verifierConfigBytes, err := json.Marshal(verifierConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(verifierConfigBytes, conf); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal static verifier config: %w", err)
+	}
+
+	if conf.Result == "" {
+		conf.Result = ResultFailure
+	}
+	if err := validateResult(conf.Result); err != nil {
+		return nil, err
+	}
+	for i, rule := range conf.Rules {
+		if err := validateResult(rule.Result); err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+		for _, pattern := range rule.Subjects {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid subject pattern %s of rule %d: %w", pattern, i, err)
+			}
+		}
+	}
+	return conf, nil
+}
+
+func validateResult(result string) error {
+	switch result {
+	case ResultSuccess, ResultFailure, ResultInconclusive:
+		return nil
+	default:
+		return fmt.Errorf("result must be %s, %s or %s, got %q", ResultSuccess, ResultFailure, ResultInconclusive, result)
+	}
+}
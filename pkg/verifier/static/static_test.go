@@ -0,0 +1,143 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package static
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/config"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	testSBOMType      = "application/spdx+json"
+	testSignatureType = "application/vnd.cncf.notary.signature"
+)
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    config.VerifierConfig
+		expectErr bool
+	}{
+		{
+			name:   "default result",
+			config: config.VerifierConfig{"name": "static", "artifactTypes": "*"},
+		},
+		{
+			name:      "invalid result",
+			config:    config.VerifierConfig{"name": "static", "artifactTypes": "*", "result": "pass"},
+			expectErr: true,
+		},
+		{
+			name: "invalid rule result",
+			config: config.VerifierConfig{"name": "static", "artifactTypes": "*", "rules": []interface{}{
+				map[string]interface{}{"result": "pass"},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "invalid subject pattern",
+			config: config.VerifierConfig{"name": "static", "artifactTypes": "*", "rules": []interface{}{
+				map[string]interface{}{"subjects": []interface{}{"registry.io/["}, "result": "success"},
+			}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := (&staticVerifierFactory{}).Create("1.0.0", tc.config, "", ""); tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	v, err := (&staticVerifierFactory{}).Create("1.0.0", config.VerifierConfig{
+		"name":          "static",
+		"artifactTypes": testSBOMType + "," + testSignatureType,
+		"rules": []interface{}{
+			map[string]interface{}{"subjects": []interface{}{"registry.io/canary/*"}, "artifactTypes": []interface{}{testSBOMType}, "result": "inconclusive"},
+			map[string]interface{}{"subjects": []interface{}{"registry.io/canary/*", "registry.io/app@" + digest.FromString("pinned").String()}, "result": "success", "message": "staged rollout"},
+		},
+		"message": "not part of the rollout",
+	}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	testCases := []struct {
+		name                 string
+		subject              common.Reference
+		artifactType         string
+		expectedSuccess      bool
+		expectedMessage      string
+		expectedInconclusive bool
+	}{
+		{
+			name:            "matching repository",
+			subject:         common.Reference{Path: "registry.io/canary/app", Digest: digest.FromString("app")},
+			artifactType:    testSignatureType,
+			expectedSuccess: true,
+			expectedMessage: "staged rollout",
+		},
+		{
+			name:                 "matching artifact type",
+			subject:              common.Reference{Path: "registry.io/canary/app", Digest: digest.FromString("app")},
+			artifactType:         testSBOMType,
+			expectedInconclusive: true,
+		},
+		{
+			name:            "matching digest",
+			subject:         common.Reference{Path: "registry.io/app", Digest: digest.FromString("pinned")},
+			artifactType:    testSignatureType,
+			expectedSuccess: true,
+			expectedMessage: "staged rollout",
+		},
+		{
+			name:            "no matching rule",
+			subject:         common.Reference{Path: "registry.io/app", Digest: digest.FromString("app")},
+			artifactType:    testSignatureType,
+			expectedMessage: "not part of the rollout",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			desc := ocispecs.ReferenceDescriptor{ArtifactType: tc.artifactType}
+			if !v.CanVerify(context.Background(), desc) {
+				t.Fatalf("expected verifier to verify %s", tc.artifactType)
+			}
+			result, err := v.Verify(context.Background(), tc.subject, desc, nil)
+			if tc.expectedInconclusive {
+				if !verifier.IsInconclusive(err) {
+					t.Fatalf("expected inconclusive error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsSuccess != tc.expectedSuccess || result.Message != tc.expectedMessage {
+				t.Fatalf("unexpected result %+v", result)
+			}
+		})
+	}
+}
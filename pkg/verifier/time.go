@@ -0,0 +1,42 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"context"
+	"time"
+)
+
+type verificationTimeKey struct{}
+
+// WithVerificationTime returns a context requesting verifiers to evaluate trust
+// as of the given time instead of the current time, e.g. to audit whether an
+// image was valid when it was admitted.
+func WithVerificationTime(ctx context.Context, verificationTime time.Time) context.Context {
+	if verificationTime.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, verificationTimeKey{}, verificationTime)
+}
+
+// VerificationTime returns the time trust is evaluated at and whether it was
+// requested in the context. It returns the current time if it was not requested.
+func VerificationTime(ctx context.Context) (time.Time, bool) {
+	if verificationTime, ok := ctx.Value(verificationTimeKey{}).(time.Time); ok {
+		return verificationTime, true
+	}
+	return time.Now(), false
+}
@@ -18,6 +18,7 @@ package types
 import (
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/verifier"
 )
@@ -30,6 +31,15 @@ const (
 	ArtifactTypes    string = "artifactTypes"
 	NestedReferences string = "nestedReferences"
 	Source           string = "source"
+	Priority         string = "priority"
+	DependsOn        string = "dependsOn"
+	Timeout          string = "timeout"
+	CircuitBreaker   string = "circuitBreaker"
+	// KeyManagementProvider is the namespaced name of the key management
+	// provider whose public keys are passed to the verifier plugin in its
+	// config with the key Keys.
+	KeyManagementProvider string = "keyManagementProvider"
+	Keys                  string = "keys"
 )
 
 const (
@@ -47,11 +57,16 @@ const (
 
 // VerifierResult describes the verification result returned from the verifier plugin
 type VerifierResult struct {
-	IsSuccess  bool        `json:"isSuccess"`
-	Message    string      `json:"message"`
-	Name       string      `json:"name"`
-	Type       string      `json:"type,omitempty"`
-	Extensions interface{} `json:"extensions"`
+	IsSuccess    bool              `json:"isSuccess"`
+	Inconclusive bool              `json:"inconclusive,omitempty"`
+	Signers      []verifier.Signer `json:"signers,omitempty"`
+	Message      string            `json:"message"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type,omitempty"`
+	Extensions   interface{}       `json:"extensions"`
+	ErrorCode    string            `json:"errorCode,omitempty"`
+	VerifiedAt   *time.Time        `json:"verifiedAt,omitempty"`
+	ValidUntil   *time.Time        `json:"validUntil,omitempty"`
 }
 
 // GetVerifierResult encodes the given JSON data into verify result object
@@ -61,11 +76,14 @@ func GetVerifierResult(result []byte) (*verifier.VerifierResult, error) {
 		return nil, err
 	}
 	return &verifier.VerifierResult{
-		IsSuccess:  vResult.IsSuccess,
-		Message:    vResult.Message,
-		Name:       vResult.Name,
-		Type:       vResult.Type,
-		Extensions: vResult.Extensions,
+		IsSuccess:    vResult.IsSuccess,
+		Inconclusive: vResult.Inconclusive,
+		Signers:      vResult.Signers,
+		Message:      vResult.Message,
+		Name:         vResult.Name,
+		Type:         vResult.Type,
+		Extensions:   vResult.Extensions,
+		ValidUntil:   vResult.ValidUntil,
 	}, nil
 }
 
@@ -78,9 +96,14 @@ func WriteVerifyResultResult(result *verifier.VerifierResult, w io.Writer) error
 // verifier.VerifierResult.
 func NewVerifierResult(result verifier.VerifierResult) VerifierResult {
 	return VerifierResult{
-		IsSuccess:  result.IsSuccess,
-		Message:    result.Message,
-		Name:       result.Name,
-		Extensions: result.Extensions,
+		IsSuccess:    result.IsSuccess,
+		Inconclusive: result.Inconclusive,
+		Signers:      result.Signers,
+		Message:      result.Message,
+		Name:         result.Name,
+		Extensions:   result.Extensions,
+		ErrorCode:    result.ErrorCode,
+		VerifiedAt:   result.VerifiedAt,
+		ValidUntil:   result.ValidUntil,
 	}
 }
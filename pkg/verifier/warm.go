@@ -0,0 +1,34 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import "context"
+
+// Warmer is implemented by verifiers that can prepare ahead of their first
+// verification, e.g. by starting their plugin or loading their trust stores.
+type Warmer interface {
+	// Warm prepares the verifier, it returns an error if the verifier will
+	// not be able to verify.
+	Warm(ctx context.Context) error
+}
+
+// Warm prepares the verifier if it implements Warmer.
+func Warm(ctx context.Context, verifier ReferenceVerifier) error {
+	if warmer, ok := verifier.(Warmer); ok {
+		return warmer.Warm(ctx)
+	}
+	return nil
+}
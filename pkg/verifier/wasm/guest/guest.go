@@ -0,0 +1,174 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package guest is the library for verifiers compiled to WebAssembly modules
+// with GOOS=wasip1 GOARCH=wasm. It implements the verifier plugin protocol and
+// accesses the referrer store through the host functions of Ratify. The
+// package only depends on the standard library and the OCI specs, as most of
+// Ratify does not compile to WebAssembly.
+//
+// A module is loaded by Ratify when it is named after the verifier type with
+// the .wasm extension, e.g. sample.wasm, and placed in a plugin directory:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o ~/.ratify/plugins/sample.wasm .
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/opencontainers/go-digest"
+)
+
+// the environment keys and error codes match the verifier plugin protocol
+const (
+	commandEnvKey = "RATIFY_VERIFIER_COMMAND"
+	subjectEnvKey = "RATIFY_VERIFIER_SUBJECT"
+	versionEnvKey = "RATIFY_VERIFIER_VERSION"
+	timeEnvKey    = "RATIFY_VERIFIER_TIME"
+	verifyCommand = "VERIFY"
+
+	errConfigParsingFailure        uint = 1
+	errUnknownCommand              uint = 3
+	errMissingEnvironmentVariables uint = 4
+	errIOFailure                   uint = 5
+	errArgsParsingFailure          uint = 7
+	errPluginCmdFailure            uint = 8
+)
+
+// VerifyReference verifies the artifact of the reference descriptor.
+type VerifyReference func(args *Args, store Store) (*Result, error)
+
+// Args are the arguments of a verification.
+type Args struct {
+	Version string
+	// Subject is the reference of the subject image.
+	Subject string
+	// Config is the verifier config.
+	Config map[string]interface{}
+	// ReferenceDescriptor describes the artifact to verify.
+	ReferenceDescriptor ocispecs.ReferenceDescriptor
+	// VerificationTime is the time to evaluate trust at, it is the current
+	// time unless Ratify requested to evaluate trust as of another time.
+	VerificationTime time.Time
+}
+
+// Result is the verification result.
+type Result struct {
+	IsSuccess  bool        `json:"isSuccess"`
+	Message    string      `json:"message"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type,omitempty"`
+	Extensions interface{} `json:"extensions"`
+}
+
+// Store reads the artifacts of the subject from the referrer store of the host.
+type Store interface {
+	GetReferenceManifest(referenceDescriptor ocispecs.ReferenceDescriptor) (ocispecs.ReferenceManifest, error)
+	GetBlobContent(digest digest.Digest) ([]byte, error)
+}
+
+type pluginError struct {
+	Code    uint   `json:"code"`
+	Msg     string `json:"msg"`
+	Details string `json:"details,omitempty"`
+}
+
+type input struct {
+	Config              map[string]interface{}       `json:"config"`
+	ReferenceDescriptor ocispecs.ReferenceDescriptor `json:"referenceDesc"`
+}
+
+// hostStore is the store backed by the host functions.
+type hostStore struct{}
+
+// Main runs the verification requested by the host and exits.
+func Main(verifyReference VerifyReference) {
+	result, e := run(os.Getenv, os.Stdin, verifyReference, hostStore{})
+	if e != nil {
+		_ = json.NewEncoder(os.Stdout).Encode(e)
+		os.Exit(1)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run(getEnviron func(string) string, stdin io.Reader, verifyReference VerifyReference, store Store) (*Result, *pluginError) {
+	for _, key := range []string{commandEnvKey, subjectEnvKey, versionEnvKey} {
+		if getEnviron(key) == "" {
+			return nil, &pluginError{Code: errMissingEnvironmentVariables, Msg: fmt.Sprintf("missing env variables [%s]", key)}
+		}
+	}
+	if cmd := getEnviron(commandEnvKey); cmd != verifyCommand {
+		return nil, &pluginError{Code: errUnknownCommand, Msg: fmt.Sprintf("unknown %s: %v", commandEnvKey, cmd)}
+	}
+
+	stdinData, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, &pluginError{Code: errIOFailure, Msg: fmt.Sprintf("error reading from stdin: %v", err)}
+	}
+	var in input
+	if err := json.Unmarshal(stdinData, &in); err != nil {
+		return nil, &pluginError{Code: errConfigParsingFailure, Msg: fmt.Sprintf("error unmarshall verifier config: %v", err)}
+	}
+
+	verificationTime := time.Now()
+	if value := getEnviron(timeEnvKey); value != "" {
+		if verificationTime, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return nil, &pluginError{Code: errArgsParsingFailure, Msg: fmt.Sprintf("cannot parse verification time %s", value), Details: err.Error()}
+		}
+	}
+
+	result, err := verifyReference(&Args{
+		Version:             getEnviron(versionEnvKey),
+		Subject:             getEnviron(subjectEnvKey),
+		Config:              in.Config,
+		ReferenceDescriptor: in.ReferenceDescriptor,
+		VerificationTime:    verificationTime,
+	}, store)
+	if err != nil {
+		return nil, &pluginError{Code: errPluginCmdFailure, Msg: fmt.Sprintf("plugin command %s failed", verifyCommand), Details: err.Error()}
+	}
+	return result, nil
+}
+
+func (hostStore) GetReferenceManifest(referenceDescriptor ocispecs.ReferenceDescriptor) (ocispecs.ReferenceManifest, error) {
+	request, err := json.Marshal(referenceDescriptor)
+	if err != nil {
+		return ocispecs.ReferenceManifest{}, err
+	}
+	response, err := callHost(getReferenceManifest, request)
+	if err != nil {
+		return ocispecs.ReferenceManifest{}, fmt.Errorf("failed to get reference manifest: %w", err)
+	}
+	var manifest ocispecs.ReferenceManifest
+	if err := json.Unmarshal(response, &manifest); err != nil {
+		return ocispecs.ReferenceManifest{}, err
+	}
+	return manifest, nil
+}
+
+func (hostStore) GetBlobContent(digest digest.Digest) ([]byte, error) {
+	content, err := callHost(getBlobContent, []byte(digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob content: %w", err)
+	}
+	return content, nil
+}
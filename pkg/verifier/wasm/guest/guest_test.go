@@ -0,0 +1,78 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	environ := map[string]string{
+		commandEnvKey: verifyCommand,
+		subjectEnvKey: "localhost/image@sha256:abc",
+		versionEnvKey: "1.0.0",
+	}
+	getEnviron := func(key string) string {
+		return environ[key]
+	}
+	stdin := `{"config":{"name":"sample"},"referenceDesc":{"artifactType":"application/vnd.example"}}`
+
+	result, e := run(getEnviron, strings.NewReader(stdin), func(args *Args, store Store) (*Result, error) {
+		if _, err := store.GetBlobContent("sha256:abc"); err == nil {
+			t.Fatalf("expected host functions to fail outside of WebAssembly")
+		}
+		return &Result{Name: args.Config["name"].(string), IsSuccess: args.ReferenceDescriptor.ArtifactType == "application/vnd.example"}, nil
+	}, hostStore{})
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e.Msg)
+	}
+	if !result.IsSuccess || result.Name != "sample" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	_, e = run(getEnviron, strings.NewReader(stdin), func(_ *Args, _ Store) (*Result, error) {
+		return nil, errors.New("failed")
+	}, hostStore{})
+	if e == nil || e.Code != errPluginCmdFailure || e.Details != "failed" {
+		t.Fatalf("expected plugin command failure, got %+v", e)
+	}
+
+	if _, e = run(getEnviron, strings.NewReader("{"), nil, hostStore{}); e == nil || e.Code != errConfigParsingFailure {
+		t.Fatalf("expected config parsing failure, got %+v", e)
+	}
+
+	environ[timeEnvKey] = "2023-06-01T12:00:00Z"
+	if _, e = run(getEnviron, strings.NewReader(stdin), func(args *Args, _ Store) (*Result, error) {
+		if !args.VerificationTime.Equal(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected verification time %v", args.VerificationTime)
+		}
+		return &Result{IsSuccess: true}, nil
+	}, hostStore{}); e != nil {
+		t.Fatalf("unexpected error: %v", e.Msg)
+	}
+	environ[timeEnvKey] = "yesterday"
+	if _, e = run(getEnviron, strings.NewReader(stdin), nil, hostStore{}); e == nil || e.Code != errArgsParsingFailure {
+		t.Fatalf("expected args parsing failure, got %+v", e)
+	}
+
+	delete(environ, subjectEnvKey)
+	if _, e = run(getEnviron, strings.NewReader(stdin), nil, hostStore{}); e == nil || e.Code != errMissingEnvironmentVariables {
+		t.Fatalf("expected missing environment variables, got %+v", e)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !wasip1
+
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guest
+
+import "errors"
+
+var errNotWasm = errors.New("host functions are only available in WebAssembly modules")
+
+func getReferenceManifest(_, _ uint32) int32 {
+	return 0
+}
+
+func getBlobContent(_, _ uint32) int32 {
+	return 0
+}
+
+// callHost fails as the host functions only exist when running in Ratify.
+func callHost(_ func(ptr, size uint32) int32, _ []byte) ([]byte, error) {
+	return nil, errNotWasm
+}
@@ -0,0 +1,55 @@
+//go:build wasip1
+
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guest
+
+import (
+	"errors"
+	"unsafe"
+)
+
+//go:wasmimport ratify get_reference_manifest
+func getReferenceManifest(ptr, size uint32) int32
+
+//go:wasmimport ratify get_blob_content
+func getBlobContent(ptr, size uint32) int32
+
+//go:wasmimport ratify read_response
+func readResponse(ptr uint32)
+
+// callHost calls the host function with the request and reads the response.
+// A negative result -(n+1) means the call failed with an error message of n bytes.
+func callHost(fn func(ptr, size uint32) int32, request []byte) ([]byte, error) {
+	size := fn(bufferPointer(request), uint32(len(request)))
+	if size < 0 {
+		return nil, errors.New(string(readHostResponse(-size - 1)))
+	}
+	return readHostResponse(size), nil
+}
+
+func readHostResponse(size int32) []byte {
+	response := make([]byte, size)
+	readResponse(bufferPointer(response))
+	return response
+}
+
+func bufferPointer(buf []byte) uint32 {
+	if len(buf) == 0 {
+		return 0
+	}
+	return uint32(uintptr(unsafe.Pointer(&buf[0])))
+}
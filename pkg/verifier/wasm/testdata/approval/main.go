@@ -0,0 +1,45 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// approval is a verifier module succeeding if the first blob of the artifact
+// equals the "approval" config value.
+package main
+
+import (
+	"fmt"
+
+	"github.com/deislabs/ratify/pkg/verifier/wasm/guest"
+)
+
+func main() {
+	guest.Main(func(args *guest.Args, store guest.Store) (*guest.Result, error) {
+		manifest, err := store.GetReferenceManifest(args.ReferenceDescriptor)
+		if err != nil {
+			return nil, err
+		}
+		if len(manifest.Blobs) == 0 {
+			return nil, fmt.Errorf("artifact %s has no blobs", args.ReferenceDescriptor.Digest)
+		}
+		content, err := store.GetBlobContent(manifest.Blobs[0].Digest)
+		if err != nil {
+			return nil, err
+		}
+		return &guest.Result{
+			Name:      fmt.Sprintf("%s", args.Config["name"]),
+			IsSuccess: string(content) == args.Config["approval"],
+			Message:   fmt.Sprintf("approval of %s is %s", args.Subject, content),
+		}, nil
+	})
+}
@@ -0,0 +1,332 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wasm runs verifiers distributed as WebAssembly modules. A module is
+// a WASI command that speaks the same protocol as verifier plugin executables:
+// it reads the command, subject and version from the environment and the
+// verifier config from stdin, and writes the verifier result to stdout.
+// Modules run sandboxed without file system or network access, so referrer
+// store operations are provided by host functions of the "ratify" module.
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	re "github.com/deislabs/ratify/errors"
+	"github.com/deislabs/ratify/pkg/common"
+	pluginCommon "github.com/deislabs/ratify/pkg/common/plugin"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/config"
+	vp "github.com/deislabs/ratify/pkg/verifier/plugin"
+	"github.com/deislabs/ratify/pkg/verifier/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// Extension is the file extension of verifier modules in the plugin directories.
+	Extension = ".wasm"
+	// HostModuleName is the name of the module providing the host functions.
+	HostModuleName = "ratify"
+
+	// memoryLimitPages limits the memory of a module instance to 256 MiB.
+	memoryLimitPages = 4096
+)
+
+var (
+	sharedRuntime    wazero.Runtime
+	sharedRuntimeErr error
+	sharedRuntimeMu  sync.Once
+)
+
+// WasmVerifier is a verifier implemented by a WebAssembly module. The module
+// is compiled once when the verifier is created and instantiated for every
+// verification, so that verifications do not share state.
+type WasmVerifier struct { //nolint:revive // ignore linter to have unique type name
+	name             string
+	verifierType     string
+	artifactTypes    []string
+	nestedReferences []string
+	version          string
+	rawConfig        config.VerifierConfig
+	module           wazero.CompiledModule
+}
+
+// call is the state of a verification used by the host functions.
+type call struct {
+	store    referrerstore.ReferrerStore
+	subject  common.Reference
+	response []byte
+}
+
+type callKey struct{}
+
+// FindModule returns the path of the module of the verifier type in the given
+// directories, or an empty string if there is none.
+func FindModule(verifierType string, paths []string) string {
+	for _, dir := range paths {
+		path := filepath.Join(dir, verifierType+Extension)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+	}
+	return ""
+}
+
+// NewVerifier compiles the module at the given path and creates a verifier
+// from the given configuration.
+func NewVerifier(version string, verifierConfig config.VerifierConfig, modulePath string) (verifier.ReferenceVerifier, error) {
+	verifierName, ok := verifierConfig[types.Name]
+	if !ok {
+		return nil, re.ErrorCodeConfigInvalid.WithDetail(fmt.Sprintf("failed to find verifier name in the verifier config with key: %s", types.Name))
+	}
+	verifierType := ""
+	if _, ok := verifierConfig[types.Type]; ok {
+		verifierType = fmt.Sprintf("%s", verifierConfig[types.Type])
+	}
+
+	var nestedReferences []string
+	if vs, ok := verifierConfig[types.NestedReferences]; ok {
+		nestedReferences = strings.Split(fmt.Sprintf("%s", vs), ",")
+	}
+
+	var artifactTypes []string
+	if at, ok := verifierConfig[types.ArtifactTypes]; ok {
+		artifactTypes = strings.Split(fmt.Sprintf("%s", at), ",")
+	}
+	if len(artifactTypes) == 0 {
+		artifactTypes = append(artifactTypes, "*")
+	}
+
+	wasm, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, re.ErrorCodePluginNotFound.NewError(re.Verifier, fmt.Sprintf("%s", verifierName), re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+	module, err := compile(context.Background(), wasm)
+	if err != nil {
+		return nil, re.ErrorCodePluginInitFailure.NewError(re.Verifier, fmt.Sprintf("%s", verifierName), re.EmptyLink, err, fmt.Sprintf("failed to compile WebAssembly module %s", modulePath), re.HideStackTrace)
+	}
+	logrus.Infof("loaded WebAssembly verifier %s from %s", verifierName, modulePath)
+
+	return &WasmVerifier{
+		name:             fmt.Sprintf("%s", verifierName),
+		verifierType:     verifierType,
+		version:          version,
+		rawConfig:        verifierConfig,
+		artifactTypes:    artifactTypes,
+		nestedReferences: nestedReferences,
+		module:           module,
+	}, nil
+}
+
+func (v *WasmVerifier) Name() string {
+	return v.name
+}
+
+func (v *WasmVerifier) Type() string {
+	return v.verifierType
+}
+
+func (v *WasmVerifier) CanVerify(_ context.Context, referenceDescriptor ocispecs.ReferenceDescriptor) bool {
+	for _, at := range v.artifactTypes {
+		if at == "*" || at == referenceDescriptor.ArtifactType {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *WasmVerifier) Verify(ctx context.Context,
+	subjectReference common.Reference,
+	referenceDescriptor ocispecs.ReferenceDescriptor,
+	store referrerstore.ReferrerStore) (verifier.VerifierResult, error) {
+	// the store config is left out as the module accesses the store through the host
+	input, err := json.Marshal(config.PluginInputConfig{
+		Config:       v.rawConfig,
+		ReferencDesc: referenceDescriptor,
+	})
+	if err != nil {
+		return verifier.VerifierResult{IsSuccess: false}, re.ErrorCodeConfigInvalid.NewError(re.Verifier, v.name, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(v.name).
+		WithEnv(vp.CommandEnvKey, vp.VerifyCommand).
+		WithEnv(vp.SubjectEnvKey, subjectReference.String()).
+		WithEnv(vp.VersionEnvKey, v.version).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+	if verificationTime, ok := verifier.VerificationTime(ctx); ok {
+		moduleConfig = moduleConfig.WithEnv(vp.TimeEnvKey, verificationTime.Format(time.RFC3339Nano))
+	}
+
+	ctx = context.WithValue(ctx, callKey{}, &call{store: store, subject: subjectReference})
+	module, err := sharedRuntime.InstantiateModule(ctx, v.module, moduleConfig)
+	if module != nil {
+		_ = module.Close(ctx)
+	}
+	if stderr.Len() > 0 {
+		logrus.Debugf("verifier %s wrote to stderr: %s", v.name, stderr.String())
+	}
+	if err != nil {
+		var exitErr *sys.ExitError
+		if errors.As(err, &exitErr) {
+			err = &pluginCommon.Error{Msg: fmt.Sprintf("plugin failed with exit code %d, msg from stError '%v', msg from stdOut '%v'", exitErr.ExitCode(), stderr.String(), stdout.String())}
+		}
+		return verifier.VerifierResult{IsSuccess: false}, re.ErrorCodeVerifyPluginFailure.NewError(re.Verifier, v.name, re.EmptyLink, err, nil, re.HideStackTrace)
+	}
+
+	result, err := types.GetVerifierResult(stdout.Bytes())
+	if err != nil {
+		return verifier.VerifierResult{IsSuccess: false}, re.ErrorCodeVerifyPluginFailure.NewError(re.Verifier, v.name, re.EmptyLink, err, "failed to parse verifier result", re.HideStackTrace)
+	}
+	return *result, nil
+}
+
+func (v *WasmVerifier) GetNestedReferences() []string {
+	return v.nestedReferences
+}
+
+// compile compiles the module with the shared runtime, which is created on
+// first use together with WASI and the host module.
+func compile(ctx context.Context, wasm []byte) (wazero.CompiledModule, error) {
+	sharedRuntimeMu.Do(func() {
+		runtimeConfig := wazero.NewRuntimeConfig().
+			WithCloseOnContextDone(true).
+			WithMemoryLimitPages(memoryLimitPages)
+		r := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+			sharedRuntimeErr = fmt.Errorf("failed to instantiate WASI: %w", err)
+			return
+		}
+		if _, err := r.NewHostModuleBuilder(HostModuleName).
+			NewFunctionBuilder().WithFunc(getReferenceManifest).Export("get_reference_manifest").
+			NewFunctionBuilder().WithFunc(getBlobContent).Export("get_blob_content").
+			NewFunctionBuilder().WithFunc(readResponse).Export("read_response").
+			Instantiate(ctx); err != nil {
+			sharedRuntimeErr = fmt.Errorf("failed to instantiate host module: %w", err)
+			return
+		}
+		sharedRuntime = r
+	})
+	if sharedRuntimeErr != nil {
+		return nil, sharedRuntimeErr
+	}
+	return sharedRuntime.CompileModule(ctx, wasm)
+}
+
+// getReferenceManifest reads the JSON encoded reference descriptor from the
+// module memory and prepares the JSON encoded reference manifest as response.
+// It returns the size of the response, or -(n+1) with an error message of n
+// bytes as response if the call failed.
+func getReferenceManifest(ctx context.Context, m api.Module, ptr, size uint32) int32 {
+	c, input, ok := callInput(ctx, m, ptr, size)
+	if !ok {
+		return -1
+	}
+	var desc ocispecs.ReferenceDescriptor
+	if err := json.Unmarshal(input, &desc); err != nil {
+		return c.fail(fmt.Errorf("failed to parse reference descriptor: %w", err))
+	}
+	manifest, err := c.store.GetReferenceManifest(ctx, c.subject, desc)
+	if err != nil {
+		return c.fail(err)
+	}
+	response, err := json.Marshal(manifest)
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.respond(response)
+}
+
+// getBlobContent reads the blob digest from the module memory and prepares the
+// blob content as response. It returns the size of the response, or -(n+1)
+// with an error message of n bytes as response if the call failed.
+func getBlobContent(ctx context.Context, m api.Module, ptr, size uint32) int32 {
+	c, input, ok := callInput(ctx, m, ptr, size)
+	if !ok {
+		return -1
+	}
+	d, err := digest.Parse(string(input))
+	if err != nil {
+		return c.fail(err)
+	}
+	content, err := c.store.GetBlobContent(ctx, c.subject, d)
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.respond(content)
+}
+
+// readResponse copies the response of the last call to the module memory.
+func readResponse(ctx context.Context, m api.Module, ptr uint32) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return
+	}
+	if !m.Memory().Write(ptr, c.response) {
+		panic(fmt.Errorf("response of %d bytes out of module memory range at %d", len(c.response), ptr))
+	}
+	c.response = nil
+}
+
+func callInput(ctx context.Context, m api.Module, ptr, size uint32) (*call, []byte, bool) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return nil, nil, false
+	}
+	input, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		c.fail(fmt.Errorf("input of %d bytes out of module memory range at %d", size, ptr))
+		return nil, nil, false
+	}
+	return c, input, true
+}
+
+func (c *call) respond(response []byte) int32 {
+	if len(response) > memoryLimitPages*65536 {
+		return c.fail(fmt.Errorf("response of %d bytes exceeds the module memory limit", len(response)))
+	}
+	c.response = response
+	return int32(len(response))
+}
+
+func (c *call) fail(err error) int32 {
+	c.response = []byte(err.Error())
+	return -int32(len(c.response)) - 1
+}
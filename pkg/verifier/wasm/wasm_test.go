@@ -0,0 +1,138 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/deislabs/ratify/pkg/verifier/config"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const approvalArtifactType = "application/vnd.example.approval"
+
+// buildModule compiles the approval verifier in testdata to a WebAssembly module
+func buildModule(t *testing.T) string {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain is required to build the test module")
+	}
+	dir := t.TempDir()
+	modulePath := filepath.Join(dir, "approval"+Extension)
+	cmd := exec.Command(goBin, "build", "-o", modulePath, "./testdata/approval")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build test module: %v: %s", err, output)
+	}
+	return modulePath
+}
+
+func TestWasmVerifier_Verify(t *testing.T) {
+	modulePath := buildModule(t)
+	if found := FindModule("approval", []string{t.TempDir(), filepath.Dir(modulePath)}); found != modulePath {
+		t.Fatalf("expected to find module %s, got %s", modulePath, found)
+	}
+
+	v, err := NewVerifier("1.0.0", config.VerifierConfig{
+		"name":          "approval",
+		"artifactTypes": approvalArtifactType,
+		"approval":      "approved",
+	}, modulePath)
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	if !v.CanVerify(context.Background(), ocispecs.ReferenceDescriptor{ArtifactType: approvalArtifactType}) {
+		t.Fatalf("expected verifier to verify %s", approvalArtifactType)
+	}
+	if v.CanVerify(context.Background(), ocispecs.ReferenceDescriptor{ArtifactType: "application/spdx+json"}) {
+		t.Fatalf("expected verifier not to verify other artifact types")
+	}
+
+	subject := common.Reference{Original: "localhost/image@sha256:abc", Path: "localhost/image", Digest: "sha256:abc"}
+	testCases := []struct {
+		name      string
+		blob      string
+		isSuccess bool
+	}{
+		{name: "approved", blob: "approved", isSuccess: true},
+		{name: "rejected", blob: "rejected", isSuccess: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			artifactDigest := digest.FromString("artifact-" + tc.name)
+			blobDigest := digest.FromString(tc.blob)
+			store := &mocks.MemoryTestStore{
+				Manifests: map[digest.Digest]ocispecs.ReferenceManifest{
+					artifactDigest: {
+						MediaType:    oci.MediaTypeImageManifest,
+						ArtifactType: approvalArtifactType,
+						Blobs:        []oci.Descriptor{{MediaType: "text/plain", Digest: blobDigest}},
+					},
+				},
+				Blobs: map[digest.Digest][]byte{blobDigest: []byte(tc.blob)},
+			}
+			desc := ocispecs.ReferenceDescriptor{Descriptor: oci.Descriptor{Digest: artifactDigest}, ArtifactType: approvalArtifactType}
+
+			result, err := v.Verify(context.Background(), subject, desc, store)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsSuccess != tc.isSuccess || result.Name != "approval" {
+				t.Fatalf("unexpected result %+v", result)
+			}
+			if !strings.Contains(result.Message, subject.Original) {
+				t.Fatalf("expected message to contain the subject, got %s", result.Message)
+			}
+		})
+	}
+
+	// errors of host functions are returned to the module, which fails the verification
+	artifactDigest := digest.FromString("artifact-invalid")
+	store := &mocks.MemoryTestStore{
+		Manifests: map[digest.Digest]ocispecs.ReferenceManifest{
+			artifactDigest: {Blobs: []oci.Descriptor{{MediaType: "text/plain", Digest: "invalid"}}},
+		},
+	}
+	desc := ocispecs.ReferenceDescriptor{Descriptor: oci.Descriptor{Digest: artifactDigest}, ArtifactType: approvalArtifactType}
+	if _, err := v.Verify(context.Background(), subject, desc, store); err == nil || !strings.Contains(err.Error(), digest.ErrDigestInvalidFormat.Error()) {
+		t.Fatalf("expected invalid digest error, got %v", err)
+	}
+}
+
+func TestNewVerifier_InvalidModule(t *testing.T) {
+	modulePath := filepath.Join(t.TempDir(), "invalid"+Extension)
+	if err := os.WriteFile(modulePath, []byte("not wasm"), 0o600); err != nil {
+		t.Fatalf("failed to write module: %v", err)
+	}
+	if _, err := NewVerifier("1.0.0", config.VerifierConfig{"name": "invalid"}, modulePath); err == nil {
+		t.Fatalf("expected error compiling invalid module")
+	}
+	if _, err := NewVerifier("1.0.0", config.VerifierConfig{"name": "missing"}, filepath.Join(t.TempDir(), "missing"+Extension)); err == nil {
+		t.Fatalf("expected error for missing module")
+	}
+	if _, err := NewVerifier("1.0.0", config.VerifierConfig{}, modulePath); err == nil {
+		t.Fatalf("expected error for missing name")
+	}
+}
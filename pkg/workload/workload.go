@@ -0,0 +1,255 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// ContainerTypeContainer is a regular container of the pod.
+	ContainerTypeContainer = "container"
+	// ContainerTypeInit is an init container of the pod.
+	ContainerTypeInit = "initContainer"
+	// ContainerTypeEphemeral is an ephemeral container of the pod.
+	ContainerTypeEphemeral = "ephemeralContainer"
+
+	// manifestBufferSize is the number of bytes read to detect whether a
+	// manifest is YAML or JSON.
+	manifestBufferSize = 4096
+)
+
+// Container is a container of a workload and the image it runs.
+type Container struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Image string `json:"image"`
+}
+
+// Workload is a Kubernetes resource running pods, e.g. a Deployment.
+type Workload struct {
+	Kind       string      `json:"kind"`
+	Name       string      `json:"name"`
+	Namespace  string      `json:"namespace,omitempty"`
+	Containers []Container `json:"containers"`
+}
+
+// ContainerResult is the verification result of the image of a container.
+type ContainerResult struct {
+	Container
+	IsSuccess bool `json:"isSuccess"`
+	// Error is set if the image could not be verified.
+	Error string `json:"error,omitempty"`
+	// Result is the verification report of the image.
+	Result interface{} `json:"result,omitempty"`
+}
+
+// WorkloadResult is the verification result of the containers of a workload.
+type WorkloadResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// IsSuccess is true if the images of all containers passed verification.
+	IsSuccess  bool              `json:"isSuccess"`
+	Containers []ContainerResult `json:"containers"`
+}
+
+// Summary is the aggregated verification result of workloads.
+type Summary struct {
+	// IsSuccess is true if all workloads passed verification.
+	IsSuccess bool             `json:"isSuccess"`
+	Workloads []WorkloadResult `json:"workloads"`
+}
+
+// VerifyFunc verifies the image of a container of a workload.
+type VerifyFunc func(ctx context.Context, workload Workload, image string) (isSuccess bool, result interface{}, err error)
+
+// manifest is a resource of a workload manifest.
+type manifest struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        metav1.ObjectMeta `json:"metadata"`
+	Spec            json.RawMessage   `json:"spec"`
+}
+
+// podTemplateSpec is the spec of workloads with a pod template.
+type podTemplateSpec struct {
+	Template corev1.PodTemplateSpec `json:"template"`
+}
+
+// cronJobSpec is the spec of a CronJob.
+type cronJobSpec struct {
+	JobTemplate struct {
+		Spec podTemplateSpec `json:"spec"`
+	} `json:"jobTemplate"`
+}
+
+// Parse returns the workloads of a YAML or JSON manifest, which may contain
+// multiple YAML documents.
+func Parse(content []byte) ([]Workload, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), manifestBufferSize)
+	workloads := make([]Workload, 0)
+	for {
+		var m manifest
+		if err := decoder.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode workload manifest: %w", err)
+		}
+		if m.Kind == "" {
+			// empty YAML document
+			continue
+		}
+		workload, err := parseManifest(m)
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, workload)
+	}
+	if len(workloads) == 0 {
+		return nil, fmt.Errorf("no workloads found in manifest")
+	}
+	return workloads, nil
+}
+
+// ParseObject returns the workload of a single JSON encoded resource, e.g. the
+// object of an admission request. Resources without a namespace are assigned
+// the given namespace.
+func ParseObject(raw []byte, namespace string) (Workload, error) {
+	var m manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return Workload{}, fmt.Errorf("failed to decode workload: %w", err)
+	}
+	if m.Metadata.Namespace == "" {
+		m.Metadata.Namespace = namespace
+	}
+	return parseManifest(m)
+}
+
+// IsSupportedKind returns true if the pod spec of resources of the kind can be
+// parsed.
+func IsSupportedKind(kind string) bool {
+	switch kind {
+	case "Pod", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job", "CronJob":
+		return true
+	}
+	return false
+}
+
+// parseManifest returns the containers of the pod spec of the resource.
+func parseManifest(m manifest) (Workload, error) {
+	var podSpec corev1.PodSpec
+	switch m.Kind {
+	case "Pod":
+		if err := json.Unmarshal(m.Spec, &podSpec); err != nil {
+			return Workload{}, fmt.Errorf("failed to decode spec of %s %s: %w", m.Kind, m.Metadata.Name, err)
+		}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		var spec podTemplateSpec
+		if err := json.Unmarshal(m.Spec, &spec); err != nil {
+			return Workload{}, fmt.Errorf("failed to decode spec of %s %s: %w", m.Kind, m.Metadata.Name, err)
+		}
+		podSpec = spec.Template.Spec
+	case "CronJob":
+		var spec cronJobSpec
+		if err := json.Unmarshal(m.Spec, &spec); err != nil {
+			return Workload{}, fmt.Errorf("failed to decode spec of %s %s: %w", m.Kind, m.Metadata.Name, err)
+		}
+		podSpec = spec.JobTemplate.Spec.Template.Spec
+	default:
+		return Workload{}, fmt.Errorf("unsupported workload kind %s", m.Kind)
+	}
+
+	workload := Workload{
+		Kind:       m.Kind,
+		Name:       m.Metadata.Name,
+		Namespace:  m.Metadata.Namespace,
+		Containers: make([]Container, 0),
+	}
+	for _, c := range podSpec.InitContainers {
+		workload.Containers = append(workload.Containers, Container{Name: c.Name, Type: ContainerTypeInit, Image: c.Image})
+	}
+	for _, c := range podSpec.Containers {
+		workload.Containers = append(workload.Containers, Container{Name: c.Name, Type: ContainerTypeContainer, Image: c.Image})
+	}
+	for _, c := range podSpec.EphemeralContainers {
+		workload.Containers = append(workload.Containers, Container{Name: c.Name, Type: ContainerTypeEphemeral, Image: c.Image})
+	}
+	if len(workload.Containers) == 0 {
+		return Workload{}, fmt.Errorf("%s %s has no containers", m.Kind, m.Metadata.Name)
+	}
+	return workload, nil
+}
+
+// Verify verifies the images of all containers of the workloads and
+// aggregates the results. Images shared by containers of a workload are
+// verified once.
+func Verify(ctx context.Context, workloads []Workload, verify VerifyFunc) Summary {
+	summary := Summary{
+		IsSuccess: true,
+		Workloads: make([]WorkloadResult, len(workloads)),
+	}
+	wg := sync.WaitGroup{}
+	for i, workload := range workloads {
+		results := make(map[string]*ContainerResult)
+		for _, container := range workload.Containers {
+			if _, ok := results[container.Image]; ok {
+				continue
+			}
+			result := &ContainerResult{}
+			results[container.Image] = result
+			wg.Add(1)
+			go func(workload Workload, image string) {
+				defer wg.Done()
+				isSuccess, report, err := verify(ctx, workload, image)
+				if err != nil {
+					result.Error = err.Error()
+					return
+				}
+				result.IsSuccess = isSuccess
+				result.Result = report
+			}(workload, container.Image)
+		}
+		wg.Wait()
+
+		workloadResult := WorkloadResult{
+			Kind:       workload.Kind,
+			Name:       workload.Name,
+			Namespace:  workload.Namespace,
+			IsSuccess:  true,
+			Containers: make([]ContainerResult, 0, len(workload.Containers)),
+		}
+		for _, container := range workload.Containers {
+			result := *results[container.Image]
+			result.Container = container
+			workloadResult.Containers = append(workloadResult.Containers, result)
+			workloadResult.IsSuccess = workloadResult.IsSuccess && result.IsSuccess
+		}
+		summary.Workloads[i] = workloadResult
+		summary.IsSuccess = summary.IsSuccess && workloadResult.IsSuccess
+	}
+	return summary
+}
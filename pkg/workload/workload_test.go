@@ -0,0 +1,163 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const testManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: team
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: registry.io/init:v1
+      containers:
+        - name: app
+          image: registry.io/app:v1
+        - name: app-debug
+          image: registry.io/app:v1
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: cleanup
+              image: registry.io/cleanup:v1
+`
+
+func TestParse(t *testing.T) {
+	workloads, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Workload{
+		{
+			Kind:      "Deployment",
+			Name:      "app",
+			Namespace: "team",
+			Containers: []Container{
+				{Name: "init", Type: ContainerTypeInit, Image: "registry.io/init:v1"},
+				{Name: "app", Type: ContainerTypeContainer, Image: "registry.io/app:v1"},
+				{Name: "app-debug", Type: ContainerTypeContainer, Image: "registry.io/app:v1"},
+			},
+		},
+		{
+			Kind:       "CronJob",
+			Name:       "cleanup",
+			Containers: []Container{{Name: "cleanup", Type: ContainerTypeContainer, Image: "registry.io/cleanup:v1"}},
+		},
+	}
+	if !reflect.DeepEqual(workloads, expected) {
+		t.Fatalf("expected workloads %+v, got %+v", expected, workloads)
+	}
+
+	workloads, err = Parse([]byte(`{"kind":"Pod","metadata":{"name":"pod"},"spec":{"containers":[{"name":"app","image":"registry.io/app:v1"}]}}`))
+	if err != nil || len(workloads) != 1 || workloads[0].Containers[0].Image != "registry.io/app:v1" {
+		t.Fatalf("expected JSON pod manifest to be parsed, got %+v, err: %v", workloads, err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, manifest := range []string{
+		"",
+		"kind: ConfigMap\nmetadata:\n  name: config\n",
+		"kind: Deployment\nmetadata:\n  name: app\nspec: {}\n",
+		"kind: [",
+	} {
+		if _, err := Parse([]byte(manifest)); err == nil {
+			t.Fatalf("expected error parsing manifest %q", manifest)
+		}
+	}
+}
+
+func TestParseObject(t *testing.T) {
+	pod := `{"apiVersion":"v1","kind":"Pod","metadata":{"generateName":"app-"},"spec":{"containers":[{"name":"app","image":"registry.io/app:v1"}]}}`
+	workload, err := ParseObject([]byte(pod), "team")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Workload{
+		Kind:       "Pod",
+		Namespace:  "team",
+		Containers: []Container{{Name: "app", Type: ContainerTypeContainer, Image: "registry.io/app:v1"}},
+	}
+	if !reflect.DeepEqual(workload, expected) {
+		t.Fatalf("expected workload %+v, got %+v", expected, workload)
+	}
+
+	if _, err := ParseObject([]byte(`{"kind":"ConfigMap"}`), "team"); err == nil {
+		t.Fatalf("expected error parsing unsupported kind")
+	}
+}
+
+func TestIsSupportedKind(t *testing.T) {
+	for kind, expected := range map[string]bool{
+		"Pod":        true,
+		"Deployment": true,
+		"CronJob":    true,
+		"ConfigMap":  false,
+		"":           false,
+	} {
+		if IsSupportedKind(kind) != expected {
+			t.Fatalf("expected kind %q supported to be %v", kind, expected)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	workloads, err := Parse([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verified := make(chan string, 10)
+	summary := Verify(context.Background(), workloads, func(_ context.Context, _ Workload, image string) (bool, interface{}, error) {
+		verified <- image
+		if image == "registry.io/cleanup:v1" {
+			return false, nil, fmt.Errorf("failed to resolve %s", image)
+		}
+		return true, image, nil
+	})
+	close(verified)
+
+	if len(verified) != 3 {
+		t.Fatalf("expected images shared by containers to be verified once, got %d verifications", len(verified))
+	}
+	if summary.IsSuccess || !summary.Workloads[0].IsSuccess || summary.Workloads[1].IsSuccess {
+		t.Fatalf("expected only the second workload to fail, got %+v", summary)
+	}
+	debug := summary.Workloads[0].Containers[2]
+	if debug.Name != "app-debug" || !debug.IsSuccess || debug.Result != "registry.io/app:v1" {
+		t.Fatalf("unexpected result of container sharing an image: %+v", debug)
+	}
+	if summary.Workloads[1].Containers[0].Error == "" {
+		t.Fatalf("expected error of failed verification to be reported")
+	}
+}
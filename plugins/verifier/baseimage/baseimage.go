@@ -0,0 +1,323 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+
+	// This import is required to utilize the oras built-in referrer store
+	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/attestation"
+	"github.com/deislabs/ratify/pkg/verifier/plugin/skel"
+	"github.com/opencontainers/go-digest"
+)
+
+// PluginConfig describes the configuration of the base image verifier
+type PluginConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// AllowedRepositories lists the repositories base images may come from.
+	// An entry ending with "/*" allows every repository under that prefix.
+	AllowedRepositories []string `json:"allowedRepositories,omitempty"`
+	// AllowedDigests lists base image digests that are allowed regardless of repository.
+	AllowedDigests []string `json:"allowedDigests,omitempty"`
+	// AllowNoBaseImages passes subjects whose provenance lists no base image,
+	// e.g. images built from scratch. They fail by default.
+	AllowNoBaseImages bool `json:"allowNoBaseImages,omitempty"`
+}
+
+type PluginInputConfig struct {
+	Config PluginConfig `json:"config"`
+}
+
+// BaseImage is a base image detected in the provenance of the subject
+type BaseImage struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	Allowed    bool   `json:"allowed"`
+}
+
+// provenancePredicate is the subset of SLSA provenance v0.2 and v1 predicates
+// listing the materials a build consumed
+type provenancePredicate struct {
+	Materials       []resourceDescriptor `json:"materials"`
+	BuildDefinition struct {
+		ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+}
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+const (
+	BaseImages        string = "baseImages"
+	dockerPurlPrefix  string = "pkg:docker/"
+	defaultRegistry   string = "docker.io"
+	officialNamespace string = "library"
+)
+
+func main() {
+	skel.PluginMain("baseimage", "1.0.0", VerifyReference, []string{"1.0.0"})
+}
+
+func parseInput(stdin []byte) (*PluginConfig, error) {
+	conf := PluginInputConfig{}
+
+	if err := json.Unmarshal(stdin, &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse stdin for the input: %w", err)
+	}
+
+	return &conf.Config, nil
+}
+
+func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (*verifier.VerifierResult, error) {
+	input, err := parseInput(args.StdinData)
+	if err != nil {
+		return nil, err
+	}
+	verifierType := ""
+	if input.Type != "" {
+		verifierType = input.Type
+	}
+
+	if len(input.AllowedRepositories) == 0 && len(input.AllowedDigests) == 0 {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   "base image validation failed: no allowedRepositories or allowedDigests configured",
+		}, nil
+	}
+
+	ctx := context.Background()
+	referenceManifest, err := referrerStore.GetReferenceManifest(ctx, subjectReference, referenceDescriptor)
+	if err != nil {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   fmt.Sprintf("Error fetching reference manifest for subject: %s reference descriptor: %v, err: %v", subjectReference, referenceDescriptor.Descriptor, err),
+		}, nil
+	}
+
+	if len(referenceManifest.Blobs) == 0 {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   fmt.Sprintf("base image validation failed: no layers found in manifest for referrer %s@%s", subjectReference.Path, referenceDescriptor.Digest.String()),
+		}, nil
+	}
+
+	subjectDigest := subjectReference.Digest
+	if subjectDigest == "" {
+		subjectDesc, err := referrerStore.GetSubjectDescriptor(ctx, subjectReference)
+		if err != nil {
+			return &verifier.VerifierResult{
+				Name:      input.Name,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("failed to resolve subject: %s, err: %v", subjectReference, err),
+			}, nil
+		}
+		subjectDigest = subjectDesc.Digest
+	}
+
+	var baseImages []BaseImage
+	for _, blobDesc := range referenceManifest.Blobs {
+		refBlob, err := referrerStore.GetBlobContent(ctx, subjectReference, blobDesc.Digest)
+		if err != nil {
+			return &verifier.VerifierResult{
+				Name:      input.Name,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("Error fetching blob for subject: %s digest: %s, err: %v", subjectReference, blobDesc.Digest, err),
+			}, nil
+		}
+
+		images, err := extractBaseImages(refBlob, subjectDigest)
+		if err != nil {
+			return &verifier.VerifierResult{
+				Name:      input.Name,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("base image validation failed for digest: %s, err: %v", blobDesc.Digest, err),
+			}, nil
+		}
+		baseImages = append(baseImages, images...)
+	}
+
+	return evaluateBaseImages(input, verifierType, baseImages), nil
+}
+
+// evaluateBaseImages marks each base image as allowed or not and returns the verifier result
+func evaluateBaseImages(input *PluginConfig, verifierType string, baseImages []BaseImage) *verifier.VerifierResult {
+	allowedDigests := map[string]struct{}{}
+	for _, d := range input.AllowedDigests {
+		allowedDigests[strings.ToLower(d)] = struct{}{}
+	}
+
+	if len(baseImages) == 0 && !input.AllowNoBaseImages {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   "base image validation failed: no base images found in the provenance of the subject",
+			Extensions: map[string]interface{}{
+				BaseImages: baseImages,
+			},
+		}
+	}
+
+	isSuccess := true
+	for i := range baseImages {
+		_, digestAllowed := allowedDigests[strings.ToLower(baseImages[i].Digest)]
+		baseImages[i].Allowed = digestAllowed || repositoryAllowed(baseImages[i].Repository, input.AllowedRepositories)
+		if !baseImages[i].Allowed {
+			isSuccess = false
+		}
+	}
+
+	message := "base image validation succeeded"
+	if !isSuccess {
+		message = "base image validation failed. Please review extensions data for base images that are not allowed."
+	}
+	return &verifier.VerifierResult{
+		Name:      input.Name,
+		Type:      verifierType,
+		IsSuccess: isSuccess,
+		Message:   message,
+		Extensions: map[string]interface{}{
+			BaseImages: baseImages,
+		},
+	}
+}
+
+// repositoryAllowed returns true if the repository equals an allowed repository
+// or is nested under an allowed prefix ending with "/*"
+func repositoryAllowed(repository string, allowedRepositories []string) bool {
+	for _, allowed := range allowedRepositories {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(repository, strings.ToLower(prefix)+"/") {
+				return true
+			}
+			continue
+		}
+		if repository == normalizeRepository(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractBaseImages returns the container images listed as materials of a
+// SLSA provenance attestation of the subject with the given digest
+func extractBaseImages(blob []byte, subjectDigest digest.Digest) ([]BaseImage, error) {
+	statement, ok, err := attestation.ParseStatement(blob)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("blob is not an in-toto statement")
+	}
+	// an attestation copied from another image must not pass for the subject
+	if err := statement.VerifySubject(subjectDigest); err != nil {
+		return nil, err
+	}
+
+	var predicate provenancePredicate
+	if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+		return nil, fmt.Errorf("failed to parse provenance predicate: %w", err)
+	}
+
+	var baseImages []BaseImage
+	materials := make([]resourceDescriptor, 0, len(predicate.Materials)+len(predicate.BuildDefinition.ResolvedDependencies))
+	materials = append(materials, predicate.Materials...)
+	materials = append(materials, predicate.BuildDefinition.ResolvedDependencies...)
+	for _, material := range materials {
+		if !strings.HasPrefix(material.URI, dockerPurlPrefix) {
+			continue
+		}
+		baseImage, err := parseDockerPurl(material.URI)
+		if err != nil {
+			return nil, err
+		}
+		if sha, ok := material.Digest["sha256"]; ok {
+			baseImage.Digest = "sha256:" + sha
+		}
+		baseImages = append(baseImages, baseImage)
+	}
+	return baseImages, nil
+}
+
+// parseDockerPurl parses a docker package URL such as
+// pkg:docker/alpine@3.18?platform=linux%2Famd64 into a base image
+func parseDockerPurl(purl string) (BaseImage, error) {
+	name := strings.TrimPrefix(purl, dockerPurlPrefix)
+	name, _, _ = strings.Cut(name, "?")
+	name, _, _ = strings.Cut(name, "#")
+	name, version, _ := strings.Cut(name, "@")
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return BaseImage{}, fmt.Errorf("invalid package url %s: %w", purl, err)
+	}
+	version, err = url.PathUnescape(version)
+	if err != nil {
+		return BaseImage{}, fmt.Errorf("invalid package url %s: %w", purl, err)
+	}
+	if name == "" {
+		return BaseImage{}, fmt.Errorf("invalid package url %s: missing name", purl)
+	}
+
+	baseImage := BaseImage{Repository: normalizeRepository(name)}
+	if strings.Contains(version, ":") {
+		baseImage.Digest = version
+	} else {
+		baseImage.Tag = version
+	}
+	return baseImage, nil
+}
+
+// normalizeRepository expands short docker hub names, e.g. alpine becomes docker.io/library/alpine
+func normalizeRepository(repository string) string {
+	repository = strings.ToLower(repository)
+	host, rest, found := strings.Cut(repository, "/")
+	if !found {
+		return defaultRegistry + "/" + officialNamespace + "/" + repository
+	}
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return defaultRegistry + "/" + repository
+	}
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		host = defaultRegistry
+	}
+	if host == defaultRegistry && !strings.Contains(rest, "/") {
+		return host + "/" + officialNamespace + "/" + rest
+	}
+	return host + "/" + rest
+}
@@ -0,0 +1,178 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	alpineDigest  = "sha256:eece025e432126ce23f223450a0326fbebde39cdf496a85d8c016293fc851978"
+	subjectDigest = digest.Digest("sha256:b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e")
+)
+
+const provenanceV02 = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://slsa.dev/provenance/v0.2",
+	"subject": [{"name": "test", "digest": {"sha256": "b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e"}}],
+	"predicate": {
+		"builder": {"id": ""},
+		"buildType": "https://mobyproject.org/buildkit@v1",
+		"materials": [
+			{"uri": "pkg:docker/alpine@3.18?platform=linux%2Famd64", "digest": {"sha256": "eece025e432126ce23f223450a0326fbebde39cdf496a85d8c016293fc851978"}},
+			{"uri": "pkg:docker/mcr.microsoft.com/cbl-mariner/base/core@2.0?platform=linux%2Famd64", "digest": {"sha256": "1111111111111111111111111111111111111111111111111111111111111111"}},
+			{"uri": "https://github.com/deislabs/ratify.git#refs/heads/main", "digest": {"sha1": "0123456789abcdef0123456789abcdef01234567"}}
+		]
+	}
+}`
+
+const provenanceV1 = `{
+	"_type": "https://in-toto.io/Statement/v1",
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"subject": [{"name": "test", "digest": {"sha256": "b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e"}}],
+	"predicate": {
+		"buildDefinition": {
+			"resolvedDependencies": [
+				{"uri": "pkg:docker/ghcr.io/deislabs/base@v1", "digest": {"sha256": "2222222222222222222222222222222222222222222222222222222222222222"}}
+			]
+		}
+	}
+}`
+
+func TestExtractBaseImages(t *testing.T) {
+	baseImages, err := extractBaseImages([]byte(provenanceV02), subjectDigest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(baseImages) != 2 {
+		t.Fatalf("expected 2 base images, got %d", len(baseImages))
+	}
+	if baseImages[0].Repository != "docker.io/library/alpine" || baseImages[0].Tag != "3.18" || baseImages[0].Digest != alpineDigest {
+		t.Fatalf("unexpected base image: %+v", baseImages[0])
+	}
+	if baseImages[1].Repository != "mcr.microsoft.com/cbl-mariner/base/core" {
+		t.Fatalf("unexpected base image: %+v", baseImages[1])
+	}
+
+	baseImages, err = extractBaseImages([]byte(provenanceV1), subjectDigest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(baseImages) != 1 || baseImages[0].Repository != "ghcr.io/deislabs/base" {
+		t.Fatalf("unexpected base images: %+v", baseImages)
+	}
+
+	if _, err := extractBaseImages([]byte(`{"version": "2.1.0"}`), subjectDigest); err == nil {
+		t.Fatalf("expected error for blob that is not an attestation")
+	}
+
+	if _, err := extractBaseImages([]byte(provenanceV02), digest.FromString("other")); err == nil {
+		t.Fatalf("expected error for attestation of another subject")
+	}
+}
+
+func TestEvaluateBaseImages(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    PluginConfig
+		isSuccess bool
+	}{
+		{
+			name:      "all repositories allowed",
+			config:    PluginConfig{AllowedRepositories: []string{"alpine", "mcr.microsoft.com/*"}},
+			isSuccess: true,
+		},
+		{
+			name:      "repository not allowed",
+			config:    PluginConfig{AllowedRepositories: []string{"docker.io/library/alpine"}},
+			isSuccess: false,
+		},
+		{
+			name:      "allowed by digest",
+			config:    PluginConfig{AllowedRepositories: []string{"mcr.microsoft.com/cbl-mariner/*"}, AllowedDigests: []string{alpineDigest}},
+			isSuccess: true,
+		},
+		{
+			name:      "prefix does not match sibling repository",
+			config:    PluginConfig{AllowedRepositories: []string{"docker.io/library/*", "mcr.microsoft.com/cbl-mariner/base/core/*"}},
+			isSuccess: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseImages, err := extractBaseImages([]byte(provenanceV02), subjectDigest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := evaluateBaseImages(&tt.config, "", baseImages)
+			if result.IsSuccess != tt.isSuccess {
+				t.Fatalf("expected success %t, got %t", tt.isSuccess, result.IsSuccess)
+			}
+			reported := result.Extensions.(map[string]interface{})[BaseImages].([]BaseImage)
+			if len(reported) != 2 {
+				t.Fatalf("expected detected base images in the report, got %+v", reported)
+			}
+		})
+	}
+}
+
+func TestEvaluateBaseImages_NoBaseImages(t *testing.T) {
+	config := PluginConfig{AllowedRepositories: []string{"alpine"}}
+	if result := evaluateBaseImages(&config, "", nil); result.IsSuccess {
+		t.Fatalf("expected provenance without base images to fail, got %+v", result)
+	}
+
+	config.AllowNoBaseImages = true
+	if result := evaluateBaseImages(&config, "", nil); !result.IsSuccess {
+		t.Fatalf("expected provenance without base images to pass if allowed, got %+v", result)
+	}
+}
+
+func TestParseDockerPurl(t *testing.T) {
+	tests := []struct {
+		purl       string
+		repository string
+		tag        string
+		digest     string
+		expectErr  bool
+	}{
+		{purl: "pkg:docker/alpine@3.18", repository: "docker.io/library/alpine", tag: "3.18"},
+		{purl: "pkg:docker/bitnami/nginx@latest?platform=linux%2Farm64", repository: "docker.io/bitnami/nginx", tag: "latest"},
+		{purl: "pkg:docker/localhost:5000/app@sha256%3Aabc", repository: "localhost:5000/app", digest: "sha256:abc"},
+		{purl: "pkg:docker/", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			baseImage, err := parseDockerPurl(tt.purl)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if baseImage.Repository != tt.repository || baseImage.Tag != tt.tag || baseImage.Digest != tt.digest {
+				t.Fatalf("unexpected base image: %+v", baseImage)
+			}
+		})
+	}
+}
@@ -23,14 +23,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/common/dsse"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
 	"github.com/deislabs/ratify/pkg/utils"
 	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/attestation"
 	"github.com/deislabs/ratify/pkg/verifier/plugin/skel"
+	"github.com/deislabs/ratify/pkg/verifier/revocation"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/opencontainers/go-digest"
@@ -42,7 +46,10 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
 )
 
 type PluginConfig struct {
@@ -50,6 +57,25 @@ type PluginConfig struct {
 	Type     string `json:"type"`
 	KeyRef   string `json:"key"`
 	RekorURL string `json:"rekorURL"`
+	// Keys are the PEM encoded public keys of the key management provider of
+	// the verifier, they are passed by Ratify and a signature verified by any
+	// of the keys is valid.
+	Keys []string `json:"keys,omitempty"`
+	// RekorPublicKey is the PEM encoded public key of the Rekor instance
+	// signatures are logged to. The key is fetched from the Sigstore TUF root
+	// if empty.
+	RekorPublicKey string `json:"rekorPublicKey,omitempty"`
+	// Offline verifies the transparency log inclusion of signatures with the
+	// Rekor bundles embedded in their annotations only, Rekor is never
+	// queried and signatures without a bundle are invalid. Keyless
+	// verification reads the Fulcio roots and CT log keys from the files set
+	// by SIGSTORE_ROOT_FILE and SIGSTORE_CT_LOG_PUBLIC_KEY_FILE if set.
+	Offline bool `json:"offline,omitempty"`
+	// Revocation enables the OCSP and CRL revocation checks of the
+	// certificate chains of signatures. The plugin runs once per
+	// verification, so set the cache directory to reuse revocation
+	// responses across verifications.
+	Revocation *revocation.Config `json:"revocation,omitempty"`
 	// config specific to the plugin
 }
 
@@ -67,7 +93,8 @@ type PluginInputConfig struct {
 }
 
 type Extension struct {
-	SignatureExtension []cosignExtension `json:"signatures,omitempty"`
+	SignatureExtension   []cosignExtension            `json:"signatures,omitempty"`
+	AttestationExtension []cosignAttestationExtension `json:"attestations,omitempty"`
 }
 
 type cosignExtension struct {
@@ -77,6 +104,18 @@ type cosignExtension struct {
 	Err             error         `json:"error,omitempty"`
 }
 
+// cosignAttestationExtension is the result of verifying a cosign attestation.
+// The predicate of verified attestations is exposed for policies to enforce
+// conditions on, e.g. the builder of a SLSA provenance.
+type cosignAttestationExtension struct {
+	AttestationDigest digest.Digest   `json:"attestationDigest"`
+	IsSuccess         bool            `json:"isSuccess"`
+	BundleVerified    bool            `json:"bundleVerified"`
+	PredicateType     string          `json:"predicateType,omitempty"`
+	Predicate         json.RawMessage `json:"predicate,omitempty"`
+	Err               error           `json:"error,omitempty"`
+}
+
 func main() {
 	skel.PluginMain("cosign", "1.1.0", VerifyReference, []string{"1.0.0"})
 }
@@ -101,20 +140,27 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 		verifierType = input.Config.Type
 	}
 	keyRef := input.Config.KeyRef
-	rekorURL := input.Config.RekorURL
 	cosignOpts := &cosign.CheckOpts{
 		ClaimVerifier: cosign.SimpleClaimVerifier,
 	}
 
-	var ecdsaVerifier signature.Verifier
+	var keyVerifiers []signature.Verifier
 	var roots *x509.CertPool
 	if keyRef != "" {
-		ecdsaVerifier, err = loadPublicKey(ctx, keyRef)
+		ecdsaVerifier, err := loadPublicKey(ctx, keyRef)
 		if err != nil {
 			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to load public key: %w", err)), nil
 		}
-		cosignOpts.SigVerifier = ecdsaVerifier
-	} else {
+		keyVerifiers = append(keyVerifiers, ecdsaVerifier)
+	}
+	for _, key := range input.Config.Keys {
+		keyVerifier, err := loadPEMPublicKey(key)
+		if err != nil {
+			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to load key management provider public key: %w", err)), nil
+		}
+		keyVerifiers = append(keyVerifiers, keyVerifier)
+	}
+	if len(keyVerifiers) == 0 {
 		roots, err = fulcio.GetRoots()
 		if err != nil {
 			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to get fulcio roots: %w", err)), nil
@@ -125,24 +171,15 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 		}
 	}
 
-	if rekorURL != "" {
-		cosignOpts.RekorClient, err = rekor.NewClient(rekorURL)
-		if err != nil {
-			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to create Rekor client from URL %s: %w", rekorURL, err)), nil
-		}
-		cosignOpts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx)
-		if err != nil {
-			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to set Certificate Transparency Log public keys: %w", err)), nil
-		}
-		// Fetches the Rekor public keys from the Rekor server
-		cosignOpts.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
-		if err != nil {
-			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to set Rekor public keys: %w", err)), nil
+	if err := configureTransparencyLog(ctx, cosignOpts, input.Config, len(keyVerifiers) == 0); err != nil {
+		return errorToVerifyResult(input.Config.Name, verifierType, err), nil
+	}
+
+	var revocationChecker *revocation.Checker
+	if input.Config.Revocation != nil {
+		if revocationChecker, err = revocation.NewChecker(*input.Config.Revocation); err != nil {
+			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("invalid revocation config: %w", err)), nil
 		}
-	} else {
-		// if no rekor url is provided, turn off transparency log verification and ignore SCTs
-		cosignOpts.IgnoreTlog = true
-		cosignOpts.IgnoreSCT = true
 	}
 
 	referenceManifest, err := referrerStore.GetReferenceManifest(ctx, subjectReference, referenceDescriptor)
@@ -165,7 +202,9 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 	}
 
 	sigExtensions := make([]cosignExtension, 0)
+	attExtensions := make([]cosignAttestationExtension, 0)
 	signatures := []oci.Signature{}
+	signers := []verifier.Signer{}
 	for _, blob := range referenceManifest.Blobs {
 		blobBytes, err := referrerStore.GetBlobContent(ctx, subjectReference, blob.Digest)
 		if err != nil {
@@ -175,12 +214,45 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 		if err != nil {
 			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to parse static signature opts: %w", err)), nil
 		}
+		if blob.MediaType == types.DssePayloadType {
+			// attestations carry the signature in the DSSE envelope of the layer
+			att, err := static.NewAttestation(blobBytes, staticOpts...)
+			if err != nil {
+				return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to generate static attestation: %w", err)), nil
+			}
+			bundleVerified, keyVerifier, err := verifyImageAttestation(ctx, att, subjectDescHash, cosignOpts, keyVerifiers)
+			extension := cosignAttestationExtension{
+				AttestationDigest: blob.Digest,
+				IsSuccess:         true,
+				BundleVerified:    bundleVerified,
+			}
+			if err == nil {
+				err = checkRevocation(revocationChecker, att)
+			}
+			if err == nil {
+				err = setPredicate(&extension, blobBytes)
+			}
+			if err != nil {
+				extension.IsSuccess = false
+				extension.Err = err
+			} else {
+				signatures = append(signatures, att)
+				if signer, err := signatureSigner(att, keyVerifier); err == nil {
+					signers = append(signers, signer)
+				}
+			}
+			attExtensions = append(attExtensions, extension)
+			continue
+		}
 		sig, err := static.NewSignature(blobBytes, blob.Annotations[static.SignatureAnnotationKey], staticOpts...)
 		if err != nil {
 			return errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("failed to generate static signature: %w", err)), nil
 		}
 		// The verification will return an error if the signature is not valid.
-		bundleVerified, err := cosign.VerifyImageSignature(ctx, sig, subjectDescHash, cosignOpts)
+		bundleVerified, keyVerifier, err := verifyImageSignature(ctx, sig, subjectDescHash, cosignOpts, keyVerifiers)
+		if err == nil {
+			err = checkRevocation(revocationChecker, sig)
+		}
 		extension := cosignExtension{
 			SignatureDigest: blob.Digest,
 			IsSuccess:       true,
@@ -191,25 +263,215 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 			extension.Err = err
 		} else {
 			signatures = append(signatures, sig)
+			if signer, err := signatureSigner(sig, keyVerifier); err == nil {
+				signers = append(signers, signer)
+			}
 		}
 		sigExtensions = append(sigExtensions, extension)
 	}
 
+	extensions := Extension{SignatureExtension: sigExtensions, AttestationExtension: attExtensions}
+	kind := "signatures"
+	if len(attExtensions) > 0 {
+		kind = "attestations"
+	}
 	if len(signatures) > 0 {
 		return &verifier.VerifierResult{
 			Name:       input.Config.Name,
 			Type:       verifierType,
 			IsSuccess:  true,
-			Message:    "cosign verification success. valid signatures found",
-			Extensions: Extension{SignatureExtension: sigExtensions},
+			Message:    fmt.Sprintf("cosign verification success. valid %s found", kind),
+			Signers:    signers,
+			Extensions: extensions,
 		}, nil
 	}
 
-	errorResult := errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("no valid signatures found"))
-	errorResult.Extensions = Extension{SignatureExtension: sigExtensions}
+	errorResult := errorToVerifyResult(input.Config.Name, verifierType, fmt.Errorf("no valid %s found", kind))
+	errorResult.Extensions = extensions
 	return errorResult, nil
 }
 
+// configureTransparencyLog sets up the verification of the transparency log
+// inclusion of signatures. Inclusion is checked online with the Rekor server
+// at the configured URL, or offline with the signed entry timestamps of the
+// Rekor bundles. Transparency log verification is turned off otherwise.
+func configureTransparencyLog(ctx context.Context, cosignOpts *cosign.CheckOpts, config PluginConfig, keyless bool) error {
+	var err error
+	switch {
+	case config.Offline:
+		cosignOpts.Offline = true
+		if keyless {
+			if cosignOpts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx); err != nil {
+				return fmt.Errorf("failed to set Certificate Transparency Log public keys: %w", err)
+			}
+		}
+	case config.RekorURL != "":
+		cosignOpts.RekorClient, err = rekor.NewClient(config.RekorURL)
+		if err != nil {
+			return fmt.Errorf("failed to create Rekor client from URL %s: %w", config.RekorURL, err)
+		}
+		cosignOpts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to set Certificate Transparency Log public keys: %w", err)
+		}
+	default:
+		// if no rekor url is provided, turn off transparency log verification and ignore SCTs
+		cosignOpts.IgnoreTlog = true
+		cosignOpts.IgnoreSCT = true
+		return nil
+	}
+
+	if cosignOpts.RekorPubKeys, err = loadRekorPublicKeys(ctx, config.RekorPublicKey); err != nil {
+		return fmt.Errorf("failed to set Rekor public keys: %w", err)
+	}
+	return nil
+}
+
+// loadRekorPublicKeys returns the PEM encoded Rekor public key, or the keys of
+// the Sigstore TUF root if empty.
+func loadRekorPublicKeys(ctx context.Context, rekorPublicKey string) (*cosign.TrustedTransparencyLogPubKeys, error) {
+	if rekorPublicKey == "" {
+		return cosign.GetRekorPubs(ctx)
+	}
+	publicKeys := cosign.NewTrustedTransparencyLogPubKeys()
+	if err := publicKeys.AddTransparencyLogPubKey([]byte(rekorPublicKey), tuf.Active); err != nil {
+		return nil, err
+	}
+	return &publicKeys, nil
+}
+
+// verifyImageSignature verifies the signature with each of the keys until one
+// succeeds and returns the verifier of that key. Keyless signatures are
+// verified if no keys are configured.
+func verifyImageSignature(ctx context.Context, sig oci.Signature, subjectDescHash v1.Hash, cosignOpts *cosign.CheckOpts, keyVerifiers []signature.Verifier) (bool, signature.Verifier, error) {
+	return verifyWithKeys(cosignOpts, keyVerifiers, func(opts *cosign.CheckOpts) (bool, error) {
+		return cosign.VerifyImageSignature(ctx, sig, subjectDescHash, opts)
+	})
+}
+
+// verifyImageAttestation verifies the DSSE envelope of the attestation like
+// verifyImageSignature and checks that the in-toto statement refers to the
+// subject. With keys configured, the key that signed the envelope is selected
+// by the shared DSSE verification before cosign verifies the bundle and claims.
+func verifyImageAttestation(ctx context.Context, att oci.Signature, subjectDescHash v1.Hash, cosignOpts *cosign.CheckOpts, keyVerifiers []signature.Verifier) (bool, signature.Verifier, error) {
+	attOpts := *cosignOpts
+	attOpts.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
+	if len(keyVerifiers) > 0 {
+		keyVerifier, err := envelopeKey(att, keyVerifiers)
+		if err != nil {
+			return false, nil, err
+		}
+		keyVerifiers = []signature.Verifier{keyVerifier}
+	}
+	return verifyWithKeys(&attOpts, keyVerifiers, func(opts *cosign.CheckOpts) (bool, error) {
+		return cosign.VerifyBlobAttestation(ctx, att, subjectDescHash, opts)
+	})
+}
+
+// envelopeKey returns the first of the keys that produced a valid signature
+// of the DSSE envelope of the attestation.
+func envelopeKey(att oci.Signature, keyVerifiers []signature.Verifier) (signature.Verifier, error) {
+	payload, err := att.Payload()
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := dsse.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+	var verifyErr error
+	for _, keyVerifier := range keyVerifiers {
+		publicKey, err := keyVerifier.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		envelopeVerifier, err := dsse.NewVerifier("", publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, verifyErr = envelope.Verify([]dsse.Verifier{envelopeVerifier}, 1); verifyErr == nil {
+			return keyVerifier, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to verify attestation envelope: %w", verifyErr)
+}
+
+func verifyWithKeys(cosignOpts *cosign.CheckOpts, keyVerifiers []signature.Verifier, verify func(*cosign.CheckOpts) (bool, error)) (bool, signature.Verifier, error) {
+	if len(keyVerifiers) == 0 {
+		bundleVerified, err := verify(cosignOpts)
+		return bundleVerified, nil, err
+	}
+	var err error
+	for _, keyVerifier := range keyVerifiers {
+		keyOpts := *cosignOpts
+		keyOpts.SigVerifier = keyVerifier
+		var bundleVerified bool
+		if bundleVerified, err = verify(&keyOpts); err == nil {
+			return bundleVerified, keyVerifier, nil
+		}
+	}
+	return false, nil, err
+}
+
+// setPredicate sets the predicate of the in-toto statement wrapped in the
+// verified attestation envelope.
+func setPredicate(extension *cosignAttestationExtension, envelope []byte) error {
+	statement, ok, err := attestation.ParseStatement(envelope)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("attestation is not an in-toto statement")
+	}
+	extension.PredicateType = statement.PredicateType
+	extension.Predicate = statement.Predicate
+	return nil
+}
+
+// checkRevocation checks the revocation status of the certificate chain of a
+// verified signature. Signatures verified with a public key have no
+// certificate to check.
+func checkRevocation(checker *revocation.Checker, sig oci.Signature) error {
+	if checker == nil {
+		return nil
+	}
+	cert, err := sig.Cert()
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return nil
+	}
+	chain, err := sig.Chain()
+	if err != nil {
+		return err
+	}
+	if len(chain) > 0 && chain[0].Equal(cert) {
+		chain = chain[1:]
+	}
+	return checker.CheckChain(append([]*x509.Certificate{cert}, chain...), time.Time{})
+}
+
+// signatureSigner returns the signer of a verified signature, which is the
+// Fulcio certificate of keyless signatures or the public key otherwise.
+func signatureSigner(sig oci.Signature, keyVerifier signature.Verifier) (verifier.Signer, error) {
+	cert, err := sig.Cert()
+	if err != nil {
+		return verifier.Signer{}, err
+	}
+	if cert != nil {
+		return verifier.NewCertificateSigner(cert), nil
+	}
+	if keyVerifier == nil {
+		return verifier.Signer{}, fmt.Errorf("signature has neither certificate nor verification key")
+	}
+	publicKey, err := keyVerifier.PublicKey()
+	if err != nil {
+		return verifier.Signer{}, err
+	}
+	return verifier.NewKeySigner(publicKey)
+}
+
 func loadPublicKey(_ context.Context, keyRef string) (verifier signature.Verifier, err error) {
 	keyPath := filepath.Clean(utils.ReplaceHomeShortcut(keyRef))
 	raw, err := os.ReadFile(keyPath)
@@ -225,6 +487,15 @@ func loadPublicKey(_ context.Context, keyRef string) (verifier signature.Verifie
 	return signature.LoadECDSAVerifier(ed, crypto.SHA256)
 }
 
+// loadPEMPublicKey loads a PEM encoded RSA, ECDSA or ED25519 public key.
+func loadPEMPublicKey(key string) (signature.Verifier, error) {
+	publicKey, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return signature.LoadVerifier(publicKey, crypto.SHA256)
+}
+
 func staticLayerOpts(desc imgspec.Descriptor) ([]static.Option, error) {
 	options := []static.Option{}
 	options = append(options, static.WithAnnotations(desc.Annotations))
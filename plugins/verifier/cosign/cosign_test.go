@@ -0,0 +1,450 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/common/dsse"
+	"github.com/deislabs/ratify/pkg/verifier/revocation"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/opencontainers/go-digest"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const testPredicateType = "https://slsa.dev/provenance/v1"
+
+func signedAttestation(t *testing.T, key *ecdsa.PrivateKey, subject digest.Digest) []byte {
+	t.Helper()
+	statement, err := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"subject":       []map[string]interface{}{{"name": "net-monitor", "digest": map[string]string{subject.Algorithm().String(): subject.Encoded()}}},
+		"predicateType": testPredicateType,
+		"predicate":     map[string]interface{}{"builder": map[string]string{"id": "https://github.com/actions/runner"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal statement: %v", err)
+	}
+	sum := sha256.Sum256(dsse.PAE(dsse.PayloadTypeInToto, statement))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatalf("failed to sign statement: %v", err)
+	}
+	envelope, err := json.Marshal(dsse.Envelope{
+		PayloadType: dsse.PayloadTypeInToto,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures:  []dsse.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+	return envelope
+}
+
+func TestVerifyImageAttestation(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyVerifier, err := signature.LoadECDSAVerifier(&key.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("failed to load verifier: %v", err)
+	}
+	unusedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	unusedVerifier, err := signature.LoadECDSAVerifier(&unusedKey.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("failed to load verifier: %v", err)
+	}
+	subject := digest.FromString("subject")
+	subjectHash := v1.Hash{Algorithm: subject.Algorithm().String(), Hex: subject.Encoded()}
+
+	tests := []struct {
+		name      string
+		envelope  []byte
+		expectErr bool
+	}{
+		{
+			name:     "valid attestation",
+			envelope: signedAttestation(t, key, subject),
+		},
+		{
+			name:      "attestation of another subject",
+			envelope:  signedAttestation(t, key, digest.FromString("other")),
+			expectErr: true,
+		},
+		{
+			name:      "attestation signed by another key",
+			envelope:  signedAttestation(t, otherKey, subject),
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			att, err := static.NewAttestation(tt.envelope)
+			if err != nil {
+				t.Fatalf("failed to create attestation: %v", err)
+			}
+			opts := &cosign.CheckOpts{IgnoreTlog: true, IgnoreSCT: true}
+			_, verifiedKey, err := verifyImageAttestation(context.Background(), att, subjectHash, opts, []signature.Verifier{unusedVerifier, keyVerifier})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected verification to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to verify attestation: %v", err)
+			}
+			if verifiedKey != keyVerifier {
+				t.Fatalf("expected the attestation to be verified by the key that signed the envelope")
+			}
+		})
+	}
+}
+
+func TestSetPredicate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	extension := cosignAttestationExtension{}
+	if err := setPredicate(&extension, signedAttestation(t, key, digest.FromString("subject"))); err != nil {
+		t.Fatalf("failed to set predicate: %v", err)
+	}
+	if extension.PredicateType != testPredicateType {
+		t.Fatalf("expected predicate type %s, got %s", testPredicateType, extension.PredicateType)
+	}
+	var predicate struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	}
+	if err := json.Unmarshal(extension.Predicate, &predicate); err != nil {
+		t.Fatalf("failed to parse predicate: %v", err)
+	}
+	if predicate.Builder.ID != "https://github.com/actions/runner" {
+		t.Fatalf("unexpected builder %s", predicate.Builder.ID)
+	}
+
+	if err := setPredicate(&extension, []byte(`{"_type":"not-in-toto"}`)); err == nil {
+		t.Fatalf("expected an error for a payload that is not an in-toto statement")
+	}
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// rekorBundle returns the bundle of a hashedrekord entry of the signature,
+// with the signed entry timestamp signed by the Rekor key.
+func rekorBundle(t *testing.T, rekorKey, signingKey *ecdsa.PrivateKey, payload []byte, b64sig string) *bundle.RekorBundle {
+	t.Helper()
+	payloadHash := sha256.Sum256(payload)
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data": map[string]interface{}{
+				"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(payloadHash[:])},
+			},
+			"signature": map[string]interface{}{
+				"content":   b64sig,
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString(publicKeyPEM(t, signingKey))},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal rekor entry: %v", err)
+	}
+	logID, err := cosign.GetTransparencyLogID(&rekorKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to get log ID: %v", err)
+	}
+	rekorPayload := bundle.RekorPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: time.Now().Unix(),
+		LogIndex:       1,
+		LogID:          logID,
+	}
+	// the canonical JSON of the payload has sorted keys, like marshaled maps
+	canonicalized, err := json.Marshal(map[string]interface{}{
+		"body":           rekorPayload.Body,
+		"integratedTime": rekorPayload.IntegratedTime,
+		"logIndex":       rekorPayload.LogIndex,
+		"logID":          rekorPayload.LogID,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal rekor payload: %v", err)
+	}
+	sum := sha256.Sum256(canonicalized)
+	set, err := ecdsa.SignASN1(rand.Reader, rekorKey, sum[:])
+	if err != nil {
+		t.Fatalf("failed to sign entry timestamp: %v", err)
+	}
+	return &bundle.RekorBundle{SignedEntryTimestamp: set, Payload: rekorPayload}
+}
+
+func TestConfigureTransparencyLog(t *testing.T) {
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	opts := &cosign.CheckOpts{}
+	if err := configureTransparencyLog(context.Background(), opts, PluginConfig{}, false); err != nil {
+		t.Fatalf("failed to configure transparency log: %v", err)
+	}
+	if !opts.IgnoreTlog || !opts.IgnoreSCT {
+		t.Fatalf("expected transparency log verification to be turned off without a Rekor URL")
+	}
+
+	opts = &cosign.CheckOpts{}
+	config := PluginConfig{Offline: true, RekorURL: "https://rekor.sigstore.dev", RekorPublicKey: string(publicKeyPEM(t, rekorKey))}
+	if err := configureTransparencyLog(context.Background(), opts, config, false); err != nil {
+		t.Fatalf("failed to configure transparency log: %v", err)
+	}
+	if !opts.Offline || opts.IgnoreTlog || opts.RekorClient != nil {
+		t.Fatalf("expected offline transparency log verification")
+	}
+	logID, err := cosign.GetTransparencyLogID(&rekorKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to get log ID: %v", err)
+	}
+	if _, ok := opts.RekorPubKeys.Keys[logID]; !ok || len(opts.RekorPubKeys.Keys) != 1 {
+		t.Fatalf("expected the configured Rekor public key to be trusted, got %v", opts.RekorPubKeys.Keys)
+	}
+
+	config.RekorPublicKey = "invalid"
+	if err := configureTransparencyLog(context.Background(), &cosign.CheckOpts{}, config, false); err == nil {
+		t.Fatalf("expected an error for an invalid Rekor public key")
+	}
+}
+
+func TestVerifyImageSignature_OfflineBundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherRekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyVerifier, err := signature.LoadECDSAVerifier(&key.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("failed to load verifier: %v", err)
+	}
+	subject := digest.FromString("subject")
+	subjectHash := v1.Hash{Algorithm: subject.Algorithm().String(), Hex: subject.Encoded()}
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"localhost:5000/net-monitor"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, subject))
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatalf("failed to sign payload: %v", err)
+	}
+	b64sig := base64.StdEncoding.EncodeToString(sig)
+
+	tests := []struct {
+		name      string
+		bundle    *bundle.RekorBundle
+		expectErr bool
+	}{
+		{
+			name:   "bundle signed by the configured Rekor key",
+			bundle: rekorBundle(t, rekorKey, key, payload, b64sig),
+		},
+		{
+			name:      "bundle signed by another Rekor key",
+			bundle:    rekorBundle(t, otherRekorKey, key, payload, b64sig),
+			expectErr: true,
+		},
+		{
+			name:      "no bundle",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var staticOpts []static.Option
+			if tt.bundle != nil {
+				staticOpts = append(staticOpts, static.WithBundle(tt.bundle))
+			}
+			ociSig, err := static.NewSignature(payload, b64sig, staticOpts...)
+			if err != nil {
+				t.Fatalf("failed to create signature: %v", err)
+			}
+			opts := &cosign.CheckOpts{ClaimVerifier: cosign.SimpleClaimVerifier}
+			config := PluginConfig{Offline: true, RekorPublicKey: string(publicKeyPEM(t, rekorKey))}
+			if err := configureTransparencyLog(context.Background(), opts, config, false); err != nil {
+				t.Fatalf("failed to configure transparency log: %v", err)
+			}
+			bundleVerified, _, err := verifyImageSignature(context.Background(), ociSig, subjectHash, opts, []signature.Verifier{keyVerifier})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected verification to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to verify signature: %v", err)
+			}
+			if !bundleVerified {
+				t.Fatalf("expected the bundle to be verified")
+			}
+		})
+	}
+}
+
+func TestCheckRevocation(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          time.Now().Add(-time.Minute),
+		NextUpdate:          time.Now().Add(time.Hour),
+		RevokedCertificates: []pkix.RevokedCertificate{{SerialNumber: big.NewInt(3), RevocationTime: time.Now().Add(-time.Minute)}},
+	}, ca, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CRL: %v", err)
+	}
+	crlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(crl)
+	}))
+	defer crlServer.Close()
+
+	leafPEM := func(serial int64) []byte {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: "test leaf"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			CRLDistributionPoints: []string{crlServer.URL},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("failed to create certificate: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	chainPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	checker, err := revocation.NewChecker(revocation.Config{})
+	if err != nil {
+		t.Fatalf("failed to create revocation checker: %v", err)
+	}
+	tests := []struct {
+		name      string
+		checker   *revocation.Checker
+		opts      []static.Option
+		expectErr bool
+	}{
+		{
+			name:    "revocation checks disabled",
+			opts:    []static.Option{static.WithCertChain(leafPEM(3), chainPEM)},
+			checker: nil,
+		},
+		{
+			name:    "signature without certificate",
+			checker: checker,
+		},
+		{
+			name:    "certificate not revoked",
+			opts:    []static.Option{static.WithCertChain(leafPEM(2), chainPEM)},
+			checker: checker,
+		},
+		{
+			name:      "certificate revoked",
+			opts:      []static.Option{static.WithCertChain(leafPEM(3), chainPEM)},
+			checker:   checker,
+			expectErr: true,
+		},
+		{
+			name:      "issuer missing from the chain",
+			opts:      []static.Option{static.WithCertChain(leafPEM(2), nil)},
+			checker:   checker,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := static.NewSignature([]byte("payload"), "", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create signature: %v", err)
+			}
+			if err := checkRevocation(tt.checker, sig); (err != nil) != tt.expectErr {
+				t.Fatalf("checkRevocation() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+
+	// This import is required to utilize the oras built-in referrer store
+	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/attestation"
+	"github.com/deislabs/ratify/pkg/verifier/plugin/skel"
+)
+
+// PluginConfig describes the configuration of the freshness verifier
+type PluginConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// MaxAge is the maximum age of the subject, either a duration such as 720h
+	// or a number of days such as 30d.
+	MaxAge string `json:"maxAge"`
+	// Source determines where the build date is read from, imageConfig (default)
+	// reads the created timestamp of the subject's image config and attestation
+	// reads the build timestamps of the SLSA provenance being verified.
+	Source string `json:"source,omitempty"`
+}
+
+type PluginInputConfig struct {
+	Config PluginConfig `json:"config"`
+}
+
+// provenancePredicate is the subset of SLSA provenance v0.2 and v1 predicates
+// holding the build timestamps
+type provenancePredicate struct {
+	Metadata struct {
+		BuildStartedOn  *time.Time `json:"buildStartedOn"`
+		BuildFinishedOn *time.Time `json:"buildFinishedOn"`
+	} `json:"metadata"`
+	RunDetails struct {
+		Metadata struct {
+			StartedOn  *time.Time `json:"startedOn"`
+			FinishedOn *time.Time `json:"finishedOn"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+}
+
+const (
+	SourceImageConfig string = "imageConfig"
+	SourceAttestation string = "attestation"
+	BuildDate         string = "buildDate"
+	Age               string = "age"
+	MaxAge            string = "maxAge"
+	Source            string = "source"
+)
+
+func main() {
+	skel.PluginMain("freshness", "1.0.0", VerifyReference, []string{"1.0.0"})
+}
+
+func parseInput(stdin []byte) (*PluginConfig, error) {
+	conf := PluginInputConfig{}
+
+	if err := json.Unmarshal(stdin, &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse stdin for the input: %w", err)
+	}
+
+	return &conf.Config, nil
+}
+
+func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (*verifier.VerifierResult, error) {
+	input, err := parseInput(args.StdinData)
+	if err != nil {
+		return nil, err
+	}
+	verifierType := ""
+	if input.Type != "" {
+		verifierType = input.Type
+	}
+
+	maxAge, err := parseMaxAge(input.MaxAge)
+	if err != nil {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   fmt.Sprintf("freshness validation failed: %v", err),
+		}, nil
+	}
+
+	ctx := context.Background()
+	var buildDate time.Time
+	source := input.Source
+	switch source {
+	case "", SourceImageConfig:
+		source = SourceImageConfig
+		buildDate, err = imageConfigCreated(ctx, subjectReference, referrerStore)
+	case SourceAttestation:
+		buildDate, err = attestationBuildDate(ctx, subjectReference, referenceDescriptor, referrerStore)
+	default:
+		err = fmt.Errorf("unsupported source %s, supported sources are %s and %s", source, SourceImageConfig, SourceAttestation)
+	}
+	if err != nil {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   fmt.Sprintf("freshness validation failed for subject %s: %v", subjectReference, err),
+		}, nil
+	}
+
+	return evaluateFreshness(input, verifierType, source, buildDate, maxAge, args.VerificationTime), nil
+}
+
+// evaluateFreshness compares the age of the build date at the verification time with the maximum age
+func evaluateFreshness(input *PluginConfig, verifierType string, source string, buildDate time.Time, maxAge time.Duration, verificationTime time.Time) *verifier.VerifierResult {
+	age := verificationTime.Sub(buildDate)
+	isSuccess := age <= maxAge
+	message := "freshness validation succeeded"
+	if !isSuccess {
+		message = fmt.Sprintf("freshness validation failed: subject was built at %s which is older than the maximum age %s", buildDate.UTC().Format(time.RFC3339), input.MaxAge)
+	}
+	return &verifier.VerifierResult{
+		Name:      input.Name,
+		Type:      verifierType,
+		IsSuccess: isSuccess,
+		Message:   message,
+		Extensions: map[string]interface{}{
+			Source:    source,
+			BuildDate: buildDate.UTC().Format(time.RFC3339),
+			Age:       age.Round(time.Second).String(),
+			MaxAge:    input.MaxAge,
+		},
+	}
+}
+
+// parseMaxAge parses a duration such as 720h, or a number of days such as 30d
+func parseMaxAge(maxAge string) (time.Duration, error) {
+	if maxAge == "" {
+		return 0, fmt.Errorf("maxAge is required")
+	}
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(maxAge, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid maxAge %s: %w", maxAge, err)
+		}
+		duration = time.Duration(count) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(maxAge); err != nil {
+			return 0, fmt.Errorf("invalid maxAge %s: %w", maxAge, err)
+		}
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("maxAge %s must be positive", maxAge)
+	}
+	return duration, nil
+}
+
+// imageConfigCreated returns the created timestamp of the subject's image config
+func imageConfigCreated(ctx context.Context, subjectReference common.Reference, referrerStore referrerstore.ReferrerStore) (time.Time, error) {
+	subjectDesc, err := referrerStore.GetSubjectDescriptor(ctx, subjectReference)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to resolve subject: %w", err)
+	}
+	manifest, err := referrerStore.GetReferenceManifest(ctx, subjectReference, ocispecs.ReferenceDescriptor{Descriptor: subjectDesc.Descriptor})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch subject manifest: %w", err)
+	}
+	if manifest.Config == nil {
+		return time.Time{}, fmt.Errorf("subject manifest of media type %s has no image config", subjectDesc.MediaType)
+	}
+	configBlob, err := referrerStore.GetBlobContent(ctx, subjectReference, manifest.Config.Digest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch image config %s: %w", manifest.Config.Digest, err)
+	}
+	var config oci.Image
+	if err := json.Unmarshal(configBlob, &config); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse image config %s: %w", manifest.Config.Digest, err)
+	}
+	if config.Created == nil || config.Created.IsZero() {
+		return time.Time{}, fmt.Errorf("image config %s has no created timestamp", manifest.Config.Digest)
+	}
+	return *config.Created, nil
+}
+
+// attestationBuildDate returns the latest build timestamp found in the SLSA
+// provenance attestations of the referrer
+func attestationBuildDate(ctx context.Context, subjectReference common.Reference, referenceDescriptor ocispecs.ReferenceDescriptor, referrerStore referrerstore.ReferrerStore) (time.Time, error) {
+	referenceManifest, err := referrerStore.GetReferenceManifest(ctx, subjectReference, referenceDescriptor)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch reference manifest %s: %w", referenceDescriptor.Digest, err)
+	}
+
+	var buildDate time.Time
+	for _, blobDesc := range referenceManifest.Blobs {
+		refBlob, err := referrerStore.GetBlobContent(ctx, subjectReference, blobDesc.Digest)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to fetch blob %s: %w", blobDesc.Digest, err)
+		}
+		date, err := provenanceBuildDate(refBlob)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("blob %s: %w", blobDesc.Digest, err)
+		}
+		if date.After(buildDate) {
+			buildDate = date
+		}
+	}
+	if buildDate.IsZero() {
+		return time.Time{}, fmt.Errorf("no build timestamp found in referrer %s", referenceDescriptor.Digest)
+	}
+	return buildDate, nil
+}
+
+// provenanceBuildDate returns the build finish time, or the build start time if
+// the finish time is not recorded, of a SLSA provenance attestation
+func provenanceBuildDate(blob []byte) (time.Time, error) {
+	statement, ok, err := attestation.ParseStatement(blob)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("blob is not an in-toto statement")
+	}
+
+	var predicate provenancePredicate
+	if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse provenance predicate: %w", err)
+	}
+	for _, date := range []*time.Time{
+		predicate.RunDetails.Metadata.FinishedOn,
+		predicate.Metadata.BuildFinishedOn,
+		predicate.RunDetails.Metadata.StartedOn,
+		predicate.Metadata.BuildStartedOn,
+	} {
+		if date != nil && !date.IsZero() {
+			return *date, nil
+		}
+	}
+	return time.Time{}, nil
+}
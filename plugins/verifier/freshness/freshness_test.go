@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/ocispecs"
+	"github.com/deislabs/ratify/pkg/referrerstore/mocks"
+	"github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const provenanceV02 = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://slsa.dev/provenance/v0.2",
+	"subject": [{"name": "test", "digest": {"sha256": "b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e"}}],
+	"predicate": {
+		"buildType": "https://mobyproject.org/buildkit@v1",
+		"metadata": {"buildStartedOn": "2023-05-01T10:00:00Z", "buildFinishedOn": "2023-05-01T10:05:00Z"}
+	}
+}`
+
+const provenanceV1 = `{
+	"_type": "https://in-toto.io/Statement/v1",
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"subject": [{"name": "test", "digest": {"sha256": "b6f3a1f0bb3e1e0d6a8bf7b1b8d0b2f4e8fa6a4b2c8a1f9b0e7d7c6a5b4c3d2e"}}],
+	"predicate": {
+		"runDetails": {"metadata": {"startedOn": "2023-06-01T10:00:00Z"}}
+	}
+}`
+
+func TestParseMaxAge(t *testing.T) {
+	testCases := []struct {
+		maxAge    string
+		expected  time.Duration
+		isSuccess bool
+	}{
+		{maxAge: "720h", expected: 720 * time.Hour, isSuccess: true},
+		{maxAge: "30d", expected: 30 * 24 * time.Hour, isSuccess: true},
+		{maxAge: ""},
+		{maxAge: "0d"},
+		{maxAge: "-1h"},
+		{maxAge: "a month"},
+		{maxAge: "xd"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.maxAge, func(t *testing.T) {
+			duration, err := parseMaxAge(tc.maxAge)
+			if (err == nil) != tc.isSuccess {
+				t.Fatalf("expected success %v, got error %v", tc.isSuccess, err)
+			}
+			if duration != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, duration)
+			}
+		})
+	}
+}
+
+func TestProvenanceBuildDate(t *testing.T) {
+	date, err := provenanceBuildDate([]byte(provenanceV02))
+	if err != nil || !date.Equal(time.Date(2023, 5, 1, 10, 5, 0, 0, time.UTC)) {
+		t.Fatalf("expected build finish time of v0.2 provenance, got %s, err: %v", date, err)
+	}
+	date, err = provenanceBuildDate([]byte(provenanceV1))
+	if err != nil || !date.Equal(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected build start time of v1 provenance, got %s, err: %v", date, err)
+	}
+	if _, err := provenanceBuildDate([]byte(`{"version": "2.1.0"}`)); err == nil {
+		t.Fatalf("expected error for blob that is not an attestation")
+	}
+}
+
+func TestImageConfigCreated(t *testing.T) {
+	configBlob := []byte(`{"created":"2023-05-01T10:00:00Z","architecture":"amd64","os":"linux"}`)
+	configDigest := digest.FromBytes(configBlob)
+	subjectDigest := digest.FromString("subject")
+	artifactDigest := digest.FromString("artifact")
+	store := &mocks.MemoryTestStore{
+		Subjects: map[digest.Digest]*ocispecs.SubjectDescriptor{
+			subjectDigest:  {Descriptor: oci.Descriptor{MediaType: oci.MediaTypeImageManifest, Digest: subjectDigest}},
+			artifactDigest: {Descriptor: oci.Descriptor{MediaType: oci.MediaTypeImageManifest, Digest: artifactDigest}},
+		},
+		Manifests: map[digest.Digest]ocispecs.ReferenceManifest{
+			subjectDigest: {
+				MediaType: oci.MediaTypeImageManifest,
+				Config:    &oci.Descriptor{MediaType: oci.MediaTypeImageConfig, Digest: configDigest},
+			},
+			artifactDigest: {MediaType: oci.MediaTypeImageManifest, ArtifactType: "application/spdx+json"},
+		},
+		Blobs: map[digest.Digest][]byte{configDigest: configBlob},
+	}
+
+	created, err := imageConfigCreated(context.Background(), common.Reference{Digest: subjectDigest}, store)
+	if err != nil || !created.Equal(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected created timestamp of image config, got %s, err: %v", created, err)
+	}
+	if _, err := imageConfigCreated(context.Background(), common.Reference{Digest: artifactDigest}, store); err == nil {
+		t.Fatalf("expected error for manifest without image config")
+	}
+}
+
+func TestEvaluateFreshness(t *testing.T) {
+	verificationTime := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	input := &PluginConfig{Name: "freshness", MaxAge: "30d"}
+	result := evaluateFreshness(input, "", SourceImageConfig, time.Date(2023, 5, 15, 0, 0, 0, 0, time.UTC), 30*24*time.Hour, verificationTime)
+	if !result.IsSuccess {
+		t.Fatalf("expected image built 17 days ago to be fresh: %s", result.Message)
+	}
+	if result.Extensions.(map[string]interface{})[Age] != "408h0m0s" {
+		t.Fatalf("unexpected extensions %v", result.Extensions)
+	}
+
+	result = evaluateFreshness(input, "", SourceImageConfig, time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), 30*24*time.Hour, verificationTime)
+	if result.IsSuccess {
+		t.Fatalf("expected image built 61 days ago to be stale")
+	}
+}
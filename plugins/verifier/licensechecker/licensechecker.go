@@ -31,9 +31,11 @@ import (
 )
 
 type PluginConfig struct {
-	Name            string   `json:"name"`
-	Type            string   `json:"type"`
-	AllowedLicenses []string `json:"allowedLicenses"`
+	Name               string                   `json:"name"`
+	Type               string                   `json:"type"`
+	AllowedLicenses    []string                 `json:"allowedLicenses,omitempty"`
+	DisallowedLicenses []string                 `json:"disallowedLicenses,omitempty"`
+	Exemptions         []utils.PackageExemption `json:"exemptions,omitempty"`
 }
 
 type PluginInputConfig struct {
@@ -63,7 +65,16 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, desc
 	if input.Type != "" {
 		verifierType = input.Type
 	}
-	allowedLicenses := utils.LoadAllowedLicenses(input.AllowedLicenses)
+	if len(input.AllowedLicenses) == 0 && len(input.DisallowedLicenses) == 0 {
+		return &verifier.VerifierResult{
+			Name:      input.Name,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   "License Check FAILED: no allowedLicenses or disallowedLicenses configured",
+		}, nil
+	}
+	allowedLicenses := utils.LoadLicenses(input.AllowedLicenses)
+	disallowedLicenses := utils.LoadLicenses(input.DisallowedLicenses)
 
 	ctx := context.Background()
 	referenceManifest, err := store.GetReferenceManifest(ctx, subjectReference, descriptor)
@@ -91,15 +102,15 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, desc
 			return nil, err
 		}
 
-		packageLicenses := utils.GetPackageLicenses(*spdxDoc)
-		disallowedLicenses := utils.FilterPackageLicenses(packageLicenses, allowedLicenses)
+		packageLicenses := utils.FilterExemptPackages(utils.GetPackageLicenses(*spdxDoc), input.Exemptions)
+		violations := utils.FilterLicenseViolations(packageLicenses, allowedLicenses, disallowedLicenses)
 
-		if len(disallowedLicenses) > 0 {
+		if len(violations) > 0 {
 			return &verifier.VerifierResult{
 				Name:      input.Name,
 				Type:      verifierType,
 				IsSuccess: false,
-				Message:   fmt.Sprintf("License Check: FAILED. %s", disallowedLicenses),
+				Message:   fmt.Sprintf("License Check: FAILED. %s", violations),
 			}, nil
 		}
 	}
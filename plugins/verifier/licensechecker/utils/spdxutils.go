@@ -33,13 +33,14 @@ func GetPackageLicenses(doc spdx.Document) []PackageLicense {
 	for _, p := range doc.Packages {
 		output = append(output, PackageLicense{
 			PackageName:    p.PackageName,
+			PackageVersion: p.PackageVersion,
 			PackageLicense: p.PackageLicenseConcluded,
 		})
 	}
 	return output
 }
 
-func LoadAllowedLicenses(licenses []string) map[string]struct{} {
+func LoadLicenses(licenses []string) map[string]struct{} {
 	output := map[string]struct{}{}
 	for _, license := range licenses {
 		output[license] = struct{}{}
@@ -47,11 +48,36 @@ func LoadAllowedLicenses(licenses []string) map[string]struct{} {
 	return output
 }
 
-func FilterPackageLicenses(packageLicenses []PackageLicense, allowedLicenses map[string]struct{}) []PackageLicense {
+// FilterLicenseViolations returns the packages whose license is not in the allowed
+// licenses, when any are configured, or is in the disallowed licenses
+func FilterLicenseViolations(packageLicenses []PackageLicense, allowedLicenses map[string]struct{}, disallowedLicenses map[string]struct{}) []PackageLicense {
 	var output []PackageLicense
 	for _, packageLicense := range packageLicenses {
-		_, ok := allowedLicenses[packageLicense.PackageLicense]
-		if !ok {
+		_, allowed := allowedLicenses[packageLicense.PackageLicense]
+		_, disallowed := disallowedLicenses[packageLicense.PackageLicense]
+		if (len(allowedLicenses) > 0 && !allowed) || disallowed {
+			output = append(output, packageLicense)
+		}
+	}
+	return output
+}
+
+// FilterExemptPackages removes the packages matching an exemption by name and,
+// if the exemption has one, by version
+func FilterExemptPackages(packageLicenses []PackageLicense, exemptions []PackageExemption) []PackageLicense {
+	if len(exemptions) == 0 {
+		return packageLicenses
+	}
+	output := []PackageLicense{}
+	for _, packageLicense := range packageLicenses {
+		exempt := false
+		for _, exemption := range exemptions {
+			if exemption.Name == packageLicense.PackageName && (exemption.Version == "" || exemption.Version == packageLicense.PackageVersion) {
+				exempt = true
+				break
+			}
+		}
+		if !exempt {
 			output = append(output, packageLicense)
 		}
 	}
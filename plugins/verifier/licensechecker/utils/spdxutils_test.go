@@ -72,24 +72,58 @@ func TestGetPackageLicenses(t *testing.T) {
 	}
 }
 
-func TestLoadAllowedLicenses(t *testing.T) {
+func TestLoadLicenses(t *testing.T) {
 	license := "GPL-2.0-only"
-	licenses := LoadAllowedLicenses([]string{license})
+	licenses := LoadLicenses([]string{license})
 	_, ok := licenses[license]
 	if !ok {
 		t.Fatalf("expected license but not present")
 	}
 }
 
-func TestFilterPackageLicenses(t *testing.T) {
-	spdxDoc, err := BlobToSPDX(spdxTestBytes)
-	if err != nil {
-		t.Fatalf("could not parse SPDX doc from bytes")
+func TestFilterLicenseViolations(t *testing.T) {
+	packageLicenses := []PackageLicense{
+		{PackageName: "musl", PackageVersion: "1.2.3-r4", PackageLicense: "MIT"},
+		{PackageName: "busybox", PackageVersion: "1.35.0-r29", PackageLicense: "GPL-2.0-only"},
+		{PackageName: "zlib", PackageVersion: "1.2.13-r0", PackageLicense: "Zlib"},
+	}
+
+	tests := []struct {
+		name       string
+		allowed    []string
+		disallowed []string
+		expected   int
+	}{
+		{name: "allowed licenses only", allowed: []string{"MIT", "Zlib"}, expected: 1},
+		{name: "disallowed licenses only", disallowed: []string{"Zlib"}, expected: 1},
+		{name: "allowed and disallowed licenses", allowed: []string{"MIT", "Zlib"}, disallowed: []string{"Zlib"}, expected: 2},
+		{name: "no violations", allowed: []string{"MIT", "Zlib", "GPL-2.0-only"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FilterLicenseViolations(packageLicenses, LoadLicenses(tt.allowed), LoadLicenses(tt.disallowed))
+			if len(result) != tt.expected {
+				t.Fatalf("expected %d violations, got %d", tt.expected, len(result))
+			}
+		})
 	}
-	PackageLicenses := GetPackageLicenses(*spdxDoc)
-	filterLicenses := LoadAllowedLicenses([]string{"GPL-2.0-only"})
-	result := FilterPackageLicenses(PackageLicenses, filterLicenses)
-	if len(result) != 0 {
-		t.Fatalf("License not filtered correctly")
+}
+
+func TestFilterExemptPackages(t *testing.T) {
+	packageLicenses := []PackageLicense{
+		{PackageName: "busybox", PackageVersion: "1.35.0-r29", PackageLicense: "GPL-2.0-only"},
+		{PackageName: "busybox", PackageVersion: "1.36.1-r0", PackageLicense: "GPL-2.0-only"},
+		{PackageName: "zlib", PackageVersion: "1.2.13-r0", PackageLicense: "Zlib"},
+	}
+
+	result := FilterExemptPackages(packageLicenses, []PackageExemption{{Name: "busybox", Version: "1.35.0-r29"}})
+	if len(result) != 2 || result[0].PackageVersion != "1.36.1-r0" {
+		t.Fatalf("expected only the exempt version to be removed, got %v", result)
+	}
+
+	result = FilterExemptPackages(packageLicenses, []PackageExemption{{Name: "busybox"}})
+	if len(result) != 1 || result[0].PackageName != "zlib" {
+		t.Fatalf("expected all versions of exempt package to be removed, got %v", result)
 	}
 }
@@ -17,5 +17,13 @@ package utils
 
 type PackageLicense struct {
 	PackageName    string
+	PackageVersion string
 	PackageLicense string
 }
+
+// PackageExemption identifies a package excluded from license checks.
+// An empty version exempts all versions of the package.
+type PackageExemption struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
@@ -31,6 +31,7 @@ import (
 	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
 	"github.com/deislabs/ratify/pkg/verifier"
 	"github.com/deislabs/ratify/pkg/verifier/plugin/skel"
+	"github.com/opencontainers/go-digest"
 	jsonLoader "github.com/spdx/tools-golang/json"
 	"github.com/spdx/tools-golang/spdx"
 	"github.com/spdx/tools-golang/spdx/v2/v2_3"
@@ -41,7 +42,11 @@ type PluginConfig struct {
 	Name               string              `json:"name"`
 	Type               string              `json:"type"`
 	DisallowedLicenses []string            `json:"disallowedLicenses,omitempty"`
+	AllowedLicenses    []string            `json:"allowedLicenses,omitempty"`
 	DisallowedPackages []utils.PackageInfo `json:"disallowedPackages,omitempty"`
+	// EcosystemPolicies replace the license and package policies above for
+	// packages of their package URL types, the first matching policy applies.
+	EcosystemPolicies []utils.EcosystemPolicy `json:"ecosystemPolicies,omitempty"`
 }
 
 type PluginInputConfig struct {
@@ -53,6 +58,8 @@ const (
 	CreationInfo      string = "creationInfo"
 	LicenseViolation  string = "licenseViolations"
 	PackageViolation  string = "packageViolations"
+	ManifestDigest    string = "manifestDigest"
+	BlobDigest        string = "blobDigest"
 )
 
 func main() {
@@ -111,9 +118,22 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 			}, nil
 		}
 
+		// only parse the SBOM referenced by the manifest being verified, so a
+		// signature over the manifest also covers the parsed content
+		if err := verifyBlobDigest(blobDesc.Digest, refBlob); err != nil {
+			return &verifier.VerifierResult{
+				Name:      input.Name,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("SBOM validation failed: blob %s of referrer %s@%s is corrupted: %v", blobDesc.Digest, subjectReference.Path, referenceDescriptor.Digest.String(), err),
+			}, nil
+		}
+
 		switch artifactType {
 		case SpdxJSONMediaType:
-			return processSpdxJSONMediaType(input.Name, verifierType, refBlob, input.DisallowedLicenses, input.DisallowedPackages), nil
+			result := processSpdxJSONMediaType(input.Name, verifierType, refBlob, input.DisallowedLicenses, input.AllowedLicenses, input.DisallowedPackages, input.EcosystemPolicies)
+			recordSBOMDigests(result, referenceDescriptor.Digest, blobDesc.Digest)
+			return result, nil
 		default:
 			return &verifier.VerifierResult{
 				Name:      input.Name,
@@ -132,39 +152,107 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 	}, nil
 }
 
-// getViolations returns the package and license violations based on the deny list
-func getViolations(spdxDoc *spdx.Document, disallowedLicenses []string, disallowedPackages []utils.PackageInfo) ([]utils.PackageLicense, []utils.PackageLicense) {
+// packagePolicy holds the deny and allow lists loaded into maps for easier existence check
+type packagePolicy struct {
+	disallowedLicenses      map[string]struct{}
+	allowedLicenses         map[string]struct{}
+	disallowedPackages      map[utils.PackageInfo]struct{}
+	disallowedPackageNames  map[string]struct{}
+	disallowedPackageRanges map[string][]*utils.VersionConstraint
+}
+
+func newPackagePolicy(disallowedLicenses []string, allowedLicenses []string, disallowedPackages []utils.PackageInfo) (*packagePolicy, error) {
+	packageMap, packageNameMap, packageRangeMap, err := loadDisallowedPackagesMap(disallowedPackages)
+	if err != nil {
+		return nil, err
+	}
+	return &packagePolicy{
+		disallowedLicenses:      utils.LoadLicenses(disallowedLicenses),
+		allowedLicenses:         utils.LoadLicenses(allowedLicenses),
+		disallowedPackages:      packageMap,
+		disallowedPackageNames:  packageNameMap,
+		disallowedPackageRanges: packageRangeMap,
+	}, nil
+}
+
+// loadEcosystemPolicies returns the policies keyed by lowercase package URL type
+func loadEcosystemPolicies(ecosystemPolicies []utils.EcosystemPolicy) (map[string]*packagePolicy, error) {
+	policies := map[string]*packagePolicy{}
+	for i, ecosystemPolicy := range ecosystemPolicies {
+		if len(ecosystemPolicy.PurlTypes) == 0 {
+			return nil, fmt.Errorf("purlTypes of ecosystem policy %d must not be empty", i)
+		}
+		policy, err := newPackagePolicy(ecosystemPolicy.DisallowedLicenses, ecosystemPolicy.AllowedLicenses, ecosystemPolicy.DisallowedPackages)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ecosystem policy %d: %w", i, err)
+		}
+		for _, purlType := range ecosystemPolicy.PurlTypes {
+			purlType = strings.ToLower(purlType)
+			if _, ok := policies[purlType]; !ok {
+				policies[purlType] = policy
+			}
+		}
+	}
+	return policies, nil
+}
+
+// getViolations returns the package and license violations based on the deny and allow lists
+func getViolations(spdxDoc *spdx.Document, disallowedLicenses []string, allowedLicenses []string, disallowedPackages []utils.PackageInfo, ecosystemPolicies []utils.EcosystemPolicy) ([]utils.PackageLicense, []utils.PackageLicense, error) {
 	packageLicenses := utils.GetPackageLicenses(*spdxDoc)
-	// load disallowed packageInfo into a map for easier existence check
-	packageMap, packageNameMap := loadDisallowedPackagesMap(disallowedPackages)
+	policy, err := newPackagePolicy(disallowedLicenses, allowedLicenses, disallowedPackages)
+	if err != nil {
+		return nil, nil, err
+	}
+	ecosystems, err := loadEcosystemPolicies(ecosystemPolicies)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// detect violation
-	licenseViolation, packageViolation := filterDisallowedPackages(packageLicenses, disallowedLicenses, packageMap, packageNameMap)
-	return packageViolation, licenseViolation
+	licenseViolation, packageViolation := filterDisallowedPackages(packageLicenses, policy, ecosystems)
+	return packageViolation, licenseViolation, nil
 }
 
-// load disallowed packageInfo, and disallowed packageName into a map for easier existence check
-func loadDisallowedPackagesMap(packages []utils.PackageInfo) (map[utils.PackageInfo]struct{}, map[string]struct{}) {
+// load disallowed packageInfo, disallowed packageName and disallowed version ranges into maps for easier existence check
+func loadDisallowedPackagesMap(packages []utils.PackageInfo) (map[utils.PackageInfo]struct{}, map[string]struct{}, map[string][]*utils.VersionConstraint, error) {
 	packagesInfo := map[utils.PackageInfo]struct{}{}
 	packagesName := map[string]struct{}{}
+	packagesRange := map[string][]*utils.VersionConstraint{}
 
 	for _, item := range packages {
 		// if the deny list item has no specific version, add to separate map
 		if len(item.Version) == 0 {
 			packagesName[item.Name] = struct{}{}
 		}
+		// if the deny list item has a version range such as "< 2.17.1", add the parsed range to separate map
+		if utils.IsVersionRange(item.Version) {
+			constraint, err := utils.ParseVersionConstraint(item.Version)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid disallowed package %s: %w", item.Name, err)
+			}
+			packagesRange[item.Name] = append(packagesRange[item.Name], constraint)
+			continue
+		}
 		packagesInfo[item] = struct{}{}
 	}
-	return packagesInfo, packagesName
+	return packagesInfo, packagesName, packagesRange, nil
 }
 
 // parse through the spdx blob and returns the verifier result
-func processSpdxJSONMediaType(name string, verifierType string, refBlob []byte, disallowedLicenses []string, disallowedPackages []utils.PackageInfo) *verifier.VerifierResult {
+func processSpdxJSONMediaType(name string, verifierType string, refBlob []byte, disallowedLicenses []string, allowedLicenses []string, disallowedPackages []utils.PackageInfo, ecosystemPolicies []utils.EcosystemPolicy) *verifier.VerifierResult {
 	var err error
 	var spdxDoc *v2_3.Document
 	if spdxDoc, err = jsonLoader.Read(bytes.NewReader(refBlob)); spdxDoc != nil && err == nil {
-		if len(disallowedLicenses) != 0 || len(disallowedPackages) != 0 {
-			packageViolation, licenseViolation := getViolations(spdxDoc, disallowedLicenses, disallowedPackages)
+		if len(disallowedLicenses) != 0 || len(allowedLicenses) != 0 || len(disallowedPackages) != 0 || len(ecosystemPolicies) != 0 {
+			packageViolation, licenseViolation, err := getViolations(spdxDoc, disallowedLicenses, allowedLicenses, disallowedPackages, ecosystemPolicies)
+			if err != nil {
+				return &verifier.VerifierResult{
+					Name:      name,
+					Type:      verifierType,
+					IsSuccess: false,
+					Message:   fmt.Sprintf("SBOM validation failed: %v", err),
+				}
+			}
 
 			var extensionData = make(map[string]interface{})
 			extensionData[CreationInfo] = spdxDoc.CreationInfo
@@ -204,18 +292,46 @@ func processSpdxJSONMediaType(name string, verifierType string, refBlob []byte,
 	}
 }
 
-// iterate through all package info and check against the deny list
+// verifyBlobDigest checks that the fetched blob matches its descriptor digest.
+func verifyBlobDigest(expected digest.Digest, blob []byte) error {
+	if err := expected.Validate(); err != nil {
+		return err
+	}
+	if actual := expected.Algorithm().FromBytes(blob); actual != expected {
+		return fmt.Errorf("content digest %s does not match expected digest %s", actual, expected)
+	}
+	return nil
+}
+
+// recordSBOMDigests records the manifest and blob digests of the parsed SBOM
+// in the result, linking the result to the exact content it evaluated.
+func recordSBOMDigests(result *verifier.VerifierResult, manifestDigest digest.Digest, blobDigest digest.Digest) {
+	extensions, ok := result.Extensions.(map[string]interface{})
+	if !ok {
+		extensions = make(map[string]interface{})
+	}
+	extensions[ManifestDigest] = manifestDigest.String()
+	extensions[BlobDigest] = blobDigest.String()
+	result.Extensions = extensions
+}
+
+// iterate through all package info and check against the deny and allow lists
+// of the ecosystem of the package, or the verifier if no ecosystem policy matches
 // return the violation packages
-func filterDisallowedPackages(packageLicenses []utils.PackageLicense, disallowedLicense []string, disallowedPackage map[utils.PackageInfo]struct{}, disallowedPackageName map[string]struct{}) ([]utils.PackageLicense, []utils.PackageLicense) {
+func filterDisallowedPackages(packageLicenses []utils.PackageLicense, defaultPolicy *packagePolicy, ecosystemPolicies map[string]*packagePolicy) ([]utils.PackageLicense, []utils.PackageLicense) {
 	var violationLicense []utils.PackageLicense
 	var violationPackage []utils.PackageLicense
 
 	for _, packageInfo := range packageLicenses {
-		// if license contains disallowed, add to violation
-		for _, disallowed := range disallowedLicense {
-			if utils.ContainsLicense(strings.ToLower(packageInfo.License), strings.ToLower(disallowed)) {
-				violationLicense = append(violationLicense, packageInfo)
-			}
+		policy := defaultPolicy
+		if ecosystemPolicy, ok := ecosystemPolicies[packageInfo.PurlType]; ok {
+			policy = ecosystemPolicy
+		}
+
+		// if the license expression cannot be satisfied without a disallowed license,
+		// or cannot be satisfied with allowed licenses only, add to violation
+		if utils.ViolatesDenyList(packageInfo.License, policy.disallowedLicenses) || utils.ViolatesAllowList(packageInfo.License, policy.allowedLicenses) {
+			violationLicense = append(violationLicense, packageInfo)
 		}
 
 		current := utils.PackageInfo{
@@ -224,14 +340,22 @@ func filterDisallowedPackages(packageLicenses []utils.PackageLicense, disallowed
 		}
 
 		// check if this package is in the deny list by package name
-		if _, ok := disallowedPackageName[current.Name]; ok {
+		if _, ok := policy.disallowedPackageNames[current.Name]; ok {
 			violationPackage = append(violationPackage, packageInfo)
 		}
 
 		//  check if this package is in the deny list by matching name and version
-		if _, ok := disallowedPackage[current]; ok {
+		if _, ok := policy.disallowedPackages[current]; ok {
 			violationPackage = append(violationPackage, packageInfo)
 		}
+
+		// check if this package is in the deny list by matching name and version range
+		for _, constraint := range policy.disallowedPackageRanges[current.Name] {
+			if constraint.Matches(current.Version) {
+				violationPackage = append(violationPackage, packageInfo)
+				break
+			}
+		}
 	}
 	return violationLicense, violationPackage
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/deislabs/ratify/plugins/verifier/sbom/utils"
+	"github.com/opencontainers/go-digest"
 )
 
 func TestProcessSPDXJsonMediaType(t *testing.T) {
@@ -28,7 +29,7 @@ func TestProcessSPDXJsonMediaType(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error reading %s", filepath.Join("testdata", "bom.json"))
 	}
-	vr := processSpdxJSONMediaType("test", "", b, nil, nil)
+	vr := processSpdxJSONMediaType("test", "", b, nil, nil, nil, nil)
 	if !vr.IsSuccess {
 		t.Fatalf("expected to successfully verify schema")
 	}
@@ -39,7 +40,7 @@ func TestProcessInvalidSPDXJsonMediaType(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error reading %s", filepath.Join("testdata", "invalid-bom.json"))
 	}
-	report := processSpdxJSONMediaType("test", "", b, nil, nil)
+	report := processSpdxJSONMediaType("test", "", b, nil, nil, nil, nil)
 
 	if !strings.Contains(report.Message, "SBOM failed to parse") {
 		t.Fatalf("expected to have an error processing spdx json file: %s", filepath.Join("testdata", "bom.json"))
@@ -79,6 +80,12 @@ func TestGetViolations(t *testing.T) {
 		Version: "1.2.13-r0",
 	}
 
+	mplViolation := utils.PackageLicense{
+		Name:    "ca-certificates-bundle",
+		Version: "20220614-r4",
+		License: "MPL-2.0 AND LicenseRef-AND AND MIT",
+	}
+
 	disallowedPackage2 := utils.PackageInfo{
 		Name:    "libcrypto3",
 		Version: "3.0.7-r3",
@@ -92,6 +99,7 @@ func TestGetViolations(t *testing.T) {
 	cases := []struct {
 		description               string
 		disallowedLicenses        []string
+		allowedLicenses           []string
 		disallowedPackages        []utils.PackageInfo
 		expectedLicenseViolations []utils.PackageLicense
 		expectedPackageViolations []utils.PackageLicense
@@ -144,6 +152,24 @@ func TestGetViolations(t *testing.T) {
 			expectedPackageViolations: []utils.PackageLicense{},
 			enabled:                   true,
 		},
+		{
+			description:               "license violation found in allow list mode",
+			allowedLicenses:           []string{"Apache-2.0", "BSD-2-Clause", "BSD-3-Clause", "GPL-2.0", "GPL-2.0+", "LicenseRef-AND", "MIT", "NOASSERTION", "NONE"},
+			expectedLicenseViolations: []utils.PackageLicense{mplViolation, violation2},
+			expectedPackageViolations: []utils.PackageLicense{},
+		},
+		{
+			description:               "package violation found by version range",
+			disallowedPackages:        []utils.PackageInfo{{Name: "libcrypto3", Version: "< 3.0.7-r3"}},
+			expectedLicenseViolations: []utils.PackageLicense{},
+			expectedPackageViolations: []utils.PackageLicense{packageViolation},
+		},
+		{
+			description:               "package violation not found by version range",
+			disallowedPackages:        []utils.PackageInfo{{Name: "libcrypto3", Version: ">= 3.0.8, < 3.1.0"}},
+			expectedLicenseViolations: []utils.PackageLicense{},
+			expectedPackageViolations: []utils.PackageLicense{},
+		},
 		{
 			description:               "license violation not found",
 			disallowedLicenses:        []string{"GPL-3.0-only"},
@@ -155,7 +181,7 @@ func TestGetViolations(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run("test scenario", func(t *testing.T) {
-			report := processSpdxJSONMediaType("test", "", b, tc.disallowedLicenses, tc.disallowedPackages)
+			report := processSpdxJSONMediaType("test", "", b, tc.disallowedLicenses, tc.allowedLicenses, tc.disallowedPackages, nil)
 
 			if len(tc.expectedPackageViolations) != 0 || len(tc.expectedLicenseViolations) != 0 {
 				if report.IsSuccess {
@@ -184,6 +210,75 @@ func TestGetViolations(t *testing.T) {
 	}
 }
 
+func TestProcessSPDXJsonMediaType_InvalidVersionRange(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "syftbom.spdx.json"))
+	if err != nil {
+		t.Fatalf("error reading %s", filepath.Join("testdata", "syftbom.spdx.json"))
+	}
+	disallowedPackages := []utils.PackageInfo{{Name: "libcrypto3", Version: "< "}}
+	report := processSpdxJSONMediaType("test", "", b, nil, nil, disallowedPackages, nil)
+	if report.IsSuccess || !strings.Contains(report.Message, "invalid disallowed package libcrypto3") {
+		t.Fatalf("expected invalid version range failure, got: %s", report.Message)
+	}
+}
+
+func TestProcessSPDXJsonMediaType_EcosystemPolicies(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "syftbom.spdx.json"))
+	if err != nil {
+		t.Fatalf("error reading %s", filepath.Join("testdata", "syftbom.spdx.json"))
+	}
+	zlibViolation := utils.PackageLicense{Name: "zlib", License: "Zlib", Version: "1.2.13-r0", PurlType: "apk"}
+
+	cases := []struct {
+		description               string
+		disallowedLicenses        []string
+		ecosystemPolicies         []utils.EcosystemPolicy
+		expectedLicenseViolations []utils.PackageLicense
+		expectedPackageViolations []utils.PackageLicense
+	}{
+		{
+			description:        "os packages allowed by ecosystem policy",
+			disallowedLicenses: []string{"Zlib"},
+			ecosystemPolicies:  []utils.EcosystemPolicy{{PurlTypes: []string{"APK", "deb"}}},
+		},
+		{
+			description:               "ecosystem policy of other package types does not apply",
+			ecosystemPolicies:         []utils.EcosystemPolicy{{PurlTypes: []string{"golang", "npm"}, DisallowedLicenses: []string{"Zlib"}}},
+			expectedLicenseViolations: nil,
+		},
+		{
+			description:               "violation found by ecosystem policy",
+			ecosystemPolicies:         []utils.EcosystemPolicy{{PurlTypes: []string{"apk"}, DisallowedLicenses: []string{"Zlib"}, DisallowedPackages: []utils.PackageInfo{{Name: "zlib"}}}},
+			expectedLicenseViolations: []utils.PackageLicense{zlibViolation},
+			expectedPackageViolations: []utils.PackageLicense{zlibViolation},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			report := processSpdxJSONMediaType("test", "", b, tc.disallowedLicenses, nil, nil, tc.ecosystemPolicies)
+			expectSuccess := len(tc.expectedLicenseViolations) == 0 && len(tc.expectedPackageViolations) == 0
+			if report.IsSuccess != expectSuccess {
+				t.Fatalf("expected IsSuccess: %v, got: %v, message: %s", expectSuccess, report.IsSuccess, report.Message)
+			}
+			if expectSuccess {
+				return
+			}
+			extensionData := report.Extensions.(map[string]interface{})
+			AssertEquals(tc.expectedLicenseViolations, extensionData[LicenseViolation].([]utils.PackageLicense), tc.description, t)
+			AssertEquals(tc.expectedPackageViolations, extensionData[PackageViolation].([]utils.PackageLicense), tc.description, t)
+			if purlType := extensionData[LicenseViolation].([]utils.PackageLicense)[0].PurlType; purlType != "apk" {
+				t.Fatalf("expected purl type apk in violation, got: %s", purlType)
+			}
+		})
+	}
+
+	report := processSpdxJSONMediaType("test", "", b, nil, nil, nil, []utils.EcosystemPolicy{{DisallowedLicenses: []string{"Zlib"}}})
+	if report.IsSuccess || !strings.Contains(report.Message, "purlTypes of ecosystem policy 0 must not be empty") {
+		t.Fatalf("expected ecosystem policy without purl types to fail, got: %s", report.Message)
+	}
+}
+
 func AssertEquals(expected []utils.PackageLicense, actual []utils.PackageLicense, description string, t *testing.T) {
 	if len(expected) != len(actual) {
 		t.Fatalf("Test %s failed. Expected len of expectedPackageViolations %v, got: %v", description, len(expected), len(actual))
@@ -201,3 +296,34 @@ func AssertEquals(expected []utils.PackageLicense, actual []utils.PackageLicense
 		}
 	}
 }
+
+func TestVerifyBlobDigest(t *testing.T) {
+	blob := []byte("sbom")
+	tests := []struct {
+		name      string
+		digest    digest.Digest
+		expectErr bool
+	}{
+		{name: "matching digest", digest: digest.FromBytes(blob)},
+		{name: "mismatched digest", digest: digest.FromString("other"), expectErr: true},
+		{name: "invalid digest", digest: digest.Digest("sha256:invalid"), expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifyBlobDigest(tt.digest, blob); (err != nil) != tt.expectErr {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRecordSBOMDigests(t *testing.T) {
+	manifestDigest := digest.FromString("manifest")
+	blobDigest := digest.FromString("blob")
+	vr := processSpdxJSONMediaType("test", "", []byte("invalid"), nil, nil, nil, nil)
+	recordSBOMDigests(vr, manifestDigest, blobDigest)
+	extensions, ok := vr.Extensions.(map[string]interface{})
+	if !ok || extensions[ManifestDigest] != manifestDigest.String() || extensions[BlobDigest] != blobDigest.String() {
+		t.Fatalf("expected manifest and blob digests to be recorded, got %v", vr.Extensions)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	operatorAnd  = "and"
+	operatorOr   = "or"
+	operatorWith = "with"
+)
+
+// deprecatedLicenseIDs maps deprecated SPDX license identifiers to their current form.
+// All keys and values are lower case.
+var deprecatedLicenseIDs = map[string]string{
+	"agpl-1.0":  "agpl-1.0-only",
+	"agpl-3.0":  "agpl-3.0-only",
+	"gfdl-1.1":  "gfdl-1.1-only",
+	"gfdl-1.2":  "gfdl-1.2-only",
+	"gfdl-1.3":  "gfdl-1.3-only",
+	"gpl-1.0":   "gpl-1.0-only",
+	"gpl-2.0":   "gpl-2.0-only",
+	"gpl-3.0":   "gpl-3.0-only",
+	"lgpl-2.0":  "lgpl-2.0-only",
+	"lgpl-2.1":  "lgpl-2.1-only",
+	"lgpl-3.0":  "lgpl-3.0-only",
+	"gpl-1.0+":  "gpl-1.0-or-later",
+	"gpl-2.0+":  "gpl-2.0-or-later",
+	"gpl-3.0+":  "gpl-3.0-or-later",
+	"lgpl-2.0+": "lgpl-2.0-or-later",
+	"lgpl-2.1+": "lgpl-2.1-or-later",
+	"lgpl-3.0+": "lgpl-3.0-or-later",
+}
+
+// LicenseExpression is a node of a parsed SPDX license expression.
+// A leaf node holds a single license with an optional exception, an inner node
+// combines its left and right operands with either AND or OR.
+type LicenseExpression struct {
+	License   string
+	Exception string
+	Operator  string
+	Left      *LicenseExpression
+	Right     *LicenseExpression
+}
+
+// ParseLicenseExpression parses an SPDX license expression such as
+// "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0".
+// Operators are matched case-insensitively, license identifiers are normalized
+// with NormalizeLicenseID.
+func ParseLicenseExpression(expression string) (*LicenseExpression, error) {
+	p := &licenseParser{tokens: tokenizeLicenseExpression(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse license expression %q: %w", expression, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("failed to parse license expression %q: unexpected token %q", expression, p.peek())
+	}
+	return node, nil
+}
+
+// NormalizeLicenseID returns the lower case form of a license identifier with
+// deprecated identifiers replaced by their current equivalent.
+// A trailing "+" on an "-only" identifier is rewritten to "-or-later".
+func NormalizeLicenseID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if normalized, ok := deprecatedLicenseIDs[id]; ok {
+		return normalized
+	}
+	if base, ok := strings.CutSuffix(id, "+"); ok {
+		base = NormalizeLicenseID(base)
+		if trimmed, ok := strings.CutSuffix(base, "-only"); ok {
+			return trimmed + "-or-later"
+		}
+		return base + "+"
+	}
+	return id
+}
+
+// LoadLicenses normalizes the configured licenses into a set. Entries may be a
+// single license identifier or a license with an exception, e.g.
+// "GPL-2.0-only WITH Classpath-exception-2.0".
+func LoadLicenses(licenses []string) map[string]struct{} {
+	output := map[string]struct{}{}
+	for _, license := range licenses {
+		if expr, err := ParseLicenseExpression(license); err == nil && expr.isLeaf() {
+			output[expr.key()] = struct{}{}
+			continue
+		}
+		output[NormalizeLicenseID(license)] = struct{}{}
+	}
+	return output
+}
+
+// ViolatesDenyList returns true if the expression cannot be satisfied without
+// choosing a denied license. An empty expression never violates the deny list,
+// an expression that cannot be parsed always does.
+func ViolatesDenyList(expression string, denied map[string]struct{}) bool {
+	if len(strings.TrimSpace(expression)) == 0 || len(denied) == 0 {
+		return false
+	}
+	expr, err := ParseLicenseExpression(expression)
+	if err != nil {
+		return true
+	}
+	return !expr.SatisfiedBy(func(leaf *LicenseExpression) bool {
+		return !leaf.matches(denied)
+	})
+}
+
+// ViolatesAllowList returns true if the expression cannot be satisfied using
+// only allowed licenses. An empty expression never violates the allow list,
+// an expression that cannot be parsed always does.
+func ViolatesAllowList(expression string, allowed map[string]struct{}) bool {
+	if len(strings.TrimSpace(expression)) == 0 || len(allowed) == 0 {
+		return false
+	}
+	expr, err := ParseLicenseExpression(expression)
+	if err != nil {
+		return true
+	}
+	return !expr.SatisfiedBy(func(leaf *LicenseExpression) bool {
+		return leaf.matches(allowed)
+	})
+}
+
+// SatisfiedBy evaluates the expression, returning true if there is a choice of
+// licenses that fulfils it where every chosen license is accepted.
+func (e *LicenseExpression) SatisfiedBy(accept func(leaf *LicenseExpression) bool) bool {
+	switch e.Operator {
+	case operatorAnd:
+		return e.Left.SatisfiedBy(accept) && e.Right.SatisfiedBy(accept)
+	case operatorOr:
+		return e.Left.SatisfiedBy(accept) || e.Right.SatisfiedBy(accept)
+	default:
+		return accept(e)
+	}
+}
+
+// Licenses returns the normalized license identifiers referenced by the expression.
+func (e *LicenseExpression) Licenses() []string {
+	if e.isLeaf() {
+		return []string{e.License}
+	}
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+func (e *LicenseExpression) String() string {
+	if e.isLeaf() {
+		return e.key()
+	}
+	return fmt.Sprintf("(%s %s %s)", e.Left.String(), strings.ToUpper(e.Operator), e.Right.String())
+}
+
+func (e *LicenseExpression) isLeaf() bool {
+	return e.Operator == ""
+}
+
+func (e *LicenseExpression) key() string {
+	if e.Exception == "" {
+		return e.License
+	}
+	return e.License + " " + operatorWith + " " + e.Exception
+}
+
+// matches returns true if the leaf license, with or without its exception, is in the set
+func (e *LicenseExpression) matches(licenses map[string]struct{}) bool {
+	if _, ok := licenses[e.key()]; ok {
+		return true
+	}
+	_, ok := licenses[e.License]
+	return ok
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *licenseParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *licenseParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *licenseParser) peekOperator(operator string) bool {
+	return strings.EqualFold(p.peek(), operator)
+}
+
+// or-expression = and-expression *("OR" and-expression)
+func (p *licenseParser) parseOr() (*LicenseExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator(operatorOr) {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LicenseExpression{Operator: operatorOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// and-expression = with-expression *("AND" with-expression)
+func (p *licenseParser) parseAnd() (*LicenseExpression, error) {
+	left, err := p.parseWith()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOperator(operatorAnd) {
+		p.next()
+		right, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+		left = &LicenseExpression{Operator: operatorAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// with-expression = "(" or-expression ")" / license-id ["WITH" exception-id]
+func (p *licenseParser) parseWith() (*LicenseExpression, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return node, nil
+	case token == ")" || isLicenseOperator(token):
+		return nil, fmt.Errorf("unexpected token %q", token)
+	}
+
+	leaf := &LicenseExpression{License: NormalizeLicenseID(token)}
+	if p.peekOperator(operatorWith) {
+		p.next()
+		exception := p.next()
+		if exception == "" || exception == "(" || exception == ")" || isLicenseOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH")
+		}
+		leaf.Exception = strings.ToLower(exception)
+	}
+	return leaf, nil
+}
+
+func isLicenseOperator(token string) bool {
+	return strings.EqualFold(token, operatorAnd) || strings.EqualFold(token, operatorOr) || strings.EqualFold(token, operatorWith)
+}
+
+// tokenizeLicenseExpression splits an expression on whitespace and parentheses
+func tokenizeLicenseExpression(expression string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range expression {
+		switch r {
+		case '(', ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case ' ', '\t', '\n', '\r':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,248 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "testing"
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		expectErr  bool
+	}{
+		{
+			name:       "single license",
+			expression: "MIT",
+			expected:   "mit",
+		},
+		{
+			name:       "AND binds tighter than OR",
+			expression: "MIT OR Apache-2.0 AND BSD-3-Clause",
+			expected:   "(mit OR (apache-2.0 AND bsd-3-clause))",
+		},
+		{
+			name:       "parentheses",
+			expression: "(MIT OR Apache-2.0) AND BSD-3-Clause",
+			expected:   "((mit OR apache-2.0) AND bsd-3-clause)",
+		},
+		{
+			name:       "with exception",
+			expression: "GPL-2.0-only WITH Classpath-exception-2.0 OR MIT",
+			expected:   "(gpl-2.0-only with classpath-exception-2.0 OR mit)",
+		},
+		{
+			name:       "deprecated identifiers are normalized",
+			expression: "GPL-2.0 and GPL-3.0+",
+			expected:   "(gpl-2.0-only AND gpl-3.0-or-later)",
+		},
+		{
+			name:       "license ref containing operator",
+			expression: "MPL-2.0 AND LicenseRef-AND AND MIT",
+			expected:   "((mpl-2.0 AND licenseref-and) AND mit)",
+		},
+		{
+			name:       "missing closing parenthesis",
+			expression: "(MIT OR Apache-2.0",
+			expectErr:  true,
+		},
+		{
+			name:       "dangling operator",
+			expression: "MIT AND",
+			expectErr:  true,
+		},
+		{
+			name:       "missing exception",
+			expression: "GPL-2.0-only WITH",
+			expectErr:  true,
+		},
+		{
+			name:       "missing operator",
+			expression: "MIT Apache-2.0",
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseLicenseExpression(tt.expression)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error parsing %q", tt.expression)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expr.String() != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, expr.String())
+			}
+		})
+	}
+}
+
+func TestViolatesDenyList(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		denied     []string
+		expected   bool
+	}{
+		{
+			name:       "exact match",
+			expression: "MIT",
+			denied:     []string{"MIT"},
+			expected:   true,
+		},
+		{
+			name:       "case insensitive match",
+			expression: "MIT",
+			denied:     []string{"mit"},
+			expected:   true,
+		},
+		{
+			name:       "partial identifier does not match",
+			expression: "MPL-2.0 AND LicenseRef-AND AND MIT",
+			denied:     []string{"MPL"},
+			expected:   false,
+		},
+		{
+			name:       "denied license in conjunction",
+			expression: "MIT AND LicenseRef-AND AND MPL-2.0",
+			denied:     []string{"MPL-2.0"},
+			expected:   true,
+		},
+		{
+			name:       "denied license in parentheses",
+			expression: "(MIT AND GPL-3.0-only)",
+			denied:     []string{"GPL-3.0-only"},
+			expected:   true,
+		},
+		{
+			name:       "alternative license available",
+			expression: "MIT OR GPL-3.0-only",
+			denied:     []string{"GPL-3.0-only"},
+			expected:   false,
+		},
+		{
+			name:       "all alternatives denied",
+			expression: "(MIT OR GPL-3.0-only) AND Apache-2.0",
+			denied:     []string{"MIT", "GPL-3.0-only"},
+			expected:   true,
+		},
+		{
+			name:       "license with exception matches denied license",
+			expression: "GPL-2.0-only WITH Classpath-exception-2.0",
+			denied:     []string{"GPL-2.0-only"},
+			expected:   true,
+		},
+		{
+			name:       "denied license with exception",
+			expression: "GPL-2.0-only WITH Classpath-exception-2.0",
+			denied:     []string{"GPL-2.0-only WITH Classpath-exception-2.0"},
+			expected:   true,
+		},
+		{
+			name:       "denied deprecated identifier",
+			expression: "GPL-2.0-only",
+			denied:     []string{"GPL-2.0"},
+			expected:   true,
+		},
+		{
+			name:       "empty expression",
+			expression: "",
+			denied:     []string{"MIT"},
+			expected:   false,
+		},
+		{
+			name:       "invalid expression",
+			expression: "MIT AND (",
+			denied:     []string{"Apache-2.0"},
+			expected:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ViolatesDenyList(tt.expression, LoadLicenses(tt.denied))
+			if result != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestViolatesAllowList(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		allowed    []string
+		expected   bool
+	}{
+		{
+			name:       "allowed license",
+			expression: "MIT",
+			allowed:    []string{"MIT"},
+			expected:   false,
+		},
+		{
+			name:       "license not allowed",
+			expression: "Zlib",
+			allowed:    []string{"MIT"},
+			expected:   true,
+		},
+		{
+			name:       "one of the alternatives allowed",
+			expression: "GPL-3.0-only OR MIT",
+			allowed:    []string{"MIT"},
+			expected:   false,
+		},
+		{
+			name:       "conjunction partially allowed",
+			expression: "GPL-3.0-only AND MIT",
+			allowed:    []string{"MIT"},
+			expected:   true,
+		},
+		{
+			name:       "exception allowed through base license",
+			expression: "GPL-2.0-only WITH Classpath-exception-2.0",
+			allowed:    []string{"GPL-2.0-only"},
+			expected:   false,
+		},
+		{
+			name:       "only license with exception allowed",
+			expression: "GPL-2.0-only",
+			allowed:    []string{"GPL-2.0-only WITH Classpath-exception-2.0"},
+			expected:   true,
+		},
+		{
+			name:       "or later operator",
+			expression: "GPL-2.0+",
+			allowed:    []string{"GPL-2.0-or-later"},
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ViolatesAllowList(tt.expression, LoadLicenses(tt.allowed))
+			if result != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, result)
+			}
+		})
+	}
+}
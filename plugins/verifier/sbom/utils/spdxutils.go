@@ -21,38 +21,39 @@ import (
 	"github.com/spdx/tools-golang/spdx"
 )
 
+const purlReferenceType = "purl"
+
 // Get the packageLicense array from spdxDoc
 func GetPackageLicenses(doc spdx.Document) []PackageLicense {
 	output := []PackageLicense{}
 	for _, p := range doc.Packages {
-		output = append(output, PackageLicense{
+		packageLicense := PackageLicense{
 			Name:    p.PackageName,
 			Version: p.PackageVersion,
 			License: p.PackageLicenseConcluded,
-		})
+		}
+		for _, ref := range p.PackageExternalReferences {
+			if ref != nil && ref.RefType == purlReferenceType {
+				packageLicense.PurlType = GetPurlType(ref.Locator)
+				break
+			}
+		}
+		output = append(output, packageLicense)
 	}
 	return output
 }
 
-// returns true if the licenseExpression contains the disallowed license
-// this implements a whole word match
-func ContainsLicense(spdxLicenseExpression string, disallowed string) bool {
-	if len(spdxLicenseExpression) == 0 {
-		return false
-	}
-
-	// if the licenseExpression is exactly the same as the disallowed license, return true
-	if spdxLicenseExpression == disallowed {
-		return true
+// GetPurlType returns the lowercase type of a package URL, e.g. npm for
+// pkg:npm/%40angular/animation@12.3.1, or an empty string if the package URL is
+// invalid.
+func GetPurlType(purl string) string {
+	purlType, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return ""
 	}
-
-	disallowed1 := disallowed + " "
-	disallowed2 := " " + disallowed
-
-	// look for whole word match
-	if strings.Contains(spdxLicenseExpression, disallowed1) || strings.Contains(spdxLicenseExpression, disallowed2) {
-		return true
+	purlType = strings.TrimLeft(purlType, "/")
+	if i := strings.Index(purlType, "/"); i > 0 {
+		return strings.ToLower(purlType[:i])
 	}
-
-	return false
+	return ""
 }
@@ -34,45 +34,26 @@ func TestGetPackageLicenses(t *testing.T) {
 	}
 }
 
-func TestContainsLicense(t *testing.T) {
-	tests := []struct {
-		name                  string
-		spdxLicenseExpression string
-		disallowed            string
-		expected              bool
-	}{
-		{
-			name:                  "exact match",
-			spdxLicenseExpression: "MIT",
-			disallowed:            "MIT",
-			expected:              true,
-		},
-		{
-			name:                  "exact match with space",
-			spdxLicenseExpression: "MPL-2.0 AND LicenseRef-AND AND MIT",
-			disallowed:            "MPL",
-			expected:              false,
-		},
-		{
-			name:                  "exact match with space",
-			spdxLicenseExpression: "MPL-2.0 AND LicenseRef-AND AND MIT",
-			disallowed:            "MPL-2.0",
-			expected:              true,
-		},
-		{
-			name:                  "exact match with space",
-			spdxLicenseExpression: "MIT AND LicenseRef-AND AND MPL-2.0",
-			disallowed:            "MPL-2.0",
-			expected:              true,
-		},
+func TestGetPurlType(t *testing.T) {
+	cases := map[string]string{
+		"pkg:npm/%40angular/animation@12.3.1":           "npm",
+		"pkg:golang/github.com/spf13/cobra@v1.8.0":      "golang",
+		"pkg:DEB/debian/curl@7.50.3-1?arch=i386":        "deb",
+		"pkg://pypi/django@1.11.1":                      "pypi",
+		"cpe:2.3:a:alpine:zlib:1.2.13-r0:*:*:*:*:*:*:*": "",
+		"pkg:invalid": "",
+	}
+	for purl, expected := range cases {
+		if purlType := GetPurlType(purl); purlType != expected {
+			t.Fatalf("expected purl type %q of %s, got %q", expected, purl, purlType)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run("test scenario", func(t *testing.T) {
-			result := ContainsLicense(tt.spdxLicenseExpression, tt.disallowed)
-			if result != tt.expected {
-				t.Fatalf("expected %t, got %t", tt.expected, result)
-			}
-		})
+	b, _ := os.ReadFile(filepath.Join("../testdata", "syftbom.spdx.json"))
+	spdxDoc, _ := jsonLoader.Read(bytes.NewReader(b))
+	for _, packageLicense := range GetPackageLicenses(*spdxDoc) {
+		if packageLicense.Name == "zlib" && packageLicense.PurlType != "apk" {
+			t.Fatalf("expected purl type apk of package zlib, got %q", packageLicense.PurlType)
+		}
 	}
 }
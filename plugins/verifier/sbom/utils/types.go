@@ -31,6 +31,9 @@ type PackageLicense struct {
 	Name    string
 	Version string
 	License string
+	// PurlType is the type of the package URL of the package, e.g. npm or
+	// golang, it is empty if the package has no package URL.
+	PurlType string `json:",omitempty"`
 }
 
 // Internal types that stores extracted Name and Version of package
@@ -49,3 +52,15 @@ type PackageInfo struct {
 	Name    string `json:"name,omitempty"`
 	Version string `json:"version,omitempty"`
 }
+
+// EcosystemPolicy replaces the license and package policies of the verifier
+// for packages of the package URL types, e.g. to disallow GPL licenses for
+// statically linked golang and npm dependencies only.
+type EcosystemPolicy struct {
+	// PurlTypes are the package URL types the policy applies to, e.g. npm,
+	// pypi, golang or deb.
+	PurlTypes          []string      `json:"purlTypes"`
+	DisallowedLicenses []string      `json:"disallowedLicenses,omitempty"`
+	AllowedLicenses    []string      `json:"allowedLicenses,omitempty"`
+	DisallowedPackages []PackageInfo `json:"disallowedPackages,omitempty"`
+}
@@ -0,0 +1,237 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// supported comparison operators, longest first so that prefix matching is unambiguous
+var versionOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// pre-release markers sort before the release they precede, e.g. 2.17.1-rc1 < 2.17.1
+var preReleaseMarkers = map[string]struct{}{
+	"alpha":    {},
+	"beta":     {},
+	"dev":      {},
+	"pre":      {},
+	"rc":       {},
+	"snapshot": {},
+}
+
+type versionComparison struct {
+	operator string
+	version  string
+}
+
+// VersionConstraint is a parsed version range such as "< 2.17.1" or
+// ">= 2.0.0, < 2.17.1 || = 1.2.3". Comparisons separated by "," must all hold,
+// groups separated by "||" are alternatives.
+type VersionConstraint struct {
+	alternatives [][]versionComparison
+}
+
+// IsVersionRange returns true if the version of a deny list entry is a range
+// constraint rather than an exact version.
+func IsVersionRange(version string) bool {
+	version = strings.TrimSpace(version)
+	for _, operator := range versionOperators {
+		if strings.HasPrefix(version, operator) {
+			return true
+		}
+	}
+	return strings.Contains(version, "||")
+}
+
+// ParseVersionConstraint parses a version range constraint.
+func ParseVersionConstraint(constraint string) (*VersionConstraint, error) {
+	result := &VersionConstraint{}
+	for _, group := range strings.Split(constraint, "||") {
+		var comparisons []versionComparison
+		for _, part := range strings.Split(group, ",") {
+			comparison, err := parseVersionComparison(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+			}
+			comparisons = append(comparisons, comparison)
+		}
+		result.alternatives = append(result.alternatives, comparisons)
+	}
+	return result, nil
+}
+
+// Matches returns true if the version satisfies the constraint.
+func (c *VersionConstraint) Matches(version string) bool {
+	if len(strings.TrimSpace(version)) == 0 {
+		return false
+	}
+	for _, comparisons := range c.alternatives {
+		matched := true
+		for _, comparison := range comparisons {
+			if !comparison.matches(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVersionComparison(part string) (versionComparison, error) {
+	part = strings.TrimSpace(part)
+	for _, operator := range versionOperators {
+		if version, ok := strings.CutPrefix(part, operator); ok {
+			version = strings.TrimSpace(version)
+			if version == "" {
+				return versionComparison{}, fmt.Errorf("missing version after %q", operator)
+			}
+			return versionComparison{operator: operator, version: version}, nil
+		}
+	}
+	if part == "" {
+		return versionComparison{}, fmt.Errorf("empty comparison")
+	}
+	return versionComparison{operator: "=", version: part}, nil
+}
+
+func (c versionComparison) matches(version string) bool {
+	result := CompareVersions(version, c.version)
+	switch c.operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case "!=":
+		return result != 0
+	default:
+		return result == 0
+	}
+}
+
+// CompareVersions compares two package versions and returns -1, 0 or 1.
+// Versions are not required to be valid semver: they are split into numeric
+// and alphabetic segments, e.g. "3.0.7-r2" is compared as [3 0 7 r 2].
+// Numeric segments compare numerically, alphabetic segments lexically, and
+// missing numeric segments count as 0, e.g. "2.17" equals "2.17.0". A version
+// with additional non-zero trailing segments is greater unless the first of
+// them is a pre-release marker such as "rc".
+func CompareVersions(a, b string) int {
+	segmentsA := splitVersion(a)
+	segmentsB := splitVersion(b)
+
+	for i := 0; i < len(segmentsA) && i < len(segmentsB); i++ {
+		if result := compareSegments(segmentsA[i], segmentsB[i]); result != 0 {
+			return result
+		}
+	}
+
+	switch {
+	case len(segmentsA) > len(segmentsB):
+		return compareTrailingSegments(segmentsA[len(segmentsB):])
+	case len(segmentsA) < len(segmentsB):
+		return -compareTrailingSegments(segmentsB[len(segmentsA):])
+	}
+	return 0
+}
+
+// compareTrailingSegments compares a version with the trailing segments to the
+// same version without them.
+func compareTrailingSegments(segments []string) int {
+	for _, segment := range segments {
+		if num, err := strconv.ParseUint(segment, 10, 64); err == nil {
+			if num == 0 {
+				continue
+			}
+			return 1
+		}
+		if isPreReleaseMarker(segment) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func compareSegments(a, b string) int {
+	numA, errA := strconv.ParseUint(a, 10, 64)
+	numB, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if numA < numB {
+			return -1
+		} else if numA > numB {
+			return 1
+		}
+		return 0
+	case errA == nil:
+		// a numeric segment is newer than a pre-release marker
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func isPreReleaseMarker(segment string) bool {
+	_, ok := preReleaseMarkers[segment]
+	return ok
+}
+
+// splitVersion splits a version into lower case numeric and alphabetic segments
+func splitVersion(version string) []string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	version = strings.TrimPrefix(version, "v")
+
+	var segments []string
+	var current strings.Builder
+	currentIsDigit := false
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range version {
+		switch {
+		case unicode.IsDigit(r):
+			if !currentIsDigit {
+				flush()
+			}
+			currentIsDigit = true
+			current.WriteRune(r)
+		case unicode.IsLetter(r):
+			if currentIsDigit {
+				flush()
+			}
+			currentIsDigit = false
+			current.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return segments
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "2.17.1", b: "2.17.1", expected: 0},
+		{a: "v2.17.1", b: "2.17.1", expected: 0},
+		{a: "2.17.0", b: "2.17.1", expected: -1},
+		{a: "2.9.0", b: "2.17.1", expected: -1},
+		{a: "2.17", b: "2.17.1", expected: -1},
+		{a: "2.17.0", b: "2.17", expected: 0},
+		{a: "2.17", b: "2.17.0.0", expected: 0},
+		{a: "1.0.0-rc1", b: "1.0", expected: -1},
+		{a: "3.0.7-r2", b: "3.0.7-r3", expected: -1},
+		{a: "3.0.7-r2", b: "3.0.7", expected: 1},
+		{a: "2.17.1-rc1", b: "2.17.1", expected: -1},
+		{a: "2.17.1", b: "2.17.1-beta", expected: 1},
+		{a: "1.0.0-alpha", b: "1.0.0-beta", expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if result := CompareVersions(tt.a, tt.b); result != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{name: "less than", constraint: "< 2.17.1", version: "2.14.0", expected: true},
+		{name: "not less than", constraint: "< 2.17.1", version: "2.17.1", expected: false},
+		{name: "less than or equal", constraint: "<=2.17.1", version: "2.17.1", expected: true},
+		{name: "less than or equal with missing segment", constraint: "<= 2.17", version: "2.17.0", expected: true},
+		{name: "greater than", constraint: "> 1.0", version: "1.0.1", expected: true},
+		{name: "not equal", constraint: "!= 1.0", version: "1.0", expected: false},
+		{name: "range", constraint: ">= 2.0.0, < 2.17.1", version: "2.15.0", expected: true},
+		{name: "outside range", constraint: ">= 2.0.0, < 2.17.1", version: "1.2.17", expected: false},
+		{name: "alternatives", constraint: "< 1.0 || >= 2.0, < 2.17.1", version: "0.9", expected: true},
+		{name: "empty version", constraint: "< 2.17.1", version: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := ParseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result := constraint.Matches(tt.version); result != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseVersionConstraint_Invalid(t *testing.T) {
+	for _, constraint := range []string{"< ", ">= 1.0,", "|| 1.0"} {
+		if _, err := ParseVersionConstraint(constraint); err == nil {
+			t.Fatalf("expected error parsing %q", constraint)
+		}
+	}
+}
+
+func TestIsVersionRange(t *testing.T) {
+	if IsVersionRange("3.0.7-r2") {
+		t.Fatalf("expected exact version not to be a range")
+	}
+	if !IsVersionRange("< 2.17.1") {
+		t.Fatalf("expected version range")
+	}
+}
@@ -18,23 +18,38 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	re "github.com/deislabs/ratify/errors"
 	"github.com/deislabs/ratify/pkg/common"
+	"github.com/deislabs/ratify/pkg/common/dsse"
 	"github.com/deislabs/ratify/pkg/ocispecs"
 	"github.com/deislabs/ratify/pkg/referrerstore"
 	_ "github.com/deislabs/ratify/pkg/referrerstore/oras"
 	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/deislabs/ratify/pkg/verifier/attestation"
 	"github.com/deislabs/ratify/pkg/verifier/plugin/skel"
 	"github.com/deislabs/ratify/plugins/verifier/schemavalidator/schemavalidation"
 )
 
 type PluginConfig struct {
-	Name    string            `json:"name"`
-	Type    string            `json:"type"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Schemas are keyed by blob media type
 	Schemas map[string]string `json:"schemas"`
+	// ArtifactTypeSchemas are keyed by referrer artifact type and apply to every
+	// blob of the referrer, DSSE envelopes are validated by their payload
+	ArtifactTypeSchemas map[string]string `json:"artifactTypeSchemas,omitempty"`
+	// PredicateSchemas are keyed by in-toto predicate type and apply to the
+	// predicate of matching attestations
+	PredicateSchemas map[string]string `json:"predicateSchemas,omitempty"`
+	// SkipSubjectCheck disables checking that in-toto attestations list the digest of the subject they are attached to
+	SkipSubjectCheck bool `json:"skipSubjectCheck,omitempty"`
 }
 
+const SubjectMismatch string = "subjectMismatch"
+
 type PluginInputConfig struct {
 	Config PluginConfig `json:"config"`
 }
@@ -62,7 +77,6 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 	if input.Type != "" {
 		verifierType = input.Type
 	}
-	schemaMap := input.Schemas
 	ctx := context.Background()
 
 	referenceManifest, err := referrerStore.GetReferenceManifest(ctx, subjectReference, referenceDescriptor)
@@ -85,7 +99,7 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 			return nil, fmt.Errorf("error fetching blob for subject:[%s] digest:[%s]", subjectReference, blobDesc.Digest)
 		}
 
-		err = processMediaType(schemaMap, blobDesc.MediaType, refBlob)
+		err = validateBlob(input, referenceDescriptor.ArtifactType, blobDesc.MediaType, refBlob)
 		if err != nil {
 			return &verifier.VerifierResult{
 				Name:      input.Name,
@@ -94,6 +108,12 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 				Message:   fmt.Sprintf("schema validation failed for digest:[%s],media type:[%s],parse errors:[%v]", blobDesc.Digest, blobDesc.MediaType, err.Error()),
 			}, nil
 		}
+
+		if !input.SkipSubjectCheck {
+			if result := verifySubject(ctx, input.Name, verifierType, subjectReference, referrerStore, refBlob); result != nil {
+				return result, nil
+			}
+		}
 	}
 
 	return &verifier.VerifierResult{
@@ -104,9 +124,82 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 	}, nil
 }
 
-func processMediaType(schemaMap map[string]string, mediaType string, refBlob []byte) error {
-	if ok := len(schemaMap[mediaType]) > 0; ok {
-		return schemavalidation.Validate(schemaMap[mediaType], refBlob)
+// verifySubject returns a failed result if the blob is an in-toto attestation
+// whose subjects do not contain the digest of the subject being verified
+func verifySubject(ctx context.Context, name string, verifierType string, subjectReference common.Reference, referrerStore referrerstore.ReferrerStore, refBlob []byte) *verifier.VerifierResult {
+	statement, ok, err := attestation.ParseStatement(refBlob)
+	if err != nil || !ok {
+		return nil
+	}
+
+	subjectDigest := subjectReference.Digest
+	if subjectDigest == "" {
+		subjectDesc, err := referrerStore.GetSubjectDescriptor(ctx, subjectReference)
+		if err != nil {
+			return &verifier.VerifierResult{
+				Name:      name,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("failed to resolve subject: %s, err: %v", subjectReference, err),
+			}
+		}
+		subjectDigest = subjectDesc.Digest
+	}
+
+	if err := statement.VerifySubject(subjectDigest); err != nil {
+		var extensions map[string]interface{}
+		var ratifyErr re.Error
+		if errors.As(err, &ratifyErr) {
+			extensions = map[string]interface{}{SubjectMismatch: ratifyErr.Detail}
+		}
+		return &verifier.VerifierResult{
+			Name:       name,
+			Type:       verifierType,
+			IsSuccess:  false,
+			Message:    fmt.Sprintf("attestation subject check failed: %v", err),
+			Extensions: extensions,
+		}
+	}
+	return nil
+}
+
+// validateBlob validates the blob against the schema configured for the
+// artifact type or, if there is none, for the media type. In-toto attestations
+// are additionally validated against the schema configured for their predicate
+// type. Fails if no schema applies to the blob.
+func validateBlob(input *PluginConfig, artifactType string, mediaType string, refBlob []byte) error {
+	validated := false
+	if schema := input.ArtifactTypeSchemas[artifactType]; schema != "" {
+		content := refBlob
+		if envelope, err := dsse.Decode(refBlob); err == nil {
+			if content, err = envelope.DecodePayload(); err != nil {
+				return err
+			}
+		}
+		if err := schemavalidation.Validate(schema, content); err != nil {
+			return err
+		}
+		validated = true
+	} else if schema := input.Schemas[mediaType]; schema != "" {
+		if err := schemavalidation.Validate(schema, refBlob); err != nil {
+			return err
+		}
+		validated = true
+	}
+
+	if len(input.PredicateSchemas) > 0 {
+		if statement, ok, err := attestation.ParseStatement(refBlob); err == nil && ok {
+			if schema := input.PredicateSchemas[statement.PredicateType]; schema != "" {
+				if err := schemavalidation.Validate(schema, statement.Predicate); err != nil {
+					return fmt.Errorf("predicate of type %s is invalid: %w", statement.PredicateType, err)
+				}
+				validated = true
+			}
+		}
+	}
+
+	if !validated {
+		return fmt.Errorf("no schema configured for artifact type:[%s] or media type:[%s]", artifactType, mediaType)
 	}
-	return fmt.Errorf("media type not configured for plugin:[%s]", mediaType)
+	return nil
 }
@@ -0,0 +1,127 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/deislabs/ratify/pkg/common/dsse"
+)
+
+const (
+	scanArtifactType  = "application/vnd.example.security-scan.v1+json"
+	scanPredicateType = "https://example.com/security-scan/v1"
+	scanSchema        = `{"type": "object", "required": ["scanner", "passed"], "properties": {"passed": {"type": "boolean"}}}`
+)
+
+func createStatement(t *testing.T, predicate string) []byte {
+	t.Helper()
+	statement := map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"subject":       []interface{}{},
+		"predicateType": scanPredicateType,
+		"predicate":     json.RawMessage(predicate),
+	}
+	blob, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatalf("failed to marshal statement: %v", err)
+	}
+	return blob
+}
+
+func createEnvelope(t *testing.T, payload []byte) []byte {
+	t.Helper()
+	blob, err := json.Marshal(dsse.Envelope{
+		PayloadType: dsse.PayloadTypeInToto,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+	return blob
+}
+
+func TestValidateBlob(t *testing.T) {
+	validScan := `{"scanner": "internal", "passed": true}`
+	invalidScan := `{"scanner": "internal", "passed": "yes"}`
+
+	testCases := []struct {
+		name         string
+		config       PluginConfig
+		artifactType string
+		mediaType    string
+		blob         []byte
+		isSuccess    bool
+	}{
+		{
+			name:         "artifact type schema",
+			config:       PluginConfig{ArtifactTypeSchemas: map[string]string{scanArtifactType: scanSchema}},
+			artifactType: scanArtifactType,
+			blob:         []byte(validScan),
+			isSuccess:    true,
+		},
+		{
+			name:         "artifact type schema mismatch",
+			config:       PluginConfig{ArtifactTypeSchemas: map[string]string{scanArtifactType: scanSchema}},
+			artifactType: scanArtifactType,
+			blob:         []byte(invalidScan),
+		},
+		{
+			name:         "artifact type schema validates envelope payload",
+			config:       PluginConfig{ArtifactTypeSchemas: map[string]string{scanArtifactType: scanSchema}},
+			artifactType: scanArtifactType,
+			blob:         createEnvelope(t, []byte(validScan)),
+			isSuccess:    true,
+		},
+		{
+			name:      "media type schema",
+			config:    PluginConfig{Schemas: map[string]string{"application/json": scanSchema}},
+			mediaType: "application/json",
+			blob:      []byte(validScan),
+			isSuccess: true,
+		},
+		{
+			name:      "predicate schema",
+			config:    PluginConfig{PredicateSchemas: map[string]string{scanPredicateType: scanSchema}},
+			mediaType: dsse.PayloadTypeInToto,
+			blob:      createEnvelope(t, createStatement(t, validScan)),
+			isSuccess: true,
+		},
+		{
+			name:      "predicate schema mismatch",
+			config:    PluginConfig{PredicateSchemas: map[string]string{scanPredicateType: scanSchema}},
+			mediaType: dsse.PayloadTypeInToto,
+			blob:      createStatement(t, invalidScan),
+		},
+		{
+			name:      "no schema configured",
+			config:    PluginConfig{PredicateSchemas: map[string]string{"https://example.com/other": scanSchema}},
+			mediaType: dsse.PayloadTypeInToto,
+			blob:      createStatement(t, validScan),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateBlob(&tc.config, tc.artifactType, tc.mediaType, tc.blob)
+			if (err == nil) != tc.isSuccess {
+				t.Fatalf("expected success %v, got error %v", tc.isSuccess, err)
+			}
+		})
+	}
+}
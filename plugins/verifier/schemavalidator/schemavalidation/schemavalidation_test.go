@@ -53,6 +53,16 @@ func TestInvalidSchemaFailsValidation(t *testing.T) {
 	}
 }
 
+func TestInlineSchemaValidates(t *testing.T) {
+	schema := `{"type": "object", "required": ["version"]}`
+	if err := Validate(schema, trivyScanReport); err != nil {
+		t.Fatalf("expected inline schema to validate, got %v", err)
+	}
+	if err := Validate(schema, []byte(`{"runs": []}`)); err == nil {
+		t.Fatalf("expected inline schema validation to fail")
+	}
+}
+
 func TestProperSchemaValidatesFromFile(t *testing.T) {
 	expected := true
 	result := ValidateAgainstOfflineSchema(schemaFileBytes, trivyScanReport) == nil
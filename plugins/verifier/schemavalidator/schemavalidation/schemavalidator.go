@@ -18,13 +18,18 @@ package schemavalidation
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
-// Validates content from a byte array against a URL schema or from a canonical file path
+// Validates content from a byte array against a URL schema, a canonical file path
+// or an inline JSON schema document
 func Validate(schema string, content []byte) error {
 	sl := gojsonschema.NewReferenceLoader(schema)
+	if strings.HasPrefix(strings.TrimSpace(schema), "{") {
+		sl = gojsonschema.NewStringLoader(schema)
+	}
 	dl := gojsonschema.NewBytesLoader(content)
 
 	result, err := gojsonschema.Validate(sl, dl)
@@ -0,0 +1,270 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deislabs/ratify/pkg/verifier"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// vulnerabilityFinding is a single vulnerability reported by a scanner
+type vulnerabilityFinding struct {
+	ID             string `json:"id"`
+	Severity       string `json:"severity"`
+	PackageName    string `json:"packageName,omitempty"`
+	PackageVersion string `json:"packageVersion,omitempty"`
+}
+
+// trivyReport is the subset of the trivy JSON report format used for verification
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// grypeReport is the subset of the grype JSON report format used for verification
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// parseTrivyReport extracts the vulnerability findings from a trivy JSON report
+func parseTrivyReport(blob []byte) ([]vulnerabilityFinding, error) {
+	var report trivyReport
+	if err := json.Unmarshal(blob, &report); err != nil {
+		return nil, fmt.Errorf("error parsing trivy report:[%w]", err)
+	}
+	findings := make([]vulnerabilityFinding, 0)
+	for _, result := range report.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			if vulnerability.VulnerabilityID == "" {
+				return nil, fmt.Errorf("vulnerability id not found for target:[%s]", result.Target)
+			}
+			findings = append(findings, vulnerabilityFinding{
+				ID:             vulnerability.VulnerabilityID,
+				Severity:       strings.ToLower(vulnerability.Severity),
+				PackageName:    vulnerability.PkgName,
+				PackageVersion: vulnerability.InstalledVersion,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// parseGrypeReport extracts the vulnerability findings from a grype JSON report
+func parseGrypeReport(blob []byte) ([]vulnerabilityFinding, error) {
+	var report grypeReport
+	if err := json.Unmarshal(blob, &report); err != nil {
+		return nil, fmt.Errorf("error parsing grype report:[%w]", err)
+	}
+	findings := make([]vulnerabilityFinding, 0, len(report.Matches))
+	for _, match := range report.Matches {
+		if match.Vulnerability.ID == "" {
+			return nil, fmt.Errorf("vulnerability id not found for artifact:[%s]", match.Artifact.Name)
+		}
+		findings = append(findings, vulnerabilityFinding{
+			ID:             match.Vulnerability.ID,
+			Severity:       strings.ToLower(match.Vulnerability.Severity),
+			PackageName:    match.Artifact.Name,
+			PackageVersion: match.Artifact.Version,
+		})
+	}
+	return findings, nil
+}
+
+// processNativeReport processes a trivy or grype JSON report running individual validations as configured
+func processNativeReport(input *PluginConfig, verifierName string, verifierType string, artifactType string, blob []byte, createdTime time.Time) *verifier.VerifierResult {
+	scannerName := TrivyScannerName
+	parse := parseTrivyReport
+	if artifactType == GrypeJSONArtifactType {
+		scannerName = GrypeScannerName
+		parse = parseGrypeReport
+	}
+
+	findings, err := parse(blob)
+	if err != nil {
+		return &verifier.VerifierResult{
+			Name:      verifierName,
+			Type:      verifierType,
+			IsSuccess: false,
+			Message:   fmt.Sprintf("vulnerability report validation failed: %v", err.Error()),
+			Extensions: map[string]interface{}{
+				"scanner":         scannerName,
+				CreatedAnnotation: createdTime,
+			},
+		}
+	}
+
+	if len(input.DenylistCVEs) > 0 {
+		denylistCVESet := make(map[string]struct{})
+		for _, cve := range input.DenylistCVEs {
+			denylistCVESet[strings.ToLower(cve)] = struct{}{}
+		}
+		denylistViolations := make(map[string]struct{})
+		for _, finding := range findings {
+			if _, ok := denylistCVESet[strings.ToLower(finding.ID)]; ok {
+				denylistViolations[strings.ToLower(finding.ID)] = struct{}{}
+			}
+		}
+		if len(denylistViolations) > 0 {
+			violations := make([]string, 0, len(denylistViolations))
+			for cve := range denylistViolations {
+				violations = append(violations, cve)
+			}
+			sort.Strings(violations)
+			return &verifier.VerifierResult{
+				Name:      verifierName,
+				Type:      verifierType,
+				IsSuccess: false,
+				Extensions: map[string]interface{}{
+					"scanner":         scannerName,
+					"denylistCVEs":    violations,
+					CreatedAnnotation: createdTime,
+				},
+				Message: "vulnerability report validation failed",
+			}
+		}
+	}
+
+	if len(input.DisallowedSeverities) > 0 {
+		violations := make([]vulnerabilityFinding, 0)
+		for _, finding := range findings {
+			for _, disallowed := range input.DisallowedSeverities {
+				if strings.EqualFold(finding.Severity, disallowed) {
+					violations = append(violations, finding)
+				}
+			}
+		}
+		if len(violations) > 0 {
+			return &verifier.VerifierResult{
+				Name:      verifierName,
+				Type:      verifierType,
+				IsSuccess: false,
+				Extensions: map[string]interface{}{
+					"scanner":            scannerName,
+					"severityViolations": violations,
+					CreatedAnnotation:    createdTime,
+				},
+				Message: "vulnerability report validation failed",
+			}
+		}
+	}
+
+	if len(input.SeverityThresholds) > 0 {
+		severities := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			severities = append(severities, finding.Severity)
+		}
+		if result := verifySeverityThresholds(verifierName, verifierType, scannerName, severities, input.SeverityThresholds, createdTime); !result.IsSuccess {
+			return result
+		}
+	}
+
+	return &verifier.VerifierResult{
+		Name:      verifierName,
+		Type:      verifierType,
+		IsSuccess: true,
+		Message:   "vulnerability report validation succeeded",
+		Extensions: map[string]interface{}{
+			CreatedAnnotation: createdTime,
+			"scanner":         scannerName,
+		},
+	}
+}
+
+// sarifSeverities returns the severity of every result in the sarif report
+func sarifSeverities(scannerName string, sarifReport *sarif.Report) ([]string, error) {
+	ruleMap := make(map[string]*sarif.ReportingDescriptor)
+	for _, rule := range sarifReport.Runs[0].Tool.Driver.Rules {
+		ruleMap[rule.ID] = rule
+	}
+	severities := make([]string, 0, len(sarifReport.Runs[0].Results))
+	for _, result := range sarifReport.Runs[0].Results {
+		if result.RuleID == nil || *result.RuleID == "" {
+			return nil, fmt.Errorf("rule id not found for result:[%v]", result)
+		}
+		rule, ok := ruleMap[*result.RuleID]
+		if !ok {
+			return nil, fmt.Errorf("rule not found for result:[%v]", result)
+		}
+		severity, err := extractSeverity(scannerName, *rule)
+		if err != nil {
+			return nil, fmt.Errorf("error extracting severity:[%w]", err)
+		}
+		severities = append(severities, severity)
+	}
+	return severities, nil
+}
+
+// verifySeverityThresholds verifies that the number of findings per severity
+// does not exceed the configured maximum count for that severity
+func verifySeverityThresholds(verifierName string, verifierType string, scannerName string, severities []string, thresholds map[string]int, createdTime time.Time) *verifier.VerifierResult {
+	counts := make(map[string]int)
+	for _, severity := range severities {
+		counts[strings.ToLower(severity)]++
+	}
+
+	violations := make(map[string]int)
+	for severity, maximum := range thresholds {
+		if count := counts[strings.ToLower(severity)]; count > maximum {
+			violations[strings.ToLower(severity)] = count
+		}
+	}
+
+	if len(violations) > 0 {
+		return &verifier.VerifierResult{
+			Name:      verifierName,
+			Type:      verifierType,
+			IsSuccess: false,
+			Extensions: map[string]interface{}{
+				"scanner":                     scannerName,
+				"severityThresholdViolations": violations,
+				CreatedAnnotation:             createdTime,
+			},
+			Message: "vulnerability report validation failed: severity thresholds exceeded",
+		}
+	}
+	return &verifier.VerifierResult{
+		Name:      verifierName,
+		Type:      verifierType,
+		IsSuccess: true,
+		Message:   "vulnerability report validation succeeded",
+		Extensions: map[string]interface{}{
+			"scanner":         scannerName,
+			CreatedAnnotation: createdTime,
+		},
+	}
+}
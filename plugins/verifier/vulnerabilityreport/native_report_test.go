@@ -0,0 +1,147 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleTrivyReport string = `{
+	"SchemaVersion": 2,
+	"ArtifactName": "alpine:3.18",
+	"Results": [
+		{
+			"Target": "alpine:3.18 (alpine 3.18.0)",
+			"Vulnerabilities": [
+				{"VulnerabilityID": "CVE-2023-5363", "PkgName": "libcrypto3", "InstalledVersion": "3.1.0-r4", "Severity": "HIGH"},
+				{"VulnerabilityID": "CVE-2023-2975", "PkgName": "libcrypto3", "InstalledVersion": "3.1.0-r4", "Severity": "MEDIUM"},
+				{"VulnerabilityID": "CVE-2022-48174", "PkgName": "busybox", "InstalledVersion": "1.36.1-r0", "Severity": "CRITICAL"}
+			]
+		}
+	]
+}`
+
+const sampleGrypeReport string = `{
+	"matches": [
+		{
+			"vulnerability": {"id": "CVE-2023-5363", "severity": "High"},
+			"artifact": {"name": "libcrypto3", "version": "3.1.0-r4"}
+		},
+		{
+			"vulnerability": {"id": "CVE-2023-5678", "severity": "High"},
+			"artifact": {"name": "libssl3", "version": "3.1.0-r4"}
+		}
+	],
+	"descriptor": {"name": "grype", "version": "0.71.0"}
+}`
+
+func TestParseNativeReports(t *testing.T) {
+	findings, err := parseTrivyReport([]byte(sampleTrivyReport))
+	if err != nil {
+		t.Fatalf("unexpected error parsing trivy report: %v", err)
+	}
+	if len(findings) != 3 || findings[2].Severity != "critical" || findings[2].PackageName != "busybox" {
+		t.Fatalf("unexpected trivy findings: %+v", findings)
+	}
+
+	findings, err = parseGrypeReport([]byte(sampleGrypeReport))
+	if err != nil {
+		t.Fatalf("unexpected error parsing grype report: %v", err)
+	}
+	if len(findings) != 2 || findings[0].Severity != "high" || findings[1].ID != "CVE-2023-5678" {
+		t.Fatalf("unexpected grype findings: %+v", findings)
+	}
+
+	if _, err := parseTrivyReport([]byte("invalid")); err == nil {
+		t.Fatalf("expected error parsing invalid trivy report")
+	}
+	if _, err := parseGrypeReport([]byte(`{"matches":[{"vulnerability":{}}]}`)); err == nil {
+		t.Fatalf("expected error parsing grype report without vulnerability id")
+	}
+}
+
+func TestProcessNativeReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        PluginConfig
+		artifactType string
+		blob         string
+		isSuccess    bool
+		extension    string
+	}{
+		{
+			name:         "trivy report without validations",
+			input:        PluginConfig{},
+			artifactType: TrivyJSONArtifactType,
+			blob:         sampleTrivyReport,
+			isSuccess:    true,
+		},
+		{
+			name:         "trivy report with deny listed CVE",
+			input:        PluginConfig{DenylistCVEs: []string{"cve-2023-5363"}},
+			artifactType: TrivyJSONArtifactType,
+			blob:         sampleTrivyReport,
+			isSuccess:    false,
+			extension:    "denylistCVEs",
+		},
+		{
+			name:         "trivy report with disallowed severity",
+			input:        PluginConfig{DisallowedSeverities: []string{"critical"}},
+			artifactType: TrivyJSONArtifactType,
+			blob:         sampleTrivyReport,
+			isSuccess:    false,
+			extension:    "severityViolations",
+		},
+		{
+			name:         "grype report exceeding high threshold",
+			input:        PluginConfig{SeverityThresholds: map[string]int{"critical": 0, "high": 1}},
+			artifactType: GrypeJSONArtifactType,
+			blob:         sampleGrypeReport,
+			isSuccess:    false,
+			extension:    "severityThresholdViolations",
+		},
+		{
+			name:         "grype report within thresholds",
+			input:        PluginConfig{SeverityThresholds: map[string]int{"critical": 0, "high": 2}},
+			artifactType: GrypeJSONArtifactType,
+			blob:         sampleGrypeReport,
+			isSuccess:    true,
+		},
+		{
+			name:         "invalid grype report",
+			input:        PluginConfig{},
+			artifactType: GrypeJSONArtifactType,
+			blob:         "invalid",
+			isSuccess:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := processNativeReport(&tt.input, "test_verifier", "", tt.artifactType, []byte(tt.blob), time.Now())
+			if result.IsSuccess != tt.isSuccess {
+				t.Fatalf("expected success %t, got %t: %s", tt.isSuccess, result.IsSuccess, result.Message)
+			}
+			if tt.extension != "" {
+				extensions := result.Extensions.(map[string]interface{})
+				if _, ok := extensions[tt.extension]; !ok {
+					t.Fatalf("expected extension %s in result: %+v", tt.extension, extensions)
+				}
+			}
+		})
+	}
+}
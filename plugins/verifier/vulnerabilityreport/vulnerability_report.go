@@ -40,6 +40,8 @@ var embeddedFS embed.FS
 
 const (
 	SarifArtifactType        string = "application/sarif+json"
+	TrivyJSONArtifactType    string = "application/vnd.aquasecurity.trivy.report.json.v1"
+	GrypeJSONArtifactType    string = "application/vnd.anchore.grype.report.json.v1"
 	SarifOfflineFilePath     string = "schemavalidation/schemas/sarif-2.1.0.json"
 	TrivyScannerName         string = "trivy"
 	GrypeScannerName         string = "grype"
@@ -57,6 +59,8 @@ type PluginConfig struct {
 	DisallowedSeverities  []string `json:"disallowedSeverities,omitempty"`
 	Passthrough           bool     `json:"passthrough,omitempty"`
 	DenylistCVEs          []string `json:"denylistCVEs,omitempty"`
+	// SeverityThresholds is the maximum number of findings allowed per severity, e.g. {"critical": 0, "high": 5}
+	SeverityThresholds map[string]int `json:"severityThresholds,omitempty"`
 }
 
 type PluginInputConfig struct {
@@ -103,7 +107,7 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 
 	// check report is newer than allowed maximum age
 	if input.MaximumAge != "" {
-		ok, err := validateMaximumAge(input.MaximumAge, createdTime)
+		ok, err := validateMaximumAge(input.MaximumAge, createdTime, args.VerificationTime)
 		if err != nil {
 			return &verifier.VerifierResult{
 				Name:      input.Name,
@@ -197,8 +201,11 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 		}, nil
 	}
 
-	if referenceDescriptor.ArtifactType == SarifArtifactType {
+	switch referenceDescriptor.ArtifactType {
+	case SarifArtifactType:
 		return processSarifReport(input, input.Name, verifierType, refBlob, createdTime)
+	case TrivyJSONArtifactType, GrypeJSONArtifactType:
+		return processNativeReport(input, input.Name, verifierType, referenceDescriptor.ArtifactType, refBlob, createdTime), nil
 	}
 
 	return &verifier.VerifierResult{
@@ -214,8 +221,12 @@ func VerifyReference(args *skel.CmdArgs, subjectReference common.Reference, refe
 
 // verifyJSONSchema validates the json schema of the report
 // if schemaURL is empty, it will use the offline schema embedded in binary
-// currently only support for sarif reports
+// currently only support for sarif reports, trivy and grype JSON reports are
+// validated while being parsed
 func verifyJSONSchema(artifactType string, refBlob []byte, schemaURL string) error {
+	if artifactType == TrivyJSONArtifactType || artifactType == GrypeJSONArtifactType {
+		return nil
+	}
 	if artifactType == SarifArtifactType {
 		// decide online or offline schema type
 		if schemaURL != "" {
@@ -275,6 +286,24 @@ func processSarifReport(input *PluginConfig, verifierName string, verifierType s
 			return verifierReport, nil
 		}
 	}
+	if len(input.SeverityThresholds) > 0 {
+		severities, err := sarifSeverities(scannerName, sarifReport)
+		if err != nil {
+			return &verifier.VerifierResult{
+				Name:      verifierName,
+				Type:      verifierType,
+				IsSuccess: false,
+				Message:   fmt.Sprintf("vulnerability report validation failed: %v", err.Error()),
+				Extensions: map[string]interface{}{
+					"scanner":         scannerName,
+					CreatedAnnotation: createdTime,
+				},
+			}, nil
+		}
+		if verifierReport := verifySeverityThresholds(input.Name, verifierType, scannerName, severities, input.SeverityThresholds, createdTime); !verifierReport.IsSuccess {
+			return verifierReport, nil
+		}
+	}
 
 	return &verifier.VerifierResult{
 		Name:      verifierName,
@@ -469,15 +498,15 @@ func extractCreationTimestamp(createdAnnotationName string, descriptor ocispecs.
 }
 
 // validateMaximumAge validates that the report is newer than the allowed maximum age
-// extracts the created timestamp from the descriptor annotations
-func validateMaximumAge(maximumAge string, createdTime time.Time) (bool, error) {
+// at the verification time
+func validateMaximumAge(maximumAge string, createdTime time.Time, verificationTime time.Time) (bool, error) {
 	// check if maxium age is a valid duration
 	duration, err := time.ParseDuration(maximumAge)
 	if err != nil {
 		return false, fmt.Errorf("error parsing maximum age:[%s]", maximumAge)
 	}
 	// check if created timestamp is older than maximum age
-	if time.Since(createdTime) > duration {
+	if verificationTime.Sub(createdTime) > duration {
 		return false, nil
 	}
 
@@ -217,9 +217,10 @@ func TestVerifyReference(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cmdArgs := skel.CmdArgs{
-				Version:   "1.0.0",
-				Subject:   "test_subject",
-				StdinData: []byte(tt.args.stdinData),
+				Version:          "1.0.0",
+				Subject:          "test_subject",
+				StdinData:        []byte(tt.args.stdinData),
+				VerificationTime: time.Now(),
 			}
 			testStore := &mocks.MemoryTestStore{
 				Manifests: map[digest.Digest]ocispecs.ReferenceManifest{manifestDigest: tt.args.referenceManifest},
@@ -378,6 +379,34 @@ func TestProcessSarifReport(t *testing.T) {
 				err:     nil,
 			},
 		},
+		{
+			name: "severity threshold exceeded",
+			args: args{
+				input: PluginConfig{
+					Name:               "test_verifier",
+					SeverityThresholds: map[string]int{"critical": 0},
+				},
+				blobContent: sampleSarifReport,
+			},
+			want: want{
+				message: "vulnerability report validation failed: severity thresholds exceeded",
+				err:     nil,
+			},
+		},
+		{
+			name: "severity threshold not exceeded",
+			args: args{
+				input: PluginConfig{
+					Name:               "test_verifier",
+					SeverityThresholds: map[string]int{"critical": 1, "high": 0},
+				},
+				blobContent: sampleSarifReport,
+			},
+			want: want{
+				message: "vulnerability report validation succeeded",
+				err:     nil,
+			},
+		},
 		{
 			name: "vulnerability report validation succeeded",
 			args: args{
@@ -973,7 +1002,7 @@ func TestValidateMaxiumAge(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, err := validateMaximumAge(tt.args.maximumAge, tt.args.createTime)
+			valid, err := validateMaximumAge(tt.args.maximumAge, tt.args.createTime, time.Now())
 			if err != nil && err.Error() != tt.want.err.Error() {
 				t.Errorf("validateMaxiumAge() error = %v, wantErr %v", err, tt.want.err)
 				return
@@ -87,6 +87,10 @@ load helpers
     run bin/ratify verify -c $RATIFY_DIR/sbom_denylist_config_packagematch.json -s $TEST_REGISTRY/sbom:v0
     assert_cmd_verify_failure
 
+    # run with deny package with matched name and version range should fail
+    run bin/ratify verify -c $RATIFY_DIR/sbom_denylist_config_packagerangematch.json -s $TEST_REGISTRY/sbom:v0
+    assert_cmd_verify_failure
+
 
     # Notes: test would fail if sbom/notary types are explicitly specified in the policy
     run bin/ratify verify -c $RATIFY_DIR/config.json -s $TEST_REGISTRY/sbom:v0
@@ -76,7 +76,7 @@ assert_cmd_cosign_keyless_verify_bundle_success() {
 }
 
 assert_cmd_verify_failure() {
-  if [[ "$status" != 0 ]]; then
+  if [[ "$status" == 0 ]]; then
     return 1
   fi
   if [[ "$output" == *'"isSuccess": true,'* ]]; then